@@ -93,6 +93,16 @@ func main() {
 		ChannelName: "account_block_new",
 		Unmarshal:   stream.UnmarshalJSON[dto.AccountBlock](),
 	})
+	// Every stream connection also subscribes here, so one subject may
+	// legitimately hold a momentum and a transaction subscription at
+	// once; the per-subject cap is already enforced by the data hubs.
+	reorgHub := stream.New(stream.Config[*dto.Reorg]{
+		ConnectFn:     connectStreamConn,
+		Logger:        logger,
+		ChannelName:   "reorg",
+		Unmarshal:     stream.UnmarshalJSON[dto.Reorg](),
+		PerSubjectMax: -1,
+	})
 
 	r := router.New(router.Deps{
 		Repos:              repos,
@@ -101,6 +111,7 @@ func main() {
 		Pool:               pool,
 		Hub:                hub,
 		TxHub:              txHub,
+		ReorgHub:           reorgHub,
 		Metrics:            m.Middleware,
 		CORSAllowedOrigins: cfg.API.CORSAllowedOriginsList(),
 		RateLimitPerMinute: cfg.API.RateLimitPerMinute,
@@ -156,6 +167,12 @@ func main() {
 				zap.Error(err))
 		}
 	}()
+	go func() {
+		if err := reorgHub.Run(ctx); err != nil {
+			logger.Error("reorg stream hub exited; streams will not receive reorg frames",
+				zap.Error(err))
+		}
+	}()
 
 	select {
 	case <-ctx.Done():
@@ -1,14 +1,33 @@
 // backfill is a one-shot tool that fills gaps in the momentums table by
-// fetching missing/incomplete heights from the node and reprocessing them.
-// It delegates the actual work to indexer.Backfill so the gap-finding query
-// and processing path stay in a single place.
+// fetching missing/incomplete heights from the node and reprocessing them,
+// or, with --reprocess, rewrites every height in a range.
+//
+// Usage:
+//
+//	# Fill every gap up to the highest indexed momentum:
+//	go run ./cmd/backfill
+//
+//	# Fill gaps in a range, four pages at a time:
+//	go run ./cmd/backfill --from 1000000 --to 2000000 --workers 4
+//
+//	# Re-run the pillar and stake handlers over the whole chain:
+//	go run ./cmd/backfill --reprocess --contracts pillar,stake --workers 4
+//
+// Progress is checkpointed in backfill_checkpoints under a name derived
+// from the flags, so re-running an interrupted command resumes it;
+// --restart starts it over. It delegates the actual work to
+// indexer.BackfillRange so the gap-finding query and processing path stay
+// in a single place.
 package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
 	"syscall"
 
 	"github.com/0x3639/znn-sdk-go/rpc_client"
@@ -20,6 +39,34 @@ import (
 )
 
 func main() {
+	var opts indexer.BackfillOptions
+	flag.Uint64Var(&opts.From, "from", 0, "first height (default 1)")
+	flag.Uint64Var(&opts.To, "to", 0, "last height (default: highest indexed momentum at the start of the run)")
+	flag.BoolVar(&opts.Reprocess, "reprocess", false, "rewrite every height in range, not only missing or incomplete ones")
+	contracts := flag.String("contracts", "", "with --reprocess, comma-separated contracts whose handlers to re-run ("+contractNames()+")")
+	flag.IntVar(&opts.Workers, "workers", 1, "concurrent momentum-page fetches")
+	flag.StringVar(&opts.Checkpoint, "checkpoint", "", "checkpoint name (default: derived from the other flags)")
+	noCheckpoint := flag.Bool("no-checkpoint", false, "do not record or resume progress")
+	flag.BoolVar(&opts.Restart, "restart", false, "discard the checkpoint's progress and start over")
+	flag.Parse()
+
+	if *contracts != "" {
+		for _, c := range strings.Split(*contracts, ",") {
+			opts.Contracts = append(opts.Contracts, strings.TrimSpace(c))
+		}
+	}
+	if err := opts.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		flag.Usage()
+		os.Exit(2)
+	}
+	switch {
+	case *noCheckpoint:
+		opts.Checkpoint = ""
+	case opts.Checkpoint == "":
+		opts.Checkpoint = opts.CheckpointName()
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to load configuration: %v\n", err)
@@ -65,6 +112,12 @@ func main() {
 	logger.Info("connected to Zenon node")
 
 	idx := indexer.NewIndexer(client, pool, logger)
+	// Heights that fail are queued with the indexer's backoff; the running
+	// indexer's retrier drains the queue.
+	idx.ConfigureFailedHeights(indexer.FailedHeightsConfig{
+		Backoff:    cfg.Indexer.FailedHeights.Backoff,
+		MaxBackoff: cfg.Indexer.FailedHeights.MaxBackoff,
+	})
 
 	// Pillar/sentinel/project cache must be warm so processed momentums can
 	// resolve owner addresses and embedded contract events.
@@ -73,7 +126,7 @@ func main() {
 		logger.Warn("failed to load cached data, some processing may be incomplete", zap.Error(err))
 	}
 
-	if err := idx.Backfill(ctx); err != nil {
+	if err := idx.BackfillRange(ctx, opts); err != nil {
 		if ctx.Err() != nil {
 			logger.Info("backfill stopped gracefully")
 			return
@@ -83,3 +136,12 @@ func main() {
 
 	logger.Info("backfill complete")
 }
+
+func contractNames() string {
+	names := make([]string, 0, len(indexer.BackfillContracts))
+	for name := range indexer.BackfillContracts {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return strings.Join(names, ", ")
+}
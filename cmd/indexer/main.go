@@ -17,6 +17,8 @@ import (
 	"github.com/0x3639/nom-indexer-go/internal/database"
 	"github.com/0x3639/nom-indexer-go/internal/health"
 	"github.com/0x3639/nom-indexer-go/internal/indexer"
+	"github.com/0x3639/nom-indexer-go/internal/indexer/metrics"
+	"github.com/0x3639/nom-indexer-go/internal/models"
 	"github.com/0x3639/nom-indexer-go/internal/webhooks"
 )
 
@@ -106,15 +108,75 @@ func main() {
 		},
 	)
 
+	idx.ConfigureCatchUp(indexer.CatchUpConfig{
+		Enabled:         cfg.Indexer.CatchUp.Enabled,
+		MomentumWorkers: cfg.Indexer.CatchUp.MomentumWorkers,
+		BlockWorkers:    cfg.Indexer.CatchUp.BlockWorkers,
+		PrefetchDepth:   cfg.Indexer.CatchUp.PrefetchDepth,
+		FanOut:          cfg.Indexer.CatchUp.FanOut,
+	})
+	idx.ConfigureFailedHeights(indexer.FailedHeightsConfig{
+		Enabled:    cfg.Indexer.FailedHeights.Enabled,
+		Interval:   cfg.Indexer.FailedHeights.Interval,
+		Backoff:    cfg.Indexer.FailedHeights.Backoff,
+		MaxBackoff: cfg.Indexer.FailedHeights.MaxBackoff,
+	})
+	idx.ConfigureVerify(indexer.VerifyConfig{
+		Enabled:  cfg.Indexer.Verify.Enabled,
+		Interval: cfg.Indexer.Verify.Interval,
+		Samples:  cfg.Indexer.Verify.Samples,
+		Checks:   cfg.Indexer.Verify.Checks,
+		Repair:   cfg.Indexer.Verify.Repair,
+	})
+	idx.ConfigureReconcile(indexer.ReconcileConfig{
+		Enabled:  cfg.Indexer.Reconcile.Enabled,
+		Interval: cfg.Indexer.Reconcile.Interval,
+		Accounts: cfg.Indexer.Reconcile.Accounts,
+	})
+
+	// Prometheus metrics on their own listener, like the API and MCP
+	// servers. Started before backfill so the catch-up that follows it is
+	// observable from the first momentum, and attached before webhooks so
+	// the dispatcher reports per-endpoint delivery metrics.
+	if cfg.Indexer.Metrics.Enabled {
+		m := metrics.New()
+		idx.AttachMetrics(m)
+		metricsMux := http.NewServeMux()
+		metricsMux.Handle("/metrics", m.Handler())
+		metricsSrv := &http.Server{
+			Addr:              fmt.Sprintf(":%d", cfg.Indexer.Metrics.Port),
+			Handler:           metricsMux,
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+		go func() {
+			logger.Info("metrics listening", zap.String("addr", metricsSrv.Addr))
+			if err := metricsSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Error("metrics server crashed", zap.Error(err))
+			}
+		}()
+	}
+
 	// Wire the webhook dispatcher when enabled. The config→Endpoint mapping
 	// lives here (not in internal/indexer) so the indexer package stays
 	// decoupled from internal/config, mirroring toIndexerNodes above. The
 	// dispatcher is stopped in idx.Run's teardown.
 	if cfg.Webhooks.Enabled {
+		endpoints, err := toWebhookEndpoints(cfg.Webhooks.Endpoints)
+		if err != nil {
+			logger.Fatal("invalid webhooks.endpoints", zap.Error(err))
+		}
 		idx.AttachWebhooks(
-			toWebhookEndpoints(cfg.Webhooks.Endpoints),
-			time.Duration(cfg.Webhooks.TimeoutSeconds)*time.Second,
-			cfg.Webhooks.MaxRetries,
+			endpoints,
+			webhooks.Config{
+				Timeout:          time.Duration(cfg.Webhooks.TimeoutSeconds) * time.Second,
+				MaxRetries:       cfg.Webhooks.MaxRetries,
+				BackoffBase:      time.Duration(cfg.Webhooks.RetryBackoffSeconds) * time.Second,
+				BackoffMax:       time.Duration(cfg.Webhooks.RetryBackoffMaxSeconds) * time.Second,
+				Retention:        time.Duration(cfg.Webhooks.RetentionHours) * time.Hour,
+				ReloadInterval:   time.Duration(cfg.Webhooks.ReloadSeconds) * time.Second,
+				CircuitThreshold: cfg.Webhooks.CircuitFailureThreshold,
+				CircuitCooldown:  time.Duration(cfg.Webhooks.CircuitCooldownSeconds) * time.Second,
+			},
 		)
 		logger.Info("webhooks enabled", zap.Int("endpoints", len(cfg.Webhooks.Endpoints)))
 	}
@@ -220,11 +282,23 @@ func toIndexerNodes(in []config.NodeEntry) []indexer.NodeEntry {
 // toWebhookEndpoints adapts config-level webhook endpoints to the
 // webhooks-package Endpoint type. Kept here (not in internal/indexer) so
 // the indexer package doesn't import internal/config, mirroring
-// toIndexerNodes.
-func toWebhookEndpoints(in []config.WebhookEndpoint) []webhooks.Endpoint {
+// toIndexerNodes. Fails on an endpoint whose filter does not validate.
+func toWebhookEndpoints(in []config.WebhookEndpoint) ([]webhooks.Endpoint, error) {
 	out := make([]webhooks.Endpoint, len(in))
 	for i, e := range in {
-		out[i] = webhooks.Endpoint{URL: e.URL, Secret: e.Secret, Events: e.Events}
+		filter := models.WebhookFilter{
+			Addresses:      e.Filter.Addresses,
+			TokenStandards: e.Filter.TokenStandards,
+			Methods:        e.Filter.Methods,
+			BlockTypes:     e.Filter.BlockTypes,
+			MinAmount:      e.Filter.MinAmount,
+		}
+		if err := webhooks.ValidateFilter(filter); err != nil {
+			return nil, fmt.Errorf("endpoint %s: filter %w", e.URL, err)
+		}
+		out[i] = webhooks.Endpoint{
+			URL: e.URL, Secret: e.Secret, PreviousSecret: e.PreviousSecret, Events: e.Events, Filter: filter,
+		}
 	}
-	return out
+	return out, nil
 }
@@ -41,7 +41,7 @@ func FromAccountBlock(ab *models.AccountBlock) *AccountBlock {
 		Height:             ab.Height,
 		Address:            ab.Address,
 		ToAddress:          ab.ToAddress,
-		Amount:             AmountFromInt64(ab.Amount),
+		Amount:             AmountFromBigInt(ab.Amount),
 		TokenStandard:      ab.TokenStandard,
 		Data:               ab.Data,
 		Method:             ab.Method,
@@ -1,18 +1,33 @@
 package dto
 
-import "strconv"
+import (
+	"math/big"
+	"strconv"
+)
 
-// Amount is a wire-safe representation of an int64 token amount. The
-// indexer stores raw integer amounts (no decimals applied) and a single
-// ZNN total supply (~9e16) already exceeds JavaScript's Number.MAX_SAFE_INTEGER
-// (2^53-1 ≈ 9.007e15). Marshaling as a JSON string is the standard
+// Amount is a wire-safe representation of a raw integer token amount.
+// The indexer stores raw integer amounts (no decimals applied) and a
+// single ZNN total supply (~9e16) already exceeds JavaScript's
+// Number.MAX_SAFE_INTEGER (2^53-1 ≈ 9.007e15); 18-decimal ZTS tokens
+// exceed int64 altogether. Marshaling as a JSON string is the standard
 // crypto-API workaround; clients in numeric-only languages can still
-// strconv it.
+// parse it with a big-integer type.
 //
-// Use AmountFromInt64(x) when constructing DTOs; never assign raw int64
-// to a JSON-exposed field that may hold amounts.
+// Use AmountFromBigInt(x) for NUMERIC-backed token amounts and
+// AmountFromInt64(x) for the remaining ZNN/QSR-only BIGINT columns;
+// never assign a raw integer to a JSON-exposed field that may hold
+// amounts.
 type Amount string
 
 func AmountFromInt64(v int64) Amount {
 	return Amount(strconv.FormatInt(v, 10))
 }
+
+// AmountFromBigInt renders v in base 10. A nil amount (a NULL column or
+// an unset model field) renders as "0".
+func AmountFromBigInt(v *big.Int) Amount {
+	if v == nil {
+		return "0"
+	}
+	return Amount(v.String())
+}
@@ -17,7 +17,7 @@ func FromBalance(b *models.Balance) *Balance {
 	return &Balance{
 		Address:              b.Address,
 		TokenStandard:        b.TokenStandard,
-		Balance:              AmountFromInt64(b.Balance),
+		Balance:              AmountFromBigInt(b.Balance),
 		LastUpdatedTimestamp: b.LastUpdatedTimestamp,
 	}
 }
@@ -33,3 +33,39 @@ func FromBalances(in []*models.Balance) []*Balance {
 	}
 	return out
 }
+
+// BalanceChange is one row of an address's balance history: the balance
+// after a momentum that changed it and the signed change in it.
+type BalanceChange struct {
+	Address           string `json:"address"`
+	TokenStandard     string `json:"token_standard"`
+	MomentumHeight    int64  `json:"momentum_height"`
+	MomentumTimestamp int64  `json:"momentum_timestamp"`
+	Balance           Amount `json:"balance"`
+	Delta             Amount `json:"delta"`
+}
+
+func FromBalanceChange(c *models.BalanceChange) *BalanceChange {
+	if c == nil {
+		return nil
+	}
+	return &BalanceChange{
+		Address:           c.Address,
+		TokenStandard:     c.TokenStandard,
+		MomentumHeight:    c.MomentumHeight,
+		MomentumTimestamp: c.MomentumTimestamp,
+		Balance:           AmountFromBigInt(c.Balance),
+		Delta:             AmountFromBigInt(c.Delta),
+	}
+}
+
+// FromBalanceChanges yields an empty (not nil) slice.
+func FromBalanceChanges(in []*models.BalanceChange) []*BalanceChange {
+	out := make([]*BalanceChange, 0, len(in))
+	for _, c := range in {
+		if d := FromBalanceChange(c); d != nil {
+			out = append(out, d)
+		}
+	}
+	return out
+}
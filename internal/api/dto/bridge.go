@@ -28,8 +28,8 @@ func FromWrapTokenRequest(w *models.WrapTokenRequest) *WrapTokenRequest {
 		ToAddress:               w.ToAddress,
 		TokenStandard:           w.TokenStandard,
 		TokenAddress:            w.TokenAddress,
-		Amount:                  AmountFromInt64(w.Amount),
-		Fee:                     AmountFromInt64(w.Fee),
+		Amount:                  AmountFromBigInt(w.Amount),
+		Fee:                     AmountFromBigInt(w.Fee),
 		Signature:               w.Signature,
 		CreationMomentumHeight:  w.CreationMomentumHeight,
 		ConfirmationsToFinality: w.ConfirmationsToFinality,
@@ -75,7 +75,7 @@ func FromUnwrapTokenRequest(u *models.UnwrapTokenRequest) *UnwrapTokenRequest {
 		ToAddress:                  u.ToAddress,
 		TokenStandard:              u.TokenStandard,
 		TokenAddress:               u.TokenAddress,
-		Amount:                     AmountFromInt64(u.Amount),
+		Amount:                     AmountFromBigInt(u.Amount),
 		Signature:                  u.Signature,
 		RegistrationMomentumHeight: u.RegistrationMomentumHeight,
 		Redeemed:                   u.Redeemed,
@@ -93,3 +93,48 @@ func FromUnwrapTokenRequests(in []*models.UnwrapTokenRequest) []*UnwrapTokenRequ
 	}
 	return out
 }
+
+// BridgeEvent is one call into the Bridge contract, decoded from the
+// ledger.
+type BridgeEvent struct {
+	AccountBlockHash  string            `json:"account_block_hash"`
+	SendBlockHash     string            `json:"send_block_hash"`
+	Method            string            `json:"method"`
+	Address           string            `json:"address"`
+	TokenStandard     string            `json:"token_standard"`
+	Amount            Amount            `json:"amount"`
+	Inputs            map[string]string `json:"inputs"`
+	MomentumHeight    int64             `json:"momentum_height"`
+	MomentumTimestamp int64             `json:"momentum_timestamp"`
+}
+
+func FromBridgeEvent(e *models.BridgeEvent) *BridgeEvent {
+	if e == nil {
+		return nil
+	}
+	inputs := e.Inputs
+	if inputs == nil {
+		inputs = map[string]string{}
+	}
+	return &BridgeEvent{
+		AccountBlockHash:  e.AccountBlockHash,
+		SendBlockHash:     e.SendBlockHash,
+		Method:            e.Method,
+		Address:           e.Address,
+		TokenStandard:     e.TokenStandard,
+		Amount:            AmountFromBigInt(e.Amount),
+		Inputs:            inputs,
+		MomentumHeight:    e.MomentumHeight,
+		MomentumTimestamp: e.MomentumTimestamp,
+	}
+}
+
+func FromBridgeEvents(in []*models.BridgeEvent) []*BridgeEvent {
+	out := make([]*BridgeEvent, 0, len(in))
+	for _, e := range in {
+		if d := FromBridgeEvent(e); d != nil {
+			out = append(out, d)
+		}
+	}
+	return out
+}
@@ -0,0 +1,35 @@
+package dto
+
+import "github.com/0x3639/nom-indexer-go/internal/models"
+
+// FailedHeight is a momentum height the indexer could not process and
+// will retry at NextRetryAt (unix seconds). LastError is the most recent
+// failure.
+type FailedHeight struct {
+	Height        uint64 `json:"height"`
+	LastError     string `json:"last_error"`
+	Attempts      int    `json:"attempts"`
+	FirstFailedAt int64  `json:"first_failed_at"`
+	LastFailedAt  int64  `json:"last_failed_at"`
+	NextRetryAt   int64  `json:"next_retry_at"`
+}
+
+// FromFailedHeights converts a slice, dropping nil entries. Never
+// returns nil so an empty page serialises as [].
+func FromFailedHeights(in []*models.FailedHeight) []*FailedHeight {
+	out := make([]*FailedHeight, 0, len(in))
+	for _, f := range in {
+		if f == nil {
+			continue
+		}
+		out = append(out, &FailedHeight{
+			Height:        f.Height,
+			LastError:     f.LastError,
+			Attempts:      f.Attempts,
+			FirstFailedAt: f.FirstFailedAt,
+			LastFailedAt:  f.LastFailedAt,
+			NextRetryAt:   f.NextRetryAt,
+		})
+	}
+	return out
+}
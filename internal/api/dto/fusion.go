@@ -26,7 +26,7 @@ func FromFusion(f *models.Fusion) *Fusion {
 		MomentumHash:      f.MomentumHash,
 		MomentumTimestamp: f.MomentumTimestamp,
 		MomentumHeight:    f.MomentumHeight,
-		QsrAmount:         AmountFromInt64(f.QsrAmount),
+		QsrAmount:         AmountFromBigInt(f.QsrAmount),
 		ExpirationHeight:  f.ExpirationHeight,
 		IsActive:          f.IsActive,
 		CancelID:          f.CancelID,
@@ -0,0 +1,146 @@
+package dto
+
+import "github.com/0x3639/nom-indexer-go/internal/models"
+
+type LiquidityStake struct {
+	ID                     string `json:"id"`
+	StakeAddress           string `json:"stake_address"`
+	TokenStandard          string `json:"token_standard"`
+	Amount                 Amount `json:"amount"`
+	WeightedAmount         Amount `json:"weighted_amount"`
+	DurationInSec          int64  `json:"duration_in_sec"`
+	StartTimestamp         int64  `json:"start_timestamp"`
+	ExpirationTimestamp    int64  `json:"expiration_timestamp"`
+	RevokeTimestamp        int64  `json:"revoke_timestamp"`
+	IsActive               bool   `json:"is_active"`
+	CreationMomentumHeight int64  `json:"creation_momentum_height"`
+	CancelMomentumHeight   int64  `json:"cancel_momentum_height"`
+	UnlockMomentumHeight   int64  `json:"unlock_momentum_height"`
+}
+
+func FromLiquidityStake(s *models.LiquidityStake) *LiquidityStake {
+	if s == nil {
+		return nil
+	}
+	return &LiquidityStake{
+		ID:                     s.ID,
+		StakeAddress:           s.StakeAddress,
+		TokenStandard:          s.TokenStandard,
+		Amount:                 AmountFromBigInt(s.Amount),
+		WeightedAmount:         AmountFromBigInt(s.WeightedAmount),
+		DurationInSec:          s.DurationInSec,
+		StartTimestamp:         s.StartTimestamp,
+		ExpirationTimestamp:    s.ExpirationTimestamp,
+		RevokeTimestamp:        s.RevokeTimestamp,
+		IsActive:               s.IsActive,
+		CreationMomentumHeight: s.CreationMomentumHeight,
+		CancelMomentumHeight:   s.CancelMomentumHeight,
+		UnlockMomentumHeight:   s.UnlockMomentumHeight,
+	}
+}
+
+func FromLiquidityStakes(in []*models.LiquidityStake) []*LiquidityStake {
+	out := make([]*LiquidityStake, 0, len(in))
+	for _, s := range in {
+		if d := FromLiquidityStake(s); d != nil {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// LiquidityTokenTuple is one LP token the program accepts. The
+// percentages are the token's share of the ZNN and QSR rewards, out of
+// 10000.
+type LiquidityTokenTuple struct {
+	TokenStandard string `json:"token_standard"`
+	ZnnPercentage uint32 `json:"znn_percentage"`
+	QsrPercentage uint32 `json:"qsr_percentage"`
+	MinAmount     Amount `json:"min_amount"`
+}
+
+type LiquidityConfig struct {
+	Administrator        string                `json:"administrator"`
+	IsHalted             bool                  `json:"is_halted"`
+	ZnnReward            Amount                `json:"znn_reward"`
+	QsrReward            Amount                `json:"qsr_reward"`
+	TokenTuples          []LiquidityTokenTuple `json:"token_tuples"`
+	Guardians            []string              `json:"guardians"`
+	AdministratorDelay   int64                 `json:"administrator_delay"`
+	SoftDelay            int64                 `json:"soft_delay"`
+	LastUpdatedTimestamp int64                 `json:"last_updated_timestamp"`
+}
+
+func FromLiquidityConfig(c *models.LiquidityConfig) *LiquidityConfig {
+	if c == nil {
+		return nil
+	}
+	out := &LiquidityConfig{
+		Administrator:        c.Administrator,
+		IsHalted:             c.IsHalted,
+		ZnnReward:            AmountFromBigInt(c.ZnnReward),
+		QsrReward:            AmountFromBigInt(c.QsrReward),
+		TokenTuples:          make([]LiquidityTokenTuple, 0, len(c.TokenTuples)),
+		Guardians:            c.Guardians,
+		AdministratorDelay:   c.AdministratorDelay,
+		SoftDelay:            c.SoftDelay,
+		LastUpdatedTimestamp: c.LastUpdatedTimestamp,
+	}
+	if out.Guardians == nil {
+		out.Guardians = []string{}
+	}
+	for _, t := range c.TokenTuples {
+		out.TokenTuples = append(out.TokenTuples, LiquidityTokenTuple{
+			TokenStandard: t.TokenStandard,
+			ZnnPercentage: t.ZnnPercentage,
+			QsrPercentage: t.QsrPercentage,
+			MinAmount:     Amount(t.MinAmount),
+		})
+	}
+	return out
+}
+
+// LiquidityAdminAction is one administrator or guardian call into the
+// Liquidity contract, decoded from the ledger.
+type LiquidityAdminAction struct {
+	AccountBlockHash  string            `json:"account_block_hash"`
+	SendBlockHash     string            `json:"send_block_hash"`
+	Method            string            `json:"method"`
+	Address           string            `json:"address"`
+	TokenStandard     string            `json:"token_standard"`
+	Amount            Amount            `json:"amount"`
+	Inputs            map[string]string `json:"inputs"`
+	MomentumHeight    int64             `json:"momentum_height"`
+	MomentumTimestamp int64             `json:"momentum_timestamp"`
+}
+
+func FromLiquidityAdminAction(a *models.LiquidityAdminAction) *LiquidityAdminAction {
+	if a == nil {
+		return nil
+	}
+	inputs := a.Inputs
+	if inputs == nil {
+		inputs = map[string]string{}
+	}
+	return &LiquidityAdminAction{
+		AccountBlockHash:  a.AccountBlockHash,
+		SendBlockHash:     a.SendBlockHash,
+		Method:            a.Method,
+		Address:           a.Address,
+		TokenStandard:     a.TokenStandard,
+		Amount:            AmountFromBigInt(a.Amount),
+		Inputs:            inputs,
+		MomentumHeight:    a.MomentumHeight,
+		MomentumTimestamp: a.MomentumTimestamp,
+	}
+}
+
+func FromLiquidityAdminActions(in []*models.LiquidityAdminAction) []*LiquidityAdminAction {
+	out := make([]*LiquidityAdminAction, 0, len(in))
+	for _, a := range in {
+		if d := FromLiquidityAdminAction(a); d != nil {
+			out = append(out, d)
+		}
+	}
+	return out
+}
@@ -99,3 +99,126 @@ func FromProjectPhases(in []*models.ProjectPhase) []*ProjectPhase {
 	}
 	return out
 }
+
+// ProjectStatusChange is one status transition of a project or, when
+// PhaseID is set, of one of its phases. OldStatus is null on the row
+// that created the project or phase.
+type ProjectStatusChange struct {
+	AccountBlockHash  string `json:"account_block_hash"`
+	ProjectID         string `json:"project_id"`
+	PhaseID           string `json:"phase_id,omitempty"`
+	VotingID          string `json:"voting_id,omitempty"`
+	Method            string `json:"method"`
+	Address           string `json:"address"`
+	OldStatus         *int16 `json:"old_status"`
+	NewStatus         int16  `json:"new_status"`
+	ZnnAmount         Amount `json:"znn_amount"`
+	QsrAmount         Amount `json:"qsr_amount"`
+	MomentumHeight    int64  `json:"momentum_height"`
+	MomentumTimestamp int64  `json:"momentum_timestamp"`
+}
+
+func FromProjectStatusChange(c *models.ProjectStatusChange) *ProjectStatusChange {
+	if c == nil {
+		return nil
+	}
+	return &ProjectStatusChange{
+		AccountBlockHash:  c.AccountBlockHash,
+		ProjectID:         c.ProjectID,
+		PhaseID:           c.PhaseID,
+		VotingID:          c.VotingID,
+		Method:            c.Method,
+		Address:           c.Address,
+		OldStatus:         c.OldStatus,
+		NewStatus:         c.NewStatus,
+		ZnnAmount:         AmountFromBigInt(c.ZnnAmount),
+		QsrAmount:         AmountFromBigInt(c.QsrAmount),
+		MomentumHeight:    c.MomentumHeight,
+		MomentumTimestamp: c.MomentumTimestamp,
+	}
+}
+
+func FromProjectStatusChanges(in []*models.ProjectStatusChange) []*ProjectStatusChange {
+	out := make([]*ProjectStatusChange, 0, len(in))
+	for _, c := range in {
+		if d := FromProjectStatusChange(c); d != nil {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// AcceleratorPayout is one ZNN or QSR send paying out a phase.
+type AcceleratorPayout struct {
+	AccountBlockHash  string `json:"account_block_hash"`
+	UpdateBlockHash   string `json:"update_block_hash"`
+	ProjectID         string `json:"project_id"`
+	PhaseID           string `json:"phase_id"`
+	ToAddress         string `json:"to_address"`
+	TokenStandard     string `json:"token_standard"`
+	Amount            Amount `json:"amount"`
+	MomentumHeight    int64  `json:"momentum_height"`
+	MomentumTimestamp int64  `json:"momentum_timestamp"`
+}
+
+func FromAcceleratorPayout(p *models.AcceleratorPayout) *AcceleratorPayout {
+	if p == nil {
+		return nil
+	}
+	return &AcceleratorPayout{
+		AccountBlockHash:  p.AccountBlockHash,
+		UpdateBlockHash:   p.UpdateBlockHash,
+		ProjectID:         p.ProjectID,
+		PhaseID:           p.PhaseID,
+		ToAddress:         p.ToAddress,
+		TokenStandard:     p.TokenStandard,
+		Amount:            AmountFromBigInt(p.Amount),
+		MomentumHeight:    p.MomentumHeight,
+		MomentumTimestamp: p.MomentumTimestamp,
+	}
+}
+
+func FromAcceleratorPayouts(in []*models.AcceleratorPayout) []*AcceleratorPayout {
+	out := make([]*AcceleratorPayout, 0, len(in))
+	for _, p := range in {
+		if d := FromAcceleratorPayout(p); d != nil {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// AcceleratorTreasuryPoint is the Accelerator contract's balance after
+// one momentum, and how much that momentum moved it.
+type AcceleratorTreasuryPoint struct {
+	MomentumHeight    int64  `json:"momentum_height"`
+	MomentumTimestamp int64  `json:"momentum_timestamp"`
+	ZnnBalance        Amount `json:"znn_balance"`
+	QsrBalance        Amount `json:"qsr_balance"`
+	ZnnDelta          Amount `json:"znn_delta"`
+	QsrDelta          Amount `json:"qsr_delta"`
+}
+
+func FromAcceleratorTreasuryPoint(t *models.AcceleratorTreasuryPoint) *AcceleratorTreasuryPoint {
+	if t == nil {
+		return nil
+	}
+	return &AcceleratorTreasuryPoint{
+		MomentumHeight:    t.MomentumHeight,
+		MomentumTimestamp: t.MomentumTimestamp,
+		ZnnBalance:        AmountFromBigInt(t.ZnnBalance),
+		QsrBalance:        AmountFromBigInt(t.QsrBalance),
+		ZnnDelta:          AmountFromBigInt(t.ZnnDelta),
+		QsrDelta:          AmountFromBigInt(t.QsrDelta),
+	}
+}
+
+func FromAcceleratorTreasury(in []*models.AcceleratorTreasuryPoint) []*AcceleratorTreasuryPoint {
+	out := make([]*AcceleratorTreasuryPoint, 0, len(in))
+	for _, t := range in {
+		if d := FromAcceleratorTreasuryPoint(t); d != nil {
+			out = append(out, d)
+		}
+	}
+	return out
+}
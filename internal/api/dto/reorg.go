@@ -0,0 +1,18 @@
+package dto
+
+// ReorgFrameType is the Type value that marks a Reorg frame on the
+// momentum and transaction WebSocket streams. Data frames carry no
+// "type" key, so clients can branch on its presence.
+const ReorgFrameType = "reorg"
+
+// Reorg is pushed on the WebSocket streams when the indexer rolls back
+// a fork. Every height in (CommonAncestorHeight, OrphanedTipHeight] that
+// a client already received has been deleted; the replacement momentums
+// and account blocks follow as ordinary frames.
+type Reorg struct {
+	Type                 string `json:"type"`
+	CommonAncestorHeight uint64 `json:"common_ancestor_height"`
+	CommonAncestorHash   string `json:"common_ancestor_hash"`
+	OrphanedTipHeight    uint64 `json:"orphaned_tip_height"`
+	OrphanedTipHash      string `json:"orphaned_tip_hash"`
+}
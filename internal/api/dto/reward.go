@@ -18,7 +18,7 @@ func FromCumulativeReward(c *models.CumulativeReward) *CumulativeReward {
 	return &CumulativeReward{
 		Address:       c.Address,
 		RewardType:    c.RewardType.String(),
-		Amount:        AmountFromInt64(c.Amount),
+		Amount:        AmountFromBigInt(c.Amount),
 		TokenStandard: c.TokenStandard,
 	}
 }
@@ -57,7 +57,7 @@ func FromRewardTransaction(rt *models.RewardTransaction) *RewardTransaction {
 		MomentumTimestamp: rt.MomentumTimestamp,
 		MomentumHeight:    rt.MomentumHeight,
 		AccountHeight:     rt.AccountHeight,
-		Amount:            AmountFromInt64(rt.Amount),
+		Amount:            AmountFromBigInt(rt.Amount),
 		TokenStandard:     rt.TokenStandard,
 		SourceAddress:     rt.SourceAddress,
 	}
@@ -32,3 +32,44 @@ func FromSentinels(in []*models.Sentinel) []*Sentinel {
 	}
 	return out
 }
+
+// SentinelEvent is one accepted call into the Sentinel contract, with
+// the Register it belongs to.
+type SentinelEvent struct {
+	AccountBlockHash      string `json:"account_block_hash"`
+	SendBlockHash         string `json:"send_block_hash"`
+	Owner                 string `json:"owner"`
+	Method                string `json:"method"`
+	ZnnAmount             Amount `json:"znn_amount"`
+	QsrAmount             Amount `json:"qsr_amount"`
+	MomentumHeight        int64  `json:"momentum_height"`
+	MomentumTimestamp     int64  `json:"momentum_timestamp"`
+	RegistrationBlockHash string `json:"registration_block_hash"`
+}
+
+func FromSentinelEvent(e *models.SentinelEvent) *SentinelEvent {
+	if e == nil {
+		return nil
+	}
+	return &SentinelEvent{
+		AccountBlockHash:      e.AccountBlockHash,
+		SendBlockHash:         e.SendBlockHash,
+		Owner:                 e.Owner,
+		Method:                e.Method,
+		ZnnAmount:             AmountFromBigInt(e.ZnnAmount),
+		QsrAmount:             AmountFromBigInt(e.QsrAmount),
+		MomentumHeight:        e.MomentumHeight,
+		MomentumTimestamp:     e.MomentumTimestamp,
+		RegistrationBlockHash: e.RegistrationBlockHash,
+	}
+}
+
+func FromSentinelEvents(in []*models.SentinelEvent) []*SentinelEvent {
+	out := make([]*SentinelEvent, 0, len(in))
+	for _, e := range in {
+		if d := FromSentinelEvent(e); d != nil {
+			out = append(out, d)
+		}
+	}
+	return out
+}
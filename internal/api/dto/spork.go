@@ -0,0 +1,44 @@
+package dto
+
+import "github.com/0x3639/nom-indexer-go/internal/models"
+
+type Spork struct {
+	ID                          string `json:"id"`
+	Name                        string `json:"name"`
+	Description                 string `json:"description,omitempty"`
+	CreatorAddress              string `json:"creator_address"`
+	Activated                   bool   `json:"activated"`
+	EnforcementHeight           int64  `json:"enforcement_height"`
+	CreationMomentumHeight      int64  `json:"creation_momentum_height"`
+	CreationMomentumTimestamp   int64  `json:"creation_momentum_timestamp"`
+	ActivationMomentumHeight    int64  `json:"activation_momentum_height"`
+	ActivationMomentumTimestamp int64  `json:"activation_momentum_timestamp"`
+}
+
+func FromSpork(s *models.Spork) *Spork {
+	if s == nil {
+		return nil
+	}
+	return &Spork{
+		ID:                          s.ID,
+		Name:                        s.Name,
+		Description:                 s.Description,
+		CreatorAddress:              s.CreatorAddress,
+		Activated:                   s.Activated,
+		EnforcementHeight:           s.EnforcementHeight,
+		CreationMomentumHeight:      s.CreationMomentumHeight,
+		CreationMomentumTimestamp:   s.CreationMomentumTimestamp,
+		ActivationMomentumHeight:    s.ActivationMomentumHeight,
+		ActivationMomentumTimestamp: s.ActivationMomentumTimestamp,
+	}
+}
+
+func FromSporks(in []*models.Spork) []*Spork {
+	out := make([]*Spork, 0, len(in))
+	for _, s := range in {
+		if d := FromSpork(s); d != nil {
+			out = append(out, d)
+		}
+	}
+	return out
+}
@@ -22,7 +22,7 @@ func FromStake(s *models.Stake) *Stake {
 		Address:             s.Address,
 		StartTimestamp:      s.StartTimestamp,
 		ExpirationTimestamp: s.ExpirationTimestamp,
-		ZnnAmount:           AmountFromInt64(s.ZnnAmount),
+		ZnnAmount:           AmountFromBigInt(s.ZnnAmount),
 		DurationInSec:       s.DurationInSec,
 		IsActive:            s.IsActive,
 		CancelID:            s.CancelID,
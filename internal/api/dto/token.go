@@ -34,12 +34,12 @@ func FromToken(t *models.Token) *Token {
 		Domain:              t.Domain,
 		Decimals:            t.Decimals,
 		Owner:               t.Owner,
-		TotalSupply:         AmountFromInt64(t.TotalSupply),
-		MaxSupply:           AmountFromInt64(t.MaxSupply),
+		TotalSupply:         AmountFromBigInt(t.TotalSupply),
+		MaxSupply:           AmountFromBigInt(t.MaxSupply),
 		IsBurnable:          t.IsBurnable,
 		IsMintable:          t.IsMintable,
 		IsUtility:           t.IsUtility,
-		TotalBurned:         AmountFromInt64(t.TotalBurned),
+		TotalBurned:         AmountFromBigInt(t.TotalBurned),
 		LastUpdateTimestamp: t.LastUpdateTimestamp,
 		HolderCount:         t.HolderCount,
 		TransactionCount:    t.TransactionCount,
@@ -55,3 +55,60 @@ func FromTokens(in []*models.Token) []*Token {
 	}
 	return out
 }
+
+// TokenEvent is one change of a token's owner or flags, or its issue.
+// The *_before fields are null for IssueToken.
+type TokenEvent struct {
+	AccountBlockHash  string            `json:"account_block_hash"`
+	SendBlockHash     string            `json:"send_block_hash"`
+	TokenStandard     string            `json:"token_standard"`
+	Method            string            `json:"method"`
+	Address           string            `json:"address"`
+	AccountHeight     int64             `json:"account_height"`
+	OwnerBefore       *string           `json:"owner_before"`
+	OwnerAfter        string            `json:"owner_after"`
+	IsMintableBefore  *bool             `json:"is_mintable_before"`
+	IsMintableAfter   bool              `json:"is_mintable_after"`
+	IsBurnableBefore  *bool             `json:"is_burnable_before"`
+	IsBurnableAfter   bool              `json:"is_burnable_after"`
+	Inputs            map[string]string `json:"inputs"`
+	MomentumHeight    int64             `json:"momentum_height"`
+	MomentumTimestamp int64             `json:"momentum_timestamp"`
+}
+
+func FromTokenEvent(e *models.TokenEvent) *TokenEvent {
+	if e == nil {
+		return nil
+	}
+	inputs := e.Inputs
+	if inputs == nil {
+		inputs = map[string]string{}
+	}
+	return &TokenEvent{
+		AccountBlockHash:  e.AccountBlockHash,
+		SendBlockHash:     e.SendBlockHash,
+		TokenStandard:     e.TokenStandard,
+		Method:            e.Method,
+		Address:           e.Address,
+		AccountHeight:     e.AccountHeight,
+		OwnerBefore:       e.OwnerBefore,
+		OwnerAfter:        e.OwnerAfter,
+		IsMintableBefore:  e.IsMintableBefore,
+		IsMintableAfter:   e.IsMintableAfter,
+		IsBurnableBefore:  e.IsBurnableBefore,
+		IsBurnableAfter:   e.IsBurnableAfter,
+		Inputs:            inputs,
+		MomentumHeight:    e.MomentumHeight,
+		MomentumTimestamp: e.MomentumTimestamp,
+	}
+}
+
+func FromTokenEvents(in []*models.TokenEvent) []*TokenEvent {
+	out := make([]*TokenEvent, 0, len(in))
+	for _, e := range in {
+		if d := FromTokenEvent(e); d != nil {
+			out = append(out, d)
+		}
+	}
+	return out
+}
@@ -0,0 +1,144 @@
+package dto
+
+import "github.com/0x3639/nom-indexer-go/internal/models"
+
+// WebhookSubscription is a runtime-registered webhook endpoint. The
+// signing secret is never part of it; see WebhookSubscriptionSecret.
+// PreviousSecretExpiresAt is when the secret replaced by the last
+// rotation stops signing deliveries; 0 if the secret was never rotated.
+type WebhookSubscription struct {
+	ID                      int64         `json:"id"`
+	URL                     string        `json:"url"`
+	Events                  []string      `json:"events"`
+	Filter                  WebhookFilter `json:"filter"`
+	Description             string        `json:"description"`
+	Status                  string        `json:"status"`
+	CreatedAt               int64         `json:"created_at"`
+	UpdatedAt               int64         `json:"updated_at"`
+	SecretRotatedAt         int64         `json:"secret_rotated_at"`
+	PreviousSecretExpiresAt int64         `json:"previous_secret_expires_at"`
+}
+
+// WebhookFilter is a subscription's content filter. Empty fields are
+// omitted and do not filter; {} matches every event.
+type WebhookFilter struct {
+	Addresses      []string `json:"addresses,omitempty"`
+	TokenStandards []string `json:"token_standards,omitempty"`
+	Methods        []string `json:"methods,omitempty"`
+	BlockTypes     []int    `json:"block_types,omitempty"`
+	MinAmount      string   `json:"min_amount,omitempty"`
+}
+
+// ToWebhookFilter converts a request's filter for storage; nil is the
+// empty filter.
+func ToWebhookFilter(f *WebhookFilter) models.WebhookFilter {
+	if f == nil {
+		return models.WebhookFilter{}
+	}
+	return models.WebhookFilter{
+		Addresses:      f.Addresses,
+		TokenStandards: f.TokenStandards,
+		Methods:        f.Methods,
+		BlockTypes:     f.BlockTypes,
+		MinAmount:      f.MinAmount,
+	}
+}
+
+// WebhookSubscriptionSecret is returned by create and rotate-secret, the
+// only two responses that reveal the signing secret.
+type WebhookSubscriptionSecret struct {
+	WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+func FromWebhookSubscription(s *models.WebhookSubscription) *WebhookSubscription {
+	if s == nil {
+		return nil
+	}
+	events := s.Events
+	if events == nil {
+		events = []string{}
+	}
+	return &WebhookSubscription{
+		ID:     s.ID,
+		URL:    s.URL,
+		Events: events,
+		Filter: WebhookFilter{
+			Addresses:      s.Filter.Addresses,
+			TokenStandards: s.Filter.TokenStandards,
+			Methods:        s.Filter.Methods,
+			BlockTypes:     s.Filter.BlockTypes,
+			MinAmount:      s.Filter.MinAmount,
+		},
+		Description:             s.Description,
+		Status:                  s.Status,
+		CreatedAt:               s.CreatedAt,
+		UpdatedAt:               s.UpdatedAt,
+		SecretRotatedAt:         s.SecretRotatedAt,
+		PreviousSecretExpiresAt: s.PreviousSecretExpiresAt,
+	}
+}
+
+func FromWebhookSubscriptionWithSecret(s *models.WebhookSubscription) *WebhookSubscriptionSecret {
+	if s == nil {
+		return nil
+	}
+	return &WebhookSubscriptionSecret{
+		WebhookSubscription: *FromWebhookSubscription(s),
+		Secret:              s.Secret,
+	}
+}
+
+func FromWebhookSubscriptions(in []*models.WebhookSubscription) []*WebhookSubscription {
+	out := make([]*WebhookSubscription, 0, len(in))
+	for _, s := range in {
+		if d := FromWebhookSubscription(s); d != nil {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// WebhookDelivery is one entry of a subscription's delivery log: a single
+// HTTP attempt plus the current state of the event it carried.
+// DeliveryID is the id the subscriber received (envelope "id" and
+// X-Webhook-ID). StatusCode is omitted when no response was received.
+type WebhookDelivery struct {
+	OutboxID       int64   `json:"outbox_id"`
+	DeliveryID     string  `json:"delivery_id"`
+	EventType      string  `json:"event_type"`
+	MomentumHeight int64   `json:"momentum_height"`
+	Attempt        int     `json:"attempt"`
+	AttemptedAt    int64   `json:"attempted_at"`
+	StatusCode     *int    `json:"status_code,omitempty"`
+	Error          *string `json:"error,omitempty"`
+	DurationMs     int     `json:"duration_ms"`
+	DeliveryStatus string  `json:"delivery_status"`
+}
+
+func FromWebhookDeliveries(in []*models.WebhookDelivery) []*WebhookDelivery {
+	out := make([]*WebhookDelivery, 0, len(in))
+	for _, d := range in {
+		if d == nil {
+			continue
+		}
+		out = append(out, &WebhookDelivery{
+			OutboxID:       d.OutboxID,
+			DeliveryID:     d.DeliveryID,
+			EventType:      d.EventType,
+			MomentumHeight: d.MomentumHeight,
+			Attempt:        d.Attempt,
+			AttemptedAt:    d.AttemptedAt,
+			StatusCode:     d.StatusCode,
+			Error:          d.Error,
+			DurationMs:     d.DurationMs,
+			DeliveryStatus: d.Status,
+		})
+	}
+	return out
+}
+
+// WebhookTestEvent acknowledges a queued test delivery.
+type WebhookTestEvent struct {
+	OutboxID int64 `json:"outbox_id"`
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/0x3639/nom-indexer-go/internal/api/dto"
+	"github.com/0x3639/nom-indexer-go/internal/api/httpx"
+	"github.com/0x3639/nom-indexer-go/internal/models"
+	"github.com/0x3639/nom-indexer-go/internal/repository"
+)
+
+type acceleratorRepo interface {
+	History(ctx context.Context, projectID string, opts repository.ListOpts) ([]*models.ProjectStatusChange, int64, error)
+	ListPayouts(ctx context.Context, f repository.AcceleratorPayoutFilter, opts repository.ListOpts) ([]*models.AcceleratorPayout, int64, error)
+	Treasury(ctx context.Context, opts repository.ListOpts) ([]*models.AcceleratorTreasuryPoint, int64, error)
+}
+
+// ProjectsHistory handles GET /api/v1/projects/{id}/history: every
+// status change of the project and its phases, newest first.
+func ProjectsHistory(repo acceleratorRepo) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		if id == "" {
+			httpx.WriteProblem(w, http.StatusBadRequest, "invalid_id", "id is required")
+			return
+		}
+		p := httpx.ParsePagination(r)
+		rows, total, err := repo.History(r.Context(), id, repository.ListOpts{
+			Limit: p.PageSize, Offset: p.Offset(),
+		})
+		if err != nil {
+			writeRepoError(w, err)
+			return
+		}
+		httpx.WriteJSON(w, http.StatusOK,
+			dto.NewPage(dto.FromProjectStatusChanges(rows), p.Page, p.PageSize, total))
+	}
+}
+
+// AcceleratorPayouts handles GET /api/v1/accelerator/payouts, optionally
+// filtered by ?project_id= and ?address= (the receiving owner).
+func AcceleratorPayouts(repo acceleratorRepo) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p := httpx.ParsePagination(r)
+		q := r.URL.Query()
+		rows, total, err := repo.ListPayouts(r.Context(), repository.AcceleratorPayoutFilter{
+			ProjectID: q.Get("project_id"), ToAddress: q.Get("address"),
+		}, repository.ListOpts{Limit: p.PageSize, Offset: p.Offset()})
+		if err != nil {
+			writeRepoError(w, err)
+			return
+		}
+		httpx.WriteJSON(w, http.StatusOK,
+			dto.NewPage(dto.FromAcceleratorPayouts(rows), p.Page, p.PageSize, total))
+	}
+}
+
+// AcceleratorTreasury handles GET /api/v1/accelerator/treasury: the
+// contract's ZNN and QSR balance after every momentum that moved it,
+// newest first.
+func AcceleratorTreasury(repo acceleratorRepo) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p := httpx.ParsePagination(r)
+		rows, total, err := repo.Treasury(r.Context(), repository.ListOpts{
+			Limit: p.PageSize, Offset: p.Offset(),
+		})
+		if err != nil {
+			writeRepoError(w, err)
+			return
+		}
+		httpx.WriteJSON(w, http.StatusOK,
+			dto.NewPage(dto.FromAcceleratorTreasury(rows), p.Page, p.PageSize, total))
+	}
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+	"github.com/0x3639/nom-indexer-go/internal/repository"
+)
+
+type fakeAcceleratorRepo struct {
+	lastProjectID string
+	lastFilter    repository.AcceleratorPayoutFilter
+}
+
+func (f *fakeAcceleratorRepo) History(_ context.Context, projectID string, _ repository.ListOpts) ([]*models.ProjectStatusChange, int64, error) {
+	f.lastProjectID = projectID
+	active := int16(models.ProjectStatusVoting)
+	return []*models.ProjectStatusChange{
+		{ProjectID: projectID, Method: "Update", OldStatus: &active, NewStatus: int16(models.ProjectStatusActive),
+			ZnnAmount: big.NewInt(0), QsrAmount: big.NewInt(0)},
+		{ProjectID: projectID, Method: "CreateProject", ZnnAmount: big.NewInt(500), QsrAmount: big.NewInt(5000)},
+	}, 2, nil
+}
+func (f *fakeAcceleratorRepo) ListPayouts(_ context.Context, filter repository.AcceleratorPayoutFilter, _ repository.ListOpts) ([]*models.AcceleratorPayout, int64, error) {
+	f.lastFilter = filter
+	return []*models.AcceleratorPayout{{PhaseID: "ph1", Amount: big.NewInt(200)}}, 1, nil
+}
+func (f *fakeAcceleratorRepo) Treasury(context.Context, repository.ListOpts) ([]*models.AcceleratorTreasuryPoint, int64, error) {
+	return []*models.AcceleratorTreasuryPoint{{MomentumHeight: 9, ZnnBalance: big.NewInt(700), ZnnDelta: big.NewInt(-300)}}, 1, nil
+}
+
+func TestAcceleratorHandlers(t *testing.T) {
+	repo := &fakeAcceleratorRepo{}
+	r := chi.NewRouter()
+	r.Get("/api/v1/projects/{id}/history", ProjectsHistory(repo))
+	r.Get("/api/v1/accelerator/payouts", AcceleratorPayouts(repo))
+	r.Get("/api/v1/accelerator/treasury", AcceleratorTreasury(repo))
+
+	for _, tc := range []struct {
+		path string
+		want []string
+	}{
+		{"/api/v1/projects/p1/history", []string{`"old_status":0,"new_status":1`, `"old_status":null`, `"znn_amount":"500"`, `"total":2`}},
+		{"/api/v1/accelerator/payouts?project_id=p1&address=z1qa", []string{`"phase_id":"ph1"`, `"amount":"200"`}},
+		{"/api/v1/accelerator/treasury", []string{`"znn_balance":"700"`, `"znn_delta":"-300"`, `"qsr_balance":"0"`}},
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d", tc.path, w.Code)
+		}
+		for _, want := range tc.want {
+			if !strings.Contains(w.Body.String(), want) {
+				t.Errorf("%s: missing %s in %s", tc.path, want, w.Body.String())
+			}
+		}
+	}
+	if repo.lastProjectID != "p1" {
+		t.Errorf("history project = %q, want p1", repo.lastProjectID)
+	}
+	if repo.lastFilter.ProjectID != "p1" || repo.lastFilter.ToAddress != "z1qa" {
+		t.Errorf("payout filter = %+v, want p1 to z1qa", repo.lastFilter)
+	}
+}
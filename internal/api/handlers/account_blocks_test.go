@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -41,7 +42,7 @@ func (f *fakeAccountBlocksRepo) GetByHash(_ context.Context, h string) (*models.
 
 func TestAccountBlocksList(t *testing.T) {
 	repo := &fakeAccountBlocksRepo{
-		list:  []*models.AccountBlock{{Hash: "abc", Amount: 12345}},
+		list:  []*models.AccountBlock{{Hash: "abc", Amount: big.NewInt(12345)}},
 		total: 1,
 	}
 	w := httptest.NewRecorder()
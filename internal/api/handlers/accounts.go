@@ -3,12 +3,14 @@ package handlers
 import (
 	"context"
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/chi/v5"
 
 	"github.com/0x3639/nom-indexer-go/internal/api/dto"
 	"github.com/0x3639/nom-indexer-go/internal/api/httpx"
 	"github.com/0x3639/nom-indexer-go/internal/models"
+	"github.com/0x3639/nom-indexer-go/internal/repository"
 )
 
 type accountsRepo interface {
@@ -17,6 +19,20 @@ type accountsRepo interface {
 
 type accountBalancesRepo interface {
 	ListByAddress(ctx context.Context, address string) ([]*models.Balance, error)
+	ListByAddressAtHeight(ctx context.Context, address string, height uint64) ([]*models.Balance, error)
+	History(ctx context.Context, address string, f repository.BalanceHistoryFilter, opts repository.ListOpts) ([]*models.BalanceChange, int64, error)
+}
+
+// heightQuery reads a momentum-height query param. ok is false when the
+// param is absent; a value that is not a non-negative integer is an
+// error.
+func heightQuery(r *http.Request, name string) (height uint64, ok bool, err error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return 0, false, nil
+	}
+	height, err = strconv.ParseUint(v, 10, 64)
+	return height, err == nil, err
 }
 
 // AccountsGet handles GET /api/v1/accounts/{address}.
@@ -38,7 +54,8 @@ func AccountsGet(repo accountsRepo) http.HandlerFunc {
 
 // AccountsBalances handles GET /api/v1/accounts/{address}/balances.
 // Returns every (token, amount) row for the address. No pagination —
-// accounts typically hold a handful of tokens.
+// accounts typically hold a handful of tokens. With ?at_height= the
+// balances are those after that momentum, from balance_history.
 func AccountsBalances(repo accountBalancesRepo) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		addr := chi.URLParam(r, "address")
@@ -46,7 +63,18 @@ func AccountsBalances(repo accountBalancesRepo) http.HandlerFunc {
 			httpx.WriteProblem(w, http.StatusBadRequest, "invalid_address", "address is required")
 			return
 		}
-		rows, err := repo.ListByAddress(r.Context(), addr)
+		height, atHeight, err := heightQuery(r, "at_height")
+		if err != nil {
+			httpx.WriteProblem(w, http.StatusBadRequest, "invalid_height",
+				"at_height must be a non-negative integer")
+			return
+		}
+		var rows []*models.Balance
+		if atHeight {
+			rows, err = repo.ListByAddressAtHeight(r.Context(), addr, height)
+		} else {
+			rows, err = repo.ListByAddress(r.Context(), addr)
+		}
 		if err != nil {
 			writeRepoError(w, err)
 			return
@@ -56,3 +84,40 @@ func AccountsBalances(repo accountBalancesRepo) http.HandlerFunc {
 		})
 	}
 }
+
+// AccountsBalanceHistory handles GET
+// /api/v1/accounts/{address}/balances/history: one row per momentum that
+// changed one of the address's balances, newest first. Optional ?token=
+// narrows it to one token standard; ?from= and ?to= bound the momentum
+// height, both inclusive.
+func AccountsBalanceHistory(repo accountBalancesRepo) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		addr := chi.URLParam(r, "address")
+		if addr == "" {
+			httpx.WriteProblem(w, http.StatusBadRequest, "invalid_address", "address is required")
+			return
+		}
+		from, _, err := heightQuery(r, "from")
+		if err != nil {
+			httpx.WriteProblem(w, http.StatusBadRequest, "invalid_height",
+				"from must be a non-negative integer")
+			return
+		}
+		to, _, err := heightQuery(r, "to")
+		if err != nil {
+			httpx.WriteProblem(w, http.StatusBadRequest, "invalid_height",
+				"to must be a non-negative integer")
+			return
+		}
+		p := httpx.ParsePagination(r)
+		rows, total, err := repo.History(r.Context(), addr, repository.BalanceHistoryFilter{
+			TokenStandard: r.URL.Query().Get("token"), FromHeight: from, ToHeight: to,
+		}, repository.ListOpts{Limit: p.PageSize, Offset: p.Offset()})
+		if err != nil {
+			writeRepoError(w, err)
+			return
+		}
+		httpx.WriteJSON(w, http.StatusOK,
+			dto.NewPage(dto.FromBalanceChanges(rows), p.Page, p.PageSize, total))
+	}
+}
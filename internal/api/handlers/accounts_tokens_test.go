@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -26,13 +27,26 @@ func (f *fakeAccountsRepo) GetByAddress(_ context.Context, a string) (*models.Ac
 }
 
 type fakeAccountBalancesRepo struct {
-	byAddr map[string][]*models.Balance
+	byAddr    map[string][]*models.Balance
+	atHeight  uint64
+	history   []*models.BalanceChange
+	gotFilter repository.BalanceHistoryFilter
 }
 
 func (f *fakeAccountBalancesRepo) ListByAddress(_ context.Context, a string) ([]*models.Balance, error) {
 	return f.byAddr[a], nil
 }
 
+func (f *fakeAccountBalancesRepo) ListByAddressAtHeight(_ context.Context, a string, height uint64) ([]*models.Balance, error) {
+	f.atHeight = height
+	return []*models.Balance{{Address: a, TokenStandard: "zts1znn", Balance: big.NewInt(42)}}, nil
+}
+
+func (f *fakeAccountBalancesRepo) History(_ context.Context, _ string, flt repository.BalanceHistoryFilter, _ repository.ListOpts) ([]*models.BalanceChange, int64, error) {
+	f.gotFilter = flt
+	return f.history, int64(len(f.history)), nil
+}
+
 type fakeTokensRepo struct {
 	list   []*models.Token
 	total  int64
@@ -51,6 +65,16 @@ func (f *fakeTokensRepo) GetByStandard(_ context.Context, s string) (*models.Tok
 	return nil, pgx.ErrNoRows
 }
 
+type fakeTokenHistoryRepo struct {
+	rows   []*models.TokenEvent
+	lastTS string
+}
+
+func (f *fakeTokenHistoryRepo) History(_ context.Context, ts string, _ repository.ListOpts) ([]*models.TokenEvent, int64, error) {
+	f.lastTS = ts
+	return f.rows, int64(len(f.rows)), nil
+}
+
 type fakeTokenHoldersRepo struct {
 	rows   []*models.Balance
 	total  int64
@@ -137,8 +161,8 @@ func TestAccountsGet(t *testing.T) {
 func TestAccountsBalances(t *testing.T) {
 	repo := &fakeAccountBalancesRepo{byAddr: map[string][]*models.Balance{
 		"z1qq": {
-			{Address: "z1qq", TokenStandard: "zts1znn", Balance: 100},
-			{Address: "z1qq", TokenStandard: "zts1qsr", Balance: 200},
+			{Address: "z1qq", TokenStandard: "zts1znn", Balance: big.NewInt(100)},
+			{Address: "z1qq", TokenStandard: "zts1qsr", Balance: big.NewInt(200)},
 		},
 	}}
 	r := chi.NewRouter()
@@ -161,6 +185,56 @@ func TestAccountsBalances(t *testing.T) {
 	}
 }
 
+func TestAccountsBalances_AtHeight(t *testing.T) {
+	repo := &fakeAccountBalancesRepo{}
+	r := chi.NewRouter()
+	r.Get("/api/v1/accounts/{address}/balances", AccountsBalances(repo))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/accounts/z1qq/balances?at_height=1234", nil))
+	if w.Code != http.StatusOK || repo.atHeight != 1234 {
+		t.Fatalf("status = %d, height = %d", w.Code, repo.atHeight)
+	}
+	if !strings.Contains(w.Body.String(), `"balance":"42"`) {
+		t.Errorf("missing balance in %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/accounts/z1qq/balances?at_height=-1", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("negative at_height status = %d, want 400", w.Code)
+	}
+}
+
+func TestAccountsBalanceHistory(t *testing.T) {
+	repo := &fakeAccountBalancesRepo{history: []*models.BalanceChange{{
+		Address: "z1qq", TokenStandard: "zts1znn", MomentumHeight: 7, MomentumTimestamp: 700,
+		Balance: big.NewInt(70), Delta: big.NewInt(-30),
+	}}}
+	r := chi.NewRouter()
+	r.Get("/api/v1/accounts/{address}/balances/history", AccountsBalanceHistory(repo))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet,
+		"/api/v1/accounts/z1qq/balances/history?token=zts1znn&from=5&to=9", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	if want := (repository.BalanceHistoryFilter{TokenStandard: "zts1znn", FromHeight: 5, ToHeight: 9}); repo.gotFilter != want {
+		t.Errorf("filter = %+v, want %+v", repo.gotFilter, want)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, `"delta":"-30"`) || !strings.Contains(body, `"total":1`) {
+		t.Errorf("unexpected body %s", body)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/accounts/z1qq/balances/history?from=x", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("bad from status = %d, want 400", w.Code)
+	}
+}
+
 func TestTokensList(t *testing.T) {
 	repo := &fakeTokensRepo{
 		list:  []*models.Token{{TokenStandard: "zts1znn", Name: "ZNN", Symbol: "ZNN"}},
@@ -181,7 +255,7 @@ func TestTokensList(t *testing.T) {
 
 func TestTokensGet(t *testing.T) {
 	repo := &fakeTokensRepo{byStd: map[string]*models.Token{
-		"zts1znn": {TokenStandard: "zts1znn", Symbol: "ZNN", TotalSupply: 9_000_000_000_000_000_00},
+		"zts1znn": {TokenStandard: "zts1znn", Symbol: "ZNN", TotalSupply: big.NewInt(9_000_000_000_000_000_00)},
 	}}
 	r := chi.NewRouter()
 	r.Get("/api/v1/tokens/{token_standard}", TokensGet(repo))
@@ -205,7 +279,7 @@ func TestTokensGet(t *testing.T) {
 
 func TestTokensHolders(t *testing.T) {
 	repo := &fakeTokenHoldersRepo{
-		rows:  []*models.Balance{{Address: "z1qq", TokenStandard: "zts1znn", Balance: 5}},
+		rows:  []*models.Balance{{Address: "z1qq", TokenStandard: "zts1znn", Balance: big.NewInt(5)}},
 		total: 1,
 	}
 	r := chi.NewRouter()
@@ -220,3 +294,28 @@ func TestTokensHolders(t *testing.T) {
 		t.Errorf("repo state = %q %+v", repo.lastTS, repo.lastOp)
 	}
 }
+
+func TestTokensHistory(t *testing.T) {
+	owner, mintable := "z1qa", true
+	repo := &fakeTokenHistoryRepo{rows: []*models.TokenEvent{
+		{AccountBlockHash: "r2", TokenStandard: "zts1abc", Method: "UpdateToken",
+			OwnerBefore: &owner, OwnerAfter: "z1qb", IsMintableBefore: &mintable},
+		{AccountBlockHash: "r1", TokenStandard: "zts1abc", Method: "IssueToken", OwnerAfter: "z1qa"},
+	}}
+	r := chi.NewRouter()
+	r.Get("/api/v1/tokens/{token_standard}/history", TokensHistory(repo))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/tokens/zts1abc/history", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	if repo.lastTS != "zts1abc" {
+		t.Errorf("token = %q", repo.lastTS)
+	}
+	for _, want := range []string{`"owner_before":"z1qa"`, `"owner_before":null`, `"is_mintable_before":true`, `"inputs":{}`, `"total":2`} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("missing %s in %s", want, w.Body.String())
+		}
+	}
+}
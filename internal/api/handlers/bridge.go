@@ -92,3 +92,26 @@ func BridgeUnwrapsByAddress(repo bridgeRepo) http.HandlerFunc {
 			dto.NewPage(dto.FromUnwrapTokenRequests(rows), p.Page, p.PageSize, total))
 	}
 }
+
+type bridgeEventsRepo interface {
+	List(ctx context.Context, f repository.BridgeEventFilter, opts repository.ListOpts) ([]*models.BridgeEvent, int64, error)
+}
+
+// BridgeEvents handles GET /api/v1/bridge/events: every call into the
+// Bridge contract, newest first. ?method= and ?address= (the caller)
+// narrow the list.
+func BridgeEvents(repo bridgeEventsRepo) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p := httpx.ParsePagination(r)
+		q := r.URL.Query()
+		rows, total, err := repo.List(r.Context(), repository.BridgeEventFilter{
+			Method: q.Get("method"), Address: q.Get("address"),
+		}, repository.ListOpts{Limit: p.PageSize, Offset: p.Offset()})
+		if err != nil {
+			writeRepoError(w, err)
+			return
+		}
+		httpx.WriteJSON(w, http.StatusOK,
+			dto.NewPage(dto.FromBridgeEvents(rows), p.Page, p.PageSize, total))
+	}
+}
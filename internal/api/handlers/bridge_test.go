@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -42,7 +43,7 @@ func (f *fakeBridgeRepo) ListUnwrapsByAddress(_ context.Context, addr string, _
 }
 
 func TestBridgeWraps(t *testing.T) {
-	repo := &fakeBridgeRepo{wraps: []*models.WrapTokenRequest{{ID: "w1", Amount: 1_000_000_000_000}}, wrapsTotal: 1}
+	repo := &fakeBridgeRepo{wraps: []*models.WrapTokenRequest{{ID: "w1", Amount: big.NewInt(1_000_000_000_000)}}, wrapsTotal: 1}
 	w := httptest.NewRecorder()
 	BridgeWraps(repo)(w, httptest.NewRequest(http.MethodGet, "/api/v1/bridge/wraps", nil))
 	if w.Code != http.StatusOK {
@@ -83,3 +84,32 @@ func TestBridgeByAddress(t *testing.T) {
 		t.Errorf("unwraps state: code=%d addr=%q", w.Code, repo.lastUnwrapAddr)
 	}
 }
+
+type fakeBridgeEventsRepo struct {
+	events     []*models.BridgeEvent
+	lastFilter repository.BridgeEventFilter
+}
+
+func (f *fakeBridgeEventsRepo) List(_ context.Context, filter repository.BridgeEventFilter, _ repository.ListOpts) ([]*models.BridgeEvent, int64, error) {
+	f.lastFilter = filter
+	return f.events, int64(len(f.events)), nil
+}
+
+func TestBridgeEvents(t *testing.T) {
+	repo := &fakeBridgeEventsRepo{events: []*models.BridgeEvent{
+		{AccountBlockHash: "r1", Method: "Halt", Address: "z1qadmin"},
+	}}
+	w := httptest.NewRecorder()
+	BridgeEvents(repo)(w, httptest.NewRequest(http.MethodGet, "/api/v1/bridge/events?method=Halt&address=z1qadmin", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	if repo.lastFilter.Method != "Halt" || repo.lastFilter.Address != "z1qadmin" {
+		t.Errorf("filter = %+v, want Halt by z1qadmin", repo.lastFilter)
+	}
+	for _, want := range []string{`"method":"Halt"`, `"amount":"0"`, `"inputs":{}`, `"total":1`} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("missing %s in %s", want, w.Body.String())
+		}
+	}
+}
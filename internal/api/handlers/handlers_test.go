@@ -94,6 +94,47 @@ func TestStatus_DBError(t *testing.T) {
 	}
 }
 
+type fakeFailedHeightsRepo struct {
+	rows     []*models.FailedHeight
+	total    int64
+	lastOpts repository.ListOpts
+}
+
+func (f *fakeFailedHeightsRepo) List(_ context.Context, opts repository.ListOpts) ([]*models.FailedHeight, int64, error) {
+	f.lastOpts = opts
+	return f.rows, f.total, nil
+}
+
+func TestFailedHeightsList(t *testing.T) {
+	repo := &fakeFailedHeightsRepo{
+		rows:  []*models.FailedHeight{{Height: 7, LastError: "timeout", Attempts: 2, NextRetryAt: 1700000120}},
+		total: 1,
+	}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/failed-heights", nil)
+	FailedHeightsList(repo)(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	if repo.lastOpts.Sort != "asc" {
+		t.Errorf("default sort = %q, want asc", repo.lastOpts.Sort)
+	}
+	body := w.Body.String()
+	for _, want := range []string{`"height":7`, `"last_error":"timeout"`, `"attempts":2`, `"next_retry_at":1700000120`, `"total":1`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %s: %s", want, body)
+		}
+	}
+
+	repo.rows = nil
+	w = httptest.NewRecorder()
+	FailedHeightsList(repo)(w, r)
+	if !strings.Contains(w.Body.String(), `"data":[]`) {
+		t.Errorf("empty queue body = %s, want data []", w.Body.String())
+	}
+}
+
 func TestMomentumsList_Envelope(t *testing.T) {
 	repo := &fakeMomentumRepo{
 		listResult: []*models.Momentum{
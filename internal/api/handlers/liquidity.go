@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/0x3639/nom-indexer-go/internal/api/dto"
+	"github.com/0x3639/nom-indexer-go/internal/api/httpx"
+	"github.com/0x3639/nom-indexer-go/internal/models"
+	"github.com/0x3639/nom-indexer-go/internal/repository"
+)
+
+type liquidityRepo interface {
+	ListStakes(ctx context.Context, address string, activeOnly bool, opts repository.ListOpts) ([]*models.LiquidityStake, int64, error)
+	GetConfig(ctx context.Context) (*models.LiquidityConfig, error)
+	ListAdminActions(ctx context.Context, method string, opts repository.ListOpts) ([]*models.LiquidityAdminAction, int64, error)
+}
+
+// LiquidityConfigGet handles GET /api/v1/liquidity/config: the program's
+// administrator, reward amounts, LP token tuples and guardians, as last
+// synced from the node. 404 until the first sync.
+func LiquidityConfigGet(repo liquidityRepo) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		c, err := repo.GetConfig(r.Context())
+		if err != nil {
+			writeRepoError(w, err)
+			return
+		}
+		httpx.WriteJSON(w, http.StatusOK, dto.FromLiquidityConfig(c))
+	}
+}
+
+// LiquidityAdminActions handles GET /api/v1/liquidity/admin-actions:
+// every administrator and guardian call into the Liquidity contract,
+// newest first, including calls the contract rejected or that are still
+// waiting out their time challenge. ?method= narrows the list.
+func LiquidityAdminActions(repo liquidityRepo) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p := httpx.ParsePagination(r)
+		rows, total, err := repo.ListAdminActions(r.Context(), r.URL.Query().Get("method"), repository.ListOpts{
+			Limit: p.PageSize, Offset: p.Offset(),
+		})
+		if err != nil {
+			writeRepoError(w, err)
+			return
+		}
+		httpx.WriteJSON(w, http.StatusOK,
+			dto.NewPage(dto.FromLiquidityAdminActions(rows), p.Page, p.PageSize, total))
+	}
+}
+
+// LiquidityStakesList handles GET /api/v1/liquidity/stakes. Active
+// entries only by default.
+func LiquidityStakesList(repo liquidityRepo) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p := httpx.ParsePagination(r)
+		activeOnly := !boolQuery(r, "include_inactive", false)
+		rows, total, err := repo.ListStakes(r.Context(), "", activeOnly, repository.ListOpts{
+			Limit: p.PageSize, Offset: p.Offset(),
+		})
+		if err != nil {
+			writeRepoError(w, err)
+			return
+		}
+		httpx.WriteJSON(w, http.StatusOK,
+			dto.NewPage(dto.FromLiquidityStakes(rows), p.Page, p.PageSize, total))
+	}
+}
+
+// LiquidityStakesByAddress handles GET
+// /api/v1/accounts/{address}/liquidity/stakes.
+func LiquidityStakesByAddress(repo liquidityRepo) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		addr := chi.URLParam(r, "address")
+		if addr == "" {
+			httpx.WriteProblem(w, http.StatusBadRequest, "invalid_address", "address is required")
+			return
+		}
+		p := httpx.ParsePagination(r)
+		activeOnly := !boolQuery(r, "include_inactive", false)
+		rows, total, err := repo.ListStakes(r.Context(), addr, activeOnly, repository.ListOpts{
+			Limit: p.PageSize, Offset: p.Offset(),
+		})
+		if err != nil {
+			writeRepoError(w, err)
+			return
+		}
+		httpx.WriteJSON(w, http.StatusOK,
+			dto.NewPage(dto.FromLiquidityStakes(rows), p.Page, p.PageSize, total))
+	}
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+	"github.com/0x3639/nom-indexer-go/internal/repository"
+)
+
+type fakeLiquidityRepo struct {
+	stakes         []*models.LiquidityStake
+	config         *models.LiquidityConfig
+	actions        []*models.LiquidityAdminAction
+	lastAddress    string
+	lastActiveOnly bool
+	lastMethod     string
+}
+
+func (f *fakeLiquidityRepo) ListStakes(_ context.Context, address string, activeOnly bool, _ repository.ListOpts) ([]*models.LiquidityStake, int64, error) {
+	f.lastAddress = address
+	f.lastActiveOnly = activeOnly
+	return f.stakes, int64(len(f.stakes)), nil
+}
+func (f *fakeLiquidityRepo) GetConfig(context.Context) (*models.LiquidityConfig, error) {
+	if f.config == nil {
+		return nil, fmt.Errorf("LiquidityRepository.GetConfig: %w", pgx.ErrNoRows)
+	}
+	return f.config, nil
+}
+
+func (f *fakeLiquidityRepo) ListAdminActions(_ context.Context, method string, _ repository.ListOpts) ([]*models.LiquidityAdminAction, int64, error) {
+	f.lastMethod = method
+	return f.actions, int64(len(f.actions)), nil
+}
+
+func TestLiquidityStakes(t *testing.T) {
+	repo := &fakeLiquidityRepo{stakes: []*models.LiquidityStake{
+		{ID: "l1", StakeAddress: "z1qa", Amount: big.NewInt(10), WeightedAmount: big.NewInt(30), IsActive: true},
+	}}
+	r := chi.NewRouter()
+	r.Get("/api/v1/liquidity/stakes", LiquidityStakesList(repo))
+	r.Get("/api/v1/accounts/{address}/liquidity/stakes", LiquidityStakesByAddress(repo))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/liquidity/stakes", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	if repo.lastAddress != "" || !repo.lastActiveOnly {
+		t.Errorf("list: address %q, activeOnly %v; want all addresses, active only", repo.lastAddress, repo.lastActiveOnly)
+	}
+	for _, want := range []string{`"amount":"10"`, `"weighted_amount":"30"`, `"total":1`} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("missing %s in %s", want, w.Body.String())
+		}
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/accounts/z1qa/liquidity/stakes?include_inactive=true", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	if repo.lastAddress != "z1qa" || repo.lastActiveOnly {
+		t.Errorf("by address: address %q, activeOnly %v; want z1qa, inactive included", repo.lastAddress, repo.lastActiveOnly)
+	}
+}
+
+func TestLiquidityConfigGet(t *testing.T) {
+	repo := &fakeLiquidityRepo{}
+	w := httptest.NewRecorder()
+	LiquidityConfigGet(repo)(w, httptest.NewRequest(http.MethodGet, "/api/v1/liquidity/config", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("before sync status = %d, want 404", w.Code)
+	}
+
+	repo.config = &models.LiquidityConfig{
+		Administrator: "z1qadmin", ZnnReward: big.NewInt(5),
+		TokenTuples: []models.LiquidityTokenTuple{{TokenStandard: "zts1lp", ZnnPercentage: 10000, MinAmount: "100"}},
+	}
+	w = httptest.NewRecorder()
+	LiquidityConfigGet(repo)(w, httptest.NewRequest(http.MethodGet, "/api/v1/liquidity/config", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	for _, want := range []string{`"znn_reward":"5"`, `"qsr_reward":"0"`, `"znn_percentage":10000`, `"min_amount":"100"`, `"guardians":[]`} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("missing %s in %s", want, w.Body.String())
+		}
+	}
+}
+
+func TestLiquidityAdminActions(t *testing.T) {
+	repo := &fakeLiquidityRepo{actions: []*models.LiquidityAdminAction{
+		{AccountBlockHash: "r1", Method: "SetIsHalted", Address: "z1qadmin",
+			Inputs: map[string]string{"isHalted": "true"}, MomentumHeight: 7},
+		{AccountBlockHash: "r0", Method: "Emergency", Address: "z1qadmin", MomentumHeight: 5},
+	}}
+	w := httptest.NewRecorder()
+	LiquidityAdminActions(repo)(w, httptest.NewRequest(http.MethodGet, "/api/v1/liquidity/admin-actions?method=SetIsHalted", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	if repo.lastMethod != "SetIsHalted" {
+		t.Errorf("method filter = %q, want SetIsHalted", repo.lastMethod)
+	}
+	for _, want := range []string{`"method":"SetIsHalted"`, `"inputs":{"isHalted":"true"}`, `"inputs":{}`, `"amount":"0"`, `"total":2`} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("missing %s in %s", want, w.Body.String())
+		}
+	}
+}
@@ -62,9 +62,12 @@ const (
 //     height before switching to live. Capped at streamReplayMaxRows
 //     to bound the catch-up scan.
 //
-// Frames: one JSON object per momentum (matches dto.Momentum). The
-// server never reads from the client — opening the connection is the
-// only protocol input.
+// Frames: one JSON object per momentum (matches dto.Momentum). When
+// reorgHub is configured, a dto.Reorg frame ({"type":"reorg",...}) is
+// pushed whenever the indexer rolls back a fork, and the re-indexed
+// momentums above the common ancestor are streamed again. The server
+// never reads from the client — opening the connection is the only
+// protocol input.
 //
 // Close codes used:
 //   - 1000 normal: server shutdown or client disconnect.
@@ -74,7 +77,12 @@ const (
 //     with from_height of the last momentum it saw.
 //
 //nolint:contextcheck // WS connection lifecycle is detached from r.Context by design — see body comment
-func MomentumsStream(signer *auth.Signer, hub *stream.Hub[*dto.Momentum], repo streamMomentumsRepo) http.HandlerFunc {
+func MomentumsStream(
+	signer *auth.Signer,
+	hub *stream.Hub[*dto.Momentum],
+	reorgHub *stream.Hub[*dto.Reorg],
+	repo streamMomentumsRepo,
+) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// 1. Auth — header preferred, query-param fallback for browsers.
 		tok, codeStr := streamToken(r)
@@ -129,6 +137,8 @@ func MomentumsStream(signer *auth.Signer, hub *stream.Hub[*dto.Momentum], repo s
 			return
 		}
 		defer sub.Close()
+		reorgs, closeReorgs := subscribeReorgs(reorgHub, subject)
+		defer closeReorgs()
 
 		// 4. Upgrade. coder/websocket accepts any Origin by default,
 		// which is what we want — auth is the trust boundary, not the
@@ -166,10 +176,10 @@ func MomentumsStream(signer *auth.Signer, hub *stream.Hub[*dto.Momentum], repo s
 			}
 			// Any live frame whose height we already replayed gets
 			// filtered in the live loop via lastSent.
-			runLive(ctx, conn, sub, lastSent)
+			runLive(ctx, conn, sub, reorgs, lastSent)
 			return
 		}
-		runLive(ctx, conn, sub, 0)
+		runLive(ctx, conn, sub, reorgs, 0)
 	}
 }
 
@@ -231,10 +241,47 @@ func replay(ctx context.Context, conn *websocket.Conn, repo streamMomentumsRepo,
 	return lastSent, nil
 }
 
+// subscribeReorgs attaches a stream connection to the reorg hub. Reorg
+// frames are advisory, so a missing or non-running hub degrades to "no
+// reorg frames" rather than refusing the stream: the returned channel is
+// nil, which blocks forever inside a select. The close func is always
+// safe to call.
+func subscribeReorgs(hub *stream.Hub[*dto.Reorg], subject string) (<-chan *dto.Reorg, func()) {
+	if hub == nil {
+		return nil, func() {}
+	}
+	sub, err := hub.Subscribe(subject)
+	if err != nil {
+		return nil, func() {}
+	}
+	return sub.Recv(), sub.Close
+}
+
+// writeReorgFrame stamps the frame type onto a hub event and writes it.
+func writeReorgFrame(ctx context.Context, conn *websocket.Conn, ev *dto.Reorg) error {
+	frame := *ev
+	frame.Type = dto.ReorgFrameType
+	body, err := json.Marshal(&frame)
+	if err != nil {
+		return err
+	}
+	writeCtx, cancel := context.WithTimeout(ctx, streamWriteTimeout)
+	defer cancel()
+	return conn.Write(writeCtx, websocket.MessageText, body)
+}
+
 // runLive blocks on the subscriber channel + a ping ticker until the
 // client goes away. lastSent suppresses any momentum height <= lastSent
 // so a replay that overlapped with live arrivals doesn't double-emit.
-func runLive(ctx context.Context, conn *websocket.Conn, sub *stream.Subscriber[*dto.Momentum], lastSent uint64) {
+// A reorg frame rewinds lastSent to the common ancestor so the
+// re-indexed heights are delivered again.
+func runLive(
+	ctx context.Context,
+	conn *websocket.Conn,
+	sub *stream.Subscriber[*dto.Momentum],
+	reorgs <-chan *dto.Reorg,
+	lastSent uint64,
+) {
 	pingTicker := time.NewTicker(streamPingInterval)
 	defer pingTicker.Stop()
 
@@ -261,6 +308,19 @@ func runLive(ctx context.Context, conn *websocket.Conn, sub *stream.Subscriber[*
 			}
 			lastSent = m.Height
 
+		case ev, ok := <-reorgs:
+			if !ok {
+				// Reorg hub reconnecting; keep streaming data frames.
+				reorgs = nil
+				continue
+			}
+			if err := writeReorgFrame(ctx, conn, ev); err != nil {
+				return
+			}
+			if lastSent > ev.CommonAncestorHeight {
+				lastSent = ev.CommonAncestorHeight
+			}
+
 		case <-pingTicker.C:
 			pingCtx, cancel := context.WithTimeout(ctx, streamWriteTimeout)
 			err := conn.Ping(pingCtx)
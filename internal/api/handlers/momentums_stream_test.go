@@ -60,7 +60,7 @@ func newStreamHarness(t *testing.T, repo *fakeStreamRepo) (string, *auth.Signer,
 	stream.MarkRunningForTest(hub) // bypass the LISTEN loop for handler tests
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("/api/v1/momentums/stream", MomentumsStream(signer, hub, repo))
+	mux.HandleFunc("/api/v1/momentums/stream", MomentumsStream(signer, hub, nil, repo))
 	srv := httptest.NewServer(mux)
 	// httptest.Server uses http:// — swap to ws:// for the WS dialer.
 	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/v1/momentums/stream"
@@ -253,7 +253,7 @@ func TestStream_HubNotRunningReturns503(t *testing.T) {
 	// Deliberately do NOT call MarkRunningForTest — hub is pending.
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("/api/v1/momentums/stream", MomentumsStream(signer, hub, &fakeStreamRepo{}))
+	mux.HandleFunc("/api/v1/momentums/stream", MomentumsStream(signer, hub, nil, &fakeStreamRepo{}))
 	srv := httptest.NewServer(mux)
 	defer srv.Close()
 
@@ -279,7 +279,7 @@ func TestStream_PerSubjectLimitReturns429(t *testing.T) {
 	stream.MarkRunningForTest(hub)
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("/api/v1/momentums/stream", MomentumsStream(signer, hub, &fakeStreamRepo{}))
+	mux.HandleFunc("/api/v1/momentums/stream", MomentumsStream(signer, hub, nil, &fakeStreamRepo{}))
 	srv := httptest.NewServer(mux)
 	defer srv.Close()
 	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/v1/momentums/stream"
@@ -317,3 +317,70 @@ func TestStream_PerSubjectLimitReturns429(t *testing.T) {
 func hubDispatch(h *stream.Hub[*dto.Momentum], m *dto.Momentum) {
 	stream.DispatchForTest(h, m)
 }
+
+// TestStream_ReorgFrameRewindsLastSent checks that a reorg frame is
+// forwarded with type "reorg" and that heights above the common ancestor
+// are delivered again afterwards instead of being deduplicated.
+func TestStream_ReorgFrameRewindsLastSent(t *testing.T) {
+	signer, _ := auth.NewSigner("test-secret-32-bytes-or-longer-okok")
+	hub := stream.New(stream.Config[*dto.Momentum]{
+		Logger:      zap.NewNop(),
+		ChannelName: "momentum_new",
+		Unmarshal:   stream.UnmarshalJSON[dto.Momentum](),
+	})
+	stream.MarkRunningForTest(hub)
+	reorgHub := stream.New(stream.Config[*dto.Reorg]{
+		Logger:        zap.NewNop(),
+		ChannelName:   "reorg",
+		Unmarshal:     stream.UnmarshalJSON[dto.Reorg](),
+		PerSubjectMax: -1,
+	})
+	stream.MarkRunningForTest(reorgHub)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/momentums/stream", MomentumsStream(signer, hub, reorgHub, &fakeStreamRepo{}))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/v1/momentums/stream"
+
+	tok, _ := signer.Issue("reorg-client", time.Hour, []string{"read"})
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	conn, _, err := websocket.Dial(ctx, wsURL+"?token="+tok, nil)
+	if err != nil {
+		t.Fatalf("ws dial: %v", err)
+	}
+	defer conn.CloseNow()
+
+	readFrame := func() map[string]any {
+		t.Helper()
+		_, body, err := conn.Read(ctx)
+		if err != nil {
+			t.Fatalf("ws read: %v", err)
+		}
+		var f map[string]any
+		if err := json.Unmarshal(body, &f); err != nil {
+			t.Fatalf("unmarshal frame: %v", err)
+		}
+		return f
+	}
+
+	hubDispatch(hub, &dto.Momentum{Height: 10, Hash: "a10"})
+	if f := readFrame(); f["height"] != float64(10) {
+		t.Fatalf("first frame = %v, want height 10", f)
+	}
+
+	stream.DispatchForTest(reorgHub, &dto.Reorg{
+		CommonAncestorHeight: 8, CommonAncestorHash: "a8",
+		OrphanedTipHeight: 10, OrphanedTipHash: "a10",
+	})
+	f := readFrame()
+	if f["type"] != dto.ReorgFrameType || f["common_ancestor_height"] != float64(8) {
+		t.Fatalf("reorg frame = %v", f)
+	}
+
+	hubDispatch(hub, &dto.Momentum{Height: 9, Hash: "b9"})
+	if f := readFrame(); f["height"] != float64(9) || f["hash"] != "b9" {
+		t.Errorf("post-reorg frame = %v, want height 9 hash b9", f)
+	}
+}
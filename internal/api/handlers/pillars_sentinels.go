@@ -150,3 +150,26 @@ func SentinelsList(repo sentinelsRepo) http.HandlerFunc {
 			dto.NewPage(dto.FromSentinels(rows), p.Page, p.PageSize, total))
 	}
 }
+
+type sentinelEventsRepo interface {
+	List(ctx context.Context, f repository.SentinelEventFilter, opts repository.ListOpts) ([]*models.SentinelEvent, int64, error)
+}
+
+// SentinelEvents handles GET /api/v1/sentinels/events: accepted Sentinel
+// contract calls, newest first, each linked to the registration it
+// belongs to. ?owner= and ?method= narrow the list.
+func SentinelEvents(repo sentinelEventsRepo) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p := httpx.ParsePagination(r)
+		q := r.URL.Query()
+		rows, total, err := repo.List(r.Context(), repository.SentinelEventFilter{
+			Owner: q.Get("owner"), Method: q.Get("method"),
+		}, repository.ListOpts{Limit: p.PageSize, Offset: p.Offset()})
+		if err != nil {
+			writeRepoError(w, err)
+			return
+		}
+		httpx.WriteJSON(w, http.StatusOK,
+			dto.NewPage(dto.FromSentinelEvents(rows), p.Page, p.PageSize, total))
+	}
+}
@@ -149,3 +149,32 @@ func TestSentinelsList(t *testing.T) {
 		}
 	})
 }
+
+type fakeSentinelEventsRepo struct {
+	events     []*models.SentinelEvent
+	lastFilter repository.SentinelEventFilter
+}
+
+func (f *fakeSentinelEventsRepo) List(_ context.Context, filter repository.SentinelEventFilter, _ repository.ListOpts) ([]*models.SentinelEvent, int64, error) {
+	f.lastFilter = filter
+	return f.events, int64(len(f.events)), nil
+}
+
+func TestSentinelEvents(t *testing.T) {
+	repo := &fakeSentinelEventsRepo{events: []*models.SentinelEvent{
+		{AccountBlockHash: "r2", Owner: "z1qs1", Method: "Revoke", RegistrationBlockHash: "r1"},
+	}}
+	w := httptest.NewRecorder()
+	SentinelEvents(repo)(w, httptest.NewRequest(http.MethodGet, "/api/v1/sentinels/events?owner=z1qs1&method=Revoke", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	if repo.lastFilter.Owner != "z1qs1" || repo.lastFilter.Method != "Revoke" {
+		t.Errorf("filter = %+v, want Revoke by z1qs1", repo.lastFilter)
+	}
+	for _, want := range []string{`"method":"Revoke"`, `"znn_amount":"0"`, `"registration_block_hash":"r1"`, `"total":1`} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("missing %s in %s", want, w.Body.String())
+		}
+	}
+}
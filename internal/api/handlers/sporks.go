@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/0x3639/nom-indexer-go/internal/api/dto"
+	"github.com/0x3639/nom-indexer-go/internal/api/httpx"
+	"github.com/0x3639/nom-indexer-go/internal/models"
+	"github.com/0x3639/nom-indexer-go/internal/repository"
+)
+
+type sporksRepo interface {
+	List(ctx context.Context, activatedOnly bool, opts repository.ListOpts) ([]*models.Spork, int64, error)
+	GetByID(ctx context.Context, id string) (*models.Spork, error)
+}
+
+// SporksList handles GET /api/v1/sporks. All sporks, newest first;
+// pass ?activated=true to show only activated ones.
+func SporksList(repo sporksRepo) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p := httpx.ParsePagination(r)
+		rows, total, err := repo.List(r.Context(), boolQuery(r, "activated", false), repository.ListOpts{
+			Limit: p.PageSize, Offset: p.Offset(),
+		})
+		if err != nil {
+			writeRepoError(w, err)
+			return
+		}
+		httpx.WriteJSON(w, http.StatusOK,
+			dto.NewPage(dto.FromSporks(rows), p.Page, p.PageSize, total))
+	}
+}
+
+// SporksGet handles GET /api/v1/sporks/{id}, where id is the hash of the
+// CreateSpork send block.
+func SporksGet(repo sporksRepo) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		if id == "" {
+			httpx.WriteProblem(w, http.StatusBadRequest, "invalid_id", "id is required")
+			return
+		}
+		s, err := repo.GetByID(r.Context(), id)
+		if err != nil {
+			writeRepoError(w, err)
+			return
+		}
+		httpx.WriteJSON(w, http.StatusOK, dto.FromSpork(s))
+	}
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+	"github.com/0x3639/nom-indexer-go/internal/repository"
+)
+
+type fakeSporksRepo struct {
+	list              []*models.Spork
+	total             int64
+	lastActivatedOnly bool
+	lastOpts          repository.ListOpts
+}
+
+func (f *fakeSporksRepo) List(_ context.Context, activatedOnly bool, o repository.ListOpts) ([]*models.Spork, int64, error) {
+	f.lastActivatedOnly = activatedOnly
+	f.lastOpts = o
+	return f.list, f.total, nil
+}
+func (f *fakeSporksRepo) GetByID(_ context.Context, id string) (*models.Spork, error) {
+	for _, s := range f.list {
+		if s.ID == id {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("SporkRepository.GetByID: %w", pgx.ErrNoRows)
+}
+
+func TestSporksList(t *testing.T) {
+	repo := &fakeSporksRepo{
+		list: []*models.Spork{
+			{ID: "s1", Name: "htlc", Activated: true, EnforcementHeight: 1006},
+		},
+		total: 1,
+	}
+	w := httptest.NewRecorder()
+	SporksList(repo)(w, httptest.NewRequest(http.MethodGet, "/api/v1/sporks?activated=true&page_size=10", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	if !repo.lastActivatedOnly {
+		t.Error("expected activated=true to propagate")
+	}
+	if repo.lastOpts.Limit != 10 {
+		t.Errorf("limit = %d, want 10", repo.lastOpts.Limit)
+	}
+	for _, want := range []string{`"name":"htlc"`, `"enforcement_height":1006`, `"total":1`} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("missing %s in %s", want, w.Body.String())
+		}
+	}
+}
+
+func TestSporksGet(t *testing.T) {
+	repo := &fakeSporksRepo{list: []*models.Spork{{ID: "s1", Name: "htlc"}}}
+	r := chi.NewRouter()
+	r.Get("/api/v1/sporks/{id}", SporksGet(repo))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/sporks/s1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/sporks/unknown", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unknown id status = %d, want 404", w.Code)
+	}
+}
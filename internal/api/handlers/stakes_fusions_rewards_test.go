@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -34,7 +35,7 @@ func (f *fakeStakesRepo) ListByAddress(_ context.Context, addr string, activeOnl
 }
 
 func TestStakesList(t *testing.T) {
-	repo := &fakeStakesRepo{rows: []*models.Stake{{ID: "s1", ZnnAmount: 9_223_372_036_000_000_000}}, total: 1}
+	repo := &fakeStakesRepo{rows: []*models.Stake{{ID: "s1", ZnnAmount: big.NewInt(9_223_372_036_000_000_000)}}, total: 1}
 	w := httptest.NewRecorder()
 	StakesList(repo)(w, httptest.NewRequest(http.MethodGet, "/api/v1/stakes", nil))
 	if w.Code != http.StatusOK || !repo.lastActive {
@@ -116,7 +117,7 @@ func (f *fakeRewardsRepo) HistoryByAddress(_ context.Context, addr string, o rep
 
 func TestRewardsCumulative(t *testing.T) {
 	repo := &fakeRewardsRepo{cum: []*models.CumulativeReward{
-		{Address: "z1qq", RewardType: models.RewardTypeDelegation, Amount: 500, TokenStandard: "zts1znn"},
+		{Address: "z1qq", RewardType: models.RewardTypeDelegation, Amount: big.NewInt(500), TokenStandard: "zts1znn"},
 	}}
 	r := chi.NewRouter()
 	r.Get("/api/v1/accounts/{address}/rewards/cumulative", RewardsCumulative(repo))
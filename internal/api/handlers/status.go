@@ -11,6 +11,7 @@ import (
 	"github.com/0x3639/nom-indexer-go/internal/api/dto"
 	"github.com/0x3639/nom-indexer-go/internal/api/httpx"
 	"github.com/0x3639/nom-indexer-go/internal/models"
+	"github.com/0x3639/nom-indexer-go/internal/repository"
 )
 
 // statusMomentumRepo is the read surface the status handler needs from
@@ -47,3 +48,27 @@ func Status(repo statusMomentumRepo, version string, now func() time.Time) http.
 		})
 	}
 }
+
+type failedHeightsRepo interface {
+	List(ctx context.Context, opts repository.ListOpts) ([]*models.FailedHeight, int64, error)
+}
+
+// FailedHeightsList handles GET /api/v1/failed-heights: the momentum
+// heights the indexer failed to process and is retrying, lowest first by
+// default. An empty list means nothing is outstanding.
+func FailedHeightsList(repo failedHeightsRepo) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p := httpx.ParsePagination(r)
+		rows, total, err := repo.List(r.Context(), repository.ListOpts{
+			Limit:  p.PageSize,
+			Offset: p.Offset(),
+			Sort:   httpx.ParseSort(r, "asc"),
+		})
+		if err != nil {
+			writeRepoError(w, err)
+			return
+		}
+		httpx.WriteJSON(w, http.StatusOK,
+			dto.NewPage(dto.FromFailedHeights(rows), p.Page, p.PageSize, total))
+	}
+}
@@ -17,6 +17,10 @@ type tokensRepo interface {
 	GetByStandard(ctx context.Context, tokenStandard string) (*models.Token, error)
 }
 
+type tokenHistoryRepo interface {
+	History(ctx context.Context, tokenStandard string, opts repository.ListOpts) ([]*models.TokenEvent, int64, error)
+}
+
 type tokenHoldersRepo interface {
 	ListByToken(ctx context.Context, tokenStandard string, opts repository.ListOpts) ([]*models.Balance, int64, error)
 }
@@ -78,3 +82,26 @@ func TokensHolders(repo tokenHoldersRepo) http.HandlerFunc {
 			dto.NewPage(dto.FromBalances(rows), p.Page, p.PageSize, total))
 	}
 }
+
+// TokensHistory handles GET /api/v1/tokens/{token_standard}/history:
+// the token's issue and every accepted UpdateToken, newest first.
+func TokensHistory(repo tokenHistoryRepo) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		std := chi.URLParam(r, "token_standard")
+		if std == "" {
+			httpx.WriteProblem(w, http.StatusBadRequest, "invalid_token_standard", "token_standard is required")
+			return
+		}
+		p := httpx.ParsePagination(r)
+		rows, total, err := repo.History(r.Context(), std, repository.ListOpts{
+			Limit:  p.PageSize,
+			Offset: p.Offset(),
+		})
+		if err != nil {
+			writeRepoError(w, err)
+			return
+		}
+		httpx.WriteJSON(w, http.StatusOK,
+			dto.NewPage(dto.FromTokenEvents(rows), p.Page, p.PageSize, total))
+	}
+}
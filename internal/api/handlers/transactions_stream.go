@@ -54,14 +54,16 @@ type streamLatestMomentumRepo interface {
 //     momentum_height up to the chain tip (capped at streamReplayMaxRows)
 //     before switching to live.
 //
-// Frames: one JSON object per account_block (matches dto.AccountBlock).
-// Close codes mirror the momentums stream: 1000 normal, 1011 internal,
+// Frames: one JSON object per account_block (matches dto.AccountBlock),
+// plus a dto.Reorg frame when the indexer rolls back a fork (see
+// MomentumsStream). Close codes mirror the momentums stream: 1000 normal, 1011 internal,
 // 4000 slow_consumer.
 //
 //nolint:contextcheck // WS connection lifecycle is detached from r.Context by design — see body comment
 func TransactionsStream(
 	signer *auth.Signer,
 	hub *stream.Hub[*dto.AccountBlock],
+	reorgHub *stream.Hub[*dto.Reorg],
 	txRepo streamTxRepo,
 	momentumRepo streamLatestMomentumRepo,
 ) http.HandlerFunc {
@@ -113,6 +115,8 @@ func TransactionsStream(
 			return
 		}
 		defer sub.Close()
+		reorgs, closeReorgs := subscribeReorgs(reorgHub, subject)
+		defer closeReorgs()
 
 		// 4. Upgrade.
 		conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{
@@ -134,7 +138,7 @@ func TransactionsStream(
 				return
 			}
 		}
-		runTxLive(ctx, conn, sub, addressFilter, cursor)
+		runTxLive(ctx, conn, sub, reorgs, addressFilter, cursor)
 	}
 }
 
@@ -213,10 +217,13 @@ func replayTransactions(
 // the optional per-address filter before each WS write. cursor
 // suppresses replay duplicates and duplicate live notifications by
 // hash without collapsing every account_block in the same momentum.
+// A reorg frame rewinds the cursor to just above the common ancestor so
+// the re-indexed blocks are delivered again.
 func runTxLive(
 	ctx context.Context,
 	conn *websocket.Conn,
 	sub *stream.Subscriber[*dto.AccountBlock],
+	reorgs <-chan *dto.Reorg,
 	addressFilter string,
 	cursor txStreamCursor,
 ) {
@@ -249,6 +256,18 @@ func runTxLive(
 			}
 			cursor.mark(ab)
 
+		case ev, ok := <-reorgs:
+			if !ok {
+				reorgs = nil
+				continue
+			}
+			if err := writeReorgFrame(ctx, conn, ev); err != nil {
+				return
+			}
+			if ancestor := int64(ev.CommonAncestorHeight); cursor.height > ancestor {
+				cursor = txStreamCursor{height: ancestor + 1}
+			}
+
 		case <-pingTicker.C:
 			pingCtx, cancel := context.WithTimeout(ctx, streamWriteTimeout)
 			err := conn.Ping(pingCtx)
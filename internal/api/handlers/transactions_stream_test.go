@@ -82,7 +82,7 @@ func newTxStreamHarness(
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/api/v1/transactions/stream",
-		TransactionsStream(signer, hub, txRepo, mom))
+		TransactionsStream(signer, hub, nil, txRepo, mom))
 	srv := httptest.NewServer(mux)
 	wsURL = "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/v1/transactions/stream"
 	return wsURL, signer, hub, srv.Close
@@ -0,0 +1,415 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/0x3639/nom-indexer-go/internal/api/dto"
+	"github.com/0x3639/nom-indexer-go/internal/api/httpx"
+	apimw "github.com/0x3639/nom-indexer-go/internal/api/middleware"
+	"github.com/0x3639/nom-indexer-go/internal/models"
+	"github.com/0x3639/nom-indexer-go/internal/repository"
+	"github.com/0x3639/nom-indexer-go/internal/webhooks"
+)
+
+// Limits on subscription input. Generous for real integrations, tight
+// enough that one subject cannot bloat the dispatcher's routing table.
+const (
+	maxWebhookSubscriptionsPerOwner = 25
+	maxWebhookURLLength             = 2048
+	maxWebhookDescriptionLength     = 256
+	maxWebhookRequestBody           = 64 << 10 // room for a full address filter
+)
+
+// webhookSecretGracePeriod is how long a rotated-out secret keeps signing
+// deliveries alongside the new one, so subscribers can switch over
+// without rejecting anything.
+const webhookSecretGracePeriod = 24 * time.Hour
+
+// webhookLookupTimeout bounds resolving a subscription URL's host when it
+// is saved.
+const webhookLookupTimeout = 2 * time.Second
+
+// webhookSubscriptionsRepo is the surface the /webhooks handlers need
+// from repository.WebhookSubscriptionRepository. Every call is scoped to
+// the token subject.
+type webhookSubscriptionsRepo interface {
+	Create(ctx context.Context, s *models.WebhookSubscription) error
+	Get(ctx context.Context, owner string, id int64) (*models.WebhookSubscription, error)
+	ListByOwner(ctx context.Context, owner string, opts repository.ListOpts) ([]*models.WebhookSubscription, int64, error)
+	Update(ctx context.Context, s *models.WebhookSubscription) error
+	RotateSecret(ctx context.Context, owner string, id int64, secret string, now, previousExpiresAt int64) error
+	Delete(ctx context.Context, owner string, id int64) error
+}
+
+// webhookOutboxRepo is the surface the test-event and delivery-log
+// handlers need from repository.WebhookOutboxRepository.
+type webhookOutboxRepo interface {
+	Insert(ctx context.Context, e *models.WebhookOutboxEntry) (int64, error)
+	ListDeliveries(ctx context.Context, subscriptionID int64, opts repository.ListOpts) ([]*models.WebhookDelivery, int64, error)
+}
+
+// webhookCreateRequest is the POST /webhooks body.
+type webhookCreateRequest struct {
+	URL         string             `json:"url"`
+	Events      []string           `json:"events"`
+	Filter      *dto.WebhookFilter `json:"filter"`
+	Description string             `json:"description"`
+}
+
+// webhookUpdateRequest is the PATCH /webhooks/{id} body. Absent fields
+// are left unchanged; a filter that is sent replaces the old one whole.
+type webhookUpdateRequest struct {
+	URL         *string            `json:"url"`
+	Events      *[]string          `json:"events"`
+	Filter      *dto.WebhookFilter `json:"filter"`
+	Description *string            `json:"description"`
+	Status      *string            `json:"status"`
+}
+
+// WebhooksList handles GET /api/v1/webhooks.
+func WebhooksList(repo webhookSubscriptionsRepo) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		owner, ok := webhookOwner(w, r)
+		if !ok {
+			return
+		}
+		p := httpx.ParsePagination(r)
+		rows, total, err := repo.ListByOwner(r.Context(), owner, repository.ListOpts{
+			Limit: p.PageSize, Offset: p.Offset(),
+		})
+		if err != nil {
+			writeRepoError(w, err)
+			return
+		}
+		httpx.WriteJSON(w, http.StatusOK,
+			dto.NewPage(dto.FromWebhookSubscriptions(rows), p.Page, p.PageSize, total))
+	}
+}
+
+// WebhooksCreate handles POST /api/v1/webhooks. The response is the only
+// place, besides rotate-secret, where the signing secret is returned.
+func WebhooksCreate(repo webhookSubscriptionsRepo, now func() time.Time) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		owner, ok := webhookOwner(w, r)
+		if !ok {
+			return
+		}
+		var req webhookCreateRequest
+		if !decodeWebhookBody(w, r, &req) {
+			return
+		}
+		events, code, detail := validateWebhookFields(r.Context(), req.URL, req.Events, req.Description)
+		if code != "" {
+			httpx.WriteProblem(w, http.StatusBadRequest, code, detail)
+			return
+		}
+		filter := dto.ToWebhookFilter(req.Filter)
+		if err := webhooks.ValidateFilter(filter); err != nil {
+			httpx.WriteProblem(w, http.StatusBadRequest, "invalid_filter", err.Error())
+			return
+		}
+
+		_, total, err := repo.ListByOwner(r.Context(), owner, repository.ListOpts{Limit: 1})
+		if err != nil {
+			writeRepoError(w, err)
+			return
+		}
+		if total >= maxWebhookSubscriptionsPerOwner {
+			httpx.WriteProblem(w, http.StatusConflict, "subscription_limit",
+				fmt.Sprintf("at most %d subscriptions per token subject", maxWebhookSubscriptionsPerOwner))
+			return
+		}
+
+		secret, err := webhooks.NewSecret()
+		if err != nil {
+			httpx.WriteProblem(w, http.StatusInternalServerError, "internal_error", "could not generate secret")
+			return
+		}
+		ts := now().Unix()
+		s := &models.WebhookSubscription{
+			Owner:           owner,
+			URL:             req.URL,
+			Events:          events,
+			Filter:          filter,
+			Description:     req.Description,
+			Secret:          secret,
+			Status:          models.WebhookSubscriptionActive,
+			CreatedAt:       ts,
+			UpdatedAt:       ts,
+			SecretRotatedAt: ts,
+		}
+		if err := repo.Create(r.Context(), s); err != nil {
+			writeRepoError(w, err)
+			return
+		}
+		httpx.WriteJSON(w, http.StatusCreated, dto.FromWebhookSubscriptionWithSecret(s))
+	}
+}
+
+// WebhooksGet handles GET /api/v1/webhooks/{id}.
+func WebhooksGet(repo webhookSubscriptionsRepo) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s, ok := loadWebhookSubscription(w, r, repo)
+		if !ok {
+			return
+		}
+		httpx.WriteJSON(w, http.StatusOK, dto.FromWebhookSubscription(s))
+	}
+}
+
+// WebhooksUpdate handles PATCH /api/v1/webhooks/{id}: change the URL,
+// event types, content filter or description, or pause / resume with
+// status.
+func WebhooksUpdate(repo webhookSubscriptionsRepo, now func() time.Time) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s, ok := loadWebhookSubscription(w, r, repo)
+		if !ok {
+			return
+		}
+		var req webhookUpdateRequest
+		if !decodeWebhookBody(w, r, &req) {
+			return
+		}
+		if req.URL != nil {
+			s.URL = *req.URL
+		}
+		if req.Events != nil {
+			s.Events = *req.Events
+		}
+		if req.Filter != nil {
+			s.Filter = dto.ToWebhookFilter(req.Filter)
+		}
+		if req.Description != nil {
+			s.Description = *req.Description
+		}
+		if req.Status != nil {
+			if *req.Status != models.WebhookSubscriptionActive && *req.Status != models.WebhookSubscriptionPaused {
+				httpx.WriteProblem(w, http.StatusBadRequest, "invalid_status",
+					"status must be active or paused")
+				return
+			}
+			s.Status = *req.Status
+		}
+		events, code, detail := validateWebhookFields(r.Context(), s.URL, s.Events, s.Description)
+		if code != "" {
+			httpx.WriteProblem(w, http.StatusBadRequest, code, detail)
+			return
+		}
+		if err := webhooks.ValidateFilter(s.Filter); err != nil {
+			httpx.WriteProblem(w, http.StatusBadRequest, "invalid_filter", err.Error())
+			return
+		}
+		s.Events = events
+		s.UpdatedAt = now().Unix()
+		if err := repo.Update(r.Context(), s); err != nil {
+			writeRepoError(w, err)
+			return
+		}
+		httpx.WriteJSON(w, http.StatusOK, dto.FromWebhookSubscription(s))
+	}
+}
+
+// WebhooksDelete handles DELETE /api/v1/webhooks/{id}. Undelivered events
+// and the delivery log are removed with the subscription.
+func WebhooksDelete(repo webhookSubscriptionsRepo) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		owner, ok := webhookOwner(w, r)
+		if !ok {
+			return
+		}
+		id, ok := webhookID(w, r)
+		if !ok {
+			return
+		}
+		if err := repo.Delete(r.Context(), owner, id); err != nil {
+			writeRepoError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// WebhooksRotateSecret handles POST /api/v1/webhooks/{id}/rotate-secret.
+// The new secret applies from the dispatcher's next reload. The old one
+// keeps signing deliveries alongside it for webhookSecretGracePeriod;
+// a secret rotated out before that is dropped at once.
+func WebhooksRotateSecret(repo webhookSubscriptionsRepo, now func() time.Time) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s, ok := loadWebhookSubscription(w, r, repo)
+		if !ok {
+			return
+		}
+		secret, err := webhooks.NewSecret()
+		if err != nil {
+			httpx.WriteProblem(w, http.StatusInternalServerError, "internal_error", "could not generate secret")
+			return
+		}
+		t := now()
+		ts, expires := t.Unix(), t.Add(webhookSecretGracePeriod).Unix()
+		if err := repo.RotateSecret(r.Context(), s.Owner, s.ID, secret, ts, expires); err != nil {
+			writeRepoError(w, err)
+			return
+		}
+		s.PreviousSecret, s.PreviousSecretExpiresAt = s.Secret, expires
+		s.Secret, s.SecretRotatedAt, s.UpdatedAt = secret, ts, ts
+		httpx.WriteJSON(w, http.StatusOK, dto.FromWebhookSubscriptionWithSecret(s))
+	}
+}
+
+// WebhooksTest handles POST /api/v1/webhooks/{id}/test. It queues a
+// webhook.test event for this subscription only, regardless of its event
+// and content filters; the indexer delivers it like any other event and the attempt
+// shows up in the delivery log.
+func WebhooksTest(subs webhookSubscriptionsRepo, outbox webhookOutboxRepo, now func() time.Time) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s, ok := loadWebhookSubscription(w, r, subs)
+		if !ok {
+			return
+		}
+		if s.Status == models.WebhookSubscriptionPaused {
+			httpx.WriteProblem(w, http.StatusConflict, "subscription_paused",
+				"resume the subscription before sending a test event")
+			return
+		}
+		ts := now().Unix()
+		payload, err := json.Marshal(map[string]any{"subscriptionId": s.ID, "sentAt": ts})
+		if err != nil {
+			httpx.WriteProblem(w, http.StatusInternalServerError, "internal_error", "failed to encode event")
+			return
+		}
+		id, err := outbox.Insert(r.Context(), &models.WebhookOutboxEntry{
+			SubscriptionID: &s.ID,
+			EndpointURL:    s.URL,
+			EventType:      webhooks.EventTest,
+			Payload:        payload,
+			NextAttemptAt:  ts,
+			CreatedAt:      ts,
+		})
+		if err != nil {
+			writeRepoError(w, err)
+			return
+		}
+		httpx.WriteJSON(w, http.StatusAccepted, &dto.WebhookTestEvent{OutboxID: id})
+	}
+}
+
+// WebhooksDeliveries handles GET /api/v1/webhooks/{id}/deliveries:
+// recent delivery attempts, newest first.
+func WebhooksDeliveries(subs webhookSubscriptionsRepo, outbox webhookOutboxRepo) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s, ok := loadWebhookSubscription(w, r, subs)
+		if !ok {
+			return
+		}
+		p := httpx.ParsePagination(r)
+		rows, total, err := outbox.ListDeliveries(r.Context(), s.ID, repository.ListOpts{
+			Limit: p.PageSize, Offset: p.Offset(),
+		})
+		if err != nil {
+			writeRepoError(w, err)
+			return
+		}
+		httpx.WriteJSON(w, http.StatusOK,
+			dto.NewPage(dto.FromWebhookDeliveries(rows), p.Page, p.PageSize, total))
+	}
+}
+
+// webhookOwner returns the token subject every /webhooks call is scoped
+// to. Auth always attaches claims on these routes; the 401 is defensive.
+func webhookOwner(w http.ResponseWriter, r *http.Request) (string, bool) {
+	c := apimw.ClaimsFromContext(r.Context())
+	if c == nil || c.Subject == "" {
+		httpx.WriteProblem(w, http.StatusUnauthorized, "invalid_token", "token has no subject")
+		return "", false
+	}
+	return c.Subject, true
+}
+
+func webhookID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		httpx.WriteProblem(w, http.StatusBadRequest, "invalid_id", "id must be a positive integer")
+		return 0, false
+	}
+	return id, true
+}
+
+// loadWebhookSubscription resolves {id} to one of the caller's
+// subscriptions, writing the error response when it cannot.
+func loadWebhookSubscription(w http.ResponseWriter, r *http.Request, repo webhookSubscriptionsRepo) (*models.WebhookSubscription, bool) {
+	owner, ok := webhookOwner(w, r)
+	if !ok {
+		return nil, false
+	}
+	id, ok := webhookID(w, r)
+	if !ok {
+		return nil, false
+	}
+	s, err := repo.Get(r.Context(), owner, id)
+	if err != nil {
+		writeRepoError(w, err)
+		return nil, false
+	}
+	return s, true
+}
+
+// decodeWebhookBody decodes a JSON request body into v, rejecting
+// unknown fields so a typo does not silently leave a setting unchanged.
+func decodeWebhookBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxWebhookRequestBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		detail := "request body must be a JSON object"
+		if !errors.Is(err, io.EOF) {
+			detail += ": " + err.Error()
+		}
+		httpx.WriteProblem(w, http.StatusBadRequest, "invalid_body", detail)
+		return false
+	}
+	return true
+}
+
+// validateWebhookFields checks a subscription's user-supplied fields and
+// returns the de-duplicated event filter. code is empty when valid. The
+// URL must not reach the indexer's own networks; see
+// webhooks.ErrPrivateTarget.
+func validateWebhookFields(ctx context.Context, rawURL string, events []string, description string) (clean []string, code, detail string) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, "invalid_url", "url must be an absolute http or https URL"
+	}
+	if len(rawURL) > maxWebhookURLLength {
+		return nil, "invalid_url", fmt.Sprintf("url must be at most %d characters", maxWebhookURLLength)
+	}
+	ctx, cancel := context.WithTimeout(ctx, webhookLookupTimeout)
+	defer cancel()
+	if err := webhooks.CheckTarget(ctx, rawURL); err != nil {
+		return nil, "invalid_url",
+			"url must not point at a loopback, private, link-local or unspecified address: " + err.Error()
+	}
+	if len(description) > maxWebhookDescriptionLength {
+		return nil, "invalid_description",
+			fmt.Sprintf("description must be at most %d characters", maxWebhookDescriptionLength)
+	}
+	clean = []string{}
+	for _, ev := range events {
+		if !webhooks.IsEventType(ev) {
+			return nil, "invalid_events", fmt.Sprintf("unknown event type %q", ev)
+		}
+		if !slices.Contains(clean, ev) {
+			clean = append(clean, ev)
+		}
+	}
+	return clean, "", ""
+}
@@ -0,0 +1,378 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	apimw "github.com/0x3639/nom-indexer-go/internal/api/middleware"
+	"github.com/0x3639/nom-indexer-go/internal/auth"
+	"github.com/0x3639/nom-indexer-go/internal/models"
+	"github.com/0x3639/nom-indexer-go/internal/repository"
+	"github.com/0x3639/nom-indexer-go/internal/webhooks"
+)
+
+// fakeWebhookRepo implements webhookSubscriptionsRepo and
+// webhookOutboxRepo over maps, with the same owner scoping as the
+// repository.
+type fakeWebhookRepo struct {
+	subs       map[int64]*models.WebhookSubscription
+	nextID     int64
+	queued     []*models.WebhookOutboxEntry
+	deliveries []*models.WebhookDelivery
+}
+
+func newFakeWebhookRepo() *fakeWebhookRepo {
+	return &fakeWebhookRepo{subs: map[int64]*models.WebhookSubscription{}}
+}
+
+func (f *fakeWebhookRepo) Create(_ context.Context, s *models.WebhookSubscription) error {
+	f.nextID++
+	s.ID = f.nextID
+	c := *s
+	f.subs[s.ID] = &c
+	return nil
+}
+func (f *fakeWebhookRepo) Get(_ context.Context, owner string, id int64) (*models.WebhookSubscription, error) {
+	s, ok := f.subs[id]
+	if !ok || s.Owner != owner {
+		return nil, pgx.ErrNoRows
+	}
+	c := *s
+	return &c, nil
+}
+func (f *fakeWebhookRepo) ListByOwner(_ context.Context, owner string, _ repository.ListOpts) ([]*models.WebhookSubscription, int64, error) {
+	var out []*models.WebhookSubscription
+	for _, s := range f.subs {
+		if s.Owner == owner {
+			out = append(out, s)
+		}
+	}
+	return out, int64(len(out)), nil
+}
+func (f *fakeWebhookRepo) Update(_ context.Context, s *models.WebhookSubscription) error {
+	if cur, ok := f.subs[s.ID]; !ok || cur.Owner != s.Owner {
+		return pgx.ErrNoRows
+	}
+	c := *s
+	f.subs[s.ID] = &c
+	return nil
+}
+func (f *fakeWebhookRepo) RotateSecret(_ context.Context, owner string, id int64, secret string, now, previousExpiresAt int64) error {
+	s, ok := f.subs[id]
+	if !ok || s.Owner != owner {
+		return pgx.ErrNoRows
+	}
+	s.PreviousSecret, s.PreviousSecretExpiresAt = s.Secret, previousExpiresAt
+	s.Secret, s.SecretRotatedAt = secret, now
+	return nil
+}
+func (f *fakeWebhookRepo) Delete(_ context.Context, owner string, id int64) error {
+	if s, ok := f.subs[id]; !ok || s.Owner != owner {
+		return pgx.ErrNoRows
+	}
+	delete(f.subs, id)
+	return nil
+}
+func (f *fakeWebhookRepo) Insert(_ context.Context, e *models.WebhookOutboxEntry) (int64, error) {
+	f.queued = append(f.queued, e)
+	return int64(len(f.queued)), nil
+}
+func (f *fakeWebhookRepo) ListDeliveries(_ context.Context, _ int64, _ repository.ListOpts) ([]*models.WebhookDelivery, int64, error) {
+	return f.deliveries, int64(len(f.deliveries)), nil
+}
+
+// webhookHarness mounts the /webhooks handlers behind Auth, the way
+// router.New does, and issues requests as the given subject.
+type webhookHarness struct {
+	t      *testing.T
+	h      http.Handler
+	signer *auth.Signer
+}
+
+func newWebhookHarness(t *testing.T, repo *fakeWebhookRepo) *webhookHarness {
+	t.Helper()
+	signer, err := auth.NewSigner("test-secret-32-bytes-or-longer-okok")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := func() time.Time { return time.Unix(1700000000, 0) }
+	r := chi.NewRouter()
+	r.Use(apimw.Auth(signer))
+	r.Get("/webhooks", WebhooksList(repo))
+	r.Post("/webhooks", WebhooksCreate(repo, now))
+	r.Get("/webhooks/{id}", WebhooksGet(repo))
+	r.Patch("/webhooks/{id}", WebhooksUpdate(repo, now))
+	r.Delete("/webhooks/{id}", WebhooksDelete(repo))
+	r.Post("/webhooks/{id}/rotate-secret", WebhooksRotateSecret(repo, now))
+	r.Post("/webhooks/{id}/test", WebhooksTest(repo, repo, now))
+	r.Get("/webhooks/{id}/deliveries", WebhooksDeliveries(repo, repo))
+	return &webhookHarness{t: t, h: r, signer: signer}
+}
+
+func (wh *webhookHarness) do(sub, method, path, body string) *httptest.ResponseRecorder {
+	wh.t.Helper()
+	tok, err := wh.signer.Issue(sub, time.Hour, []string{"read", "webhooks"})
+	if err != nil {
+		wh.t.Fatal(err)
+	}
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+tok)
+	w := httptest.NewRecorder()
+	wh.h.ServeHTTP(w, req)
+	return w
+}
+
+func TestWebhooksCreate(t *testing.T) {
+	repo := newFakeWebhookRepo()
+	wh := newWebhookHarness(t, repo)
+
+	w := wh.do("alice", http.MethodPost, "/webhooks",
+		`{"url":"https://example.com/hook","events":["reorg","reorg","momentum.inserted"],"description":"ops"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+	var got struct {
+		ID     int64    `json:"id"`
+		Secret string   `json:"secret"`
+		Events []string `json:"events"`
+		Status string   `json:"status"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Secret) != 64 || got.Status != "active" {
+		t.Errorf("create response = %+v, want a 64-char secret and active status", got)
+	}
+	if len(got.Events) != 2 {
+		t.Errorf("events = %v, want de-duplicated [reorg momentum.inserted]", got.Events)
+	}
+	if s := repo.subs[got.ID]; s == nil || s.Owner != "alice" || s.Secret != got.Secret {
+		t.Errorf("stored = %+v", s)
+	}
+
+	// The secret is never shown again.
+	w = wh.do("alice", http.MethodGet, "/webhooks/1", "")
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "secret\"") {
+		t.Errorf("get = %d %s, want 200 without the secret", w.Code, w.Body.String())
+	}
+}
+
+func TestWebhooksCreate_Validation(t *testing.T) {
+	wh := newWebhookHarness(t, newFakeWebhookRepo())
+	for _, tc := range []struct {
+		body string
+		code string
+	}{
+		{`{"url":"ftp://example.com"}`, "invalid_url"},
+		{`{"url":"/relative"}`, "invalid_url"},
+		{`{"url":"http://127.0.0.1"}`, "invalid_url"},
+		{`{"url":"http://169.254.169.254/latest/meta-data/"}`, "invalid_url"},
+		{`{"url":"http://[::1]:8080/hook"}`, "invalid_url"},
+		{`{"url":"https://example.com","events":["nope"]}`, "invalid_events"},
+		{`{"url":"https://example.com","secret":"mine"}`, "invalid_body"},
+		{`not json`, "invalid_body"},
+		{`{"url":"https://example.com","description":"` + strings.Repeat("x", 300) + `"}`, "invalid_description"},
+		{`{"url":"https://example.com","filter":{"addresses":["z1nope"]}}`, "invalid_filter"},
+		{`{"url":"https://example.com","filter":{"block_types":[9]}}`, "invalid_filter"},
+		{`{"url":"https://example.com","filter":{"min_amount":"-1"}}`, "invalid_filter"},
+		{`{"url":"https://example.com","filter":{"amount":"1"}}`, "invalid_body"},
+	} {
+		w := wh.do("alice", http.MethodPost, "/webhooks", tc.body)
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), `"`+tc.code+`"`) {
+			t.Errorf("%s: got %d %s, want 400 %s", tc.body, w.Code, w.Body.String(), tc.code)
+		}
+	}
+}
+
+func TestWebhooksCreate_Limit(t *testing.T) {
+	repo := newFakeWebhookRepo()
+	for n := 0; n < maxWebhookSubscriptionsPerOwner; n++ {
+		_ = repo.Create(context.Background(), &models.WebhookSubscription{Owner: "alice"})
+	}
+	wh := newWebhookHarness(t, repo)
+	if w := wh.do("alice", http.MethodPost, "/webhooks", `{"url":"https://example.com"}`); w.Code != http.StatusConflict {
+		t.Errorf("status = %d at the limit, want 409", w.Code)
+	}
+	if w := wh.do("bob", http.MethodPost, "/webhooks", `{"url":"https://example.com"}`); w.Code != http.StatusCreated {
+		t.Errorf("status = %d for another subject, want 201", w.Code)
+	}
+}
+
+func TestWebhooks_ScopedToOwner(t *testing.T) {
+	repo := newFakeWebhookRepo()
+	_ = repo.Create(context.Background(), &models.WebhookSubscription{Owner: "alice", URL: "https://a", Status: "active"})
+	wh := newWebhookHarness(t, repo)
+
+	for _, req := range []struct{ method, path, body string }{
+		{http.MethodGet, "/webhooks/1", ""},
+		{http.MethodPatch, "/webhooks/1", `{"status":"paused"}`},
+		{http.MethodDelete, "/webhooks/1", ""},
+		{http.MethodPost, "/webhooks/1/rotate-secret", ""},
+		{http.MethodPost, "/webhooks/1/test", ""},
+		{http.MethodGet, "/webhooks/1/deliveries", ""},
+	} {
+		if w := wh.do("mallory", req.method, req.path, req.body); w.Code != http.StatusNotFound {
+			t.Errorf("%s %s as another subject = %d, want 404", req.method, req.path, w.Code)
+		}
+	}
+	if w := wh.do("alice", http.MethodGet, "/webhooks/abc", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("non-numeric id = %d, want 400", w.Code)
+	}
+	if len(repo.subs) != 1 || repo.subs[1].Status != "active" {
+		t.Errorf("subscription changed by another subject: %+v", repo.subs[1])
+	}
+}
+
+func TestWebhooksUpdate_PauseAndEdit(t *testing.T) {
+	repo := newFakeWebhookRepo()
+	_ = repo.Create(context.Background(), &models.WebhookSubscription{
+		Owner: "alice", URL: "https://a", Events: []string{"reorg"}, Status: "active", Secret: "s",
+	})
+	wh := newWebhookHarness(t, repo)
+
+	w := wh.do("alice", http.MethodPatch, "/webhooks/1", `{"status":"paused","events":[]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+	s := repo.subs[1]
+	if s.Status != "paused" || len(s.Events) != 0 || s.URL != "https://a" || s.Secret != "s" {
+		t.Errorf("after pause = %+v", s)
+	}
+	if s.UpdatedAt != 1700000000 {
+		t.Errorf("updated_at = %d", s.UpdatedAt)
+	}
+
+	if w := wh.do("alice", http.MethodPatch, "/webhooks/1", `{"status":"off"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("bad status = %d, want 400", w.Code)
+	}
+	if w := wh.do("alice", http.MethodPatch, "/webhooks/1", `{"url":"nope"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("bad url = %d, want 400", w.Code)
+	}
+	if w := wh.do("alice", http.MethodPatch, "/webhooks/1", `{"url":"http://10.0.0.5/hook"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("private url = %d, want 400", w.Code)
+	}
+}
+
+func TestWebhooks_Filter(t *testing.T) {
+	repo := newFakeWebhookRepo()
+	wh := newWebhookHarness(t, repo)
+
+	w := wh.do("alice", http.MethodPost, "/webhooks",
+		`{"url":"https://example.com/hook","filter":{"token_standards":["`+models.ZnnTokenStandard+`"],"min_amount":"100000000"}}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+	s := repo.subs[1]
+	if len(s.Filter.TokenStandards) != 1 || s.Filter.MinAmount != "100000000" {
+		t.Errorf("stored filter = %+v", s.Filter)
+	}
+
+	// PATCH replaces the filter whole; other fields are untouched.
+	w = wh.do("alice", http.MethodPatch, "/webhooks/1", `{"filter":{"methods":["Delegate"]}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+	if f := repo.subs[1].Filter; len(f.TokenStandards) != 0 || f.MinAmount != "" || len(f.Methods) != 1 {
+		t.Errorf("filter after patch = %+v, want only methods", f)
+	}
+	if !strings.Contains(w.Body.String(), `"filter":{"methods":["Delegate"]}`) {
+		t.Errorf("response = %s, want the new filter", w.Body.String())
+	}
+
+	if w := wh.do("alice", http.MethodPatch, "/webhooks/1", `{"filter":{"token_standards":["znn"]}}`); w.Code != http.StatusBadRequest {
+		t.Errorf("bad token standard = %d, want 400", w.Code)
+	}
+	if w := wh.do("alice", http.MethodPatch, "/webhooks/1", `{"filter":{}}`); w.Code != http.StatusOK || !repo.subs[1].Filter.IsZero() {
+		t.Errorf("clearing the filter = %d %+v", w.Code, repo.subs[1].Filter)
+	}
+}
+
+func TestWebhooksRotateSecretAndDelete(t *testing.T) {
+	repo := newFakeWebhookRepo()
+	_ = repo.Create(context.Background(), &models.WebhookSubscription{Owner: "alice", URL: "https://a", Secret: "old"})
+	wh := newWebhookHarness(t, repo)
+
+	w := wh.do("alice", http.MethodPost, "/webhooks/1/rotate-secret", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("rotate = %d", w.Code)
+	}
+	var got struct {
+		Secret                  string `json:"secret"`
+		PreviousSecretExpiresAt int64  `json:"previous_secret_expires_at"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &got)
+	if got.Secret == "" || got.Secret == "old" || repo.subs[1].Secret != got.Secret {
+		t.Errorf("rotated secret %q, stored %q", got.Secret, repo.subs[1].Secret)
+	}
+	// The old secret keeps signing for the grace period.
+	want := int64(1700000000 + 24*60*60)
+	if repo.subs[1].PreviousSecret != "old" || got.PreviousSecretExpiresAt != want {
+		t.Errorf("previous secret %q until %d, want \"old\" until %d",
+			repo.subs[1].PreviousSecret, got.PreviousSecretExpiresAt, want)
+	}
+	if strings.Contains(w.Body.String(), `"old"`) {
+		t.Error("response reveals the previous secret")
+	}
+
+	if w := wh.do("alice", http.MethodDelete, "/webhooks/1", ""); w.Code != http.StatusNoContent {
+		t.Errorf("delete = %d, want 204", w.Code)
+	}
+	if w := wh.do("alice", http.MethodDelete, "/webhooks/1", ""); w.Code != http.StatusNotFound {
+		t.Errorf("second delete = %d, want 404", w.Code)
+	}
+}
+
+func TestWebhooksTest(t *testing.T) {
+	repo := newFakeWebhookRepo()
+	_ = repo.Create(context.Background(), &models.WebhookSubscription{
+		Owner: "alice", URL: "https://a", Events: []string{"reorg"}, Status: "active",
+	})
+	wh := newWebhookHarness(t, repo)
+
+	w := wh.do("alice", http.MethodPost, "/webhooks/1/test", "")
+	if w.Code != http.StatusAccepted || !strings.Contains(w.Body.String(), `"outbox_id":1`) {
+		t.Fatalf("test = %d %s", w.Code, w.Body.String())
+	}
+	e := repo.queued[0]
+	if e.EventType != webhooks.EventTest || e.SubscriptionID == nil || *e.SubscriptionID != 1 ||
+		e.EndpointURL != "https://a" || e.NextAttemptAt != 1700000000 {
+		t.Errorf("queued = %+v", e)
+	}
+
+	repo.subs[1].Status = "paused"
+	if w := wh.do("alice", http.MethodPost, "/webhooks/1/test", ""); w.Code != http.StatusConflict {
+		t.Errorf("test while paused = %d, want 409", w.Code)
+	}
+}
+
+func TestWebhooksDeliveries(t *testing.T) {
+	repo := newFakeWebhookRepo()
+	_ = repo.Create(context.Background(), &models.WebhookSubscription{Owner: "alice", URL: "https://a"})
+	code := 503
+	msg := "unexpected status 503"
+	repo.deliveries = []*models.WebhookDelivery{{
+		WebhookDeliveryAttempt: models.WebhookDeliveryAttempt{
+			OutboxID: 7, Attempt: 2, AttemptedAt: 1700000000, StatusCode: &code, Error: &msg, DurationMs: 41,
+		},
+		EventType: "reorg", MomentumHeight: 100, Status: "pending",
+	}}
+	wh := newWebhookHarness(t, repo)
+
+	w := wh.do("alice", http.MethodGet, "/webhooks/1/deliveries", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	for _, want := range []string{`"status_code":503`, `"duration_ms":41`, `"delivery_status":"pending"`, `"total":1`} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("body missing %s: %s", want, w.Body.String())
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -24,6 +25,9 @@ const (
 	codeMissingToken = "missing_token"
 	codeInvalidToken = "invalid_token"
 	codeExpiredToken = "expired_token"
+	// codeInsufficientScope is a valid token that lacks the scope a
+	// route requires (403, not 401: re-authenticating will not help).
+	codeInsufficientScope = "insufficient_scope"
 )
 
 // Auth returns middleware that verifies the Authorization: Bearer <token>
@@ -68,6 +72,22 @@ func Auth(signer *auth.Signer) func(http.Handler) http.Handler {
 	}
 }
 
+// RequireScope returns middleware that lets a request through only when
+// the claims attached by Auth carry scope. Mount it after Auth.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims := ClaimsFromContext(r.Context())
+			if claims == nil || !slices.Contains(claims.Scopes(), scope) {
+				httpx.WriteProblem(w, http.StatusForbidden, codeInsufficientScope,
+					"token lacks the "+scope+" scope")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // ClaimsFromContext returns the *auth.Claims attached by Auth, or nil.
 func ClaimsFromContext(ctx context.Context) *auth.Claims {
 	c, _ := ctx.Value(claimsKey{}).(*auth.Claims)
@@ -194,6 +194,32 @@ func TestAuth_PassesValidTokenAndAttachesClaims(t *testing.T) {
 	}
 }
 
+func TestRequireScope(t *testing.T) {
+	signer, _ := auth.NewSigner("secret")
+	h := Auth(signer)(RequireScope("webhooks")(noopHandler))
+
+	for _, tc := range []struct {
+		scopes []string
+		want   int
+	}{
+		{[]string{"read"}, http.StatusForbidden},
+		{nil, http.StatusForbidden},
+		{[]string{"read", "webhooks"}, http.StatusOK},
+	} {
+		tok, _ := signer.Issue("alice", time.Hour, tc.scopes)
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", "Bearer "+tok)
+		h.ServeHTTP(rr, req)
+		if rr.Code != tc.want {
+			t.Errorf("scopes %v: status = %d, want %d", tc.scopes, rr.Code, tc.want)
+		}
+		if tc.want == http.StatusForbidden && !strings.Contains(rr.Body.String(), `"insufficient_scope"`) {
+			t.Errorf("scopes %v: body = %s, want insufficient_scope", tc.scopes, rr.Body.String())
+		}
+	}
+}
+
 func TestBearerToken_Tolerant(t *testing.T) {
 	tests := []struct {
 		header    string
@@ -43,6 +43,7 @@ type Deps struct {
 	Pool               *pgxpool.Pool                  // used by /readyz to ping the DB; may be nil in tests
 	Hub                *stream.Hub[*dto.Momentum]     // optional; required for /api/v1/momentums/stream
 	TxHub              *stream.Hub[*dto.AccountBlock] // optional; required for /api/v1/transactions/stream
+	ReorgHub           *stream.Hub[*dto.Reorg]        // optional; adds reorg frames to both streams
 	Metrics            MetricsMiddleware
 	CORSAllowedOrigins []string
 	RateLimitPerMinute int
@@ -50,6 +51,10 @@ type Deps struct {
 	Now                func() time.Time // injected for testability; falls back to time.Now
 }
 
+// WebhooksScope is the token scope required by the /api/v1/webhooks
+// subscription-management routes.
+const WebhooksScope = "webhooks"
+
 // New builds the chi router with the full middleware stack and route
 // table. It is the single source of truth for which paths exist.
 func New(d Deps) http.Handler {
@@ -94,7 +99,7 @@ func New(d Deps) http.Handler {
 				"momentum stream hub is not configured on this instance")
 			return
 		}
-		handlers.MomentumsStream(d.Signer, d.Hub, d.Repos.Momentum)(w, r)
+		handlers.MomentumsStream(d.Signer, d.Hub, d.ReorgHub, d.Repos.Momentum)(w, r)
 	})
 	r.Get("/api/v1/transactions/stream", func(w http.ResponseWriter, r *http.Request) {
 		if d.TxHub == nil {
@@ -102,7 +107,7 @@ func New(d Deps) http.Handler {
 				"transactions stream hub is not configured on this instance")
 			return
 		}
-		handlers.TransactionsStream(d.Signer, d.TxHub, d.Repos.AccountBlock, d.Repos.Momentum)(w, r)
+		handlers.TransactionsStream(d.Signer, d.TxHub, d.ReorgHub, d.Repos.AccountBlock, d.Repos.Momentum)(w, r)
 	})
 
 	// Authenticated /api/v1 subtree.
@@ -111,6 +116,7 @@ func New(d Deps) http.Handler {
 		r.Use(apimw.RateLimit(d.RateLimitPerMinute))
 
 		r.Get("/status", handlers.Status(d.Repos.Momentum, d.Version, d.Now))
+		r.Get("/failed-heights", handlers.FailedHeightsList(d.Repos.FailedHeight))
 
 		// Flat routes (no Route() subgroup) so chi walks them with the
 		// exact paths advertised in openapi.yaml — see router_test.go.
@@ -120,6 +126,7 @@ func New(d Deps) http.Handler {
 
 		r.Get("/accounts/{address}", handlers.AccountsGet(d.Repos.Account))
 		r.Get("/accounts/{address}/balances", handlers.AccountsBalances(d.Repos.Balance))
+		r.Get("/accounts/{address}/balances/history", handlers.AccountsBalanceHistory(d.Repos.Balance))
 		r.Get("/accounts/{address}/transactions", handlers.AccountBlocksByAddress(d.Repos.AccountBlock))
 
 		r.Get("/account_blocks", handlers.AccountBlocksList(d.Repos.AccountBlock))
@@ -128,6 +135,7 @@ func New(d Deps) http.Handler {
 		r.Get("/tokens", handlers.TokensList(d.Repos.Token))
 		r.Get("/tokens/{token_standard}", handlers.TokensGet(d.Repos.Token))
 		r.Get("/tokens/{token_standard}/holders", handlers.TokensHolders(d.Repos.Balance))
+		r.Get("/tokens/{token_standard}/history", handlers.TokensHistory(d.Repos.TokenEvent))
 
 		r.Get("/pillars", handlers.PillarsList(d.Repos.Pillar))
 		r.Get("/pillars/{name}", handlers.PillarsGetByName(d.Repos.Pillar))
@@ -135,6 +143,7 @@ func New(d Deps) http.Handler {
 		r.Get("/pillars/{name}/voting-report", handlers.PillarsVotingHistory(d.Repos.Vote))
 
 		r.Get("/sentinels", handlers.SentinelsList(d.Repos.Sentinel))
+		r.Get("/sentinels/events", handlers.SentinelEvents(d.Repos.SentinelEvent))
 
 		r.Get("/stakes", handlers.StakesList(d.Repos.Stake))
 		r.Get("/accounts/{address}/stakes", handlers.StakesByAddress(d.Repos.Stake))
@@ -145,16 +154,43 @@ func New(d Deps) http.Handler {
 		r.Get("/accounts/{address}/rewards", handlers.RewardsHistory(d.Repos.Reward))
 		r.Get("/accounts/{address}/rewards/cumulative", handlers.RewardsCumulative(d.Repos.Reward))
 
+		r.Get("/sporks", handlers.SporksList(d.Repos.Spork))
+		r.Get("/sporks/{id}", handlers.SporksGet(d.Repos.Spork))
+
+		r.Get("/liquidity/config", handlers.LiquidityConfigGet(d.Repos.Liquidity))
+		r.Get("/liquidity/admin-actions", handlers.LiquidityAdminActions(d.Repos.Liquidity))
+		r.Get("/liquidity/stakes", handlers.LiquidityStakesList(d.Repos.Liquidity))
+		r.Get("/accounts/{address}/liquidity/stakes", handlers.LiquidityStakesByAddress(d.Repos.Liquidity))
+
 		r.Get("/projects", handlers.ProjectsList(d.Repos.Project))
 		r.Get("/projects/{id}", handlers.ProjectsGet(d.Repos.Project))
 		r.Get("/projects/{id}/phases", handlers.ProjectsPhases(d.Repos.ProjectPhase))
 		r.Get("/projects/{id}/votes", handlers.ProjectsVotes(d.Repos.Vote))
 		r.Get("/projects/{id}/voting-report", handlers.ProjectsVotingReport(d.Repos.Vote))
+		r.Get("/projects/{id}/history", handlers.ProjectsHistory(d.Repos.Accelerator))
+		r.Get("/accelerator/payouts", handlers.AcceleratorPayouts(d.Repos.Accelerator))
+		r.Get("/accelerator/treasury", handlers.AcceleratorTreasury(d.Repos.Accelerator))
 
 		r.Get("/bridge/wraps", handlers.BridgeWraps(d.Repos.Bridge))
 		r.Get("/bridge/unwraps", handlers.BridgeUnwraps(d.Repos.Bridge))
+		r.Get("/bridge/events", handlers.BridgeEvents(d.Repos.BridgeEvent))
 		r.Get("/accounts/{address}/bridge/wraps", handlers.BridgeWrapsByAddress(d.Repos.Bridge))
 		r.Get("/accounts/{address}/bridge/unwraps", handlers.BridgeUnwrapsByAddress(d.Repos.Bridge))
+
+		// Webhook subscription management. The only write surface in the
+		// API, so it needs the webhooks scope on top of a valid token, and
+		// every call is scoped to the token's subject.
+		r.Group(func(r chi.Router) {
+			r.Use(apimw.RequireScope(WebhooksScope))
+			r.Get("/webhooks", handlers.WebhooksList(d.Repos.WebhookSubscription))
+			r.Post("/webhooks", handlers.WebhooksCreate(d.Repos.WebhookSubscription, d.Now))
+			r.Get("/webhooks/{id}", handlers.WebhooksGet(d.Repos.WebhookSubscription))
+			r.Patch("/webhooks/{id}", handlers.WebhooksUpdate(d.Repos.WebhookSubscription, d.Now))
+			r.Delete("/webhooks/{id}", handlers.WebhooksDelete(d.Repos.WebhookSubscription))
+			r.Post("/webhooks/{id}/rotate-secret", handlers.WebhooksRotateSecret(d.Repos.WebhookSubscription, d.Now))
+			r.Post("/webhooks/{id}/test", handlers.WebhooksTest(d.Repos.WebhookSubscription, d.Repos.WebhookOutbox, d.Now))
+			r.Get("/webhooks/{id}/deliveries", handlers.WebhooksDeliveries(d.Repos.WebhookSubscription, d.Repos.WebhookOutbox))
+		})
 	})
 
 	return r
@@ -171,9 +207,15 @@ func healthz(w http.ResponseWriter, _ *http.Request) {
 // some /api/v1/* endpoint will 500 on a missing table; bumping too
 // aggressively (i.e. before the migration actually ships in operators'
 // indexer image) means /readyz stays 503 after a deploy. Today the API
-// reads account counter columns added through 012 and indexer_sync_status
-// added in 013.
-const minSchemaVersion = 13 // bumped from 12 — adds indexer_sync_status table for /readyz drift checks
+// reads account counter columns added through 012, indexer_sync_status
+// added in 013, the NUMERIC amount columns from 017, the webhook
+// subscription tables from 019, their filter column from 020, the
+// delivery ids and previous secrets from 021, indexer_failed_heights
+// from 023, sporks from 026, the liquidity tables from 027,
+// bridge_events from 028, sentinel_events from 029, token_events
+// from 030, project_status_changes and accelerator_payouts from 031,
+// balance_history from 033, and liquidity_admin_actions from 036.
+const minSchemaVersion = 36 // bumped from 33 — /api/v1/liquidity/admin-actions reads liquidity_admin_actions
 
 // unhealthyStreakForReady is the number of consecutive non-"synced" ticks
 // the watchdog must record before /readyz starts returning 503. Matches
@@ -20,9 +20,9 @@ import (
 	"github.com/0x3639/nom-indexer-go/internal/repository"
 )
 
-// TestRouterMatchesOpenAPISpec asserts that every path documented in
-// docs/api/openapi.yaml is registered on the chi router, and vice
-// versa. This is the drift-detection backstop the plan calls for —
+// TestRouterMatchesOpenAPISpec asserts that every operation (method +
+// path) documented in docs/api/openapi.yaml is registered on the chi
+// router, and vice versa. This is the drift-detection backstop the plan calls for —
 // without it, the spec and the implementation can diverge silently.
 //
 // When this test fails, either:
@@ -41,12 +41,12 @@ func TestRouterMatchesOpenAPISpec(t *testing.T) {
 
 	for _, p := range specPaths {
 		if !routerSet[p] {
-			t.Errorf("openapi path %q has no matching route in router.New", p)
+			t.Errorf("openapi operation %q has no matching route in router.New", p)
 		}
 	}
 	for _, p := range routerPaths {
 		if !specSet[p] {
-			t.Errorf("router path %q is not documented in docs/api/openapi.yaml", p)
+			t.Errorf("router route %q is not documented in docs/api/openapi.yaml", p)
 		}
 	}
 }
@@ -85,10 +85,8 @@ func specPathsOf(doc *openapi3.T) []string {
 		if item == nil {
 			continue
 		}
-		// Only consider paths that expose at least one GET — those are the
-		// ones we actually serve today.
-		if item.Get != nil {
-			out = append(out, path)
+		for method := range item.Operations() {
+			out = append(out, method+" "+path)
 		}
 	}
 	return out
@@ -102,10 +100,7 @@ func routerPathsOf(t *testing.T, h http.Handler) []string {
 	}
 	var out []string
 	walkErr := chi.Walk(r, func(method string, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
-		if method != http.MethodGet {
-			return nil
-		}
-		out = append(out, route)
+		out = append(out, method+" "+route)
 		return nil
 	})
 	if walkErr != nil {
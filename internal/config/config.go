@@ -33,25 +33,72 @@ type WebhooksConfig struct {
 	Endpoints []WebhookEndpoint `mapstructure:"endpoints"`
 	// TimeoutSeconds is the per-request HTTP timeout (default 5).
 	TimeoutSeconds int `mapstructure:"timeout_seconds"`
-	// MaxRetries is the number of resend attempts on failure (default 3).
+	// MaxRetries is the number of resend attempts on failure before an
+	// outbox row is dead-lettered (default 10).
 	MaxRetries int `mapstructure:"max_retries"`
+	// RetryBackoffSeconds is the delay before the first retry; it doubles
+	// on each further retry, with jitter (default 2).
+	RetryBackoffSeconds int `mapstructure:"retry_backoff_seconds"`
+	// RetryBackoffMaxSeconds caps the retry delay (default 3600).
+	RetryBackoffMaxSeconds int `mapstructure:"retry_backoff_max_seconds"`
+	// RetentionHours is how long delivered outbox rows are kept before
+	// pruning (default 168). Dead-lettered rows are kept until replayed.
+	RetentionHours int `mapstructure:"retention_hours"`
+	// ReloadSeconds is how often subscriptions registered through the API
+	// are re-read from the database (default 5).
+	ReloadSeconds int `mapstructure:"reload_seconds"`
+	// CircuitFailureThreshold is how many consecutive failed deliveries
+	// pause an endpoint (default 5).
+	CircuitFailureThreshold int `mapstructure:"circuit_failure_threshold"`
+	// CircuitCooldownSeconds is how long a paused endpoint waits before
+	// one delivery probes it again (default 60).
+	CircuitCooldownSeconds int `mapstructure:"circuit_cooldown_seconds"`
 }
 
 // WebhookEndpoint is one subscriber.
 type WebhookEndpoint struct {
 	URL    string `mapstructure:"url"`
 	Secret string `mapstructure:"secret"`
+	// PreviousSecret also signs deliveries while you rotate Secret;
+	// remove it once every receiver accepts the new one.
+	PreviousSecret string `mapstructure:"previous_secret"`
 	// Events is the set this endpoint receives; empty means all.
 	Events []string `mapstructure:"events"`
+	// Filter narrows Events by content; empty fields don't filter.
+	Filter WebhookFilterConfig `mapstructure:"filter"`
+}
+
+// WebhookFilterConfig is an endpoint's content filter. An event must
+// match every non-empty field, and any one value within a field.
+type WebhookFilterConfig struct {
+	// Addresses matches events involving any of these addresses
+	// (sender, recipient, or the address a contract event concerns).
+	Addresses []string `mapstructure:"addresses"`
+	// TokenStandards matches events moving one of these ZTS tokens.
+	TokenStandards []string `mapstructure:"token_standards"`
+	// Methods matches events from these embedded-contract methods.
+	Methods []string `mapstructure:"methods"`
+	// BlockTypes matches account blocks of these types (1-5).
+	BlockTypes []int `mapstructure:"block_types"`
+	// MinAmount drops events moving less than this many base units.
+	MinAmount string `mapstructure:"min_amount"`
 }
 
 // IndexerConfig groups the indexer-process-only settings: the prioritized
-// list of upstream nodes, the sync watchdog policy, and the indexer's
-// own HTTP health server. The API and MCP processes do not consult it.
+// list of upstream nodes, the sync watchdog policy, the catch-up pipeline,
+// the failed-height retrier, the periodic verifier, the balance
+// reconciliation job, and the indexer's own
+// HTTP health and metrics servers. The API and MCP processes do not
+// consult it.
 type IndexerConfig struct {
-	Nodes    []NodeEntry    `mapstructure:"nodes"`
-	Watchdog WatchdogConfig `mapstructure:"watchdog"`
-	Health   HealthConfig   `mapstructure:"health"`
+	Nodes         []NodeEntry          `mapstructure:"nodes"`
+	Watchdog      WatchdogConfig       `mapstructure:"watchdog"`
+	Health        HealthConfig         `mapstructure:"health"`
+	Metrics       IndexerMetricsConfig `mapstructure:"metrics"`
+	CatchUp       CatchUpConfig        `mapstructure:"catchup"`
+	FailedHeights FailedHeightsConfig  `mapstructure:"failed_heights"`
+	Verify        VerifyConfig         `mapstructure:"verify"`
+	Reconcile     ReconcileConfig      `mapstructure:"reconcile"`
 }
 
 // NodeEntry is one upstream Zenon node. URL accepts ws://, wss://,
@@ -109,6 +156,79 @@ type HealthConfig struct {
 	Port    int  `mapstructure:"port"`
 }
 
+// IndexerMetricsConfig configures the indexer's Prometheus listener.
+// Separate from the health server so scrapes and compose healthchecks
+// don't share a port, matching the API and MCP split-listener layout.
+type IndexerMetricsConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	Port    int  `mapstructure:"port"`
+}
+
+// CatchUpConfig tunes the pipelined catch-up sync. Workers only talk to
+// the node; a single committer still writes one transaction per
+// momentum in height order. See docs/operations/sync-and-recovery.md.
+type CatchUpConfig struct {
+	// Enabled switches catch-up from the serial fetch-then-commit loop
+	// to the prefetching pipeline.
+	Enabled bool `mapstructure:"enabled"`
+	// MomentumWorkers is the number of concurrent momentum page fetches
+	// (100 momentums per page).
+	MomentumWorkers int `mapstructure:"momentum_workers"`
+	// BlockWorkers is the number of concurrent account-block and
+	// account-info fetches.
+	BlockWorkers int `mapstructure:"block_workers"`
+	// PrefetchDepth caps how many momentums may sit fetched and decoded
+	// ahead of the committer.
+	PrefetchDepth int `mapstructure:"prefetch_depth"`
+	// FanOut spreads catch-up reads across every node in indexer.nodes
+	// the watchdog reports synced on the same chain. Needs the watchdog
+	// and at least two nodes; otherwise all reads use the active node.
+	FanOut bool `mapstructure:"fan_out"`
+}
+
+// FailedHeightsConfig tunes the retrier that re-processes momentum
+// heights backfill failed on (indexer_failed_heights). See
+// docs/operations/backfill.md.
+type FailedHeightsConfig struct {
+	// Enabled runs the retrier inside the indexer process.
+	Enabled bool `mapstructure:"enabled"`
+	// Interval is how often the retrier looks for due heights.
+	Interval time.Duration `mapstructure:"interval"`
+	// Backoff is the delay before a height's first retry; it doubles
+	// with every further failure.
+	Backoff time.Duration `mapstructure:"backoff"`
+	// MaxBackoff caps the retry delay.
+	MaxBackoff time.Duration `mapstructure:"max_backoff"`
+}
+
+// VerifyConfig schedules the sampled comparison of indexed data with the
+// node that cmd/verify runs on demand. See docs/operations/verify.md.
+type VerifyConfig struct {
+	// Enabled runs the job inside the indexer process.
+	Enabled bool `mapstructure:"enabled"`
+	// Interval is the time between runs.
+	Interval time.Duration `mapstructure:"interval"`
+	// Samples is how many heights, and addresses per check, a run
+	// compares.
+	Samples int `mapstructure:"samples"`
+	// Checks restricts the job to these checks; empty runs them all.
+	Checks []string `mapstructure:"checks"`
+	// Repair queues a fix for every difference found.
+	Repair bool `mapstructure:"repair"`
+}
+
+// ReconcileConfig schedules the job that compares balances with the node
+// a slice of accounts at a time and corrects the ones that drifted. See
+// docs/schema/balances.md.
+type ReconcileConfig struct {
+	// Enabled runs the job inside the indexer process.
+	Enabled bool `mapstructure:"enabled"`
+	// Interval is the time between runs.
+	Interval time.Duration `mapstructure:"interval"`
+	// Accounts is how many addresses a run compares.
+	Accounts int `mapstructure:"accounts"`
+}
+
 type NodeConfig struct {
 	WebSocketURL string `mapstructure:"ws_url"`
 }
@@ -275,9 +395,34 @@ func load(configPaths []string) (*Config, error) {
 	v.SetDefault("indexer.watchdog.failback_streak", 5)
 	v.SetDefault("indexer.health.enabled", true)
 	v.SetDefault("indexer.health.port", 9092)
+	v.SetDefault("indexer.metrics.enabled", true)
+	v.SetDefault("indexer.metrics.port", 9093)
+	v.SetDefault("indexer.catchup.enabled", true)
+	v.SetDefault("indexer.catchup.momentum_workers", 2)
+	v.SetDefault("indexer.catchup.block_workers", 8)
+	v.SetDefault("indexer.catchup.prefetch_depth", 256)
+	v.SetDefault("indexer.catchup.fan_out", true)
+	v.SetDefault("indexer.failed_heights.enabled", true)
+	v.SetDefault("indexer.failed_heights.interval", "1m")
+	v.SetDefault("indexer.failed_heights.backoff", "1m")
+	v.SetDefault("indexer.failed_heights.max_backoff", "1h")
+	v.SetDefault("indexer.verify.enabled", false)
+	v.SetDefault("indexer.verify.interval", "1h")
+	v.SetDefault("indexer.verify.samples", 100)
+	v.SetDefault("indexer.verify.checks", []string{})
+	v.SetDefault("indexer.verify.repair", false)
+	v.SetDefault("indexer.reconcile.enabled", true)
+	v.SetDefault("indexer.reconcile.interval", "10m")
+	v.SetDefault("indexer.reconcile.accounts", 1000)
 	v.SetDefault("webhooks.enabled", false)
 	v.SetDefault("webhooks.timeout_seconds", 5)
-	v.SetDefault("webhooks.max_retries", 3)
+	v.SetDefault("webhooks.max_retries", 10)
+	v.SetDefault("webhooks.retry_backoff_seconds", 2)
+	v.SetDefault("webhooks.retry_backoff_max_seconds", 3600)
+	v.SetDefault("webhooks.retention_hours", 168)
+	v.SetDefault("webhooks.reload_seconds", 5)
+	v.SetDefault("webhooks.circuit_failure_threshold", 5)
+	v.SetDefault("webhooks.circuit_cooldown_seconds", 60)
 
 	// Enable environment variable binding
 	v.AutomaticEnv()
@@ -313,6 +458,21 @@ func load(configPaths []string) (*Config, error) {
 	_ = v.BindEnv("indexer.watchdog.failback_streak", "INDEXER_WATCHDOG_FAILBACK_STREAK")
 	_ = v.BindEnv("indexer.health.enabled", "INDEXER_HEALTH_ENABLED")
 	_ = v.BindEnv("indexer.health.port", "INDEXER_HEALTH_PORT")
+	_ = v.BindEnv("indexer.metrics.enabled", "INDEXER_METRICS_ENABLED")
+	_ = v.BindEnv("indexer.metrics.port", "INDEXER_METRICS_PORT")
+	_ = v.BindEnv("indexer.catchup.enabled", "INDEXER_CATCHUP_ENABLED")
+	_ = v.BindEnv("indexer.catchup.momentum_workers", "INDEXER_CATCHUP_MOMENTUM_WORKERS")
+	_ = v.BindEnv("indexer.catchup.block_workers", "INDEXER_CATCHUP_BLOCK_WORKERS")
+	_ = v.BindEnv("indexer.catchup.prefetch_depth", "INDEXER_CATCHUP_PREFETCH_DEPTH")
+	_ = v.BindEnv("indexer.catchup.fan_out", "INDEXER_CATCHUP_FAN_OUT")
+	_ = v.BindEnv("indexer.failed_heights.enabled", "INDEXER_FAILED_HEIGHTS_ENABLED")
+	_ = v.BindEnv("indexer.failed_heights.interval", "INDEXER_FAILED_HEIGHTS_INTERVAL")
+	_ = v.BindEnv("indexer.verify.enabled", "INDEXER_VERIFY_ENABLED")
+	_ = v.BindEnv("indexer.verify.interval", "INDEXER_VERIFY_INTERVAL")
+	_ = v.BindEnv("indexer.verify.repair", "INDEXER_VERIFY_REPAIR")
+	_ = v.BindEnv("indexer.reconcile.enabled", "INDEXER_RECONCILE_ENABLED")
+	_ = v.BindEnv("indexer.reconcile.interval", "INDEXER_RECONCILE_INTERVAL")
+	_ = v.BindEnv("indexer.reconcile.accounts", "INDEXER_RECONCILE_ACCOUNTS")
 	_ = v.BindEnv("webhooks.enabled", "WEBHOOKS_ENABLED")
 
 	// Try to read config file (optional)
@@ -421,6 +581,18 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("database.password is required (set in config.yaml or DATABASE_PASSWORD env var)")
 	}
 
+	if c.Indexer.CatchUp.Enabled {
+		if c.Indexer.CatchUp.MomentumWorkers < 1 {
+			return fmt.Errorf("indexer.catchup.momentum_workers must be at least 1")
+		}
+		if c.Indexer.CatchUp.BlockWorkers < 1 {
+			return fmt.Errorf("indexer.catchup.block_workers must be at least 1")
+		}
+		if c.Indexer.CatchUp.PrefetchDepth < 1 {
+			return fmt.Errorf("indexer.catchup.prefetch_depth must be at least 1")
+		}
+	}
+
 	return nil
 }
 
@@ -123,6 +123,18 @@ func TestConfig_Validate(t *testing.T) {
 			modify:      func(c *Config) { c.Database.Password = "" },
 			expectError: "database.password is required",
 		},
+		{
+			name: "catchup enabled with zero block workers",
+			modify: func(c *Config) {
+				c.Indexer.CatchUp = CatchUpConfig{Enabled: true, MomentumWorkers: 1, BlockWorkers: 0, PrefetchDepth: 1}
+			},
+			expectError: "indexer.catchup.block_workers must be at least 1",
+		},
+		{
+			name:        "catchup disabled ignores worker counts",
+			modify:      func(c *Config) { c.Indexer.CatchUp = CatchUpConfig{} },
+			expectError: "",
+		},
 	}
 
 	for _, tt := range tests {
@@ -262,7 +274,10 @@ func TestLoad_WebhooksDefaults(t *testing.T) {
 	if cfg.Webhooks.Enabled {
 		t.Error("webhooks should default disabled")
 	}
-	if cfg.Webhooks.TimeoutSeconds != 5 || cfg.Webhooks.MaxRetries != 3 {
+	if cfg.Webhooks.TimeoutSeconds != 5 || cfg.Webhooks.MaxRetries != 10 ||
+		cfg.Webhooks.RetryBackoffSeconds != 2 || cfg.Webhooks.RetryBackoffMaxSeconds != 3600 ||
+		cfg.Webhooks.RetentionHours != 168 || cfg.Webhooks.CircuitFailureThreshold != 5 ||
+		cfg.Webhooks.CircuitCooldownSeconds != 60 {
 		t.Errorf("unexpected webhook defaults: %+v", cfg.Webhooks)
 	}
 }
@@ -288,3 +303,65 @@ func TestWatchdogConfigDefaults(t *testing.T) {
 		t.Fatalf("default health port: %d", cfg.Indexer.Health.Port)
 	}
 }
+
+func TestCatchUpConfigDefaults(t *testing.T) {
+	t.Setenv("DATABASE_PASSWORD", "x")
+	t.Setenv("API_JWT_SECRET", "y")
+	t.Setenv("NODE_URL_WS", "ws://znnd:35998")
+	t.Setenv("INDEXER_CATCHUP_BLOCK_WORKERS", "16")
+	cfg, err := load(nil)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := CatchUpConfig{Enabled: true, MomentumWorkers: 2, BlockWorkers: 16, PrefetchDepth: 256, FanOut: true}
+	if cfg.Indexer.CatchUp != want {
+		t.Fatalf("catchup = %+v, want %+v", cfg.Indexer.CatchUp, want)
+	}
+	if !cfg.Indexer.Metrics.Enabled || cfg.Indexer.Metrics.Port != 9093 {
+		t.Fatalf("indexer metrics defaults: %+v", cfg.Indexer.Metrics)
+	}
+}
+
+func TestFailedHeightsConfigDefaults(t *testing.T) {
+	t.Setenv("DATABASE_PASSWORD", "x")
+	t.Setenv("API_JWT_SECRET", "y")
+	t.Setenv("NODE_URL_WS", "ws://znnd:35998")
+	t.Setenv("INDEXER_FAILED_HEIGHTS_INTERVAL", "30s")
+	cfg, err := load(nil)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := FailedHeightsConfig{Enabled: true, Interval: 30 * time.Second, Backoff: time.Minute, MaxBackoff: time.Hour}
+	if cfg.Indexer.FailedHeights != want {
+		t.Fatalf("failed_heights = %+v, want %+v", cfg.Indexer.FailedHeights, want)
+	}
+}
+
+func TestVerifyConfigDefaults(t *testing.T) {
+	t.Setenv("DATABASE_PASSWORD", "x")
+	t.Setenv("API_JWT_SECRET", "y")
+	t.Setenv("NODE_URL_WS", "ws://znnd:35998")
+	t.Setenv("INDEXER_VERIFY_ENABLED", "true")
+	cfg, err := load(nil)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	v := cfg.Indexer.Verify
+	if !v.Enabled || v.Interval != time.Hour || v.Samples != 100 || len(v.Checks) != 0 || v.Repair {
+		t.Fatalf("verify = %+v", v)
+	}
+}
+
+func TestReconcileConfigDefaults(t *testing.T) {
+	t.Setenv("DATABASE_PASSWORD", "x")
+	t.Setenv("API_JWT_SECRET", "y")
+	t.Setenv("NODE_URL_WS", "ws://znnd:35998")
+	cfg, err := load(nil)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := ReconcileConfig{Enabled: true, Interval: 10 * time.Minute, Accounts: 1000}
+	if cfg.Indexer.Reconcile != want {
+		t.Fatalf("reconcile = %+v, want %+v", cfg.Indexer.Reconcile, want)
+	}
+}
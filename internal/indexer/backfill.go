@@ -0,0 +1,431 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0x3639/znn-sdk-go/utils"
+	"github.com/jackc/pgx/v5"
+	"github.com/zenon-network/go-zenon/rpc/api"
+	"go.uber.org/zap"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+// backfillScanChunk is how many heights one gap query covers. It keeps
+// each query bounded however long the chain grows, and is the checkpoint
+// granularity of a gap run.
+const backfillScanChunk = 10_000
+
+// backfillCheckpointEvery is how many reprocessed heights pass between
+// checkpoint writes.
+const backfillCheckpointEvery = 1000
+
+// BackfillContracts maps the contract names BackfillOptions.Contracts
+// accepts to the embedded contract whose handler each re-runs.
+var BackfillContracts = map[string]string{
+	"pillar":      models.PillarAddress,
+	"stake":       models.StakeAddress,
+	"sentinel":    models.SentinelAddress,
+	"plasma":      models.PlasmaAddress,
+	"accelerator": models.AcceleratorAddress,
+	"token":       models.TokenAddress,
+	"htlc":        models.HtlcAddress,
+	"swap":        models.SwapAddress,
+	"spork":       models.SporkAddress,
+	"liquidity":   models.LiquidityAddress,
+	"bridge":      models.BridgeAddress,
+}
+
+// BackfillOptions selects the heights a backfill covers and what it does
+// with them. The zero value fills every gap up to the highest indexed
+// momentum, without a checkpoint.
+type BackfillOptions struct {
+	// From and To bound the run, inclusive. From 0 means 1; To 0 means
+	// the highest indexed momentum when the run starts.
+	From, To uint64
+	// Reprocess rewrites every height in range instead of only missing
+	// or incomplete ones.
+	Reprocess bool
+	// Contracts, with Reprocess, re-runs only these embedded contracts'
+	// handlers (keys of BackfillContracts) and leaves momentums, account
+	// blocks and balances alone.
+	Contracts []string
+	// Workers is the number of concurrent momentum-page fetches; four
+	// times as many fetch account blocks. Commits stay in height order.
+	// Default 1.
+	Workers int
+	// Checkpoint names the backfill_checkpoints row that records
+	// progress. A run whose checkpoint is unfinished resumes after the
+	// last height it recorded. Empty disables checkpointing.
+	Checkpoint string
+	// Restart discards the checkpoint's progress and starts over.
+	Restart bool
+}
+
+// Validate reports whether o is usable.
+func (o BackfillOptions) Validate() error {
+	if o.To != 0 && o.From > o.To {
+		return fmt.Errorf("from %d is above to %d", o.From, o.To)
+	}
+	if len(o.Contracts) > 0 && !o.Reprocess {
+		return errors.New("contracts needs reprocess: a gap is filled whole")
+	}
+	for _, c := range o.Contracts {
+		if _, ok := BackfillContracts[c]; !ok {
+			return fmt.Errorf("unknown contract %q", c)
+		}
+	}
+	if o.Workers < 0 {
+		return fmt.Errorf("workers must be positive, got %d", o.Workers)
+	}
+	return nil
+}
+
+// mode returns the checkpoint mode o runs in.
+func (o BackfillOptions) mode() string {
+	if o.Reprocess {
+		return models.BackfillModeReprocess
+	}
+	return models.BackfillModeGaps
+}
+
+// CheckpointName derives a checkpoint name from o's mode, range and
+// contracts, e.g. "reprocess:1-head:pillar,stake", so that re-running
+// the same command resumes the same run.
+func (o BackfillOptions) CheckpointName() string {
+	to := "head"
+	if o.To != 0 {
+		to = strconv.FormatUint(o.To, 10)
+	}
+	name := fmt.Sprintf("%s:%d-%s", o.mode(), max(o.From, 1), to)
+	if len(o.Contracts) > 0 {
+		name += ":" + strings.Join(sortedContracts(o.Contracts), ",")
+	}
+	return name
+}
+
+func sortedContracts(in []string) []string {
+	out := slices.Clone(in)
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
+// Backfill fills every missing or incomplete momentum up to the highest
+// indexed height. Missing = height gaps in the momentums table;
+// incomplete = momentums whose tx_count exceeds the account blocks
+// stored for them.
+func (i *Indexer) Backfill(ctx context.Context) error {
+	return i.BackfillRange(ctx, BackfillOptions{})
+}
+
+// BackfillRange runs the backfill opts describes. Momentums are fetched
+// by the catch-up pipeline, opts.Workers pages at a time, and committed
+// in height order. With a checkpoint, progress is recorded as the run
+// goes, and an interrupted run resumes where it stopped.
+func (i *Indexer) BackfillRange(ctx context.Context, opts BackfillOptions) error {
+	if err := opts.Validate(); err != nil {
+		return fmt.Errorf("backfill: %w", err)
+	}
+	from, to := max(opts.From, 1), opts.To
+	if to == 0 {
+		latest, err := i.repos.Momentum.GetLatestHeight(ctx)
+		if err != nil {
+			return fmt.Errorf("backfill: latest indexed height: %w", err)
+		}
+		to = latest
+	}
+
+	next := from
+	if opts.Checkpoint != "" {
+		existing, err := i.repos.BackfillCheckpoint.Get(ctx, opts.Checkpoint)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("backfill: %w", err)
+		}
+		cp, resume, err := resolveCheckpoint(existing, opts, from, to, time.Now().Unix())
+		if err != nil {
+			return fmt.Errorf("backfill: %w", err)
+		}
+		if cp.CompletedAt != nil {
+			i.logger.Info("backfill: checkpoint already complete; restart it to run again",
+				zap.String("checkpoint", cp.Name))
+			return nil
+		}
+		if !resume {
+			if err := i.repos.BackfillCheckpoint.Save(ctx, cp); err != nil {
+				return fmt.Errorf("backfill: %w", err)
+			}
+		}
+		next, to = cp.NextHeight, cp.ToHeight
+		if resume {
+			i.logger.Info("backfill: resuming from checkpoint",
+				zap.String("checkpoint", cp.Name), zap.Uint64("next_height", next))
+		}
+	}
+
+	i.logger.Info("backfill: starting",
+		zap.String("mode", opts.mode()),
+		zap.Uint64("from", next),
+		zap.Uint64("to", to),
+		zap.Strings("contracts", opts.Contracts),
+		zap.Int("workers", max(opts.Workers, 1)),
+		zap.String("checkpoint", opts.Checkpoint))
+
+	var (
+		done int
+		err  error
+	)
+	if opts.Reprocess {
+		done, err = i.reprocessRange(ctx, opts, next, to)
+	} else {
+		done, err = i.fillGaps(ctx, opts, next, to)
+	}
+	if err != nil {
+		return err
+	}
+	if opts.Checkpoint != "" {
+		if err := i.repos.BackfillCheckpoint.Complete(ctx, opts.Checkpoint, time.Now().Unix()); err != nil {
+			return fmt.Errorf("backfill: %w", err)
+		}
+	}
+	i.logger.Info("backfill: complete", zap.String("mode", opts.mode()), zap.Int("processed", done))
+	return nil
+}
+
+// resolveCheckpoint decides where a run starts. With no existing
+// checkpoint, with opts.Restart, or when a finished run to "head" is
+// asked for again (the head has moved on), it returns a fresh one to
+// save (resume false). Otherwise it returns the existing one, after
+// checking that it was made for the same kind of run; the stored range
+// wins over from/to, so a run to "head" keeps the head it started with.
+// A finished checkpoint for a fixed range comes back as is.
+func resolveCheckpoint(existing *models.BackfillCheckpoint, opts BackfillOptions, from, to uint64, now int64) (cp *models.BackfillCheckpoint, resume bool, err error) {
+	if existing != nil && !opts.Restart && (existing.Mode != opts.mode() || existing.FromHeight != from ||
+		!slices.Equal(existing.Contracts, sortedContracts(opts.Contracts)) ||
+		(opts.To != 0 && existing.ToHeight != opts.To)) {
+		return nil, false, fmt.Errorf("checkpoint %q belongs to a different run (%s %d-%d %v); restart it or pick another name",
+			existing.Name, existing.Mode, existing.FromHeight, existing.ToHeight, existing.Contracts)
+	}
+	if existing == nil || opts.Restart || (existing.CompletedAt != nil && opts.To == 0) {
+		return &models.BackfillCheckpoint{
+			Name:       opts.Checkpoint,
+			Mode:       opts.mode(),
+			Contracts:  sortedContracts(opts.Contracts),
+			FromHeight: from,
+			ToHeight:   to,
+			NextHeight: from,
+			StartedAt:  now,
+			UpdatedAt:  now,
+		}, false, nil
+	}
+	return existing, true, nil
+}
+
+// fillGaps fills the missing and incomplete heights in [from, to],
+// scanning backfillScanChunk heights at a time and checkpointing after
+// each chunk. Returns how many heights it processed.
+func (i *Indexer) fillGaps(ctx context.Context, opts BackfillOptions, from, to uint64) (int, error) {
+	p := i.backfillPipeline(opts, i.prepareFull, i.commitGap)
+	done := 0
+	for start := from; start <= to; start += backfillScanChunk {
+		end := min(start+backfillScanChunk-1, to)
+		gaps, err := i.repos.Momentum.GapsInRange(ctx, start, end)
+		if err != nil {
+			return done, fmt.Errorf("backfill: %w", err)
+		}
+		if len(gaps) > 0 {
+			i.logger.Info("backfill: found gaps to fill",
+				zap.Uint64("from", start), zap.Uint64("to", end), zap.Int("count", len(gaps)))
+		}
+		for _, r := range heightRuns(gaps) {
+			n, err := p.run(ctx, r[0], r[1])
+			done += n
+			if err != nil {
+				return done, fmt.Errorf("backfill: heights %d-%d: %w", r[0], r[1], err)
+			}
+			if want := int(r[1] - r[0] + 1); n < want {
+				return done, fmt.Errorf("backfill: node returned %d of %d momentums at %d-%d", n, want, r[0], r[1])
+			}
+		}
+		i.advanceCheckpoint(ctx, opts.Checkpoint, end+1)
+	}
+	return done, nil
+}
+
+// heightRuns groups ascending heights into inclusive runs of
+// consecutive heights, so each run is one pipeline pass.
+func heightRuns(heights []uint64) [][2]uint64 {
+	var out [][2]uint64
+	for _, h := range heights {
+		if n := len(out); n > 0 && out[n-1][1]+1 == h {
+			out[n-1][1] = h
+			continue
+		}
+		out = append(out, [2]uint64{h, h})
+	}
+	return out
+}
+
+// commitGap commits a gap's momentum. A momentum that does not extend
+// the indexed chain is left for live sync's rollback; any other failure
+// is queued in indexer_failed_heights for the retrier. The run carries
+// on either way.
+func (i *Indexer) commitGap(ctx context.Context, pm *prefetchedMomentum) error {
+	err := i.commitMomentum(ctx, pm)
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	height := pm.momentum.Height
+	// A neighbour of this gap is on a different fork than the node.
+	// Committing anything here would stitch the two forks together.
+	var rerr *reorgError
+	if errors.As(err, &rerr) {
+		i.logger.Warn("backfill: momentum does not extend indexed chain, skipping",
+			zap.Uint64("height", height), zap.Error(err))
+		return nil
+	}
+	i.logger.Error("backfill: failed to process momentum, queued for retry",
+		zap.Uint64("height", height), zap.Error(err))
+	i.failedHeights().record(ctx, height, err)
+	return nil
+}
+
+// reprocessRange rewrites every height in [from, to], checkpointing every
+// backfillCheckpointEvery heights. A height that fails is logged and
+// skipped; the run then ends with an error naming how many failed, and
+// its checkpoint is left unfinished.
+func (i *Indexer) reprocessRange(ctx context.Context, opts BackfillOptions, from, to uint64) (int, error) {
+	prepare, commit := i.prepareFull, i.commitMomentum
+	if len(opts.Contracts) > 0 {
+		addrs := make(map[string]bool, len(opts.Contracts))
+		for _, c := range opts.Contracts {
+			addrs[BackfillContracts[c]] = true
+		}
+		prepare = func(m *api.Momentum) (*prefetchedMomentum, []func()) {
+			return i.newContractPrefetch(m, addrs)
+		}
+		commit = i.commitContracts
+	}
+
+	var failed []uint64
+	p := i.backfillPipeline(opts, prepare, func(ctx context.Context, pm *prefetchedMomentum) error {
+		height := pm.momentum.Height
+		if err := commit(ctx, pm); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			i.logger.Error("backfill: failed to reprocess momentum", zap.Uint64("height", height), zap.Error(err))
+			failed = append(failed, height)
+		}
+		if height%backfillCheckpointEvery == 0 && len(failed) == 0 {
+			i.advanceCheckpoint(ctx, opts.Checkpoint, height+1)
+		}
+		return nil
+	})
+	done, err := p.run(ctx, from, to)
+	if err != nil {
+		return done, fmt.Errorf("backfill: %w", err)
+	}
+	if want := int(to - from + 1); done < want {
+		return done, fmt.Errorf("backfill: node returned %d of %d momentums at %d-%d", done, want, from, to)
+	}
+	if len(failed) > 0 {
+		return done, fmt.Errorf("backfill: %d heights failed to reprocess, first %d", len(failed), failed[0])
+	}
+	return done, nil
+}
+
+// newContractPrefetch is newPrefetchedMomentum for a contracts-only
+// reprocess: it fetches just the blocks sent by the contracts in addrs,
+// and no balances.
+func (i *Indexer) newContractPrefetch(m *api.Momentum, addrs map[string]bool) (*prefetchedMomentum, []func()) {
+	pm := &prefetchedMomentum{
+		momentum: m,
+		blocks:   make([]*prefetchedBlock, len(m.Content)),
+	}
+	var tasks []func()
+	for j, header := range m.Content {
+		if !addrs[header.Address.String()] {
+			continue
+		}
+		tasks = append(tasks, func() {
+			pm.blocks[j] = i.fetchAccountBlock(header.Hash, true)
+		})
+	}
+	return pm, tasks
+}
+
+// commitContracts re-runs the embedded-contract handlers for the blocks
+// newContractPrefetch fetched, in one transaction. Domain events are
+// dropped: subscribers already had them when the height was indexed.
+func (i *Indexer) commitContracts(ctx context.Context, pm *prefetchedMomentum) error {
+	if err := i.checkParent(ctx, pm.momentum); err != nil {
+		return err
+	}
+	batch := &pgx.Batch{}
+	for _, pb := range pm.blocks {
+		if pb == nil || pb.pairedTxData == nil {
+			continue
+		}
+		block := pb.block
+		if block.BlockType != utils.BlockTypeContractReceive || block.PairedAccountBlock == nil {
+			continue
+		}
+		i.indexEmbeddedContracts(ctx, batch, block, pb.pairedTxData, pm.momentum)
+	}
+	if batch.Len() == 0 {
+		return nil
+	}
+	if err := i.execBatchTx(ctx, batch); err != nil {
+		return fmt.Errorf("momentum %d: %w", pm.momentum.Height, err)
+	}
+	return nil
+}
+
+// prepareFull fetches everything commitMomentum needs, spreading the
+// reads across the read rotation when there is one.
+func (i *Indexer) prepareFull(m *api.Momentum) (*prefetchedMomentum, []func()) {
+	return i.newPrefetchedMomentum(m, true)
+}
+
+// backfillPipeline builds a catch-up pipeline sized by opts.Workers with
+// the given prepare and commit steps. It reports no metrics: backfill
+// heights sit below the frontier and would drag the committed-height
+// gauge backwards under a running indexer.
+func (i *Indexer) backfillPipeline(opts BackfillOptions,
+	prepare func(*api.Momentum) (*prefetchedMomentum, []func()),
+	commit func(context.Context, *prefetchedMomentum) error) *catchUpPipeline {
+	workers := max(opts.Workers, 1)
+	return &catchUpPipeline{
+		cfg: CatchUpConfig{
+			MomentumWorkers: workers,
+			BlockWorkers:    4 * workers,
+		},
+		logger: i.logger,
+		fetchPage: func(ctx context.Context, start, count uint64) ([]*api.Momentum, error) {
+			return i.fetchMomentumPage(ctx, start, count, true)
+		},
+		prepare: prepare,
+		commit:  commit,
+	}
+}
+
+// advanceCheckpoint records progress; a failure only costs some rework
+// on resume, so it is logged rather than returned.
+func (i *Indexer) advanceCheckpoint(ctx context.Context, name string, next uint64) {
+	if name == "" {
+		return
+	}
+	if err := i.repos.BackfillCheckpoint.Advance(ctx, name, next, time.Now().Unix()); err != nil {
+		i.logger.Warn("backfill: checkpoint update failed",
+			zap.String("checkpoint", name), zap.Uint64("next_height", next), zap.Error(err))
+	}
+}
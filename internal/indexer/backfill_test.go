@@ -0,0 +1,122 @@
+package indexer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+func TestBackfillOptions_Validate(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts BackfillOptions
+		ok   bool
+	}{
+		{"zero value", BackfillOptions{}, true},
+		{"range", BackfillOptions{From: 10, To: 20}, true},
+		{"open-ended", BackfillOptions{From: 10}, true},
+		{"inverted", BackfillOptions{From: 20, To: 10}, false},
+		{"contracts", BackfillOptions{Reprocess: true, Contracts: []string{"pillar", "htlc"}}, true},
+		{"contracts without reprocess", BackfillOptions{Contracts: []string{"pillar"}}, false},
+		{"unknown contract", BackfillOptions{Reprocess: true, Contracts: []string{"pillars"}}, false},
+		{"negative workers", BackfillOptions{Workers: -1}, false},
+	} {
+		if err := tc.opts.Validate(); (err == nil) != tc.ok {
+			t.Errorf("%s: Validate = %v, want ok=%v", tc.name, err, tc.ok)
+		}
+	}
+}
+
+func TestBackfillOptions_CheckpointName(t *testing.T) {
+	for _, tc := range []struct {
+		opts BackfillOptions
+		want string
+	}{
+		{BackfillOptions{}, "gaps:1-head"},
+		{BackfillOptions{From: 5, To: 900}, "gaps:5-900"},
+		{BackfillOptions{Reprocess: true, Contracts: []string{"stake", "pillar", "stake"}}, "reprocess:1-head:pillar,stake"},
+		// Workers and Restart do not change which run it is.
+		{BackfillOptions{Reprocess: true, To: 7, Workers: 8, Restart: true}, "reprocess:1-7"},
+	} {
+		if got := tc.opts.CheckpointName(); got != tc.want {
+			t.Errorf("CheckpointName(%+v) = %q, want %q", tc.opts, got, tc.want)
+		}
+	}
+}
+
+func TestResolveCheckpoint(t *testing.T) {
+	done := int64(50)
+	opts := BackfillOptions{Reprocess: true, Contracts: []string{"stake", "pillar"}, Checkpoint: "cp"}
+	stored := &models.BackfillCheckpoint{
+		Name: "cp", Mode: models.BackfillModeReprocess, Contracts: []string{"pillar", "stake"},
+		FromHeight: 1, ToHeight: 1000, NextHeight: 400, StartedAt: 10, UpdatedAt: 20,
+	}
+
+	// No checkpoint yet: a fresh one over the resolved range.
+	cp, resume, err := resolveCheckpoint(nil, opts, 1, 1000, 99)
+	if err != nil || resume {
+		t.Fatalf("fresh: resume=%v err=%v", resume, err)
+	}
+	want := &models.BackfillCheckpoint{
+		Name: "cp", Mode: models.BackfillModeReprocess, Contracts: []string{"pillar", "stake"},
+		FromHeight: 1, ToHeight: 1000, NextHeight: 1, StartedAt: 99, UpdatedAt: 99,
+	}
+	if !reflect.DeepEqual(cp, want) {
+		t.Errorf("fresh checkpoint = %+v, want %+v", cp, want)
+	}
+
+	// Same run, head has moved: resume with the stored range.
+	cp, resume, err = resolveCheckpoint(stored, opts, 1, 5000, 99)
+	if err != nil || !resume || cp != stored {
+		t.Errorf("resume: cp=%+v resume=%v err=%v", cp, resume, err)
+	}
+
+	// Restart ignores the stored progress.
+	restart := opts
+	restart.Restart = true
+	if cp, resume, _ = resolveCheckpoint(stored, restart, 1, 5000, 99); resume || cp.NextHeight != 1 || cp.ToHeight != 5000 {
+		t.Errorf("restart: cp=%+v resume=%v", cp, resume)
+	}
+
+	// A finished run to head starts over; a finished fixed range stays done.
+	finished := *stored
+	finished.CompletedAt = &done
+	if cp, resume, _ = resolveCheckpoint(&finished, opts, 1, 5000, 99); resume || cp.ToHeight != 5000 {
+		t.Errorf("finished to head: cp=%+v resume=%v", cp, resume)
+	}
+	fixed := opts
+	fixed.To = 1000
+	if cp, resume, _ = resolveCheckpoint(&finished, fixed, 1, 1000, 99); !resume || cp.CompletedAt == nil {
+		t.Errorf("finished fixed range: cp=%+v resume=%v", cp, resume)
+	}
+
+	// A different run under the same name is refused.
+	for name, o := range map[string]BackfillOptions{
+		"mode":      {Checkpoint: "cp"},
+		"contracts": {Reprocess: true, Contracts: []string{"pillar"}, Checkpoint: "cp"},
+		"to":        {Reprocess: true, Contracts: []string{"pillar", "stake"}, To: 2000, Checkpoint: "cp"},
+	} {
+		if _, _, err := resolveCheckpoint(stored, o, 1, 2000, 99); err == nil {
+			t.Errorf("%s mismatch accepted", name)
+		}
+	}
+	if _, _, err := resolveCheckpoint(stored, opts, 2, 1000, 99); err == nil {
+		t.Error("from mismatch accepted")
+	}
+}
+
+func TestHeightRuns(t *testing.T) {
+	for _, tc := range []struct {
+		in   []uint64
+		want [][2]uint64
+	}{
+		{nil, nil},
+		{[]uint64{7}, [][2]uint64{{7, 7}}},
+		{[]uint64{1, 2, 3, 5, 8, 9}, [][2]uint64{{1, 3}, {5, 5}, {8, 9}}},
+	} {
+		if got := heightRuns(tc.in); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("heightRuns(%v) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/0x3639/znn-sdk-go/rpc_client"
+	"github.com/zenon-network/go-zenon/rpc/api"
+	"go.uber.org/zap"
+
+	"github.com/0x3639/nom-indexer-go/internal/indexer/metrics"
+)
+
+// catchUpPageSize is the number of momentums requested per
+// GetMomentumsByHeight call, in both the serial and pipelined sync.
+const catchUpPageSize = uint64(100)
+
+// catchUpProgressEvery is how many committed momentums pass between
+// throughput log lines in the pipelined catch-up.
+const catchUpProgressEvery = 1000
+
+// CatchUpConfig controls the pipelined catch-up sync. When Enabled is
+// false (the zero value, used by NewIndexer and cmd/backfill) sync()
+// fetches and commits one momentum at a time.
+//
+// The pipeline never writes out of order: workers only talk to the node,
+// and a single committer applies momentums in height order with the same
+// per-momentum transaction as the live path.
+type CatchUpConfig struct {
+	Enabled bool
+	// MomentumWorkers is the number of concurrent GetMomentumsByHeight
+	// page fetches. Each page holds catchUpPageSize momentums.
+	MomentumWorkers int
+	// BlockWorkers is the number of concurrent account-block and
+	// account-info fetches shared across all prefetched momentums.
+	BlockWorkers int
+	// PrefetchDepth caps how many momentums may be fetched ahead of the
+	// committer. Bounds memory: genesis alone carries tens of thousands
+	// of account blocks.
+	PrefetchDepth int
+	// FanOut spreads momentum-page and account-block fetches across every
+	// node the watchdog reports synced on the same chain, instead of only
+	// the active node. See readRotation.
+	FanOut bool
+}
+
+// withDefaults fills non-positive fields with the documented defaults.
+func (c CatchUpConfig) withDefaults() CatchUpConfig {
+	if c.MomentumWorkers <= 0 {
+		c.MomentumWorkers = 2
+	}
+	if c.BlockWorkers <= 0 {
+		c.BlockWorkers = 8
+	}
+	if c.PrefetchDepth <= 0 {
+		c.PrefetchDepth = 256
+	}
+	return c
+}
+
+// catchUpPipeline runs one pipelined catch-up pass over [from, to]. Its
+// node and database access is injected so the ordering logic can be
+// tested without either.
+type catchUpPipeline struct {
+	cfg     CatchUpConfig
+	logger  *zap.Logger
+	metrics *metrics.Metrics
+
+	// fetchPage returns up to count momentums starting at height start.
+	fetchPage func(ctx context.Context, start, count uint64) ([]*api.Momentum, error)
+	// prepare allocates a prefetchedMomentum and the tasks that fill it.
+	prepare func(m *api.Momentum) (*prefetchedMomentum, []func())
+	// commit writes one fully prefetched momentum. Called in height
+	// order from a single goroutine.
+	commit func(ctx context.Context, pm *prefetchedMomentum) error
+}
+
+// pageFuture is one in-flight GetMomentumsByHeight page.
+type pageFuture struct {
+	start     uint64
+	done      chan struct{}
+	momentums []*api.Momentum
+	err       error
+}
+
+// momentumFuture is one momentum whose prefetch tasks may still be
+// running. done is closed when every task has returned.
+type momentumFuture struct {
+	pm      *prefetchedMomentum
+	pending sync.WaitGroup
+	done    chan struct{}
+	err     error
+}
+
+// run fetches pages and account blocks ahead of the committer and
+// commits every momentum in [from, to] in order, returning how many it
+// committed. An empty page ends the pass early: the node has nothing past
+// that height yet. A short page is only accepted from the active node
+// (see fetchMomentumPage); its momentums are committed and the pass ends
+// at the empty page after it. The first commit or fetch error is returned unchanged, so a *reorgError
+// still reaches the caller's errors.As check. All worker goroutines have
+// exited by the time run returns.
+func (p *catchUpPipeline) run(ctx context.Context, from, to uint64) (int, error) {
+	if from > to {
+		return 0, nil
+	}
+	cfg := p.cfg.withDefaults()
+
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+
+	pageJobs := make(chan *pageFuture)
+	// pages preserves page order for the sequencer; its capacity is what
+	// lets MomentumWorkers pages be in flight at once.
+	pages := make(chan *pageFuture, cfg.MomentumWorkers)
+	taskJobs := make(chan func())
+	// ordered preserves momentum order for the committer; its capacity is
+	// the prefetch depth.
+	ordered := make(chan *momentumFuture, cfg.PrefetchDepth)
+
+	// Page producer: walks [from, to] in catchUpPageSize steps.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(pages)
+		defer close(pageJobs)
+		for start := from; start <= to; start += catchUpPageSize {
+			pf := &pageFuture{start: start, done: make(chan struct{})}
+			select {
+			case pages <- pf:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case pageJobs <- pf:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for w := 0; w < cfg.MomentumWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for pf := range pageJobs {
+				count := catchUpPageSize
+				if remaining := to - pf.start + 1; remaining < count {
+					count = remaining
+				}
+				start := time.Now()
+				pf.momentums, pf.err = p.fetchPage(ctx, pf.start, count)
+				p.metrics.ObserveFetch(metrics.StageMomentums, time.Since(start))
+				close(pf.done)
+			}
+		}()
+	}
+
+	for w := 0; w < cfg.BlockWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for task := range taskJobs {
+				task()
+			}
+		}()
+	}
+
+	// Sequencer: turns pages into per-momentum futures in height order
+	// and hands their fetch tasks to the block workers.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(ordered)
+		defer close(taskJobs)
+		for pf := range pages {
+			select {
+			case <-pf.done:
+			case <-ctx.Done():
+				return
+			}
+			if pf.err != nil {
+				f := &momentumFuture{done: make(chan struct{}), err: pf.err}
+				close(f.done)
+				select {
+				case ordered <- f:
+				case <-ctx.Done():
+				}
+				return
+			}
+			if len(pf.momentums) == 0 {
+				// The node has nothing past this height yet; the outer
+				// sync loop re-reads the frontier and tries again.
+				return
+			}
+			for _, m := range pf.momentums {
+				if m.Height > to {
+					return
+				}
+				pm, tasks := p.prepare(m)
+				f := &momentumFuture{pm: pm, done: make(chan struct{})}
+				f.pending.Add(len(tasks))
+				select {
+				case ordered <- f:
+				case <-ctx.Done():
+					return
+				}
+				p.metrics.SetPrefetched(len(ordered))
+				for _, task := range tasks {
+					job := func() {
+						defer f.pending.Done()
+						task()
+					}
+					select {
+					case taskJobs <- job:
+					case <-ctx.Done():
+						// Unblock this future's waiter; the committer
+						// is returning on ctx anyway.
+						f.pending.Done()
+					}
+				}
+				go func() {
+					f.pending.Wait()
+					close(f.done)
+				}()
+			}
+		}
+	}()
+
+	// Committer: the calling goroutine, strictly in height order.
+	committed := 0
+	passStart := time.Now()
+	for f := range ordered {
+		select {
+		case <-f.done:
+		case <-ctx.Done():
+			return committed, ctx.Err()
+		}
+		p.metrics.SetPrefetched(len(ordered))
+		if f.err != nil {
+			return committed, f.err
+		}
+		m := f.pm.momentum
+
+		p.logger.Info("processing momentum",
+			zap.Uint64("height", m.Height),
+			zap.Int("txCount", len(m.Content)))
+
+		start := time.Now()
+		if err := p.commit(ctx, f.pm); err != nil {
+			return committed, err
+		}
+		p.metrics.ObserveCommit(m.Height, len(m.Content), time.Since(start))
+
+		committed++
+		if committed%catchUpProgressEvery == 0 {
+			elapsed := time.Since(passStart)
+			p.logger.Info("catch-up progress",
+				zap.Uint64("height", m.Height),
+				zap.Uint64("target", to),
+				zap.Int("committed", committed),
+				zap.Float64("momentums_per_sec", float64(committed)/elapsed.Seconds()))
+		}
+	}
+	return committed, ctx.Err()
+}
+
+// catchUp runs one pipelined pass from startHeight to frontierHeight
+// using the indexer's node client and database. Returns the number of
+// momentums committed.
+func (i *Indexer) catchUp(ctx context.Context, startHeight, frontierHeight uint64) (int, error) {
+	cfg := i.catchUpCfg.withDefaults()
+	i.metrics.SetTargetHeight(frontierHeight)
+	i.logger.Info("starting pipelined catch-up",
+		zap.Uint64("from", startHeight),
+		zap.Uint64("to", frontierHeight),
+		zap.Int("momentum_workers", cfg.MomentumWorkers),
+		zap.Int("block_workers", cfg.BlockWorkers),
+		zap.Int("prefetch_depth", cfg.PrefetchDepth))
+
+	p := &catchUpPipeline{
+		cfg:     cfg,
+		logger:  i.logger,
+		metrics: i.metrics,
+		fetchPage: func(ctx context.Context, start, count uint64) ([]*api.Momentum, error) {
+			return i.fetchMomentumPage(ctx, start, count, true)
+		},
+		prepare: func(m *api.Momentum) (*prefetchedMomentum, []func()) {
+			return i.newPrefetchedMomentum(m, true)
+		},
+		commit: func(ctx context.Context, pm *prefetchedMomentum) error {
+			if err := i.commitMomentum(ctx, pm); err != nil {
+				return err
+			}
+			i.lastProgressAt.Store(time.Now().Unix())
+			return nil
+		},
+	}
+	return p.run(ctx, startHeight, frontierHeight)
+}
+
+// fetchMomentumPage fetches the count momentums starting at start, with
+// retries. fanOut spreads the request across the read rotation; only
+// the active node's short page is returned, so the caller can stop at
+// the node's frontier.
+func (i *Indexer) fetchMomentumPage(ctx context.Context, start, count uint64, fanOut bool) ([]*api.Momentum, error) {
+	var list []*api.Momentum
+	if err := withRetry(ctx, i.logger, "GetMomentumsByHeight", func() error {
+		return i.readLedger(fanOut, func(c *rpc_client.RpcClient) error {
+			m, err := c.LedgerApi.GetMomentumsByHeight(start, count)
+			if err != nil {
+				return err
+			}
+			list = nil
+			if m != nil {
+				list = m.List
+			}
+			// A short or misaligned page from a lagging rotation
+			// node would leave a gap; only the active node's page
+			// may end a pass early.
+			if uint64(len(list)) < count || list[0].Height != start {
+				return errReadMiss
+			}
+			return nil
+		})
+	}); err != nil {
+		return nil, fmt.Errorf("failed to get momentums at height %d: %w", start, err)
+	}
+	return list, nil
+}
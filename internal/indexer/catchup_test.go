@@ -0,0 +1,173 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/rpc/api"
+	"go.uber.org/zap"
+)
+
+// fakeChain serves momentums 1..frontier, each with txCount prefetch
+// tasks that sleep a random few microseconds so workers finish out of
+// order.
+type fakeChain struct {
+	frontier uint64
+	txCount  int
+}
+
+func (c *fakeChain) fetchPage(_ context.Context, start, count uint64) ([]*api.Momentum, error) {
+	var list []*api.Momentum
+	for h := start; h < start+count && h <= c.frontier; h++ {
+		list = append(list, &api.Momentum{Momentum: &nom.Momentum{Height: h}})
+	}
+	return list, nil
+}
+
+func (c *fakeChain) prepare(m *api.Momentum) (*prefetchedMomentum, []func()) {
+	pm := &prefetchedMomentum{
+		momentum: m,
+		blocks:   make([]*prefetchedBlock, c.txCount),
+	}
+	tasks := make([]func(), c.txCount)
+	for j := range tasks {
+		tasks[j] = func() {
+			time.Sleep(time.Duration(rand.Intn(50)) * time.Microsecond)
+			pm.blocks[j] = &prefetchedBlock{}
+		}
+	}
+	return pm, tasks
+}
+
+func newTestPipeline(c *fakeChain, commit func(context.Context, *prefetchedMomentum) error) *catchUpPipeline {
+	return &catchUpPipeline{
+		cfg:       CatchUpConfig{Enabled: true, MomentumWorkers: 3, BlockWorkers: 4, PrefetchDepth: 16},
+		logger:    zap.NewNop(),
+		fetchPage: c.fetchPage,
+		prepare:   c.prepare,
+		commit:    commit,
+	}
+}
+
+func TestCatchUpPipeline_CommitsInOrderAfterPrefetch(t *testing.T) {
+	c := &fakeChain{frontier: 450, txCount: 3}
+	var next uint64 = 5
+	p := newTestPipeline(c, func(_ context.Context, pm *prefetchedMomentum) error {
+		if pm.momentum.Height != next {
+			t.Fatalf("committed height %d, want %d", pm.momentum.Height, next)
+		}
+		for j, b := range pm.blocks {
+			if b == nil {
+				t.Fatalf("height %d block %d committed before its prefetch finished", next, j)
+			}
+		}
+		next++
+		return nil
+	})
+
+	n, err := p.run(context.Background(), 5, 430)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if n != 426 || next != 431 {
+		t.Fatalf("committed %d (next %d), want 426 (next 431)", n, next)
+	}
+}
+
+func TestCatchUpPipeline_ShortChainEndsPass(t *testing.T) {
+	// The node serves fewer momentums than the requested range; the
+	// pass ends cleanly at the node's tip.
+	c := &fakeChain{frontier: 120, txCount: 1}
+	p := newTestPipeline(c, func(context.Context, *prefetchedMomentum) error { return nil })
+
+	n, err := p.run(context.Background(), 1, 500)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if n != 120 {
+		t.Fatalf("committed %d, want 120", n)
+	}
+}
+
+func TestCatchUpPipeline_CommitErrorPassesThrough(t *testing.T) {
+	c := &fakeChain{frontier: 1000, txCount: 2}
+	rerr := &reorgError{height: 77}
+	p := newTestPipeline(c, func(_ context.Context, pm *prefetchedMomentum) error {
+		if pm.momentum.Height == 77 {
+			return rerr
+		}
+		return nil
+	})
+
+	n, err := p.run(context.Background(), 1, 1000)
+	var got *reorgError
+	if !errors.As(err, &got) || got != rerr {
+		t.Fatalf("run error = %v, want the commit's *reorgError", err)
+	}
+	if n != 76 {
+		t.Fatalf("committed %d, want 76", n)
+	}
+}
+
+func TestCatchUpPipeline_FetchErrorStopsAfterEarlierPages(t *testing.T) {
+	c := &fakeChain{frontier: 1000, txCount: 1}
+	fetchErr := errors.New("node down")
+	var commits atomic.Int64
+	p := newTestPipeline(c, func(context.Context, *prefetchedMomentum) error {
+		commits.Add(1)
+		return nil
+	})
+	p.fetchPage = func(ctx context.Context, start, count uint64) ([]*api.Momentum, error) {
+		if start == 201 {
+			return nil, fetchErr
+		}
+		return c.fetchPage(ctx, start, count)
+	}
+
+	n, err := p.run(context.Background(), 1, 1000)
+	if !errors.Is(err, fetchErr) {
+		t.Fatalf("run error = %v, want %v", err, fetchErr)
+	}
+	// Pages before the failing one are still committed, in order.
+	if n != 200 || commits.Load() != 200 {
+		t.Fatalf("committed %d, want 200", n)
+	}
+}
+
+func TestCatchUpPipeline_CancelReturnsPromptly(t *testing.T) {
+	c := &fakeChain{frontier: 100000, txCount: 1}
+	ctx, cancel := context.WithCancel(context.Background())
+	p := newTestPipeline(c, func(_ context.Context, pm *prefetchedMomentum) error {
+		if pm.momentum.Height == 50 {
+			cancel()
+		}
+		return nil
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := p.run(ctx, 1, 100000)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("run error = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("run did not return after cancel")
+	}
+}
+
+func TestCatchUpConfigDefaults(t *testing.T) {
+	got := CatchUpConfig{Enabled: true, BlockWorkers: 16}.withDefaults()
+	want := CatchUpConfig{Enabled: true, MomentumWorkers: 2, BlockWorkers: 16, PrefetchDepth: 256}
+	if got != want {
+		t.Fatalf("withDefaults = %+v, want %+v", got, want)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/0x3639/nom-indexer-go/internal/models"
+	"github.com/0x3639/nom-indexer-go/internal/statbackfill"
 )
 
 // runCronLoop schedules updatePillarVotingActivity and updateTokenHolderCounts
@@ -51,7 +52,8 @@ func (i *Indexer) runCronLoop(ctx context.Context, votingActivityInterval, token
 }
 
 // runStatSnapshots refreshes the current day's row in each *_stat_histories
-// table. Each snapshot job is independent; one failure does not block the
+// table, after rebuilding any past day not yet final (see fillStatDays).
+// Each snapshot job is independent; one failure does not block the
 // others.
 func (i *Indexer) runStatSnapshots(ctx context.Context) {
 	start := time.Now()
@@ -65,6 +67,8 @@ func (i *Indexer) runStatSnapshots(ctx context.Context) {
 	startTs := todayStart.Unix()
 	endTs := startTs + 86400
 
+	i.fillStatDays(ctx, today)
+
 	if err := i.snapshotNetworkStats(ctx, today, startTs, endTs); err != nil {
 		i.logger.Warn("stat snapshots: network failed", zap.Error(err))
 	}
@@ -177,82 +181,48 @@ func (i *Indexer) snapshotPillarStats(ctx context.Context, date string) error {
 }
 
 func (i *Indexer) snapshotBridgeStats(ctx context.Context, date string, startTs, endTs int64) error {
-	// Aggregate wrap requests by (network_class, chain_id, token_standard) for today.
-	rows, err := i.pool.Query(ctx, `
-		SELECT network_class, chain_id, token_standard,
-			COUNT(*)::bigint AS wrap_tx,
-			COALESCE(SUM(amount), 0)::bigint AS wrapped_amount
-		FROM wrap_token_requests
-		WHERE creation_momentum_height IN (
-			SELECT height FROM momentums WHERE timestamp >= $1 AND timestamp < $2
-		)
-		GROUP BY network_class, chain_id, token_standard`,
-		startTs, endTs)
+	stats, err := i.repos.StatHistory.BridgeStatsForDay(ctx, date, startTs, endTs)
 	if err != nil {
-		return fmt.Errorf("query wrap aggregates: %w", err)
+		return err
 	}
-	stats := map[string]*models.BridgeStatHistory{}
-	for rows.Next() {
-		var s models.BridgeStatHistory
-		if scanErr := rows.Scan(&s.NetworkClass, &s.ChainID, &s.TokenStandard,
-			&s.WrapTxCount, &s.WrappedAmount); scanErr != nil {
-			rows.Close()
-			return scanErr
+	for _, s := range stats {
+		if err := i.repos.StatHistory.UpsertBridgeStat(ctx, s); err != nil {
+			i.logger.Warn("bridge stat upsert failed", zap.Error(err))
 		}
-		s.Date = date
-		s.TotalVolume = s.WrappedAmount
-		key := fmt.Sprintf("%d:%d:%s", s.NetworkClass, s.ChainID, s.TokenStandard)
-		stats[key] = &s
-	}
-	rows.Close()
-	if iterErr := rows.Err(); iterErr != nil {
-		return iterErr
 	}
+	return nil
+}
 
-	// Unwrap side.
-	rows, err = i.pool.Query(ctx, `
-		SELECT network_class, chain_id, token_standard,
-			COUNT(*)::bigint AS unwrap_tx,
-			COALESCE(SUM(amount), 0)::bigint AS unwrapped_amount
-		FROM unwrap_token_requests
-		WHERE registration_momentum_height IN (
-			SELECT height FROM momentums WHERE timestamp >= $1 AND timestamp < $2
-		)
-		GROUP BY network_class, chain_id, token_standard`,
-		startTs, endTs)
+// fillStatDays rebuilds, as each stood at its end, the days from the
+// latest snapshot through yesterday: the latest one because the hourly
+// snapshot last saw it before midnight, and any after it the indexer
+// was down for. Without a snapshot yet there is nothing to continue
+// from; history back to genesis is cmd/backfill-stats' job.
+func (i *Indexer) fillStatDays(ctx context.Context, today string) {
+	latest, err := i.repos.StatHistory.LatestDate(ctx)
 	if err != nil {
-		return fmt.Errorf("query unwrap aggregates: %w", err)
+		i.logger.Warn("stat snapshots: read latest date failed", zap.Error(err))
+		return
 	}
-	defer rows.Close()
-	for rows.Next() {
-		var nc, ci int
-		var ts string
-		var tx, amount int64
-		if err := rows.Scan(&nc, &ci, &ts, &tx, &amount); err != nil {
-			return err
-		}
-		key := fmt.Sprintf("%d:%d:%s", nc, ci, ts)
-		s := stats[key]
-		if s == nil {
-			s = &models.BridgeStatHistory{
-				Date: date, NetworkClass: nc, ChainID: ci, TokenStandard: ts,
-			}
-			stats[key] = s
-		}
-		s.UnwrapTxCount = tx
-		s.UnwrappedAmount = amount
-		s.TotalVolume += amount
+	if latest == "" || latest >= today {
+		return
 	}
-	if err := rows.Err(); err != nil {
-		return err
+	from, err := time.Parse(statbackfill.DateLayout, latest)
+	if err != nil {
+		i.logger.Warn("stat snapshots: parse latest date failed", zap.Error(err))
+		return
 	}
-
-	for _, s := range stats {
-		if err := i.repos.StatHistory.UpsertBridgeStat(ctx, s); err != nil {
-			i.logger.Warn("bridge stat upsert failed", zap.Error(err))
-		}
+	report, err := statbackfill.NewRunner(i.pool, i.logger, statbackfill.Options{From: from}).Run(ctx)
+	if err != nil {
+		i.logger.Warn("stat snapshots: rebuilding past days failed", zap.Error(err))
+		return
+	}
+	if report.Days > 0 {
+		i.logger.Info("stat snapshots: past days rebuilt",
+			zap.String("from", report.From),
+			zap.String("to", report.To),
+			zap.Int("days", report.Days))
 	}
-	return nil
 }
 
 // runVotingActivity recomputes voting_activity for every pillar. The score is
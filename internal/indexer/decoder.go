@@ -14,11 +14,31 @@ import (
 
 // tryDecodeTxData attempts to decode transaction data from an account block
 func (i *Indexer) tryDecodeTxData(block *rpcapi.AccountBlock) *models.TxData {
-	if len(block.Data) == 0 {
+	txData := DecodeTxData(i.logger, block.ToAddress.String(), block.Data)
+	if txData == nil {
+		if len(block.Data) > 0 && models.IsEmbeddedContract(block.ToAddress.String()) {
+			i.logger.Debug("unable to decode transaction data",
+				zap.String("hash", block.Hash.String()),
+				zap.String("toAddress", block.ToAddress.String()))
+		}
 		return nil
 	}
 
-	toAddress := block.ToAddress.String()
+	i.logger.Debug("decoded transaction",
+		zap.String("method", txData.Method),
+		zap.String("hash", block.Hash.String()))
+
+	return txData
+}
+
+// DecodeTxData decodes the call data of a block sent to toAddress. It
+// returns nil unless toAddress is an embedded contract and data matches a
+// method of the common or the contract's own ABI. The rederive tool uses
+// it to re-decode stored account_blocks.data exactly as the indexer does.
+func DecodeTxData(logger *zap.Logger, toAddress string, data []byte) *models.TxData {
+	if len(data) == 0 {
+		return nil
+	}
 
 	// Only decode for embedded contracts
 	if !models.IsEmbeddedContract(toAddress) {
@@ -26,7 +46,7 @@ func (i *Indexer) tryDecodeTxData(block *rpcapi.AccountBlock) *models.TxData {
 	}
 
 	// Try common definitions first
-	txData := i.tryDecodeFromAbi(block.Data, embedded.Common)
+	txData := DecodeFromAbi(logger, data, embedded.Common)
 	if txData != nil && txData.Method != "" {
 		return txData
 	}
@@ -54,6 +74,8 @@ func (i *Indexer) tryDecodeTxData(block *rpcapi.AccountBlock) *models.TxData {
 		contractAbi = embedded.Bridge
 	case models.HtlcAddress:
 		contractAbi = embedded.Htlc
+	case models.SporkAddress:
+		contractAbi = embedded.Spork
 	default:
 		return nil
 	}
@@ -62,23 +84,21 @@ func (i *Indexer) tryDecodeTxData(block *rpcapi.AccountBlock) *models.TxData {
 		return nil
 	}
 
-	txData = i.tryDecodeFromAbi(block.Data, contractAbi)
+	txData = DecodeFromAbi(logger, data, contractAbi)
 	if txData == nil || txData.Method == "" {
-		i.logger.Debug("unable to decode transaction data",
-			zap.String("hash", block.Hash.String()),
-			zap.String("toAddress", toAddress))
 		return nil
 	}
-
-	i.logger.Debug("decoded transaction",
-		zap.String("method", txData.Method),
-		zap.String("hash", block.Hash.String()))
-
 	return txData
 }
 
 // tryDecodeFromAbi tries to decode data using the SDK's ABI
 func (i *Indexer) tryDecodeFromAbi(data []byte, contractAbi *abi.Abi) *models.TxData {
+	return DecodeFromAbi(i.logger, data, contractAbi)
+}
+
+// DecodeFromAbi decodes data as a call to a method of contractAbi, or
+// returns nil when no method's signature matches.
+func DecodeFromAbi(logger *zap.Logger, data []byte, contractAbi *abi.Abi) *models.TxData {
 	if contractAbi == nil || len(data) < 4 {
 		return nil
 	}
@@ -107,7 +127,7 @@ func (i *Indexer) tryDecodeFromAbi(data []byte, contractAbi *abi.Abi) *models.Tx
 				// Use the Abi's DecodeFunction which handles decoding
 				args, err := contractAbi.DecodeFunction(data)
 				if err != nil {
-					i.logger.Debug("failed to decode inputs",
+					logger.Debug("failed to decode inputs",
 						zap.String("method", entry.Name),
 						zap.Error(err))
 					return txData
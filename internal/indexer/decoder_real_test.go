@@ -6,6 +6,8 @@ import (
 	"github.com/0x3639/znn-sdk-go/embedded"
 	"github.com/zenon-network/go-zenon/common/types"
 	"go.uber.org/zap"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
 )
 
 // TestTryDecodeFromAbi_Delegate exercises tryDecodeFromAbi end-to-end by
@@ -59,6 +61,29 @@ func TestTryDecodeFromAbi_VoteByName(t *testing.T) {
 	}
 }
 
+// TestDecodeTxData_Spork confirms calls to the Spork contract decode with
+// their inputs, the id as the hex of the created spork's hash.
+func TestDecodeTxData_Spork(t *testing.T) {
+	create, err := embedded.Spork.EncodeFunction("CreateSpork", []interface{}{"htlc-spork", "Enables HTLCs"})
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	got := DecodeTxData(zap.NewNop(), models.SporkAddress, create)
+	if got == nil || got.Method != "CreateSpork" || got.Inputs["name"] != "htlc-spork" || got.Inputs["description"] != "Enables HTLCs" {
+		t.Errorf("CreateSpork decoded as %+v", got)
+	}
+
+	id := "0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20"
+	activate, err := embedded.Spork.EncodeFunction("ActivateSpork", []interface{}{types.HexToHashPanic(id)})
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	got = DecodeTxData(zap.NewNop(), models.SporkAddress, activate)
+	if got == nil || got.Method != "ActivateSpork" || got.Inputs["id"] != id {
+		t.Errorf("ActivateSpork decoded as %+v", got)
+	}
+}
+
 func TestTryDecodeFromAbi_NilAbi(t *testing.T) {
 	i := &Indexer{logger: zap.NewNop()}
 	if got := i.tryDecodeFromAbi([]byte{1, 2, 3, 4, 5}, nil); got != nil {
@@ -12,7 +12,10 @@
 //
 // Per-momentum processing is transactional: every write for a single
 // momentum lands in one pgx.Batch wrapped in a transaction. A failure
-// rolls back and the sync loop retries the height.
+// rolls back and the sync loop retries the height. Catch-up may fetch
+// ahead with worker pools (catchup.go), spread across every healthy
+// node (readpool.go), but commits stay one momentum at a time, in
+// height order.
 //
 // See docs/architecture/overview.md for the system picture and
 // docs/architecture/data-flow.md for the per-momentum trace.
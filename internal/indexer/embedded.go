@@ -12,49 +12,94 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/0x3639/nom-indexer-go/internal/models"
+	"github.com/0x3639/nom-indexer-go/internal/webhooks"
 )
 
-// indexEmbeddedContracts handles indexing for embedded contract interactions
-func (i *Indexer) indexEmbeddedContracts(ctx context.Context, batch *pgx.Batch, block *api.AccountBlock, txData *models.TxData, m *api.Momentum) {
+// indexEmbeddedContracts handles indexing for embedded contract interactions.
+// It returns the webhook domain events the call produced, or nil when
+// webhooks are disabled.
+func (i *Indexer) indexEmbeddedContracts(ctx context.Context, batch *pgx.Batch, block *api.AccountBlock, txData *models.TxData, m *api.Momentum) []webhooks.Event {
 	if txData == nil {
-		return
+		return nil
 	}
 
 	address := block.Address.String()
 
 	switch address {
 	case models.PillarAddress:
-		i.indexPillarContract(ctx, batch, block, txData, m)
+		return i.indexPillarContract(ctx, batch, block, txData, m)
 	case models.StakeAddress:
-		i.indexStakeContract(ctx, batch, block, txData, m)
+		return i.indexStakeContract(ctx, batch, block, txData, m)
 	case models.SentinelAddress:
 		i.indexSentinelContract(ctx, batch, block, txData, m)
 	case models.PlasmaAddress:
-		i.indexPlasmaContract(ctx, batch, block, txData, m)
+		return i.indexPlasmaContract(ctx, batch, block, txData, m)
 	case models.AcceleratorAddress:
-		i.indexAcceleratorContract(ctx, batch, block, txData, m)
+		return i.indexAcceleratorContract(ctx, batch, block, txData, m)
 	case models.TokenAddress:
-		i.indexTokenContract(ctx, batch, block, txData, m)
+		return i.indexTokenContract(ctx, batch, block, txData, m)
 	case models.HtlcAddress:
-		i.indexHtlcContract(ctx, batch, block, txData, m)
+		return i.indexHtlcContract(ctx, batch, block, txData, m)
 	case models.SwapAddress:
 		i.indexSwapContract(ctx, batch, block, txData, m)
+	case models.SporkAddress:
+		i.indexSporkContract(ctx, batch, block, txData, m)
+	case models.LiquidityAddress:
+		i.indexLiquidityContract(ctx, batch, block, txData, m)
+	case models.BridgeAddress:
+		i.indexBridgeContract(batch, block, txData, m)
+	}
+	return nil
+}
+
+// domainEvent wraps payload as a one-event slice for the embedded-contract
+// handlers to return, or nil when webhooks are disabled.
+func (i *Indexer) domainEvent(eventType string, payload any) []webhooks.Event {
+	if i.webhooks == nil {
+		return nil
+	}
+	return []webhooks.Event{{Type: eventType, Payload: payload}}
+}
+
+// eventSource locates a domain event at the contract receive block and
+// its momentum, and names the contract method that produced it.
+func eventSource(block *api.AccountBlock, txData *models.TxData, m *api.Momentum) webhooks.Source {
+	return webhooks.Source{
+		Method:            txData.Method,
+		AccountBlockHash:  block.Hash.String(),
+		MomentumHeight:    m.Height,
+		MomentumTimestamp: int64(m.TimestampUnix),
+	}
+}
+
+// amountString renders a token amount for a webhook payload or a
+// verify comparison; nil is "0".
+func amountString(v *big.Int) string {
+	if v == nil {
+		return "0"
 	}
+	return v.String()
 }
 
 // indexPillarContract handles pillar contract events
-func (i *Indexer) indexPillarContract(ctx context.Context, batch *pgx.Batch, block *api.AccountBlock, txData *models.TxData, m *api.Momentum) {
+func (i *Indexer) indexPillarContract(ctx context.Context, batch *pgx.Batch, block *api.AccountBlock, txData *models.TxData, m *api.Momentum) []webhooks.Event {
 	method := txData.Method
 
 	switch method {
 	case "Register", "RegisterLegacy":
-		// Record pillar update
+		// Record pillar update. The contract refunds a call it rejects (a
+		// taken name or producer address, a short deposit); only an
+		// accepted one burns the slot cost, as a descendant block to the
+		// token contract.
 		name := txData.Inputs["name"]
 		producerAddress := txData.Inputs["producerAddress"]
 		rewardAddress := txData.Inputs["rewardAddress"]
-		if name != "" && block.PairedAccountBlock != nil {
+		if name != "" && block.PairedAccountBlock != nil && len(block.DescendantBlocks) > 0 &&
+			block.DescendantBlocks[0].ToAddress.String() == models.TokenAddress {
 			ownerAddress := block.PairedAccountBlock.Address.String()
 			update := &models.PillarUpdate{
+				AccountBlockHash:  block.Hash.String(),
+				Method:            method,
 				OwnerAddress:      ownerAddress,
 				ProducerAddress:   producerAddress,
 				WithdrawAddress:   rewardAddress,
@@ -64,34 +109,39 @@ func (i *Indexer) indexPillarContract(ctx context.Context, batch *pgx.Batch, blo
 				MomentumHash:      m.Hash.String(),
 			}
 			i.repos.PillarUpdate.InsertBatch(batch, update)
+			i.pillarNames.Registered(name, ownerAddress, m.Height)
 
-			// Check descendant blocks for Burn transaction to get slot cost
+			// The burn's amount is the slot cost.
 			// Note: DescendantBlocks are nom.AccountBlock type with limited fields
-			// The descendant's ToAddress and Amount are accessible for determining QSR burn
-			if len(block.DescendantBlocks) > 0 {
-				descendant := block.DescendantBlocks[0]
-				if descendant.ToAddress.String() == models.TokenAddress {
-					// The descendant is a Burn transaction to the token contract
-					slotCostQsr := safeBigIntToInt64(descendant.Amount, i.logger,
-						"pillar slot cost overflow",
-						zap.String("name", name),
-						zap.String("owner", ownerAddress))
-					i.repos.Pillar.UpdateSpawnInfoBatch(batch, ownerAddress, int64(m.TimestampUnix), slotCostQsr)
-					i.logger.Debug("pillar registered with spawn info",
-						zap.String("name", name),
-						zap.String("owner", ownerAddress),
-						zap.Int64("slotCost", slotCostQsr))
-				}
-			}
+			slotCostQsr := safeBigIntToInt64(block.DescendantBlocks[0].Amount, i.logger,
+				"pillar slot cost overflow",
+				zap.String("name", name),
+				zap.String("owner", ownerAddress))
+			i.repos.Pillar.UpdateSpawnInfoBatch(batch, ownerAddress, int64(m.TimestampUnix), slotCostQsr)
+			i.logger.Debug("pillar registered with spawn info",
+				zap.String("name", name),
+				zap.String("owner", ownerAddress),
+				zap.Int64("slotCost", slotCostQsr))
+			return i.domainEvent(webhooks.EventPillarRegistered, webhooks.PillarRegistered{
+				Source:          eventSource(block, txData, m),
+				Name:            name,
+				Owner:           ownerAddress,
+				ProducerAddress: producerAddress,
+				RewardAddress:   rewardAddress,
+			})
 		}
 	case "UpdatePillar":
-		// Record pillar update
+		// Record pillar update. The contract only accepts it from the
+		// owner of an active pillar.
 		name := txData.Inputs["name"]
 		producerAddress := txData.Inputs["producerAddress"]
 		rewardAddress := txData.Inputs["rewardAddress"]
-		pillarOwner := txData.Inputs["pillarOwner"]
-		if name != "" && pillarOwner != "" {
+		pillarOwner := i.getPillarOwnerAddress(name, m.Height)
+		if name != "" && pillarOwner != "" && block.PairedAccountBlock != nil &&
+			block.PairedAccountBlock.Address.String() == pillarOwner {
 			update := &models.PillarUpdate{
+				AccountBlockHash:  block.Hash.String(),
+				Method:            method,
 				OwnerAddress:      pillarOwner,
 				ProducerAddress:   producerAddress,
 				WithdrawAddress:   rewardAddress,
@@ -104,44 +154,72 @@ func (i *Indexer) indexPillarContract(ctx context.Context, batch *pgx.Batch, blo
 		}
 	case "Delegate":
 		// Update account delegation + append to delegation history (close
-		// the previous open interval if any, then open a new one).
+		// the previous open interval if any, then open a new one). A
+		// reprocessed block that later delegations supersede is a no-op.
 		pillarName := txData.Inputs["name"]
 		if pillarName != "" && block.PairedAccountBlock != nil {
-			pillarOwner := i.getPillarOwnerAddress(pillarName)
+			pillarOwner := i.getPillarOwnerAddress(pillarName, m.Height)
 			if pillarOwner != "" {
 				delegatorAddress := block.PairedAccountBlock.Address.String()
-				ts := int64(m.TimestampUnix)
-				i.repos.Account.UpdateDelegateBatch(batch, delegatorAddress, pillarOwner, ts)
-				i.repos.Delegation.CloseActiveBatch(batch, delegatorAddress, ts)
-				i.repos.Delegation.OpenBatch(batch, delegatorAddress, pillarOwner, ts)
+				i.repos.Delegation.DelegateBatch(batch, block.Hash.String(), block.Height,
+					delegatorAddress, pillarOwner, int64(m.TimestampUnix))
 				i.logger.Debug("delegation recorded",
 					zap.String("delegator", delegatorAddress),
 					zap.String("pillar", pillarName))
+				return i.domainEvent(webhooks.EventDelegationChanged, webhooks.DelegationChanged{
+					Source:      eventSource(block, txData, m),
+					Delegator:   delegatorAddress,
+					Delegated:   true,
+					PillarName:  pillarName,
+					PillarOwner: pillarOwner,
+				})
 			}
 		}
 	case "Undelegate":
 		// Clear account delegation + close any open delegation interval.
 		if block.PairedAccountBlock != nil {
 			delegatorAddress := block.PairedAccountBlock.Address.String()
-			i.repos.Account.UpdateDelegateBatch(batch, delegatorAddress, "", 0)
-			i.repos.Delegation.CloseActiveBatch(batch, delegatorAddress, int64(m.TimestampUnix))
+			i.repos.Delegation.UndelegateBatch(batch, block.Hash.String(), block.Height,
+				delegatorAddress, int64(m.TimestampUnix))
 			i.logger.Debug("undelegation recorded", zap.String("delegator", delegatorAddress))
+			return i.domainEvent(webhooks.EventDelegationChanged, webhooks.DelegationChanged{
+				Source:    eventSource(block, txData, m),
+				Delegator: delegatorAddress,
+			})
 		}
 	case "Revoke":
-		// Mark pillar as revoked
+		// Mark pillar as revoked. An accepted revoke returns the stake to
+		// the owner in a descendant block; a rejected one (not the owner,
+		// not yet revocable) has none.
 		pillarName := txData.Inputs["name"]
-		if pillarName != "" && block.PairedAccountBlock != nil {
+		if pillarName != "" && block.PairedAccountBlock != nil && len(block.DescendantBlocks) > 0 {
 			pillarOwner := block.PairedAccountBlock.Address.String()
 			i.repos.Pillar.SetAsRevokedBatch(batch, pillarOwner, pillarName, int64(m.TimestampUnix))
+			i.repos.PillarUpdate.InsertBatch(batch, &models.PillarUpdate{
+				AccountBlockHash:  block.Hash.String(),
+				Method:            method,
+				OwnerAddress:      pillarOwner,
+				Name:              pillarName,
+				MomentumHeight:    int64(m.Height),
+				MomentumTimestamp: int64(m.TimestampUnix),
+				MomentumHash:      m.Hash.String(),
+			})
+			i.pillarNames.Revoked(pillarName, pillarOwner, m.Height)
 			i.logger.Debug("pillar revoked",
 				zap.String("name", pillarName),
 				zap.String("owner", pillarOwner))
+			return i.domainEvent(webhooks.EventPillarRevoked, webhooks.PillarRevoked{
+				Source: eventSource(block, txData, m),
+				Name:   pillarName,
+				Owner:  pillarOwner,
+			})
 		}
 	}
+	return nil
 }
 
 // indexStakeContract handles stake contract events
-func (i *Indexer) indexStakeContract(ctx context.Context, batch *pgx.Batch, block *api.AccountBlock, txData *models.TxData, m *api.Momentum) {
+func (i *Indexer) indexStakeContract(ctx context.Context, batch *pgx.Batch, block *api.AccountBlock, txData *models.TxData, m *api.Momentum) []webhooks.Event {
 	method := txData.Method
 
 	switch method {
@@ -155,13 +233,10 @@ func (i *Indexer) indexStakeContract(ctx context.Context, batch *pgx.Batch, bloc
 			}
 
 			stakeID := block.PairedAccountBlock.Hash.String()
-			znnAmount := safeBigIntToInt64(block.PairedAccountBlock.Amount, i.logger,
-				"stake amount overflow",
-				zap.String("stakeID", stakeID))
 			stake := &models.Stake{
 				ID:                  stakeID,
 				Address:             block.PairedAccountBlock.Address.String(),
-				ZnnAmount:           znnAmount,
+				ZnnAmount:           block.PairedAccountBlock.Amount,
 				StartTimestamp:      int64(m.TimestampUnix),
 				DurationInSec:       duration,
 				ExpirationTimestamp: int64(m.TimestampUnix) + int64(duration),
@@ -169,6 +244,14 @@ func (i *Indexer) indexStakeContract(ctx context.Context, batch *pgx.Batch, bloc
 				CancelID:            i.getStakeCancelID(stakeID),
 			}
 			i.repos.Stake.InsertBatch(batch, stake)
+			return i.domainEvent(webhooks.EventStakeCreated, webhooks.StakeCreated{
+				Source:              eventSource(block, txData, m),
+				ID:                  stakeID,
+				Address:             stake.Address,
+				ZnnAmount:           amountString(stake.ZnnAmount),
+				DurationInSec:       duration,
+				ExpirationTimestamp: stake.ExpirationTimestamp,
+			})
 		}
 	case "Cancel":
 		stakeID := txData.Inputs["id"]
@@ -177,29 +260,109 @@ func (i *Indexer) indexStakeContract(ctx context.Context, batch *pgx.Batch, bloc
 			cancelID := i.getStakeCancelID(stakeID)
 			address := block.PairedAccountBlock.Address.String()
 			i.repos.Stake.SetInactiveBatch(batch, cancelID, address)
+			return i.domainEvent(webhooks.EventStakeCancelled, webhooks.StakeCancelled{
+				Source:  eventSource(block, txData, m),
+				ID:      stakeID,
+				Address: address,
+			})
 		}
 	}
+	return nil
 }
 
-// indexSentinelContract handles sentinel contract events
+// indexSentinelContract records the Sentinel contract's accepted calls
+// as sentinel_events and keeps sentinels' active flag in step with
+// Register and Revoke. Whether the contract accepted a call shows in its
+// descendants: Register and DepositQsr carry funds and are refunded when
+// rejected, while Revoke, WithdrawQsr and CollectReward pay out only
+// when accepted.
 func (i *Indexer) indexSentinelContract(ctx context.Context, batch *pgx.Batch, block *api.AccountBlock, txData *models.TxData, m *api.Momentum) {
-	method := txData.Method
+	if block.PairedAccountBlock == nil {
+		return
+	}
+	paired := block.PairedAccountBlock
+	owner := paired.Address.String()
+	event := &models.SentinelEvent{
+		AccountBlockHash:  block.Hash.String(),
+		SendBlockHash:     paired.Hash.String(),
+		Owner:             owner,
+		Method:            txData.Method,
+		ZnnAmount:         big.NewInt(0),
+		QsrAmount:         big.NewInt(0),
+		MomentumHeight:    int64(m.Height),
+		MomentumTimestamp: int64(m.TimestampUnix),
+	}
 
-	switch method {
-	case "Revoke":
-		// Mark sentinel as inactive
-		if block.PairedAccountBlock != nil {
-			owner := block.PairedAccountBlock.Address.String()
+	switch txData.Method {
+	case "DepositQsr":
+		if len(block.DescendantBlocks) > 0 || paired.Amount == nil {
+			return
+		}
+		event.QsrAmount = paired.Amount
+	case "Register":
+		// The ZNN comes with the call; the QSR is taken from the owner's
+		// deposit.
+		if len(block.DescendantBlocks) > 0 || paired.Amount == nil {
+			return
+		}
+		event.ZnnAmount = paired.Amount
+		event.QsrAmount = new(big.Int).Set(embedded.SentinelRegisterQsrAmount)
+		i.repos.Sentinel.RegisterBatch(batch, owner, int64(m.TimestampUnix))
+		i.logger.Debug("sentinel registered", zap.String("owner", owner))
+	case "Revoke", "WithdrawQsr":
+		// Both pay back with plain sends, one per token.
+		if len(block.DescendantBlocks) == 0 {
+			return
+		}
+		for _, d := range block.DescendantBlocks {
+			addSentinelAmount(event, d.TokenStandard.String(), d.Amount)
+		}
+		if txData.Method == "Revoke" {
 			i.repos.Sentinel.SetInactiveBatch(batch, owner)
 			i.logger.Debug("sentinel revoked", zap.String("owner", owner))
 		}
+	case "CollectReward":
+		// Rewards are minted: each descendant is a Token.Mint whose data
+		// carries the token and amount, as for swap retrievals.
+		if len(block.DescendantBlocks) == 0 {
+			return
+		}
+		for _, d := range block.DescendantBlocks {
+			dec := i.tryDecodeFromAbi(d.Data, embedded.Token)
+			if dec == nil || dec.Method != "Mint" {
+				continue
+			}
+			amount, ok := new(big.Int).SetString(dec.Inputs["amount"], 10)
+			if !ok {
+				i.logger.Warn("sentinel reward: unparseable mint amount",
+					zap.String("hash", block.Hash.String()),
+					zap.String("amount", dec.Inputs["amount"]))
+				continue
+			}
+			addSentinelAmount(event, dec.Inputs["tokenStandard"], amount)
+		}
 	default:
-		i.logger.Debug("sentinel contract event", zap.String("method", method))
+		i.logger.Debug("sentinel contract event", zap.String("method", txData.Method))
+		return
+	}
+	i.repos.SentinelEvent.InsertBatch(batch, event)
+}
+
+// addSentinelAmount adds amount to e's ZNN or QSR total by token.
+func addSentinelAmount(e *models.SentinelEvent, tokenStandard string, amount *big.Int) {
+	if amount == nil {
+		return
+	}
+	switch tokenStandard {
+	case models.ZnnTokenStandard:
+		e.ZnnAmount.Add(e.ZnnAmount, amount)
+	case models.QsrTokenStandard:
+		e.QsrAmount.Add(e.QsrAmount, amount)
 	}
 }
 
 // indexPlasmaContract handles plasma contract events
-func (i *Indexer) indexPlasmaContract(ctx context.Context, batch *pgx.Batch, block *api.AccountBlock, txData *models.TxData, m *api.Momentum) {
+func (i *Indexer) indexPlasmaContract(ctx context.Context, batch *pgx.Batch, block *api.AccountBlock, txData *models.TxData, m *api.Momentum) []webhooks.Event {
 	method := txData.Method
 
 	switch method {
@@ -210,14 +373,11 @@ func (i *Indexer) indexPlasmaContract(ctx context.Context, batch *pgx.Batch, blo
 				beneficiary = block.PairedAccountBlock.Address.String()
 			}
 			fusionID := block.PairedAccountBlock.Hash.String()
-			qsrAmount := safeBigIntToInt64(block.PairedAccountBlock.Amount, i.logger,
-				"fusion qsr amount overflow",
-				zap.String("fusionID", fusionID))
 			fusion := &models.Fusion{
 				ID:                fusionID,
 				Address:           block.PairedAccountBlock.Address.String(),
 				Beneficiary:       beneficiary,
-				QsrAmount:         qsrAmount,
+				QsrAmount:         block.PairedAccountBlock.Amount,
 				MomentumTimestamp: int64(m.TimestampUnix),
 				MomentumHeight:    int64(m.Height),
 				MomentumHash:      m.Hash.String(),
@@ -226,6 +386,14 @@ func (i *Indexer) indexPlasmaContract(ctx context.Context, batch *pgx.Batch, blo
 				CancelID:          i.getFusionCancelID(fusionID),
 			}
 			i.repos.Fusion.InsertBatch(batch, fusion)
+			return i.domainEvent(webhooks.EventFusionCreated, webhooks.FusionCreated{
+				Source:           eventSource(block, txData, m),
+				ID:               fusionID,
+				Address:          fusion.Address,
+				Beneficiary:      beneficiary,
+				QsrAmount:        amountString(fusion.QsrAmount),
+				ExpirationHeight: fusion.ExpirationHeight,
+			})
 		}
 	case "CancelFuse":
 		fusionID := txData.Inputs["id"]
@@ -236,10 +404,11 @@ func (i *Indexer) indexPlasmaContract(ctx context.Context, batch *pgx.Batch, blo
 			i.repos.Fusion.SetInactiveBatch(batch, cancelID, address)
 		}
 	}
+	return nil
 }
 
 // indexAcceleratorContract handles accelerator contract events
-func (i *Indexer) indexAcceleratorContract(ctx context.Context, batch *pgx.Batch, block *api.AccountBlock, txData *models.TxData, m *api.Momentum) {
+func (i *Indexer) indexAcceleratorContract(ctx context.Context, batch *pgx.Batch, block *api.AccountBlock, txData *models.TxData, m *api.Momentum) []webhooks.Event {
 	method := txData.Method
 
 	switch method {
@@ -268,7 +437,7 @@ func (i *Indexer) indexAcceleratorContract(ctx context.Context, batch *pgx.Batch
 			if method == "VoteByName" {
 				pillarName := txData.Inputs["name"]
 				if pillarName != "" {
-					if owner := i.getPillarOwnerAddress(pillarName); owner != "" {
+					if owner := i.getPillarOwnerAddress(pillarName, m.Height); owner != "" {
 						voterAddress = owner
 					}
 				}
@@ -291,16 +460,130 @@ func (i *Indexer) indexAcceleratorContract(ctx context.Context, batch *pgx.Batch
 				zap.String("projectID", projectID),
 				zap.String("phaseID", phaseID),
 				zap.String("voter", voterAddress))
+			return i.domainEvent(webhooks.EventVoteCast, webhooks.VoteCast{
+				Source:    eventSource(block, txData, m),
+				VotingID:  votingID,
+				Voter:     voterAddress,
+				ProjectID: projectID,
+				PhaseID:   phaseID,
+				Vote:      voteValue,
+			})
 		}
 	case "CreateProject":
+		// The project row itself is written by the cached-data sync, which
+		// reads the full project from the node. The id is the send-block
+		// hash (go-zenon: project.Id = sendBlock.Hash).
 		i.logger.Debug("project created", zap.String("method", method))
+		paired := block.PairedAccountBlock
+		// A rejected create refunds the fee; an accepted one sends nothing.
+		if len(block.DescendantBlocks) == 0 {
+			c := newProjectStatusChange(block, txData, m)
+			c.ProjectID = paired.Hash.String()
+			c.VotingID = c.ProjectID
+			i.repos.Accelerator.CreateProjectBatch(batch, c)
+		}
+		return i.domainEvent(webhooks.EventProjectCreated, webhooks.ProjectCreated{
+			Source:         eventSource(block, txData, m),
+			ID:             paired.Hash.String(),
+			Owner:          paired.Address.String(),
+			Name:           txData.Inputs["name"],
+			Description:    txData.Inputs["description"],
+			URL:            txData.Inputs["url"],
+			ZnnFundsNeeded: txData.Inputs["znnFundsNeeded"],
+			QsrFundsNeeded: txData.Inputs["qsrFundsNeeded"],
+		})
 	case "AddPhase", "UpdatePhase":
 		i.logger.Debug("phase updated", zap.String("method", method))
+		// The phase id is the send-block hash, as for projects. Neither
+		// call moves funds, so the repository replays the contract's
+		// checks to tell an accepted one from a rejected one.
+		if block.PairedAccountBlock == nil || txData.Inputs["id"] == "" {
+			return nil
+		}
+		c := newProjectStatusChange(block, txData, m)
+		c.ProjectID = txData.Inputs["id"]
+		c.PhaseID = block.PairedAccountBlock.Hash.String()
+		c.VotingID = c.PhaseID
+		i.repos.Accelerator.AddPhaseBatch(batch, c)
+	case "Update":
+		// Update settles projects in voting and pays out phases whose
+		// votes passed: one ZNN and one QSR send to the project owner,
+		// each carrying the phase id as data.
+		if block.PairedAccountBlock == nil {
+			return nil
+		}
+		i.repos.Accelerator.SettleBatch(batch, block.Hash.String(),
+			block.PairedAccountBlock.Address.String(), int64(m.Height), int64(m.TimestampUnix))
+		var paid []*models.ProjectStatusChange
+		byPhase := map[string]*models.ProjectStatusChange{}
+		for _, d := range block.DescendantBlocks {
+			if len(d.Data) != 32 || d.Amount == nil {
+				continue
+			}
+			phaseID := hex.EncodeToString(d.Data)
+			c, ok := byPhase[phaseID]
+			if !ok {
+				c = newProjectStatusChange(block, txData, m)
+				c.PhaseID = phaseID
+				byPhase[phaseID] = c
+				paid = append(paid, c)
+			}
+			tokenStandard := d.TokenStandard.String()
+			switch tokenStandard {
+			case models.ZnnTokenStandard:
+				c.ZnnAmount.Add(c.ZnnAmount, d.Amount)
+			case models.QsrTokenStandard:
+				c.QsrAmount.Add(c.QsrAmount, d.Amount)
+			}
+			i.repos.Accelerator.InsertPayoutBatch(batch, &models.AcceleratorPayout{
+				AccountBlockHash:  d.Hash.String(),
+				UpdateBlockHash:   block.Hash.String(),
+				PhaseID:           phaseID,
+				ToAddress:         d.ToAddress.String(),
+				TokenStandard:     tokenStandard,
+				Amount:            d.Amount,
+				MomentumHeight:    int64(m.Height),
+				MomentumTimestamp: int64(m.TimestampUnix),
+			})
+		}
+		for _, c := range paid {
+			i.repos.Accelerator.PhasePaidBatch(batch, c)
+			i.logger.Debug("accelerator phase paid",
+				zap.String("phaseID", c.PhaseID),
+				zap.String("znn", c.ZnnAmount.String()),
+				zap.String("qsr", c.QsrAmount.String()))
+		}
 	}
+	return nil
+}
+
+// newProjectStatusChange fills the fields every project_status_changes
+// row shares from the accelerator's receive block and its paired send.
+// Amounts default to the funds the call asks for, zero when it asks for
+// none.
+func newProjectStatusChange(block *api.AccountBlock, txData *models.TxData, m *api.Momentum) *models.ProjectStatusChange {
+	c := &models.ProjectStatusChange{
+		AccountBlockHash:  block.Hash.String(),
+		Method:            txData.Method,
+		ZnnAmount:         big.NewInt(0),
+		QsrAmount:         big.NewInt(0),
+		MomentumHeight:    int64(m.Height),
+		MomentumTimestamp: int64(m.TimestampUnix),
+	}
+	if block.PairedAccountBlock != nil {
+		c.Address = block.PairedAccountBlock.Address.String()
+	}
+	if v, ok := new(big.Int).SetString(txData.Inputs["znnFundsNeeded"], 10); ok {
+		c.ZnnAmount = v
+	}
+	if v, ok := new(big.Int).SetString(txData.Inputs["qsrFundsNeeded"], 10); ok {
+		c.QsrAmount = v
+	}
+	return c
 }
 
 // indexTokenContract handles token contract events
-func (i *Indexer) indexTokenContract(ctx context.Context, batch *pgx.Batch, block *api.AccountBlock, txData *models.TxData, m *api.Momentum) {
+func (i *Indexer) indexTokenContract(ctx context.Context, batch *pgx.Batch, block *api.AccountBlock, txData *models.TxData, m *api.Momentum) []webhooks.Event {
 	method := txData.Method
 
 	switch method {
@@ -310,18 +593,17 @@ func (i *Indexer) indexTokenContract(ctx context.Context, batch *pgx.Batch, bloc
 		// address is the issuer (typically an embedded reward contract or a
 		// token owner).
 		if block.PairedAccountBlock == nil {
-			return
+			return nil
 		}
 		tokenStandard := txData.Inputs["tokenStandard"]
 		amountStr := txData.Inputs["amount"]
 		receiver := txData.Inputs["receiveAddress"]
-		amount, err := strconv.ParseInt(amountStr, 10, 64)
-		if err != nil {
+		amount, ok := new(big.Int).SetString(amountStr, 10)
+		if !ok {
 			i.logger.Warn("invalid mint amount",
 				zap.String("amount", amountStr),
-				zap.String("hash", block.Hash.String()),
-				zap.Error(err))
-			return
+				zap.String("hash", block.Hash.String()))
+			return nil
 		}
 		mint := &models.TokenMint{
 			AccountBlockHash:  block.Hash.String(),
@@ -337,20 +619,24 @@ func (i *Indexer) indexTokenContract(ctx context.Context, batch *pgx.Batch, bloc
 			zap.String("token", tokenStandard),
 			zap.String("issuer", mint.Issuer),
 			zap.String("receiver", mint.Receiver),
-			zap.Int64("amount", amount))
+			zap.Stringer("amount", amount))
+		return i.domainEvent(webhooks.EventTokenMinted, webhooks.TokenMinted{
+			Source:        eventSource(block, txData, m),
+			TokenStandard: tokenStandard,
+			Issuer:        mint.Issuer,
+			Receiver:      receiver,
+			Amount:        amount.String(),
+		})
 
 	case "Burn":
 		// A Burn contract-receive on the token contract. The paired send
 		// carries the actual amount and token; the send's address is the
 		// burner.
 		if block.PairedAccountBlock == nil {
-			return
+			return nil
 		}
 		tokenStandard := block.PairedAccountBlock.TokenStandard.String()
-		burnAmount := safeBigIntToInt64(block.PairedAccountBlock.Amount, i.logger,
-			"token burn amount overflow",
-			zap.String("hash", block.Hash.String()),
-			zap.String("token", tokenStandard))
+		burnAmount := block.PairedAccountBlock.Amount
 		burner := block.PairedAccountBlock.Address.String()
 		i.repos.TokenEvent.InsertBurnBatch(batch, &models.TokenBurn{
 			AccountBlockHash:  block.Hash.String(),
@@ -360,11 +646,43 @@ func (i *Indexer) indexTokenContract(ctx context.Context, batch *pgx.Batch, bloc
 			Burner:            burner,
 			Amount:            burnAmount,
 		})
-		i.repos.Token.UpdateBurnAmountBatch(batch, tokenStandard, burnAmount)
+		i.repos.Token.AddBlockBurnBatch(batch, block.Hash.String(), tokenStandard, burnAmount)
 		i.logger.Debug("token burn recorded",
 			zap.String("token", tokenStandard),
 			zap.String("burner", burner),
-			zap.Int64("amount", burnAmount))
+			zap.Stringer("amount", burnAmount))
+		return i.domainEvent(webhooks.EventTokenBurned, webhooks.TokenBurned{
+			Source:        eventSource(block, txData, m),
+			TokenStandard: tokenStandard,
+			Burner:        burner,
+			Amount:        amountString(burnAmount),
+		})
+
+	case "IssueToken":
+		// The contract sends the whole initial supply of the new token
+		// back to the issuer; a rejected issue only refunds the ZNN fee.
+		// That descendant is the only place the new token standard shows.
+		if block.PairedAccountBlock == nil {
+			return nil
+		}
+		tokenStandard := ""
+		for _, d := range block.DescendantBlocks {
+			if zts := d.TokenStandard.String(); zts != models.ZnnTokenStandard {
+				tokenStandard = zts
+				break
+			}
+		}
+		if tokenStandard == "" {
+			return nil
+		}
+		e := newTokenEvent(block, txData, m, tokenStandard)
+		e.OwnerAfter = e.Address
+		e.IsMintableAfter = txData.Inputs["isMintable"] == "true"
+		e.IsBurnableAfter = txData.Inputs["isBurnable"] == "true"
+		i.repos.TokenEvent.InsertIssueBatch(batch, e)
+		i.logger.Debug("token issue recorded",
+			zap.String("token", tokenStandard),
+			zap.String("owner", e.OwnerAfter))
 
 	case "UpdateToken":
 		// Update token last update timestamp
@@ -375,6 +693,33 @@ func (i *Indexer) indexTokenContract(ctx context.Context, batch *pgx.Batch, bloc
 				zap.String("token", tokenStandard),
 				zap.Int64("timestamp", int64(m.TimestampUnix)))
 		}
+		// UpdateToken has no descendants either way; the repository
+		// replays the contract's owner and mintable checks instead.
+		if tokenStandard == "" || block.PairedAccountBlock == nil {
+			return nil
+		}
+		e := newTokenEvent(block, txData, m, tokenStandard)
+		e.OwnerAfter = txData.Inputs["owner"]
+		e.IsMintableAfter = txData.Inputs["isMintable"] == "true"
+		e.IsBurnableAfter = txData.Inputs["isBurnable"] == "true"
+		i.repos.TokenEvent.UpdateTokenBatch(batch, e)
+	}
+	return nil
+}
+
+// newTokenEvent fills the fields every token_events row shares from the
+// token contract's receive block and its paired send.
+func newTokenEvent(block *api.AccountBlock, txData *models.TxData, m *api.Momentum, tokenStandard string) *models.TokenEvent {
+	return &models.TokenEvent{
+		AccountBlockHash:  block.Hash.String(),
+		SendBlockHash:     block.PairedAccountBlock.Hash.String(),
+		TokenStandard:     tokenStandard,
+		Method:            txData.Method,
+		Address:           block.PairedAccountBlock.Address.String(),
+		AccountHeight:     int64(block.Height),
+		Inputs:            txData.Inputs,
+		MomentumHeight:    int64(m.Height),
+		MomentumTimestamp: int64(m.TimestampUnix),
 	}
 }
 
@@ -455,9 +800,9 @@ func bytesToHex(b []byte) string {
 // receive-block hash). Create carries the lock params + the send's
 // amount/token/sender (all from paired); Unlock and Reclaim carry the target id
 // (and Unlock a preimage) to settle the entry.
-func (i *Indexer) indexHtlcContract(ctx context.Context, batch *pgx.Batch, block *api.AccountBlock, txData *models.TxData, m *api.Momentum) {
+func (i *Indexer) indexHtlcContract(ctx context.Context, batch *pgx.Batch, block *api.AccountBlock, txData *models.TxData, m *api.Momentum) []webhooks.Event {
 	if block.PairedAccountBlock == nil {
-		return
+		return nil
 	}
 	paired := block.PairedAccountBlock
 
@@ -489,15 +834,12 @@ func (i *Indexer) indexHtlcContract(ctx context.Context, batch *pgx.Batch, block
 			keyMaxSize = 0
 		}
 
-		amount := safeBigIntToInt64(paired.Amount, i.logger,
-			"htlc amount overflow", zap.String("htlcID", id))
-
 		h := &models.Htlc{
 			ID:                  id,
 			TimeLockedAddress:   paired.Address.String(), // sender can Reclaim
 			HashLockedAddress:   txData.Inputs["hashLocked"],
 			TokenStandard:       paired.TokenStandard.String(),
-			Amount:              amount,
+			Amount:              paired.Amount,
 			ExpirationTimestamp: expiration,
 			HashType:            int16(hashType),
 			KeyMaxSize:          int16(keyMaxSize),
@@ -510,22 +852,182 @@ func (i *Indexer) indexHtlcContract(ctx context.Context, batch *pgx.Batch, block
 			CreationMomentumTimestamp: int64(m.TimestampUnix),
 		}
 		i.repos.Htlc.InsertBatch(batch, h)
+		return i.domainEvent(webhooks.EventHtlcCreated, webhooks.HtlcCreated{
+			Source:              eventSource(block, txData, m),
+			ID:                  id,
+			TimeLockedAddress:   h.TimeLockedAddress,
+			HashLockedAddress:   h.HashLockedAddress,
+			TokenStandard:       h.TokenStandard,
+			Amount:              amountString(h.Amount),
+			ExpirationTimestamp: expiration,
+			HashType:            hashType,
+			KeyMaxSize:          keyMaxSize,
+			HashLock:            h.HashLock,
+		})
 
 	case "Unlock":
 		id := txData.Inputs["id"]
 		if id == "" {
-			return
+			return nil
 		}
 		// preimage is ABI `bytes`; encode unconditionally (see Create/hashLock).
+		preimage := bytesToHex([]byte(txData.Inputs["preimage"]))
 		i.repos.Htlc.SettleBatch(batch, id, int16(models.HtlcStatusUnlocked),
-			bytesToHex([]byte(txData.Inputs["preimage"])), int64(m.Height), int64(m.TimestampUnix))
+			preimage, int64(m.Height), int64(m.TimestampUnix))
+		return i.domainEvent(webhooks.EventHtlcUnlocked, webhooks.HtlcUnlocked{
+			Source:   eventSource(block, txData, m),
+			ID:       id,
+			Address:  paired.Address.String(),
+			Preimage: preimage,
+		})
 
 	case "Reclaim":
 		id := txData.Inputs["id"]
 		if id == "" {
-			return
+			return nil
 		}
 		i.repos.Htlc.SettleBatch(batch, id, int16(models.HtlcStatusReclaimed),
 			"", int64(m.Height), int64(m.TimestampUnix))
+		return i.domainEvent(webhooks.EventHtlcReclaimed, webhooks.HtlcReclaimed{
+			Source:  eventSource(block, txData, m),
+			ID:      id,
+			Address: paired.Address.String(),
+		})
+	}
+	return nil
+}
+
+// indexSporkContract handles Spork contract events. A spork's id is the
+// hash of its CreateSpork send block, which is what ActivateSpork names.
+// The contract enforces a spork from the frontier momentum it saw when
+// activating it, which is the receive block's acknowledged momentum,
+// plus SporkMinHeightDelay.
+func (i *Indexer) indexSporkContract(ctx context.Context, batch *pgx.Batch, block *api.AccountBlock, txData *models.TxData, m *api.Momentum) {
+	if block.PairedAccountBlock == nil {
+		return
+	}
+	paired := block.PairedAccountBlock
+
+	switch txData.Method {
+	case "CreateSpork":
+		i.repos.Spork.InsertBatch(batch, &models.Spork{
+			ID:                        paired.Hash.String(),
+			Name:                      txData.Inputs["name"],
+			Description:               txData.Inputs["description"],
+			CreatorAddress:            paired.Address.String(),
+			CreationMomentumHeight:    int64(m.Height),
+			CreationMomentumTimestamp: int64(m.TimestampUnix),
+		})
+		i.logger.Info("spork created",
+			zap.String("id", paired.Hash.String()), zap.String("name", txData.Inputs["name"]))
+
+	case "ActivateSpork":
+		id := txData.Inputs["id"]
+		if id == "" {
+			return
+		}
+		enforcement := int64(block.MomentumAcknowledged.Height) + models.SporkMinHeightDelay
+		i.repos.Spork.ActivateBatch(batch, id, enforcement, int64(m.Height), int64(m.TimestampUnix))
+		i.logger.Info("spork activated",
+			zap.String("id", id), zap.Int64("enforcementHeight", enforcement))
+	}
+}
+
+// indexLiquidityContract handles the Liquidity contract's LP-token stake
+// entries, and records every administrator and guardian call as a
+// liquidity_admin_actions row. Liquidity reward receipts are indexed by
+// indexLiquidityReward. The configuration itself is left to
+// syncLiquidityConfig, as the administrator's calls only take effect
+// once their time challenge has passed; like bridge_events, the
+// recorded calls include ones the contract rejected.
+func (i *Indexer) indexLiquidityContract(ctx context.Context, batch *pgx.Batch, block *api.AccountBlock, txData *models.TxData, m *api.Momentum) {
+	if block.PairedAccountBlock == nil {
+		return
+	}
+	paired := block.PairedAccountBlock
+
+	if models.LiquidityAdminMethods[txData.Method] {
+		i.repos.Liquidity.InsertAdminActionBatch(batch, &models.LiquidityAdminAction{
+			AccountBlockHash:  block.Hash.String(),
+			SendBlockHash:     paired.Hash.String(),
+			Method:            txData.Method,
+			Address:           paired.Address.String(),
+			TokenStandard:     paired.TokenStandard.String(),
+			Amount:            paired.Amount,
+			Inputs:            txData.Inputs,
+			MomentumHeight:    int64(m.Height),
+			MomentumTimestamp: int64(m.TimestampUnix),
+		})
+	}
+
+	switch txData.Method {
+	case "LiquidityStake":
+		// A rejected stake (token not configured, amount below its
+		// minimum) is refunded by a descendant send; an accepted one has
+		// no descendants.
+		if len(block.DescendantBlocks) > 0 || paired.Amount == nil {
+			return
+		}
+		duration, err := strconv.ParseInt(txData.Inputs["durationInSec"], 10, 64)
+		if err != nil {
+			i.logger.Warn("invalid liquidity stake duration",
+				zap.String("duration", txData.Inputs["durationInSec"]), zap.Error(err))
+			return
+		}
+		start := int64(m.TimestampUnix)
+		i.repos.Liquidity.InsertStakeBatch(batch, &models.LiquidityStake{
+			ID:                     paired.Hash.String(),
+			StakeAddress:           paired.Address.String(),
+			TokenStandard:          paired.TokenStandard.String(),
+			Amount:                 paired.Amount,
+			WeightedAmount:         new(big.Int).Mul(paired.Amount, big.NewInt(duration/models.LiquidityStakeTimeUnitSec)),
+			DurationInSec:          duration,
+			StartTimestamp:         start,
+			ExpirationTimestamp:    start + duration,
+			IsActive:               true,
+			CreationMomentumHeight: int64(m.Height),
+		})
+
+	case "CancelLiquidityStake":
+		// Only a cancel the contract accepted pays the stake back; one
+		// sent before expiry (or for someone else's entry) has no
+		// descendants.
+		id := txData.Inputs["id"]
+		if id == "" || len(block.DescendantBlocks) == 0 {
+			return
+		}
+		i.repos.Liquidity.CancelStakeBatch(batch, id, paired.Address.String(), int64(m.TimestampUnix), int64(m.Height))
+
+	case "UnlockLiquidityStakeEntries":
+		// Administrator only; the token whose entries are unlocked is the
+		// call's token standard.
+		token := paired.TokenStandard.String()
+		i.repos.Liquidity.UnlockStakesBatch(batch, token, int64(m.TimestampUnix), int64(m.Height))
+		i.logger.Info("liquidity stake entries unlocked",
+			zap.String("token", token), zap.String("sender", paired.Address.String()))
 	}
 }
+
+// indexBridgeContract records every call into the Bridge contract —
+// user wraps, unwraps and redeems as well as the administrator's and
+// guardians' calls — as a bridge_events row. Requests themselves are
+// still mirrored from BridgeApi by runBridgeSyncLoop; this is the ledger
+// side. Calls the contract rejected are recorded too: a rejection leaves
+// no mark on the receive block that every method shares.
+func (i *Indexer) indexBridgeContract(batch *pgx.Batch, block *api.AccountBlock, txData *models.TxData, m *api.Momentum) {
+	if block.PairedAccountBlock == nil {
+		return
+	}
+	paired := block.PairedAccountBlock
+	i.repos.BridgeEvent.InsertBatch(batch, &models.BridgeEvent{
+		AccountBlockHash:  block.Hash.String(),
+		SendBlockHash:     paired.Hash.String(),
+		Method:            txData.Method,
+		Address:           paired.Address.String(),
+		TokenStandard:     paired.TokenStandard.String(),
+		Amount:            paired.Amount,
+		Inputs:            txData.Inputs,
+		MomentumHeight:    int64(m.Height),
+		MomentumTimestamp: int64(m.TimestampUnix),
+	})
+}
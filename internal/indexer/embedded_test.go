@@ -0,0 +1,481 @@
+package indexer
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/0x3639/znn-sdk-go/embedded"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api"
+	"go.uber.org/zap"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+	"github.com/0x3639/nom-indexer-go/internal/repository"
+	"github.com/0x3639/nom-indexer-go/internal/webhooks"
+)
+
+const (
+	testHashA = "0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20"
+	testHashB = "a1a2a3a4a5a6a7a8a9aaabacadaeafb0b1b2b3b4b5b6b7b8b9babbbcbdbebfc0"
+	testUser  = "z1qph8dkja68pg3g6j4spwk9re0kjdkul0amwqnt"
+)
+
+// contractReceive builds a contract receive block on contract paired
+// with a user send of amount ZNN.
+func contractReceive(contract string, amount int64) *api.AccountBlock {
+	return &api.AccountBlock{
+		AccountBlock: nom.AccountBlock{
+			Hash:    types.HexToHashPanic(testHashA),
+			Address: types.ParseAddressPanic(contract),
+		},
+		PairedAccountBlock: &api.AccountBlock{
+			AccountBlock: nom.AccountBlock{
+				Hash:          types.HexToHashPanic(testHashB),
+				Address:       types.ParseAddressPanic(testUser),
+				Amount:        big.NewInt(amount),
+				TokenStandard: types.ZnnTokenStandard,
+			},
+		},
+	}
+}
+
+func testMomentum() *api.Momentum {
+	return &api.Momentum{Momentum: &nom.Momentum{Height: 100, TimestampUnix: 1700000000}}
+}
+
+func TestIndexEmbeddedContracts_EmitsDomainEvents(t *testing.T) {
+	i := &Indexer{
+		logger:   zap.NewNop(),
+		repos:    repository.NewRepositories(nil),
+		webhooks: webhooks.New(nil, nil, nil, webhooks.Config{}, nil),
+	}
+	ctx := context.Background()
+
+	var batch pgx.Batch
+	events := i.indexEmbeddedContracts(ctx, &batch, contractReceive(models.StakeAddress, 5e8),
+		&models.TxData{Method: "Stake", Inputs: map[string]string{"durationInSec": "2592000"}}, testMomentum())
+	if len(events) != 1 || events[0].Type != webhooks.EventStakeCreated {
+		t.Fatalf("events = %+v, want one stake.created", events)
+	}
+	stake := events[0].Payload.(webhooks.StakeCreated)
+	want := webhooks.StakeCreated{
+		Source:              webhooks.Source{Method: "Stake", AccountBlockHash: testHashA, MomentumHeight: 100, MomentumTimestamp: 1700000000},
+		ID:                  testHashB,
+		Address:             testUser,
+		ZnnAmount:           "500000000",
+		DurationInSec:       2592000,
+		ExpirationTimestamp: 1700000000 + 2592000,
+	}
+	if stake != want {
+		t.Errorf("payload = %+v\nwant      %+v", stake, want)
+	}
+	if batch.Len() != 1 {
+		t.Errorf("batch.Len() = %d, want the stake insert", batch.Len())
+	}
+
+	events = i.indexEmbeddedContracts(ctx, &pgx.Batch{}, contractReceive(models.HtlcAddress, 0),
+		&models.TxData{Method: "Unlock", Inputs: map[string]string{"id": testHashB, "preimage": "\x01\x02"}}, testMomentum())
+	if len(events) != 1 {
+		t.Fatalf("events = %+v, want one htlc.unlocked", events)
+	}
+	if p, ok := events[0].Payload.(webhooks.HtlcUnlocked); !ok || p.Preimage != "0102" || p.Address != testUser {
+		t.Errorf("htlc.unlocked payload = %+v", events[0].Payload)
+	}
+
+	// Calls that are indexed without a domain event return nothing.
+	events = i.indexEmbeddedContracts(ctx, &pgx.Batch{}, contractReceive(models.PlasmaAddress, 0),
+		&models.TxData{Method: "CancelFuse", Inputs: map[string]string{"id": testHashB}}, testMomentum())
+	if events != nil {
+		t.Errorf("CancelFuse events = %+v, want nil", events)
+	}
+}
+
+func TestIndexEmbeddedContracts_NoEventsWhenWebhooksDisabled(t *testing.T) {
+	i := &Indexer{logger: zap.NewNop(), repos: repository.NewRepositories(nil)}
+
+	var batch pgx.Batch
+	events := i.indexEmbeddedContracts(context.Background(), &batch, contractReceive(models.StakeAddress, 5e8),
+		&models.TxData{Method: "Stake", Inputs: map[string]string{"durationInSec": "60"}}, testMomentum())
+	if events != nil {
+		t.Errorf("events = %+v, want nil with webhooks disabled", events)
+	}
+	if batch.Len() != 1 {
+		t.Errorf("batch.Len() = %d, want the stake insert to be queued regardless", batch.Len())
+	}
+}
+
+func TestIndexSporkContract(t *testing.T) {
+	i := &Indexer{logger: zap.NewNop(), repos: repository.NewRepositories(nil)}
+	ctx := context.Background()
+
+	var batch pgx.Batch
+	i.indexEmbeddedContracts(ctx, &batch, contractReceive(models.SporkAddress, 0),
+		&models.TxData{Method: "CreateSpork", Inputs: map[string]string{"name": "spork-1", "description": "d"}}, testMomentum())
+	if batch.Len() != 1 {
+		t.Fatalf("CreateSpork queued %d statements, want the insert", batch.Len())
+	}
+	if args := batch.QueuedQueries[0].Arguments; args[0] != testHashB || args[1] != "spork-1" || args[3] != testUser {
+		t.Errorf("insert args = %v, want id %s, name spork-1, creator %s", args, testHashB, testUser)
+	}
+
+	// The spork is enforced 6 momentums after the activation's frontier,
+	// which is the receive block's acknowledged momentum.
+	rcv := contractReceive(models.SporkAddress, 0)
+	rcv.MomentumAcknowledged = types.HashHeight{Height: 98}
+	batch = pgx.Batch{}
+	i.indexEmbeddedContracts(ctx, &batch, rcv,
+		&models.TxData{Method: "ActivateSpork", Inputs: map[string]string{"id": testHashB}}, testMomentum())
+	if batch.Len() != 1 {
+		t.Fatalf("ActivateSpork queued %d statements, want the update", batch.Len())
+	}
+	args := batch.QueuedQueries[0].Arguments
+	if args[0] != testHashB || args[1] != int64(104) || args[2] != int64(100) {
+		t.Errorf("activate args = %v, want id %s, enforcement 104, height 100", args, testHashB)
+	}
+}
+
+func TestIndexLiquidityContract(t *testing.T) {
+	i := &Indexer{logger: zap.NewNop(), repos: repository.NewRepositories(nil)}
+	ctx := context.Background()
+	const month = models.LiquidityStakeTimeUnitSec
+
+	var batch pgx.Batch
+	i.indexEmbeddedContracts(ctx, &batch, contractReceive(models.LiquidityAddress, 50),
+		&models.TxData{Method: "LiquidityStake", Inputs: map[string]string{"durationInSec": "7776000"}}, testMomentum())
+	if batch.Len() != 1 {
+		t.Fatalf("LiquidityStake queued %d statements, want the insert", batch.Len())
+	}
+	args := batch.QueuedQueries[0].Arguments
+	if args[0] != testHashB || args[1] != testUser || args[5] != int64(3*month) ||
+		args[6] != int64(1700000000) || args[7] != int64(1700000000+3*month) {
+		t.Errorf("insert args = %v, want id %s, staker %s, 3 months from the momentum", args, testHashB, testUser)
+	}
+	if w, ok := args[4].(pgtype.Numeric); !ok || w.Int.Int64() != 150 {
+		t.Errorf("weighted amount = %v, want 150 (50 × 3 months)", args[4])
+	}
+
+	// A rejected stake is refunded by a descendant send.
+	refunded := contractReceive(models.LiquidityAddress, 50)
+	refunded.DescendantBlocks = []*nom.AccountBlock{{}}
+	batch = pgx.Batch{}
+	i.indexEmbeddedContracts(ctx, &batch, refunded,
+		&models.TxData{Method: "LiquidityStake", Inputs: map[string]string{"durationInSec": "7776000"}}, testMomentum())
+	if batch.Len() != 0 {
+		t.Errorf("refunded LiquidityStake queued %d statements, want none", batch.Len())
+	}
+
+	// A cancel only took effect if it paid the stake back.
+	batch = pgx.Batch{}
+	i.indexEmbeddedContracts(ctx, &batch, contractReceive(models.LiquidityAddress, 0),
+		&models.TxData{Method: "CancelLiquidityStake", Inputs: map[string]string{"id": testHashA}}, testMomentum())
+	if batch.Len() != 0 {
+		t.Errorf("rejected CancelLiquidityStake queued %d statements, want none", batch.Len())
+	}
+	paid := contractReceive(models.LiquidityAddress, 0)
+	paid.DescendantBlocks = []*nom.AccountBlock{{}}
+	i.indexEmbeddedContracts(ctx, &batch, paid,
+		&models.TxData{Method: "CancelLiquidityStake", Inputs: map[string]string{"id": testHashA}}, testMomentum())
+	if batch.Len() != 1 {
+		t.Fatalf("CancelLiquidityStake queued %d statements, want the update", batch.Len())
+	}
+	if args := batch.QueuedQueries[0].Arguments; args[0] != testHashA || args[1] != testUser || args[3] != int64(100) {
+		t.Errorf("cancel args = %v, want id %s, staker %s, height 100", args, testHashA, testUser)
+	}
+
+	batch = pgx.Batch{}
+	i.indexEmbeddedContracts(ctx, &batch, contractReceive(models.LiquidityAddress, 0),
+		&models.TxData{Method: "UnlockLiquidityStakeEntries", Inputs: map[string]string{}}, testMomentum())
+	if batch.Len() != 2 {
+		t.Fatalf("UnlockLiquidityStakeEntries queued %d statements, want the admin action and the update", batch.Len())
+	}
+	if args := batch.QueuedQueries[0].Arguments; args[0] != testHashA || args[2] != "UnlockLiquidityStakeEntries" || args[3] != testUser {
+		t.Errorf("admin action args = %v, want the unlock by %s", args, testUser)
+	}
+	if args := batch.QueuedQueries[1].Arguments; args[0] != types.ZnnTokenStandard.String() || args[1] != int64(1700000000) {
+		t.Errorf("unlock args = %v, want ZNN entries unlocked at the momentum timestamp", args)
+	}
+
+	// Configuration calls are recorded as they land; liquidity_config
+	// follows once the node applies them.
+	batch = pgx.Batch{}
+	i.indexEmbeddedContracts(ctx, &batch, contractReceive(models.LiquidityAddress, 0),
+		&models.TxData{Method: "SetIsHalted", Inputs: map[string]string{"isHalted": "true"}}, testMomentum())
+	if batch.Len() != 1 {
+		t.Fatalf("SetIsHalted queued %d statements, want the admin action", batch.Len())
+	}
+	if args := batch.QueuedQueries[0].Arguments; args[2] != "SetIsHalted" || args[6] != `{"isHalted":"true"}` || args[7] != int64(100) {
+		t.Errorf("admin action args = %v, want SetIsHalted(true) at height 100", args)
+	}
+}
+
+func TestIndexBridgeContract(t *testing.T) {
+	i := &Indexer{logger: zap.NewNop(), repos: repository.NewRepositories(nil)}
+
+	var batch pgx.Batch
+	i.indexEmbeddedContracts(context.Background(), &batch, contractReceive(models.BridgeAddress, 75),
+		&models.TxData{Method: "WrapToken", Inputs: map[string]string{"chainId": "1"}}, testMomentum())
+	if batch.Len() != 1 {
+		t.Fatalf("WrapToken queued %d statements, want the event insert", batch.Len())
+	}
+	args := batch.QueuedQueries[0].Arguments
+	if args[0] != testHashA || args[1] != testHashB || args[2] != "WrapToken" || args[3] != testUser ||
+		args[6] != `{"chainId":"1"}` || args[7] != int64(100) {
+		t.Errorf("insert args = %v, want receive %s, send %s, WrapToken by %s at 100", args, testHashA, testHashB, testUser)
+	}
+}
+
+func TestIndexSentinelContract(t *testing.T) {
+	i := &Indexer{logger: zap.NewNop(), repos: repository.NewRepositories(nil)}
+	ctx := context.Background()
+	numericArg := func(v any) int64 { return v.(pgtype.Numeric).Int.Int64() }
+
+	// An accepted Register activates the sentinel and records the ZNN
+	// sent and the QSR taken from the deposit.
+	var batch pgx.Batch
+	i.indexEmbeddedContracts(ctx, &batch, contractReceive(models.SentinelAddress, 5000),
+		&models.TxData{Method: "Register", Inputs: map[string]string{}}, testMomentum())
+	if batch.Len() != 2 {
+		t.Fatalf("Register queued %d statements, want the sentinel upsert and the event", batch.Len())
+	}
+	if args := batch.QueuedQueries[0].Arguments; args[0] != testUser || args[1] != int64(1700000000) {
+		t.Errorf("register args = %v, want %s at 1700000000", args, testUser)
+	}
+	args := batch.QueuedQueries[1].Arguments
+	if args[0] != testHashA || args[1] != testHashB || args[2] != testUser || args[3] != "Register" ||
+		numericArg(args[4]) != 5000 || numericArg(args[5]) != embedded.SentinelRegisterQsrAmount.Int64() {
+		t.Errorf("event args = %v, want Register by %s with 5000 ZNN and the QSR deposit", args, testUser)
+	}
+
+	// A refunded Register was rejected.
+	refunded := contractReceive(models.SentinelAddress, 5000)
+	refunded.DescendantBlocks = []*nom.AccountBlock{{}}
+	batch = pgx.Batch{}
+	i.indexEmbeddedContracts(ctx, &batch, refunded,
+		&models.TxData{Method: "Register", Inputs: map[string]string{}}, testMomentum())
+	if batch.Len() != 0 {
+		t.Errorf("refunded Register queued %d statements, want none", batch.Len())
+	}
+
+	// A Revoke only took effect if it paid the collateral back.
+	batch = pgx.Batch{}
+	i.indexEmbeddedContracts(ctx, &batch, contractReceive(models.SentinelAddress, 0),
+		&models.TxData{Method: "Revoke", Inputs: map[string]string{}}, testMomentum())
+	if batch.Len() != 0 {
+		t.Errorf("unpaid Revoke queued %d statements, want none", batch.Len())
+	}
+	revoked := contractReceive(models.SentinelAddress, 0)
+	revoked.DescendantBlocks = []*nom.AccountBlock{
+		{Amount: big.NewInt(5000), TokenStandard: types.ZnnTokenStandard},
+		{Amount: big.NewInt(50000), TokenStandard: types.QsrTokenStandard},
+	}
+	i.indexEmbeddedContracts(ctx, &batch, revoked,
+		&models.TxData{Method: "Revoke", Inputs: map[string]string{}}, testMomentum())
+	if batch.Len() != 2 {
+		t.Fatalf("Revoke queued %d statements, want the deactivation and the event", batch.Len())
+	}
+	if args := batch.QueuedQueries[1].Arguments; args[3] != "Revoke" || numericArg(args[4]) != 5000 || numericArg(args[5]) != 50000 {
+		t.Errorf("revoke event args = %v, want 5000 ZNN and 50000 QSR returned", args)
+	}
+
+	// CollectReward amounts come from the Mint calls it makes.
+	mint, err := embedded.Token.EncodeFunction("Mint", []interface{}{types.QsrTokenStandard, big.NewInt(42), types.ParseAddressPanic(testUser)})
+	if err != nil {
+		t.Fatalf("encode mint: %v", err)
+	}
+	collected := contractReceive(models.SentinelAddress, 0)
+	collected.DescendantBlocks = []*nom.AccountBlock{{TokenStandard: types.ZnnTokenStandard, Amount: big.NewInt(0), Data: mint}}
+	batch = pgx.Batch{}
+	i.indexEmbeddedContracts(ctx, &batch, collected,
+		&models.TxData{Method: "CollectReward", Inputs: map[string]string{}}, testMomentum())
+	if batch.Len() != 1 {
+		t.Fatalf("CollectReward queued %d statements, want the event", batch.Len())
+	}
+	if args := batch.QueuedQueries[0].Arguments; numericArg(args[4]) != 0 || numericArg(args[5]) != 42 {
+		t.Errorf("reward event args = %v, want 42 QSR", args)
+	}
+}
+
+func TestIndexPillarContract_NameHistory(t *testing.T) {
+	i := &Indexer{logger: zap.NewNop(), repos: repository.NewRepositories(nil)}
+	ctx := context.Background()
+	at := func(height uint64) *api.Momentum {
+		return &api.Momentum{Momentum: &nom.Momentum{Height: height, TimestampUnix: 1700000000 + height}}
+	}
+	pillarTx := func(method string) *models.TxData {
+		return &models.TxData{Method: method, Inputs: map[string]string{
+			"name": "p1", "producerAddress": "z1qproducer", "rewardAddress": "z1qreward",
+		}}
+	}
+
+	// A refunded Register was rejected and leaves no trace.
+	refunded := contractReceive(models.PillarAddress, 15000)
+	refunded.DescendantBlocks = []*nom.AccountBlock{{ToAddress: types.ParseAddressPanic(testUser)}}
+	var batch pgx.Batch
+	i.indexEmbeddedContracts(ctx, &batch, refunded, pillarTx("Register"), at(100))
+	if batch.Len() != 0 || i.getPillarOwnerAddress("p1", 100) != "" {
+		t.Fatalf("refunded Register queued %d statements, want none", batch.Len())
+	}
+
+	// An accepted Register burns the slot cost.
+	registered := contractReceive(models.PillarAddress, 15000)
+	registered.DescendantBlocks = []*nom.AccountBlock{{
+		ToAddress: types.ParseAddressPanic(models.TokenAddress), Amount: big.NewInt(150000),
+	}}
+	i.indexEmbeddedContracts(ctx, &batch, registered, pillarTx("Register"), at(100))
+	if batch.Len() != 2 {
+		t.Fatalf("Register queued %d statements, want the pillar update and the spawn info", batch.Len())
+	}
+	if args := batch.QueuedQueries[0].Arguments; args[0] != testHashA || args[1] != "Register" || args[3] != testUser {
+		t.Errorf("pillar update args = %v, want Register by %s", args, testUser)
+	}
+	if got := i.getPillarOwnerAddress("p1", 99); got != "" {
+		t.Errorf("owner before the registration = %q, want empty", got)
+	}
+
+	// A delegation resolves the owner at its own height.
+	batch = pgx.Batch{}
+	i.indexEmbeddedContracts(ctx, &batch, contractReceive(models.PillarAddress, 0), pillarTx("Delegate"), at(150))
+	if batch.Len() != 3 || batch.QueuedQueries[0].Arguments[4] != testUser {
+		t.Fatalf("Delegate queued %d statements, want the delegation to %s", batch.Len(), testUser)
+	}
+
+	// A Revoke without the stake returned was rejected.
+	batch = pgx.Batch{}
+	i.indexEmbeddedContracts(ctx, &batch, contractReceive(models.PillarAddress, 0), pillarTx("Revoke"), at(200))
+	if batch.Len() != 0 {
+		t.Fatalf("rejected Revoke queued %d statements, want none", batch.Len())
+	}
+	revoked := contractReceive(models.PillarAddress, 0)
+	revoked.DescendantBlocks = []*nom.AccountBlock{{ToAddress: types.ParseAddressPanic(testUser)}}
+	i.indexEmbeddedContracts(ctx, &batch, revoked, pillarTx("Revoke"), at(200))
+	if batch.Len() != 2 {
+		t.Fatalf("Revoke queued %d statements, want the revocation and the pillar update", batch.Len())
+	}
+	if args := batch.QueuedQueries[1].Arguments; args[1] != "Revoke" || args[2] != "p1" || args[3] != testUser {
+		t.Errorf("revoke row args = %v, want Revoke of p1 by %s", args, testUser)
+	}
+
+	// After the revoke the name resolves to no one: delegations, updates
+	// and votes by name no longer reach the old owner.
+	batch = pgx.Batch{}
+	i.indexEmbeddedContracts(ctx, &batch, contractReceive(models.PillarAddress, 0), pillarTx("Delegate"), at(201))
+	i.indexEmbeddedContracts(ctx, &batch, contractReceive(models.PillarAddress, 0), pillarTx("UpdatePillar"), at(201))
+	if batch.Len() != 0 {
+		t.Errorf("calls after the revoke queued %d statements, want none", batch.Len())
+	}
+	i.indexEmbeddedContracts(ctx, &batch, contractReceive(models.PillarAddress, 0), pillarTx("UpdatePillar"), at(180))
+	if batch.Len() != 1 || batch.QueuedQueries[0].Arguments[1] != "UpdatePillar" {
+		t.Errorf("UpdatePillar while active queued %d statements, want the pillar update", batch.Len())
+	}
+}
+
+func TestIndexTokenContract_ControlEvents(t *testing.T) {
+	i := &Indexer{logger: zap.NewNop(), repos: repository.NewRepositories(nil)}
+	ctx := context.Background()
+	const newZTS = "zts1qanamzukd2v0pp8j2wzx6m"
+	issueInputs := map[string]string{"tokenSymbol": "TEST", "isMintable": "true", "isBurnable": "false"}
+
+	// A rejected issue only refunds the ZNN fee.
+	refunded := contractReceive(models.TokenAddress, 1000000000)
+	refunded.DescendantBlocks = []*nom.AccountBlock{{Amount: big.NewInt(1000000000), TokenStandard: types.ZnnTokenStandard}}
+	var batch pgx.Batch
+	i.indexEmbeddedContracts(ctx, &batch, refunded,
+		&models.TxData{Method: "IssueToken", Inputs: issueInputs}, testMomentum())
+	if batch.Len() != 0 {
+		t.Errorf("refunded IssueToken queued %d statements, want none", batch.Len())
+	}
+
+	// An accepted issue sends the supply of the new token to the issuer.
+	issued := contractReceive(models.TokenAddress, 1000000000)
+	issued.Height = 7
+	issued.DescendantBlocks = []*nom.AccountBlock{{Amount: big.NewInt(100), TokenStandard: types.ParseZTSPanic(newZTS)}}
+	i.indexEmbeddedContracts(ctx, &batch, issued,
+		&models.TxData{Method: "IssueToken", Inputs: issueInputs}, testMomentum())
+	if batch.Len() != 1 {
+		t.Fatalf("IssueToken queued %d statements, want the event", batch.Len())
+	}
+	args := batch.QueuedQueries[0].Arguments
+	if args[0] != testHashA || args[1] != testHashB || args[2] != newZTS || args[3] != testUser ||
+		args[4] != int64(7) || args[5] != testUser || args[6] != true || args[7] != false {
+		t.Errorf("issue args = %v, want %s issued and owned by %s, mintable, not burnable", args, newZTS, testUser)
+	}
+
+	// UpdateToken keeps bumping the timestamp and queues the checked update.
+	batch = pgx.Batch{}
+	i.indexEmbeddedContracts(ctx, &batch, contractReceive(models.TokenAddress, 0),
+		&models.TxData{Method: "UpdateToken", Inputs: map[string]string{
+			"tokenStandard": newZTS, "owner": models.PillarAddress, "isMintable": "false", "isBurnable": "true",
+		}}, testMomentum())
+	if batch.Len() != 2 {
+		t.Fatalf("UpdateToken queued %d statements, want the timestamp and the event", batch.Len())
+	}
+	args = batch.QueuedQueries[1].Arguments
+	if args[2] != newZTS || args[3] != testUser || args[5] != models.PillarAddress || args[6] != false || args[7] != true {
+		t.Errorf("update args = %v, want %s handed to %s, not mintable, burnable", args, newZTS, models.PillarAddress)
+	}
+}
+
+func TestIndexAcceleratorContract_History(t *testing.T) {
+	i := &Indexer{logger: zap.NewNop(), repos: repository.NewRepositories(nil)}
+	ctx := context.Background()
+	createInputs := map[string]string{"name": "p", "znnFundsNeeded": "500", "qsrFundsNeeded": "5000"}
+
+	// A rejected create refunds the fee.
+	refunded := contractReceive(models.AcceleratorAddress, 100)
+	refunded.DescendantBlocks = []*nom.AccountBlock{{Amount: big.NewInt(100), TokenStandard: types.ZnnTokenStandard}}
+	var batch pgx.Batch
+	i.indexEmbeddedContracts(ctx, &batch, refunded,
+		&models.TxData{Method: "CreateProject", Inputs: createInputs}, testMomentum())
+	if batch.Len() != 0 {
+		t.Errorf("refunded CreateProject queued %d statements, want none", batch.Len())
+	}
+
+	i.indexEmbeddedContracts(ctx, &batch, contractReceive(models.AcceleratorAddress, 100),
+		&models.TxData{Method: "CreateProject", Inputs: createInputs}, testMomentum())
+	if batch.Len() != 1 {
+		t.Fatalf("CreateProject queued %d statements, want the creation row", batch.Len())
+	}
+	args := batch.QueuedQueries[0].Arguments
+	if args[1] != testHashB || args[2] != testHashB || args[3] != testUser ||
+		args[5].(pgtype.Numeric).Int.Int64() != 500 || args[6].(pgtype.Numeric).Int.Int64() != 5000 {
+		t.Errorf("create args = %v, want project %s by %s asking 500 ZNN, 5000 QSR", args, testHashB, testUser)
+	}
+
+	// AddPhase: the phase id is the send hash, the project id its input.
+	batch = pgx.Batch{}
+	i.indexEmbeddedContracts(ctx, &batch, contractReceive(models.AcceleratorAddress, 0),
+		&models.TxData{Method: "AddPhase", Inputs: map[string]string{
+			"id": testHashA, "znnFundsNeeded": "200", "qsrFundsNeeded": "2000",
+		}}, testMomentum())
+	if batch.Len() != 1 {
+		t.Fatalf("AddPhase queued %d statements, want one", batch.Len())
+	}
+	args = batch.QueuedQueries[0].Arguments
+	if args[1] != testHashA || args[2] != testHashB || args[4] != "AddPhase" {
+		t.Errorf("phase args = %v, want phase %s of project %s", args, testHashB, testHashA)
+	}
+
+	// Update settles projects and pays a phase with a ZNN and a QSR send.
+	phase := types.HexToHashPanic(testHashB)
+	update := contractReceive(models.AcceleratorAddress, 0)
+	update.DescendantBlocks = []*nom.AccountBlock{
+		{Hash: types.HexToHashPanic(testHashA), ToAddress: types.ParseAddressPanic(testUser),
+			Amount: big.NewInt(200), TokenStandard: types.ZnnTokenStandard, Data: phase.Bytes()},
+		{Hash: types.HexToHashPanic(testHashB), ToAddress: types.ParseAddressPanic(testUser),
+			Amount: big.NewInt(2000), TokenStandard: types.QsrTokenStandard, Data: phase.Bytes()},
+	}
+	batch = pgx.Batch{}
+	i.indexEmbeddedContracts(ctx, &batch, update, &models.TxData{Method: "Update"}, testMomentum())
+	// Settle, two payouts, then the paid phase and the completion check.
+	if batch.Len() != 5 {
+		t.Fatalf("Update queued %d statements, want 5", batch.Len())
+	}
+	args = batch.QueuedQueries[3].Arguments
+	if args[1] != testHashB || args[5].(pgtype.Numeric).Int.Int64() != 200 || args[6].(pgtype.Numeric).Int.Int64() != 2000 {
+		t.Errorf("paid phase args = %v, want %s paid 200 ZNN, 2000 QSR", args, testHashB)
+	}
+}
@@ -0,0 +1,205 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/0x3639/nom-indexer-go/internal/indexer/metrics"
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+// failedHeightsBatch caps how many due heights one retrier pass takes.
+const failedHeightsBatch = 100
+
+// FailedHeightsConfig tunes the queue of heights the indexer failed to
+// process (indexer_failed_heights). Backfill adds to it; the retrier
+// drains it. The zero value leaves the retrier off, but Backoff and
+// MaxBackoff still schedule the heights backfill records.
+type FailedHeightsConfig struct {
+	// Enabled runs the retrier alongside live sync.
+	Enabled bool
+	// Interval is how often the retrier looks for due heights. Default 1m.
+	Interval time.Duration
+	// Backoff is the delay before a height's first retry; it doubles
+	// with every failure. Default 1m.
+	Backoff time.Duration
+	// MaxBackoff caps the delay. Default 1h.
+	MaxBackoff time.Duration
+}
+
+// withDefaults fills non-positive durations with the documented defaults.
+func (c FailedHeightsConfig) withDefaults() FailedHeightsConfig {
+	if c.Interval <= 0 {
+		c.Interval = time.Minute
+	}
+	if c.Backoff <= 0 {
+		c.Backoff = time.Minute
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = time.Hour
+	}
+	return c
+}
+
+// ConfigureFailedHeights enables or tunes the failed-height retrier.
+// Call before Run.
+func (i *Indexer) ConfigureFailedHeights(cfg FailedHeightsConfig) {
+	i.failedHeightsCfg = cfg
+}
+
+// failedHeightStore is the part of FailedHeightRepository the retrier
+// uses, so tests can run it against memory.
+type failedHeightStore interface {
+	Record(ctx context.Context, height uint64, errMsg string, now, backoff, maxBackoff int64) error
+	Due(ctx context.Context, now int64, limit int) ([]*models.FailedHeight, error)
+	Resolve(ctx context.Context, height uint64) error
+	Count(ctx context.Context) (int64, error)
+}
+
+// failedHeightRetrier re-processes queued heights. Like catchUpPipeline,
+// its node and database access is injected.
+type failedHeightRetrier struct {
+	cfg     FailedHeightsConfig
+	logger  *zap.Logger
+	metrics *metrics.Metrics
+	store   failedHeightStore
+	now     func() time.Time
+
+	// pending reports whether a queued height still needs processing:
+	// it is at or below the indexed head, and missing or incomplete
+	// unless the verifier queued it for a reprocess. A height that no
+	// longer does was filled by another path, or will be reached by
+	// live sync, and is dropped from the queue.
+	pending func(ctx context.Context, f *models.FailedHeight) (bool, error)
+	// process fetches and commits one height.
+	process func(ctx context.Context, height uint64) error
+}
+
+// record queues height after a failed attempt.
+func (r *failedHeightRetrier) record(ctx context.Context, height uint64, cause error) {
+	cfg := r.cfg.withDefaults()
+	err := r.store.Record(ctx, height, cause.Error(), r.now().Unix(),
+		int64(cfg.Backoff/time.Second), int64(cfg.MaxBackoff/time.Second))
+	if err != nil {
+		r.logger.Error("failed to queue failed height", zap.Uint64("height", height), zap.Error(err))
+	}
+}
+
+// retryDue makes one attempt at every due height, lowest first, and
+// returns how many it resolved and how many failed again.
+func (r *failedHeightRetrier) retryDue(ctx context.Context) (resolved, failed int) {
+	due, err := r.store.Due(ctx, r.now().Unix(), failedHeightsBatch)
+	if err != nil {
+		r.logger.Warn("failed heights: listing due heights failed", zap.Error(err))
+		return 0, 0
+	}
+	for _, f := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := r.retry(ctx, f); err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			r.logger.Warn("failed heights: retry failed",
+				zap.Uint64("height", f.Height),
+				zap.Int("attempts", f.Attempts+1),
+				zap.Error(err))
+			r.record(ctx, f.Height, err)
+			r.metrics.ObserveFailedHeightRetry(false)
+			failed++
+			continue
+		}
+		r.logger.Info("failed heights: height resolved",
+			zap.Uint64("height", f.Height), zap.Int("attempts", f.Attempts))
+		r.metrics.ObserveFailedHeightRetry(true)
+		resolved++
+	}
+	r.refreshGauge(ctx)
+	return resolved, failed
+}
+
+// retry processes a queued height if it still needs it, then drops it
+// from the queue.
+func (r *failedHeightRetrier) retry(ctx context.Context, f *models.FailedHeight) error {
+	pending, err := r.pending(ctx, f)
+	if err != nil {
+		return err
+	}
+	if pending {
+		if err := r.process(ctx, f.Height); err != nil {
+			return err
+		}
+	}
+	return r.store.Resolve(ctx, f.Height)
+}
+
+// refreshGauge publishes the outstanding count.
+func (r *failedHeightRetrier) refreshGauge(ctx context.Context) {
+	n, err := r.store.Count(ctx)
+	if err != nil {
+		r.logger.Warn("failed heights: count failed", zap.Error(err))
+		return
+	}
+	r.metrics.SetFailedHeights(n)
+}
+
+// failedHeights builds the retrier over the indexer's database and
+// active node.
+func (i *Indexer) failedHeights() *failedHeightRetrier {
+	return &failedHeightRetrier{
+		cfg:     i.failedHeightsCfg,
+		logger:  i.logger,
+		metrics: i.metrics,
+		store:   i.repos.FailedHeight,
+		now:     time.Now,
+		pending: func(ctx context.Context, f *models.FailedHeight) (bool, error) {
+			latest, err := i.repos.Momentum.GetLatestHeight(ctx)
+			if err != nil {
+				return false, err
+			}
+			if f.Height > latest {
+				return false, nil
+			}
+			if f.Reprocess {
+				return true, nil
+			}
+			gaps, err := i.repos.Momentum.GapsInRange(ctx, f.Height, f.Height)
+			return len(gaps) > 0, err
+		},
+		process: func(ctx context.Context, height uint64) error {
+			list, err := i.fetchMomentumPage(ctx, height, 1, false)
+			if err != nil {
+				return err
+			}
+			if len(list) == 0 {
+				return fmt.Errorf("node has no momentum at height %d", height)
+			}
+			return i.processMomentum(ctx, list[0])
+		},
+	}
+}
+
+// runFailedHeightsLoop retries due failed heights every interval until
+// ctx is canceled.
+func (i *Indexer) runFailedHeightsLoop(ctx context.Context) {
+	r := i.failedHeights()
+	interval := r.cfg.withDefaults().Interval
+	i.logger.Info("starting failed-height retrier", zap.Duration("interval", interval))
+	r.refreshGauge(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			i.logger.Info("failed-height retrier stopped")
+			return
+		case <-ticker.C:
+			r.retryDue(ctx)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+// memFailedHeights is an in-memory failedHeightStore with the same
+// backoff arithmetic as FailedHeightRepository.Record.
+type memFailedHeights struct {
+	mu   sync.Mutex
+	rows map[uint64]*models.FailedHeight
+}
+
+func (m *memFailedHeights) Record(_ context.Context, height uint64, errMsg string, now, backoff, maxBackoff int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.rows == nil {
+		m.rows = map[uint64]*models.FailedHeight{}
+	}
+	f, ok := m.rows[height]
+	if !ok {
+		f = &models.FailedHeight{Height: height, FirstFailedAt: now}
+		m.rows[height] = f
+	}
+	delay := backoff << min(f.Attempts, 30)
+	f.Attempts++
+	f.LastError, f.LastFailedAt, f.NextRetryAt = errMsg, now, now+min(delay, maxBackoff)
+	return nil
+}
+
+func (m *memFailedHeights) Due(_ context.Context, now int64, limit int) ([]*models.FailedHeight, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []*models.FailedHeight
+	for _, f := range m.rows {
+		if f.NextRetryAt <= now {
+			c := *f
+			out = append(out, &c)
+		}
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].Height < out[b].Height })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (m *memFailedHeights) Resolve(_ context.Context, height uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.rows, height)
+	return nil
+}
+
+func (m *memFailedHeights) Count(context.Context) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return int64(len(m.rows)), nil
+}
+
+func (m *memFailedHeights) get(height uint64) *models.FailedHeight {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.rows[height]
+}
+
+func TestFailedHeightRetrier_RetryDue(t *testing.T) {
+	now := time.Unix(1000, 0)
+	store := &memFailedHeights{}
+	var processed []uint64
+	r := &failedHeightRetrier{
+		cfg:    FailedHeightsConfig{Backoff: time.Minute, MaxBackoff: 3 * time.Minute},
+		logger: zap.NewNop(),
+		store:  store,
+		now:    func() time.Time { return now },
+		// Height 20 was filled by another path in the meantime.
+		pending: func(_ context.Context, f *models.FailedHeight) (bool, error) { return f.Height != 20, nil },
+		process: func(_ context.Context, h uint64) error {
+			processed = append(processed, h)
+			if h == 30 {
+				return errors.New("node timeout")
+			}
+			return nil
+		},
+	}
+	ctx := context.Background()
+	for _, h := range []uint64{30, 10, 20} {
+		r.record(ctx, h, errors.New("boom"))
+	}
+	if f := store.get(10); f.Attempts != 1 || f.NextRetryAt != 1060 || f.LastError != "boom" {
+		t.Fatalf("recorded row = %+v", f)
+	}
+
+	// Nothing is due before the first backoff.
+	if resolved, failed := r.retryDue(ctx); resolved+failed != 0 || len(processed) != 0 {
+		t.Fatalf("early pass: resolved=%d failed=%d processed=%v", resolved, failed, processed)
+	}
+
+	now = now.Add(time.Minute)
+	resolved, failed := r.retryDue(ctx)
+	if resolved != 2 || failed != 1 {
+		t.Errorf("resolved=%d failed=%d, want 2 and 1", resolved, failed)
+	}
+	// Lowest height first; the already-filled height is not processed.
+	if len(processed) != 2 || processed[0] != 10 || processed[1] != 30 {
+		t.Errorf("processed %v, want [10 30]", processed)
+	}
+	f := store.get(30)
+	if store.get(10) != nil || store.get(20) != nil || f == nil {
+		t.Fatalf("after pass: rows %v", store.rows)
+	}
+	// The second failure doubles the delay; the third hits the cap.
+	if f.Attempts != 2 || f.NextRetryAt != now.Unix()+120 || f.LastError != "node timeout" {
+		t.Errorf("re-failed row = %+v", f)
+	}
+	now = now.Add(2 * time.Minute)
+	r.retryDue(ctx)
+	if f = store.get(30); f.Attempts != 3 || f.NextRetryAt != now.Unix()+180 {
+		t.Errorf("capped row = %+v", f)
+	}
+}
+
+func TestFailedHeightRetrier_PendingErrorCountsAsFailure(t *testing.T) {
+	store := &memFailedHeights{}
+	r := &failedHeightRetrier{
+		logger:  zap.NewNop(),
+		store:   store,
+		now:     func() time.Time { return time.Unix(0, 0) },
+		pending: func(context.Context, *models.FailedHeight) (bool, error) { return false, errors.New("db down") },
+		process: func(context.Context, uint64) error { t.Fatal("processed despite pending error"); return nil },
+	}
+	ctx := context.Background()
+	_ = store.Record(ctx, 5, "boom", -3600, 60, 3600)
+	if resolved, failed := r.retryDue(ctx); resolved != 0 || failed != 1 {
+		t.Errorf("resolved=%d failed=%d, want 0 and 1", resolved, failed)
+	}
+	if f := store.get(5); f == nil || f.LastError != "db down" {
+		t.Errorf("row = %+v, want kept with the new error", f)
+	}
+}
@@ -2,18 +2,22 @@ package indexer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/0x3639/znn-sdk-go/abi"
 	"github.com/0x3639/znn-sdk-go/embedded"
 	"github.com/0x3639/znn-sdk-go/rpc_client"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/zenon-network/go-zenon/common/types"
 	"github.com/zenon-network/go-zenon/rpc/api"
 	"go.uber.org/zap"
 
+	"github.com/0x3639/nom-indexer-go/internal/indexer/metrics"
 	"github.com/0x3639/nom-indexer-go/internal/models"
 	"github.com/0x3639/nom-indexer-go/internal/repository"
 	"github.com/0x3639/nom-indexer-go/internal/webhooks"
@@ -31,8 +35,8 @@ type Indexer struct {
 	pillars  []*models.Pillar
 	pillarMu sync.RWMutex
 
-	// Pillar name to owner address mapping
-	pillarNameToOwner map[string]string
+	// Pillar name to owner address, resolved at a momentum height
+	pillarNames PillarNames
 
 	// Channel to signal subscription restart needed (triggered by SDK reconnection callback)
 	restartSubCh chan struct{}
@@ -49,12 +53,39 @@ type Indexer struct {
 
 	watchdogCfg WatchdogConfigForIndexer
 
-	// webhooks dispatches momentum.inserted / account_block.inserted
-	// events to configured subscribers AFTER each per-momentum transaction
-	// commits. nil when webhooks are disabled (the default), in which case
-	// the emit path is a single nil check with zero further work.
+	// webhooks routes momentum.inserted / account_block.inserted / reorg
+	// events into the webhook_outbox inside each per-momentum transaction
+	// and delivers them from there. nil when webhooks are disabled (the
+	// default), in which case the event path is a single nil check with
+	// zero further work.
 	webhooks *webhooks.Dispatcher
 
+	// catchUpCfg selects and tunes the pipelined catch-up in sync(). The
+	// zero value keeps the serial fetch-then-commit loop.
+	catchUpCfg CatchUpConfig
+
+	// failedHeightsCfg tunes the queue of heights backfill could not
+	// process and the retrier that drains it. The zero value leaves the
+	// retrier off.
+	failedHeightsCfg FailedHeightsConfig
+
+	// verifyCfg schedules the periodic comparison of indexed data with
+	// the node. The zero value leaves it off.
+	verifyCfg VerifyConfig
+
+	// reconcileCfg schedules the periodic correction of balances from
+	// the node. The zero value leaves it off.
+	reconcileCfg ReconcileConfig
+
+	// reads spreads catch-up ledger fetches across every healthy node in
+	// nodePool. nil unless catch-up fan-out is configured with more than
+	// one node and the watchdog enabled (the watchdog decides membership).
+	reads *readRotation
+
+	// metrics records catch-up throughput. nil (the default) is safe:
+	// every *metrics.Metrics method no-ops on a nil receiver.
+	metrics *metrics.Metrics
+
 	// clientFactory builds a fresh SDK client for a given URL. nil means
 	// "use rpc_client.NewRpcClient" (production). Integration tests
 	// override this to bypass the SDK's real WebSocket dial, which would
@@ -80,12 +111,11 @@ func NewIndexer(client *rpc_client.RpcClient, pool *pgxpool.Pool, logger *zap.Lo
 // Zero durations fall back to defaults inside the cron loop.
 func NewIndexerWithCron(client *rpc_client.RpcClient, pool *pgxpool.Pool, logger *zap.Logger, cron CronConfig) *Indexer {
 	i := &Indexer{
-		pool:              pool,
-		repos:             repository.NewRepositories(pool),
-		logger:            logger,
-		cron:              cron,
-		pillarNameToOwner: make(map[string]string),
-		restartSubCh:      make(chan struct{}, 1),
+		pool:         pool,
+		repos:        repository.NewRepositories(pool),
+		logger:       logger,
+		cron:         cron,
+		restartSubCh: make(chan struct{}, 1),
 	}
 	i.activeClient.Store(client)
 	return i
@@ -110,12 +140,14 @@ func NewIndexerWithNodes(
 }
 
 // AttachWebhooks builds and starts a webhook dispatcher for the given
-// endpoints and stores it on the indexer. The dispatcher is stopped in
-// Run's teardown. Pass an empty endpoints slice or never call this to
-// leave webhooks disabled (the default). Callers own the config→Endpoint
-// mapping so internal/indexer stays decoupled from internal/config,
-// mirroring the toIndexerNodes pattern in cmd/indexer.
-func (i *Indexer) AttachWebhooks(endpoints []webhooks.Endpoint, timeout time.Duration, maxRetries int) {
+// endpoints plus every subscription registered through the API, backed
+// by the webhook_outbox table, and stores it on the indexer. The dispatcher is stopped in Run's teardown. Pass an empty
+// endpoints slice or never call this to leave webhooks disabled (the
+// default). Callers own the config→Endpoint mapping so internal/indexer
+// stays decoupled from internal/config, mirroring the toIndexerNodes
+// pattern in cmd/indexer. Call AttachMetrics first for per-endpoint
+// delivery metrics.
+func (i *Indexer) AttachWebhooks(endpoints []webhooks.Endpoint, cfg webhooks.Config) {
 	// Defensively idempotent: a second call would overwrite i.webhooks,
 	// orphaning the already-started dispatcher (goroutine leak). Mirror the
 	// dispatcher's own hardened idempotent API and no-op instead.
@@ -123,11 +155,36 @@ func (i *Indexer) AttachWebhooks(endpoints []webhooks.Endpoint, timeout time.Dur
 		i.logger.Warn("AttachWebhooks called more than once; ignoring duplicate, keeping existing dispatcher")
 		return
 	}
-	d := webhooks.New(endpoints, timeout, maxRetries, i.logger)
+	if i.metrics != nil && cfg.Observer == nil {
+		cfg.Observer = i.metrics
+	}
+	d := webhooks.New(endpoints, i.repos.WebhookOutbox, i.repos.WebhookSubscription, cfg, i.logger)
 	d.Start()
 	i.webhooks = d
 }
 
+// ConfigureCatchUp enables or tunes the pipelined catch-up sync. Call
+// before Run; the setting is read at the start of every sync pass.
+// FanOut only takes effect on an indexer built with NewIndexerWithNodes
+// that has more than one node and the watchdog enabled.
+func (i *Indexer) ConfigureCatchUp(cfg CatchUpConfig) {
+	i.catchUpCfg = cfg
+	i.reads = nil
+	if cfg.Enabled && cfg.FanOut && i.nodePool != nil && i.nodePool.Len() > 1 && i.watchdogCfg.Enabled {
+		labels := make([]string, i.nodePool.Len())
+		for idx := range labels {
+			labels[idx] = i.nodePool.Entry(idx).Label
+		}
+		i.reads = newReadRotation(labels)
+	}
+}
+
+// AttachMetrics stores the Prometheus collectors the indexer records
+// catch-up throughput into. Serving them is the caller's job.
+func (i *Indexer) AttachMetrics(m *metrics.Metrics) {
+	i.metrics = m
+}
+
 // client returns the currently-active SDK client. All RPC call sites
 // must read through this accessor — the underlying value changes when
 // the watchdog fails over to a different node.
@@ -244,11 +301,15 @@ func (i *Indexer) Run(ctx context.Context) error {
 	i.logger.Info("starting indexer")
 
 	// Stop the webhook dispatcher on shutdown. Stop is idempotent and
-	// safe even if an Emit races with it. Deferred here so it runs once
+	// safe even if a Notify races with it. Deferred here so it runs once
 	// on every Run return path (ctx cancel, sync error, subscription end).
 	if i.webhooks != nil {
 		defer i.webhooks.Stop()
 	}
+	// Close the read rotation's per-node connections on the same paths.
+	if i.reads != nil {
+		defer i.reads.close()
+	}
 
 	// Register SDK callbacks for connection events on the initial client.
 	// The SDK handles reconnection automatically; we just need to restart
@@ -296,6 +357,27 @@ func (i *Indexer) Run(ctx context.Context) error {
 			i.runSyncWatchdogLoop(runCtx)
 		}()
 	}
+	if i.failedHeightsCfg.Enabled {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			i.runFailedHeightsLoop(runCtx)
+		}()
+	}
+	if i.verifyCfg.Enabled {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			i.runVerifyLoop(runCtx)
+		}()
+	}
+	if i.reconcileCfg.Enabled {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			i.runReconcileLoop(runCtx)
+		}()
+	}
 	// defer is LIFO: register wg.Wait() first (runs last) and cancel second
 	// (runs first) so the loops are canceled before we wait for them to exit.
 	defer wg.Wait()
@@ -315,9 +397,9 @@ func (i *Indexer) Run(ctx context.Context) error {
 // sync performs the catch-up sync from last indexed height.
 //
 // The pillar cache is primed synchronously first, and fails CLOSED: catch-up
-// does not begin until pillarNameToOwner is populated. Account-block handlers
+// does not begin until pillarNames is populated. Account-block handlers
 // (delegation, pillar updates, VoteByName, ABI enrichment) resolve owners via
-// that map, and on a fresh process it starts empty — there is no safe stale
+// that lookup, and on a fresh process it starts empty — there is no safe stale
 // fallback, so indexing against an empty map would silently drop delegation
 // rows and write fallback voter addresses. A transient pillar RPC failure is
 // retried; if it still cannot populate, sync returns an error rather than
@@ -381,11 +463,35 @@ func (i *Indexer) sync(ctx context.Context) error {
 			startHeight = dbHeight + 1
 		}
 
+		// Pipelined mode: prefetch ahead of a single ordered committer up
+		// to the frontier read above, then loop to re-read both heights.
+		if i.catchUpCfg.Enabled {
+			committed, err := i.catchUp(ctx, startHeight, frontierHeight)
+			if err != nil {
+				var rerr *reorgError
+				if errors.As(err, &rerr) {
+					if herr := i.handleReorg(ctx, rerr); herr != nil {
+						return fmt.Errorf("failed to roll back reorg at height %d: %w", rerr.height, herr)
+					}
+					continue
+				}
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				return fmt.Errorf("pipelined catch-up from height %d: %w", startHeight, err)
+			}
+			if committed == 0 {
+				// The node reported a higher frontier than it would
+				// serve; give it a moment, as the serial path does.
+				time.Sleep(time.Second)
+			}
+			continue
+		}
+
 		// Fetch and process momentums in batches
-		batchSize := uint64(100)
 		var momentums *api.MomentumList
 		if err := withRetry(ctx, i.logger, "GetMomentumsByHeight", func() error {
-			m, err := i.client().LedgerApi.GetMomentumsByHeight(startHeight, batchSize)
+			m, err := i.client().LedgerApi.GetMomentumsByHeight(startHeight, catchUpPageSize)
 			if err != nil {
 				return err
 			}
@@ -413,6 +519,15 @@ func (i *Indexer) sync(ctx context.Context) error {
 				zap.Int("txCount", len(m.Content)))
 
 			if err := i.processMomentum(ctx, m); err != nil {
+				var rerr *reorgError
+				if errors.As(err, &rerr) {
+					if herr := i.handleReorg(ctx, rerr); herr != nil {
+						return fmt.Errorf("failed to roll back reorg at height %d: %w", rerr.height, herr)
+					}
+					// The rest of this page builds on the orphaned
+					// chain's heights; re-read MAX(height) and refetch.
+					break
+				}
 				return fmt.Errorf("failed to process momentum %d: %w", m.Height, err)
 			} else {
 				i.lastProgressAt.Store(time.Now().Unix())
@@ -510,6 +625,17 @@ func (i *Indexer) runSubscriptionSession(ctx context.Context) error {
 				}
 
 				if err := i.processMomentum(ctx, fullMomentum.List[0]); err != nil {
+					// A fork switch ends the session: after the rollback
+					// the next live momentum no longer follows MAX(height),
+					// so the catch-up sync in runSubscriptionLoop must
+					// re-index from the common ancestor first.
+					var rerr *reorgError
+					if errors.As(err, &rerr) {
+						if herr := i.handleReorg(ctx, rerr); herr != nil {
+							return fmt.Errorf("failed to roll back reorg at height %d: %w", rerr.height, herr)
+						}
+						return fmt.Errorf("chain reorg at height %d, resyncing from common ancestor", rerr.height)
+					}
 					i.logger.Error("failed to process momentum",
 						zap.Uint64("height", m.Height),
 						zap.Error(err))
@@ -522,12 +648,13 @@ func (i *Indexer) runSubscriptionSession(ctx context.Context) error {
 }
 
 // updatePillarCache fetches the pillar set and publishes the in-memory pillar
-// snapshot and name->owner map.
+// snapshot, then refreshes pillarNames: the node's name->owner map as the
+// fallback, and the name history rebuilt from pillar_updates.
 //
 // It is separated from updateCachedData so it can be primed synchronously
 // before catch-up. Several account-block handlers — delegation, pillar
 // updates, VoteByName, and ABI enrichment — resolve owners through
-// pillarNameToOwner via getPillarOwnerAddress; processing momentums against an
+// pillarNames via getPillarOwnerAddress; processing momentums against an
 // empty map silently drops delegation rows, writes fallback voter addresses,
 // and skips pillarOwner enrichment. Unlike the sentinel/accelerator/swap work
 // in updateCachedData, this is a single fast call, so gating catch-up on it
@@ -571,10 +698,16 @@ func (i *Indexer) updatePillarCache(ctx context.Context) error {
 		}
 	}
 
+	history, err := i.repos.PillarUpdate.NameHistory(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load pillar name history: %w", err)
+	}
+
 	i.pillarMu.Lock()
 	i.pillars = pillars
-	i.pillarNameToOwner = nameToOwner
 	i.pillarMu.Unlock()
+	i.pillarNames.SetCurrent(nameToOwner)
+	i.pillarNames.Replace(history)
 
 	i.logger.Info("updateCachedData: pillars done", zap.Int("count", len(pillarList.List)))
 	return nil
@@ -745,10 +878,70 @@ func (i *Indexer) updateCachedData(ctx context.Context) error {
 	// Snapshot remaining legacy genesis-swap balances.
 	i.syncSwapAssets(ctx)
 
+	// Mirror the liquidity program's configuration.
+	i.syncLiquidityConfig(ctx)
+
 	i.logger.Info("updateCachedData: complete")
 	return nil
 }
 
+// syncLiquidityConfig mirrors the Liquidity contract's configuration into
+// the liquidity_config singleton from LiquidityApi.GetLiquidityInfo and
+// GetSecurityInfo. The administrator's SetTokenTuple / SetAdditionalReward
+// calls only take effect once their time challenge has passed, so the
+// node, not the call data, is the source. Failures are logged and skipped
+// so a liquidity RPC error doesn't abort the cached-data refresh; without
+// security info the row keeps its previous guardians and delays.
+func (i *Indexer) syncLiquidityConfig(ctx context.Context) {
+	info, err := i.client().LiquidityApi.GetLiquidityInfo()
+	if err != nil || info == nil {
+		i.logger.Warn("liquidity sync: GetLiquidityInfo failed", zap.Error(err))
+		return
+	}
+	cfg := &models.LiquidityConfig{
+		Administrator:        info.Administrator.String(),
+		IsHalted:             info.IsHalted,
+		ZnnReward:            info.ZnnReward,
+		QsrReward:            info.QsrReward,
+		TokenTuples:          make([]models.LiquidityTokenTuple, 0, len(info.TokenTuples)),
+		LastUpdatedTimestamp: time.Now().Unix(),
+	}
+	for _, t := range info.TokenTuples {
+		if t == nil {
+			continue
+		}
+		minAmount := "0"
+		if t.MinAmount != nil {
+			minAmount = t.MinAmount.String()
+		}
+		cfg.TokenTuples = append(cfg.TokenTuples, models.LiquidityTokenTuple{
+			TokenStandard: t.TokenStandard.String(),
+			ZnnPercentage: t.ZnnPercentage,
+			QsrPercentage: t.QsrPercentage,
+			MinAmount:     minAmount,
+		})
+	}
+	if sec, err := i.client().LiquidityApi.GetSecurityInfo(); err != nil || sec == nil {
+		i.logger.Warn("liquidity sync: GetSecurityInfo failed", zap.Error(err))
+		if prev, err := i.repos.Liquidity.GetConfig(ctx); err == nil {
+			cfg.Guardians = prev.Guardians
+			cfg.AdministratorDelay = prev.AdministratorDelay
+			cfg.SoftDelay = prev.SoftDelay
+		}
+	} else {
+		cfg.AdministratorDelay = int64(sec.AdministratorDelay)
+		cfg.SoftDelay = int64(sec.SoftDelay)
+		for _, g := range sec.Guardians {
+			cfg.Guardians = append(cfg.Guardians, g.String())
+		}
+	}
+	if err := i.repos.Liquidity.UpsertConfig(ctx, cfg); err != nil {
+		i.logger.Warn("liquidity sync: upsert config failed", zap.Error(err))
+		return
+	}
+	i.logger.Info("liquidity sync: config snapshot complete", zap.Int("tokenTuples", len(cfg.TokenTuples)))
+}
+
 // syncSwapAssets snapshots the remaining unswapped legacy genesis balances.
 //
 // SwapApi.GetAssets() returns map[types.Hash]*embedded.SwapAssetEntrySimple
@@ -1069,12 +1262,6 @@ func (i *Indexer) updateBridgeWrapRequests(ctx context.Context) error {
 		reachedStopHeight := false
 
 		for _, w := range wrapList.List {
-			amount := safeBigIntToInt64(w.Amount, i.logger,
-				"wrap amount overflow",
-				zap.String("id", w.Id.String()))
-			fee := safeBigIntToInt64(w.Fee, i.logger,
-				"wrap fee overflow",
-				zap.String("id", w.Id.String()))
 			wrapRequest := &models.WrapTokenRequest{
 				ID:                      w.Id.String(),
 				NetworkClass:            int(w.NetworkClass),
@@ -1082,13 +1269,13 @@ func (i *Indexer) updateBridgeWrapRequests(ctx context.Context) error {
 				ToAddress:               w.ToAddress,
 				TokenStandard:           w.TokenStandard.String(),
 				TokenAddress:            w.TokenAddress,
-				Amount:                  amount,
-				Fee:                     fee,
+				Amount:                  w.Amount,
+				Fee:                     w.Fee,
 				Signature:               w.Signature,
 				CreationMomentumHeight:  int64(w.CreationMomentumHeight),
 				ConfirmationsToFinality: int(w.ConfirmationsToFinality),
 			}
-			if err := i.repos.Bridge.UpsertWrapRequest(ctx, wrapRequest); err != nil {
+			if err := i.storeWrapRequest(ctx, wrapRequest, stopHeight == 0); err != nil {
 				i.logger.Warn("failed to upsert wrap request", zap.String("id", w.Id.String()), zap.Error(err))
 			}
 
@@ -1113,10 +1300,56 @@ func (i *Indexer) updateBridgeWrapRequests(ctx context.Context) error {
 		pageIndex++
 	}
 
+	if i.webhooks != nil {
+		i.webhooks.Notify()
+	}
 	i.logger.Info("bridge sync: wrap requests done", zap.Uint32("pagesProcessed", pageIndex+1), zap.Int64("stopHeight", stopHeight))
 	return nil
 }
 
+// storeWrapRequest upserts w. When webhooks are enabled and w's status
+// differs from the stored row's, a bridge.wrap.status_changed event is
+// queued in the same transaction. baseline suppresses the event: the
+// first sync into an empty table would otherwise announce every request
+// the bridge has ever seen.
+func (i *Indexer) storeWrapRequest(ctx context.Context, w *models.WrapTokenRequest, baseline bool) error {
+	if i.webhooks == nil || baseline {
+		return i.repos.Bridge.UpsertWrapRequest(ctx, w)
+	}
+	var previous string
+	prev, err := i.repos.Bridge.GetWrapRequestByID(ctx, w.ID)
+	switch {
+	case err == nil:
+		previous = prev.Status()
+	case !errors.Is(err, pgx.ErrNoRows):
+		return err
+	}
+	if previous == w.Status() {
+		return i.repos.Bridge.UpsertWrapRequest(ctx, w)
+	}
+	batch := &pgx.Batch{}
+	i.repos.Bridge.UpsertWrapRequestBatch(batch, w)
+	err = i.queueWebhooks(batch, []webhooks.Event{{
+		Type: webhooks.EventBridgeWrapStatusChanged,
+		Payload: webhooks.BridgeWrapStatusChanged{
+			ID:                     w.ID,
+			PreviousStatus:         previous,
+			Status:                 w.Status(),
+			NetworkClass:           w.NetworkClass,
+			ChainID:                w.ChainID,
+			ToAddress:              w.ToAddress,
+			TokenStandard:          w.TokenStandard,
+			Amount:                 amountString(w.Amount),
+			Fee:                    amountString(w.Fee),
+			CreationMomentumHeight: w.CreationMomentumHeight,
+		},
+	}}, uint64(w.CreationMomentumHeight))
+	if err != nil {
+		return err
+	}
+	return i.execBatchTx(ctx, batch)
+}
+
 // updateBridgeUnwrapRequests fetches and stores unwrap token requests from the bridge
 // Stops early only when an entire page consists of records that exist AND are finalized in our DB
 func (i *Indexer) updateBridgeUnwrapRequests(ctx context.Context) error {
@@ -1143,10 +1376,6 @@ func (i *Indexer) updateBridgeUnwrapRequests(ctx context.Context) error {
 		reachedStopHeight := false
 
 		for _, u := range unwrapList.List {
-			amount := safeBigIntToInt64(u.Amount, i.logger,
-				"unwrap amount overflow",
-				zap.String("txHash", u.TransactionHash.String()),
-				zap.Int64("logIndex", int64(u.LogIndex)))
 			unwrapRequest := &models.UnwrapTokenRequest{
 				TransactionHash:            u.TransactionHash.String(),
 				LogIndex:                   int64(u.LogIndex),
@@ -1155,14 +1384,14 @@ func (i *Indexer) updateBridgeUnwrapRequests(ctx context.Context) error {
 				ToAddress:                  u.ToAddress.String(),
 				TokenStandard:              u.TokenStandard.String(),
 				TokenAddress:               u.TokenAddress,
-				Amount:                     amount,
+				Amount:                     u.Amount,
 				Signature:                  u.Signature,
 				RegistrationMomentumHeight: int64(u.RegistrationMomentumHeight),
 				Redeemed:                   u.Redeemed > 0,
 				Revoked:                    u.Revoked > 0,
 				RedeemableIn:               int64(u.RedeemableIn),
 			}
-			if err := i.repos.Bridge.UpsertUnwrapRequest(ctx, unwrapRequest); err != nil {
+			if err := i.storeUnwrapRequest(ctx, unwrapRequest, stopHeight == 0); err != nil {
 				i.logger.Warn("failed to upsert unwrap request",
 					zap.String("txHash", u.TransactionHash.String()),
 					zap.Int64("logIndex", int64(u.LogIndex)),
@@ -1188,15 +1417,57 @@ func (i *Indexer) updateBridgeUnwrapRequests(ctx context.Context) error {
 		pageIndex++
 	}
 
+	if i.webhooks != nil {
+		i.webhooks.Notify()
+	}
 	i.logger.Info("bridge sync: unwrap requests done", zap.Uint32("pagesProcessed", pageIndex+1), zap.Int64("stopHeight", stopHeight))
 	return nil
 }
 
-// getPillarOwnerAddress returns the owner address for a pillar name
-func (i *Indexer) getPillarOwnerAddress(name string) string {
-	i.pillarMu.RLock()
-	defer i.pillarMu.RUnlock()
-	return i.pillarNameToOwner[name]
+// storeUnwrapRequest is storeWrapRequest for unwrap requests, emitting
+// bridge.unwrap.status_changed.
+func (i *Indexer) storeUnwrapRequest(ctx context.Context, u *models.UnwrapTokenRequest, baseline bool) error {
+	if i.webhooks == nil || baseline {
+		return i.repos.Bridge.UpsertUnwrapRequest(ctx, u)
+	}
+	var previous string
+	prev, err := i.repos.Bridge.GetUnwrapRequestByTxHash(ctx, u.TransactionHash, u.LogIndex)
+	switch {
+	case err == nil:
+		previous = prev.Status()
+	case !errors.Is(err, pgx.ErrNoRows):
+		return err
+	}
+	if previous == u.Status() {
+		return i.repos.Bridge.UpsertUnwrapRequest(ctx, u)
+	}
+	batch := &pgx.Batch{}
+	i.repos.Bridge.UpsertUnwrapRequestBatch(batch, u)
+	err = i.queueWebhooks(batch, []webhooks.Event{{
+		Type: webhooks.EventBridgeUnwrapStatusChanged,
+		Payload: webhooks.BridgeUnwrapStatusChanged{
+			TransactionHash:            u.TransactionHash,
+			LogIndex:                   u.LogIndex,
+			PreviousStatus:             previous,
+			Status:                     u.Status(),
+			NetworkClass:               u.NetworkClass,
+			ChainID:                    u.ChainID,
+			ToAddress:                  u.ToAddress,
+			TokenStandard:              u.TokenStandard,
+			Amount:                     amountString(u.Amount),
+			RegistrationMomentumHeight: u.RegistrationMomentumHeight,
+		},
+	}}, uint64(u.RegistrationMomentumHeight))
+	if err != nil {
+		return err
+	}
+	return i.execBatchTx(ctx, batch)
+}
+
+// getPillarOwnerAddress returns the owner address of the pillar named name
+// at momentum height, or "" when no pillar held the name then.
+func (i *Indexer) getPillarOwnerAddress(name string, height uint64) string {
+	return i.pillarNames.Owner(name, height)
 }
 
 // getVotingID computes the voting ID for a project or phase by encoding/decoding
@@ -1231,68 +1502,66 @@ func (i *Indexer) getVotingID(id string) string {
 	return id
 }
 
-// getFusionCancelID computes the cancel ID for a fusion by encoding/decoding
-// a CancelFuse call. This mimics how the protocol computes the cancel ID.
+// getFusionCancelID computes the cancel ID for a fusion, logging and
+// returning id unchanged when it cannot.
 func (i *Indexer) getFusionCancelID(id string) string {
-	hash, err := types.HexToHash(id)
+	cancelID, err := FusionCancelID(id)
 	if err != nil {
-		i.logger.Warn("getFusionCancelID: invalid hash", zap.String("id", id), zap.Error(err))
-		return id
-	}
-
-	// Encode CancelFuse(id) using the Plasma ABI
-	encoded, err := embedded.Plasma.EncodeFunction("CancelFuse", []interface{}{hash})
-	if err != nil {
-		i.logger.Warn("getFusionCancelID: encode failed", zap.String("id", id), zap.Error(err))
-		return id
+		i.logger.Warn("getFusionCancelID failed", zap.String("id", id), zap.Error(err))
 	}
+	return cancelID
+}
 
-	// Decode to get the cancel ID (first parameter)
-	decoded, err := embedded.Plasma.DecodeFunction(encoded)
+// getStakeCancelID computes the cancel ID for a stake, logging and
+// returning id unchanged when it cannot.
+func (i *Indexer) getStakeCancelID(id string) string {
+	cancelID, err := StakeCancelID(id)
 	if err != nil {
-		i.logger.Warn("getFusionCancelID: decode failed", zap.String("id", id), zap.Error(err))
-		return id
-	}
-
-	if len(decoded) > 0 {
-		if h, ok := decoded[0].(types.Hash); ok {
-			return h.String()
-		}
+		i.logger.Warn("getStakeCancelID failed", zap.String("id", id), zap.Error(err))
 	}
+	return cancelID
+}
 
-	return id
+// FusionCancelID computes the cancel ID for a fusion by encoding/decoding
+// a CancelFuse call. This mimics how the protocol computes the cancel ID.
+// On error it returns id unchanged.
+func FusionCancelID(id string) (string, error) {
+	return cancelID(embedded.Plasma, "CancelFuse", id)
 }
 
-// getStakeCancelID computes the cancel ID for a stake by encoding/decoding
+// StakeCancelID computes the cancel ID for a stake by encoding/decoding
 // a Cancel call. This mimics how the protocol computes the cancel ID.
-func (i *Indexer) getStakeCancelID(id string) string {
+// On error it returns id unchanged.
+func StakeCancelID(id string) (string, error) {
+	return cancelID(embedded.Stake, "Cancel", id)
+}
+
+// cancelID round-trips id through method(id) on contractAbi and returns
+// the decoded first parameter.
+func cancelID(contractAbi *abi.Abi, method, id string) (string, error) {
 	hash, err := types.HexToHash(id)
 	if err != nil {
-		i.logger.Warn("getStakeCancelID: invalid hash", zap.String("id", id), zap.Error(err))
-		return id
+		return id, fmt.Errorf("invalid hash: %w", err)
 	}
 
-	// Encode Cancel(id) using the Stake ABI
-	encoded, err := embedded.Stake.EncodeFunction("Cancel", []interface{}{hash})
+	encoded, err := contractAbi.EncodeFunction(method, []interface{}{hash})
 	if err != nil {
-		i.logger.Warn("getStakeCancelID: encode failed", zap.String("id", id), zap.Error(err))
-		return id
+		return id, fmt.Errorf("encode %s: %w", method, err)
 	}
 
 	// Decode to get the cancel ID (first parameter)
-	decoded, err := embedded.Stake.DecodeFunction(encoded)
+	decoded, err := contractAbi.DecodeFunction(encoded)
 	if err != nil {
-		i.logger.Warn("getStakeCancelID: decode failed", zap.String("id", id), zap.Error(err))
-		return id
+		return id, fmt.Errorf("decode %s: %w", method, err)
 	}
 
 	if len(decoded) > 0 {
 		if h, ok := decoded[0].(types.Hash); ok {
-			return h.String()
+			return h.String(), nil
 		}
 	}
 
-	return id
+	return id, nil
 }
 
 // GetRepositories returns the repository instances
@@ -1320,121 +1589,3 @@ func (i *Indexer) ProcessMomentumPublic(ctx context.Context, m *api.Momentum) er
 func (i *Indexer) UpdateCachedDataPublic(ctx context.Context) error {
 	return i.updateCachedData(ctx)
 }
-
-// Backfill finds and reprocesses any missing or incomplete momentums.
-// Missing = height gaps in the momentums table
-// Incomplete = momentums where tx_count > actual account blocks stored
-func (i *Indexer) Backfill(ctx context.Context) error {
-	i.logger.Info("starting backfill check")
-
-	// Find missing momentum heights OR momentums with missing account blocks
-	query := `
-		WITH expected AS (
-			SELECT generate_series(1::bigint, (SELECT MAX(height) FROM momentums)) as height
-		),
-		missing_momentums AS (
-			SELECT e.height
-			FROM expected e
-			LEFT JOIN momentums m ON e.height = m.height
-			WHERE m.height IS NULL
-		),
-		incomplete_momentums AS (
-			SELECT m.height
-			FROM momentums m
-			LEFT JOIN (
-				SELECT momentum_height, COUNT(*) as actual_txs
-				FROM account_blocks
-				GROUP BY momentum_height
-			) ab ON m.height = ab.momentum_height
-			WHERE m.tx_count > 0 AND COALESCE(ab.actual_txs, 0) < m.tx_count
-		)
-		SELECT height FROM missing_momentums
-		UNION
-		SELECT height FROM incomplete_momentums
-		ORDER BY height
-	`
-
-	rows, err := i.pool.Query(ctx, query)
-	if err != nil {
-		return fmt.Errorf("failed to query missing momentums: %w", err)
-	}
-	defer rows.Close()
-
-	var missingHeights []uint64
-	for rows.Next() {
-		var height uint64
-		if err := rows.Scan(&height); err != nil {
-			return fmt.Errorf("failed to scan height: %w", err)
-		}
-		missingHeights = append(missingHeights, height)
-	}
-
-	if err := rows.Err(); err != nil {
-		return fmt.Errorf("error iterating rows: %w", err)
-	}
-
-	if len(missingHeights) == 0 {
-		i.logger.Info("backfill: no missing or incomplete momentums found")
-		return nil
-	}
-
-	i.logger.Info("backfill: found gaps to fill", zap.Int("count", len(missingHeights)))
-
-	// Process each missing momentum
-	for idx, height := range missingHeights {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-		}
-
-		i.logger.Info("backfill: processing momentum",
-			zap.Uint64("height", height),
-			zap.Int("progress", idx+1),
-			zap.Int("total", len(missingHeights)))
-
-		// Fetch momentum from node
-		momentums, err := i.client().LedgerApi.GetMomentumsByHeight(height, 1)
-		if err != nil {
-			i.logger.Error("backfill: failed to fetch momentum",
-				zap.Uint64("height", height),
-				zap.Error(err))
-			continue
-		}
-
-		if momentums == nil || len(momentums.List) == 0 {
-			i.logger.Warn("backfill: momentum not found on node",
-				zap.Uint64("height", height))
-			continue
-		}
-
-		// Process the momentum
-		if processErr := i.processMomentum(ctx, momentums.List[0]); processErr != nil {
-			i.logger.Error("backfill: failed to process momentum",
-				zap.Uint64("height", height),
-				zap.Error(processErr))
-			// Try direct insert as fallback
-			i.backfillMomentumDirect(ctx, momentums.List[0])
-		}
-	}
-
-	i.logger.Info("backfill: complete", zap.Int("processed", len(missingHeights)))
-	return nil
-}
-
-// backfillMomentumDirect inserts just the momentum record as a fallback
-func (i *Indexer) backfillMomentumDirect(ctx context.Context, m *api.Momentum) {
-	_, err := i.pool.Exec(ctx, `
-		INSERT INTO momentums (height, hash, timestamp, tx_count, producer, producer_owner, producer_name)
-		VALUES ($1, $2, $3, $4, $5, '', '')
-		ON CONFLICT (height) DO NOTHING`,
-		m.Height, m.Hash.String(), int64(m.TimestampUnix), len(m.Content), m.Producer.String())
-	if err != nil {
-		i.logger.Error("backfill: failed to insert momentum directly",
-			zap.Uint64("height", m.Height),
-			zap.Error(err))
-	} else {
-		i.logger.Info("backfill: inserted momentum directly",
-			zap.Uint64("height", m.Height))
-	}
-}
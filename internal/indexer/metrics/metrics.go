@@ -0,0 +1,319 @@
+// Package metrics owns the Prometheus registry for the indexer process
+// and the collectors that describe catch-up sync throughput, webhook
+// delivery and the failed-height queue.
+//
+// Metrics are exported on a separate listener (port 9093 by default),
+// next to the indexer's health server on 9092 — same split-listener
+// convention as the REST API (9090) and MCP server (9091).
+//
+// Every method is safe on a nil *Metrics so the indexer can record
+// unconditionally; cmd/backfill and tests simply never attach one.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Fetch stages observed by ObserveFetch.
+const (
+	StageMomentums    = "momentums"
+	StageAccountBlock = "account_block"
+	StageAccountInfo  = "account_info"
+)
+
+// Metrics owns the prometheus.Registry and its registered collectors.
+// Build one via New(); hand it to Indexer.AttachMetrics and serve
+// Handler() from the metrics http.Server.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	momentumsTotal     prometheus.Counter
+	accountBlocksTotal prometheus.Counter
+	fetchDur           *prometheus.HistogramVec
+	commitDur          prometheus.Histogram
+	prefetched         prometheus.Gauge
+	committedHeight    prometheus.Gauge
+	targetHeight       prometheus.Gauge
+	nodeReads          *prometheus.CounterVec
+	nodeEjections      *prometheus.CounterVec
+	readRotation       prometheus.Gauge
+
+	webhookQueueDepth  *prometheus.GaugeVec
+	webhookDeliveries  *prometheus.CounterVec
+	webhookDeliveryDur *prometheus.HistogramVec
+	webhookCircuitOpen *prometheus.GaugeVec
+
+	failedHeights       prometheus.Gauge
+	failedHeightRetries *prometheus.CounterVec
+
+	consistencyIssues *prometheus.GaugeVec
+}
+
+// New constructs a Metrics with the standard process + Go runtime
+// collectors plus the catch-up, webhook, failed-height and verifier
+// counters, histograms and gauges.
+func New() *Metrics {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collectors.NewGoCollector())
+	reg.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+
+	m := &Metrics{
+		registry: reg,
+		momentumsTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "nom_indexer",
+			Name:      "catchup_momentums_total",
+			Help:      "Momentums committed by the catch-up sync.",
+		}),
+		accountBlocksTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "nom_indexer",
+			Name:      "catchup_account_blocks_total",
+			Help:      "Account blocks committed by the catch-up sync.",
+		}),
+		fetchDur: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "nom_indexer",
+			Name:      "catchup_fetch_duration_seconds",
+			Help:      "Duration of node RPC fetches made by catch-up prefetch workers, labeled by stage.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"stage"}),
+		commitDur: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "nom_indexer",
+			Name:      "catchup_commit_duration_seconds",
+			Help:      "Duration of the per-momentum transaction in the catch-up committer, in seconds.",
+			Buckets:   prometheus.DefBuckets,
+		}),
+		prefetched: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "nom_indexer",
+			Name:      "catchup_prefetched_momentums",
+			Help:      "Momentums queued ahead of the committer (bounded by indexer.catchup.prefetch_depth).",
+		}),
+		committedHeight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "nom_indexer",
+			Name:      "catchup_committed_height",
+			Help:      "Height of the last momentum committed by the catch-up sync.",
+		}),
+		targetHeight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "nom_indexer",
+			Name:      "catchup_target_height",
+			Help:      "Node frontier height the current catch-up pass is syncing towards.",
+		}),
+		nodeReads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "nom_indexer",
+			Name:      "catchup_node_reads_total",
+			Help:      "Catch-up reads served through the read rotation, labeled by node and result (ok, miss, error).",
+		}, []string{"node", "result"}),
+		nodeEjections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "nom_indexer",
+			Name:      "catchup_node_ejections_total",
+			Help:      "Nodes ejected from the catch-up read rotation, labeled by node and reason (error, slow).",
+		}, []string{"node", "reason"}),
+		readRotation: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "nom_indexer",
+			Name:      "catchup_read_rotation_nodes",
+			Help:      "Nodes currently taking catch-up reads (indexer.catchup.fan_out).",
+		}),
+		webhookQueueDepth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "nom_indexer",
+			Name:      "webhook_queue_depth",
+			Help:      "Claimed outbox rows waiting for an endpoint's delivery worker, labeled by endpoint.",
+		}, []string{"endpoint"}),
+		webhookDeliveries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "nom_indexer",
+			Name:      "webhook_deliveries_total",
+			Help:      "Webhook delivery attempts, labeled by endpoint and result (success, failure).",
+		}, []string{"endpoint", "result"}),
+		webhookDeliveryDur: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "nom_indexer",
+			Name:      "webhook_delivery_duration_seconds",
+			Help:      "Duration of webhook delivery attempts, labeled by endpoint.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"endpoint"}),
+		webhookCircuitOpen: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "nom_indexer",
+			Name:      "webhook_circuit_open",
+			Help:      "1 while an endpoint's circuit breaker is open (deliveries paused), else 0.",
+		}, []string{"endpoint"}),
+		failedHeights: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "nom_indexer",
+			Name:      "failed_heights",
+			Help:      "Momentum heights in indexer_failed_heights waiting to be re-processed.",
+		}),
+		failedHeightRetries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "nom_indexer",
+			Name:      "failed_height_retries_total",
+			Help:      "Retries of failed momentum heights, labeled by result (resolved, failed).",
+		}, []string{"result"}),
+		consistencyIssues: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "nom_indexer",
+			Name:      "consistency_issues",
+			Help:      "Open differences between the database and the node in consistency_issues, labeled by check.",
+		}, []string{"check"}),
+	}
+	reg.MustRegister(
+		m.momentumsTotal,
+		m.accountBlocksTotal,
+		m.fetchDur,
+		m.commitDur,
+		m.prefetched,
+		m.committedHeight,
+		m.targetHeight,
+		m.nodeReads,
+		m.nodeEjections,
+		m.readRotation,
+		m.webhookQueueDepth,
+		m.webhookDeliveries,
+		m.webhookDeliveryDur,
+		m.webhookCircuitOpen,
+		m.failedHeights,
+		m.failedHeightRetries,
+		m.consistencyIssues,
+	)
+	return m
+}
+
+// Handler returns the promhttp.HandlerFor for the metrics endpoint.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ObserveFetch records one RPC fetch made by a prefetch worker.
+func (m *Metrics) ObserveFetch(stage string, d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.fetchDur.WithLabelValues(stage).Observe(d.Seconds())
+}
+
+// ObserveCommit records one committed momentum: its transaction
+// duration, its height, and the number of account blocks it carried.
+func (m *Metrics) ObserveCommit(height uint64, accountBlocks int, d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.momentumsTotal.Inc()
+	m.accountBlocksTotal.Add(float64(accountBlocks))
+	m.commitDur.Observe(d.Seconds())
+	m.committedHeight.Set(float64(height))
+}
+
+// SetPrefetched reports how many momentums are queued ahead of the
+// committer.
+func (m *Metrics) SetPrefetched(n int) {
+	if m == nil {
+		return
+	}
+	m.prefetched.Set(float64(n))
+}
+
+// SetTargetHeight reports the frontier the current catch-up pass is
+// syncing towards.
+func (m *Metrics) SetTargetHeight(height uint64) {
+	if m == nil {
+		return
+	}
+	m.targetHeight.Set(float64(height))
+}
+
+// ObserveNodeRead records one read made through the catch-up read
+// rotation. result is "ok", "miss" or "error".
+func (m *Metrics) ObserveNodeRead(node, result string) {
+	if m == nil {
+		return
+	}
+	m.nodeReads.WithLabelValues(node, result).Inc()
+}
+
+// ObserveNodeEjection records a node leaving the read rotation.
+func (m *Metrics) ObserveNodeEjection(node, reason string) {
+	if m == nil {
+		return
+	}
+	m.nodeEjections.WithLabelValues(node, reason).Inc()
+}
+
+// SetReadRotation reports how many nodes currently take catch-up reads.
+func (m *Metrics) SetReadRotation(n int) {
+	if m == nil {
+		return
+	}
+	m.readRotation.Set(float64(n))
+}
+
+// SetWebhookQueueDepth reports how many claimed rows wait for endpoint's
+// delivery worker.
+func (m *Metrics) SetWebhookQueueDepth(endpoint string, n int) {
+	if m == nil {
+		return
+	}
+	m.webhookQueueDepth.WithLabelValues(endpoint).Set(float64(n))
+}
+
+// ObserveWebhookDelivery records one delivery attempt to endpoint.
+func (m *Metrics) ObserveWebhookDelivery(endpoint string, ok bool, d time.Duration) {
+	if m == nil {
+		return
+	}
+	result := "success"
+	if !ok {
+		result = "failure"
+	}
+	m.webhookDeliveries.WithLabelValues(endpoint, result).Inc()
+	m.webhookDeliveryDur.WithLabelValues(endpoint).Observe(d.Seconds())
+}
+
+// SetWebhookCircuitOpen reports endpoint's circuit breaker state.
+func (m *Metrics) SetWebhookCircuitOpen(endpoint string, open bool) {
+	if m == nil {
+		return
+	}
+	v := 0.0
+	if open {
+		v = 1
+	}
+	m.webhookCircuitOpen.WithLabelValues(endpoint).Set(v)
+}
+
+// ForgetWebhookEndpoint drops every webhook series of endpoint, e.g.
+// once its subscription is deleted.
+func (m *Metrics) ForgetWebhookEndpoint(endpoint string) {
+	if m == nil {
+		return
+	}
+	match := prometheus.Labels{"endpoint": endpoint}
+	m.webhookQueueDepth.DeletePartialMatch(match)
+	m.webhookDeliveries.DeletePartialMatch(match)
+	m.webhookDeliveryDur.DeletePartialMatch(match)
+	m.webhookCircuitOpen.DeletePartialMatch(match)
+}
+
+// SetFailedHeights reports how many heights are queued for retry.
+func (m *Metrics) SetFailedHeights(n int64) {
+	if m == nil {
+		return
+	}
+	m.failedHeights.Set(float64(n))
+}
+
+// ObserveFailedHeightRetry records one retry of a failed height.
+func (m *Metrics) ObserveFailedHeightRetry(resolved bool) {
+	if m == nil {
+		return
+	}
+	result := "resolved"
+	if !resolved {
+		result = "failed"
+	}
+	m.failedHeightRetries.WithLabelValues(result).Inc()
+}
+
+// SetConsistencyIssues reports how many issues of check are open.
+func (m *Metrics) SetConsistencyIssues(check string, n int64) {
+	if m == nil {
+		return
+	}
+	m.consistencyIssues.WithLabelValues(check).Set(float64(n))
+}
@@ -0,0 +1,149 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetrics_ObserveCommit(t *testing.T) {
+	m := New()
+	m.ObserveCommit(41, 3, 10*time.Millisecond)
+	m.ObserveCommit(42, 0, 10*time.Millisecond)
+
+	body := scrape(t, m)
+	for _, want := range []string{
+		"nom_indexer_catchup_momentums_total 2",
+		"nom_indexer_catchup_account_blocks_total 3",
+		"nom_indexer_catchup_committed_height 42",
+		"nom_indexer_catchup_commit_duration_seconds_count 2",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q; got:\n%s", want, body)
+		}
+	}
+}
+
+func TestMetrics_FetchStageLabel(t *testing.T) {
+	m := New()
+	m.ObserveFetch(StageAccountBlock, time.Millisecond)
+
+	body := scrape(t, m)
+	if !strings.Contains(body, `nom_indexer_catchup_fetch_duration_seconds_count{stage="account_block"} 1`) {
+		t.Errorf("expected fetch histogram observation labeled by stage; got:\n%s", body)
+	}
+}
+
+func TestMetrics_NilSafe(t *testing.T) {
+	var m *Metrics
+	// None of these may panic: the indexer records unconditionally and
+	// only cmd/indexer attaches a registry.
+	m.ObserveFetch(StageMomentums, time.Second)
+	m.ObserveCommit(1, 1, time.Second)
+	m.SetPrefetched(5)
+	m.SetTargetHeight(10)
+	m.ObserveNodeRead("primary", "ok")
+	m.ObserveNodeEjection("primary", "slow")
+	m.SetReadRotation(2)
+	m.SetWebhookQueueDepth("subscription/1", 3)
+	m.ObserveWebhookDelivery("subscription/1", true, time.Second)
+	m.SetWebhookCircuitOpen("subscription/1", true)
+	m.ForgetWebhookEndpoint("subscription/1")
+	m.SetFailedHeights(2)
+	m.ObserveFailedHeightRetry(true)
+	m.SetConsistencyIssues("balances", 1)
+}
+
+func TestMetrics_NodeReadLabels(t *testing.T) {
+	m := New()
+	m.ObserveNodeRead("fallback", "error")
+	m.ObserveNodeEjection("fallback", "error")
+	m.SetReadRotation(1)
+
+	body := scrape(t, m)
+	for _, want := range []string{
+		`nom_indexer_catchup_node_reads_total{node="fallback",result="error"} 1`,
+		`nom_indexer_catchup_node_ejections_total{node="fallback",reason="error"} 1`,
+		"nom_indexer_catchup_read_rotation_nodes 1",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q; got:\n%s", want, body)
+		}
+	}
+}
+
+func TestMetrics_WebhookEndpointSeries(t *testing.T) {
+	m := New()
+	m.SetWebhookQueueDepth("subscription/7", 4)
+	m.ObserveWebhookDelivery("subscription/7", true, 20*time.Millisecond)
+	m.ObserveWebhookDelivery("subscription/7", false, time.Second)
+	m.SetWebhookCircuitOpen("subscription/7", true)
+	m.ObserveWebhookDelivery("https://ops.example/hook", true, time.Millisecond)
+
+	body := scrape(t, m)
+	for _, want := range []string{
+		`nom_indexer_webhook_queue_depth{endpoint="subscription/7"} 4`,
+		`nom_indexer_webhook_deliveries_total{endpoint="subscription/7",result="success"} 1`,
+		`nom_indexer_webhook_deliveries_total{endpoint="subscription/7",result="failure"} 1`,
+		`nom_indexer_webhook_delivery_duration_seconds_count{endpoint="subscription/7"} 2`,
+		`nom_indexer_webhook_circuit_open{endpoint="subscription/7"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q; got:\n%s", want, body)
+		}
+	}
+
+	// A deleted subscription's series go away; others stay.
+	m.ForgetWebhookEndpoint("subscription/7")
+	body = scrape(t, m)
+	if strings.Contains(body, `endpoint="subscription/7"`) {
+		t.Errorf("series for a forgotten endpoint remain:\n%s", body)
+	}
+	if !strings.Contains(body, `endpoint="https://ops.example/hook"`) {
+		t.Error("forgetting one endpoint dropped another's series")
+	}
+}
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	return w.Body.String()
+}
+
+func TestMetrics_FailedHeights(t *testing.T) {
+	m := New()
+	m.SetFailedHeights(3)
+	m.ObserveFailedHeightRetry(true)
+	m.ObserveFailedHeightRetry(false)
+	m.ObserveFailedHeightRetry(false)
+
+	body := scrape(t, m)
+	for _, want := range []string{
+		"nom_indexer_failed_heights 3",
+		`nom_indexer_failed_height_retries_total{result="resolved"} 1`,
+		`nom_indexer_failed_height_retries_total{result="failed"} 2`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q; got:\n%s", want, body)
+		}
+	}
+}
+
+func TestMetrics_ConsistencyIssues(t *testing.T) {
+	m := New()
+	m.SetConsistencyIssues("momentums", 0)
+	m.SetConsistencyIssues("balances", 4)
+
+	body := scrape(t, m)
+	for _, want := range []string{
+		`nom_indexer_consistency_issues{check="momentums"} 0`,
+		`nom_indexer_consistency_issues{check="balances"} 4`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q; got:\n%s", want, body)
+		}
+	}
+}
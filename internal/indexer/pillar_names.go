@@ -0,0 +1,116 @@
+package indexer
+
+import (
+	"sync"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+// PillarNames resolves a pillar name to the address that owned it at a
+// given momentum height. The pillar contract never frees a name — a
+// revoked pillar keeps it — so each name has at most one registration
+// and one revocation. Both come from the Register and Revoke rows of
+// pillar_updates, and from the calls the indexer sees as it runs.
+//
+// Names with no recorded registration or revocation, the genesis pillars
+// and pillars registered before the indexer kept this history, fall back
+// to the node's current pillar list. The zero value is ready to use.
+type PillarNames struct {
+	mu      sync.RWMutex
+	history map[string]*pillarName
+	current map[string]string
+}
+
+// pillarName is one name's known lifetime. registeredAt is 0 when the
+// registration is not known; revokedAt is 0 while the pillar is active.
+type pillarName struct {
+	owner        string
+	registeredAt uint64
+	revokedAt    uint64
+}
+
+// Owner returns the owner of the pillar named name at height, or "" when
+// no pillar held the name then: before its registration or after the
+// momentum that revoked it.
+func (p *PillarNames) Owner(name string, height uint64) string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if n, ok := p.history[name]; ok {
+		if height < n.registeredAt || (n.revokedAt != 0 && height > n.revokedAt) {
+			return ""
+		}
+		return n.owner
+	}
+	return p.current[name]
+}
+
+// SetCurrent replaces the fallback used for names without history with
+// the node's current name -> owner map.
+func (p *PillarNames) SetCurrent(nameToOwner map[string]string) {
+	p.mu.Lock()
+	p.current = nameToOwner
+	p.mu.Unlock()
+}
+
+// Load merges pillar_updates name-history rows, as returned by
+// PillarUpdateRepository.NameHistory, into the history. Merging keeps
+// the earliest registration and revocation of each name, so reloading
+// rows already seen is a no-op.
+func (p *PillarNames) Load(rows []*models.PillarUpdate) {
+	for _, pu := range rows {
+		switch pu.Method {
+		case "Register", "RegisterLegacy":
+			p.Registered(pu.Name, pu.OwnerAddress, uint64(pu.MomentumHeight))
+		case "Revoke":
+			p.Revoked(pu.Name, pu.OwnerAddress, uint64(pu.MomentumHeight))
+		}
+	}
+}
+
+// Replace discards the history and rebuilds it from rows, as Load would.
+// Unlike Load it forgets registrations and revocations missing from
+// rows, so it is how a reorg drops the ones that only existed on the
+// orphaned fork.
+func (p *PillarNames) Replace(rows []*models.PillarUpdate) {
+	var fresh PillarNames
+	fresh.Load(rows)
+	p.mu.Lock()
+	p.history = fresh.history
+	p.mu.Unlock()
+}
+
+// Registered records an accepted registration of name by owner at
+// height.
+func (p *PillarNames) Registered(name, owner string, height uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := p.entry(name, owner)
+	if n.registeredAt == 0 || height < n.registeredAt {
+		n.owner = owner
+		n.registeredAt = height
+	}
+}
+
+// Revoked records an accepted revocation of name by owner at height.
+func (p *PillarNames) Revoked(name, owner string, height uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := p.entry(name, owner)
+	if n.revokedAt == 0 || height < n.revokedAt {
+		n.revokedAt = height
+	}
+}
+
+// entry returns name's history entry, creating it for owner. p.mu must
+// be held for writing.
+func (p *PillarNames) entry(name, owner string) *pillarName {
+	if p.history == nil {
+		p.history = make(map[string]*pillarName)
+	}
+	n, ok := p.history[name]
+	if !ok {
+		n = &pillarName{owner: owner}
+		p.history[name] = n
+	}
+	return n
+}
@@ -0,0 +1,79 @@
+package indexer
+
+import (
+	"testing"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+func TestPillarNames_Owner(t *testing.T) {
+	var p PillarNames
+	p.SetCurrent(map[string]string{"genesis": "z1qgenesis", "late": "z1qlate"})
+	p.Load([]*models.PillarUpdate{
+		{Method: "Register", Name: "late", OwnerAddress: "z1qlate", MomentumHeight: 200},
+		{Method: "UpdatePillar", Name: "late", OwnerAddress: "z1qlate", MomentumHeight: 250},
+		{Method: "Revoke", Name: "late", OwnerAddress: "z1qlate", MomentumHeight: 300},
+		{Method: "Revoke", Name: "old", OwnerAddress: "z1qold", MomentumHeight: 150},
+	})
+
+	tests := []struct {
+		name   string
+		height uint64
+		want   string
+	}{
+		{"genesis", 1, "z1qgenesis"}, // no history: the node's current map
+		{"late", 199, ""},            // not registered yet
+		{"late", 200, "z1qlate"},
+		{"late", 300, "z1qlate"}, // the revoking momentum still resolves
+		{"late", 301, ""},
+		{"old", 100, "z1qold"}, // revoked, registration unknown
+		{"old", 151, ""},
+		{"missing", 100, ""},
+	}
+	for _, tt := range tests {
+		if got := p.Owner(tt.name, tt.height); got != tt.want {
+			t.Errorf("Owner(%q, %d) = %q, want %q", tt.name, tt.height, got, tt.want)
+		}
+	}
+}
+
+func TestPillarNames_KeepsEarliest(t *testing.T) {
+	var p PillarNames
+	p.Registered("p1", "z1qfirst", 100)
+	p.Revoked("p1", "z1qfirst", 500)
+
+	// A reload of rows already seen, and a later registration of the
+	// taken name, change nothing.
+	p.Load([]*models.PillarUpdate{
+		{Method: "Register", Name: "p1", OwnerAddress: "z1qfirst", MomentumHeight: 100},
+		{Method: "RegisterLegacy", Name: "p1", OwnerAddress: "z1qsecond", MomentumHeight: 600},
+		{Method: "Revoke", Name: "p1", OwnerAddress: "z1qfirst", MomentumHeight: 700},
+	})
+	if got := p.Owner("p1", 400); got != "z1qfirst" {
+		t.Errorf("Owner at 400 = %q, want z1qfirst", got)
+	}
+	if got := p.Owner("p1", 650); got != "" {
+		t.Errorf("Owner at 650 = %q, want empty after the revoke at 500", got)
+	}
+}
+
+func TestPillarNames_ReplaceDropsOrphanedRevoke(t *testing.T) {
+	rows := []*models.PillarUpdate{
+		{Method: "Register", Name: "p1", OwnerAddress: "z1qowner", MomentumHeight: 100},
+	}
+	var p PillarNames
+	p.Load(rows)
+	p.Revoked("p1", "z1qowner", 500) // seen on a fork later rolled back to 400
+	if got := p.Owner("p1", 600); got != "" {
+		t.Fatalf("Owner at 600 = %q, want empty after the revoke at 500", got)
+	}
+
+	// pillar_updates after the rollback no longer holds the revoke.
+	p.Replace(rows)
+	if got := p.Owner("p1", 600); got != "z1qowner" {
+		t.Errorf("Owner at 600 = %q, want z1qowner once the revoke is rolled back", got)
+	}
+	if got := p.Owner("p1", 99); got != "" {
+		t.Errorf("Owner at 99 = %q, want empty before the registration", got)
+	}
+}
@@ -1,36 +1,34 @@
 package indexer
 
 import (
+	"cmp"
 	"context"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"math"
 	"math/big"
-	"strconv"
+	"slices"
 	"time"
 
+	"github.com/0x3639/znn-sdk-go/rpc_client"
 	"github.com/0x3639/znn-sdk-go/utils"
 	"github.com/jackc/pgx/v5"
 	"github.com/zenon-network/go-zenon/common/types"
 	"github.com/zenon-network/go-zenon/rpc/api"
 	"go.uber.org/zap"
 
+	"github.com/0x3639/nom-indexer-go/internal/indexer/metrics"
 	"github.com/0x3639/nom-indexer-go/internal/models"
 	"github.com/0x3639/nom-indexer-go/internal/webhooks"
 )
 
-// genesisBalanceUpdateThreshold skips per-address balance fetching for
-// momentums with this many or more transactions. Genesis has tens of thousands
-// of txs and per-address GetAccountInfoByAddress calls would be prohibitive.
-const genesisBalanceUpdateThreshold = 1000
-
 // safeBigIntToInt64 converts a *big.Int to int64, capping at math.MaxInt64 if
 // the value overflows. Returns 0 if v is nil. Logs a warning on cap.
 //
-// Storage columns for amounts/balances/supplies are BIGINT, so out-of-range
-// values are silently truncated. Reconsider NUMERIC(78,0) if any token's
-// supply approaches 9.22e18 satoshi.
+// Only for the BIGINT columns that hold ZNN/QSR exclusively (account flows,
+// pillar weights, AZ funds, swap amounts). Token amounts are NUMERIC(78,0)
+// and must stay *big.Int end to end.
 func safeBigIntToInt64(v *big.Int, logger *zap.Logger, msg string, fields ...zap.Field) int64 {
 	if v == nil {
 		return 0
@@ -42,44 +40,162 @@ func safeBigIntToInt64(v *big.Int, logger *zap.Logger, msg string, fields ...zap
 	return math.MaxInt64
 }
 
-// processMomentum processes a single momentum and all its account blocks
+// prefetchedMomentum is a momentum plus everything commitMomentum would
+// otherwise fetch from the node: its account blocks (index-aligned with
+// m.Content) with their tx data already decoded, and the token
+// contract's account info when it refreshes it. Filling it touches only
+// the node, never the database, so the catch-up pipeline fills many of
+// these ahead of the committer.
+type prefetchedMomentum struct {
+	momentum *api.Momentum
+	// blocks[j] is nil when m.Content[j] could not be fetched; the
+	// committer skips it, as the serial path always has.
+	blocks []*prefetchedBlock
+	// balances holds the token contract's account info when the
+	// momentum has one of its blocks (see balanceDeltas); nil otherwise.
+	balances []*prefetchedBalance
+}
+
+type prefetchedBlock struct {
+	block        *api.AccountBlock
+	txData       *models.TxData
+	pairedTxData *models.TxData
+}
+
+type prefetchedBalance struct {
+	address types.Address
+	info    *api.AccountInfo // nil when the RPC failed
+}
+
+// newPrefetchedMomentum allocates the slots for m and returns the fetch
+// tasks that fill them. Each task writes only its own slot, so the tasks
+// may run in any order and concurrently; the momentum is complete once
+// all of them have returned. fanOut spreads the account-block fetches
+// across the read rotation (catch-up only; see readLedger).
+func (i *Indexer) newPrefetchedMomentum(m *api.Momentum, fanOut bool) (*prefetchedMomentum, []func()) {
+	pm := &prefetchedMomentum{
+		momentum: m,
+		blocks:   make([]*prefetchedBlock, len(m.Content)),
+	}
+	tasks := make([]func(), 0, len(m.Content))
+	for j, header := range m.Content {
+		tasks = append(tasks, func() {
+			pm.blocks[j] = i.fetchAccountBlock(header.Hash, fanOut)
+		})
+	}
+
+	for _, header := range m.Content {
+		if header.Address != types.TokenContract {
+			continue
+		}
+		b := &prefetchedBalance{address: header.Address}
+		pm.balances = append(pm.balances, b)
+		tasks = append(tasks, func() {
+			b.info = i.fetchAccountInfo(b.address)
+		})
+		break
+	}
+	return pm, tasks
+}
+
+// fetchAccountBlock fetches one account block and decodes its tx data and
+// its paired block's tx data. Returns nil (logged) on RPC failure.
+func (i *Indexer) fetchAccountBlock(hash types.Hash, fanOut bool) *prefetchedBlock {
+	start := time.Now()
+	var block *api.AccountBlock
+	err := i.readLedger(fanOut, func(c *rpc_client.RpcClient) error {
+		b, err := c.LedgerApi.GetAccountBlockByHash(hash)
+		if err != nil {
+			return err
+		}
+		block = b
+		if b == nil {
+			// A rotation node that is a few momentums behind; the
+			// active node is asked last and its nil is final.
+			return errReadMiss
+		}
+		return nil
+	})
+	i.metrics.ObserveFetch(metrics.StageAccountBlock, time.Since(start))
+	if err != nil {
+		i.logger.Warn("failed to get account block",
+			zap.String("hash", hash.String()),
+			zap.Error(err))
+		return nil
+	}
+	if block == nil {
+		return nil
+	}
+	pb := &prefetchedBlock{
+		block:  block,
+		txData: i.tryDecodeTxData(block),
+	}
+	if block.PairedAccountBlock != nil {
+		pb.pairedTxData = i.tryDecodeTxData(block.PairedAccountBlock)
+	}
+	return pb
+}
+
+// fetchAccountInfo returns the node's current account info for address,
+// or nil (logged) on RPC failure.
+func (i *Indexer) fetchAccountInfo(address types.Address) *api.AccountInfo {
+	start := time.Now()
+	accountInfo, err := i.client().LedgerApi.GetAccountInfoByAddress(address)
+	i.metrics.ObserveFetch(metrics.StageAccountInfo, time.Since(start))
+	if err != nil {
+		i.logger.Warn("failed to get account info",
+			zap.String("address", address.String()),
+			zap.Error(err))
+		return nil
+	}
+	return accountInfo
+}
+
+// processMomentum processes a single momentum and all its account blocks,
+// fetching them serially before committing.
 func (i *Indexer) processMomentum(ctx context.Context, m *api.Momentum) error {
+	pm, tasks := i.newPrefetchedMomentum(m, false)
+	for _, task := range tasks {
+		task()
+	}
+	return i.commitMomentum(ctx, pm)
+}
+
+// commitMomentum writes a prefetched momentum in a single transaction.
+// It is the only step of indexing a height that reads or writes the
+// database, so callers must invoke it in height order.
+func (i *Indexer) commitMomentum(ctx context.Context, pm *prefetchedMomentum) error {
 	start := time.Now()
+	m := pm.momentum
+
+	// Refuse to build on a fork we have not indexed. The caller turns a
+	// *reorgError into a rollback (handleReorg) and resumes from there.
+	if err := i.checkParent(ctx, m); err != nil {
+		return err
+	}
 
 	batch := &pgx.Batch{}
 
 	// blockEvents holds account_block.inserted webhook events collected
-	// while building the batch; they are emitted ONLY after the
-	// transaction below commits. Nil when webhooks are disabled.
+	// while building the batch; they are queued into webhook_outbox
+	// below, in the same transaction. Nil when webhooks are disabled.
 	var blockEvents []webhooks.Event
 
 	// Process account blocks if any
 	if len(m.Content) > 0 {
 		// Process each account block
-		events, err := i.processAccountBlocks(ctx, batch, m)
+		events, err := i.processAccountBlocks(ctx, batch, m, pm.blocks)
 		if err != nil {
 			return fmt.Errorf("failed to process account blocks: %w", err)
 		}
 		blockEvents = events
 
-		// Skip per-address balance fetching for momentums with too many txs:
-		// genesis has tens of thousands and per-address GetAccountInfoByAddress
-		// would be prohibitively slow.
-		if m.Height > 1 && len(m.Content) < genesisBalanceUpdateThreshold {
-			if err := i.updateBalances(ctx, batch, m.Content, int64(m.TimestampUnix)); err != nil {
-				i.logger.Warn("failed to update balances", zap.Error(err))
-			}
-		}
+		i.updateBalances(batch, pm.balances, int64(m.TimestampUnix))
 	}
 
 	// Get pillar info for this momentum
 	producerOwner, producerName := i.getPillarInfoForProducer(ctx, m.Producer.String(), m.Height)
 
-	// Increment pillar momentum count
-	if producerOwner != "" {
-		i.repos.Pillar.IncrementMomentumCountBatch(batch, producerOwner)
-	}
-
 	// Insert momentum
 	momentum := &models.Momentum{
 		Height:        m.Height,
@@ -92,6 +208,19 @@ func (i *Indexer) processMomentum(ctx context.Context, m *api.Momentum) error {
 	}
 	i.repos.Momentum.InsertBatch(ctx, batch, momentum)
 
+	// Increment pillar momentum count and apply the balance changes.
+	// Queued after the momentum insert, like every gated counter after
+	// its row (see repository/effects.go).
+	if producerOwner != "" {
+		i.repos.Pillar.IncrementMomentumCountBatch(batch, m.Height, producerOwner)
+	}
+	for _, d := range balanceDeltas(pm.blocks) {
+		i.repos.Balance.AddDeltaBatch(batch, m.Height, d.address, d.tokenStandard, d.delta, momentum.Timestamp)
+	}
+	// Every counter of this momentum is queued; flag it and its blocks
+	// as counted so reprocessing the height does not count them again.
+	i.repos.Momentum.MarkEffectsAppliedBatch(batch, m.Height)
+
 	// Queue NOTIFY in the same transaction as the row writes. Postgres
 	// delivers NOTIFY only when the transaction commits, so live stream
 	// clients cannot see an event for rolled-back data, and a pg_notify
@@ -100,11 +229,65 @@ func (i *Indexer) processMomentum(ctx context.Context, m *api.Momentum) error {
 		return fmt.Errorf("queue momentum %d notify: %w", m.Height, err)
 	}
 
+	// Queue webhook outbox rows in the same transaction as the rows they
+	// describe: the events exist exactly when the momentum is committed,
+	// so neither a rollback (phantom event) nor a crash after commit (lost
+	// event) can separate the two. The dispatcher delivers from the table.
+	if i.webhooks != nil {
+		events := append([]webhooks.Event{{
+			Type: webhooks.EventMomentumInserted,
+			Payload: webhooks.MomentumInserted{
+				Height:    m.Height,
+				Hash:      m.Hash.String(),
+				Timestamp: int64(m.TimestampUnix),
+			},
+		}}, blockEvents...)
+		if err := i.queueWebhooks(batch, events, m.Height); err != nil {
+			return fmt.Errorf("queue momentum %d webhooks: %w", m.Height, err)
+		}
+	}
+
 	// Run the batch inside a transaction so partial failures roll back and the
 	// caller can retry the height instead of advancing past corrupted state.
+	if err := i.execBatchTx(ctx, batch); err != nil {
+		return fmt.Errorf("momentum %d: %w", m.Height, err)
+	}
+
+	// Wake the delivery worker now that the outbox rows are visible.
+	if i.webhooks != nil {
+		i.webhooks.Notify()
+	}
+
+	i.logger.Debug("processed momentum",
+		zap.Uint64("height", m.Height),
+		zap.Duration("duration", time.Since(start)))
+
+	return nil
+}
+
+// queueWebhooks routes each event to its subscribed endpoints and queues
+// one webhook_outbox row per (event, endpoint) on batch.
+func (i *Indexer) queueWebhooks(batch *pgx.Batch, events []webhooks.Event, height uint64) error {
+	now := time.Now()
+	for _, ev := range events {
+		entries, err := i.webhooks.Outbox(ev, height, now)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			i.repos.WebhookOutbox.InsertBatch(batch, e)
+		}
+	}
+	return nil
+}
+
+// execBatchTx sends batch inside a single transaction and commits it.
+// Every queued statement is drained even after a failure so all errors
+// are logged, but the first error aborts the transaction.
+func (i *Indexer) execBatchTx(ctx context.Context, batch *pgx.Batch) error {
 	tx, err := i.pool.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("begin tx for momentum %d: %w", m.Height, err)
+		return fmt.Errorf("begin tx: %w", err)
 	}
 	committed := false
 	defer func() {
@@ -127,39 +310,13 @@ func (i *Indexer) processMomentum(ctx context.Context, m *api.Momentum) error {
 		batchErr = fmt.Errorf("close batch results: %w", closeErr)
 	}
 	if batchErr != nil {
-		return fmt.Errorf("momentum %d batch failed: %w", m.Height, batchErr)
+		return fmt.Errorf("batch failed: %w", batchErr)
 	}
 
 	if err := tx.Commit(ctx); err != nil {
-		return fmt.Errorf("commit momentum %d: %w", m.Height, err)
+		return fmt.Errorf("commit: %w", err)
 	}
 	committed = true
-
-	// Emit webhook events ONLY now that the transaction has committed.
-	// This is strictly after Commit succeeds and the function never reaches
-	// here on the batch-error / commit-error paths above (each returns
-	// early, leaving committed=false and rolling back). Emit is async and
-	// non-blocking. A crash after commit but before/within Emit just means
-	// the height is re-processed and events re-fire — at-least-once, which
-	// is acceptable for these notifications.
-	if i.webhooks != nil {
-		i.webhooks.Emit(webhooks.Event{
-			Type: "momentum.inserted",
-			Payload: map[string]any{
-				"height":    m.Height,
-				"hash":      m.Hash.String(),
-				"timestamp": int64(m.TimestampUnix),
-			},
-		})
-		for _, ev := range blockEvents {
-			i.webhooks.Emit(ev)
-		}
-	}
-
-	i.logger.Debug("processed momentum",
-		zap.Uint64("height", m.Height),
-		zap.Duration("duration", time.Since(start)))
-
 	return nil
 }
 
@@ -198,7 +355,7 @@ func queueMomentumNotify(batch *pgx.Batch, m *models.Momentum) error {
 //
 // Payload field names mirror dto.AccountBlock so the stream hub can
 // json.Unmarshal directly. Amount is stringified (matches the REST DTO
-// convention; int64 transfers can exceed 2^53). Input is included if
+// convention; transfers can exceed 2^53). Input is included if
 // txData decoded a method. Large inputs (contract calls) can push the
 // payload over Postgres' 8 KB NOTIFY cap; when that happens we omit
 // bulky optional fields (input, then data) and still stream the core
@@ -220,6 +377,10 @@ func queueAccountBlockNotify(batch *pgx.Batch, ab *models.AccountBlock, txData *
 			input = b
 		}
 	}
+	amount := "0"
+	if ab.Amount != nil {
+		amount = ab.Amount.String()
+	}
 	fields := map[string]interface{}{
 		"hash":                 ab.Hash,
 		"momentum_hash":        ab.MomentumHash,
@@ -229,7 +390,7 @@ func queueAccountBlockNotify(batch *pgx.Batch, ab *models.AccountBlock, txData *
 		"height":               ab.Height,
 		"address":              ab.Address,
 		"to_address":           ab.ToAddress,
-		"amount":               strconv.FormatInt(ab.Amount, 10),
+		"amount":               amount,
 		"token_standard":       ab.TokenStandard,
 		"paired_account_block": ab.PairedAccountBlock,
 	}
@@ -268,64 +429,118 @@ func queueAccountBlockNotify(batch *pgx.Batch, ab *models.AccountBlock, txData *
 	return nil
 }
 
-// updateBalances updates balances for all addresses in a momentum
-func (i *Indexer) updateBalances(ctx context.Context, batch *pgx.Batch, headers []*types.AccountHeader, momentumTimestamp int64) error {
-	for _, header := range headers {
-		accountInfo, err := i.client().LedgerApi.GetAccountInfoByAddress(header.Address)
-		if err != nil {
-			i.logger.Warn("failed to get account info",
-				zap.String("address", header.Address.String()),
-				zap.Error(err))
+// updateBalances queues the token contract's balances, from the
+// account info prefetched for it.
+func (i *Indexer) updateBalances(batch *pgx.Batch, balances []*prefetchedBalance, momentumTimestamp int64) {
+	for _, b := range balances {
+		i.queueBalanceUpserts(batch, b.address, b.info, momentumTimestamp)
+	}
+}
+
+// balanceDelta is the net change a momentum makes to one balance.
+type balanceDelta struct {
+	address       string
+	tokenStandard string
+	delta         *big.Int
+}
+
+// balanceDeltas nets the balance changes of a momentum's blocks per
+// (address, token), as the node applies them: a send debits its sender
+// by its own amount, and a receive — which carries no amount — credits
+// the receiver with its paired send's. Zero nets are left out and the
+// rest are sorted, so concurrent writers lock rows in the same order.
+//
+// Genesis receives set their balances outside any block; the
+// reconciliation job (see reconcile.go) brings those in. The token
+// contract is skipped too: IssueToken and Mint credit it the supply it
+// then sends out and Burn debits what it received, none of it in a
+// block, so its balances are refreshed from the node instead.
+func balanceDeltas(blocks []*prefetchedBlock) []balanceDelta {
+	type key struct{ address, tokenStandard string }
+	sums := map[key]*big.Int{}
+	add := func(address types.Address, zts types.ZenonTokenStandard, amount *big.Int, sign int) {
+		if amount == nil || amount.Sign() == 0 {
+			return
+		}
+		k := key{address.String(), zts.String()}
+		sum, ok := sums[k]
+		if !ok {
+			sum = new(big.Int)
+			sums[k] = sum
+		}
+		if sign < 0 {
+			sum.Sub(sum, amount)
+		} else {
+			sum.Add(sum, amount)
+		}
+	}
+	for _, pb := range blocks {
+		if pb == nil || pb.block.Address == types.TokenContract {
 			continue
 		}
+		b := pb.block
+		switch b.BlockType {
+		case utils.BlockTypeUserSend, utils.BlockTypeContractSend:
+			add(b.Address, b.TokenStandard, b.Amount, -1)
+		case utils.BlockTypeUserReceive, utils.BlockTypeContractReceive:
+			if b.PairedAccountBlock != nil {
+				add(b.Address, b.PairedAccountBlock.TokenStandard, b.PairedAccountBlock.Amount, +1)
+			}
+		}
+	}
+	out := make([]balanceDelta, 0, len(sums))
+	for k, sum := range sums {
+		if sum.Sign() != 0 {
+			out = append(out, balanceDelta{address: k.address, tokenStandard: k.tokenStandard, delta: sum})
+		}
+	}
+	slices.SortFunc(out, func(a, b balanceDelta) int {
+		return cmp.Or(cmp.Compare(a.address, b.address), cmp.Compare(a.tokenStandard, b.tokenStandard))
+	})
+	return out
+}
 
-		if accountInfo.BalanceInfoMap != nil {
-			for tokenStandard, balanceInfo := range accountInfo.BalanceInfoMap {
-				if balanceInfo.Balance != nil && balanceInfo.Balance.Sign() >= 0 {
-					// Check for Int64 overflow before conversion. Balance columns are BIGINT,
-					// so values >math.MaxInt64 are silently capped. ZNN/QSR amounts use 1e8
-					// satoshi scaling and are well below int64 max today; reconsider if any
-					// token's supply approaches 9.22e18 satoshi.
-					balanceInt64 := safeBigIntToInt64(balanceInfo.Balance, i.logger,
-						"balance overflow",
-						zap.String("address", header.Address.String()),
-						zap.String("token", tokenStandard.String()))
-					balance := &models.Balance{
-						Address:              header.Address.String(),
-						TokenStandard:        tokenStandard.String(),
-						Balance:              balanceInt64,
-						LastUpdatedTimestamp: momentumTimestamp,
-					}
-					i.repos.Balance.UpsertBatch(batch, balance)
-				}
+// queueBalanceRefresh fetches the node's current balances for address and
+// queues an upsert per token. RPC failures are logged and skipped; the
+// reconciliation job corrects the address later.
+func (i *Indexer) queueBalanceRefresh(batch *pgx.Batch, address types.Address, timestamp int64) {
+	i.queueBalanceUpserts(batch, address, i.fetchAccountInfo(address), timestamp)
+}
+
+// queueBalanceUpserts queues an upsert per token in accountInfo. A nil
+// accountInfo (failed fetch) queues nothing.
+func (i *Indexer) queueBalanceUpserts(batch *pgx.Batch, address types.Address, accountInfo *api.AccountInfo, timestamp int64) {
+	if accountInfo == nil || accountInfo.BalanceInfoMap == nil {
+		return
+	}
+	for tokenStandard, balanceInfo := range accountInfo.BalanceInfoMap {
+		if balanceInfo.Balance != nil && balanceInfo.Balance.Sign() >= 0 {
+			balance := &models.Balance{
+				Address:              address.String(),
+				TokenStandard:        tokenStandard.String(),
+				Balance:              balanceInfo.Balance,
+				LastUpdatedTimestamp: timestamp,
 			}
+			i.repos.Balance.UpsertBatch(batch, balance)
 		}
 	}
-	return nil
 }
 
-// processAccountBlocks processes all account blocks in a momentum. When
+// processAccountBlocks processes the prefetched account blocks of a
+// momentum. When
 // webhooks are enabled it also returns one account_block.inserted event
-// per processed block; these are NOT emitted here — processMomentum emits
-// them only after the per-momentum transaction commits. blockEvents is nil
+// per processed block, each followed by any domain events (stake.created,
+// vote.cast, ...) its embedded-contract call produced; commitMomentum
+// queues them into webhook_outbox on the same batch. blockEvents is nil
 // (no allocation) when webhooks are disabled.
-func (i *Indexer) processAccountBlocks(ctx context.Context, batch *pgx.Batch, m *api.Momentum) ([]webhooks.Event, error) {
+func (i *Indexer) processAccountBlocks(ctx context.Context, batch *pgx.Batch, m *api.Momentum, blocks []*prefetchedBlock) ([]webhooks.Event, error) {
 	var blockEvents []webhooks.Event
-	for _, header := range m.Content {
-		block, err := i.client().LedgerApi.GetAccountBlockByHash(header.Hash)
-		if err != nil {
-			i.logger.Warn("failed to get account block",
-				zap.String("hash", header.Hash.String()),
-				zap.Error(err))
-			continue
-		}
-
-		if block == nil {
+	for _, pb := range blocks {
+		if pb == nil {
 			continue
 		}
-
-		// Decode transaction data if any
-		txData := i.tryDecodeTxData(block)
+		block := pb.block
+		txData := pb.txData
 
 		// Add pillar owner address to inputs for pillar-related transactions
 		if block.ToAddress.String() == models.PillarAddress && txData != nil {
@@ -334,7 +549,7 @@ func (i *Indexer) processAccountBlocks(ctx context.Context, batch *pgx.Batch, m
 				method := txData.Method
 				if method == "Delegate" || method == "Register" || method == "RegisterLegacy" ||
 					method == "Revoke" || method == "UpdatePillar" {
-					txData.Inputs["pillarOwner"] = i.getPillarOwnerAddress(pillarName)
+					txData.Inputs["pillarOwner"] = i.getPillarOwnerAddress(pillarName, m.Height)
 				}
 			}
 		}
@@ -358,10 +573,6 @@ func (i *Indexer) processAccountBlocks(ctx context.Context, batch *pgx.Batch, m
 			data = hex.EncodeToString(block.Data)
 		}
 
-		amountInt64 := safeBigIntToInt64(block.Amount, i.logger,
-			"amount overflow",
-			zap.String("hash", block.Hash.String()))
-
 		accountBlock := &models.AccountBlock{
 			Hash:               block.Hash.String(),
 			MomentumHash:       m.Hash.String(),
@@ -371,7 +582,7 @@ func (i *Indexer) processAccountBlocks(ctx context.Context, batch *pgx.Batch, m
 			Height:             int64(block.Height),
 			Address:            block.Address.String(),
 			ToAddress:          block.ToAddress.String(),
-			Amount:             amountInt64,
+			Amount:             block.Amount,
 			TokenStandard:      block.TokenStandard.String(),
 			Data:               data,
 			PairedAccountBlock: pairedAccountBlock,
@@ -388,18 +599,25 @@ func (i *Indexer) processAccountBlocks(ctx context.Context, batch *pgx.Batch, m
 			return nil, fmt.Errorf("queue account_block %s notify: %w", accountBlock.Hash, err)
 		}
 
-		// Collect an account_block.inserted webhook event (emitted by
-		// processMomentum only after commit). Skipped when webhooks are
-		// disabled so the common path allocates nothing.
+		// Collect an account_block.inserted webhook event (queued into the
+		// outbox by commitMomentum). Skipped when webhooks are disabled so
+		// the common path allocates nothing.
 		if i.webhooks != nil {
+			method := ""
+			if txData != nil {
+				method = txData.Method
+			}
 			blockEvents = append(blockEvents, webhooks.Event{
-				Type: "account_block.inserted",
-				Payload: map[string]any{
-					"momentumHeight": m.Height,
-					"hash":           block.Hash.String(),
-					"address":        block.Address.String(),
-					"toAddress":      block.ToAddress.String(),
-					"blockType":      int(block.BlockType),
+				Type: webhooks.EventAccountBlockInserted,
+				Payload: webhooks.AccountBlockInserted{
+					MomentumHeight: m.Height,
+					Hash:           block.Hash.String(),
+					Address:        block.Address.String(),
+					ToAddress:      block.ToAddress.String(),
+					BlockType:      int(block.BlockType),
+					TokenStandard:  block.TokenStandard.String(),
+					Amount:         amountString(block.Amount),
+					Method:         method,
 				},
 			})
 		}
@@ -410,14 +628,23 @@ func (i *Indexer) processAccountBlocks(ctx context.Context, batch *pgx.Batch, m
 		ts := int64(m.TimestampUnix)
 		sender := block.Address.String()
 		tokenStd := block.TokenStandard.String()
+		// The flow columns are BIGINT and ZNN/QSR-only; both supplies sit
+		// far below int64 at 8 decimals. Other tokens only bump activity,
+		// so their amount is never narrowed.
+		var flowAmount int64
+		if tokenStd == models.ZnnTokenStandard || tokenStd == models.QsrTokenStandard {
+			flowAmount = safeBigIntToInt64(block.Amount, i.logger,
+				"flow amount overflow",
+				zap.String("hash", block.Hash.String()))
+		}
 		switch block.BlockType {
 		case utils.BlockTypeUserSend, utils.BlockTypeContractSend:
-			i.repos.Account.AddSendBatch(batch, sender, tokenStd, amountInt64, ts)
+			i.repos.Account.AddSendBatch(batch, accountBlock.Hash, sender, tokenStd, flowAmount, ts)
 		case utils.BlockTypeUserReceive, utils.BlockTypeContractReceive, utils.BlockTypeGenesisReceive:
-			i.repos.Account.AddReceiveBatch(batch, sender, tokenStd, amountInt64, ts)
+			i.repos.Account.AddReceiveBatch(batch, accountBlock.Hash, sender, tokenStd, flowAmount, ts)
 			// At genesis (height 1), seed genesis_*_balance from the received amount.
 			if block.BlockType == utils.BlockTypeGenesisReceive && m.Height == 1 {
-				i.repos.Account.SetGenesisBalanceBatch(batch, sender, tokenStd, amountInt64)
+				i.repos.Account.SetGenesisBalanceBatch(batch, sender, tokenStd, flowAmount)
 			}
 		}
 
@@ -425,10 +652,10 @@ func (i *Indexer) processAccountBlocks(ctx context.Context, batch *pgx.Batch, m
 		// plays in this block. Mirrors the WHERE address=$1 OR to_address=$1
 		// filter on /accounts/{address}/transactions so the totals match.
 		// Self-sends (sender == recipient) count once, matching the OR.
-		i.repos.Account.BumpTxCountBatch(batch, sender, ts)
+		i.repos.Account.BumpTxCountBatch(batch, accountBlock.Hash, sender, ts)
 		recipient := block.ToAddress.String()
 		if recipient != "" && recipient != sender {
-			i.repos.Account.BumpTxCountBatch(batch, recipient, ts)
+			i.repos.Account.BumpTxCountBatch(batch, accountBlock.Hash, recipient, ts)
 		}
 
 		// Update paired block reference
@@ -447,9 +674,9 @@ func (i *Indexer) processAccountBlocks(ctx context.Context, batch *pgx.Batch, m
 			block.PairedAccountBlock != nil &&
 			models.IsEmbeddedContract(block.Address.String()) {
 
-			pairedTxData := i.tryDecodeTxData(block.PairedAccountBlock)
-			if pairedTxData != nil {
-				i.indexEmbeddedContracts(ctx, batch, block, pairedTxData, m)
+			if pb.pairedTxData != nil {
+				// Domain events follow the block's account_block.inserted.
+				blockEvents = append(blockEvents, i.indexEmbeddedContracts(ctx, batch, block, pb.pairedTxData, m)...)
 			}
 		}
 
@@ -471,14 +698,6 @@ func (i *Indexer) processAccountBlocks(ctx context.Context, batch *pgx.Batch, m
 
 		// Update token info from TokenInfo field
 		if block.TokenInfo != nil {
-			tokenStdStr := block.TokenInfo.ZenonTokenStandard.String()
-			totalSupply := safeBigIntToInt64(block.TokenInfo.TotalSupply, i.logger,
-				"total_supply overflow",
-				zap.String("token", tokenStdStr))
-			maxSupply := safeBigIntToInt64(block.TokenInfo.MaxSupply, i.logger,
-				"max_supply overflow",
-				zap.String("token", tokenStdStr))
-
 			token := &models.Token{
 				TokenStandard: block.TokenInfo.ZenonTokenStandard.String(),
 				Name:          block.TokenInfo.TokenName,
@@ -486,14 +705,14 @@ func (i *Indexer) processAccountBlocks(ctx context.Context, batch *pgx.Batch, m
 				Domain:        block.TokenInfo.TokenDomain,
 				Decimals:      int(block.TokenInfo.Decimals),
 				Owner:         block.TokenInfo.Owner.String(),
-				TotalSupply:   totalSupply,
-				MaxSupply:     maxSupply,
+				TotalSupply:   block.TokenInfo.TotalSupply,
+				MaxSupply:     block.TokenInfo.MaxSupply,
 				IsBurnable:    block.TokenInfo.IsBurnable,
 				IsMintable:    block.TokenInfo.IsMintable,
 				IsUtility:     block.TokenInfo.IsUtility,
 			}
 			i.repos.Token.UpsertBatch(batch, token)
-			i.repos.Token.IncrementTransactionCountBatch(batch, block.TokenInfo.ZenonTokenStandard.String())
+			i.repos.Token.IncrementTransactionCountBatch(batch, block.Hash.String(), block.TokenInfo.ZenonTokenStandard.String())
 		}
 	}
 
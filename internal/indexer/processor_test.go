@@ -6,7 +6,11 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/0x3639/znn-sdk-go/utils"
 	"github.com/jackc/pgx/v5"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest/observer"
 
@@ -59,7 +63,7 @@ func TestQueueAccountBlockNotifyOmitsLargeInputsButQueues(t *testing.T) {
 		Height:            7,
 		Address:           "z1qsender",
 		ToAddress:         "z1qrecipient",
-		Amount:            42,
+		Amount:            big.NewInt(42),
 		TokenStandard:     models.ZnnTokenStandard,
 		Data:              strings.Repeat("d", 200),
 	}
@@ -77,3 +81,79 @@ func TestQueueAccountBlockNotifyOmitsLargeInputsButQueues(t *testing.T) {
 		t.Fatalf("batch.Len() = %d, want 1", batch.Len())
 	}
 }
+
+func TestBalanceDeltas(t *testing.T) {
+	user := types.ParseAddressPanic(testUser)
+	pillar := types.ParseAddressPanic(models.PillarAddress)
+	send := func(from types.Address, zts types.ZenonTokenStandard, amount int64) *prefetchedBlock {
+		return &prefetchedBlock{block: &api.AccountBlock{AccountBlock: nom.AccountBlock{
+			BlockType: utils.BlockTypeUserSend, Address: from, TokenStandard: zts, Amount: big.NewInt(amount),
+		}}}
+	}
+	receive := func(to types.Address, blockType uint64, paired *prefetchedBlock) *prefetchedBlock {
+		// Receive blocks carry no amount of their own.
+		return &prefetchedBlock{block: &api.AccountBlock{
+			AccountBlock:       nom.AccountBlock{BlockType: blockType, Address: to, Amount: big.NewInt(0)},
+			PairedAccountBlock: paired.block,
+		}}
+	}
+
+	toPillar := send(user, types.ZnnTokenStandard, 1500000000000)
+	refund := send(pillar, types.ZnnTokenStandard, 1500000000000)
+	refund.block.BlockType = utils.BlockTypeContractSend
+	mint := send(types.TokenContract, types.QsrTokenStandard, 42)
+	mint.block.BlockType = utils.BlockTypeContractSend
+	blocks := []*prefetchedBlock{
+		toPillar,
+		receive(pillar, utils.BlockTypeContractReceive, toPillar),
+		refund,
+		receive(user, utils.BlockTypeUserReceive, refund),
+		send(user, types.QsrTokenStandard, 7),
+		nil, // a block that could not be fetched
+		mint,
+		receive(user, utils.BlockTypeUserReceive, mint),
+		{block: &api.AccountBlock{AccountBlock: nom.AccountBlock{BlockType: utils.BlockTypeGenesisReceive, Address: user}}},
+	}
+
+	got := balanceDeltas(blocks)
+	want := []balanceDelta{
+		// The ZNN round trip nets to zero on both sides and is left out;
+		// the token contract's send of the minted QSR is not counted.
+		{address: testUser, tokenStandard: models.QsrTokenStandard, delta: big.NewInt(42 - 7)},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("balanceDeltas = %+v, want %+v", got, want)
+	}
+	for j := range want {
+		if got[j].address != want[j].address || got[j].tokenStandard != want[j].tokenStandard ||
+			got[j].delta.Cmp(want[j].delta) != 0 {
+			t.Errorf("delta %d = %+v, want %+v", j, got[j], want[j])
+		}
+	}
+}
+
+func TestBalanceDeltas_Sorted(t *testing.T) {
+	addrs := []string{testUser, models.PillarAddress, models.StakeAddress}
+	var blocks []*prefetchedBlock
+	for _, a := range addrs {
+		for _, zts := range []types.ZenonTokenStandard{types.QsrTokenStandard, types.ZnnTokenStandard} {
+			blocks = append(blocks, &prefetchedBlock{block: &api.AccountBlock{AccountBlock: nom.AccountBlock{
+				BlockType: utils.BlockTypeUserSend, Address: types.ParseAddressPanic(a),
+				TokenStandard: zts, Amount: big.NewInt(1),
+			}}})
+		}
+	}
+	got := balanceDeltas(blocks)
+	if len(got) != 6 {
+		t.Fatalf("got %d deltas, want 6", len(got))
+	}
+	for j := 1; j < len(got); j++ {
+		prev, cur := got[j-1], got[j]
+		if prev.address > cur.address || (prev.address == cur.address && prev.tokenStandard >= cur.tokenStandard) {
+			t.Errorf("deltas out of order at %d: %+v before %+v", j, prev, cur)
+		}
+	}
+	if got[0].delta.Cmp(big.NewInt(-1)) != 0 {
+		t.Errorf("send delta = %s, want -1", got[0].delta)
+	}
+}
@@ -0,0 +1,339 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/0x3639/znn-sdk-go/rpc_client"
+	"go.uber.org/zap"
+)
+
+// Read-rotation tuning. Not configurable: the watchdog thresholds already
+// decide which nodes are healthy; these only decide how reads are shared
+// between them.
+const (
+	// readLatencyAlpha is the EWMA weight of each new read latency.
+	readLatencyAlpha = 0.2
+	// readSlowFactor ejects a node whose latency EWMA exceeds this
+	// multiple of the fastest node still in rotation.
+	readSlowFactor = 4
+	// readSlowFloor keeps sub-millisecond jitter between fast nodes from
+	// counting as "slow".
+	readSlowFloor = 200 * time.Millisecond
+	// readEjectCooldown is how long an ejected node sits out before a
+	// watchdog tick may re-admit it.
+	readEjectCooldown = time.Minute
+)
+
+// errReadMiss marks a read the node answered without error but could not
+// serve, e.g. an account block it has not synced yet. The read moves on
+// to another node; the node is not ejected, since the watchdog already
+// tolerates NodeDriftThreshold momentums of lag.
+var errReadMiss = errors.New("not available on this node")
+
+// Ejection reasons reported to metrics and logs.
+const (
+	ejectError = "error"
+	ejectSlow  = "slow"
+)
+
+// readRotation spreads catch-up ledger reads across every node the
+// watchdog last classified as synced and on the indexer's chain. It is
+// index-aligned with the NodePool.
+//
+// Membership is set by the watchdog tick (admit / drop). Reads eject a
+// node on error, or when it is much slower than the fastest member, for
+// readEjectCooldown. Ejection only affects reads: failover streaks and the
+// active client are left to the watchdog.
+type readRotation struct {
+	mu    sync.Mutex
+	nodes []readNode
+
+	now   func() time.Time
+	float func() float64
+}
+
+type readNode struct {
+	label string
+	// client is the node's own SDK connection, built on first admission.
+	// nil for the active node, whose reads go through Indexer.client().
+	client       *rpc_client.RpcClient
+	eligible     bool
+	latency      time.Duration // EWMA; zero until seeded
+	ejectedUntil time.Time
+}
+
+// newReadRotation builds an empty rotation for the given node labels.
+func newReadRotation(labels []string) *readRotation {
+	r := &readRotation{
+		nodes: make([]readNode, len(labels)),
+		now:   time.Now,
+		float: rand.Float64,
+	}
+	for idx, label := range labels {
+		r.nodes[idx].label = label
+	}
+	return r
+}
+
+// admit marks idx eligible for reads, seeding its latency from the
+// watchdog probe when no reads have been observed yet. A node still
+// inside its ejection cooldown stays out until the cooldown passes.
+func (r *readRotation) admit(idx int, probeLatency time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := &r.nodes[idx]
+	n.eligible = true
+	if n.latency == 0 {
+		n.latency = probeLatency
+	}
+}
+
+// drop removes idx from the rotation until a later admit.
+func (r *readRotation) drop(idx int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nodes[idx].eligible = false
+}
+
+// needsClient reports whether idx has no pooled connection yet.
+func (r *readRotation) needsClient(idx int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.nodes[idx].client == nil
+}
+
+// setClient stores the pooled connection for idx.
+func (r *readRotation) setClient(idx int, c *rpc_client.RpcClient) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nodes[idx].client = c
+}
+
+// available reports whether idx can take a read right now. Caller holds mu.
+func (r *readRotation) available(idx int, now time.Time) bool {
+	n := &r.nodes[idx]
+	return n.eligible && !now.Before(n.ejectedUntil)
+}
+
+// pick chooses a node for the next read, weighted by inverse latency, and
+// skipping any index in tried. Returns -1 when no node is available. The
+// returned client is nil when the node has no pooled connection (the
+// active node); callers then read through Indexer.client().
+func (r *readRotation) pick(tried map[int]bool) (int, *rpc_client.RpcClient) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+
+	var total float64
+	weights := make([]float64, len(r.nodes))
+	for idx := range r.nodes {
+		if tried[idx] || !r.available(idx, now) {
+			continue
+		}
+		weights[idx] = 1 / max(r.nodes[idx].latency.Seconds(), 0.001)
+		total += weights[idx]
+	}
+	if total == 0 {
+		return -1, nil
+	}
+
+	x := r.float() * total
+	last := -1
+	for idx, w := range weights {
+		if w == 0 {
+			continue
+		}
+		last = idx
+		if x < w {
+			return idx, r.nodes[idx].client
+		}
+		x -= w
+	}
+	// Float rounding can leave x a hair above the final weight.
+	return last, r.nodes[last].client
+}
+
+// observe records the outcome of one read on idx and reports the ejection
+// reason, or "" when the node stays in rotation. A miss updates nothing.
+func (r *readRotation) observe(idx int, d time.Duration, err error) string {
+	if errors.Is(err, errReadMiss) {
+		return ""
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	n := &r.nodes[idx]
+
+	if err != nil {
+		r.eject(n, now)
+		return ejectError
+	}
+
+	if n.latency == 0 {
+		n.latency = d
+	} else {
+		n.latency = time.Duration(readLatencyAlpha*float64(d) + (1-readLatencyAlpha)*float64(n.latency))
+	}
+
+	if n.latency < readSlowFloor {
+		return ""
+	}
+	fastest := time.Duration(0)
+	for other := range r.nodes {
+		if other == idx || !r.available(other, now) || r.nodes[other].latency == 0 {
+			continue
+		}
+		if fastest == 0 || r.nodes[other].latency < fastest {
+			fastest = r.nodes[other].latency
+		}
+	}
+	if fastest > 0 && n.latency > readSlowFactor*fastest {
+		r.eject(n, now)
+		return ejectSlow
+	}
+	return ""
+}
+
+// eject takes n out of rotation for readEjectCooldown and forgets its
+// latency so re-admission starts from a fresh probe. Caller holds mu.
+func (r *readRotation) eject(n *readNode, now time.Time) {
+	n.ejectedUntil = now.Add(readEjectCooldown)
+	n.latency = 0
+}
+
+// size returns how many nodes can currently take reads.
+func (r *readRotation) size() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	count := 0
+	for idx := range r.nodes {
+		if r.available(idx, now) {
+			count++
+		}
+	}
+	return count
+}
+
+// label returns the configured label for idx.
+func (r *readRotation) label(idx int) string {
+	return r.nodes[idx].label
+}
+
+// close stops every pooled connection. The active client is owned by the
+// indexer and is not touched.
+func (r *readRotation) close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for idx := range r.nodes {
+		if c := r.nodes[idx].client; c != nil {
+			c.Stop()
+			r.nodes[idx].client = nil
+		}
+	}
+}
+
+// readLedger runs fn against a node from the read rotation, moving to
+// another node when one errors or misses, and finally falls back to the
+// active client. With fanOut false, or no rotation configured, fn simply
+// runs against the active client. fn returns errReadMiss for an answer
+// another node might complete; the active client's answer is final, so
+// a miss there is returned as success with whatever fn stored.
+func (i *Indexer) readLedger(fanOut bool, fn func(c *rpc_client.RpcClient) error) error {
+	if fanOut && i.reads != nil {
+		tried := make(map[int]bool)
+		for {
+			idx, c := i.reads.pick(tried)
+			if idx < 0 {
+				break
+			}
+			tried[idx] = true
+			if c == nil {
+				c = i.client()
+			}
+			start := time.Now()
+			err := fn(c)
+			i.metrics.ObserveNodeRead(i.reads.label(idx), readResult(err))
+			if reason := i.reads.observe(idx, time.Since(start), err); reason != "" {
+				i.metrics.ObserveNodeEjection(i.reads.label(idx), reason)
+				i.metrics.SetReadRotation(i.reads.size())
+				i.logger.Warn("ejected node from catch-up read rotation",
+					zap.String("node", i.reads.label(idx)),
+					zap.String("reason", reason),
+					zap.Error(err))
+			}
+			if err == nil {
+				return nil
+			}
+		}
+	}
+	if err := fn(i.client()); !errors.Is(err, errReadMiss) {
+		return err
+	}
+	return nil
+}
+
+// readResult maps a read outcome to its metrics label.
+func readResult(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, errReadMiss):
+		return "miss"
+	default:
+		return "error"
+	}
+}
+
+// refreshReadRotation re-evaluates every node for the read rotation: a
+// node is admitted when its probe succeeds, it is within
+// NodeDriftThreshold of its network target, and its genesis matches
+// chainID. The probe this tick already made of probedIdx is reused.
+// Called from the watchdog tick; never touches failover streaks.
+func (i *Indexer) refreshReadRotation(
+	ctx context.Context,
+	probedIdx int,
+	probedResult ProbeResult,
+	probedErr error,
+	chainID string,
+	cfg classifyConfig,
+) {
+	// The tick may have just failed over or back; the active node is the
+	// one that reads through Indexer.client() instead of its own connection.
+	i.syncStateMu.RLock()
+	activeIdx := i.syncStateInternal.activeIdx
+	i.syncStateMu.RUnlock()
+
+	for idx := 0; idx < i.nodePool.Len(); idx++ {
+		probe, err := probedResult, probedErr
+		if idx != probedIdx {
+			probe, err = i.nodePool.Probe(ctx, idx)
+		}
+		if err != nil || chainID == "" || probe.GenesisHash != chainID ||
+			int64(probe.Target)-int64(probe.Frontier) > cfg.NodeDriftThreshold {
+			i.reads.drop(idx)
+			continue
+		}
+		if idx != activeIdx && i.reads.needsClient(idx) {
+			factory := i.clientFactory
+			if factory == nil {
+				factory = rpc_client.NewRpcClient
+			}
+			c, cerr := factory(i.nodePool.Entry(idx).URL)
+			if cerr != nil {
+				i.logger.Warn("read rotation: connect failed",
+					zap.String("node", i.nodePool.Entry(idx).Label),
+					zap.Error(cerr))
+				i.reads.drop(idx)
+				continue
+			}
+			i.reads.setClient(idx, c)
+		}
+		i.reads.admit(idx, probe.Latency)
+	}
+	i.metrics.SetReadRotation(i.reads.size())
+}
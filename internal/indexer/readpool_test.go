@@ -0,0 +1,203 @@
+package indexer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/0x3639/znn-sdk-go/rpc_client"
+	"go.uber.org/zap"
+)
+
+// newTestRotation builds a rotation with a fixed clock and a scripted
+// random source, every node admitted at the given latencies.
+func newTestRotation(now *time.Time, roll float64, latencies ...time.Duration) *readRotation {
+	labels := make([]string, len(latencies))
+	for idx := range labels {
+		labels[idx] = string(rune('a' + idx))
+	}
+	r := newReadRotation(labels)
+	r.now = func() time.Time { return *now }
+	r.float = func() float64 { return roll }
+	for idx, l := range latencies {
+		r.admit(idx, l)
+	}
+	return r
+}
+
+func TestReadRotationPickWeightsByInverseLatency(t *testing.T) {
+	now := time.Unix(1000, 0)
+	// Weights 1/10ms = 100 and 1/40ms = 25; total 125. Rolls below 0.8
+	// land on the fast node.
+	r := newTestRotation(&now, 0.79, 10*time.Millisecond, 40*time.Millisecond)
+	if idx, _ := r.pick(nil); idx != 0 {
+		t.Fatalf("roll 0.79 picked %d, want 0", idx)
+	}
+	r.float = func() float64 { return 0.81 }
+	if idx, _ := r.pick(nil); idx != 1 {
+		t.Fatalf("roll 0.81 picked %d, want 1", idx)
+	}
+}
+
+func TestReadRotationPickSkipsTriedAndDropped(t *testing.T) {
+	now := time.Unix(1000, 0)
+	r := newTestRotation(&now, 0, 10*time.Millisecond, 10*time.Millisecond, 10*time.Millisecond)
+	r.drop(1)
+	if idx, _ := r.pick(map[int]bool{0: true}); idx != 2 {
+		t.Fatalf("picked %d, want 2", idx)
+	}
+	if idx, _ := r.pick(map[int]bool{0: true, 2: true}); idx != -1 {
+		t.Fatalf("picked %d with every node tried or dropped, want -1", idx)
+	}
+}
+
+func TestReadRotationErrorEjectsUntilCooldown(t *testing.T) {
+	now := time.Unix(1000, 0)
+	r := newTestRotation(&now, 0, 10*time.Millisecond, 10*time.Millisecond)
+
+	if reason := r.observe(0, time.Millisecond, errors.New("connection reset")); reason != ejectError {
+		t.Fatalf("reason = %q, want %q", reason, ejectError)
+	}
+	if r.size() != 1 {
+		t.Fatalf("size = %d after ejection, want 1", r.size())
+	}
+
+	// A watchdog tick inside the cooldown does not bring it back.
+	r.admit(0, 10*time.Millisecond)
+	if idx, _ := r.pick(nil); idx != 1 {
+		t.Fatalf("picked %d during cooldown, want 1", idx)
+	}
+
+	now = now.Add(readEjectCooldown)
+	r.admit(0, 10*time.Millisecond)
+	if r.size() != 2 {
+		t.Fatalf("size = %d after cooldown, want 2", r.size())
+	}
+}
+
+func TestReadRotationSlowNodeEjected(t *testing.T) {
+	now := time.Unix(1000, 0)
+	r := newTestRotation(&now, 0, 50*time.Millisecond, 50*time.Millisecond)
+
+	// The EWMA climbs towards 2s; once above 4x the 50ms peer it is out.
+	var reason string
+	for n := 0; n < 20 && reason == ""; n++ {
+		reason = r.observe(1, 2*time.Second, nil)
+	}
+	if reason != ejectSlow {
+		t.Fatalf("reason = %q, want %q", reason, ejectSlow)
+	}
+	if r.size() != 1 {
+		t.Fatalf("size = %d, want 1", r.size())
+	}
+}
+
+func TestReadRotationSlowFloorAndSoleNodeKept(t *testing.T) {
+	now := time.Unix(1000, 0)
+	r := newTestRotation(&now, 0, time.Millisecond, 20*time.Millisecond)
+	// 20x slower but under readSlowFloor: not worth ejecting.
+	if reason := r.observe(1, 20*time.Millisecond, nil); reason != "" {
+		t.Fatalf("reason = %q under the floor, want none", reason)
+	}
+
+	r = newTestRotation(&now, 0, 5*time.Second)
+	// No peer to compare against: a slow sole node stays.
+	if reason := r.observe(0, 5*time.Second, nil); reason != "" {
+		t.Fatalf("reason = %q for sole node, want none", reason)
+	}
+}
+
+func TestReadRotationMissDoesNotEject(t *testing.T) {
+	now := time.Unix(1000, 0)
+	r := newTestRotation(&now, 0, 10*time.Millisecond, 10*time.Millisecond)
+	if reason := r.observe(0, time.Millisecond, errReadMiss); reason != "" {
+		t.Fatalf("reason = %q for a miss, want none", reason)
+	}
+	if r.size() != 2 {
+		t.Fatalf("size = %d, want 2", r.size())
+	}
+}
+
+func TestReadLedgerFallsBackToActiveClient(t *testing.T) {
+	now := time.Unix(1000, 0)
+	active := &rpc_client.RpcClient{}
+	behind := &rpc_client.RpcClient{}
+	broken := &rpc_client.RpcClient{}
+
+	r := newTestRotation(&now, 0, 10*time.Millisecond, 10*time.Millisecond)
+	r.setClient(0, behind)
+	r.setClient(1, broken)
+
+	i := &Indexer{logger: zap.NewNop(), reads: r}
+	i.activeClient.Store(active)
+
+	var calls []*rpc_client.RpcClient
+	err := i.readLedger(true, func(c *rpc_client.RpcClient) error {
+		calls = append(calls, c)
+		switch c {
+		case behind:
+			return errReadMiss
+		case broken:
+			return errors.New("eof")
+		}
+		// The active node's miss is final and reported as success.
+		return errReadMiss
+	})
+	if err != nil {
+		t.Fatalf("readLedger: %v", err)
+	}
+	if len(calls) != 3 || calls[2] != active {
+		t.Fatalf("calls = %d, want both rotation nodes then the active client", len(calls))
+	}
+	// Only the erroring node leaves the rotation.
+	if r.size() != 1 {
+		t.Fatalf("size = %d, want 1", r.size())
+	}
+}
+
+func TestReadLedgerWithoutFanOutUsesActiveClient(t *testing.T) {
+	now := time.Unix(1000, 0)
+	active := &rpc_client.RpcClient{}
+	r := newTestRotation(&now, 0, 10*time.Millisecond)
+	r.setClient(0, &rpc_client.RpcClient{})
+
+	i := &Indexer{logger: zap.NewNop(), reads: r}
+	i.activeClient.Store(active)
+
+	var got *rpc_client.RpcClient
+	if err := i.readLedger(false, func(c *rpc_client.RpcClient) error {
+		got = c
+		return nil
+	}); err != nil {
+		t.Fatalf("readLedger: %v", err)
+	}
+	if got != active {
+		t.Fatal("fanOut=false read did not use the active client")
+	}
+}
+
+func TestConfigureCatchUpFanOutNeedsWatchdogAndPeers(t *testing.T) {
+	nodes := []NodeEntry{{URL: "ws://a", Label: "a"}, {URL: "ws://b", Label: "b"}}
+	cfg := CatchUpConfig{Enabled: true, FanOut: true}
+
+	i := NewIndexerWithNodes(nil, NewNodePool(nodes, zap.NewNop()), nil, zap.NewNop(),
+		CronConfig{}, WatchdogConfigForIndexer{Enabled: true})
+	i.ConfigureCatchUp(cfg)
+	if i.reads == nil {
+		t.Fatal("expected a read rotation with two nodes and the watchdog enabled")
+	}
+
+	i = NewIndexerWithNodes(nil, NewNodePool(nodes, zap.NewNop()), nil, zap.NewNop(),
+		CronConfig{}, WatchdogConfigForIndexer{})
+	i.ConfigureCatchUp(cfg)
+	if i.reads != nil {
+		t.Fatal("read rotation built with the watchdog disabled")
+	}
+
+	i = NewIndexerWithNodes(nil, NewNodePool(nodes[:1], zap.NewNop()), nil, zap.NewNop(),
+		CronConfig{}, WatchdogConfigForIndexer{Enabled: true})
+	i.ConfigureCatchUp(cfg)
+	if i.reads != nil {
+		t.Fatal("read rotation built for a single node")
+	}
+}
@@ -0,0 +1,151 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultReconcileAccounts is how many addresses a reconciliation run
+// compares when ReconcileConfig.Accounts is unset.
+const DefaultReconcileAccounts = 1000
+
+// ReconcileConfig schedules the balance reconciliation job. Balances are
+// kept from the amounts in each momentum's blocks (see balanceDeltas);
+// the job compares them with the node a slice of accounts at a time and
+// rewrites the ones that drifted, which is also how the genesis balances
+// get in. The zero value leaves it off.
+type ReconcileConfig struct {
+	// Enabled runs the job alongside live sync.
+	Enabled bool
+	// Interval is the time between runs. Default 10m.
+	Interval time.Duration
+	// Accounts is how many addresses each run compares. Default
+	// DefaultReconcileAccounts.
+	Accounts int
+}
+
+// withDefaults fills unset fields with the documented defaults.
+func (c ReconcileConfig) withDefaults() ReconcileConfig {
+	if c.Interval <= 0 {
+		c.Interval = 10 * time.Minute
+	}
+	if c.Accounts <= 0 {
+		c.Accounts = DefaultReconcileAccounts
+	}
+	return c
+}
+
+// ConfigureReconcile enables or tunes the balance reconciliation job.
+// Call before Run.
+func (i *Indexer) ConfigureReconcile(cfg ReconcileConfig) {
+	i.reconcileCfg = cfg
+}
+
+// runReconcileLoop reconciles the next slice of accounts every interval
+// until ctx is canceled, skipping a run while the indexer is catching
+// up: an account's balances can only be compared once its blocks are
+// indexed.
+func (i *Indexer) runReconcileLoop(ctx context.Context) {
+	cfg := i.reconcileCfg.withDefaults()
+	i.logger.Info("starting balance reconciliation job",
+		zap.Duration("interval", cfg.Interval),
+		zap.Int("accounts", cfg.Accounts))
+
+	cursor := ""
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			i.logger.Info("balance reconciliation job stopped")
+			return
+		case <-ticker.C:
+			lag, err := i.verifyLag(ctx)
+			if err != nil {
+				i.logger.Warn("reconcile: lag check failed, skipping run", zap.Error(err))
+				continue
+			}
+			if lag > verifyMaxLag {
+				i.logger.Info("reconcile: indexer catching up, skipping run", zap.Uint64("lag", lag))
+				continue
+			}
+			next, rep, err := i.ReconcileBalances(ctx, cursor, cfg.Accounts)
+			if err != nil {
+				if ctx.Err() == nil {
+					i.logger.Warn("reconcile: run failed", zap.Error(err))
+				}
+				continue
+			}
+			cursor = next
+			i.logger.Info("reconcile: run complete",
+				zap.Int("compared", rep.Compared),
+				zap.Int("drifted", len(rep.Issues)),
+				zap.Int("corrected", rep.RepairsQueued))
+		}
+	}
+}
+
+// ReconcileBalances compares the balances of up to n addresses with the
+// node and rewrites the ones that differ, recording each difference in
+// consistency_issues under the balances check. Addresses holding a
+// negative balance, which is always drift, go first; the rest of the
+// run takes the accounts after cursor in address order. It returns the
+// cursor for the next run, "" once the walk has reached the end.
+func (i *Indexer) ReconcileBalances(ctx context.Context, cursor string, n int) (string, *VerifyCheckReport, error) {
+	negative, err := i.repos.Consistency.Addresses(ctx, "negative_balances", "", n)
+	if err != nil {
+		return cursor, nil, fmt.Errorf("reconcile: %w", err)
+	}
+	next := cursor
+	var page []string
+	if want := n - len(negative); want > 0 {
+		if page, err = i.repos.Consistency.Addresses(ctx, "accounts", cursor, want); err != nil {
+			return cursor, nil, fmt.Errorf("reconcile: %w", err)
+		}
+		next = nextReconcileCursor(page, want)
+	}
+	subjects := reconcileSubjects(negative, page)
+
+	check := i.verifyChecks()[slices.Index(VerifyChecks, "balances")]
+	check.scan = func(_ context.Context, _ VerifyOptions, compare func([]string) error) error {
+		if len(subjects) == 0 {
+			return nil
+		}
+		return compare(subjects)
+	}
+	v := &verifier{
+		opts:   VerifyOptions{Repair: true},
+		logger: i.logger,
+		checks: []verifyCheck{check},
+		store:  i.repos.Consistency,
+		now:    time.Now,
+	}
+	report, err := v.run(ctx)
+	i.refreshConsistencyGauge(ctx)
+	if err != nil {
+		return cursor, nil, fmt.Errorf("reconcile: %w", err)
+	}
+	return next, report.Checks[0], nil
+}
+
+// nextReconcileCursor returns where the account walk resumes after page,
+// a request for want addresses: its last address, or "" to start over
+// when the page came back short.
+func nextReconcileCursor(page []string, want int) string {
+	if len(page) < want {
+		return ""
+	}
+	return page[len(page)-1]
+}
+
+// reconcileSubjects merges the negative-balance addresses and the page,
+// sorted, each once.
+func reconcileSubjects(negative, page []string) []string {
+	out := slices.Concat(negative, page)
+	slices.Sort(out)
+	return slices.Compact(out)
+}
@@ -0,0 +1,37 @@
+package indexer
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestReconcileConfigDefaults(t *testing.T) {
+	got := ReconcileConfig{}.withDefaults()
+	if got.Interval != 10*time.Minute || got.Accounts != DefaultReconcileAccounts {
+		t.Errorf("defaults = %+v", got)
+	}
+	set := ReconcileConfig{Interval: time.Minute, Accounts: 5}.withDefaults()
+	if set.Interval != time.Minute || set.Accounts != 5 {
+		t.Errorf("explicit values overridden: %+v", set)
+	}
+}
+
+func TestNextReconcileCursor(t *testing.T) {
+	if got := nextReconcileCursor([]string{"z1a", "z1b"}, 2); got != "z1b" {
+		t.Errorf("full page: cursor = %q, want z1b", got)
+	}
+	if got := nextReconcileCursor([]string{"z1a"}, 2); got != "" {
+		t.Errorf("short page: cursor = %q, want a restart", got)
+	}
+	if got := nextReconcileCursor(nil, 2); got != "" {
+		t.Errorf("empty page: cursor = %q, want a restart", got)
+	}
+}
+
+func TestReconcileSubjects(t *testing.T) {
+	got := reconcileSubjects([]string{"z1c", "z1a"}, []string{"z1a", "z1b"})
+	if want := []string{"z1a", "z1b", "z1c"}; !slices.Equal(got, want) {
+		t.Errorf("subjects = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,247 @@
+package indexer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api"
+	"go.uber.org/zap"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+	"github.com/0x3639/nom-indexer-go/internal/webhooks"
+)
+
+// maxReorgDepth bounds the common-ancestor search. Zenon momentums are
+// final long before this many heights; a mismatch deeper than this means
+// the node is on a different network or its database was replaced, and
+// rolling back automatically would do more harm than stopping.
+const maxReorgDepth = 1000
+
+// reorgScanWindow is the number of heights compared per step of the
+// common-ancestor search — one GetMomentumsByHeight call and one range
+// query per step.
+const reorgScanWindow = 100
+
+// reorgError reports that a momentum does not extend the indexed chain:
+// either its previous hash disagrees with the stored hash at height-1,
+// or a different momentum is already stored at its own height. height is
+// the indexed height whose stored hash no longer matches the node.
+type reorgError struct {
+	height uint64
+	stored string
+	node   string
+}
+
+func (e *reorgError) Error() string {
+	return fmt.Sprintf("chain reorg at height %d: indexed hash %s, node hash %s", e.height, e.stored, e.node)
+}
+
+// reorgEvent is the payload of the `reorg` NOTIFY and webhook. Heights
+// in (CommonAncestorHeight, OrphanedTipHeight] were removed and will be
+// re-indexed from the node's new fork.
+type reorgEvent struct {
+	CommonAncestorHeight uint64 `json:"common_ancestor_height"`
+	CommonAncestorHash   string `json:"common_ancestor_hash"`
+	OrphanedTipHeight    uint64 `json:"orphaned_tip_height"`
+	OrphanedTipHash      string `json:"orphaned_tip_hash"`
+}
+
+// checkParent verifies that m extends the indexed chain. It is a no-op
+// for genesis and for heights whose neighbours are not indexed (e.g. a
+// backfill filling a gap); there is nothing stored to disagree with.
+func (i *Indexer) checkParent(ctx context.Context, m *api.Momentum) error {
+	if m.Height <= 1 {
+		return nil
+	}
+	stored, err := i.repos.Momentum.HashesByHeightRange(ctx, m.Height-1, m.Height)
+	if err != nil {
+		return fmt.Errorf("load parent hash for momentum %d: %w", m.Height, err)
+	}
+	if rerr := parentMismatch(m.Height, m.Hash.String(), m.PreviousHash.String(), stored); rerr != nil {
+		return rerr
+	}
+	return nil
+}
+
+// parentMismatch is the pure half of checkParent: stored holds the
+// indexed hashes at height-1 and height (either may be absent).
+// Re-processing the exact momentum already stored at height is not a
+// mismatch.
+func parentMismatch(height uint64, hash, previousHash string, stored map[uint64]string) *reorgError {
+	if s, ok := stored[height]; ok && s != hash {
+		return &reorgError{height: height, stored: s, node: hash}
+	}
+	if s, ok := stored[height-1]; ok && s != previousHash {
+		return &reorgError{height: height - 1, stored: s, node: previousHash}
+	}
+	return nil
+}
+
+// handleReorg rolls the index back to the highest height on which the
+// database and the node still agree, in a single transaction. Callers
+// resume catch-up from the new MAX(height) afterwards, which re-indexes
+// the node's fork.
+//
+// Balances are reversed with the rest of the ledger-derived state, except
+// the token contract's: those are node snapshots (see balanceDeltas), so
+// the rollback re-fetches them when an orphaned block touched it.
+// The `reorg` NOTIFY is queued in the same transaction, so stream
+// clients hear about it only if the rollback commits.
+func (i *Indexer) handleReorg(ctx context.Context, detected *reorgError) error {
+	i.logger.Warn("chain reorg detected",
+		zap.Uint64("height", detected.height),
+		zap.String("indexedHash", detected.stored),
+		zap.String("nodeHash", detected.node))
+
+	tip, err := i.repos.Momentum.GetLatest(ctx)
+	if err != nil {
+		return fmt.Errorf("load indexed tip: %w", err)
+	}
+	ancestorHeight, err := i.findCommonAncestor(ctx, tip.Height)
+	if err != nil {
+		return err
+	}
+	if ancestorHeight == tip.Height {
+		// The node flipped back to our fork between detection and the
+		// search; nothing to undo.
+		i.logger.Info("reorg resolved itself; indexed chain matches node",
+			zap.Uint64("height", tip.Height))
+		return nil
+	}
+	ancestor, err := i.repos.Momentum.GetByHeight(ctx, ancestorHeight)
+	if err != nil {
+		return fmt.Errorf("load common ancestor %d: %w", ancestorHeight, err)
+	}
+
+	addresses, err := i.repos.Reorg.AffectedAddresses(ctx, int64(ancestorHeight))
+	if err != nil {
+		return fmt.Errorf("load addresses above %d: %w", ancestorHeight, err)
+	}
+
+	batch := &pgx.Batch{}
+	i.repos.Reorg.RollbackAboveBatch(batch, int64(ancestorHeight), ancestor.Timestamp)
+	if slices.Contains(addresses, models.TokenAddress) {
+		i.queueBalanceRefresh(batch, types.TokenContract, ancestor.Timestamp)
+	}
+
+	ev := reorgEvent{
+		CommonAncestorHeight: ancestor.Height,
+		CommonAncestorHash:   ancestor.Hash,
+		OrphanedTipHeight:    tip.Height,
+		OrphanedTipHash:      tip.Hash,
+	}
+	if err := queueReorgNotify(batch, ev); err != nil {
+		return err
+	}
+	if i.webhooks != nil {
+		// Undelivered events for the orphaned momentums go with them; the
+		// reorg event itself is queued in the same transaction.
+		i.repos.WebhookOutbox.DiscardPendingAboveBatch(batch, int64(ancestorHeight))
+		err := i.queueWebhooks(batch, []webhooks.Event{{
+			Type: webhooks.EventReorg,
+			Payload: webhooks.Reorg{
+				CommonAncestorHeight: ev.CommonAncestorHeight,
+				CommonAncestorHash:   ev.CommonAncestorHash,
+				OrphanedTipHeight:    ev.OrphanedTipHeight,
+				OrphanedTipHash:      ev.OrphanedTipHash,
+			},
+		}}, ancestorHeight)
+		if err != nil {
+			return fmt.Errorf("queue reorg webhook: %w", err)
+		}
+	}
+	if err := i.execBatchTx(ctx, batch); err != nil {
+		return fmt.Errorf("roll back above height %d: %w", ancestorHeight, err)
+	}
+	if i.webhooks != nil {
+		i.webhooks.Notify()
+	}
+
+	// The rollback removed the orphaned Register and Revoke rows; rebuild
+	// the name history so they stop resolving owners on the new fork.
+	history, err := i.repos.PillarUpdate.NameHistory(ctx)
+	if err != nil {
+		return fmt.Errorf("reload pillar name history: %w", err)
+	}
+	i.pillarNames.Replace(history)
+
+	i.logger.Warn("rolled back orphaned momentums",
+		zap.Uint64("commonAncestor", ev.CommonAncestorHeight),
+		zap.Uint64("orphanedTip", ev.OrphanedTipHeight),
+		zap.Int("addressesRefreshed", len(addresses)))
+	return nil
+}
+
+// findCommonAncestor walks down from tip in reorgScanWindow steps and
+// returns the highest indexed height whose hash matches the node.
+func (i *Indexer) findCommonAncestor(ctx context.Context, tip uint64) (uint64, error) {
+	for top := tip; top > 0; {
+		if tip-top >= maxReorgDepth {
+			return 0, fmt.Errorf("no common ancestor within %d momentums of height %d", maxReorgDepth, tip)
+		}
+		from := uint64(1)
+		if top > reorgScanWindow {
+			from = top - reorgScanWindow + 1
+		}
+
+		stored, err := i.repos.Momentum.HashesByHeightRange(ctx, from, top)
+		if err != nil {
+			return 0, fmt.Errorf("load indexed hashes %d-%d: %w", from, top, err)
+		}
+		var list *api.MomentumList
+		if err := withRetry(ctx, i.logger, "GetMomentumsByHeight", func() error {
+			l, err := i.client().LedgerApi.GetMomentumsByHeight(from, top-from+1)
+			if err != nil {
+				return err
+			}
+			list = l
+			return nil
+		}); err != nil {
+			return 0, fmt.Errorf("fetch node momentums %d-%d: %w", from, top, err)
+		}
+		node := make(map[uint64]string)
+		if list != nil {
+			for _, m := range list.List {
+				node[m.Height] = m.Hash.String()
+			}
+		}
+
+		if h, ok := commonAncestor(stored, node, from, top); ok {
+			return h, nil
+		}
+		top = from - 1
+	}
+	return 0, fmt.Errorf("no common ancestor: indexed genesis momentum differs from node")
+}
+
+// commonAncestor returns the highest height in [from, top] that is both
+// indexed and hashed identically by the node. Heights missing from
+// either side (index gaps, or a node whose fork is shorter) are skipped.
+func commonAncestor(stored, node map[uint64]string, from, top uint64) (uint64, bool) {
+	for h := top; h >= from && h > 0; h-- {
+		s, ok := stored[h]
+		if !ok {
+			continue
+		}
+		if n, ok := node[h]; ok && n == s {
+			return h, true
+		}
+	}
+	return 0, false
+}
+
+// queueReorgNotify appends a NOTIFY reorg statement. Like the momentum
+// and account-block notifies it must be queued inside the rollback
+// transaction so it is delivered only on commit.
+func queueReorgNotify(batch *pgx.Batch, ev reorgEvent) error {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("marshal reorg notify payload: %w", err)
+	}
+	batch.Queue(`SELECT pg_notify('reorg', $1::text)`, string(payload))
+	return nil
+}
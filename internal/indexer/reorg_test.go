@@ -0,0 +1,90 @@
+package indexer
+
+import "testing"
+
+func TestParentMismatch(t *testing.T) {
+	stored := map[uint64]string{9: "h9", 10: "h10"}
+
+	tests := []struct {
+		name       string
+		height     uint64
+		hash, prev string
+		stored     map[uint64]string
+		wantHeight uint64 // 0 = no mismatch
+	}{
+		{"extends tip", 11, "h11", "h10", stored, 0},
+		{"re-process same momentum", 10, "h10", "h9", stored, 0},
+		{"parent differs", 11, "h11", "x10", stored, 10},
+		{"different momentum at indexed height", 10, "x10", "h9", stored, 10},
+		{"sibling on fork at indexed height", 10, "x10", "x9", stored, 10},
+		{"parent not indexed", 20, "h20", "h19", stored, 0},
+		{"empty index", 1, "h1", "", map[uint64]string{}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parentMismatch(tt.height, tt.hash, tt.prev, tt.stored)
+			if tt.wantHeight == 0 {
+				if got != nil {
+					t.Fatalf("parentMismatch = %v, want nil", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("parentMismatch = nil, want mismatch at %d", tt.wantHeight)
+			}
+			if got.height != tt.wantHeight {
+				t.Errorf("mismatch height = %d, want %d", got.height, tt.wantHeight)
+			}
+		})
+	}
+}
+
+func TestCommonAncestor(t *testing.T) {
+	stored := map[uint64]string{1: "a1", 2: "a2", 3: "a3", 5: "a5", 6: "a6"}
+
+	tests := []struct {
+		name     string
+		node     map[uint64]string
+		from     uint64
+		top      uint64
+		want     uint64
+		wantFind bool
+	}{
+		{
+			name: "fork above 3",
+			node: map[uint64]string{1: "a1", 2: "a2", 3: "a3", 4: "b4", 5: "b5", 6: "b6"},
+			from: 1, top: 6, want: 3, wantFind: true,
+		},
+		{
+			name: "skips index gap at 4",
+			node: map[uint64]string{1: "a1", 2: "a2", 3: "a3", 4: "a4", 5: "a5", 6: "b6"},
+			from: 1, top: 6, want: 5, wantFind: true,
+		},
+		{
+			name: "node fork shorter than index",
+			node: map[uint64]string{1: "a1", 2: "a2"},
+			from: 1, top: 6, want: 2, wantFind: true,
+		},
+		{
+			name: "no match in window",
+			node: map[uint64]string{5: "b5", 6: "b6"},
+			from: 5, top: 6, wantFind: false,
+		},
+		{
+			name: "genesis differs",
+			node: map[uint64]string{1: "b1", 2: "b2", 3: "b3"},
+			from: 1, top: 3, wantFind: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := commonAncestor(stored, tt.node, tt.from, tt.top)
+			if ok != tt.wantFind {
+				t.Fatalf("found = %v, want %v", ok, tt.wantFind)
+			}
+			if ok && got != tt.want {
+				t.Errorf("ancestor = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
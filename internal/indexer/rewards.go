@@ -16,9 +16,6 @@ func (i *Indexer) indexLiquidityReward(batch *pgx.Batch, block *api.AccountBlock
 		return
 	}
 
-	amount := safeBigIntToInt64(block.PairedAccountBlock.Amount, i.logger,
-		"liquidity reward amount overflow",
-		zap.String("hash", block.Hash.String()))
 	rt := &models.RewardTransaction{
 		Hash:              block.Hash.String(),
 		Address:           block.Address.String(),
@@ -26,17 +23,17 @@ func (i *Indexer) indexLiquidityReward(batch *pgx.Batch, block *api.AccountBlock
 		MomentumTimestamp: int64(m.TimestampUnix),
 		MomentumHeight:    int64(m.Height),
 		AccountHeight:     int64(block.Height),
-		Amount:            amount,
+		Amount:            block.PairedAccountBlock.Amount,
 		TokenStandard:     block.PairedAccountBlock.TokenStandard.String(),
 		SourceAddress:     block.PairedAccountBlock.Address.String(),
 	}
 
 	i.repos.Reward.InsertRewardTransactionBatch(batch, rt)
-	i.repos.Reward.UpdateCumulativeRewardsBatch(batch, rt.Address, rt.RewardType, rt.Amount, rt.TokenStandard)
+	i.repos.Reward.AddRewardBatch(batch, rt)
 
 	i.logger.Debug("indexed liquidity reward",
 		zap.String("address", rt.Address),
-		zap.Int64("amount", rt.Amount))
+		zap.Stringer("amount", rt.Amount))
 }
 
 // indexReceivedReward handles received reward transactions from embedded contracts
@@ -53,10 +50,6 @@ func (i *Indexer) indexReceivedReward(ctx context.Context, batch *pgx.Batch, blo
 		return
 	}
 
-	amount := safeBigIntToInt64(block.PairedAccountBlock.Amount, i.logger,
-		"reward amount overflow",
-		zap.String("hash", block.Hash.String()),
-		zap.String("source", sourceAddress))
 	rt := &models.RewardTransaction{
 		Hash:              block.Hash.String(),
 		Address:           receiverAddress,
@@ -64,18 +57,18 @@ func (i *Indexer) indexReceivedReward(ctx context.Context, batch *pgx.Batch, blo
 		MomentumTimestamp: int64(m.TimestampUnix),
 		MomentumHeight:    int64(m.Height),
 		AccountHeight:     int64(block.Height),
-		Amount:            amount,
+		Amount:            block.PairedAccountBlock.Amount,
 		TokenStandard:     block.PairedAccountBlock.TokenStandard.String(),
 		SourceAddress:     sourceAddress,
 	}
 
 	i.repos.Reward.InsertRewardTransactionBatch(batch, rt)
-	i.repos.Reward.UpdateCumulativeRewardsBatch(batch, rt.Address, rt.RewardType, rt.Amount, rt.TokenStandard)
+	i.repos.Reward.AddRewardBatch(batch, rt)
 
 	i.logger.Debug("indexed reward",
 		zap.String("type", rt.RewardType.String()),
 		zap.String("address", rt.Address),
-		zap.Int64("amount", rt.Amount))
+		zap.Stringer("amount", rt.Amount))
 }
 
 // classifyReward maps (source contract, receiver) to a RewardType. Pillar
@@ -0,0 +1,1110 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api"
+	"go.uber.org/zap"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+	"github.com/0x3639/nom-indexer-go/internal/repository"
+)
+
+// VerifyChecks lists the comparisons Verify can run, in the order it
+// runs them.
+var VerifyChecks = []string{"momentums", "balances", "tokens", "pillars", "stakes", "fusions"}
+
+// DefaultVerifySamples is how many heights, and how many addresses per
+// address check, a sampled run compares when VerifyOptions.Samples is
+// unset.
+const DefaultVerifySamples = 100
+
+// verifyBatch is how many heights or addresses one comparison, and so
+// one consistency_issues write, covers.
+const verifyBatch = 500
+
+// verifyMaxLag is how many momentums the indexer may trail the node's
+// frontier by for the periodic job to run. Further behind, balances and
+// stakes differ only because their blocks are not indexed yet.
+const verifyMaxLag = 10
+
+// verifyEntriesPage is the page size of the stake and fusion entry
+// listings read from the node.
+const verifyEntriesPage = 100
+
+// VerifyOptions selects what a verification run compares. The zero
+// value samples DefaultVerifySamples heights and addresses for every
+// check, without repairing anything.
+type VerifyOptions struct {
+	// From and To bound the momentum heights compared, inclusive. From 0
+	// means 1; To 0 means the highest indexed momentum when the run
+	// starts.
+	From, To uint64
+	// Checks restricts the run to these checks (see VerifyChecks). Empty
+	// runs them all.
+	Checks []string
+	// Samples is how many heights, and how many addresses per address
+	// check, a sampled run compares. Default DefaultVerifySamples.
+	Samples int
+	// Exhaustive compares every height in range and every address
+	// instead of a sample. Tokens and pillars are always compared whole.
+	Exhaustive bool
+	// Repair queues a fix for every issue found: heights and stake or
+	// fusion entries are re-queued for the failed-height retrier to
+	// reprocess, and balances, tokens and pillars are refreshed from
+	// the node.
+	Repair bool
+}
+
+// Validate reports whether o is usable.
+func (o VerifyOptions) Validate() error {
+	if o.To != 0 && o.From > o.To {
+		return fmt.Errorf("from %d is above to %d", o.From, o.To)
+	}
+	for _, c := range o.Checks {
+		if !slices.Contains(VerifyChecks, c) {
+			return fmt.Errorf("unknown check %q", c)
+		}
+	}
+	if o.Samples < 0 {
+		return fmt.Errorf("samples must be positive, got %d", o.Samples)
+	}
+	return nil
+}
+
+// VerifyReport is the outcome of a verification run.
+type VerifyReport struct {
+	From       uint64               `json:"from"`
+	To         uint64               `json:"to"`
+	Exhaustive bool                 `json:"exhaustive"`
+	Repair     bool                 `json:"repair"`
+	Checks     []*VerifyCheckReport `json:"checks"`
+}
+
+// Clean reports whether no check found an issue.
+func (r *VerifyReport) Clean() bool {
+	for _, c := range r.Checks {
+		if len(c.Issues) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// VerifyCheckReport is one check's part of a run.
+type VerifyCheckReport struct {
+	Check string `json:"check"`
+	// Compared counts the subjects compared: heights, addresses, tokens
+	// or pillars.
+	Compared int `json:"compared"`
+	// Issues are the differences found, as recorded in
+	// consistency_issues.
+	Issues []*VerifyIssue `json:"issues,omitempty"`
+	// RepairsQueued counts the issues a repair was queued for.
+	RepairsQueued int `json:"repairs_queued"`
+}
+
+// VerifyIssue is one difference between the database and the node.
+type VerifyIssue struct {
+	Subject   string `json:"subject"`
+	Kind      string `json:"kind"`
+	DBValue   string `json:"db_value,omitempty"`
+	NodeValue string `json:"node_value,omitempty"`
+}
+
+// VerifyConfig schedules the indexer's periodic sampled verification.
+// The zero value leaves it off.
+type VerifyConfig struct {
+	// Enabled runs the job alongside live sync.
+	Enabled bool
+	// Interval is the time between runs. Default 1h.
+	Interval time.Duration
+	// Samples is how many heights and addresses per check each run
+	// compares. Default DefaultVerifySamples.
+	Samples int
+	// Checks restricts the job to these checks. Empty runs them all.
+	Checks []string
+	// Repair queues a fix for every issue found.
+	Repair bool
+}
+
+// withDefaults fills unset fields with the documented defaults.
+func (c VerifyConfig) withDefaults() VerifyConfig {
+	if c.Interval <= 0 {
+		c.Interval = time.Hour
+	}
+	if c.Samples <= 0 {
+		c.Samples = DefaultVerifySamples
+	}
+	return c
+}
+
+// ConfigureVerify enables or tunes the periodic verification job. Call
+// before Run.
+func (i *Indexer) ConfigureVerify(cfg VerifyConfig) {
+	i.verifyCfg = cfg
+}
+
+// verifyCheck compares one kind of indexed data with the node. A subject
+// is what an issue is recorded against: a height, an address, a token
+// standard or a pillar owner.
+type verifyCheck struct {
+	name string
+	// scan calls compare with each batch of subjects the run covers. A
+	// nil batch stands for every subject either side has.
+	scan func(ctx context.Context, opts VerifyOptions, compare func(subjects []string) error) error
+	// db and node render the given subjects on their side, or every
+	// subject the side has when subjects is nil, and leave out the ones
+	// the side does not have.
+	db, node func(ctx context.Context, subjects []string) (map[string]string, error)
+	// repair queues fixes for issues and returns the subjects it queued
+	// one for. Failures are logged: the issue stays open either way.
+	repair func(ctx context.Context, issues []*models.ConsistencyIssue) []string
+}
+
+// consistencyStore is the part of ConsistencyRepository the verifier
+// writes through, so tests can run it against memory.
+type consistencyStore interface {
+	Report(ctx context.Context, check string, found []*models.ConsistencyIssue, clean []string, now int64) error
+	MarkRepairQueued(ctx context.Context, check, subject string, now int64) error
+}
+
+// verifier runs checks over a resolved height range. Like
+// failedHeightRetrier, its node and database access is injected.
+type verifier struct {
+	opts   VerifyOptions
+	logger *zap.Logger
+	checks []verifyCheck
+	store  consistencyStore
+	now    func() time.Time
+}
+
+// run runs every check in turn and returns the combined report. An
+// error leaves the batches compared before it recorded.
+func (v *verifier) run(ctx context.Context) (*VerifyReport, error) {
+	report := &VerifyReport{From: v.opts.From, To: v.opts.To, Exhaustive: v.opts.Exhaustive, Repair: v.opts.Repair}
+	for _, c := range v.checks {
+		rep := &VerifyCheckReport{Check: c.name}
+		report.Checks = append(report.Checks, rep)
+		err := c.scan(ctx, v.opts, func(subjects []string) error {
+			return v.compare(ctx, c, rep, subjects)
+		})
+		if err != nil {
+			return report, fmt.Errorf("verify %s: %w", c.name, err)
+		}
+		v.logger.Info("verify: check done",
+			zap.String("check", c.name),
+			zap.Int("compared", rep.Compared),
+			zap.Int("issues", len(rep.Issues)),
+			zap.Int("repairs_queued", rep.RepairsQueued))
+	}
+	return report, nil
+}
+
+// compare reads one batch on both sides, records the differences and
+// the subjects that match, and in repair mode queues the fixes.
+func (v *verifier) compare(ctx context.Context, c verifyCheck, rep *VerifyCheckReport, subjects []string) error {
+	found, clean, err := v.diff(ctx, c, subjects)
+	if err != nil {
+		return err
+	}
+	// A block indexed between the two reads makes the sides differ for a
+	// moment; only a difference that survives a second read counts.
+	if len(found) > 0 {
+		recheck := make([]string, len(found))
+		for j, f := range found {
+			recheck[j] = f.Subject
+		}
+		var settled []string
+		if found, settled, err = v.diff(ctx, c, recheck); err != nil {
+			return err
+		}
+		clean = append(clean, settled...)
+	}
+
+	now := v.now().Unix()
+	if err := v.store.Report(ctx, c.name, found, clean, now); err != nil {
+		return err
+	}
+	rep.Compared += len(found) + len(clean)
+	for _, f := range found {
+		v.logger.Warn("verify: database differs from node",
+			zap.String("check", c.name),
+			zap.String("subject", f.Subject),
+			zap.String("kind", f.Kind),
+			zap.String("db", f.DBValue),
+			zap.String("node", f.NodeValue))
+		rep.Issues = append(rep.Issues, &VerifyIssue{Subject: f.Subject, Kind: f.Kind, DBValue: f.DBValue, NodeValue: f.NodeValue})
+	}
+	if !v.opts.Repair || len(found) == 0 || c.repair == nil {
+		return nil
+	}
+	for _, subject := range c.repair(ctx, found) {
+		if err := v.store.MarkRepairQueued(ctx, c.name, subject, now); err != nil {
+			return err
+		}
+		rep.RepairsQueued++
+	}
+	return nil
+}
+
+// diff reads subjects on both sides and splits them into issues and
+// matching subjects.
+func (v *verifier) diff(ctx context.Context, c verifyCheck, subjects []string) (found []*models.ConsistencyIssue, clean []string, err error) {
+	db, err := c.db(ctx, subjects)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read database: %w", err)
+	}
+	node, err := c.node(ctx, subjects)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read node: %w", err)
+	}
+	found, clean = diffSides(c.name, db, node, subjects)
+	return found, clean, nil
+}
+
+// diffSides compares the renderings of subjects on the two sides, or of
+// every subject either side has when subjects is nil. A subject neither
+// side has matches.
+func diffSides(check string, db, node map[string]string, subjects []string) (found []*models.ConsistencyIssue, clean []string) {
+	if subjects == nil {
+		for s := range db {
+			subjects = append(subjects, s)
+		}
+		for s := range node {
+			if _, ok := db[s]; !ok {
+				subjects = append(subjects, s)
+			}
+		}
+		slices.Sort(subjects)
+	}
+	for _, s := range subjects {
+		d, inDB := db[s]
+		n, onNode := node[s]
+		issue := &models.ConsistencyIssue{CheckName: check, Subject: s, DBValue: d, NodeValue: n}
+		switch {
+		case inDB == onNode && d == n:
+			clean = append(clean, s)
+			continue
+		case !inDB:
+			issue.Kind = models.IssueMissingInDB
+		case !onNode:
+			issue.Kind = models.IssueMissingOnNode
+		default:
+			issue.Kind = models.IssueDiffers
+		}
+		found = append(found, issue)
+	}
+	return found, clean
+}
+
+// scanHeights feeds compare the heights to verify, ascending and
+// verifyBatch at a time: every height from opts.From through opts.To
+// when exhaustive, else opts.Samples distinct heights drawn at random.
+func scanHeights(ctx context.Context, opts VerifyOptions, compare func([]string) error) error {
+	from, to := opts.From, opts.To
+	if to < from {
+		return nil
+	}
+	var heights []uint64
+	if !opts.Exhaustive && uint64(opts.Samples) <= to-from {
+		seen := make(map[uint64]bool, opts.Samples)
+		for len(heights) < opts.Samples {
+			h := from + rand.Uint64N(to-from+1)
+			if !seen[h] {
+				seen[h] = true
+				heights = append(heights, h)
+			}
+		}
+		slices.Sort(heights)
+	}
+	next := func() []string {
+		var batch []string
+		if heights != nil {
+			n := min(verifyBatch, len(heights))
+			for _, h := range heights[:n] {
+				batch = append(batch, strconv.FormatUint(h, 10))
+			}
+			heights = heights[n:]
+			return batch
+		}
+		for ; from <= to && len(batch) < verifyBatch; from++ {
+			batch = append(batch, strconv.FormatUint(from, 10))
+			if from == to {
+				to = from - 1 // from++ would overflow at the top of the range
+				break
+			}
+		}
+		return batch
+	}
+	for batch := next(); len(batch) > 0; batch = next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := compare(batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanAll feeds compare a single nil batch: the whole table.
+func scanAll(_ context.Context, _ VerifyOptions, compare func([]string) error) error {
+	return compare(nil)
+}
+
+// scanAddresses returns a scan over source ("accounts", "stakes" or
+// "fusions"): every address, verifyBatch at a time, when exhaustive,
+// else a random sample.
+func (i *Indexer) scanAddresses(source string) func(context.Context, VerifyOptions, func([]string) error) error {
+	return func(ctx context.Context, opts VerifyOptions, compare func([]string) error) error {
+		if !opts.Exhaustive {
+			addrs, err := i.repos.Consistency.SampleAddresses(ctx, source, opts.Samples)
+			if err != nil {
+				return err
+			}
+			for len(addrs) > 0 {
+				n := min(verifyBatch, len(addrs))
+				if err := compare(addrs[:n]); err != nil {
+					return err
+				}
+				addrs = addrs[n:]
+			}
+			return nil
+		}
+		after := ""
+		for {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			addrs, err := i.repos.Consistency.Addresses(ctx, source, after, verifyBatch)
+			if err != nil {
+				return err
+			}
+			if len(addrs) == 0 {
+				return nil
+			}
+			if err := compare(addrs); err != nil {
+				return err
+			}
+			after = addrs[len(addrs)-1]
+		}
+	}
+}
+
+// Verify compares the indexed data with the node as opts describes,
+// records the differences in consistency_issues, resolves the issues of
+// subjects that match again, and in repair mode queues fixes.
+func (i *Indexer) Verify(ctx context.Context, opts VerifyOptions) (*VerifyReport, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("verify: %w", err)
+	}
+	opts.From = max(opts.From, 1)
+	if opts.To == 0 {
+		latest, err := i.repos.Momentum.GetLatestHeight(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("verify: latest indexed height: %w", err)
+		}
+		opts.To = latest
+	}
+	if opts.Samples == 0 {
+		opts.Samples = DefaultVerifySamples
+	}
+	names := opts.Checks
+	if len(names) == 0 {
+		names = VerifyChecks
+	}
+	var checks []verifyCheck
+	for _, c := range i.verifyChecks() {
+		if slices.Contains(names, c.name) {
+			checks = append(checks, c)
+		}
+	}
+
+	v := &verifier{
+		opts:   opts,
+		logger: i.logger,
+		checks: checks,
+		store:  i.repos.Consistency,
+		now:    time.Now,
+	}
+	report, err := v.run(ctx)
+	i.refreshConsistencyGauge(ctx)
+	return report, err
+}
+
+// verifyChecks builds every check over the indexer's database and
+// active node, in VerifyChecks order.
+func (i *Indexer) verifyChecks() []verifyCheck {
+	return []verifyCheck{
+		{name: "momentums", scan: scanHeights, db: i.dbMomentums, node: i.nodeMomentums, repair: i.requeueMomentums},
+		{name: "balances", scan: i.scanAddresses("accounts"), db: i.dbBalances, node: i.nodeBalances, repair: i.refreshBalances},
+		{name: "tokens", scan: scanAll, db: i.dbTokens, node: i.nodeTokens, repair: i.refreshTokens},
+		{name: "pillars", scan: scanAll, db: i.dbPillars, node: i.nodePillars, repair: i.refreshPillars},
+		{name: "stakes", scan: i.scanAddresses("stakes"), db: i.dbStakes, node: i.nodeStakes,
+			repair: i.requeueEntries("stakes", models.StakeAddress)},
+		{name: "fusions", scan: i.scanAddresses("fusions"), db: i.dbFusions, node: i.nodeFusions,
+			repair: i.requeueEntries("fusions", models.PlasmaAddress)},
+	}
+}
+
+// --- momentums: hash and account block count per height ---
+
+func momentumValue(hash string, blocks int) string {
+	return fmt.Sprintf("%s blocks=%d", hash, blocks)
+}
+
+// parseHeights parses height subjects, ascending.
+func parseHeights(subjects []string) ([]uint64, error) {
+	heights := make([]uint64, 0, len(subjects))
+	for _, s := range subjects {
+		h, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("height subject %q: %w", s, err)
+		}
+		heights = append(heights, h)
+	}
+	slices.Sort(heights)
+	return heights, nil
+}
+
+func (i *Indexer) dbMomentums(ctx context.Context, subjects []string) (map[string]string, error) {
+	heights, err := parseHeights(subjects)
+	if err != nil {
+		return nil, err
+	}
+	hashes, blocks, err := i.repos.Consistency.StoredMomentums(ctx, heights)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]string, len(hashes))
+	for h, hash := range hashes {
+		out[strconv.FormatUint(h, 10)] = momentumValue(hash, blocks[h])
+	}
+	return out, nil
+}
+
+func (i *Indexer) nodeMomentums(ctx context.Context, subjects []string) (map[string]string, error) {
+	heights, err := parseHeights(subjects)
+	if err != nil {
+		return nil, err
+	}
+	want := make(map[uint64]bool, len(heights))
+	for _, h := range heights {
+		want[h] = true
+	}
+	out := make(map[string]string, len(heights))
+	for _, r := range heightRuns(slices.Compact(heights)) {
+		for start := r[0]; start <= r[1]; start += catchUpPageSize {
+			count := min(catchUpPageSize, r[1]-start+1)
+			list, err := i.fetchMomentumPage(ctx, start, count, false)
+			if err != nil {
+				return nil, err
+			}
+			for _, m := range list {
+				if want[m.Height] {
+					out[strconv.FormatUint(m.Height, 10)] = momentumValue(m.Hash.String(), len(m.Content))
+				}
+			}
+			if r[1]-start < catchUpPageSize {
+				break // start += catchUpPageSize would overflow at the top of the range
+			}
+		}
+	}
+	return out, nil
+}
+
+// requeueMomentums queues each height for the retrier to reprocess.
+func (i *Indexer) requeueMomentums(ctx context.Context, issues []*models.ConsistencyIssue) []string {
+	var queued []string
+	for _, f := range issues {
+		h, err := strconv.ParseUint(f.Subject, 10, 64)
+		if err != nil {
+			continue
+		}
+		if err := i.requeueHeight(ctx, h, f); err != nil {
+			i.logger.Warn("verify: requeue height failed", zap.Uint64("height", h), zap.Error(err))
+			continue
+		}
+		queued = append(queued, f.Subject)
+	}
+	return queued
+}
+
+// requeueHeight queues height for a reprocess on behalf of issue.
+func (i *Indexer) requeueHeight(ctx context.Context, height uint64, issue *models.ConsistencyIssue) error {
+	reason := fmt.Sprintf("verify %s %s: %s (db %q, node %q)",
+		issue.CheckName, issue.Subject, issue.Kind, issue.DBValue, issue.NodeValue)
+	return i.repos.FailedHeight.Requeue(ctx, height, reason, time.Now().Unix())
+}
+
+// --- balances: every non-zero token balance per address ---
+
+// balancesValue renders an address's non-zero balances as
+// "zts=amount" pairs, sorted by token.
+func balancesValue(balances map[string]*big.Int) string {
+	var parts []string
+	for zts, b := range balances {
+		if b != nil && b.Sign() != 0 {
+			parts = append(parts, zts+"="+b.String())
+		}
+	}
+	slices.Sort(parts)
+	return strings.Join(parts, ",")
+}
+
+func (i *Indexer) dbBalances(ctx context.Context, subjects []string) (map[string]string, error) {
+	out := make(map[string]string, len(subjects))
+	for _, a := range subjects {
+		rows, err := i.repos.Balance.ListByAddress(ctx, a)
+		if err != nil {
+			return nil, err
+		}
+		balances := make(map[string]*big.Int, len(rows))
+		for _, b := range rows {
+			balances[b.TokenStandard] = b.Balance
+		}
+		out[a] = balancesValue(balances)
+	}
+	return out, nil
+}
+
+// nodeAccountInfo reads address's account info from the active node.
+func (i *Indexer) nodeAccountInfo(ctx context.Context, address string) (*api.AccountInfo, error) {
+	addr, err := types.ParseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	var info *api.AccountInfo
+	err = withRetry(ctx, i.logger, "GetAccountInfoByAddress", func() error {
+		info, err = i.client().LedgerApi.GetAccountInfoByAddress(addr)
+		return err
+	})
+	return info, err
+}
+
+// nodeBalanceMap flattens info's balances, keyed by token standard.
+func nodeBalanceMap(info *api.AccountInfo) map[string]*big.Int {
+	out := map[string]*big.Int{}
+	if info == nil {
+		return out
+	}
+	for zts, b := range info.BalanceInfoMap {
+		if b != nil {
+			out[zts.String()] = b.Balance
+		}
+	}
+	return out
+}
+
+// nodeBalances renders the node's balances of subjects. An address whose
+// chain on the node is not at its indexed height has a block on its way
+// to the index, or one the index is still catching up to, so it cannot
+// be compared: it is rendered as the database has it and matches for
+// this run.
+func (i *Indexer) nodeBalances(ctx context.Context, subjects []string) (map[string]string, error) {
+	heights, err := i.repos.Consistency.AccountHeights(ctx, subjects)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]string, len(subjects))
+	var moving []string
+	for _, a := range subjects {
+		info, err := i.nodeAccountInfo(ctx, a)
+		if err != nil {
+			return nil, fmt.Errorf("account %s: %w", a, err)
+		}
+		if info != nil && info.AccountHeight != heights[a] {
+			moving = append(moving, a)
+			continue
+		}
+		out[a] = balancesValue(nodeBalanceMap(info))
+	}
+	if len(moving) > 0 {
+		db, err := i.dbBalances(ctx, moving)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range moving {
+			out[a] = db[a]
+		}
+	}
+	return out, nil
+}
+
+// refreshBalances rewrites each address's balances from the node,
+// zeroing the tokens the node no longer lists. An address whose chain
+// has moved since it was compared is left for the next run.
+func (i *Indexer) refreshBalances(ctx context.Context, issues []*models.ConsistencyIssue) []string {
+	var queued []string
+	for _, f := range issues {
+		if err := i.refreshAddressBalances(ctx, f.Subject); err != nil {
+			i.logger.Warn("verify: balance refresh failed", zap.String("address", f.Subject), zap.Error(err))
+			continue
+		}
+		queued = append(queued, f.Subject)
+	}
+	return queued
+}
+
+func (i *Indexer) refreshAddressBalances(ctx context.Context, address string) error {
+	info, err := i.nodeAccountInfo(ctx, address)
+	if err != nil {
+		return err
+	}
+	if info == nil {
+		return fmt.Errorf("no account info")
+	}
+	heights, err := i.repos.Consistency.AccountHeights(ctx, []string{address})
+	if err != nil {
+		return err
+	}
+	if info.AccountHeight != heights[address] {
+		return fmt.Errorf("account height %d on node, %d indexed", info.AccountHeight, heights[address])
+	}
+	stored, err := i.repos.Balance.ListByAddress(ctx, address)
+	if err != nil {
+		return err
+	}
+	balances := nodeBalanceMap(info)
+	for _, b := range stored {
+		if _, ok := balances[b.TokenStandard]; !ok {
+			balances[b.TokenStandard] = new(big.Int)
+		}
+	}
+	now := time.Now().Unix()
+	batch := &pgx.Batch{}
+	for zts, b := range balances {
+		if b == nil || b.Sign() < 0 {
+			continue
+		}
+		i.repos.Balance.UpsertAtHeightBatch(batch, &models.Balance{
+			Address:              address,
+			TokenStandard:        zts,
+			Balance:              b,
+			LastUpdatedTimestamp: now,
+		}, info.AccountHeight)
+	}
+	if batch.Len() == 0 {
+		return nil
+	}
+	return i.execBatchTx(ctx, batch)
+}
+
+// --- tokens: total supply per token ---
+
+// wanted returns a filter for subjects; nil subjects keeps everything.
+func wanted(subjects []string) func(string) bool {
+	if subjects == nil {
+		return func(string) bool { return true }
+	}
+	set := make(map[string]bool, len(subjects))
+	for _, s := range subjects {
+		set[s] = true
+	}
+	return func(s string) bool { return set[s] }
+}
+
+func (i *Indexer) dbTokens(ctx context.Context, subjects []string) (map[string]string, error) {
+	tokens, err := i.repos.Token.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keep, out := wanted(subjects), map[string]string{}
+	for _, t := range tokens {
+		if keep(t.TokenStandard) {
+			out[t.TokenStandard] = amountString(t.TotalSupply)
+		}
+	}
+	return out, nil
+}
+
+func (i *Indexer) nodeTokens(ctx context.Context, subjects []string) (map[string]string, error) {
+	keep, out := wanted(subjects), map[string]string{}
+	for page := uint32(0); ; page++ {
+		var n int
+		err := withRetry(ctx, i.logger, "TokenApi.GetAll", func() error {
+			list, err := i.client().TokenApi.GetAll(page, verifyEntriesPage)
+			if err != nil {
+				return err
+			}
+			n = len(list.List)
+			for _, t := range list.List {
+				if zts := t.TokenStandard.String(); keep(zts) {
+					out[zts] = amountString(t.TotalSupply)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if n < verifyEntriesPage {
+			return out, nil
+		}
+	}
+}
+
+// refreshTokens rewrites each token's supply from the node. A token the
+// node does not know is left alone.
+func (i *Indexer) refreshTokens(ctx context.Context, issues []*models.ConsistencyIssue) []string {
+	var queued []string
+	for _, f := range issues {
+		if f.Kind == models.IssueMissingOnNode {
+			continue
+		}
+		if err := i.refreshToken(ctx, f.Subject); err != nil {
+			i.logger.Warn("verify: token refresh failed", zap.String("token", f.Subject), zap.Error(err))
+			continue
+		}
+		queued = append(queued, f.Subject)
+	}
+	return queued
+}
+
+func (i *Indexer) refreshToken(ctx context.Context, standard string) error {
+	zts, err := types.ParseZTS(standard)
+	if err != nil {
+		return err
+	}
+	return withRetry(ctx, i.logger, "TokenApi.GetByZts", func() error {
+		t, err := i.client().TokenApi.GetByZts(zts)
+		if err != nil {
+			return err
+		}
+		if t == nil {
+			return errors.New("node has no such token")
+		}
+		return i.repos.Token.Upsert(ctx, &models.Token{
+			TokenStandard: t.TokenStandard.String(),
+			Name:          t.Name,
+			Symbol:        t.Symbol,
+			Domain:        t.Domain,
+			Decimals:      int(t.Decimals),
+			Owner:         t.Owner.String(),
+			TotalSupply:   t.TotalSupply,
+			MaxSupply:     t.MaxSupply,
+			IsBurnable:    t.IsBurnable,
+			IsMintable:    t.IsMintable,
+			IsUtility:     t.IsUtility,
+		})
+	})
+}
+
+// --- pillars: weight per active pillar owner ---
+
+func (i *Indexer) dbPillars(ctx context.Context, subjects []string) (map[string]string, error) {
+	pillars, err := i.repos.Pillar.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keep, out := wanted(subjects), map[string]string{}
+	for _, p := range pillars {
+		if keep(p.OwnerAddress) {
+			out[p.OwnerAddress] = strconv.FormatInt(p.Weight, 10)
+		}
+	}
+	return out, nil
+}
+
+func (i *Indexer) nodePillars(ctx context.Context, subjects []string) (map[string]string, error) {
+	keep, out := wanted(subjects), map[string]string{}
+	err := withRetry(ctx, i.logger, "PillarApi.GetAll", func() error {
+		// The pillar cache reads the same single page.
+		list, err := i.client().PillarApi.GetAll(0, 200)
+		if err != nil {
+			return err
+		}
+		for _, p := range list.List {
+			if owner := p.OwnerAddress.String(); keep(owner) {
+				out[owner] = amountString(p.Weight)
+			}
+		}
+		return nil
+	})
+	return out, err
+}
+
+// refreshPillars re-reads the pillar set once, which rewrites every
+// pillar's weight. A pillar only the database lists is left alone.
+func (i *Indexer) refreshPillars(ctx context.Context, issues []*models.ConsistencyIssue) []string {
+	if err := i.updatePillarCache(ctx); err != nil {
+		i.logger.Warn("verify: pillar refresh failed", zap.Error(err))
+		return nil
+	}
+	var queued []string
+	for _, f := range issues {
+		if f.Kind != models.IssueMissingOnNode {
+			queued = append(queued, f.Subject)
+		}
+	}
+	return queued
+}
+
+// --- stakes and fusions: active entries per address ---
+
+// entriesValue renders an address's entries, sorted.
+func entriesValue(entries []string) string {
+	slices.Sort(entries)
+	return strings.Join(entries, ",")
+}
+
+// changedEntryIDs returns the ids of the entries that appear on only one
+// side of an entries issue, or differ between the sides.
+func changedEntryIDs(dbValue, nodeValue string) []string {
+	split := func(v string) map[string]bool {
+		out := map[string]bool{}
+		for _, e := range strings.Split(v, ",") {
+			if e != "" {
+				out[e] = true
+			}
+		}
+		return out
+	}
+	db, node := split(dbValue), split(nodeValue)
+	var ids []string
+	for _, side := range []struct{ this, other map[string]bool }{{db, node}, {node, db}} {
+		for e := range side.this {
+			if !side.other[e] {
+				id, _, _ := strings.Cut(e, ":")
+				ids = append(ids, id)
+			}
+		}
+	}
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
+
+func stakeEntry(id string, amount *big.Int) string {
+	return id + ":" + amountString(amount)
+}
+
+func fusionEntry(id string, qsr *big.Int, beneficiary string) string {
+	return id + ":" + amountString(qsr) + ":" + beneficiary
+}
+
+// verifyEntryLimit bounds one address's stored entries read back; far
+// above what one address holds.
+var verifyEntryLimit = repository.ListOpts{Limit: 10_000}
+
+func (i *Indexer) dbStakes(ctx context.Context, subjects []string) (map[string]string, error) {
+	out := make(map[string]string, len(subjects))
+	for _, a := range subjects {
+		stakes, _, err := i.repos.Stake.ListByAddress(ctx, a, true, verifyEntryLimit)
+		if err != nil {
+			return nil, err
+		}
+		entries := make([]string, 0, len(stakes))
+		for _, s := range stakes {
+			entries = append(entries, stakeEntry(s.ID, s.ZnnAmount))
+		}
+		out[a] = entriesValue(entries)
+	}
+	return out, nil
+}
+
+func (i *Indexer) nodeStakes(ctx context.Context, subjects []string) (map[string]string, error) {
+	out := make(map[string]string, len(subjects))
+	for _, a := range subjects {
+		addr, err := types.ParseAddress(a)
+		if err != nil {
+			return nil, err
+		}
+		var entries []string
+		for page := uint32(0); ; page++ {
+			var n int
+			err := withRetry(ctx, i.logger, "StakeApi.GetEntriesByAddress", func() error {
+				list, err := i.client().StakeApi.GetEntriesByAddress(addr, page, verifyEntriesPage)
+				if err != nil {
+					return err
+				}
+				n = len(list.List)
+				for _, s := range list.List {
+					entries = append(entries, stakeEntry(s.Id.String(), s.Amount))
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("stakes of %s: %w", a, err)
+			}
+			if n < verifyEntriesPage {
+				break
+			}
+		}
+		out[a] = entriesValue(entries)
+	}
+	return out, nil
+}
+
+func (i *Indexer) dbFusions(ctx context.Context, subjects []string) (map[string]string, error) {
+	out := make(map[string]string, len(subjects))
+	for _, a := range subjects {
+		fusions, _, err := i.repos.Fusion.ListByAddress(ctx, a, true, verifyEntryLimit)
+		if err != nil {
+			return nil, err
+		}
+		entries := make([]string, 0, len(fusions))
+		for _, f := range fusions {
+			// ListByAddress also returns the fusions a fuses to; the node
+			// lists only the ones a made.
+			if f.Address == a {
+				entries = append(entries, fusionEntry(f.ID, f.QsrAmount, f.Beneficiary))
+			}
+		}
+		out[a] = entriesValue(entries)
+	}
+	return out, nil
+}
+
+func (i *Indexer) nodeFusions(ctx context.Context, subjects []string) (map[string]string, error) {
+	out := make(map[string]string, len(subjects))
+	for _, a := range subjects {
+		addr, err := types.ParseAddress(a)
+		if err != nil {
+			return nil, err
+		}
+		var entries []string
+		for page := uint32(0); ; page++ {
+			var n int
+			err := withRetry(ctx, i.logger, "PlasmaApi.GetEntriesByAddress", func() error {
+				list, err := i.client().PlasmaApi.GetEntriesByAddress(addr, page, verifyEntriesPage)
+				if err != nil {
+					return err
+				}
+				n = len(list.List)
+				for _, f := range list.List {
+					entries = append(entries, fusionEntry(f.Id.String(), f.QsrAmount, f.Beneficiary.String()))
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("fusions of %s: %w", a, err)
+			}
+			if n < verifyEntriesPage {
+				break
+			}
+		}
+		out[a] = entriesValue(entries)
+	}
+	return out, nil
+}
+
+// requeueEntries returns a repair that queues, for every entry that
+// differs, the heights of the stored contract calls that open and cancel
+// it. An entry with no stored calls needs its heights backfilled first.
+func (i *Indexer) requeueEntries(check, contract string) func(context.Context, []*models.ConsistencyIssue) []string {
+	return func(ctx context.Context, issues []*models.ConsistencyIssue) []string {
+		var queued []string
+		for _, f := range issues {
+			requeued := false
+			for _, id := range changedEntryIDs(f.DBValue, f.NodeValue) {
+				heights, err := i.repos.Consistency.ContractCallHeights(ctx, contract, id)
+				if err != nil {
+					i.logger.Warn("verify: entry heights lookup failed", zap.String("check", check), zap.String("id", id), zap.Error(err))
+					continue
+				}
+				if len(heights) == 0 {
+					i.logger.Warn("verify: no stored calls for entry; backfill its heights first",
+						zap.String("check", check), zap.String("address", f.Subject), zap.String("id", id))
+					continue
+				}
+				for _, h := range heights {
+					if err := i.requeueHeight(ctx, h, f); err != nil {
+						i.logger.Warn("verify: requeue height failed", zap.Uint64("height", h), zap.Error(err))
+						continue
+					}
+					requeued = true
+				}
+			}
+			if requeued {
+				queued = append(queued, f.Subject)
+			}
+		}
+		return queued
+	}
+}
+
+// refreshConsistencyGauge publishes the open issue count of every check.
+func (i *Indexer) refreshConsistencyGauge(ctx context.Context) {
+	if i.metrics == nil {
+		return
+	}
+	counts, err := i.repos.Consistency.OpenCounts(ctx)
+	if err != nil {
+		i.logger.Warn("verify: open issue count failed", zap.Error(err))
+		return
+	}
+	for _, c := range VerifyChecks {
+		i.metrics.SetConsistencyIssues(c, counts[c])
+	}
+}
+
+// verifyLag returns how many momentums the indexed head trails the
+// node's frontier by.
+func (i *Indexer) verifyLag(ctx context.Context) (uint64, error) {
+	latest, err := i.repos.Momentum.GetLatestHeight(ctx)
+	if err != nil {
+		return 0, err
+	}
+	frontier, err := i.client().LedgerApi.GetFrontierMomentum()
+	if err != nil {
+		return 0, err
+	}
+	if frontier.Height <= latest {
+		return 0, nil
+	}
+	return frontier.Height - latest, nil
+}
+
+// runVerifyLoop runs a sampled verification every interval until ctx is
+// canceled, skipping a run while the indexer is catching up.
+func (i *Indexer) runVerifyLoop(ctx context.Context) {
+	cfg := i.verifyCfg.withDefaults()
+	i.logger.Info("starting verify job",
+		zap.Duration("interval", cfg.Interval),
+		zap.Int("samples", cfg.Samples),
+		zap.Strings("checks", cfg.Checks),
+		zap.Bool("repair", cfg.Repair))
+
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			i.logger.Info("verify job stopped")
+			return
+		case <-ticker.C:
+			lag, err := i.verifyLag(ctx)
+			if err != nil {
+				i.logger.Warn("verify: lag check failed, skipping run", zap.Error(err))
+				continue
+			}
+			if lag > verifyMaxLag {
+				i.logger.Info("verify: indexer catching up, skipping run", zap.Uint64("lag", lag))
+				continue
+			}
+			report, err := i.Verify(ctx, VerifyOptions{Checks: cfg.Checks, Samples: cfg.Samples, Repair: cfg.Repair})
+			if err != nil {
+				if ctx.Err() == nil {
+					i.logger.Warn("verify: run failed", zap.Error(err))
+				}
+				continue
+			}
+			issues := 0
+			for _, c := range report.Checks {
+				issues += len(c.Issues)
+			}
+			i.logger.Info("verify: run complete", zap.Int("issues", issues))
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"reflect"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+func TestVerifyOptionsValidate(t *testing.T) {
+	for _, tc := range []struct {
+		opts    VerifyOptions
+		wantErr bool
+	}{
+		{VerifyOptions{}, false},
+		{VerifyOptions{From: 5, To: 10, Checks: []string{"momentums", "stakes"}, Samples: 10}, false},
+		{VerifyOptions{From: 10, To: 5}, true},
+		{VerifyOptions{Checks: []string{"balances", "bogus"}}, true},
+		{VerifyOptions{Samples: -1}, true},
+	} {
+		if err := tc.opts.Validate(); (err != nil) != tc.wantErr {
+			t.Errorf("Validate(%+v) = %v, want error %v", tc.opts, err, tc.wantErr)
+		}
+	}
+}
+
+func TestDiffSides(t *testing.T) {
+	db := map[string]string{"same": "1", "changed": "1", "dbonly": "1"}
+	node := map[string]string{"same": "1", "changed": "2", "nodeonly": "1"}
+
+	found, clean := diffSides("c", db, node, []string{"same", "changed", "dbonly", "nodeonly", "neither"})
+	kinds := map[string]string{}
+	for _, f := range found {
+		kinds[f.Subject] = f.Kind
+	}
+	wantKinds := map[string]string{
+		"changed":  models.IssueDiffers,
+		"dbonly":   models.IssueMissingOnNode,
+		"nodeonly": models.IssueMissingInDB,
+	}
+	if !reflect.DeepEqual(kinds, wantKinds) {
+		t.Errorf("kinds = %v, want %v", kinds, wantKinds)
+	}
+	if want := []string{"same", "neither"}; !reflect.DeepEqual(clean, want) {
+		t.Errorf("clean = %v, want %v", clean, want)
+	}
+
+	// nil subjects compares the union of both sides.
+	found, clean = diffSides("c", db, node, nil)
+	if len(found) != 3 || !reflect.DeepEqual(clean, []string{"same"}) {
+		t.Errorf("whole-table diff: found %d, clean %v", len(found), clean)
+	}
+}
+
+// memConsistency is an in-memory consistencyStore.
+type memConsistency struct {
+	open   map[string]*models.ConsistencyIssue
+	clean  []string
+	marked []string
+}
+
+func (m *memConsistency) Report(_ context.Context, check string, found []*models.ConsistencyIssue, clean []string, now int64) error {
+	if m.open == nil {
+		m.open = map[string]*models.ConsistencyIssue{}
+	}
+	for _, f := range found {
+		if prev, ok := m.open[check+"/"+f.Subject]; ok {
+			prev.Occurrences++
+			continue
+		}
+		c := *f
+		c.Occurrences, c.FirstSeenAt = 1, now
+		m.open[check+"/"+f.Subject] = &c
+	}
+	for _, s := range clean {
+		delete(m.open, check+"/"+s)
+		m.clean = append(m.clean, check+"/"+s)
+	}
+	return nil
+}
+
+func (m *memConsistency) MarkRepairQueued(_ context.Context, check, subject string, _ int64) error {
+	m.marked = append(m.marked, check+"/"+subject)
+	return nil
+}
+
+// flakyCheck serves a node side in which "drift" differs once, as a
+// block indexed between the two reads would, and "bad" always differs.
+func flakyCheck(repaired *[]string) verifyCheck {
+	nodeReads := 0
+	return verifyCheck{
+		name: "fake",
+		scan: func(_ context.Context, _ VerifyOptions, compare func([]string) error) error {
+			return compare([]string{"ok", "drift", "bad"})
+		},
+		db: func(_ context.Context, subjects []string) (map[string]string, error) {
+			out := map[string]string{}
+			for _, s := range subjects {
+				out[s] = "v"
+			}
+			return out, nil
+		},
+		node: func(_ context.Context, subjects []string) (map[string]string, error) {
+			nodeReads++
+			out := map[string]string{}
+			for _, s := range subjects {
+				out[s] = "v"
+				if s == "bad" || (s == "drift" && nodeReads == 1) {
+					out[s] = "w"
+				}
+			}
+			return out, nil
+		},
+		repair: func(_ context.Context, issues []*models.ConsistencyIssue) []string {
+			for _, f := range issues {
+				*repaired = append(*repaired, f.Subject)
+			}
+			return []string{issues[0].Subject}
+		},
+	}
+}
+
+func newTestVerifier(opts VerifyOptions, store consistencyStore, checks ...verifyCheck) *verifier {
+	return &verifier{
+		opts:   opts,
+		logger: zap.NewNop(),
+		checks: checks,
+		store:  store,
+		now:    func() time.Time { return time.Unix(1000, 0) },
+	}
+}
+
+func TestVerifier_ConfirmsBeforeRecording(t *testing.T) {
+	store := &memConsistency{}
+	var repaired []string
+	report, err := newTestVerifier(VerifyOptions{}, store, flakyCheck(&repaired)).run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep := report.Checks[0]
+	if rep.Compared != 3 || len(rep.Issues) != 1 || rep.Issues[0].Subject != "bad" {
+		t.Errorf("report = %+v, want 3 compared and only bad differing", rep)
+	}
+	if report.Clean() {
+		t.Error("Clean() = true with an issue")
+	}
+	if _, ok := store.open["fake/bad"]; !ok || len(store.open) != 1 {
+		t.Errorf("open issues = %v, want fake/bad", store.open)
+	}
+	// The difference that went away on the second read counts as clean.
+	slices.Sort(store.clean)
+	if want := []string{"fake/drift", "fake/ok"}; !reflect.DeepEqual(store.clean, want) {
+		t.Errorf("clean = %v, want %v", store.clean, want)
+	}
+	if len(repaired) != 0 || rep.RepairsQueued != 0 || len(store.marked) != 0 {
+		t.Errorf("repaired without repair mode: %v", repaired)
+	}
+}
+
+func TestVerifier_RepairMarksQueuedIssues(t *testing.T) {
+	store := &memConsistency{}
+	var repaired []string
+	report, err := newTestVerifier(VerifyOptions{Repair: true}, store, flakyCheck(&repaired)).run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(repaired, []string{"bad"}) {
+		t.Errorf("repaired = %v, want [bad]", repaired)
+	}
+	if report.Checks[0].RepairsQueued != 1 || !reflect.DeepEqual(store.marked, []string{"fake/bad"}) {
+		t.Errorf("repairs = %d, marked = %v", report.Checks[0].RepairsQueued, store.marked)
+	}
+}
+
+func TestVerifier_StopsOnError(t *testing.T) {
+	boom := errors.New("boom")
+	failing := verifyCheck{
+		name: "failing",
+		scan: scanAll,
+		db:   func(context.Context, []string) (map[string]string, error) { return nil, boom },
+	}
+	report, err := newTestVerifier(VerifyOptions{}, &memConsistency{}, failing).run(context.Background())
+	if !errors.Is(err, boom) || report == nil {
+		t.Errorf("run = %v, %v; want the check's error and a partial report", report, err)
+	}
+}
+
+func collectHeights(t *testing.T, opts VerifyOptions) (batches int, heights []uint64) {
+	t.Helper()
+	err := scanHeights(context.Background(), opts, func(batch []string) error {
+		batches++
+		for _, s := range batch {
+			h, err := strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				return err
+			}
+			heights = append(heights, h)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return batches, heights
+}
+
+func TestScanHeights(t *testing.T) {
+	// Exhaustive covers the whole range in verifyBatch-sized batches.
+	batches, heights := collectHeights(t, VerifyOptions{From: 1, To: 1200, Exhaustive: true})
+	if batches != 3 || len(heights) != 1200 || heights[0] != 1 || heights[1199] != 1200 {
+		t.Errorf("exhaustive: %d batches, %d heights", batches, len(heights))
+	}
+
+	// Sampled draws distinct heights in range, ascending.
+	_, heights = collectHeights(t, VerifyOptions{From: 100, To: 10_000, Samples: 50})
+	if len(heights) != 50 || !slices.IsSorted(heights) || len(slices.Compact(slices.Clone(heights))) != 50 {
+		t.Errorf("sampled heights = %v", heights)
+	}
+	if heights[0] < 100 || heights[49] > 10_000 {
+		t.Errorf("sampled heights out of range: %v", heights)
+	}
+
+	// A range no bigger than the sample is compared whole.
+	_, heights = collectHeights(t, VerifyOptions{From: 5, To: 9, Samples: 50})
+	if want := []uint64{5, 6, 7, 8, 9}; !reflect.DeepEqual(heights, want) {
+		t.Errorf("small range = %v, want %v", heights, want)
+	}
+
+	const top = ^uint64(0)
+	_, heights = collectHeights(t, VerifyOptions{From: top - 1, To: top, Exhaustive: true})
+	if want := []uint64{top - 1, top}; !reflect.DeepEqual(heights, want) {
+		t.Errorf("top of range = %v, want %v", heights, want)
+	}
+}
+
+func TestBalancesValue(t *testing.T) {
+	got := balancesValue(map[string]*big.Int{
+		"zts1b": big.NewInt(5),
+		"zts1a": big.NewInt(10),
+		"zts1z": new(big.Int),
+		"zts1n": nil,
+	})
+	if got != "zts1a=10,zts1b=5" {
+		t.Errorf("balancesValue = %q", got)
+	}
+}
+
+func TestChangedEntryIDs(t *testing.T) {
+	db := entriesValue([]string{stakeEntry("a", big.NewInt(1)), stakeEntry("b", big.NewInt(2)), stakeEntry("c", big.NewInt(3))})
+	node := entriesValue([]string{stakeEntry("a", big.NewInt(1)), stakeEntry("b", big.NewInt(5)), stakeEntry("d", big.NewInt(4))})
+	if got, want := changedEntryIDs(db, node), []string{"b", "c", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("changedEntryIDs = %v, want %v", got, want)
+	}
+	if got := changedEntryIDs("", fusionEntry("f", big.NewInt(1), "z1qb")); !reflect.DeepEqual(got, []string{"f"}) {
+		t.Errorf("changedEntryIDs from empty = %v", got)
+	}
+}
+
+func TestVerifyConfigDefaults(t *testing.T) {
+	cfg := VerifyConfig{}.withDefaults()
+	if cfg.Interval != time.Hour || cfg.Samples != DefaultVerifySamples {
+		t.Errorf("defaults = %+v", cfg)
+	}
+	cfg = VerifyConfig{Interval: time.Minute, Samples: 5}.withDefaults()
+	if cfg.Interval != time.Minute || cfg.Samples != 5 {
+		t.Errorf("overrides lost: %+v", cfg)
+	}
+}
@@ -69,17 +69,15 @@ func TestGetStakeCancelID_InvalidHashReturnsInput(t *testing.T) {
 	}
 }
 
-// TestGetPillarOwnerAddress confirms the read-lock path returns mapped owners
-// and empty for unknown names.
+// TestGetPillarOwnerAddress confirms names without history resolve through
+// the node's current map, and empty for unknown names.
 func TestGetPillarOwnerAddress(t *testing.T) {
-	i := &Indexer{
-		logger:            zap.NewNop(),
-		pillarNameToOwner: map[string]string{"alphanet-1": "z1qowner1"},
-	}
-	if got := i.getPillarOwnerAddress("alphanet-1"); got != "z1qowner1" {
+	i := &Indexer{logger: zap.NewNop()}
+	i.pillarNames.SetCurrent(map[string]string{"alphanet-1": "z1qowner1"})
+	if got := i.getPillarOwnerAddress("alphanet-1", 1); got != "z1qowner1" {
 		t.Errorf("expected mapped owner, got %q", got)
 	}
-	if got := i.getPillarOwnerAddress("missing"); got != "" {
+	if got := i.getPillarOwnerAddress("missing", 1); got != "" {
 		t.Errorf("expected empty for missing, got %q", got)
 	}
 }
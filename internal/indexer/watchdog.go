@@ -396,6 +396,13 @@ func (i *Indexer) runWatchdogTick(ctx context.Context, cCfg classifyConfig, rCfg
 		i.signalSubscriptionRestart()
 	}
 
+	// Re-evaluate catch-up read fan-out membership. Eligibility uses the
+	// same head and chain checks as failover, but only decides where reads
+	// go; it never moves streaks.
+	if i.reads != nil {
+		i.refreshReadRotation(ctx, activeIdx, probe, probeErr, chainID, cCfg)
+	}
+
 	i.publishSyncStatus(ctx, probe, probeErr, dbHeight, class, activeIdx, now)
 }
 
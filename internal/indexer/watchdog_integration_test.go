@@ -107,7 +107,6 @@ func newIntegrationIndexer(t *testing.T, nodes []*fakeNode, unhealthy, failback
 		pool:              pool,
 		repos:             repository.NewRepositories(pool),
 		logger:            zap.NewNop(),
-		pillarNameToOwner: make(map[string]string),
 		restartSubCh:      make(chan struct{}, 1),
 		nodePool:          nodePool,
 		syncStateInternal: newSyncState(nodePool.Len()),
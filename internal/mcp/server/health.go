@@ -12,10 +12,12 @@ import (
 )
 
 // minSchemaVersion is the lowest golang-migrate version the MCP server
-// can serve against. Mirrors the REST API's gate (router.minSchemaVersion)
-// because both processes read the same tables. Bump this in the same PR
-// that adds a migration the MCP server depends on.
-const minSchemaVersion = 12
+// can serve against. Tracks the REST API's gate (router.minSchemaVersion)
+// for the tables both processes read; it stays behind when a migration
+// only touches API-only tables (019 through 021, webhooks; 023, failed
+// heights). Bump this in the same PR that adds a migration the MCP server
+// depends on.
+const minSchemaVersion = 36 // bumped from 33 — list_liquidity_admin_actions reads liquidity_admin_actions
 
 // Healthz reports that the process is alive. Always 200; no DB ping.
 // Use as the k8s liveness probe.
@@ -6,6 +6,7 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	"github.com/0x3639/nom-indexer-go/internal/api/dto"
+	"github.com/0x3639/nom-indexer-go/internal/models"
 	"github.com/0x3639/nom-indexer-go/internal/repository"
 )
 
@@ -16,6 +17,23 @@ type AddressParams struct {
 	Address string `json:"address" jsonschema:"Zenon address (z1q...). Required."`
 }
 
+// ListAccountBalancesParams targets an address, optionally as of a
+// momentum height.
+type ListAccountBalancesParams struct {
+	AddressParams
+	AtHeight uint64 `json:"at_height,omitempty" jsonschema:"Return the balances after this momentum height instead of the current ones."`
+}
+
+// BalanceHistoryParams targets an address AND paginates its balance
+// changes, with optional token and height-range filters.
+type BalanceHistoryParams struct {
+	AddressParams
+	pageParams
+	Token string `json:"token,omitempty" jsonschema:"Only changes of this token standard (zts1...)."`
+	From  uint64 `json:"from,omitempty" jsonschema:"Lowest momentum height, inclusive."`
+	To    uint64 `json:"to,omitempty" jsonschema:"Highest momentum height, inclusive."`
+}
+
 func registerAccounts(srv *mcp.Server, repos *repository.Repositories) {
 	mcp.AddTool(srv, &mcp.Tool{
 		Name: "get_account",
@@ -30,8 +48,18 @@ func registerAccounts(srv *mcp.Server, repos *repository.Repositories) {
 		Name: "list_account_balances",
 		Description: "Return every (token_standard, balance) row for the given address, " +
 			"sorted by balance descending. Not paginated — accounts typically hold a handful " +
-			"of tokens. Empty result is returned as {data: []} (not an error).",
+			"of tokens. Pass at_height for the balances after that momentum; tokens the " +
+			"address held none of then are left out. Empty result is returned as {data: []} " +
+			"(not an error).",
 	}, listAccountBalances(repos))
+
+	mcp.AddTool(srv, &mcp.Tool{
+		Name: "get_balance_history",
+		Description: "An address's balance history, newest first: one row per momentum that " +
+			"changed one of its token balances, with the balance after the momentum and the " +
+			"signed change (delta). Optional token filter and from/to momentum-height range, " +
+			"both inclusive. Amounts ship as strings.",
+	}, getBalanceHistory(repos))
 }
 
 func getAccount(repos *repository.Repositories) func(context.Context, *mcp.CallToolRequest, *AddressParams) (*mcp.CallToolResult, any, error) {
@@ -51,12 +79,33 @@ type listAccountBalancesResult struct {
 	Data []*dto.Balance `json:"data"`
 }
 
-func listAccountBalances(repos *repository.Repositories) func(context.Context, *mcp.CallToolRequest, *AddressParams) (*mcp.CallToolResult, any, error) {
-	return func(ctx context.Context, _ *mcp.CallToolRequest, p *AddressParams) (*mcp.CallToolResult, any, error) {
-		rows, err := repos.Balance.ListByAddress(ctx, p.Address)
+func listAccountBalances(repos *repository.Repositories) func(context.Context, *mcp.CallToolRequest, *ListAccountBalancesParams) (*mcp.CallToolResult, any, error) {
+	return func(ctx context.Context, _ *mcp.CallToolRequest, p *ListAccountBalancesParams) (*mcp.CallToolResult, any, error) {
+		var (
+			rows []*models.Balance
+			err  error
+		)
+		if p.AtHeight > 0 {
+			rows, err = repos.Balance.ListByAddressAtHeight(ctx, p.Address, p.AtHeight)
+		} else {
+			rows, err = repos.Balance.ListByAddress(ctx, p.Address)
+		}
 		if err != nil {
 			return nil, nil, err
 		}
 		return jsonResult(&listAccountBalancesResult{Data: dto.FromBalances(rows)})
 	}
 }
+
+func getBalanceHistory(repos *repository.Repositories) func(context.Context, *mcp.CallToolRequest, *BalanceHistoryParams) (*mcp.CallToolResult, any, error) {
+	return func(ctx context.Context, _ *mcp.CallToolRequest, p *BalanceHistoryParams) (*mcp.CallToolResult, any, error) {
+		page := pagination(p.pageParams)
+		rows, total, err := repos.Balance.History(ctx, p.Address, repository.BalanceHistoryFilter{
+			TokenStandard: p.Token, FromHeight: p.From, ToHeight: p.To,
+		}, repository.ListOpts{Limit: page.PageSize, Offset: page.Offset()})
+		if err != nil {
+			return nil, nil, err
+		}
+		return jsonResult(dto.NewPage(dto.FromBalanceChanges(rows), page.Page, page.PageSize, total))
+	}
+}
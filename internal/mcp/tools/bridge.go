@@ -17,6 +17,14 @@ type ListAccountBridgeParams struct {
 	pageParams
 }
 
+// ListBridgeEventsParams paginates bridge contract calls with optional
+// method and caller filters.
+type ListBridgeEventsParams struct {
+	pageParams
+	Method  string `json:"method,omitempty" jsonschema:"Only calls of this ABI method, e.g. WrapToken, Redeem, Halt, SetTokenPair."`
+	Address string `json:"address,omitempty" jsonschema:"Only calls made by this z1 address."`
+}
+
 func registerBridge(srv *mcp.Server, repos *repository.Repositories) {
 	mcp.AddTool(srv, &mcp.Tool{
 		Name: "list_bridge_wraps",
@@ -41,6 +49,31 @@ func registerBridge(srv *mcp.Server, repos *repository.Repositories) {
 		Description: "List unwraps whose `to_address` (Zenon destination) matches the " +
 			"given address.",
 	}, listAccountBridgeUnwraps(repos))
+
+	mcp.AddTool(srv, &mcp.Tool{
+		Name: "list_bridge_events",
+		Description: "List every call into the Bridge contract decoded from the ledger — " +
+			"WrapToken, UnwrapToken, Redeem and administrator/guardian actions such as Halt, " +
+			"SetNetwork, SetTokenPair, ChangeAdministrator — ordered by momentum_height DESC. " +
+			"Each has the caller, the amount sent and the decoded inputs. Filter by method " +
+			"and/or address. Rejected calls are included.",
+	}, listBridgeEvents(repos))
+}
+
+func listBridgeEvents(repos *repository.Repositories) func(context.Context, *mcp.CallToolRequest, *ListBridgeEventsParams) (*mcp.CallToolResult, any, error) {
+	return func(ctx context.Context, _ *mcp.CallToolRequest, p *ListBridgeEventsParams) (*mcp.CallToolResult, any, error) {
+		page := pagination(p.pageParams)
+		rows, total, err := repos.BridgeEvent.List(ctx, repository.BridgeEventFilter{
+			Method: p.Method, Address: p.Address,
+		}, repository.ListOpts{
+			Limit:  page.PageSize,
+			Offset: page.Offset(),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return jsonResult(dto.NewPage(dto.FromBridgeEvents(rows), page.Page, page.PageSize, total))
+	}
 }
 
 func listBridgeWraps(repos *repository.Repositories) func(context.Context, *mcp.CallToolRequest, *ListMomentumsParams) (*mcp.CallToolResult, any, error) {
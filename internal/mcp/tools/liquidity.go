@@ -0,0 +1,117 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/0x3639/nom-indexer-go/internal/api/dto"
+	"github.com/0x3639/nom-indexer-go/internal/repository"
+)
+
+// ListLiquidityStakesParams paginates liquidity stake entries with an
+// optional include-inactive toggle.
+type ListLiquidityStakesParams struct {
+	pageParams
+	IncludeInactive bool `json:"include_inactive,omitempty" jsonschema:"Include canceled liquidity stakes (default false)."`
+}
+
+// ListAccountLiquidityStakesParams scopes the liquidity stakes list to one
+// address.
+type ListAccountLiquidityStakesParams struct {
+	AddressParams
+	pageParams
+	IncludeInactive bool `json:"include_inactive,omitempty"`
+}
+
+// ListLiquidityAdminActionsParams paginates the Liquidity administrator's
+// and guardians' calls with an optional method filter.
+type ListLiquidityAdminActionsParams struct {
+	pageParams
+	Method string `json:"method,omitempty" jsonschema:"Only calls of this ABI method, e.g. SetTokenTuple, SetAdditionalReward, SetIsHalted, ChangeAdministrator, NominateGuardians."`
+}
+
+func registerLiquidity(srv *mcp.Server, repos *repository.Repositories) {
+	mcp.AddTool(srv, &mcp.Tool{
+		Name: "get_liquidity_config",
+		Description: "Return the liquidity program's configuration as last synced from the " +
+			"node: administrator, is_halted, the extra znn_reward/qsr_reward per epoch, the " +
+			"accepted LP token_tuples (reward shares out of 10000 and min_amount) and the " +
+			"guardians. Errors until the first sync.",
+	}, getLiquidityConfig(repos))
+
+	mcp.AddTool(srv, &mcp.Tool{
+		Name: "list_liquidity_admin_actions",
+		Description: "List the administrator's and guardians' calls into the Liquidity contract " +
+			"decoded from the ledger — SetTokenTuple, SetAdditionalReward, SetIsHalted, " +
+			"ChangeAdministrator, NominateGuardians, ProposeAdministrator, Emergency, Fund, " +
+			"BurnZnn, UnlockLiquidityStakeEntries — ordered by momentum_height DESC, with the " +
+			"caller and decoded inputs. The history behind get_liquidity_config; calls still " +
+			"in their time challenge or rejected are included. Filter by method.",
+	}, listLiquidityAdminActions(repos))
+
+	mcp.AddTool(srv, &mcp.Tool{
+		Name: "list_liquidity_stakes",
+		Description: "List liquidity (LP token) stake entries ordered by " +
+			"creation_momentum_height DESC. weighted_amount is amount times whole 30-day " +
+			"months locked. Active only by default; include_inactive=true also returns " +
+			"canceled entries. Amounts ship as strings.",
+	}, listLiquidityStakes(repos))
+
+	mcp.AddTool(srv, &mcp.Tool{
+		Name:        "list_account_liquidity_stakes",
+		Description: "Same as list_liquidity_stakes but scoped to a single staker address.",
+	}, listAccountLiquidityStakes(repos))
+}
+
+func getLiquidityConfig(repos *repository.Repositories) func(context.Context, *mcp.CallToolRequest, *struct{}) (*mcp.CallToolResult, any, error) {
+	return func(ctx context.Context, _ *mcp.CallToolRequest, _ *struct{}) (*mcp.CallToolResult, any, error) {
+		c, err := repos.Liquidity.GetConfig(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		return jsonResult(dto.FromLiquidityConfig(c))
+	}
+}
+
+func listLiquidityAdminActions(repos *repository.Repositories) func(context.Context, *mcp.CallToolRequest, *ListLiquidityAdminActionsParams) (*mcp.CallToolResult, any, error) {
+	return func(ctx context.Context, _ *mcp.CallToolRequest, p *ListLiquidityAdminActionsParams) (*mcp.CallToolResult, any, error) {
+		page := pagination(p.pageParams)
+		rows, total, err := repos.Liquidity.ListAdminActions(ctx, p.Method, repository.ListOpts{
+			Limit:  page.PageSize,
+			Offset: page.Offset(),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return jsonResult(dto.NewPage(dto.FromLiquidityAdminActions(rows), page.Page, page.PageSize, total))
+	}
+}
+
+func listLiquidityStakes(repos *repository.Repositories) func(context.Context, *mcp.CallToolRequest, *ListLiquidityStakesParams) (*mcp.CallToolResult, any, error) {
+	return func(ctx context.Context, _ *mcp.CallToolRequest, p *ListLiquidityStakesParams) (*mcp.CallToolResult, any, error) {
+		page := pagination(p.pageParams)
+		rows, total, err := repos.Liquidity.ListStakes(ctx, "", !p.IncludeInactive, repository.ListOpts{
+			Limit:  page.PageSize,
+			Offset: page.Offset(),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return jsonResult(dto.NewPage(dto.FromLiquidityStakes(rows), page.Page, page.PageSize, total))
+	}
+}
+
+func listAccountLiquidityStakes(repos *repository.Repositories) func(context.Context, *mcp.CallToolRequest, *ListAccountLiquidityStakesParams) (*mcp.CallToolResult, any, error) {
+	return func(ctx context.Context, _ *mcp.CallToolRequest, p *ListAccountLiquidityStakesParams) (*mcp.CallToolResult, any, error) {
+		page := pagination(p.pageParams)
+		rows, total, err := repos.Liquidity.ListStakes(ctx, p.Address, !p.IncludeInactive, repository.ListOpts{
+			Limit:  page.PageSize,
+			Offset: page.Offset(),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return jsonResult(dto.NewPage(dto.FromLiquidityStakes(rows), page.Page, page.PageSize, total))
+	}
+}
@@ -20,6 +20,25 @@ type ListProjectVotesParams struct {
 	pageParams
 }
 
+// ProjectHistoryParams targets a project AND paginates its status history.
+type ProjectHistoryParams struct {
+	ProjectIDParams
+	pageParams
+}
+
+// AcceleratorTreasuryParams paginates the treasury balance points.
+type AcceleratorTreasuryParams struct {
+	pageParams
+}
+
+// ListAcceleratorPayoutsParams paginates phase payouts with optional
+// project and receiver filters.
+type ListAcceleratorPayoutsParams struct {
+	pageParams
+	ProjectID string `json:"project_id,omitempty" jsonschema:"Only payouts of this Accelerator-Z project ID."`
+	Address   string `json:"address,omitempty" jsonschema:"Only payouts sent to this z1 address (the project owner)."`
+}
+
 func registerProjects(srv *mcp.Server, repos *repository.Repositories) {
 	mcp.AddTool(srv, &mcp.Tool{
 		Name: "list_projects",
@@ -55,6 +74,29 @@ func registerProjects(srv *mcp.Server, repos *repository.Repositories) {
 			"the LLM does not have to enumerate pillars × proposals client-side. Phases " +
 			"are returned in creation order. Pillar lists are name-only and alphabetized.",
 	}, getProjectVotingReport(repos))
+
+	mcp.AddTool(srv, &mcp.Tool{
+		Name: "get_project_history",
+		Description: "Status history of one project and its phases, newest first, decoded " +
+			"from the ledger. Each row has old_status and new_status (0 voting, 1 active, " +
+			"2 paid, 3 closed, 4 completed; old_status is null where the project or phase " +
+			"was created), phase_id when the change is to a phase, the method and caller, " +
+			"and znn_amount/qsr_amount: the ask on creation rows, the payout on paid rows.",
+	}, getProjectHistory(repos))
+
+	mcp.AddTool(srv, &mcp.Tool{
+		Name: "list_accelerator_payouts",
+		Description: "List the ZNN and QSR sends that paid out accepted Accelerator-Z phases, " +
+			"ordered by momentum_height DESC. Optional project_id and address (receiver) " +
+			"filters. Amounts ship as strings.",
+	}, listAcceleratorPayouts(repos))
+
+	mcp.AddTool(srv, &mcp.Tool{
+		Name: "get_accelerator_treasury",
+		Description: "The Accelerator-Z contract's ZNN and QSR balance after every momentum " +
+			"that moved it, newest first, with the signed change in that momentum " +
+			"(znn_delta, qsr_delta). Summed from indexed account blocks. Amounts ship as strings.",
+	}, getAcceleratorTreasury(repos))
 }
 
 func listProjects(repos *repository.Repositories) func(context.Context, *mcp.CallToolRequest, *ListMomentumsParams) (*mcp.CallToolResult, any, error) {
@@ -120,6 +162,47 @@ func getProjectVotingReport(repos *repository.Repositories) func(context.Context
 	}
 }
 
+func getProjectHistory(repos *repository.Repositories) func(context.Context, *mcp.CallToolRequest, *ProjectHistoryParams) (*mcp.CallToolResult, any, error) {
+	return func(ctx context.Context, _ *mcp.CallToolRequest, p *ProjectHistoryParams) (*mcp.CallToolResult, any, error) {
+		page := pagination(p.pageParams)
+		rows, total, err := repos.Accelerator.History(ctx, p.ID, repository.ListOpts{
+			Limit:  page.PageSize,
+			Offset: page.Offset(),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return jsonResult(dto.NewPage(dto.FromProjectStatusChanges(rows), page.Page, page.PageSize, total))
+	}
+}
+
+func listAcceleratorPayouts(repos *repository.Repositories) func(context.Context, *mcp.CallToolRequest, *ListAcceleratorPayoutsParams) (*mcp.CallToolResult, any, error) {
+	return func(ctx context.Context, _ *mcp.CallToolRequest, p *ListAcceleratorPayoutsParams) (*mcp.CallToolResult, any, error) {
+		page := pagination(p.pageParams)
+		rows, total, err := repos.Accelerator.ListPayouts(ctx, repository.AcceleratorPayoutFilter{
+			ProjectID: p.ProjectID, ToAddress: p.Address,
+		}, repository.ListOpts{Limit: page.PageSize, Offset: page.Offset()})
+		if err != nil {
+			return nil, nil, err
+		}
+		return jsonResult(dto.NewPage(dto.FromAcceleratorPayouts(rows), page.Page, page.PageSize, total))
+	}
+}
+
+func getAcceleratorTreasury(repos *repository.Repositories) func(context.Context, *mcp.CallToolRequest, *AcceleratorTreasuryParams) (*mcp.CallToolResult, any, error) {
+	return func(ctx context.Context, _ *mcp.CallToolRequest, p *AcceleratorTreasuryParams) (*mcp.CallToolResult, any, error) {
+		page := pagination(p.pageParams)
+		rows, total, err := repos.Accelerator.Treasury(ctx, repository.ListOpts{
+			Limit:  page.PageSize,
+			Offset: page.Offset(),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return jsonResult(dto.NewPage(dto.FromAcceleratorTreasury(rows), page.Page, page.PageSize, total))
+	}
+}
+
 // projectVotingReportToDTO bridges the repository row type into the
 // public dto.ProjectVotingReport. The translation goes here rather
 // than in repo or dto: repo doesn't import dto, and dto doesn't
@@ -27,4 +27,6 @@ func Register(srv *mcp.Server, repos *repository.Repositories, version string) {
 	registerRewards(srv, repos)
 	registerProjects(srv, repos)
 	registerBridge(srv, repos)
+	registerSporks(srv, repos)
+	registerLiquidity(srv, repos)
 }
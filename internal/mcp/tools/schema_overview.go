@@ -61,10 +61,14 @@ func catalog() schemaOverview {
 				Tools: []string{"get_account"}},
 			{Name: "balances", Domain: "core_ledger", Purpose: "Current balance per (address, token).",
 				Tools: []string{"list_account_balances", "list_token_holders"}},
+			{Name: "balance_history", Domain: "core_ledger", Purpose: "Balance after each momentum that changed an (address, token) pair.",
+				Tools: []string{"get_balance_history", "list_account_balances"}},
 			{Name: "tokens", Domain: "core_ledger", Purpose: "ZTS token registry with current supply + holder/tx counts.",
 				Tools: []string{"list_tokens", "get_token"}},
 			{Name: "token_mints", Domain: "core_ledger", Purpose: "Every mint event as its own row."},
 			{Name: "token_burns", Domain: "core_ledger", Purpose: "Every burn event as its own row."},
+			{Name: "token_events", Domain: "core_ledger", Purpose: "Token issues and owner/flag changes with before and after values, from the ledger.",
+				Tools: []string{"get_token_history"}},
 
 			// Pillars + delegation
 			{Name: "pillars", Domain: "pillars", Purpose: "Current pillar registry.",
@@ -75,6 +79,8 @@ func catalog() schemaOverview {
 			// Sentinels / stakes / plasma
 			{Name: "sentinels", Domain: "sentinels_stakes_plasma", Purpose: "Sentinel node registrations.",
 				Tools: []string{"list_sentinels"}},
+			{Name: "sentinel_events", Domain: "sentinels_stakes_plasma", Purpose: "Sentinel deposits, registrations, revocations and reward collections, from the ledger.",
+				Tools: []string{"list_sentinel_events"}},
 			{Name: "stakes", Domain: "sentinels_stakes_plasma", Purpose: "Staking entries (with ABI-derived cancel_id).",
 				Tools: []string{"list_stakes", "list_account_stakes"}},
 			{Name: "fusions", Domain: "sentinels_stakes_plasma", Purpose: "Plasma fusion entries (with ABI-derived cancel_id).",
@@ -87,6 +93,10 @@ func catalog() schemaOverview {
 				Tools: []string{"list_project_phases"}},
 			{Name: "votes", Domain: "accelerator_z", Purpose: "Pillar votes on projects/phases.",
 				Tools: []string{"list_project_votes", "get_project_voting_report", "get_pillar_voting_history"}},
+			{Name: "project_status_changes", Domain: "accelerator_z", Purpose: "Project and phase status transitions, from the ledger.",
+				Tools: []string{"get_project_history"}},
+			{Name: "accelerator_payouts", Domain: "accelerator_z", Purpose: "ZNN and QSR sends paying out accepted phases.",
+				Tools: []string{"list_accelerator_payouts"}},
 
 			// Rewards
 			{Name: "reward_transactions", Domain: "rewards", Purpose: "Per-event reward receipts.",
@@ -99,6 +109,8 @@ func catalog() schemaOverview {
 				Tools: []string{"list_bridge_wraps", "list_account_bridge_wraps"}},
 			{Name: "unwrap_token_requests", Domain: "bridge", Purpose: "External-chain → ZTS unwrap intents.",
 				Tools: []string{"list_bridge_unwraps", "list_account_bridge_unwraps"}},
+			{Name: "bridge_events", Domain: "bridge", Purpose: "Every call into the Bridge contract, decoded from the ledger.",
+				Tools: []string{"list_bridge_events"}},
 			{Name: "bridge_networks", Domain: "bridge", Purpose: "Configured destination networks (paginated from BridgeApi)."},
 			{Name: "bridge_network_tokens", Domain: "bridge", Purpose: "Per-network token pair configuration (fees, min, redeem delay)."},
 			{Name: "bridge_admin", Domain: "bridge", Purpose: "Singleton with current administrator + halt state."},
@@ -106,6 +118,18 @@ func catalog() schemaOverview {
 			{Name: "bridge_orchestrator_info", Domain: "bridge", Purpose: "Singleton with orchestrator parameters."},
 			{Name: "bridge_security_info", Domain: "bridge", Purpose: "Singleton with security delay parameters."},
 
+			// Sporks
+			{Name: "sporks", Domain: "sporks", Purpose: "Protocol feature switches with activation status + enforcement height.",
+				Tools: []string{"list_sporks", "get_spork"}},
+
+			// Liquidity
+			{Name: "liquidity_stakes", Domain: "liquidity", Purpose: "LP token stake entries with weight, lock duration and revocation.",
+				Tools: []string{"list_liquidity_stakes", "list_account_liquidity_stakes"}},
+			{Name: "liquidity_config", Domain: "liquidity", Purpose: "Singleton with the liquidity program's rewards, token tuples and guardians.",
+				Tools: []string{"get_liquidity_config"}},
+			{Name: "liquidity_admin_actions", Domain: "liquidity", Purpose: "Every administrator and guardian call into the Liquidity contract, decoded from the ledger.",
+				Tools: []string{"list_liquidity_admin_actions"}},
+
 			// Daily snapshots
 			{Name: "network_stat_histories", Domain: "daily_snapshots", Purpose: "Daily network-wide totals + activity."},
 			{Name: "token_stat_histories", Domain: "daily_snapshots", Purpose: "Daily per-token mints/burns + carried state."},
@@ -113,7 +137,9 @@ func catalog() schemaOverview {
 			{Name: "bridge_stat_histories", Domain: "daily_snapshots", Purpose: "Daily per-(network, chain, token) wrap/unwrap volume."},
 		},
 		Notes: []string{
-			"All amounts/balances/supplies are int64 (BIGINT) — JSON-encoded as strings to dodge JavaScript Number precision loss.",
+			"Token amounts, balances and supplies are NUMERIC(78,0) (any uint256): account_blocks.amount, balances, token supplies/mints/burns, rewards, stakes, fusions, HTLCs, bridge requests/events, sentinel/accelerator/liquidity event amounts, balance_history, and the daily token/bridge sums. SUM() over them is NUMERIC; don't cast to bigint.",
+			"Columns that only ever hold ZNN/QSR stay BIGINT (int64): accounts flow and genesis balances, pillars.weight/slot_cost_qsr, project and phase funds, swap_* amounts, bridge_network_tokens.min_amount.",
+			"Both kinds are returned as JSON decimal strings in raw base units, not numbers, to avoid JavaScript Number precision loss.",
 			"All timestamps are Unix seconds (BIGINT).",
 			"All hashes and addresses are lowercase hex/zenon strings.",
 			"No foreign keys: joins are by hash/address/standard. The indexer writes per momentum in a transaction.",
@@ -16,6 +16,14 @@ type ListSentinelsParams struct {
 	IncludeInactive bool `json:"include_inactive,omitempty" jsonschema:"Include retired (inactive) sentinels (default false)."`
 }
 
+// ListSentinelEventsParams paginates sentinel lifecycle events with
+// optional owner and method filters.
+type ListSentinelEventsParams struct {
+	pageParams
+	Owner  string `json:"owner,omitempty" jsonschema:"Only this sentinel owner's events (z1 address)."`
+	Method string `json:"method,omitempty" jsonschema:"Only events of this method: DepositQsr, WithdrawQsr, Register, Revoke or CollectReward."`
+}
+
 func registerSentinels(srv *mcp.Server, repos *repository.Repositories) {
 	mcp.AddTool(srv, &mcp.Tool{
 		Name: "list_sentinels",
@@ -23,6 +31,15 @@ func registerSentinels(srv *mcp.Server, repos *repository.Repositories) {
 			"pillars) ordered by registration_timestamp DESC (newest first). Active " +
 			"sentinels only by default; pass include_inactive=true to include retired ones.",
 	}, listSentinels(repos))
+
+	mcp.AddTool(srv, &mcp.Tool{
+		Name: "list_sentinel_events",
+		Description: "List sentinel lifecycle events decoded from the ledger — DepositQsr, " +
+			"WithdrawQsr, Register, Revoke and CollectReward calls the Sentinel contract " +
+			"accepted — ordered by momentum_height DESC. Each has the ZNN and QSR amounts " +
+			"moved (collateral locked or returned, rewards collected) and " +
+			"registration_block_hash, the Register it belongs to. Filter by owner and/or method.",
+	}, listSentinelEvents(repos))
 }
 
 func listSentinels(repos *repository.Repositories) func(context.Context, *mcp.CallToolRequest, *ListSentinelsParams) (*mcp.CallToolResult, any, error) {
@@ -39,3 +56,19 @@ func listSentinels(repos *repository.Repositories) func(context.Context, *mcp.Ca
 		return jsonResult(dto.NewPage(dto.FromSentinels(rows), page.Page, page.PageSize, total))
 	}
 }
+
+func listSentinelEvents(repos *repository.Repositories) func(context.Context, *mcp.CallToolRequest, *ListSentinelEventsParams) (*mcp.CallToolResult, any, error) {
+	return func(ctx context.Context, _ *mcp.CallToolRequest, p *ListSentinelEventsParams) (*mcp.CallToolResult, any, error) {
+		page := pagination(p.pageParams)
+		rows, total, err := repos.SentinelEvent.List(ctx, repository.SentinelEventFilter{
+			Owner: p.Owner, Method: p.Method,
+		}, repository.ListOpts{
+			Limit:  page.PageSize,
+			Offset: page.Offset(),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return jsonResult(dto.NewPage(dto.FromSentinelEvents(rows), page.Page, page.PageSize, total))
+	}
+}
@@ -0,0 +1,62 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/0x3639/nom-indexer-go/internal/api/dto"
+	"github.com/0x3639/nom-indexer-go/internal/repository"
+)
+
+// ListSporksParams paginates the spork set with an optional
+// activated-only filter.
+type ListSporksParams struct {
+	pageParams
+	Activated bool `json:"activated,omitempty" jsonschema:"Only return activated sporks (default false)."`
+}
+
+// SporkIDParams targets one spork by ID.
+type SporkIDParams struct {
+	ID string `json:"id" jsonschema:"Spork ID: hash of the CreateSpork send block (64-char hex)."`
+}
+
+func registerSporks(srv *mcp.Server, repos *repository.Repositories) {
+	mcp.AddTool(srv, &mcp.Tool{
+		Name: "list_sporks",
+		Description: "List sporks (protocol feature switches of the Spork contract) ordered by " +
+			"creation_momentum_height DESC (newest first). Each has name, description, " +
+			"activated and enforcement_height, the momentum height the change takes effect " +
+			"at (0 until activated). Pass activated=true for activated sporks only.",
+	}, listSporks(repos))
+
+	mcp.AddTool(srv, &mcp.Tool{
+		Name: "get_spork",
+		Description: "Return one spork by its ID (the CreateSpork send-block hash), " +
+			"including activation status and enforcement_height.",
+	}, getSpork(repos))
+}
+
+func listSporks(repos *repository.Repositories) func(context.Context, *mcp.CallToolRequest, *ListSporksParams) (*mcp.CallToolResult, any, error) {
+	return func(ctx context.Context, _ *mcp.CallToolRequest, p *ListSporksParams) (*mcp.CallToolResult, any, error) {
+		page := pagination(p.pageParams)
+		rows, total, err := repos.Spork.List(ctx, p.Activated, repository.ListOpts{
+			Limit:  page.PageSize,
+			Offset: page.Offset(),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return jsonResult(dto.NewPage(dto.FromSporks(rows), page.Page, page.PageSize, total))
+	}
+}
+
+func getSpork(repos *repository.Repositories) func(context.Context, *mcp.CallToolRequest, *SporkIDParams) (*mcp.CallToolResult, any, error) {
+	return func(ctx context.Context, _ *mcp.CallToolRequest, p *SporkIDParams) (*mcp.CallToolResult, any, error) {
+		s, err := repos.Spork.GetByID(ctx, p.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		return jsonResult(dto.FromSpork(s))
+	}
+}
@@ -21,6 +21,12 @@ type TokenHoldersParams struct {
 	pageParams
 }
 
+// TokenHistoryParams targets a token AND paginates its event history.
+type TokenHistoryParams struct {
+	TokenStandardParams
+	pageParams
+}
+
 func registerTokens(srv *mcp.Server, repos *repository.Repositories) {
 	mcp.AddTool(srv, &mcp.Tool{
 		Name: "list_tokens",
@@ -42,6 +48,15 @@ func registerTokens(srv *mcp.Server, repos *repository.Repositories) {
 			"descending. Excludes zero balances (the underlying partial index filters " +
 			"them). Paginated; default page_size=50, max 200.",
 	}, listTokenHolders(repos))
+
+	mcp.AddTool(srv, &mcp.Tool{
+		Name: "get_token_history",
+		Description: "Who controlled a ZTS token when: its IssueToken and every UpdateToken " +
+			"the token contract accepted, ordered newest first. Each event has the owner " +
+			"and is_mintable/is_burnable flags before and after (the *_before fields are " +
+			"null for IssueToken), the caller, the momentum, and the decoded inputs — the " +
+			"issuance parameters for IssueToken.",
+	}, getTokenHistory(repos))
 }
 
 func listTokens(repos *repository.Repositories) func(context.Context, *mcp.CallToolRequest, *ListMomentumsParams) (*mcp.CallToolResult, any, error) {
@@ -85,3 +100,17 @@ func listTokenHolders(repos *repository.Repositories) func(context.Context, *mcp
 		return jsonResult(dto.NewPage(dto.FromBalances(rows), page.Page, page.PageSize, total))
 	}
 }
+
+func getTokenHistory(repos *repository.Repositories) func(context.Context, *mcp.CallToolRequest, *TokenHistoryParams) (*mcp.CallToolResult, any, error) {
+	return func(ctx context.Context, _ *mcp.CallToolRequest, p *TokenHistoryParams) (*mcp.CallToolResult, any, error) {
+		page := pagination(p.pageParams)
+		rows, total, err := repos.TokenEvent.History(ctx, p.TokenStandard, repository.ListOpts{
+			Limit:  page.PageSize,
+			Offset: page.Offset(),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return jsonResult(dto.NewPage(dto.FromTokenEvents(rows), page.Page, page.PageSize, total))
+	}
+}
@@ -28,6 +28,17 @@ const (
 	QsrTokenStandard   = "zts1qsrxxxxxxxxxxxxxmrhjll"
 )
 
+// Account block types as stored in account_blocks.block_type. The values
+// mirror the SDK's utils.BlockType* constants; they are repeated here so
+// SQL in internal/repository can filter by type without importing the SDK.
+const (
+	BlockTypeGenesisReceive  = 1
+	BlockTypeUserSend        = 2
+	BlockTypeUserReceive     = 3
+	BlockTypeContractSend    = 4
+	BlockTypeContractReceive = 5
+)
+
 // Genesis momentum timestamp (used to fetch first momentum)
 const GenesisMomentumTime = 1637755210
 
@@ -42,6 +53,32 @@ const MomentumBlockTimeSec = 10
 // number of momentums, given the chain's target block time.
 const FusionExpirationBlocks = FusionExpirationTime / MomentumBlockTimeSec
 
+// SporkMinHeightDelay is how many momentums after its activation's
+// frontier momentum a spork is enforced (go-zenon constants.SporkMinHeightDelay).
+const SporkMinHeightDelay = 6
+
+// LiquidityStakeTimeUnitSec is the liquidity stake period unit (30 days).
+// A stake's weighted amount is its amount times the number of whole units
+// it is locked for (go-zenon constants.StakeTimeUnitSec and
+// LiquidityStakeWeights).
+const LiquidityStakeTimeUnitSec = 30 * 24 * 60 * 60
+
+// LiquidityAdminMethods are the Liquidity contract methods reserved for
+// its administrator or guardians. Calls to them are recorded in
+// liquidity_admin_actions.
+var LiquidityAdminMethods = map[string]bool{
+	"Fund":                        true,
+	"BurnZnn":                     true,
+	"SetTokenTuple":               true,
+	"NominateGuardians":           true,
+	"ProposeAdministrator":        true,
+	"Emergency":                   true,
+	"SetIsHalted":                 true,
+	"UnlockLiquidityStakeEntries": true,
+	"SetAdditionalReward":         true,
+	"ChangeAdministrator":         true,
+}
+
 // EmbeddedContractAddresses returns all embedded contract addresses
 func EmbeddedContractAddresses() []string {
 	return []string{
@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"math/big"
 )
 
 // RewardType represents the type of reward
@@ -71,10 +72,21 @@ type Account struct {
 
 // Balance represents a token balance for an account
 type Balance struct {
-	Address              string `db:"address"`
-	TokenStandard        string `db:"token_standard"`
-	Balance              int64  `db:"balance"`
-	LastUpdatedTimestamp int64  `db:"last_updated_timestamp"`
+	Address              string   `db:"address"`
+	TokenStandard        string   `db:"token_standard"`
+	Balance              *big.Int `db:"balance"`
+	LastUpdatedTimestamp int64    `db:"last_updated_timestamp"`
+}
+
+// BalanceChange is an (address, token) balance after a momentum that
+// changed it, and the signed change in that momentum.
+type BalanceChange struct {
+	Address           string   `db:"address"`
+	TokenStandard     string   `db:"token_standard"`
+	MomentumHeight    int64    `db:"momentum_height"`
+	MomentumTimestamp int64    `db:"momentum_timestamp"`
+	Balance           *big.Int `db:"balance"`
+	Delta             *big.Int `db:"delta"`
 }
 
 // AccountBlock represents a transaction
@@ -87,7 +99,7 @@ type AccountBlock struct {
 	Height             int64           `db:"height"`
 	Address            string          `db:"address"`
 	ToAddress          string          `db:"to_address"`
-	Amount             int64           `db:"amount"`
+	Amount             *big.Int        `db:"amount"`
 	TokenStandard      string          `db:"token_standard"`
 	Data               string          `db:"data"`
 	Method             string          `db:"method"`
@@ -96,23 +108,30 @@ type AccountBlock struct {
 	DescendantOf       string          `db:"descendant_of"`
 }
 
+// BlockPair is a stored receive block with the send block it received,
+// read back from account_blocks to re-derive projections from them.
+type BlockPair struct {
+	Receive AccountBlock
+	Send    AccountBlock
+}
+
 // Token represents a ZTS token
 type Token struct {
-	TokenStandard       string `db:"token_standard"`
-	Name                string `db:"name"`
-	Symbol              string `db:"symbol"`
-	Domain              string `db:"domain"`
-	Decimals            int    `db:"decimals"`
-	Owner               string `db:"owner"`
-	TotalSupply         int64  `db:"total_supply"`
-	MaxSupply           int64  `db:"max_supply"`
-	IsBurnable          bool   `db:"is_burnable"`
-	IsMintable          bool   `db:"is_mintable"`
-	IsUtility           bool   `db:"is_utility"`
-	TotalBurned         int64  `db:"total_burned"`
-	LastUpdateTimestamp int64  `db:"last_update_timestamp"`
-	HolderCount         int64  `db:"holder_count"`
-	TransactionCount    int64  `db:"transaction_count"`
+	TokenStandard       string   `db:"token_standard"`
+	Name                string   `db:"name"`
+	Symbol              string   `db:"symbol"`
+	Domain              string   `db:"domain"`
+	Decimals            int      `db:"decimals"`
+	Owner               string   `db:"owner"`
+	TotalSupply         *big.Int `db:"total_supply"`
+	MaxSupply           *big.Int `db:"max_supply"`
+	IsBurnable          bool     `db:"is_burnable"`
+	IsMintable          bool     `db:"is_mintable"`
+	IsUtility           bool     `db:"is_utility"`
+	TotalBurned         *big.Int `db:"total_burned"`
+	LastUpdateTimestamp int64    `db:"last_update_timestamp"`
+	HolderCount         int64    `db:"holder_count"`
+	TransactionCount    int64    `db:"transaction_count"`
 }
 
 // Pillar represents a validator
@@ -137,9 +156,14 @@ type Pillar struct {
 	IsRevoked                    bool    `db:"is_revoked"`
 }
 
-// PillarUpdate represents a historical pillar configuration change
+// PillarUpdate represents a historical pillar configuration change.
+// Method is the pillar contract call that wrote the row; Revoke rows
+// carry only the name and owner. AccountBlockHash is the contract's
+// receive block, empty on rows written before it was recorded.
 type PillarUpdate struct {
 	ID                           int    `db:"id"`
+	AccountBlockHash             string `db:"account_block_hash"`
+	Method                       string `db:"method"`
 	Name                         string `db:"name"`
 	OwnerAddress                 string `db:"owner_address"`
 	ProducerAddress              string `db:"producer_address"`
@@ -162,14 +186,14 @@ type Sentinel struct {
 
 // Stake represents a staking entry
 type Stake struct {
-	ID                  string `db:"id"`
-	Address             string `db:"address"`
-	StartTimestamp      int64  `db:"start_timestamp"`
-	ExpirationTimestamp int64  `db:"expiration_timestamp"`
-	ZnnAmount           int64  `db:"znn_amount"`
-	DurationInSec       int    `db:"duration_in_sec"`
-	IsActive            bool   `db:"is_active"`
-	CancelID            string `db:"cancel_id"`
+	ID                  string   `db:"id"`
+	Address             string   `db:"address"`
+	StartTimestamp      int64    `db:"start_timestamp"`
+	ExpirationTimestamp int64    `db:"expiration_timestamp"`
+	ZnnAmount           *big.Int `db:"znn_amount"`
+	DurationInSec       int      `db:"duration_in_sec"`
+	IsActive            bool     `db:"is_active"`
+	CancelID            string   `db:"cancel_id"`
 }
 
 // Project represents an Accelerator-Z project
@@ -208,6 +232,61 @@ type ProjectPhase struct {
 	TotalVotes        int16  `db:"total_votes"`
 }
 
+// ProjectStatus is the Accelerator contract's status enum, shared by
+// projects and phases.
+type ProjectStatus int16
+
+const (
+	ProjectStatusVoting    ProjectStatus = 0
+	ProjectStatusActive    ProjectStatus = 1
+	ProjectStatusPaid      ProjectStatus = 2
+	ProjectStatusClosed    ProjectStatus = 3
+	ProjectStatusCompleted ProjectStatus = 4
+)
+
+// ProjectStatusChange is one status change of a project, or of one of
+// its phases when PhaseID is set. OldStatus is nil when the project or
+// phase was created.
+type ProjectStatusChange struct {
+	AccountBlockHash  string   `db:"account_block_hash"`
+	ProjectID         string   `db:"project_id"`
+	PhaseID           string   `db:"phase_id"`
+	VotingID          string   `db:"voting_id"`
+	Method            string   `db:"method"`
+	Address           string   `db:"address"`
+	OldStatus         *int16   `db:"old_status"`
+	NewStatus         int16    `db:"new_status"`
+	ZnnAmount         *big.Int `db:"znn_amount"`
+	QsrAmount         *big.Int `db:"qsr_amount"`
+	MomentumHeight    int64    `db:"momentum_height"`
+	MomentumTimestamp int64    `db:"momentum_timestamp"`
+}
+
+// AcceleratorPayout is one send of phase funds from the Accelerator
+// contract to a project owner.
+type AcceleratorPayout struct {
+	AccountBlockHash  string   `db:"account_block_hash"`
+	UpdateBlockHash   string   `db:"update_block_hash"`
+	ProjectID         string   `db:"project_id"`
+	PhaseID           string   `db:"phase_id"`
+	ToAddress         string   `db:"to_address"`
+	TokenStandard     string   `db:"token_standard"`
+	Amount            *big.Int `db:"amount"`
+	MomentumHeight    int64    `db:"momentum_height"`
+	MomentumTimestamp int64    `db:"momentum_timestamp"`
+}
+
+// AcceleratorTreasuryPoint is the Accelerator contract's ZNN and QSR
+// balance after a momentum that changed it.
+type AcceleratorTreasuryPoint struct {
+	MomentumHeight    int64    `db:"momentum_height"`
+	MomentumTimestamp int64    `db:"momentum_timestamp"`
+	ZnnBalance        *big.Int `db:"znn_balance"`
+	QsrBalance        *big.Int `db:"qsr_balance"`
+	ZnnDelta          *big.Int `db:"znn_delta"`
+	QsrDelta          *big.Int `db:"qsr_delta"`
+}
+
 // Vote represents a pillar vote on a project or phase
 type Vote struct {
 	ID                int    `db:"id"`
@@ -223,16 +302,16 @@ type Vote struct {
 
 // Fusion represents a plasma fusion entry
 type Fusion struct {
-	ID                string `db:"id"`
-	Address           string `db:"address"`
-	Beneficiary       string `db:"beneficiary"`
-	MomentumHash      string `db:"momentum_hash"`
-	MomentumTimestamp int64  `db:"momentum_timestamp"`
-	MomentumHeight    int64  `db:"momentum_height"`
-	QsrAmount         int64  `db:"qsr_amount"`
-	ExpirationHeight  int64  `db:"expiration_height"`
-	IsActive          bool   `db:"is_active"`
-	CancelID          string `db:"cancel_id"`
+	ID                string   `db:"id"`
+	Address           string   `db:"address"`
+	Beneficiary       string   `db:"beneficiary"`
+	MomentumHash      string   `db:"momentum_hash"`
+	MomentumTimestamp int64    `db:"momentum_timestamp"`
+	MomentumHeight    int64    `db:"momentum_height"`
+	QsrAmount         *big.Int `db:"qsr_amount"`
+	ExpirationHeight  int64    `db:"expiration_height"`
+	IsActive          bool     `db:"is_active"`
+	CancelID          string   `db:"cancel_id"`
 }
 
 // CumulativeReward represents cumulative rewards for an address
@@ -240,7 +319,7 @@ type CumulativeReward struct {
 	ID            int        `db:"id"`
 	Address       string     `db:"address"`
 	RewardType    RewardType `db:"reward_type"`
-	Amount        int64      `db:"amount"`
+	Amount        *big.Int   `db:"amount"`
 	TokenStandard string     `db:"token_standard"`
 }
 
@@ -252,7 +331,7 @@ type RewardTransaction struct {
 	MomentumTimestamp int64      `db:"momentum_timestamp"`
 	MomentumHeight    int64      `db:"momentum_height"`
 	AccountHeight     int64      `db:"account_height"`
-	Amount            int64      `db:"amount"`
+	Amount            *big.Int   `db:"amount"`
 	TokenStandard     string     `db:"token_standard"`
 	SourceAddress     string     `db:"source_address"`
 }
@@ -272,57 +351,116 @@ func NewTxData() *TxData {
 
 // WrapTokenRequest represents a request to wrap tokens from Zenon to an external chain
 type WrapTokenRequest struct {
-	ID                      string `db:"id"`
-	NetworkClass            int    `db:"network_class"`
-	ChainID                 int    `db:"chain_id"`
-	ToAddress               string `db:"to_address"`
-	TokenStandard           string `db:"token_standard"`
-	TokenAddress            string `db:"token_address"`
-	Amount                  int64  `db:"amount"`
-	Fee                     int64  `db:"fee"`
-	Signature               string `db:"signature"`
-	CreationMomentumHeight  int64  `db:"creation_momentum_height"`
-	ConfirmationsToFinality int    `db:"confirmations_to_finality"`
+	ID                      string   `db:"id"`
+	NetworkClass            int      `db:"network_class"`
+	ChainID                 int      `db:"chain_id"`
+	ToAddress               string   `db:"to_address"`
+	TokenStandard           string   `db:"token_standard"`
+	TokenAddress            string   `db:"token_address"`
+	Amount                  *big.Int `db:"amount"`
+	Fee                     *big.Int `db:"fee"`
+	Signature               string   `db:"signature"`
+	CreationMomentumHeight  int64    `db:"creation_momentum_height"`
+	ConfirmationsToFinality int      `db:"confirmations_to_finality"`
+}
+
+// Bridge request statuses, derived from the fields the bridge API
+// reports. They are what bridge.*.status_changed webhooks carry.
+const (
+	BridgeRequestPending   = "pending"
+	BridgeRequestSigned    = "signed"
+	BridgeRequestFinalized = "finalized"
+	BridgeRequestRedeemed  = "redeemed"
+	BridgeRequestRevoked   = "revoked"
+)
+
+// Status is pending until the orchestrator signs the request, then
+// signed until it has no confirmations left to finality.
+func (w *WrapTokenRequest) Status() string {
+	switch {
+	case w.Signature == "":
+		return BridgeRequestPending
+	case w.ConfirmationsToFinality > 0:
+		return BridgeRequestSigned
+	default:
+		return BridgeRequestFinalized
+	}
 }
 
 // UnwrapTokenRequest represents a request to unwrap tokens from an external chain to Zenon
 type UnwrapTokenRequest struct {
-	TransactionHash            string `db:"transaction_hash"`
-	LogIndex                   int64  `db:"log_index"`
-	NetworkClass               int    `db:"network_class"`
-	ChainID                    int    `db:"chain_id"`
-	ToAddress                  string `db:"to_address"`
-	TokenStandard              string `db:"token_standard"`
-	TokenAddress               string `db:"token_address"`
-	Amount                     int64  `db:"amount"`
-	Signature                  string `db:"signature"`
-	RegistrationMomentumHeight int64  `db:"registration_momentum_height"`
-	Redeemed                   bool   `db:"redeemed"`
-	Revoked                    bool   `db:"revoked"`
-	RedeemableIn               int64  `db:"redeemable_in"`
+	TransactionHash            string   `db:"transaction_hash"`
+	LogIndex                   int64    `db:"log_index"`
+	NetworkClass               int      `db:"network_class"`
+	ChainID                    int      `db:"chain_id"`
+	ToAddress                  string   `db:"to_address"`
+	TokenStandard              string   `db:"token_standard"`
+	TokenAddress               string   `db:"token_address"`
+	Amount                     *big.Int `db:"amount"`
+	Signature                  string   `db:"signature"`
+	RegistrationMomentumHeight int64    `db:"registration_momentum_height"`
+	Redeemed                   bool     `db:"redeemed"`
+	Revoked                    bool     `db:"revoked"`
+	RedeemableIn               int64    `db:"redeemable_in"`
+}
+
+// Status is revoked or redeemed once settled, otherwise signed or
+// pending depending on whether the orchestrator has signed it.
+func (u *UnwrapTokenRequest) Status() string {
+	switch {
+	case u.Revoked:
+		return BridgeRequestRevoked
+	case u.Redeemed:
+		return BridgeRequestRedeemed
+	case u.Signature != "":
+		return BridgeRequestSigned
+	default:
+		return BridgeRequestPending
+	}
 }
 
 // TokenMint is a single mint event on a token.
 type TokenMint struct {
-	ID                int64  `db:"id"`
-	AccountBlockHash  string `db:"account_block_hash"`
-	MomentumHeight    int64  `db:"momentum_height"`
-	MomentumTimestamp int64  `db:"momentum_timestamp"`
-	TokenStandard     string `db:"token_standard"`
-	Issuer            string `db:"issuer"`
-	Receiver          string `db:"receiver"`
-	Amount            int64  `db:"amount"`
+	ID                int64    `db:"id"`
+	AccountBlockHash  string   `db:"account_block_hash"`
+	MomentumHeight    int64    `db:"momentum_height"`
+	MomentumTimestamp int64    `db:"momentum_timestamp"`
+	TokenStandard     string   `db:"token_standard"`
+	Issuer            string   `db:"issuer"`
+	Receiver          string   `db:"receiver"`
+	Amount            *big.Int `db:"amount"`
+}
+
+// TokenEvent is an accepted IssueToken or UpdateToken call: who held the
+// token and which of its flags were set before and after. The *Before
+// fields are nil for IssueToken.
+type TokenEvent struct {
+	AccountBlockHash  string            `db:"account_block_hash"`
+	SendBlockHash     string            `db:"send_block_hash"`
+	TokenStandard     string            `db:"token_standard"`
+	Method            string            `db:"method"`
+	Address           string            `db:"address"`
+	AccountHeight     int64             `db:"account_height"`
+	OwnerBefore       *string           `db:"owner_before"`
+	OwnerAfter        string            `db:"owner_after"`
+	IsMintableBefore  *bool             `db:"is_mintable_before"`
+	IsMintableAfter   bool              `db:"is_mintable_after"`
+	IsBurnableBefore  *bool             `db:"is_burnable_before"`
+	IsBurnableAfter   bool              `db:"is_burnable_after"`
+	Inputs            map[string]string `db:"inputs"`
+	MomentumHeight    int64             `db:"momentum_height"`
+	MomentumTimestamp int64             `db:"momentum_timestamp"`
 }
 
 // TokenBurn is a single burn event on a token.
 type TokenBurn struct {
-	ID                int64  `db:"id"`
-	AccountBlockHash  string `db:"account_block_hash"`
-	MomentumHeight    int64  `db:"momentum_height"`
-	MomentumTimestamp int64  `db:"momentum_timestamp"`
-	TokenStandard     string `db:"token_standard"`
-	Burner            string `db:"burner"`
-	Amount            int64  `db:"amount"`
+	ID                int64    `db:"id"`
+	AccountBlockHash  string   `db:"account_block_hash"`
+	MomentumHeight    int64    `db:"momentum_height"`
+	MomentumTimestamp int64    `db:"momentum_timestamp"`
+	TokenStandard     string   `db:"token_standard"`
+	Burner            string   `db:"burner"`
+	Amount            *big.Int `db:"amount"`
 }
 
 // BridgeNetwork is one configured destination network on the Zenon bridge.
@@ -410,13 +548,13 @@ type NetworkStatHistory struct {
 
 // TokenStatHistory is a daily per-token snapshot row.
 type TokenStatHistory struct {
-	Date              string `db:"date"`
-	TokenStandard     string `db:"token_standard"`
-	DailyMinted       int64  `db:"daily_minted"`
-	DailyBurned       int64  `db:"daily_burned"`
-	TotalSupply       int64  `db:"total_supply"`
-	TotalHolders      int64  `db:"total_holders"`
-	TotalTransactions int64  `db:"total_transactions"`
+	Date              string   `db:"date"`
+	TokenStandard     string   `db:"token_standard"`
+	DailyMinted       *big.Int `db:"daily_minted"`
+	DailyBurned       *big.Int `db:"daily_burned"`
+	TotalSupply       *big.Int `db:"total_supply"`
+	TotalHolders      int64    `db:"total_holders"`
+	TotalTransactions int64    `db:"total_transactions"`
 }
 
 // PillarStatHistory is a daily per-pillar snapshot row.
@@ -432,15 +570,15 @@ type PillarStatHistory struct {
 
 // BridgeStatHistory is a daily per-(network, token) bridge snapshot row.
 type BridgeStatHistory struct {
-	Date            string `db:"date"`
-	NetworkClass    int    `db:"network_class"`
-	ChainID         int    `db:"chain_id"`
-	TokenStandard   string `db:"token_standard"`
-	WrapTxCount     int64  `db:"wrap_tx_count"`
-	WrappedAmount   int64  `db:"wrapped_amount"`
-	UnwrapTxCount   int64  `db:"unwrap_tx_count"`
-	UnwrappedAmount int64  `db:"unwrapped_amount"`
-	TotalVolume     int64  `db:"total_volume"`
+	Date            string   `db:"date"`
+	NetworkClass    int      `db:"network_class"`
+	ChainID         int      `db:"chain_id"`
+	TokenStandard   string   `db:"token_standard"`
+	WrapTxCount     int64    `db:"wrap_tx_count"`
+	WrappedAmount   *big.Int `db:"wrapped_amount"`
+	UnwrapTxCount   int64    `db:"unwrap_tx_count"`
+	UnwrappedAmount *big.Int `db:"unwrapped_amount"`
+	TotalVolume     *big.Int `db:"total_volume"`
 }
 
 // Delegation is one interval of an account delegating to a pillar.
@@ -451,6 +589,7 @@ type Delegation struct {
 	PillarOwnerAddress string `db:"pillar_owner_address"`
 	StartedAt          int64  `db:"started_at"`
 	EndedAt            *int64 `db:"ended_at"`
+	AccountBlockHash   string `db:"account_block_hash"` // pillar contract receive that opened it; empty before migration 035
 }
 
 // SyncStatus is the in-DB projection of the watchdog's last tick.
@@ -482,21 +621,21 @@ const (
 
 // Htlc represents a hash-time-locked contract entry.
 type Htlc struct {
-	ID                        string `db:"id"`
-	TimeLockedAddress         string `db:"time_locked_address"`
-	HashLockedAddress         string `db:"hash_locked_address"`
-	TokenStandard             string `db:"token_standard"`
-	Amount                    int64  `db:"amount"`
-	ExpirationTimestamp       int64  `db:"expiration_timestamp"`
-	HashType                  int16  `db:"hash_type"`
-	KeyMaxSize                int16  `db:"key_max_size"`
-	HashLock                  string `db:"hash_lock"`
-	Status                    int16  `db:"status"`
-	Preimage                  string `db:"preimage"`
-	CreationMomentumHeight    int64  `db:"creation_momentum_height"`
-	CreationMomentumTimestamp int64  `db:"creation_momentum_timestamp"`
-	SettleMomentumHeight      int64  `db:"settle_momentum_height"`
-	SettleMomentumTimestamp   int64  `db:"settle_momentum_timestamp"`
+	ID                        string   `db:"id"`
+	TimeLockedAddress         string   `db:"time_locked_address"`
+	HashLockedAddress         string   `db:"hash_locked_address"`
+	TokenStandard             string   `db:"token_standard"`
+	Amount                    *big.Int `db:"amount"`
+	ExpirationTimestamp       int64    `db:"expiration_timestamp"`
+	HashType                  int16    `db:"hash_type"`
+	KeyMaxSize                int16    `db:"key_max_size"`
+	HashLock                  string   `db:"hash_lock"`
+	Status                    int16    `db:"status"`
+	Preimage                  string   `db:"preimage"`
+	CreationMomentumHeight    int64    `db:"creation_momentum_height"`
+	CreationMomentumTimestamp int64    `db:"creation_momentum_timestamp"`
+	SettleMomentumHeight      int64    `db:"settle_momentum_height"`
+	SettleMomentumTimestamp   int64    `db:"settle_momentum_timestamp"`
 }
 
 // SwapRetrieval is one RetrieveAssets claim against the legacy genesis swap.
@@ -527,3 +666,256 @@ type BridgeTimeChallenge struct {
 	EndHeight            int64  `db:"end_height"`
 	LastUpdatedTimestamp int64  `db:"last_updated_timestamp"`
 }
+
+// Webhook outbox statuses. See migrations/018.
+const (
+	WebhookStatusPending   = "pending"
+	WebhookStatusDelivered = "delivered"
+	WebhookStatusDead      = "dead"
+)
+
+// WebhookOutboxEntry is one event queued for one webhook endpoint. It is
+// written in the same transaction as the momentum that produced it.
+type WebhookOutboxEntry struct {
+	ID             int64   `db:"id"`
+	DeliveryID     string  `db:"delivery_id"`     // UUID, derived from the event and endpoint; stable across retries
+	SubscriptionID *int64  `db:"subscription_id"` // nil for config-file endpoints
+	EndpointURL    string  `db:"endpoint_url"`
+	EventType      string  `db:"event_type"`
+	Payload        []byte  `db:"payload"` // JSON object
+	MomentumHeight int64   `db:"momentum_height"`
+	Status         string  `db:"status"` // pending | delivered | dead
+	Attempts       int     `db:"attempts"`
+	NextAttemptAt  int64   `db:"next_attempt_at"`
+	LastError      *string `db:"last_error"`
+	CreatedAt      int64   `db:"created_at"`
+	DeliveredAt    *int64  `db:"delivered_at"`
+	DeadAt         *int64  `db:"dead_at"`
+}
+
+// WebhookDeliveryAttempt is one HTTP attempt against an outbox entry.
+// StatusCode is nil when no response was received.
+type WebhookDeliveryAttempt struct {
+	OutboxID    int64   `db:"outbox_id"`
+	Attempt     int     `db:"attempt"`
+	AttemptedAt int64   `db:"attempted_at"`
+	StatusCode  *int    `db:"status_code"`
+	Error       *string `db:"error"`
+	DurationMs  int     `db:"duration_ms"`
+}
+
+// Webhook subscription statuses. See migrations/019.
+const (
+	WebhookSubscriptionActive = "active"
+	WebhookSubscriptionPaused = "paused"
+)
+
+// WebhookFilter narrows a webhook endpoint's events by content. Every
+// non-empty field must match (values within a field are alternatives);
+// the zero value matches everything. Stored as JSONB in
+// webhook_subscriptions.filter. MinAmount is a decimal string in base
+// units.
+type WebhookFilter struct {
+	Addresses      []string `json:"addresses,omitempty"`
+	TokenStandards []string `json:"token_standards,omitempty"`
+	Methods        []string `json:"methods,omitempty"`
+	BlockTypes     []int    `json:"block_types,omitempty"`
+	MinAmount      string   `json:"min_amount,omitempty"`
+}
+
+// IsZero reports whether f filters nothing.
+func (f WebhookFilter) IsZero() bool {
+	return len(f.Addresses) == 0 && len(f.TokenStandards) == 0 && len(f.Methods) == 0 &&
+		len(f.BlockTypes) == 0 && f.MinAmount == ""
+}
+
+// WebhookSubscription is a webhook endpoint registered at runtime through
+// the API, owned by the JWT subject that created it. Events empty means
+// every event type. After a rotation, PreviousSecret also signs
+// deliveries until PreviousSecretExpiresAt.
+type WebhookSubscription struct {
+	ID                      int64         `db:"id"`
+	Owner                   string        `db:"owner"`
+	URL                     string        `db:"url"`
+	Events                  []string      `db:"events"`
+	Filter                  WebhookFilter `db:"filter"`
+	Description             string        `db:"description"`
+	Secret                  string        `db:"secret"`
+	PreviousSecret          string        `db:"previous_secret"`
+	PreviousSecretExpiresAt int64         `db:"previous_secret_expires_at"`
+	Status                  string        `db:"status"` // active | paused
+	CreatedAt               int64         `db:"created_at"`
+	UpdatedAt               int64         `db:"updated_at"`
+	SecretRotatedAt         int64         `db:"secret_rotated_at"`
+}
+
+// WebhookDelivery is one delivery attempt together with the outbox row
+// it belongs to, as shown in a subscription's delivery log.
+type WebhookDelivery struct {
+	WebhookDeliveryAttempt
+	DeliveryID     string `db:"delivery_id"`
+	EventType      string `db:"event_type"`
+	MomentumHeight int64  `db:"momentum_height"`
+	Status         string `db:"status"` // the outbox row's current status
+}
+
+// Backfill run modes. See migrations/022.
+const (
+	BackfillModeGaps      = "gaps"      // only missing or incomplete heights
+	BackfillModeReprocess = "reprocess" // every height in range
+)
+
+// BackfillCheckpoint records the progress of a named cmd/backfill run.
+// Heights in [FromHeight, NextHeight) are done. Contracts is empty unless
+// the run re-ran only those embedded contracts' handlers.
+type BackfillCheckpoint struct {
+	Name        string   `db:"name"`
+	Mode        string   `db:"mode"` // gaps | reprocess
+	Contracts   []string `db:"contracts"`
+	FromHeight  uint64   `db:"from_height"`
+	ToHeight    uint64   `db:"to_height"`
+	NextHeight  uint64   `db:"next_height"`
+	StartedAt   int64    `db:"started_at"`
+	UpdatedAt   int64    `db:"updated_at"`
+	CompletedAt *int64   `db:"completed_at"`
+}
+
+// FailedHeight is a momentum height the indexer could not process,
+// queued for the retrier. See migrations/023. Reprocess marks a height
+// queued by the verifier, which the retrier rewrites even though it is
+// complete (migrations/024).
+type FailedHeight struct {
+	Height        uint64 `db:"height"`
+	LastError     string `db:"last_error"`
+	Attempts      int    `db:"attempts"`
+	FirstFailedAt int64  `db:"first_failed_at"`
+	LastFailedAt  int64  `db:"last_failed_at"`
+	NextRetryAt   int64  `db:"next_retry_at"`
+	Reprocess     bool   `db:"reprocess"`
+}
+
+// Consistency issue kinds.
+const (
+	IssueDiffers       = "differs"         // both sides have the subject, with different values
+	IssueMissingInDB   = "missing_in_db"   // only the node has it
+	IssueMissingOnNode = "missing_on_node" // only the database has it
+)
+
+// ConsistencyIssue is a difference between the indexed data and the
+// node, found by the verifier. See migrations/024.
+type ConsistencyIssue struct {
+	ID             int64  `db:"id"`
+	CheckName      string `db:"check_name"`
+	Subject        string `db:"subject"`
+	Kind           string `db:"kind"` // differs | missing_in_db | missing_on_node
+	DBValue        string `db:"db_value"`
+	NodeValue      string `db:"node_value"`
+	Occurrences    int    `db:"occurrences"`
+	FirstSeenAt    int64  `db:"first_seen_at"`
+	LastSeenAt     int64  `db:"last_seen_at"`
+	RepairQueuedAt *int64 `db:"repair_queued_at"`
+	ResolvedAt     *int64 `db:"resolved_at"`
+}
+
+// Spork is a protocol feature switch created and activated through the
+// Spork contract. See migrations/026.
+type Spork struct {
+	ID                          string `db:"id"`
+	Name                        string `db:"name"`
+	Description                 string `db:"description"`
+	CreatorAddress              string `db:"creator_address"`
+	Activated                   bool   `db:"activated"`
+	EnforcementHeight           int64  `db:"enforcement_height"`
+	CreationMomentumHeight      int64  `db:"creation_momentum_height"`
+	CreationMomentumTimestamp   int64  `db:"creation_momentum_timestamp"`
+	ActivationMomentumHeight    int64  `db:"activation_momentum_height"`
+	ActivationMomentumTimestamp int64  `db:"activation_momentum_timestamp"`
+}
+
+// LiquidityStake is one LP-token stake entry on the Liquidity contract,
+// keyed by the LiquidityStake send-block hash.
+type LiquidityStake struct {
+	ID                     string   `db:"id"`
+	StakeAddress           string   `db:"stake_address"`
+	TokenStandard          string   `db:"token_standard"`
+	Amount                 *big.Int `db:"amount"`
+	WeightedAmount         *big.Int `db:"weighted_amount"`
+	DurationInSec          int64    `db:"duration_in_sec"`
+	StartTimestamp         int64    `db:"start_timestamp"`
+	ExpirationTimestamp    int64    `db:"expiration_timestamp"`
+	RevokeTimestamp        int64    `db:"revoke_timestamp"`
+	IsActive               bool     `db:"is_active"`
+	CreationMomentumHeight int64    `db:"creation_momentum_height"`
+	CancelMomentumHeight   int64    `db:"cancel_momentum_height"`
+	UnlockMomentumHeight   int64    `db:"unlock_momentum_height"`
+}
+
+// LiquidityTokenTuple is one LP token the liquidity program rewards: its
+// share of the ZNN and QSR reward, in hundredths of a percent, and the
+// minimum stake. Stored as an element of liquidity_config.token_tuples.
+type LiquidityTokenTuple struct {
+	TokenStandard string `json:"token_standard"`
+	ZnnPercentage uint32 `json:"znn_percentage"`
+	QsrPercentage uint32 `json:"qsr_percentage"`
+	MinAmount     string `json:"min_amount"`
+}
+
+// LiquidityConfig is the singleton liquidity_config row, mirrored from
+// LiquidityApi.GetLiquidityInfo and GetSecurityInfo.
+type LiquidityConfig struct {
+	Administrator        string                `db:"administrator"`
+	IsHalted             bool                  `db:"is_halted"`
+	ZnnReward            *big.Int              `db:"znn_reward"`
+	QsrReward            *big.Int              `db:"qsr_reward"`
+	TokenTuples          []LiquidityTokenTuple `db:"token_tuples"`
+	Guardians            []string              `db:"guardians"`
+	AdministratorDelay   int64                 `db:"administrator_delay"`
+	SoftDelay            int64                 `db:"soft_delay"`
+	LastUpdatedTimestamp int64                 `db:"last_updated_timestamp"`
+}
+
+// LiquidityAdminAction is one administrator or guardian call into the
+// Liquidity contract as it landed on chain: the contract receive block,
+// the send that made the call and its decoded inputs.
+type LiquidityAdminAction struct {
+	AccountBlockHash  string            `db:"account_block_hash"`
+	SendBlockHash     string            `db:"send_block_hash"`
+	Method            string            `db:"method"`
+	Address           string            `db:"address"`
+	TokenStandard     string            `db:"token_standard"`
+	Amount            *big.Int          `db:"amount"`
+	Inputs            map[string]string `db:"inputs"`
+	MomentumHeight    int64             `db:"momentum_height"`
+	MomentumTimestamp int64             `db:"momentum_timestamp"`
+}
+
+// BridgeEvent is one call into the Bridge contract as it landed on
+// chain: the contract receive block, the send that made the call and its
+// decoded inputs.
+type BridgeEvent struct {
+	AccountBlockHash  string            `db:"account_block_hash"`
+	SendBlockHash     string            `db:"send_block_hash"`
+	Method            string            `db:"method"`
+	Address           string            `db:"address"`
+	TokenStandard     string            `db:"token_standard"`
+	Amount            *big.Int          `db:"amount"`
+	Inputs            map[string]string `db:"inputs"`
+	MomentumHeight    int64             `db:"momentum_height"`
+	MomentumTimestamp int64             `db:"momentum_timestamp"`
+}
+
+// SentinelEvent is one accepted call into the Sentinel contract: a QSR
+// deposit or withdrawal, a registration, a revocation or a reward
+// collection. RegistrationBlockHash is filled on read: the Register in
+// force at the event's height.
+type SentinelEvent struct {
+	AccountBlockHash      string   `db:"account_block_hash"`
+	SendBlockHash         string   `db:"send_block_hash"`
+	Owner                 string   `db:"owner"`
+	Method                string   `db:"method"`
+	ZnnAmount             *big.Int `db:"znn_amount"`
+	QsrAmount             *big.Int `db:"qsr_amount"`
+	MomentumHeight        int64    `db:"momentum_height"`
+	MomentumTimestamp     int64    `db:"momentum_timestamp"`
+	RegistrationBlockHash string   `db:"registration_block_hash"`
+}
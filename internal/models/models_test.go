@@ -79,3 +79,35 @@ func TestSyncStatusZeroValue(t *testing.T) {
 		t.Fatalf("zero value DBHeight should be 0, got %d", s.DBHeight)
 	}
 }
+
+func TestBridgeRequestStatus(t *testing.T) {
+	wraps := []struct {
+		w    WrapTokenRequest
+		want string
+	}{
+		{WrapTokenRequest{ConfirmationsToFinality: 5}, BridgeRequestPending},
+		{WrapTokenRequest{ConfirmationsToFinality: 0}, BridgeRequestPending},
+		{WrapTokenRequest{Signature: "sig", ConfirmationsToFinality: 5}, BridgeRequestSigned},
+		{WrapTokenRequest{Signature: "sig"}, BridgeRequestFinalized},
+	}
+	for _, tt := range wraps {
+		if got := tt.w.Status(); got != tt.want {
+			t.Errorf("wrap %+v: Status() = %q, want %q", tt.w, got, tt.want)
+		}
+	}
+
+	unwraps := []struct {
+		u    UnwrapTokenRequest
+		want string
+	}{
+		{UnwrapTokenRequest{}, BridgeRequestPending},
+		{UnwrapTokenRequest{Signature: "sig"}, BridgeRequestSigned},
+		{UnwrapTokenRequest{Signature: "sig", Redeemed: true}, BridgeRequestRedeemed},
+		{UnwrapTokenRequest{Signature: "sig", Revoked: true}, BridgeRequestRevoked},
+	}
+	for _, tt := range unwraps {
+		if got := tt.u.Status(); got != tt.want {
+			t.Errorf("unwrap %+v: Status() = %q, want %q", tt.u, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package rederive
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+// rebuildAccountFlows recomputes the flow and activity counters of every
+// address a block in the range touches. The counters cover an address's
+// whole history, so they are computed in SQL over all of account_blocks
+// rather than accumulated chunk by chunk.
+func rebuildAccountFlows(ctx context.Context, e *env, from, to uint64) (*rebuilt, error) {
+	addrs, err := e.repos.Rederive.TouchedAddresses(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	res := &rebuilt{current: rowSet{}, derived: rowSet{}, queue: func(*pgx.Batch) error { return nil }}
+	if len(addrs) == 0 {
+		return res, nil
+	}
+	current, err := e.repos.Rederive.Accounts(ctx, addrs)
+	if err != nil {
+		return nil, err
+	}
+	derived, err := e.repos.Rederive.AccountFlows(ctx, addrs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range current {
+		res.current.add(a.Address, accountFlowRow(a))
+	}
+	for _, a := range derived {
+		res.derived.add(a.Address, accountFlowRow(a))
+	}
+	res.queue = func(batch *pgx.Batch) error {
+		for _, a := range derived {
+			if res.current[a.Address] != res.derived[a.Address] {
+				e.repos.Rederive.SetAccountFlowsBatch(batch, a)
+			}
+		}
+		return nil
+	}
+	return res, nil
+}
+
+func accountFlowRow(a *models.Account) string {
+	return row(a.ZnnSent, a.ZnnReceived, a.QsrSent, a.QsrReceived,
+		a.FirstActiveAt, a.LastActiveAt, a.FirstSeen, a.LastSeen, a.TxCount)
+}
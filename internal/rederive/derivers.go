@@ -0,0 +1,623 @@
+package rederive
+
+import (
+	"context"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+// registry lists every deriver in the order a run applies them. Pillar
+// updates come first so later derivers see the pillar names they record.
+var registry = []deriver{
+	{name: "pillar-updates", tables: []string{"pillar_updates"}, rebuild: rebuildPillarUpdates},
+	{name: "delegations", tables: []string{"delegations", "accounts"}, rebuild: rebuildDelegations},
+	{name: "votes", tables: []string{"votes"}, rebuild: rebuildVotes},
+	{name: "rewards", tables: []string{"reward_transactions", "cumulative_rewards"}, rebuild: rebuildRewards},
+	{name: "stakes", tables: []string{"stakes"}, rebuild: rebuildStakes},
+	{name: "fusions", tables: []string{"fusions"}, rebuild: rebuildFusions},
+	{name: "htlcs", tables: []string{"htlcs"}, rebuild: rebuildHtlcs},
+	{name: "token-events", tables: []string{"token_mints", "token_burns", "tokens"}, rebuild: rebuildTokenEvents},
+	{name: "bridge-events", tables: []string{"bridge_events"}, rebuild: rebuildBridgeEvents},
+	{name: "account-flows", tables: []string{"accounts"}, rebuild: rebuildAccountFlows},
+}
+
+// contractCalls reads and decodes the calls contract received in heights
+// from through to.
+func (e *env) contractCalls(ctx context.Context, contract string, from, to uint64) ([]call, error) {
+	pairs, err := e.repos.Rederive.ContractCalls(ctx, contract, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return decodeCalls(e.logger, contract, pairs), nil
+}
+
+// --- pillar updates ---
+
+func rebuildPillarUpdates(ctx context.Context, e *env, from, to uint64) (*rebuilt, error) {
+	calls, err := e.contractCalls(ctx, models.PillarAddress, from, to)
+	if err != nil {
+		return nil, err
+	}
+	derived, err := derivePillarUpdates(ctx, e.lookups, calls)
+	if err != nil {
+		return nil, err
+	}
+	current, err := e.repos.Rederive.PillarUpdates(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &rebuilt{current: rowSet{}, derived: rowSet{}}
+	for _, pu := range current {
+		res.current.add(pillarUpdateKey(pu), pillarUpdateRow(pu))
+	}
+	for _, pu := range derived {
+		res.derived.add(pillarUpdateKey(pu), pillarUpdateRow(pu))
+	}
+	res.queue = func(batch *pgx.Batch) error {
+		e.repos.Rederive.DeletePillarUpdatesBatch(batch, from, to)
+		for _, pu := range derived {
+			e.repos.PillarUpdate.InsertBatch(batch, pu)
+		}
+		return nil
+	}
+	return res, nil
+}
+
+// derivePillarUpdates mirrors indexPillarContract's Register,
+// RegisterLegacy, UpdatePillar and Revoke cases. The stored blocks do not
+// say whether the contract accepted a call, so that is read from the name
+// history the indexer recorded: a registration is kept if its sender held
+// the name at its height, a revocation if the name was gone at the next.
+func derivePillarUpdates(ctx context.Context, res resolver, calls []call) ([]*models.PillarUpdate, error) {
+	var out []*models.PillarUpdate
+	for _, c := range calls {
+		name := c.tx.Inputs["name"]
+		switch c.tx.Method {
+		case "Register", "RegisterLegacy", "UpdatePillar", "Revoke":
+		default:
+			continue
+		}
+		if name == "" {
+			continue
+		}
+		height := uint64(c.Receive.MomentumHeight)
+		owner, err := res.pillarOwner(ctx, name, height)
+		if err != nil {
+			return nil, err
+		}
+		if owner != c.Send.Address {
+			continue
+		}
+		pu := &models.PillarUpdate{
+			AccountBlockHash:  c.Receive.Hash,
+			Method:            c.tx.Method,
+			Name:              name,
+			OwnerAddress:      owner,
+			MomentumTimestamp: c.Receive.MomentumTimestamp,
+			MomentumHeight:    c.Receive.MomentumHeight,
+			MomentumHash:      c.Receive.MomentumHash,
+		}
+		if c.tx.Method == "Revoke" {
+			next, err := res.pillarOwner(ctx, name, height+1)
+			if err != nil {
+				return nil, err
+			}
+			if next != "" {
+				continue
+			}
+		} else {
+			pu.ProducerAddress = c.tx.Inputs["producerAddress"]
+			pu.WithdrawAddress = c.tx.Inputs["rewardAddress"]
+		}
+		out = append(out, pu)
+	}
+	return out, nil
+}
+
+func pillarUpdateKey(pu *models.PillarUpdate) string {
+	return row(pu.MomentumHeight, pu.Name, pu.Method)
+}
+
+// pillarUpdateRow leaves out the reward percentages: the indexer never
+// derives them from blocks.
+func pillarUpdateRow(pu *models.PillarUpdate) string {
+	return row(pu.AccountBlockHash, pu.OwnerAddress, pu.ProducerAddress, pu.WithdrawAddress,
+		pu.MomentumTimestamp, pu.MomentumHash)
+}
+
+// --- votes ---
+
+func rebuildVotes(ctx context.Context, e *env, from, to uint64) (*rebuilt, error) {
+	calls, err := e.contractCalls(ctx, models.AcceleratorAddress, from, to)
+	if err != nil {
+		return nil, err
+	}
+	derived, err := deriveVotes(ctx, e.lookups, calls)
+	if err != nil {
+		return nil, err
+	}
+	voters, votingIDs := make([]string, len(derived)), make([]string, len(derived))
+	for i, v := range derived {
+		voters[i], votingIDs[i] = v.VoterAddress, v.VotingID
+	}
+	current, err := e.repos.Rederive.Votes(ctx, from, to, voters, votingIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &rebuilt{current: rowSet{}, derived: rowSet{}}
+	// votes keeps one row per voter and voting id: the latest. A key whose
+	// row is a vote cast after the range is out of this range's hands.
+	later := map[string]bool{}
+	for _, v := range current {
+		if uint64(v.MomentumHeight) > to {
+			later[voteKey(v)] = true
+			continue
+		}
+		res.current.add(voteKey(v), voteRow(v))
+	}
+	var writes []*models.Vote
+	for _, v := range derived {
+		if later[voteKey(v)] {
+			continue
+		}
+		res.derived.add(voteKey(v), voteRow(v))
+		writes = append(writes, v)
+	}
+	res.queue = func(batch *pgx.Batch) error {
+		var delVoters, delIDs []string
+		for _, v := range current {
+			if !later[voteKey(v)] {
+				delVoters, delIDs = append(delVoters, v.VoterAddress), append(delIDs, v.VotingID)
+			}
+		}
+		e.repos.Rederive.DeleteVotesBatch(batch, delVoters, delIDs)
+		for _, v := range writes {
+			e.repos.Vote.InsertBatch(batch, v)
+		}
+		return nil
+	}
+	return res, nil
+}
+
+// deriveVotes mirrors indexAcceleratorContract's vote cases and keeps the
+// last vote per voter and voting id, as the votes upsert does.
+func deriveVotes(ctx context.Context, res resolver, calls []call) ([]*models.Vote, error) {
+	var out []*models.Vote
+	index := map[string]int{}
+	for _, c := range calls {
+		if c.tx.Method != "VoteByName" && c.tx.Method != "VoteByProdAddress" {
+			continue
+		}
+		votingID := c.tx.Inputs["id"]
+		if votingID == "" {
+			continue
+		}
+		voteValue, err := strconv.Atoi(c.tx.Inputs["vote"])
+		if err != nil {
+			voteValue = 0
+		}
+		voter := c.Send.Address
+		if c.tx.Method == "VoteByName" {
+			if name := c.tx.Inputs["name"]; name != "" {
+				owner, err := res.pillarOwner(ctx, name, uint64(c.Receive.MomentumHeight))
+				if err != nil {
+					return nil, err
+				}
+				if owner != "" {
+					voter = owner
+				}
+			}
+		}
+		projectID, phaseID := res.votingTarget(ctx, votingID)
+		v := &models.Vote{
+			VotingID:          votingID,
+			VoterAddress:      voter,
+			ProjectID:         projectID,
+			PhaseID:           phaseID,
+			Vote:              int16(voteValue),
+			MomentumTimestamp: c.Receive.MomentumTimestamp,
+			MomentumHeight:    c.Receive.MomentumHeight,
+			MomentumHash:      c.Receive.MomentumHash,
+		}
+		if i, ok := index[voteKey(v)]; ok {
+			out[i] = v
+			continue
+		}
+		index[voteKey(v)] = len(out)
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+func voteKey(v *models.Vote) string {
+	return row(v.VoterAddress, v.VotingID)
+}
+
+func voteRow(v *models.Vote) string {
+	return row(v.Vote, v.ProjectID, v.PhaseID, v.MomentumHeight, v.MomentumTimestamp, v.MomentumHash)
+}
+
+// --- rewards ---
+
+func rebuildRewards(ctx context.Context, e *env, from, to uint64) (*rebuilt, error) {
+	pairs, err := e.repos.Rederive.RewardReceives(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	derived, err := deriveRewards(ctx, e.lookups, e.logger, pairs)
+	if err != nil {
+		return nil, err
+	}
+	current, err := e.repos.Rederive.RewardTransactions(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &rebuilt{current: rowSet{}, derived: rowSet{}}
+	var hashes []string
+	for _, rt := range current {
+		res.current.add(rt.Hash, rewardRow(rt))
+		hashes = append(hashes, rt.Hash)
+	}
+	for _, rt := range derived {
+		res.derived.add(rt.Hash, rewardRow(rt))
+		hashes = append(hashes, rt.Hash)
+	}
+	res.queue = func(batch *pgx.Batch) error {
+		if err := e.repos.Rederive.DeleteKeysBatch(batch, "reward_transactions", hashes); err != nil {
+			return err
+		}
+		for _, rt := range derived {
+			e.repos.Reward.InsertRewardTransactionBatch(batch, rt)
+		}
+		// cumulative_rewards only ever accumulates, so it moves by the
+		// difference between the two sides.
+		for _, d := range rewardDeltas(current, derived) {
+			e.repos.Reward.UpdateCumulativeRewardsBatch(batch, d.address, d.rewardType, d.amount, d.tokenStandard)
+		}
+		return nil
+	}
+	return res, nil
+}
+
+// deriveRewards mirrors the reward branch of processAccountBlocks:
+// liquidity treasury payouts, and receives of contract sends classified
+// by their source the way classifyReward does.
+func deriveRewards(ctx context.Context, res resolver, logger *zap.Logger, pairs []*models.BlockPair) ([]*models.RewardTransaction, error) {
+	var out []*models.RewardTransaction
+	for _, p := range pairs {
+		var rewardType models.RewardType
+		switch {
+		case p.Send.Address == models.LiquidityTreasuryAddress:
+			rewardType = models.RewardTypeLiquidity
+		case p.Send.BlockType == models.BlockTypeContractSend &&
+			p.Receive.ToAddress == models.EmptyAddress &&
+			p.Receive.TokenStandard == models.EmptyTokenStandard:
+			var err error
+			if rewardType, err = classifyReward(ctx, res, logger, p.Send.Address, p.Receive.Address); err != nil {
+				return nil, err
+			}
+		default:
+			continue
+		}
+		if rewardType == models.RewardTypeUnknown {
+			continue
+		}
+		out = append(out, &models.RewardTransaction{
+			Hash:              p.Receive.Hash,
+			Address:           p.Receive.Address,
+			RewardType:        rewardType,
+			MomentumTimestamp: p.Receive.MomentumTimestamp,
+			MomentumHeight:    p.Receive.MomentumHeight,
+			AccountHeight:     p.Receive.Height,
+			Amount:            p.Send.Amount,
+			TokenStandard:     p.Send.TokenStandard,
+			SourceAddress:     p.Send.Address,
+		})
+	}
+	return out, nil
+}
+
+// classifyReward is the indexer's classifyReward over a resolver. A
+// failed withdraw-address lookup fails the chunk rather than defaulting
+// to Pillar: a rebuild should not bake in a guess.
+func classifyReward(ctx context.Context, res resolver, logger *zap.Logger, source, receiver string) (models.RewardType, error) {
+	switch source {
+	case models.SentinelAddress:
+		return models.RewardTypeSentinel, nil
+	case models.StakeAddress:
+		return models.RewardTypeStake, nil
+	case models.LiquidityAddress:
+		return models.RewardTypeLiquidity, nil
+	case models.PillarAddress:
+		isPillar, err := res.isWithdrawAddress(ctx, receiver)
+		if err != nil {
+			logger.Warn("rederive: withdraw address lookup failed", zap.String("receiver", receiver), zap.Error(err))
+			return models.RewardTypeUnknown, err
+		}
+		if isPillar {
+			return models.RewardTypePillar, nil
+		}
+		return models.RewardTypeDelegation, nil
+	default:
+		return models.RewardTypeUnknown, nil
+	}
+}
+
+func rewardRow(rt *models.RewardTransaction) string {
+	return row(rt.Address, int(rt.RewardType), rt.Amount, rt.TokenStandard, rt.SourceAddress,
+		rt.MomentumHeight, rt.MomentumTimestamp, rt.AccountHeight)
+}
+
+// rewardDelta is a change to one cumulative_rewards row.
+type rewardDelta struct {
+	address       string
+	rewardType    models.RewardType
+	tokenStandard string
+	amount        *big.Int
+}
+
+// rewardDeltas sums derived minus current per cumulative_rewards row,
+// leaving out the rows that do not move.
+func rewardDeltas(current, derived []*models.RewardTransaction) []rewardDelta {
+	var out []rewardDelta
+	index := map[string]int{}
+	add := func(rt *models.RewardTransaction, sign int) {
+		key := row(rt.Address, int(rt.RewardType), rt.TokenStandard)
+		i, ok := index[key]
+		if !ok {
+			i = len(out)
+			index[key] = i
+			out = append(out, rewardDelta{
+				address: rt.Address, rewardType: rt.RewardType, tokenStandard: rt.TokenStandard, amount: new(big.Int),
+			})
+		}
+		if rt.Amount == nil {
+			return
+		}
+		if sign < 0 {
+			out[i].amount.Sub(out[i].amount, rt.Amount)
+		} else {
+			out[i].amount.Add(out[i].amount, rt.Amount)
+		}
+	}
+	for _, rt := range current {
+		add(rt, -1)
+	}
+	for _, rt := range derived {
+		add(rt, 1)
+	}
+	moved := out[:0]
+	for _, d := range out {
+		if d.amount.Sign() != 0 {
+			moved = append(moved, d)
+		}
+	}
+	return moved
+}
+
+// --- token mints and burns ---
+
+func rebuildTokenEvents(ctx context.Context, e *env, from, to uint64) (*rebuilt, error) {
+	calls, err := e.contractCalls(ctx, models.TokenAddress, from, to)
+	if err != nil {
+		return nil, err
+	}
+	mints, burns := deriveTokenEvents(e.logger, calls)
+	curMints, err := e.repos.Rederive.TokenMints(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	curBurns, err := e.repos.Rederive.TokenBurns(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &rebuilt{current: rowSet{}, derived: rowSet{}}
+	var mintHashes, burnHashes []string
+	for _, m := range curMints {
+		res.current.add("mint:"+m.AccountBlockHash, mintRow(m))
+		mintHashes = append(mintHashes, m.AccountBlockHash)
+	}
+	for _, m := range mints {
+		res.derived.add("mint:"+m.AccountBlockHash, mintRow(m))
+		mintHashes = append(mintHashes, m.AccountBlockHash)
+	}
+	for _, b := range curBurns {
+		res.current.add("burn:"+b.AccountBlockHash, burnRow(b))
+		burnHashes = append(burnHashes, b.AccountBlockHash)
+	}
+	for _, b := range burns {
+		res.derived.add("burn:"+b.AccountBlockHash, burnRow(b))
+		burnHashes = append(burnHashes, b.AccountBlockHash)
+	}
+	res.queue = func(batch *pgx.Batch) error {
+		if err := e.repos.Rederive.DeleteKeysBatch(batch, "token_mints", mintHashes); err != nil {
+			return err
+		}
+		if err := e.repos.Rederive.DeleteKeysBatch(batch, "token_burns", burnHashes); err != nil {
+			return err
+		}
+		for _, m := range mints {
+			e.repos.TokenEvent.InsertMintBatch(batch, m)
+		}
+		for _, b := range burns {
+			e.repos.TokenEvent.InsertBurnBatch(batch, b)
+		}
+		// tokens.total_burned only ever accumulates, so it moves by the
+		// difference between the two sides.
+		for _, d := range burnDeltas(curBurns, burns) {
+			e.repos.Token.UpdateBurnAmountBatch(batch, d.tokenStandard, d.amount)
+		}
+		return nil
+	}
+	return res, nil
+}
+
+// deriveTokenEvents mirrors indexTokenContract's Mint and Burn cases.
+func deriveTokenEvents(logger *zap.Logger, calls []call) ([]*models.TokenMint, []*models.TokenBurn) {
+	var (
+		mints []*models.TokenMint
+		burns []*models.TokenBurn
+	)
+	for _, c := range calls {
+		switch c.tx.Method {
+		case "Mint":
+			amount, ok := new(big.Int).SetString(c.tx.Inputs["amount"], 10)
+			if !ok {
+				logger.Warn("rederive: invalid mint amount",
+					zap.String("amount", c.tx.Inputs["amount"]),
+					zap.String("hash", c.Receive.Hash))
+				continue
+			}
+			mints = append(mints, &models.TokenMint{
+				AccountBlockHash:  c.Receive.Hash,
+				MomentumHeight:    c.Receive.MomentumHeight,
+				MomentumTimestamp: c.Receive.MomentumTimestamp,
+				TokenStandard:     c.tx.Inputs["tokenStandard"],
+				Issuer:            c.Send.Address,
+				Receiver:          c.tx.Inputs["receiveAddress"],
+				Amount:            amount,
+			})
+		case "Burn":
+			burns = append(burns, &models.TokenBurn{
+				AccountBlockHash:  c.Receive.Hash,
+				MomentumHeight:    c.Receive.MomentumHeight,
+				MomentumTimestamp: c.Receive.MomentumTimestamp,
+				TokenStandard:     c.Send.TokenStandard,
+				Burner:            c.Send.Address,
+				Amount:            c.Send.Amount,
+			})
+		}
+	}
+	return mints, burns
+}
+
+func mintRow(m *models.TokenMint) string {
+	return row(m.TokenStandard, m.Issuer, m.Receiver, m.Amount, m.MomentumHeight, m.MomentumTimestamp)
+}
+
+func burnRow(b *models.TokenBurn) string {
+	return row(b.TokenStandard, b.Burner, b.Amount, b.MomentumHeight, b.MomentumTimestamp)
+}
+
+// --- bridge events ---
+
+func rebuildBridgeEvents(ctx context.Context, e *env, from, to uint64) (*rebuilt, error) {
+	calls, err := e.contractCalls(ctx, models.BridgeAddress, from, to)
+	if err != nil {
+		return nil, err
+	}
+	derived := deriveBridgeEvents(calls)
+	current, err := e.repos.Rederive.BridgeEvents(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &rebuilt{current: rowSet{}, derived: rowSet{}}
+	var hashes []string
+	for _, be := range current {
+		res.current.add(be.AccountBlockHash, bridgeEventRow(be))
+		hashes = append(hashes, be.AccountBlockHash)
+	}
+	for _, be := range derived {
+		res.derived.add(be.AccountBlockHash, bridgeEventRow(be))
+		hashes = append(hashes, be.AccountBlockHash)
+	}
+	res.queue = func(batch *pgx.Batch) error {
+		if err := e.repos.Rederive.DeleteKeysBatch(batch, "bridge_events", hashes); err != nil {
+			return err
+		}
+		for _, be := range derived {
+			e.repos.BridgeEvent.InsertBatch(batch, be)
+		}
+		return nil
+	}
+	return res, nil
+}
+
+// deriveBridgeEvents mirrors indexBridgeContract: one event per call.
+func deriveBridgeEvents(calls []call) []*models.BridgeEvent {
+	out := make([]*models.BridgeEvent, 0, len(calls))
+	for _, c := range calls {
+		out = append(out, &models.BridgeEvent{
+			AccountBlockHash:  c.Receive.Hash,
+			SendBlockHash:     c.Send.Hash,
+			Method:            c.tx.Method,
+			Address:           c.Send.Address,
+			TokenStandard:     c.Send.TokenStandard,
+			Amount:            c.Send.Amount,
+			Inputs:            c.tx.Inputs,
+			MomentumHeight:    c.Receive.MomentumHeight,
+			MomentumTimestamp: c.Receive.MomentumTimestamp,
+		})
+	}
+	return out
+}
+
+// bridgeEventRow renders inputs sorted by name, so the stored JSONB and
+// the decoded map compare equal.
+func bridgeEventRow(be *models.BridgeEvent) string {
+	names := make([]string, 0, len(be.Inputs))
+	for k := range be.Inputs {
+		names = append(names, k)
+	}
+	slices.Sort(names)
+	inputs := make([]string, len(names))
+	for i, k := range names {
+		inputs[i] = k + "=" + be.Inputs[k]
+	}
+	return row(be.SendBlockHash, be.Method, be.Address, be.TokenStandard, be.Amount,
+		strings.Join(inputs, ","), be.MomentumHeight, be.MomentumTimestamp)
+}
+
+// burnDelta is a change to one token's total_burned.
+type burnDelta struct {
+	tokenStandard string
+	amount        *big.Int
+}
+
+// burnDeltas sums derived minus current burns per token, leaving out the
+// tokens that do not move.
+func burnDeltas(current, derived []*models.TokenBurn) []burnDelta {
+	var out []burnDelta
+	index := map[string]int{}
+	add := func(b *models.TokenBurn, sign int) {
+		i, ok := index[b.TokenStandard]
+		if !ok {
+			i = len(out)
+			index[b.TokenStandard] = i
+			out = append(out, burnDelta{tokenStandard: b.TokenStandard, amount: new(big.Int)})
+		}
+		if b.Amount == nil {
+			return
+		}
+		if sign < 0 {
+			out[i].amount.Sub(out[i].amount, b.Amount)
+		} else {
+			out[i].amount.Add(out[i].amount, b.Amount)
+		}
+	}
+	for _, b := range current {
+		add(b, -1)
+	}
+	for _, b := range derived {
+		add(b, 1)
+	}
+	moved := out[:0]
+	for _, d := range out {
+		if d.amount.Sign() != 0 {
+			moved = append(moved, d)
+		}
+	}
+	return moved
+}
@@ -0,0 +1,433 @@
+package rederive
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/0x3639/znn-sdk-go/abi"
+	"github.com/0x3639/znn-sdk-go/embedded"
+	"github.com/zenon-network/go-zenon/common/types"
+	"go.uber.org/zap"
+
+	"github.com/0x3639/nom-indexer-go/internal/indexer"
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+// fakeResolver answers lookups from maps. Pillar names resolve through
+// history, pillar_updates name-history rows, falling back to owners.
+type fakeResolver struct {
+	owners   map[string]string
+	history  []*models.PillarUpdate
+	withdraw map[string]bool
+	targets  map[string][2]string
+
+	names *indexer.PillarNames
+}
+
+func (f *fakeResolver) pillarOwner(_ context.Context, name string, height uint64) (string, error) {
+	if f.names == nil {
+		f.names = &indexer.PillarNames{}
+		f.names.SetCurrent(f.owners)
+		f.names.Load(f.history)
+	}
+	return f.names.Owner(name, height), nil
+}
+
+func (f *fakeResolver) isWithdrawAddress(_ context.Context, address string) (bool, error) {
+	return f.withdraw[address], nil
+}
+
+func (f *fakeResolver) votingTarget(_ context.Context, votingID string) (string, string) {
+	t := f.targets[votingID]
+	return t[0], t[1]
+}
+
+// hashN returns the hex of a hash whose last byte is n.
+func hashN(n int) string {
+	return fmt.Sprintf("%064x", n)
+}
+
+// callAt builds the stored block pair of a call to contract, encoded
+// with contractAbi, sent by sender in send block hashN(n) and received at
+// momentum height.
+func callAt(t *testing.T, contract string, contractAbi *abi.Abi, method string, args []interface{}, sender string, n int, height int64) *models.BlockPair {
+	t.Helper()
+	data, err := contractAbi.EncodeFunction(method, args)
+	if err != nil {
+		t.Fatalf("encode %s: %v", method, err)
+	}
+	return &models.BlockPair{
+		Receive: models.AccountBlock{
+			Hash: "r" + hashN(n), Address: contract, BlockType: models.BlockTypeContractReceive,
+			MomentumHeight: height, MomentumTimestamp: height * 10, MomentumHash: fmt.Sprintf("m%d", height),
+		},
+		Send: models.AccountBlock{
+			Hash: hashN(n), Address: sender, ToAddress: contract, BlockType: models.BlockTypeUserSend,
+			Amount: big.NewInt(int64(n) * 100), TokenStandard: models.ZnnTokenStandard,
+			Data: hex.EncodeToString(data),
+		},
+	}
+}
+
+func calls(t *testing.T, contract string, pairs ...*models.BlockPair) []call {
+	t.Helper()
+	out := decodeCalls(zap.NewNop(), contract, pairs)
+	if len(out) != len(pairs) {
+		t.Fatalf("decoded %d of %d calls", len(out), len(pairs))
+	}
+	return out
+}
+
+func TestDecodeCalls_DropsUndecodable(t *testing.T) {
+	good := callAt(t, models.PillarAddress, embedded.Pillar, "Undelegate", nil, "z1a", 1, 1)
+	badHex := callAt(t, models.PillarAddress, embedded.Pillar, "Undelegate", nil, "z1a", 2, 1)
+	badHex.Send.Data = "zz"
+	unknown := callAt(t, models.PillarAddress, embedded.Pillar, "Undelegate", nil, "z1a", 3, 1)
+	unknown.Send.Data = "ffeeddcc"
+
+	got := decodeCalls(zap.NewNop(), models.PillarAddress, []*models.BlockPair{good, badHex, unknown})
+	if len(got) != 1 || got[0].Send.Hash != good.Send.Hash || got[0].tx.Method != "Undelegate" {
+		t.Fatalf("decodeCalls = %+v, want only the Undelegate", got)
+	}
+}
+
+func TestDerivePillarUpdates(t *testing.T) {
+	producer := types.ParseAddressPanic(models.StakeAddress)
+	reward := types.ParseAddressPanic(models.PlasmaAddress)
+	register := callAt(t, models.PillarAddress, embedded.Pillar, "Register",
+		[]interface{}{"p1", producer, reward, uint8(0), uint8(100)}, "z1owner", 1, 5)
+	update := callAt(t, models.PillarAddress, embedded.Pillar, "UpdatePillar",
+		[]interface{}{"p1", producer, producer, uint8(0), uint8(100)}, "z1owner", 2, 6)
+	orphan := callAt(t, models.PillarAddress, embedded.Pillar, "UpdatePillar",
+		[]interface{}{"gone", producer, producer, uint8(0), uint8(100)}, "z1x", 3, 7)
+	taken := callAt(t, models.PillarAddress, embedded.Pillar, "Register",
+		[]interface{}{"p1", producer, reward, uint8(0), uint8(100)}, "z1x", 4, 8)
+	early := callAt(t, models.PillarAddress, embedded.Pillar, "Revoke", []interface{}{"p1"}, "z1owner", 5, 8)
+	revoke := callAt(t, models.PillarAddress, embedded.Pillar, "Revoke", []interface{}{"p1"}, "z1owner", 6, 9)
+	late := callAt(t, models.PillarAddress, embedded.Pillar, "UpdatePillar",
+		[]interface{}{"p1", producer, producer, uint8(0), uint8(100)}, "z1owner", 7, 10)
+	res := &fakeResolver{history: []*models.PillarUpdate{
+		{Method: "Register", Name: "p1", OwnerAddress: "z1owner", MomentumHeight: 5},
+		{Method: "Revoke", Name: "p1", OwnerAddress: "z1owner", MomentumHeight: 9},
+	}}
+
+	got, err := derivePillarUpdates(context.Background(), res,
+		calls(t, models.PillarAddress, register, update, orphan, taken, early, revoke, late))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %d updates, want 3 (unknown pillar, taken name, early revoke and revoked pillar skipped): %+v", len(got), got)
+	}
+	want := &models.PillarUpdate{
+		AccountBlockHash: "r" + hashN(1), Method: "Register",
+		Name: "p1", OwnerAddress: "z1owner",
+		ProducerAddress: models.StakeAddress, WithdrawAddress: models.PlasmaAddress,
+		MomentumHeight: 5, MomentumTimestamp: 50, MomentumHash: "m5",
+	}
+	if !reflect.DeepEqual(got[0], want) {
+		t.Errorf("register = %+v, want %+v", got[0], want)
+	}
+	if got[1].Method != "UpdatePillar" || got[1].WithdrawAddress != models.StakeAddress || got[1].MomentumHeight != 6 {
+		t.Errorf("update = %+v", got[1])
+	}
+	if got[2].Method != "Revoke" || got[2].MomentumHeight != 9 || got[2].ProducerAddress != "" {
+		t.Errorf("revoke = %+v", got[2])
+	}
+}
+
+func TestDeriveVotes(t *testing.T) {
+	project := types.HexToHashPanic(hashN(0xa1))
+	phase := types.HexToHashPanic(hashN(0xa2))
+	pairs := []*models.BlockPair{
+		callAt(t, models.AcceleratorAddress, embedded.Accelerator, "VoteByName",
+			[]interface{}{project, "p1", uint8(0)}, "z1producer", 1, 10),
+		callAt(t, models.AcceleratorAddress, embedded.Accelerator, "VoteByProdAddress",
+			[]interface{}{phase, uint8(2)}, "z1producer", 2, 11),
+		// A pillar name with no owner falls back to the sender.
+		callAt(t, models.AcceleratorAddress, embedded.Accelerator, "VoteByName",
+			[]interface{}{project, "unknown", uint8(1)}, "z1other", 3, 12),
+		// A later vote on the same id by the same voter replaces the first.
+		callAt(t, models.AcceleratorAddress, embedded.Accelerator, "VoteByName",
+			[]interface{}{project, "p1", uint8(1)}, "z1producer", 4, 13),
+	}
+	res := &fakeResolver{
+		owners: map[string]string{"p1": "z1owner"},
+		targets: map[string][2]string{
+			project.String(): {"proj"},
+			phase.String():   {"proj", "ph"},
+		},
+	}
+
+	got, err := deriveVotes(context.Background(), res, calls(t, models.AcceleratorAddress, pairs...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		row("z1owner", project.String(), 1, "proj", "", 13),
+		row("z1producer", phase.String(), 2, "proj", "ph", 11),
+		row("z1other", project.String(), 1, "proj", "", 12),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d votes, want %d: %+v", len(got), len(want), got)
+	}
+	for i, v := range got {
+		if r := row(v.VoterAddress, v.VotingID, v.Vote, v.ProjectID, v.PhaseID, v.MomentumHeight); r != want[i] {
+			t.Errorf("vote %d = %s, want %s", i, r, want[i])
+		}
+	}
+}
+
+func TestDeriveRewards(t *testing.T) {
+	reward := func(n int, source string, sendType int16, to, token string) *models.BlockPair {
+		return &models.BlockPair{
+			Receive: models.AccountBlock{
+				Hash: hashN(n), Address: fmt.Sprintf("z1recv%d", n), BlockType: models.BlockTypeUserReceive,
+				ToAddress: to, TokenStandard: token, Height: int64(n), MomentumHeight: 100, MomentumTimestamp: 1000,
+			},
+			Send: models.AccountBlock{
+				Address: source, BlockType: sendType, Amount: big.NewInt(int64(n)), TokenStandard: models.QsrTokenStandard,
+			},
+		}
+	}
+	empty, emptyZts := models.EmptyAddress, models.EmptyTokenStandard
+	pairs := []*models.BlockPair{
+		reward(1, models.LiquidityTreasuryAddress, models.BlockTypeUserSend, "", ""),
+		reward(2, models.PillarAddress, models.BlockTypeContractSend, empty, emptyZts),
+		reward(3, models.PillarAddress, models.BlockTypeContractSend, empty, emptyZts),
+		reward(4, models.SentinelAddress, models.BlockTypeContractSend, empty, emptyZts),
+		reward(5, models.StakeAddress, models.BlockTypeContractSend, empty, emptyZts),
+		// Not a reward: an unclassified contract, and a receive that names
+		// a recipient.
+		reward(6, models.HtlcAddress, models.BlockTypeContractSend, empty, emptyZts),
+		reward(7, models.StakeAddress, models.BlockTypeContractSend, "z1someone", emptyZts),
+	}
+	res := &fakeResolver{withdraw: map[string]bool{"z1recv2": true}}
+
+	got, err := deriveRewards(context.Background(), res, zap.NewNop(), pairs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantTypes := []models.RewardType{
+		models.RewardTypeLiquidity, models.RewardTypePillar, models.RewardTypeDelegation,
+		models.RewardTypeSentinel, models.RewardTypeStake,
+	}
+	if len(got) != len(wantTypes) {
+		t.Fatalf("got %d rewards, want %d: %+v", len(got), len(wantTypes), got)
+	}
+	for i, rt := range got {
+		if rt.RewardType != wantTypes[i] || rt.Hash != hashN(i+1) || rt.Amount.Int64() != int64(i+1) ||
+			rt.TokenStandard != models.QsrTokenStandard || rt.AccountHeight != int64(i+1) {
+			t.Errorf("reward %d = %+v, want type %v", i, rt, wantTypes[i])
+		}
+	}
+}
+
+func TestRewardDeltas(t *testing.T) {
+	rt := func(addr string, amount int64) *models.RewardTransaction {
+		return &models.RewardTransaction{
+			Address: addr, RewardType: models.RewardTypeStake, TokenStandard: models.QsrTokenStandard,
+			Amount: big.NewInt(amount),
+		}
+	}
+	current := []*models.RewardTransaction{rt("a", 5), rt("b", 7), rt("c", 1)}
+	derived := []*models.RewardTransaction{rt("a", 5), rt("b", 3), rt("d", 2)}
+
+	got := map[string]int64{}
+	for _, d := range rewardDeltas(current, derived) {
+		got[d.address] = d.amount.Int64()
+	}
+	want := map[string]int64{"b": -4, "c": -1, "d": 2}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rewardDeltas = %v, want %v", got, want)
+	}
+}
+
+func TestDeriveStakes(t *testing.T) {
+	stake := func(n int, sender string) *models.BlockPair {
+		return callAt(t, models.StakeAddress, embedded.Stake, "Stake", []interface{}{int64(3600)}, sender, n, 10)
+	}
+	cancel := func(id, n int, sender string) *models.BlockPair {
+		return callAt(t, models.StakeAddress, embedded.Stake, "Cancel",
+			[]interface{}{types.HexToHashPanic(hashN(id))}, sender, n, 20)
+	}
+	stakes := calls(t, models.StakeAddress, stake(1, "z1a"), stake(2, "z1b"),
+		cancel(1, 9, "z1a"))
+	// Only the staker's own cancel ends a stake.
+	cancels := calls(t, models.StakeAddress, cancel(1, 9, "z1a"), cancel(2, 10, "z1intruder"))
+
+	got := deriveStakes(zap.NewNop(), stakes, cancels)
+	if len(got) != 2 {
+		t.Fatalf("got %d stakes, want 2: %+v", len(got), got)
+	}
+	wantCancel, _ := indexer.StakeCancelID(hashN(1))
+	if s := got[0]; s.ID != hashN(1) || s.IsActive || s.CancelID != wantCancel ||
+		s.StartTimestamp != 100 || s.ExpirationTimestamp != 3700 || s.DurationInSec != 3600 || s.ZnnAmount.Int64() != 100 {
+		t.Errorf("cancelled stake = %+v", s)
+	}
+	if s := got[1]; s.ID != hashN(2) || !s.IsActive || s.Address != "z1b" {
+		t.Errorf("live stake = %+v", s)
+	}
+}
+
+func TestDeriveFusions(t *testing.T) {
+	beneficiary := types.ParseAddressPanic(models.SentinelAddress)
+	fuse := callAt(t, models.PlasmaAddress, embedded.Plasma, "Fuse", []interface{}{beneficiary}, "z1a", 1, 10)
+	cancel := callAt(t, models.PlasmaAddress, embedded.Plasma, "CancelFuse",
+		[]interface{}{types.HexToHashPanic(hashN(1))}, "z1a", 2, 20)
+
+	got := deriveFusions(zap.NewNop(), calls(t, models.PlasmaAddress, fuse), calls(t, models.PlasmaAddress, cancel))
+	if len(got) != 1 {
+		t.Fatalf("got %d fusions, want 1", len(got))
+	}
+	wantCancel, _ := indexer.FusionCancelID(hashN(1))
+	f := got[0]
+	if f.Beneficiary != models.SentinelAddress || f.IsActive || f.CancelID != wantCancel ||
+		f.ExpirationHeight != 10+models.FusionExpirationBlocks || f.QsrAmount.Int64() != 100 {
+		t.Errorf("fusion = %+v", f)
+	}
+}
+
+func TestDeriveHtlcs(t *testing.T) {
+	locked := types.ParseAddressPanic(models.SentinelAddress)
+	create := func(n int) *models.BlockPair {
+		return callAt(t, models.HtlcAddress, embedded.Htlc, "Create",
+			[]interface{}{locked, int64(5000), uint8(0), uint8(32), []byte("deadbeef")}, "z1sender", n, 10)
+	}
+	id := func(n int) types.Hash { return types.HexToHashPanic(hashN(n)) }
+	settles := calls(t, models.HtlcAddress,
+		callAt(t, models.HtlcAddress, embedded.Htlc, "Reclaim", []interface{}{id(1)}, "z1sender", 10, 20),
+		callAt(t, models.HtlcAddress, embedded.Htlc, "Unlock", []interface{}{id(1), []byte{0xca, 0xfe}}, "z1x", 11, 21),
+	)
+
+	got := deriveHtlcs(zap.NewNop(), calls(t, models.HtlcAddress, create(1), create(2)), settles)
+	if len(got) != 2 {
+		t.Fatalf("got %d htlcs, want 2", len(got))
+	}
+	h := got[0]
+	// The last settlement wins, as the indexer's unconditional update
+	// leaves it; hash locks and preimages re-decode to their raw bytes.
+	if h.Status != int16(models.HtlcStatusUnlocked) || h.Preimage != "cafe" ||
+		h.SettleMomentumHeight != 21 || h.SettleMomentumTimestamp != 210 ||
+		h.HashLock != "6465616462656566" || h.HashLockedAddress != models.SentinelAddress ||
+		h.ExpirationTimestamp != 5000 || h.KeyMaxSize != 32 {
+		t.Errorf("settled htlc = %+v", h)
+	}
+	if h := got[1]; h.Status != int16(models.HtlcStatusActive) || h.SettleMomentumHeight != 0 || h.Preimage != "" {
+		t.Errorf("open htlc = %+v", h)
+	}
+}
+
+func TestDeriveDelegations(t *testing.T) {
+	delegate := func(name string, n int, height int64) *models.BlockPair {
+		return callAt(t, models.PillarAddress, embedded.Pillar, "Delegate", []interface{}{name}, "z1d", n, height)
+	}
+	undelegate := func(n int, height int64) *models.BlockPair {
+		return callAt(t, models.PillarAddress, embedded.Pillar, "Undelegate", nil, "z1d", n, height)
+	}
+	res := &fakeResolver{owners: map[string]string{"p1": "z1p1", "p2": "z1p2"}}
+
+	got, err := deriveDelegations(context.Background(), res, calls(t, models.PillarAddress,
+		delegate("p1", 1, 1), delegate("p2", 2, 2), undelegate(3, 3),
+		delegate("gone", 4, 4), // unknown pillar: skipped, as the indexer does
+		delegate("p1", 5, 5),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := got["z1d"]
+	if h == nil || len(got) != 1 {
+		t.Fatalf("histories = %+v", got)
+	}
+	var rows []string
+	for _, d := range h.intervals {
+		rows = append(rows, row(d.PillarOwnerAddress, d.StartedAt, d.EndedAt))
+	}
+	want := []string{"z1p1|10|20", "z1p2|20|30", "z1p1|50|-"}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("intervals = %v, want %v", rows, want)
+	}
+	if h.delegate != "z1p1" || h.since != 50 {
+		t.Errorf("final delegate = %s since %d, want z1p1 since 50", h.delegate, h.since)
+	}
+}
+
+func TestDeriveDelegations_UndelegateClearsDelegate(t *testing.T) {
+	res := &fakeResolver{owners: map[string]string{"p1": "z1p1"}}
+	got, err := deriveDelegations(context.Background(), res, calls(t, models.PillarAddress,
+		callAt(t, models.PillarAddress, embedded.Pillar, "Delegate", []interface{}{"p1"}, "z1d", 1, 1),
+		callAt(t, models.PillarAddress, embedded.Pillar, "Undelegate", nil, "z1d", 2, 2),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := got["z1d"]; h.delegate != "" || h.since != 0 || h.intervals[0].EndedAt == nil {
+		t.Errorf("history = %+v", h)
+	}
+}
+
+func TestDeriveTokenEvents(t *testing.T) {
+	zts := types.ParseZTSPanic(models.QsrTokenStandard)
+	receiver := types.ParseAddressPanic(models.StakeAddress)
+	mint := callAt(t, models.TokenAddress, embedded.Token, "Mint",
+		[]interface{}{zts, big.NewInt(12345), receiver}, models.PillarAddress, 1, 10)
+	burn := callAt(t, models.TokenAddress, embedded.Token, "Burn", nil, "z1burner", 2, 11)
+
+	mints, burns := deriveTokenEvents(zap.NewNop(), calls(t, models.TokenAddress, mint, burn))
+	if len(mints) != 1 || len(burns) != 1 {
+		t.Fatalf("got %d mints and %d burns, want 1 and 1", len(mints), len(burns))
+	}
+	m := mints[0]
+	if m.AccountBlockHash != mint.Receive.Hash || m.TokenStandard != models.QsrTokenStandard ||
+		m.Issuer != models.PillarAddress || m.Receiver != models.StakeAddress || m.Amount.Int64() != 12345 {
+		t.Errorf("mint = %+v", m)
+	}
+	b := burns[0]
+	if b.AccountBlockHash != burn.Receive.Hash || b.Burner != "z1burner" ||
+		b.TokenStandard != models.ZnnTokenStandard || b.Amount.Int64() != 200 {
+		t.Errorf("burn = %+v", b)
+	}
+}
+
+func TestBurnDeltas(t *testing.T) {
+	burn := func(token string, amount int64) *models.TokenBurn {
+		return &models.TokenBurn{TokenStandard: token, Amount: big.NewInt(amount)}
+	}
+	got := burnDeltas(
+		[]*models.TokenBurn{burn("zts1a", 10), burn("zts1b", 4)},
+		[]*models.TokenBurn{burn("zts1a", 10), burn("zts1a", 6), burn("zts1b", 4)},
+	)
+	if len(got) != 1 || got[0].tokenStandard != "zts1a" || got[0].amount.Int64() != 6 {
+		t.Errorf("burnDeltas = %+v, want zts1a +6 only", got)
+	}
+}
+
+func TestDeriveBridgeEvents(t *testing.T) {
+	wrap := callAt(t, models.BridgeAddress, embedded.Bridge, "WrapToken",
+		[]interface{}{uint32(2), uint32(1), "0x00000000000000000000000000000000000000aa"}, "z1wrapper", 1, 10)
+	halt := callAt(t, models.BridgeAddress, embedded.Bridge, "Halt", []interface{}{"sig"}, "z1admin", 2, 11)
+
+	got := deriveBridgeEvents(calls(t, models.BridgeAddress, wrap, halt))
+	if len(got) != 2 {
+		t.Fatalf("got %d events, want 2", len(got))
+	}
+	w := got[0]
+	if w.AccountBlockHash != wrap.Receive.Hash || w.SendBlockHash != wrap.Send.Hash || w.Method != "WrapToken" ||
+		w.Address != "z1wrapper" || w.Amount.Int64() != 100 || w.Inputs["chainId"] != "1" || w.MomentumHeight != 10 {
+		t.Errorf("wrap = %+v", w)
+	}
+	if h := got[1]; h.Method != "Halt" || h.Address != "z1admin" || h.Inputs["signature"] != "sig" {
+		t.Errorf("halt = %+v", h)
+	}
+
+	// Input order must not matter to the comparison.
+	a := &models.BridgeEvent{Inputs: map[string]string{"a": "1", "b": "2"}}
+	b := &models.BridgeEvent{Inputs: map[string]string{"b": "2", "a": "1"}}
+	if bridgeEventRow(a) != bridgeEventRow(b) {
+		t.Error("bridgeEventRow depends on input order")
+	}
+}
@@ -0,0 +1,14 @@
+// Package rederive rebuilds the projections the indexer derives from
+// account blocks — votes, rewards, delegations, stakes, fusions, HTLCs,
+// token mints and burns, pillar updates and account flow counters —
+// from the blocks already stored in account_blocks, without a node.
+//
+// Each deriver reads the stored blocks of a height range, decodes them
+// the way the indexer's contract handlers do, and compares the rows it
+// would write with the rows the table holds. The comparison is reported
+// as a diff; in apply mode the deriver's rows for the range are replaced
+// in one transaction per chunk of heights.
+//
+// cmd/rederive is the command-line front end. See
+// docs/operations/backfill.md for when to reach for it.
+package rederive
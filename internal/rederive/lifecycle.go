@@ -0,0 +1,453 @@
+package rederive
+
+import (
+	"context"
+	"encoding/hex"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"github.com/0x3639/nom-indexer-go/internal/indexer"
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+// The derivers in this file rebuild entries that a later call can end: a
+// stake or fusion cancelled, an HTLC unlocked or reclaimed, a delegation
+// replaced. An entry belongs to the range that created it and is written
+// with its final state, whatever height ended it.
+
+// settlements reads and decodes every call to contract, at any height,
+// that is one of methods and names one of ids as its `id` input.
+func (e *env) settlements(ctx context.Context, contract string, methods, ids []string) ([]call, error) {
+	pairs, err := e.repos.Rederive.ContractCallsByID(ctx, contract, methods, ids)
+	if err != nil {
+		return nil, err
+	}
+	return decodeCalls(e.logger, contract, pairs), nil
+}
+
+// cancelled returns the ids in calls' `id` inputs keyed by sender, the
+// pairs a Cancel or CancelFuse matches on.
+func cancelled(calls []call, method string) map[[2]string]bool {
+	out := map[[2]string]bool{}
+	for _, c := range calls {
+		if c.tx.Method == method && c.tx.Inputs["id"] != "" {
+			out[[2]string{c.tx.Inputs["id"], c.Send.Address}] = true
+		}
+	}
+	return out
+}
+
+// --- stakes ---
+
+func rebuildStakes(ctx context.Context, e *env, from, to uint64) (*rebuilt, error) {
+	calls, err := e.contractCalls(ctx, models.StakeAddress, from, to)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, c := range calls {
+		if c.tx.Method == "Stake" {
+			ids = append(ids, c.Send.Hash)
+		}
+	}
+	cancels, err := e.settlements(ctx, models.StakeAddress, []string{"Cancel"}, ids)
+	if err != nil {
+		return nil, err
+	}
+	derived := deriveStakes(e.logger, calls, cancels)
+
+	// stakes records no height, only the start timestamp.
+	first, last, err := e.repos.Rederive.TimestampRange(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	current, err := e.repos.Rederive.Stakes(ctx, ids, first, last)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &rebuilt{current: rowSet{}, derived: rowSet{}}
+	keys := ids
+	for _, s := range current {
+		res.current.add(s.ID, stakeRow(s))
+		keys = append(keys, s.ID)
+	}
+	for _, s := range derived {
+		res.derived.add(s.ID, stakeRow(s))
+	}
+	res.queue = func(batch *pgx.Batch) error {
+		if err := e.repos.Rederive.DeleteKeysBatch(batch, "stakes", keys); err != nil {
+			return err
+		}
+		for _, s := range derived {
+			e.repos.Stake.InsertBatch(batch, s)
+		}
+		return nil
+	}
+	return res, nil
+}
+
+// deriveStakes mirrors indexStakeContract: a Stake opens an entry keyed by
+// the send hash, and a Cancel of that id from the same address ends it.
+func deriveStakes(logger *zap.Logger, calls, cancels []call) []*models.Stake {
+	ended := cancelled(cancels, "Cancel")
+	var out []*models.Stake
+	for _, c := range calls {
+		if c.tx.Method != "Stake" {
+			continue
+		}
+		duration, err := strconv.Atoi(c.tx.Inputs["durationInSec"])
+		if err != nil {
+			logger.Warn("rederive: invalid stake duration",
+				zap.String("duration", c.tx.Inputs["durationInSec"]), zap.String("hash", c.Send.Hash))
+			duration = 0
+		}
+		cancelID, err := indexer.StakeCancelID(c.Send.Hash)
+		if err != nil {
+			logger.Warn("rederive: stake cancel id", zap.String("id", c.Send.Hash), zap.Error(err))
+		}
+		out = append(out, &models.Stake{
+			ID:                  c.Send.Hash,
+			Address:             c.Send.Address,
+			ZnnAmount:           c.Send.Amount,
+			StartTimestamp:      c.Receive.MomentumTimestamp,
+			DurationInSec:       duration,
+			ExpirationTimestamp: c.Receive.MomentumTimestamp + int64(duration),
+			IsActive:            !ended[[2]string{c.Send.Hash, c.Send.Address}],
+			CancelID:            cancelID,
+		})
+	}
+	return out
+}
+
+func stakeRow(s *models.Stake) string {
+	return row(s.Address, s.ZnnAmount, s.StartTimestamp, s.DurationInSec, s.ExpirationTimestamp, s.IsActive, s.CancelID)
+}
+
+// --- fusions ---
+
+func rebuildFusions(ctx context.Context, e *env, from, to uint64) (*rebuilt, error) {
+	calls, err := e.contractCalls(ctx, models.PlasmaAddress, from, to)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, c := range calls {
+		if c.tx.Method == "Fuse" {
+			ids = append(ids, c.Send.Hash)
+		}
+	}
+	cancels, err := e.settlements(ctx, models.PlasmaAddress, []string{"CancelFuse"}, ids)
+	if err != nil {
+		return nil, err
+	}
+	derived := deriveFusions(e.logger, calls, cancels)
+	current, err := e.repos.Rederive.Fusions(ctx, ids, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &rebuilt{current: rowSet{}, derived: rowSet{}}
+	keys := ids
+	for _, f := range current {
+		res.current.add(f.ID, fusionRow(f))
+		keys = append(keys, f.ID)
+	}
+	for _, f := range derived {
+		res.derived.add(f.ID, fusionRow(f))
+	}
+	res.queue = func(batch *pgx.Batch) error {
+		if err := e.repos.Rederive.DeleteKeysBatch(batch, "fusions", keys); err != nil {
+			return err
+		}
+		for _, f := range derived {
+			e.repos.Fusion.InsertBatch(batch, f)
+		}
+		return nil
+	}
+	return res, nil
+}
+
+// deriveFusions mirrors indexPlasmaContract: a Fuse opens an entry keyed
+// by the send hash, and a CancelFuse of that id from the same address
+// ends it.
+func deriveFusions(logger *zap.Logger, calls, cancels []call) []*models.Fusion {
+	ended := cancelled(cancels, "CancelFuse")
+	var out []*models.Fusion
+	for _, c := range calls {
+		if c.tx.Method != "Fuse" {
+			continue
+		}
+		beneficiary := c.tx.Inputs["address"]
+		if beneficiary == "" {
+			beneficiary = c.Send.Address
+		}
+		cancelID, err := indexer.FusionCancelID(c.Send.Hash)
+		if err != nil {
+			logger.Warn("rederive: fusion cancel id", zap.String("id", c.Send.Hash), zap.Error(err))
+		}
+		out = append(out, &models.Fusion{
+			ID:                c.Send.Hash,
+			Address:           c.Send.Address,
+			Beneficiary:       beneficiary,
+			QsrAmount:         c.Send.Amount,
+			MomentumTimestamp: c.Receive.MomentumTimestamp,
+			MomentumHeight:    c.Receive.MomentumHeight,
+			MomentumHash:      c.Receive.MomentumHash,
+			ExpirationHeight:  c.Receive.MomentumHeight + models.FusionExpirationBlocks,
+			IsActive:          !ended[[2]string{c.Send.Hash, c.Send.Address}],
+			CancelID:          cancelID,
+		})
+	}
+	return out
+}
+
+func fusionRow(f *models.Fusion) string {
+	return row(f.Address, f.Beneficiary, f.QsrAmount, f.MomentumHeight, f.MomentumTimestamp, f.MomentumHash,
+		f.ExpirationHeight, f.IsActive, f.CancelID)
+}
+
+// --- htlcs ---
+
+func rebuildHtlcs(ctx context.Context, e *env, from, to uint64) (*rebuilt, error) {
+	calls, err := e.contractCalls(ctx, models.HtlcAddress, from, to)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, c := range calls {
+		if c.tx.Method == "Create" {
+			ids = append(ids, c.Send.Hash)
+		}
+	}
+	settles, err := e.settlements(ctx, models.HtlcAddress, []string{"Unlock", "Reclaim"}, ids)
+	if err != nil {
+		return nil, err
+	}
+	derived := deriveHtlcs(e.logger, calls, settles)
+	current, err := e.repos.Rederive.Htlcs(ctx, ids, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &rebuilt{current: rowSet{}, derived: rowSet{}}
+	keys := ids
+	for _, h := range current {
+		res.current.add(h.ID, htlcRow(h))
+		keys = append(keys, h.ID)
+	}
+	for _, h := range derived {
+		res.derived.add(h.ID, htlcRow(h))
+	}
+	res.queue = func(batch *pgx.Batch) error {
+		if err := e.repos.Rederive.DeleteKeysBatch(batch, "htlcs", keys); err != nil {
+			return err
+		}
+		for _, h := range derived {
+			e.repos.Htlc.InsertBatch(batch, h)
+		}
+		return nil
+	}
+	return res, nil
+}
+
+// deriveHtlcs mirrors indexHtlcContract: a Create opens an entry keyed by
+// the send hash, and the last Unlock or Reclaim naming it settles it,
+// as the indexer's unconditional SettleBatch leaves it.
+func deriveHtlcs(logger *zap.Logger, calls, settles []call) []*models.Htlc {
+	settled := map[string]call{}
+	for _, c := range settles {
+		if id := c.tx.Inputs["id"]; id != "" && (c.tx.Method == "Unlock" || c.tx.Method == "Reclaim") {
+			settled[id] = c
+		}
+	}
+	var out []*models.Htlc
+	for _, c := range calls {
+		if c.tx.Method != "Create" {
+			continue
+		}
+		id := c.Send.Hash
+		h := &models.Htlc{
+			ID:                        id,
+			TimeLockedAddress:         c.Send.Address,
+			HashLockedAddress:         c.tx.Inputs["hashLocked"],
+			TokenStandard:             c.Send.TokenStandard,
+			Amount:                    c.Send.Amount,
+			ExpirationTimestamp:       parseInt(logger, id, "expirationTime", c.tx.Inputs["expirationTime"]),
+			HashType:                  int16(parseInt(logger, id, "hashType", c.tx.Inputs["hashType"])),
+			KeyMaxSize:                int16(parseInt(logger, id, "keyMaxSize", c.tx.Inputs["keyMaxSize"])),
+			HashLock:                  hex.EncodeToString([]byte(c.tx.Inputs["hashLock"])),
+			Status:                    int16(models.HtlcStatusActive),
+			CreationMomentumHeight:    c.Receive.MomentumHeight,
+			CreationMomentumTimestamp: c.Receive.MomentumTimestamp,
+		}
+		if s, ok := settled[id]; ok {
+			h.SettleMomentumHeight, h.SettleMomentumTimestamp = s.Receive.MomentumHeight, s.Receive.MomentumTimestamp
+			if s.tx.Method == "Unlock" {
+				h.Status = int16(models.HtlcStatusUnlocked)
+				h.Preimage = hex.EncodeToString([]byte(s.tx.Inputs["preimage"]))
+			} else {
+				h.Status = int16(models.HtlcStatusReclaimed)
+			}
+		}
+		out = append(out, h)
+	}
+	return out
+}
+
+// parseInt parses an integer HTLC input, logging and returning 0 when it
+// is malformed as indexHtlcContract does.
+func parseInt(logger *zap.Logger, id, field, s string) int64 {
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		logger.Warn("rederive: invalid htlc "+field, zap.String("htlcID", id), zap.String(field, s))
+		return 0
+	}
+	return v
+}
+
+func htlcRow(h *models.Htlc) string {
+	return row(h.TimeLockedAddress, h.HashLockedAddress, h.TokenStandard, h.Amount, h.ExpirationTimestamp,
+		h.HashType, h.KeyMaxSize, h.HashLock, h.Status, h.Preimage,
+		h.CreationMomentumHeight, h.CreationMomentumTimestamp, h.SettleMomentumHeight, h.SettleMomentumTimestamp)
+}
+
+// --- delegations ---
+
+// rebuildDelegations replays the whole delegation history of every
+// address that called Delegate or Undelegate in the range, since each
+// interval's end depends on the call after it.
+func rebuildDelegations(ctx context.Context, e *env, from, to uint64) (*rebuilt, error) {
+	calls, err := e.contractCalls(ctx, models.PillarAddress, from, to)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	var delegators []string
+	for _, c := range calls {
+		if (c.tx.Method == "Delegate" || c.tx.Method == "Undelegate") && !seen[c.Send.Address] {
+			seen[c.Send.Address] = true
+			delegators = append(delegators, c.Send.Address)
+		}
+	}
+	res := &rebuilt{current: rowSet{}, derived: rowSet{}, queue: func(*pgx.Batch) error { return nil }}
+	if len(delegators) == 0 {
+		return res, nil
+	}
+
+	pairs, err := e.repos.Rederive.ContractCallsBySender(ctx, models.PillarAddress, delegators)
+	if err != nil {
+		return nil, err
+	}
+	history, err := deriveDelegations(ctx, e.lookups, decodeCalls(e.logger, models.PillarAddress, pairs))
+	if err != nil {
+		return nil, err
+	}
+	current, err := e.repos.Rederive.Delegations(ctx, delegators)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := e.repos.Rederive.Accounts(ctx, delegators)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range current {
+		res.current.add(delegationKey(d), delegationRow(d))
+	}
+	for _, a := range accounts {
+		if _, ok := history[a.Address]; ok {
+			res.current.add("account:"+a.Address, row(a.Delegate, a.DelegationStartTimestamp))
+		}
+	}
+	for addr, h := range history {
+		for _, d := range h.intervals {
+			res.derived.add(delegationKey(d), delegationRow(d))
+		}
+		res.derived.add("account:"+addr, row(h.delegate, h.since))
+	}
+	res.queue = func(batch *pgx.Batch) error {
+		if err := e.repos.Rederive.DeleteKeysBatch(batch, "delegations", delegators); err != nil {
+			return err
+		}
+		for _, addr := range delegators {
+			h, ok := history[addr]
+			if !ok {
+				continue
+			}
+			for _, d := range h.intervals {
+				e.repos.Delegation.OpenBatch(batch, d.AccountBlockHash, addr, d.PillarOwnerAddress, d.StartedAt)
+				if d.EndedAt != nil {
+					e.repos.Delegation.CloseActiveBatch(batch, addr, *d.EndedAt)
+				}
+			}
+			e.repos.Account.UpdateDelegateBatch(batch, addr, h.delegate, h.since)
+		}
+		return nil
+	}
+	return res, nil
+}
+
+// delegationHistory is one delegator's replayed state: every interval,
+// oldest first, and the delegate the account ends up with.
+type delegationHistory struct {
+	intervals []*models.Delegation
+	delegate  string
+	since     int64
+}
+
+// deriveDelegations mirrors indexPillarContract's Delegate and Undelegate
+// cases over calls in chain order. Delegators whose calls were all
+// skipped — a Delegate to an unknown pillar is — have no history.
+func deriveDelegations(ctx context.Context, res resolver, calls []call) (map[string]*delegationHistory, error) {
+	out := map[string]*delegationHistory{}
+	for _, c := range calls {
+		addr, ts := c.Send.Address, c.Receive.MomentumTimestamp
+		var owner string
+		switch c.tx.Method {
+		case "Delegate":
+			name := c.tx.Inputs["name"]
+			if name == "" {
+				continue
+			}
+			var err error
+			if owner, err = res.pillarOwner(ctx, name, uint64(c.Receive.MomentumHeight)); err != nil {
+				return nil, err
+			}
+			if owner == "" {
+				continue
+			}
+		case "Undelegate":
+		default:
+			continue
+		}
+		h := out[addr]
+		if h == nil {
+			h = &delegationHistory{}
+			out[addr] = h
+		}
+		if n := len(h.intervals); n > 0 && h.intervals[n-1].EndedAt == nil {
+			ended := ts
+			h.intervals[n-1].EndedAt = &ended
+		}
+		h.delegate, h.since = "", 0
+		if owner != "" {
+			h.intervals = append(h.intervals, &models.Delegation{
+				DelegatorAddress: addr, PillarOwnerAddress: owner, StartedAt: ts,
+				AccountBlockHash: c.Receive.Hash,
+			})
+			h.delegate, h.since = owner, ts
+		}
+	}
+	return out, nil
+}
+
+func delegationKey(d *models.Delegation) string {
+	return "delegation:" + row(d.DelegatorAddress, d.StartedAt)
+}
+
+func delegationRow(d *models.Delegation) string {
+	return row(d.PillarOwnerAddress, d.EndedAt)
+}
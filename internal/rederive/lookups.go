@@ -0,0 +1,141 @@
+package rederive
+
+import (
+	"context"
+	"encoding/hex"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/0x3639/nom-indexer-go/internal/indexer"
+	"github.com/0x3639/nom-indexer-go/internal/models"
+	"github.com/0x3639/nom-indexer-go/internal/repository"
+)
+
+// resolver answers the lookups the indexer's contract handlers make
+// against its caches and tables, so derivers can be tested without a
+// database.
+type resolver interface {
+	// pillarOwner returns the owner address of the pillar named name at
+	// momentum height, or "" when no pillar held the name then.
+	pillarOwner(ctx context.Context, name string, height uint64) (string, error)
+	// isWithdrawAddress reports whether address is a pillar's withdraw
+	// address.
+	isWithdrawAddress(ctx context.Context, address string) (bool, error)
+	// votingTarget returns the project, and phase if any, that votingID
+	// votes on; both are "" when it matches neither.
+	votingTarget(ctx context.Context, votingID string) (projectID, phaseID string)
+}
+
+// dbLookups is the resolver over the database. Answers are cached for
+// the lifetime of a run, which is what the indexer's own pillar cache
+// amounts to over a range. Pillar names resolve as the indexer's do:
+// through the pillar_updates name history, falling back to the current
+// pillars.
+type dbLookups struct {
+	repos  *repository.Repositories
+	logger *zap.Logger
+
+	mu       sync.Mutex
+	names    *indexer.PillarNames
+	withdraw map[string]bool
+	targets  map[string][2]string
+}
+
+func newDBLookups(repos *repository.Repositories, logger *zap.Logger) *dbLookups {
+	return &dbLookups{
+		repos:    repos,
+		logger:   logger,
+		withdraw: map[string]bool{},
+		targets:  map[string][2]string{},
+	}
+}
+
+func (l *dbLookups) pillarOwner(ctx context.Context, name string, height uint64) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.names == nil {
+		pillars, err := l.repos.Pillar.GetAll(ctx)
+		if err != nil {
+			return "", err
+		}
+		history, err := l.repos.PillarUpdate.NameHistory(ctx)
+		if err != nil {
+			return "", err
+		}
+		owners := make(map[string]string, len(pillars))
+		for _, p := range pillars {
+			owners[p.Name] = p.OwnerAddress
+		}
+		l.names = &indexer.PillarNames{}
+		l.names.SetCurrent(owners)
+		l.names.Load(history)
+	}
+	return l.names.Owner(name, height), nil
+}
+
+func (l *dbLookups) isWithdrawAddress(ctx context.Context, address string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if v, ok := l.withdraw[address]; ok {
+		return v, nil
+	}
+	v, err := l.repos.Pillar.IsWithdrawAddress(ctx, address)
+	if err != nil {
+		return false, err
+	}
+	l.withdraw[address] = v
+	return v, nil
+}
+
+func (l *dbLookups) votingTarget(ctx context.Context, votingID string) (string, string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if t, ok := l.targets[votingID]; ok {
+		return t[0], t[1]
+	}
+	// Same resolution order as indexAcceleratorContract: a project, then
+	// a phase.
+	var t [2]string
+	projectID, err := l.repos.Project.GetIDFromVotingID(ctx, votingID)
+	if err != nil || projectID == "" {
+		t[0], t[1], _ = l.repos.ProjectPhase.GetProjectAndPhaseIDFromVotingID(ctx, votingID)
+	} else {
+		t[0] = projectID
+	}
+	l.targets[votingID] = t
+	return t[0], t[1]
+}
+
+// call is a contract call read back from account_blocks: the contract's
+// receive block, the send that made the call, and the send's data
+// decoded against the contract's ABI.
+type call struct {
+	*models.BlockPair
+	tx *models.TxData
+}
+
+// decodeCalls decodes the send data of pairs against contract's ABI,
+// dropping the pairs the indexer would not have handled: those whose
+// data does not decode.
+//
+// The data column is re-decoded rather than the stored input read back
+// because input went through JSON, which mangles ABI bytes arguments
+// such as HTLC hash locks and preimages.
+func decodeCalls(logger *zap.Logger, contract string, pairs []*models.BlockPair) []call {
+	out := make([]call, 0, len(pairs))
+	for _, p := range pairs {
+		data, err := hex.DecodeString(p.Send.Data)
+		if err != nil {
+			logger.Warn("rederive: undecodable block data",
+				zap.String("hash", p.Send.Hash), zap.Error(err))
+			continue
+		}
+		tx := indexer.DecodeTxData(logger, contract, data)
+		if tx == nil {
+			continue
+		}
+		out = append(out, call{BlockPair: p, tx: tx})
+	}
+	return out
+}
@@ -0,0 +1,388 @@
+package rederive
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"github.com/0x3639/nom-indexer-go/internal/repository"
+)
+
+// DefaultStep is how many heights one chunk covers when Options.Step is
+// unset.
+const DefaultStep = 10_000
+
+// DefaultSamples is how many differing rows each deriver reports when
+// Options.Samples is unset.
+const DefaultSamples = 5
+
+// Options selects what a run rebuilds. The zero value dry-runs every
+// deriver over every indexed height.
+type Options struct {
+	// From and To bound the run, inclusive. From 0 means 1; To 0 means
+	// the highest indexed momentum when the run starts.
+	From, To uint64
+	// Only restricts the run to these derivers (see Names). Empty runs
+	// them all.
+	Only []string
+	// Apply writes the derived rows. Without it the run only reports.
+	Apply bool
+	// Step is how many heights one chunk, and so one transaction per
+	// deriver, covers. Default DefaultStep.
+	Step uint64
+	// Samples caps the differing rows reported per deriver. Default
+	// DefaultSamples; negative reports none.
+	Samples int
+}
+
+// Validate reports whether o is usable.
+func (o Options) Validate() error {
+	if o.To != 0 && o.From > o.To {
+		return fmt.Errorf("from %d is above to %d", o.From, o.To)
+	}
+	for _, name := range o.Only {
+		if lookupDeriver(name) == nil {
+			return fmt.Errorf("unknown deriver %q", name)
+		}
+	}
+	return nil
+}
+
+// Names lists the derivers in the order a run applies them.
+func Names() []string {
+	names := make([]string, len(registry))
+	for i, d := range registry {
+		names[i] = d.name
+	}
+	return names
+}
+
+// deriver rebuilds one projection. rebuild reads the stored blocks and
+// the current rows of heights from through to and returns both sides of
+// the comparison, plus the writes that make the table match.
+type deriver struct {
+	name    string
+	tables  []string
+	rebuild func(ctx context.Context, e *env, from, to uint64) (*rebuilt, error)
+}
+
+// rebuilt is one deriver's result for one chunk. current and derived map
+// a row key to a canonical rendering of the row; queue adds the writes
+// that replace current with derived to a batch.
+type rebuilt struct {
+	current rowSet
+	derived rowSet
+	queue   func(batch *pgx.Batch) error
+}
+
+// env is what derivers read through.
+type env struct {
+	repos   *repository.Repositories
+	logger  *zap.Logger
+	lookups resolver
+}
+
+func lookupDeriver(name string) *deriver {
+	for i := range registry {
+		if registry[i].name == name {
+			return &registry[i]
+		}
+	}
+	return nil
+}
+
+// Report is the outcome of a run.
+type Report struct {
+	From     uint64           `json:"from"`
+	To       uint64           `json:"to"`
+	Apply    bool             `json:"apply"`
+	Derivers []*DeriverReport `json:"derivers"`
+}
+
+// Clean reports whether every deriver found its table matching the
+// stored blocks.
+func (r *Report) Clean() bool {
+	for _, d := range r.Derivers {
+		if d.Added+d.Removed+d.Changed > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// DeriverReport totals one deriver's diff over every chunk of a run.
+type DeriverReport struct {
+	Name   string   `json:"name"`
+	Tables []string `json:"tables"`
+	// Current and Derived count the rows compared on each side.
+	Current int `json:"current"`
+	Derived int `json:"derived"`
+	// Added rows are derived but missing, Removed rows are held but not
+	// derived, and Changed rows are both with different values.
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+	Changed int `json:"changed"`
+	// AppliedChunks counts the chunks whose rows were rewritten.
+	AppliedChunks int      `json:"applied_chunks"`
+	Samples       []Sample `json:"samples,omitempty"`
+}
+
+// Sample is one differing row.
+type Sample struct {
+	// Change is "added", "removed" or "changed".
+	Change  string `json:"change"`
+	Key     string `json:"key"`
+	Current string `json:"current,omitempty"`
+	Derived string `json:"derived,omitempty"`
+}
+
+// Runner walks a height range chunk by chunk and runs the selected
+// derivers over each. Its database access is injected, like the
+// indexer's catch-up pipeline.
+type Runner struct {
+	opts     Options
+	logger   *zap.Logger
+	derivers []deriver
+	env      *env
+
+	// head returns the highest indexed momentum.
+	head func(ctx context.Context) (uint64, error)
+	// exec sends a batch inside one transaction and commits it.
+	exec func(ctx context.Context, batch *pgx.Batch) error
+}
+
+// NewRunner builds a Runner over pool. opts must have passed Validate.
+func NewRunner(pool *pgxpool.Pool, logger *zap.Logger, opts Options) *Runner {
+	repos := repository.NewRepositories(pool)
+	return &Runner{
+		opts:     opts,
+		logger:   logger,
+		derivers: selectDerivers(opts.Only),
+		env:      &env{repos: repos, logger: logger, lookups: newDBLookups(repos, logger)},
+		head:     repos.Momentum.GetLatestHeight,
+		exec: func(ctx context.Context, batch *pgx.Batch) error {
+			return execBatchTx(ctx, pool, batch)
+		},
+	}
+}
+
+// selectDerivers returns the registry entries named in only, in registry
+// order, or all of them when only is empty.
+func selectDerivers(only []string) []deriver {
+	if len(only) == 0 {
+		return slices.Clone(registry)
+	}
+	var out []deriver
+	for _, d := range registry {
+		if slices.Contains(only, d.name) {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// Run rebuilds every selected deriver over the range and returns the
+// combined report. In apply mode a chunk is written as soon as it has
+// been compared, so an error leaves earlier chunks applied.
+func (r *Runner) Run(ctx context.Context) (*Report, error) {
+	from, to := max(r.opts.From, 1), r.opts.To
+	if to == 0 {
+		head, err := r.head(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("read indexed head: %w", err)
+		}
+		to = head
+	}
+	step := r.opts.Step
+	if step == 0 {
+		step = DefaultStep
+	}
+	samples := r.opts.Samples
+	if samples == 0 {
+		samples = DefaultSamples
+	}
+
+	report := &Report{From: from, To: to, Apply: r.opts.Apply}
+	for _, d := range r.derivers {
+		report.Derivers = append(report.Derivers, &DeriverReport{Name: d.name, Tables: d.tables})
+	}
+	for lo := from; lo <= to; lo += step {
+		hi := to
+		if to-lo >= step {
+			hi = lo + step - 1
+		}
+		for i, d := range r.derivers {
+			if err := ctx.Err(); err != nil {
+				return report, err
+			}
+			if err := r.runChunk(ctx, d, report.Derivers[i], lo, hi, samples); err != nil {
+				return report, fmt.Errorf("%s at heights %d-%d: %w", d.name, lo, hi, err)
+			}
+		}
+		r.logger.Info("rederive: chunk done", zap.Uint64("from", lo), zap.Uint64("to", hi))
+		if hi == to {
+			break // lo += step would overflow at the top of the range
+		}
+	}
+	return report, nil
+}
+
+// runChunk compares and, in apply mode, rewrites one deriver's rows for
+// heights from through to.
+func (r *Runner) runChunk(ctx context.Context, d deriver, rep *DeriverReport, from, to uint64, samples int) error {
+	res, err := d.rebuild(ctx, r.env, from, to)
+	if err != nil {
+		return err
+	}
+	diff := compare(res.current, res.derived)
+	rep.Current += len(res.current)
+	rep.Derived += len(res.derived)
+	rep.Added += len(diff.added)
+	rep.Removed += len(diff.removed)
+	rep.Changed += len(diff.changed)
+	rep.Samples = diff.sample(rep.Samples, res, samples)
+	if diff.empty() || !r.opts.Apply {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	if err := res.queue(batch); err != nil {
+		return err
+	}
+	if err := r.exec(ctx, batch); err != nil {
+		return err
+	}
+	rep.AppliedChunks++
+	r.logger.Info("rederive: chunk applied",
+		zap.String("deriver", d.name),
+		zap.Uint64("from", from), zap.Uint64("to", to),
+		zap.Int("added", len(diff.added)),
+		zap.Int("removed", len(diff.removed)),
+		zap.Int("changed", len(diff.changed)))
+	return nil
+}
+
+// rowSet maps a row key to the row's canonical rendering.
+type rowSet map[string]string
+
+// add stores value under key, suffixing "#2", "#3", ... when key is
+// already taken so that tables without a unique key still compare row
+// for row.
+func (s rowSet) add(key, value string) {
+	if _, ok := s[key]; !ok {
+		s[key] = value
+		return
+	}
+	for n := 2; ; n++ {
+		k := key + "#" + strconv.Itoa(n)
+		if _, ok := s[k]; !ok {
+			s[k] = value
+			return
+		}
+	}
+}
+
+// row renders fields as one canonical string. Amounts render as their
+// decimal value, with nil as 0, so a NULL-free NUMERIC column compares
+// equal to the big.Int it was written from.
+func row(fields ...interface{}) string {
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		switch v := f.(type) {
+		case *big.Int:
+			if v == nil {
+				parts[i] = "0"
+			} else {
+				parts[i] = v.String()
+			}
+		case *int64:
+			if v == nil {
+				parts[i] = "-"
+			} else {
+				parts[i] = strconv.FormatInt(*v, 10)
+			}
+		default:
+			parts[i] = fmt.Sprint(v)
+		}
+	}
+	return strings.Join(parts, "|")
+}
+
+// diff is the key-level difference between a current and a derived set.
+type diff struct {
+	added, removed, changed []string
+}
+
+func compare(current, derived rowSet) diff {
+	var d diff
+	for k, v := range derived {
+		cur, ok := current[k]
+		switch {
+		case !ok:
+			d.added = append(d.added, k)
+		case cur != v:
+			d.changed = append(d.changed, k)
+		}
+	}
+	for k := range current {
+		if _, ok := derived[k]; !ok {
+			d.removed = append(d.removed, k)
+		}
+	}
+	slices.Sort(d.added)
+	slices.Sort(d.removed)
+	slices.Sort(d.changed)
+	return d
+}
+
+func (d diff) empty() bool {
+	return len(d.added)+len(d.removed)+len(d.changed) == 0
+}
+
+// sample appends differing rows of res to out until it holds limit.
+func (d diff) sample(out []Sample, res *rebuilt, limit int) []Sample {
+	for _, group := range []struct {
+		change string
+		keys   []string
+	}{{"added", d.added}, {"removed", d.removed}, {"changed", d.changed}} {
+		for _, k := range group.keys {
+			if len(out) >= limit {
+				return out
+			}
+			out = append(out, Sample{Change: group.change, Key: k, Current: res.current[k], Derived: res.derived[k]})
+		}
+	}
+	return out
+}
+
+// execBatchTx sends batch inside a single transaction and commits it,
+// failing on the first statement that errors.
+func execBatchTx(ctx context.Context, pool *pgxpool.Pool, batch *pgx.Batch) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	results := tx.SendBatch(ctx, batch)
+	for j := 0; j < batch.Len(); j++ {
+		if _, err := results.Exec(); err != nil {
+			_ = results.Close()
+			return fmt.Errorf("batch op %d: %w", j, err)
+		}
+	}
+	if err := results.Close(); err != nil {
+		return fmt.Errorf("close batch results: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package rederive
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+func TestOptionsValidate(t *testing.T) {
+	for _, tc := range []struct {
+		opts    Options
+		wantErr bool
+	}{
+		{Options{}, false},
+		{Options{From: 5, To: 10, Only: []string{"votes", "rewards"}}, false},
+		{Options{From: 10, To: 5}, true},
+		{Options{Only: []string{"votes", "bogus"}}, true},
+	} {
+		if err := tc.opts.Validate(); (err != nil) != tc.wantErr {
+			t.Errorf("Validate(%+v) = %v, want error %v", tc.opts, err, tc.wantErr)
+		}
+	}
+}
+
+func TestSelectDerivers_KeepsRegistryOrder(t *testing.T) {
+	var got []string
+	for _, d := range selectDerivers([]string{"account-flows", "votes"}) {
+		got = append(got, d.name)
+	}
+	if want := []string{"votes", "account-flows"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("selectDerivers = %v, want %v", got, want)
+	}
+	if n := len(selectDerivers(nil)); n != len(Names()) {
+		t.Errorf("selectDerivers(nil) = %d derivers, want all %d", n, len(Names()))
+	}
+}
+
+func TestRowSetAdd_SuffixesDuplicates(t *testing.T) {
+	s := rowSet{}
+	s.add("k", "a")
+	s.add("k", "b")
+	s.add("k", "c")
+	want := rowSet{"k": "a", "k#2": "b", "k#3": "c"}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("rowSet = %v, want %v", s, want)
+	}
+}
+
+func TestRow(t *testing.T) {
+	var nilAmount *big.Int
+	var nilTs *int64
+	ts := int64(7)
+	if got := row("a", 1, int16(2), true, big.NewInt(10), nilAmount, &ts, nilTs); got != "a|1|2|true|10|0|7|-" {
+		t.Errorf("row = %q", got)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	d := compare(
+		rowSet{"same": "1", "changed": "1", "removed": "1"},
+		rowSet{"same": "1", "changed": "2", "added": "1"},
+	)
+	if !reflect.DeepEqual(d.added, []string{"added"}) ||
+		!reflect.DeepEqual(d.removed, []string{"removed"}) ||
+		!reflect.DeepEqual(d.changed, []string{"changed"}) {
+		t.Errorf("compare = %+v", d)
+	}
+	if d.empty() || !compare(rowSet{"a": "1"}, rowSet{"a": "1"}).empty() {
+		t.Error("empty() wrong")
+	}
+}
+
+// fakeDeriver serves canned sides per chunk and records what it was
+// asked for and what it queued.
+type fakeDeriver struct {
+	chunks [][2]uint64
+	queued [][2]uint64
+}
+
+func (f *fakeDeriver) deriver(name string) deriver {
+	return deriver{name: name, tables: []string{name}, rebuild: func(_ context.Context, _ *env, from, to uint64) (*rebuilt, error) {
+		f.chunks = append(f.chunks, [2]uint64{from, to})
+		res := &rebuilt{current: rowSet{"k": "same"}, derived: rowSet{"k": "same"}}
+		// The chunk starting at 11 disagrees with the blocks.
+		if from == 11 {
+			res.current = rowSet{"k": "old", "gone": "x"}
+			res.derived = rowSet{"k": "new", "extra": "y"}
+		}
+		res.queue = func(batch *pgx.Batch) error {
+			f.queued = append(f.queued, [2]uint64{from, to})
+			batch.Queue("SELECT 1")
+			return nil
+		}
+		return res, nil
+	}}
+}
+
+func newTestRunner(opts Options, derivers ...deriver) (*Runner, *int) {
+	execs := 0
+	return &Runner{
+		opts:     opts,
+		logger:   zap.NewNop(),
+		derivers: derivers,
+		env:      &env{logger: zap.NewNop()},
+		head:     func(context.Context) (uint64, error) { return 25, nil },
+		exec: func(_ context.Context, batch *pgx.Batch) error {
+			if batch.Len() == 0 {
+				return errors.New("empty batch")
+			}
+			execs++
+			return nil
+		},
+	}, &execs
+}
+
+func TestRunner_DryRunReportsWithoutWriting(t *testing.T) {
+	f := &fakeDeriver{}
+	r, execs := newTestRunner(Options{Step: 10}, f.deriver("fake"))
+
+	report, err := r.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// To defaults to the head; the last chunk is short.
+	if want := [][2]uint64{{1, 10}, {11, 20}, {21, 25}}; !reflect.DeepEqual(f.chunks, want) {
+		t.Errorf("chunks = %v, want %v", f.chunks, want)
+	}
+	if *execs != 0 || len(f.queued) != 0 {
+		t.Errorf("dry run wrote: execs=%d queued=%v", *execs, f.queued)
+	}
+	d := report.Derivers[0]
+	if report.From != 1 || report.To != 25 || report.Apply ||
+		d.Added != 1 || d.Removed != 1 || d.Changed != 1 || d.Current != 4 || d.Derived != 4 || d.AppliedChunks != 0 {
+		t.Errorf("report = %+v, deriver = %+v", report, d)
+	}
+	if report.Clean() {
+		t.Error("Clean() = true with differences")
+	}
+	wantSamples := []Sample{
+		{Change: "added", Key: "extra", Derived: "y"},
+		{Change: "removed", Key: "gone", Current: "x"},
+		{Change: "changed", Key: "k", Current: "old", Derived: "new"},
+	}
+	if !reflect.DeepEqual(d.Samples, wantSamples) {
+		t.Errorf("samples = %+v, want %+v", d.Samples, wantSamples)
+	}
+}
+
+func TestRunner_ApplyWritesOnlyDifferingChunks(t *testing.T) {
+	a, b := &fakeDeriver{}, &fakeDeriver{}
+	r, execs := newTestRunner(Options{From: 5, To: 24, Step: 6, Apply: true, Samples: 1},
+		a.deriver("a"), b.deriver("b"))
+
+	report, err := r.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Chunks 5-10, 11-16, 17-22, 23-24: only 11-16 differs, once per deriver.
+	if want := [][2]uint64{{11, 16}}; !reflect.DeepEqual(a.queued, want) || !reflect.DeepEqual(b.queued, want) {
+		t.Errorf("queued a=%v b=%v, want %v each", a.queued, b.queued, want)
+	}
+	if *execs != 2 {
+		t.Errorf("execs = %d, want 2 (one transaction per deriver per chunk)", *execs)
+	}
+	for _, d := range report.Derivers {
+		if d.AppliedChunks != 1 || len(d.Samples) != 1 {
+			t.Errorf("%s: applied=%d samples=%d", d.Name, d.AppliedChunks, len(d.Samples))
+		}
+	}
+}
+
+func TestRunner_StopsOnError(t *testing.T) {
+	boom := errors.New("boom")
+	failing := deriver{name: "failing", rebuild: func(context.Context, *env, uint64, uint64) (*rebuilt, error) {
+		return nil, boom
+	}}
+	r, _ := newTestRunner(Options{To: 5}, failing)
+	report, err := r.Run(context.Background())
+	if !errors.Is(err, boom) || report == nil {
+		t.Errorf("Run = %v, %v; want the deriver's error and a partial report", report, err)
+	}
+}
+
+func TestRunner_TopOfRangeDoesNotOverflow(t *testing.T) {
+	f := &fakeDeriver{}
+	const top = ^uint64(0)
+	r, _ := newTestRunner(Options{From: top - 1, To: top, Step: 10}, f.deriver("fake"))
+	if _, err := r.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want := [][2]uint64{{top - 1, top}}; !reflect.DeepEqual(f.chunks, want) {
+		t.Errorf("chunks = %v, want %v", f.chunks, want)
+	}
+}
@@ -0,0 +1,325 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+// AcceleratorRepository manages the Accelerator-Z history decoded from
+// the ledger: project_status_changes and accelerator_payouts, plus the
+// contract's balance over time.
+type AcceleratorRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewAcceleratorRepository constructs an AcceleratorRepository backed by
+// pool.
+func NewAcceleratorRepository(pool *pgxpool.Pool) *AcceleratorRepository {
+	return &AcceleratorRepository{pool: pool}
+}
+
+// Mirror go-zenon's constants.AcceleratorProjectVotingPeriod (seconds)
+// and constants.VoteAcceptanceThreshold (percent of active pillars).
+const (
+	acceleratorVotingPeriod = 14 * 24 * 60 * 60
+	voteAcceptanceThreshold = 33
+)
+
+const projectStatusChangeColumns = `account_block_hash, project_id, phase_id, voting_id, method, address,
+	old_status, new_status, znn_amount, qsr_amount, momentum_height, momentum_timestamp`
+
+const acceleratorPayoutColumns = `account_block_hash, update_block_hash, project_id, phase_id, to_address,
+	token_standard, amount, momentum_height, momentum_timestamp`
+
+// phaseProjectID is the project a phase belongs to: from the phase's own
+// status changes, else from the node's project_phases, else empty.
+func phaseProjectID(phaseParam string) string {
+	return `COALESCE(
+		(SELECT project_id FROM project_status_changes WHERE phase_id = ` + phaseParam + ` LIMIT 1),
+		(SELECT project_id FROM project_phases WHERE id = ` + phaseParam + `),
+		'')`
+}
+
+// latestStatus is the most recent status of a project (empty phase) or
+// phase. Statuses only move forward, so within a momentum the higher one
+// is the later.
+func latestStatus(projectExpr, phaseExpr string) string {
+	return `(SELECT new_status FROM project_status_changes
+		WHERE project_id = ` + projectExpr + ` AND phase_id = ` + phaseExpr + `
+		ORDER BY momentum_height DESC, new_status DESC LIMIT 1)`
+}
+
+// CreateProjectBatch enqueues an accepted CreateProject on the
+// per-momentum batch: the project's first row, in voting, carrying the
+// funds it asks for. Idempotent via the primary key.
+func (r *AcceleratorRepository) CreateProjectBatch(batch *pgx.Batch, c *models.ProjectStatusChange) {
+	batch.Queue(`
+		INSERT INTO project_status_changes (`+projectStatusChangeColumns+`)
+		VALUES ($1, $2, '', $3, 'CreateProject', $4, NULL, $5, $6, $7, $8, $9)
+		ON CONFLICT DO NOTHING`,
+		c.AccountBlockHash, c.ProjectID, c.VotingID, c.Address, int16(models.ProjectStatusVoting),
+		numeric(c.ZnnAmount), numeric(c.QsrAmount), c.MomentumHeight, c.MomentumTimestamp)
+}
+
+// AddPhaseBatch enqueues an AddPhase or UpdatePhase (c.Method) on the
+// per-momentum batch: the new phase's first row, in voting. Neither call
+// leaves a trace of being rejected, so the statement replays the
+// contract's checks against the recorded history: the caller created the
+// project, and its latest phase is paid (AddPhase) or still in voting
+// (UpdatePhase, which replaces it).
+func (r *AcceleratorRepository) AddPhaseBatch(batch *pgx.Batch, c *models.ProjectStatusChange) {
+	batch.Queue(`
+		WITH latest_phase AS (
+			SELECT `+latestStatus("c.project_id", "c.phase_id")+` AS status
+			FROM project_status_changes c
+			WHERE c.project_id = $2 AND c.phase_id <> '' AND c.old_status IS NULL
+			ORDER BY c.momentum_height DESC
+			LIMIT 1
+		)
+		INSERT INTO project_status_changes (`+projectStatusChangeColumns+`)
+		SELECT $1, $2, $3, $4, $5, $6, NULL, $7, $8, $9, $10, $11
+		WHERE EXISTS (SELECT 1 FROM project_status_changes
+				WHERE project_id = $2 AND phase_id = '' AND old_status IS NULL AND address = $6)
+			AND CASE WHEN $5 = 'UpdatePhase'
+				THEN (SELECT status FROM latest_phase) = $7
+				ELSE COALESCE((SELECT status FROM latest_phase), $12) = $12 END
+		ON CONFLICT DO NOTHING`,
+		c.AccountBlockHash, c.ProjectID, c.PhaseID, c.VotingID, c.Method, c.Address,
+		int16(models.ProjectStatusVoting), numeric(c.ZnnAmount), numeric(c.QsrAmount),
+		c.MomentumHeight, c.MomentumTimestamp, int16(models.ProjectStatusPaid))
+}
+
+// SettleBatch enqueues the project half of an accepted Update on the
+// per-momentum batch. The contract closes every project still in voting
+// once its voting period is over, and activates one inside the period
+// when more pillars voted yes than no and more than a third of the active
+// pillars voted. Neither change shows on the ledger, so the statement
+// replays both from the recorded votes and pillars.
+func (r *AcceleratorRepository) SettleBatch(batch *pgx.Batch, blockHash, address string, height, ts int64) {
+	batch.Queue(`
+		INSERT INTO project_status_changes (`+projectStatusChangeColumns+`)
+		SELECT $1, c.project_id, '', c.voting_id, 'Update', $2, $5,
+			CASE WHEN c.momentum_timestamp + $8 < $4 THEN $7::smallint ELSE $6::smallint END, 0, 0, $3, $4
+		FROM project_status_changes c
+		WHERE c.phase_id = '' AND c.old_status IS NULL
+			AND NOT EXISTS (SELECT 1 FROM project_status_changes l
+				WHERE l.project_id = c.project_id AND l.phase_id = '' AND l.old_status IS NOT NULL)
+			AND (c.momentum_timestamp + $8 < $4 OR (
+				SELECT COUNT(*) FILTER (WHERE v.vote = 0) > COUNT(*) FILTER (WHERE v.vote = 1)
+					AND COUNT(*) * 100 > $9 * (SELECT COUNT(*) FROM pillars p
+						WHERE p.spawn_timestamp <= $4 AND (NOT p.is_revoked OR p.revoke_timestamp > $4))
+				FROM votes v
+				WHERE v.voting_id = c.voting_id AND v.momentum_height <= $3))
+		ON CONFLICT DO NOTHING`,
+		blockHash, address, height, ts,
+		int16(models.ProjectStatusVoting), int16(models.ProjectStatusActive), int16(models.ProjectStatusClosed),
+		int64(acceleratorVotingPeriod), int64(voteAcceptanceThreshold))
+}
+
+// InsertPayoutBatch enqueues one payout send on the per-momentum batch.
+// An empty p.ProjectID is resolved from the phase. Idempotent via ON
+// CONFLICT (account_block_hash) DO NOTHING.
+func (r *AcceleratorRepository) InsertPayoutBatch(batch *pgx.Batch, p *models.AcceleratorPayout) {
+	batch.Queue(`
+		INSERT INTO accelerator_payouts (`+acceleratorPayoutColumns+`)
+		VALUES ($1, $2, COALESCE(NULLIF($3, ''), `+phaseProjectID("$4")+`), $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (account_block_hash) DO NOTHING`,
+		p.AccountBlockHash, p.UpdateBlockHash, p.ProjectID, p.PhaseID, p.ToAddress,
+		p.TokenStandard, numeric(p.Amount), p.MomentumHeight, p.MomentumTimestamp)
+}
+
+// PhasePaidBatch enqueues the phase half of an accepted Update on the
+// per-momentum batch: c.PhaseID moves to paid with the ZNN and QSR it
+// was sent. A second statement completes the project once its paid
+// phases add up to the funds it asked for, as the contract does.
+func (r *AcceleratorRepository) PhasePaidBatch(batch *pgx.Batch, c *models.ProjectStatusChange) {
+	batch.Queue(`
+		INSERT INTO project_status_changes (`+projectStatusChangeColumns+`)
+		SELECT $1, x.project_id, $2, $2, 'Update', $3, $4, $5, $6, $7, $8, $9
+		FROM (SELECT `+phaseProjectID("$2")+` AS project_id) x
+		ON CONFLICT DO NOTHING`,
+		c.AccountBlockHash, c.PhaseID, c.Address,
+		int16(models.ProjectStatusVoting), int16(models.ProjectStatusPaid),
+		numeric(c.ZnnAmount), numeric(c.QsrAmount), c.MomentumHeight, c.MomentumTimestamp)
+	batch.Queue(`
+		INSERT INTO project_status_changes (`+projectStatusChangeColumns+`)
+		SELECT $1, p.project_id, '', p.voting_id, 'Update', $3,
+			`+latestStatus("p.project_id", "''")+`, $4, 0, 0, $5, $6
+		FROM project_status_changes paid
+		JOIN project_status_changes p
+			ON p.project_id = paid.project_id AND p.phase_id = '' AND p.old_status IS NULL
+		WHERE paid.account_block_hash = $1 AND paid.phase_id = $2
+			AND (p.znn_amount, p.qsr_amount) = (
+				SELECT COALESCE(SUM(znn_amount), 0), COALESCE(SUM(qsr_amount), 0)
+				FROM project_status_changes
+				WHERE project_id = p.project_id AND phase_id <> '' AND new_status = $7)
+		ON CONFLICT DO NOTHING`,
+		c.AccountBlockHash, c.PhaseID, c.Address, int16(models.ProjectStatusCompleted),
+		c.MomentumHeight, c.MomentumTimestamp, int16(models.ProjectStatusPaid))
+}
+
+// History returns every status change of a project and its phases,
+// newest first.
+func (r *AcceleratorRepository) History(ctx context.Context, projectID string, opts ListOpts) ([]*models.ProjectStatusChange, int64, error) {
+	const where = `WHERE project_id = $1`
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+projectStatusChangeColumns+`, COUNT(*) OVER () AS total
+		FROM project_status_changes `+where+`
+		ORDER BY momentum_height DESC, new_status DESC, phase_id
+		LIMIT $2 OFFSET $3`, projectID, opts.Limit, opts.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("AcceleratorRepository.History: %w", err)
+	}
+	defer rows.Close()
+	var (
+		out   []*models.ProjectStatusChange
+		total int64
+	)
+	for rows.Next() {
+		c := &models.ProjectStatusChange{}
+		if err := rows.Scan(
+			&c.AccountBlockHash, &c.ProjectID, &c.PhaseID, &c.VotingID, &c.Method, &c.Address,
+			&c.OldStatus, &c.NewStatus, NumericDest(&c.ZnnAmount), NumericDest(&c.QsrAmount),
+			&c.MomentumHeight, &c.MomentumTimestamp, &total); err != nil {
+			return nil, 0, fmt.Errorf("AcceleratorRepository.History: %w", err)
+		}
+		out = append(out, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("AcceleratorRepository.History: %w", err)
+	}
+	if len(out) == 0 && opts.Offset > 0 {
+		if total, err = fallbackCount(ctx, r.pool, `SELECT COUNT(*) FROM project_status_changes `+where, projectID); err != nil {
+			return nil, 0, fmt.Errorf("AcceleratorRepository.History: %w", err)
+		}
+	}
+	return out, total, nil
+}
+
+// AcceleratorPayoutFilter narrows ListPayouts. Empty fields match
+// everything.
+type AcceleratorPayoutFilter struct {
+	ProjectID string
+	ToAddress string
+}
+
+// ListPayouts returns payout sends newest first.
+func (r *AcceleratorRepository) ListPayouts(ctx context.Context, f AcceleratorPayoutFilter, opts ListOpts) ([]*models.AcceleratorPayout, int64, error) {
+	const where = `WHERE ($1 = '' OR project_id = $1) AND ($2 = '' OR to_address = $2)`
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+acceleratorPayoutColumns+`, COUNT(*) OVER () AS total
+		FROM accelerator_payouts `+where+`
+		ORDER BY momentum_height DESC, account_block_hash
+		LIMIT $3 OFFSET $4`, f.ProjectID, f.ToAddress, opts.Limit, opts.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("AcceleratorRepository.ListPayouts: %w", err)
+	}
+	defer rows.Close()
+	var (
+		out   []*models.AcceleratorPayout
+		total int64
+	)
+	for rows.Next() {
+		p := &models.AcceleratorPayout{}
+		if err := rows.Scan(
+			&p.AccountBlockHash, &p.UpdateBlockHash, &p.ProjectID, &p.PhaseID, &p.ToAddress,
+			&p.TokenStandard, NumericDest(&p.Amount), &p.MomentumHeight, &p.MomentumTimestamp,
+			&total); err != nil {
+			return nil, 0, fmt.Errorf("AcceleratorRepository.ListPayouts: %w", err)
+		}
+		out = append(out, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("AcceleratorRepository.ListPayouts: %w", err)
+	}
+	if len(out) == 0 && opts.Offset > 0 {
+		if total, err = fallbackCount(ctx, r.pool, `SELECT COUNT(*) FROM accelerator_payouts `+where, f.ProjectID, f.ToAddress); err != nil {
+			return nil, 0, fmt.Errorf("AcceleratorRepository.ListPayouts: %w", err)
+		}
+	}
+	return out, total, nil
+}
+
+// acceleratorFlowsSQL lists every ZNN and QSR movement of the Accelerator
+// contract ($1) as a signed delta: sends ($2, $3) subtract their own
+// amount, receives ($4, $5) add their paired send's (a receive block's
+// own amount is zero), and the genesis receive adds its own.
+const acceleratorFlowsSQL = `
+	flows AS (
+		SELECT momentum_height, momentum_timestamp, token_standard,
+			CASE WHEN block_type IN ($2, $3) THEN -amount ELSE amount END AS delta
+		FROM account_blocks
+		WHERE address = $1 AND block_type NOT IN ($4, $5)
+		UNION ALL
+		SELECT r.momentum_height, r.momentum_timestamp, s.token_standard, s.amount
+		FROM account_blocks r
+		JOIN account_blocks s ON s.hash = r.paired_account_block
+		WHERE r.address = $1 AND r.block_type IN ($4, $5)
+	),
+	moved AS (
+		SELECT * FROM flows WHERE token_standard IN ($6, $7) AND delta <> 0
+	)`
+
+// Treasury returns the Accelerator contract's ZNN and QSR balance after
+// every momentum that moved either, newest first. Balances are running
+// sums of the contract's flows (see acceleratorFlowsSQL).
+func (r *AcceleratorRepository) Treasury(ctx context.Context, opts ListOpts) ([]*models.AcceleratorTreasuryPoint, int64, error) {
+	rows, err := r.pool.Query(ctx, `
+		WITH `+acceleratorFlowsSQL+`,
+		deltas AS (
+			SELECT momentum_height, MAX(momentum_timestamp) AS momentum_timestamp,
+				COALESCE(SUM(delta) FILTER (WHERE token_standard = $6), 0) AS znn_delta,
+				COALESCE(SUM(delta) FILTER (WHERE token_standard = $7), 0) AS qsr_delta
+			FROM moved
+			GROUP BY momentum_height
+		)
+		SELECT momentum_height, momentum_timestamp,
+			SUM(znn_delta) OVER (ORDER BY momentum_height),
+			SUM(qsr_delta) OVER (ORDER BY momentum_height),
+			znn_delta, qsr_delta, COUNT(*) OVER () AS total
+		FROM deltas
+		ORDER BY momentum_height DESC
+		LIMIT $8 OFFSET $9`,
+		append(acceleratorFlowsArgs(), opts.Limit, opts.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("AcceleratorRepository.Treasury: %w", err)
+	}
+	defer rows.Close()
+	var (
+		out   []*models.AcceleratorTreasuryPoint
+		total int64
+	)
+	for rows.Next() {
+		t := &models.AcceleratorTreasuryPoint{}
+		if err := rows.Scan(&t.MomentumHeight, &t.MomentumTimestamp,
+			NumericDest(&t.ZnnBalance), NumericDest(&t.QsrBalance),
+			NumericDest(&t.ZnnDelta), NumericDest(&t.QsrDelta), &total); err != nil {
+			return nil, 0, fmt.Errorf("AcceleratorRepository.Treasury: %w", err)
+		}
+		out = append(out, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("AcceleratorRepository.Treasury: %w", err)
+	}
+	if len(out) == 0 && opts.Offset > 0 {
+		if total, err = fallbackCount(ctx, r.pool, `
+			WITH `+acceleratorFlowsSQL+`
+			SELECT COUNT(DISTINCT momentum_height) FROM moved`,
+			acceleratorFlowsArgs()...); err != nil {
+			return nil, 0, fmt.Errorf("AcceleratorRepository.Treasury: %w", err)
+		}
+	}
+	return out, total, nil
+}
+
+func acceleratorFlowsArgs() []any {
+	return []any{models.AcceleratorAddress,
+		models.BlockTypeUserSend, models.BlockTypeContractSend,
+		models.BlockTypeUserReceive, models.BlockTypeContractReceive,
+		models.ZnnTokenStandard, models.QsrTokenStandard}
+}
@@ -23,70 +23,63 @@ func (r *AccountRepository) Upsert(ctx context.Context, a *models.Account) error
 		INSERT INTO accounts (address, block_count, public_key)
 		VALUES ($1, $2, $3)
 		ON CONFLICT (address) DO UPDATE SET
-			block_count = $2,
+			block_count = GREATEST(accounts.block_count, $2),
 			public_key = COALESCE(NULLIF($3, ''), accounts.public_key)`,
 		a.Address, a.BlockCount, a.PublicKey)
 	return err
 }
 
-// UpsertBatch adds an account upsert to a batch
+// UpsertBatch adds an account upsert to a batch. block_count only grows,
+// so reprocessing an old height leaves it at the chain's indexed height;
+// the reorg rollback recomputes it.
 func (r *AccountRepository) UpsertBatch(batch *pgx.Batch, a *models.Account) {
 	batch.Queue(`
 		INSERT INTO accounts (address, block_count, public_key)
 		VALUES ($1, $2, $3)
 		ON CONFLICT (address) DO UPDATE SET
-			block_count = $2,
+			block_count = GREATEST(accounts.block_count, $2),
 			public_key = COALESCE(NULLIF($3, ''), accounts.public_key)`,
 		a.Address, a.BlockCount, a.PublicKey)
 }
 
 // AddSendBatch increments the address's znn_sent or qsr_sent by the given
 // amount (depending on tokenStandard) and bumps first/last activity. Non-ZNN
-// /non-QSR tokens update activity only.
-func (r *AccountRepository) AddSendBatch(batch *pgx.Batch, address, tokenStandard string, amount, timestamp int64) {
-	col := flowColumn(tokenStandard, "sent")
-	if col == "" {
-		batch.Queue(`
-			INSERT INTO accounts (address, block_count, public_key, first_active_at, last_active_at)
-			VALUES ($1, 0, '', $2, $2)
-			ON CONFLICT (address) DO UPDATE SET
-				first_active_at = LEAST(COALESCE(accounts.first_active_at, EXCLUDED.first_active_at), EXCLUDED.first_active_at),
-				last_active_at = GREATEST(COALESCE(accounts.last_active_at, EXCLUDED.last_active_at), EXCLUDED.last_active_at)`,
-			address, timestamp)
-		return
-	}
-	// String concat is safe here: col comes from flowColumn's whitelist, never user input.
-	batch.Queue(`
-		INSERT INTO accounts (address, block_count, public_key, `+col+`, first_active_at, last_active_at)
-		VALUES ($1, 0, '', $2, $3, $3)
-		ON CONFLICT (address) DO UPDATE SET
-			`+col+` = accounts.`+col+` + EXCLUDED.`+col+`,
-			first_active_at = LEAST(COALESCE(accounts.first_active_at, EXCLUDED.first_active_at), EXCLUDED.first_active_at),
-			last_active_at = GREATEST(COALESCE(accounts.last_active_at, EXCLUDED.last_active_at), EXCLUDED.last_active_at)`,
-		address, amount, timestamp)
+// /non-QSR tokens update activity only. The update applies once per
+// blockHash; see blockEffectsPending.
+func (r *AccountRepository) AddSendBatch(batch *pgx.Batch, blockHash, address, tokenStandard string, amount, timestamp int64) {
+	r.addFlowBatch(batch, blockHash, address, flowColumn(tokenStandard, "sent"), amount, timestamp)
 }
 
 // AddReceiveBatch is the receive-side analog of AddSendBatch.
-func (r *AccountRepository) AddReceiveBatch(batch *pgx.Batch, address, tokenStandard string, amount, timestamp int64) {
-	col := flowColumn(tokenStandard, "received")
+func (r *AccountRepository) AddReceiveBatch(batch *pgx.Batch, blockHash, address, tokenStandard string, amount, timestamp int64) {
+	r.addFlowBatch(batch, blockHash, address, flowColumn(tokenStandard, "received"), amount, timestamp)
+}
+
+// addFlowBatch adds amount to col ("" for activity only) once per
+// blockHash. The insert branch only runs for an address with no row,
+// which the block cannot have been counted for yet.
+func (r *AccountRepository) addFlowBatch(batch *pgx.Batch, blockHash, address, col string, amount, timestamp int64) {
 	if col == "" {
 		batch.Queue(`
 			INSERT INTO accounts (address, block_count, public_key, first_active_at, last_active_at)
 			VALUES ($1, 0, '', $2, $2)
 			ON CONFLICT (address) DO UPDATE SET
 				first_active_at = LEAST(COALESCE(accounts.first_active_at, EXCLUDED.first_active_at), EXCLUDED.first_active_at),
-				last_active_at = GREATEST(COALESCE(accounts.last_active_at, EXCLUDED.last_active_at), EXCLUDED.last_active_at)`,
-			address, timestamp)
+				last_active_at = GREATEST(COALESCE(accounts.last_active_at, EXCLUDED.last_active_at), EXCLUDED.last_active_at)
+			WHERE `+blockEffectsPending("$3"),
+			address, timestamp, blockHash)
 		return
 	}
+	// String concat is safe here: col comes from flowColumn's whitelist, never user input.
 	batch.Queue(`
 		INSERT INTO accounts (address, block_count, public_key, `+col+`, first_active_at, last_active_at)
 		VALUES ($1, 0, '', $2, $3, $3)
 		ON CONFLICT (address) DO UPDATE SET
 			`+col+` = accounts.`+col+` + EXCLUDED.`+col+`,
 			first_active_at = LEAST(COALESCE(accounts.first_active_at, EXCLUDED.first_active_at), EXCLUDED.first_active_at),
-			last_active_at = GREATEST(COALESCE(accounts.last_active_at, EXCLUDED.last_active_at), EXCLUDED.last_active_at)`,
-		address, amount, timestamp)
+			last_active_at = GREATEST(COALESCE(accounts.last_active_at, EXCLUDED.last_active_at), EXCLUDED.last_active_at)
+		WHERE `+blockEffectsPending("$4"),
+		address, amount, timestamp, blockHash)
 }
 
 // BumpTxCountBatch increments tx_count by 1 and updates first_seen
@@ -97,15 +90,17 @@ func (r *AccountRepository) AddReceiveBatch(batch *pgx.Batch, address, tokenStan
 // Upserts a stub row when the address has no prior account entry; this
 // is how to_address-only recipients (sends not yet claimed) acquire
 // rows so their tx_count survives a future GET /accounts/{address}.
-func (r *AccountRepository) BumpTxCountBatch(batch *pgx.Batch, address string, timestamp int64) {
+// The bump applies once per blockHash; see blockEffectsPending.
+func (r *AccountRepository) BumpTxCountBatch(batch *pgx.Batch, blockHash, address string, timestamp int64) {
 	batch.Queue(`
 		INSERT INTO accounts (address, block_count, public_key, first_seen, last_seen, tx_count)
 		VALUES ($1, 0, '', $2, $2, 1)
 		ON CONFLICT (address) DO UPDATE SET
 			first_seen = LEAST(COALESCE(accounts.first_seen, EXCLUDED.first_seen), EXCLUDED.first_seen),
 			last_seen  = GREATEST(COALESCE(accounts.last_seen, EXCLUDED.last_seen), EXCLUDED.last_seen),
-			tx_count   = accounts.tx_count + 1`,
-		address, timestamp)
+			tx_count   = accounts.tx_count + 1
+		WHERE `+blockEffectsPending("$3"),
+		address, timestamp, blockHash)
 }
 
 // flowColumn maps (token_standard, direction) to the accounts column name.
@@ -55,7 +55,7 @@ func (r *AccountBlockRepository) Insert(ctx context.Context, ab *models.AccountB
 			input = EXCLUDED.input,
 			paired_account_block = EXCLUDED.paired_account_block`,
 		ab.Hash, ab.MomentumHash, ab.MomentumTimestamp, ab.MomentumHeight, ab.BlockType,
-		ab.Height, ab.Address, ab.ToAddress, ab.Amount, ab.TokenStandard, ab.Data, method, input, ab.PairedAccountBlock)
+		ab.Height, ab.Address, ab.ToAddress, numeric(ab.Amount), ab.TokenStandard, ab.Data, method, input, ab.PairedAccountBlock)
 	return err
 }
 
@@ -83,7 +83,7 @@ func (r *AccountBlockRepository) InsertBatch(batch *pgx.Batch, ab *models.Accoun
 			input = EXCLUDED.input,
 			paired_account_block = EXCLUDED.paired_account_block`,
 		ab.Hash, ab.MomentumHash, ab.MomentumTimestamp, ab.MomentumHeight, ab.BlockType,
-		ab.Height, ab.Address, ab.ToAddress, ab.Amount, ab.TokenStandard, ab.Data, method, input, ab.PairedAccountBlock)
+		ab.Height, ab.Address, ab.ToAddress, numeric(ab.Amount), ab.TokenStandard, ab.Data, method, input, ab.PairedAccountBlock)
 }
 
 // UpdatePairedBlock updates the paired account block reference
@@ -125,7 +125,7 @@ func (r *AccountBlockRepository) GetByHash(ctx context.Context, hash string) (*m
 			paired_account_block, descendant_of
 		FROM account_blocks WHERE hash = $1`, hash).Scan(
 		&ab.Hash, &ab.MomentumHash, &ab.MomentumTimestamp, &ab.MomentumHeight, &ab.BlockType,
-		&ab.Height, &ab.Address, &ab.ToAddress, &ab.Amount, &ab.TokenStandard, &ab.Data,
+		&ab.Height, &ab.Address, &ab.ToAddress, NumericDest(&ab.Amount), &ab.TokenStandard, &ab.Data,
 		&ab.Method, &ab.Input, &ab.PairedAccountBlock, &ab.DescendantOf)
 	if err != nil {
 		return nil, err
@@ -169,16 +169,21 @@ const accountBlockCols = `hash, momentum_hash, momentum_timestamp, momentum_heig
 	height, address, to_address, amount, token_standard, data, method, input,
 	paired_account_block, descendant_of`
 
+// accountBlockDest returns the Scan destinations for accountBlockCols.
+func accountBlockDest(ab *models.AccountBlock) []interface{} {
+	return []interface{}{
+		&ab.Hash, &ab.MomentumHash, &ab.MomentumTimestamp, &ab.MomentumHeight, &ab.BlockType,
+		&ab.Height, &ab.Address, &ab.ToAddress, NumericDest(&ab.Amount), &ab.TokenStandard, &ab.Data,
+		&ab.Method, &ab.Input, &ab.PairedAccountBlock, &ab.DescendantOf,
+	}
+}
+
 // scanAccountBlock reads one account_blocks row. total may be nil for
 // callers that compute total via a separate query (see List below); when
 // non-nil, the SELECT must include `COUNT(*) OVER () AS total` as the
 // final column.
 func scanAccountBlock(rows pgx.Row, ab *models.AccountBlock, total *int64) error {
-	dst := []interface{}{
-		&ab.Hash, &ab.MomentumHash, &ab.MomentumTimestamp, &ab.MomentumHeight, &ab.BlockType,
-		&ab.Height, &ab.Address, &ab.ToAddress, &ab.Amount, &ab.TokenStandard, &ab.Data,
-		&ab.Method, &ab.Input, &ab.PairedAccountBlock, &ab.DescendantOf,
-	}
+	dst := accountBlockDest(ab)
 	if total != nil {
 		dst = append(dst, total)
 	}
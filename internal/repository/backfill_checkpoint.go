@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+// BackfillCheckpointRepository stores the progress of named backfill
+// runs so an interrupted run can resume.
+type BackfillCheckpointRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewBackfillCheckpointRepository constructs a BackfillCheckpointRepository backed by pool.
+func NewBackfillCheckpointRepository(pool *pgxpool.Pool) *BackfillCheckpointRepository {
+	return &BackfillCheckpointRepository{pool: pool}
+}
+
+// Get returns the checkpoint called name. The error wraps pgx.ErrNoRows
+// when there is none.
+func (r *BackfillCheckpointRepository) Get(ctx context.Context, name string) (*models.BackfillCheckpoint, error) {
+	var c models.BackfillCheckpoint
+	err := r.pool.QueryRow(ctx, `
+		SELECT name, mode, contracts, from_height, to_height, next_height,
+			started_at, updated_at, completed_at
+		FROM backfill_checkpoints
+		WHERE name = $1`, name).Scan(&c.Name, &c.Mode, &c.Contracts, &c.FromHeight, &c.ToHeight,
+		&c.NextHeight, &c.StartedAt, &c.UpdatedAt, &c.CompletedAt)
+	if err != nil {
+		return nil, fmt.Errorf("BackfillCheckpointRepository.Get: %w", err)
+	}
+	return &c, nil
+}
+
+// Save writes c, replacing any checkpoint with the same name. Used to
+// start (or restart) a run.
+func (r *BackfillCheckpointRepository) Save(ctx context.Context, c *models.BackfillCheckpoint) error {
+	contracts := c.Contracts
+	if contracts == nil {
+		contracts = []string{} // the column is NOT NULL
+	}
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO backfill_checkpoints (name, mode, contracts, from_height, to_height,
+			next_height, started_at, updated_at, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (name) DO UPDATE SET
+			mode = EXCLUDED.mode, contracts = EXCLUDED.contracts,
+			from_height = EXCLUDED.from_height, to_height = EXCLUDED.to_height,
+			next_height = EXCLUDED.next_height, started_at = EXCLUDED.started_at,
+			updated_at = EXCLUDED.updated_at, completed_at = EXCLUDED.completed_at`,
+		c.Name, c.Mode, contracts, c.FromHeight, c.ToHeight, c.NextHeight,
+		c.StartedAt, c.UpdatedAt, c.CompletedAt)
+	if err != nil {
+		return fmt.Errorf("BackfillCheckpointRepository.Save: %w", err)
+	}
+	return nil
+}
+
+// Advance records that every height below next is done.
+func (r *BackfillCheckpointRepository) Advance(ctx context.Context, name string, next uint64, now int64) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE backfill_checkpoints SET next_height = $2, updated_at = $3
+		WHERE name = $1`, name, next, now)
+	if err != nil {
+		return fmt.Errorf("BackfillCheckpointRepository.Advance: %w", err)
+	}
+	return nil
+}
+
+// Complete marks the run finished: next_height moves past to_height.
+func (r *BackfillCheckpointRepository) Complete(ctx context.Context, name string, now int64) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE backfill_checkpoints SET
+			next_height = to_height + 1, updated_at = $2, completed_at = $2
+		WHERE name = $1`, name, now)
+	if err != nil {
+		return fmt.Errorf("BackfillCheckpointRepository.Complete: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+func TestIntegration_Momentum_GapsInRange(t *testing.T) {
+	pool := newTestDB(t)
+	ctx := context.Background()
+	repo := NewMomentumRepository(pool)
+	blocks := NewAccountBlockRepository(pool)
+
+	// Heights 1-10 minus 4 and 7. Height 5 claims two blocks but has one;
+	// height 6 has both of its two.
+	for h := uint64(1); h <= 10; h++ {
+		if h == 4 || h == 7 {
+			continue
+		}
+		m := &models.Momentum{Height: h, Hash: fmt.Sprintf("0xm%d", h), Timestamp: int64(h), Producer: "z1qp"}
+		if h == 5 || h == 6 {
+			m.TxCount = 2
+		}
+		if err := repo.Insert(ctx, m); err != nil {
+			t.Fatalf("insert momentum %d: %v", h, err)
+		}
+	}
+	for i, h := range []uint64{5, 6, 6} {
+		ab := &models.AccountBlock{
+			Hash: fmt.Sprintf("0xab%d", i), MomentumHash: fmt.Sprintf("0xm%d", h), MomentumHeight: int64(h),
+			BlockType: 2, Height: int64(i + 1), Address: "z1qx", Amount: big.NewInt(0),
+			TokenStandard: models.ZnnTokenStandard,
+		}
+		if err := blocks.Insert(ctx, ab, nil); err != nil {
+			t.Fatalf("insert block: %v", err)
+		}
+	}
+
+	for _, tc := range []struct {
+		from, to uint64
+		want     []uint64
+	}{
+		{1, 12, []uint64{4, 5, 7, 11, 12}},
+		{5, 6, []uint64{5}},
+		{8, 10, nil},
+		{9, 3, nil},
+	} {
+		got, err := repo.GapsInRange(ctx, tc.from, tc.to)
+		if err != nil {
+			t.Fatalf("GapsInRange(%d, %d): %v", tc.from, tc.to, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("GapsInRange(%d, %d) = %v, want %v", tc.from, tc.to, got, tc.want)
+		}
+	}
+}
+
+func TestIntegration_BackfillCheckpoint_Lifecycle(t *testing.T) {
+	pool := newTestDB(t)
+	ctx := context.Background()
+	repo := NewBackfillCheckpointRepository(pool)
+
+	if _, err := repo.Get(ctx, "missing"); !errors.Is(err, pgx.ErrNoRows) {
+		t.Fatalf("Get(missing) err = %v, want pgx.ErrNoRows", err)
+	}
+
+	cp := &models.BackfillCheckpoint{
+		Name: "reprocess:1-500:pillar", Mode: models.BackfillModeReprocess, Contracts: []string{"pillar"},
+		FromHeight: 1, ToHeight: 500, NextHeight: 1, StartedAt: 100, UpdatedAt: 100,
+	}
+	if err := repo.Save(ctx, cp); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if err := repo.Advance(ctx, cp.Name, 201, 150); err != nil {
+		t.Fatalf("advance: %v", err)
+	}
+	got, err := repo.Get(ctx, cp.Name)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	want := *cp
+	want.NextHeight, want.UpdatedAt = 201, 150
+	if !reflect.DeepEqual(got, &want) {
+		t.Errorf("after advance = %+v, want %+v", got, &want)
+	}
+
+	if err := repo.Complete(ctx, cp.Name, 200); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	got, _ = repo.Get(ctx, cp.Name)
+	if got.NextHeight != 501 || got.CompletedAt == nil || *got.CompletedAt != 200 {
+		t.Errorf("after complete = %+v", got)
+	}
+
+	// Save over an existing name restarts it; nil contracts store as empty.
+	restart := &models.BackfillCheckpoint{
+		Name: cp.Name, Mode: models.BackfillModeGaps,
+		FromHeight: 1, ToHeight: 900, NextHeight: 1, StartedAt: 300, UpdatedAt: 300,
+	}
+	if err := repo.Save(ctx, restart); err != nil {
+		t.Fatalf("restart: %v", err)
+	}
+	got, _ = repo.Get(ctx, cp.Name)
+	if got.Mode != models.BackfillModeGaps || got.NextHeight != 1 || got.CompletedAt != nil || len(got.Contracts) != 0 {
+		t.Errorf("after restart = %+v", got)
+	}
+}
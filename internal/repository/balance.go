@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"math/big"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -26,7 +27,7 @@ func (r *BalanceRepository) Upsert(ctx context.Context, b *models.Balance) error
 		ON CONFLICT (address, token_standard) DO UPDATE SET
 			balance = EXCLUDED.balance,
 			last_updated_timestamp = EXCLUDED.last_updated_timestamp`,
-		b.Address, b.TokenStandard, b.Balance, b.LastUpdatedTimestamp)
+		b.Address, b.TokenStandard, numeric(b.Balance), b.LastUpdatedTimestamp)
 	return err
 }
 
@@ -38,7 +39,62 @@ func (r *BalanceRepository) UpsertBatch(batch *pgx.Batch, b *models.Balance) {
 		ON CONFLICT (address, token_standard) DO UPDATE SET
 			balance = EXCLUDED.balance,
 			last_updated_timestamp = EXCLUDED.last_updated_timestamp`,
-		b.Address, b.TokenStandard, b.Balance, b.LastUpdatedTimestamp)
+		b.Address, b.TokenStandard, numeric(b.Balance), b.LastUpdatedTimestamp)
+}
+
+// AddDeltaBatch adds delta, which may be negative, to an address's
+// balance of tokenStandard, applied once per momentum height; see
+// momentumEffectsPending. The insert branch only runs for a pair with no
+// row, which the momentum cannot have been counted for yet. An applied
+// delta is recorded in balance_history with the balance it produced.
+func (r *BalanceRepository) AddDeltaBatch(batch *pgx.Batch, height uint64, address, tokenStandard string, delta *big.Int, timestamp int64) {
+	batch.Queue(`
+		WITH applied AS (
+			INSERT INTO balances (address, token_standard, balance, last_updated_timestamp)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (address, token_standard) DO UPDATE SET
+				balance = balances.balance + EXCLUDED.balance,
+				last_updated_timestamp = GREATEST(balances.last_updated_timestamp, EXCLUDED.last_updated_timestamp)
+			WHERE `+momentumEffectsPending("$5")+`
+			RETURNING balance
+		)
+		INSERT INTO balance_history (address, token_standard, momentum_height, momentum_timestamp, balance, delta)
+		SELECT $1, $2, $5, $4, balance, $3 FROM applied
+		ON CONFLICT (address, token_standard, momentum_height) DO UPDATE SET
+			momentum_timestamp = EXCLUDED.momentum_timestamp,
+			balance = EXCLUDED.balance,
+			delta = EXCLUDED.delta`,
+		address, tokenStandard, numeric(delta), timestamp, height)
+}
+
+// UpsertAtHeightBatch is UpsertBatch for a balance read from the node
+// when the address's chain was at accountHeight. It writes only while
+// the indexed chain is at the same height (accounts.block_count), so a
+// block indexed since the read is not overwritten.
+//
+// A write that changes the balance is a correction of drift the indexed
+// blocks do not explain, genesis balances above all, so it is taken to
+// predate the pair's balance_history: every recorded balance moves by
+// the same difference and the deltas stay as they are.
+func (r *BalanceRepository) UpsertAtHeightBatch(batch *pgx.Batch, b *models.Balance, accountHeight uint64) {
+	batch.Queue(`
+		WITH prev AS (
+			SELECT balance FROM balances WHERE address = $1 AND token_standard = $2
+		), written AS (
+			INSERT INTO balances (address, token_standard, balance, last_updated_timestamp)
+			SELECT $1, $2, $3, $4
+			WHERE EXISTS (SELECT 1 FROM accounts WHERE address = $1 AND block_count = $5)
+			ON CONFLICT (address, token_standard) DO UPDATE SET
+				balance = EXCLUDED.balance,
+				last_updated_timestamp = EXCLUDED.last_updated_timestamp
+			RETURNING balance
+		)
+		UPDATE balance_history h
+		SET balance = h.balance + w.balance - COALESCE((SELECT balance FROM prev), 0)
+		FROM written w
+		WHERE h.address = $1 AND h.token_standard = $2
+			AND w.balance <> COALESCE((SELECT balance FROM prev), 0)`,
+		b.Address, b.TokenStandard, numeric(b.Balance), b.LastUpdatedTimestamp, accountHeight)
 }
 
 // GetByAddressAndToken retrieves a balance by address and token standard
@@ -47,7 +103,7 @@ func (r *BalanceRepository) GetByAddressAndToken(ctx context.Context, address, t
 	err := r.pool.QueryRow(ctx, `
 		SELECT address, token_standard, balance
 		FROM balances WHERE address = $1 AND token_standard = $2`,
-		address, tokenStandard).Scan(&b.Address, &b.TokenStandard, &b.Balance)
+		address, tokenStandard).Scan(&b.Address, &b.TokenStandard, NumericDest(&b.Balance))
 	if err != nil {
 		return nil, err
 	}
@@ -83,7 +139,7 @@ func (r *BalanceRepository) ListByAddress(ctx context.Context, address string) (
 	var out []*models.Balance
 	for rows.Next() {
 		var b models.Balance
-		if err := rows.Scan(&b.Address, &b.TokenStandard, &b.Balance, &b.LastUpdatedTimestamp); err != nil {
+		if err := rows.Scan(&b.Address, &b.TokenStandard, NumericDest(&b.Balance), &b.LastUpdatedTimestamp); err != nil {
 			return nil, err
 		}
 		out = append(out, &b)
@@ -117,7 +173,7 @@ func (r *BalanceRepository) ListByToken(ctx context.Context, tokenStandard strin
 	)
 	for rows.Next() {
 		var b models.Balance
-		if err := rows.Scan(&b.Address, &b.TokenStandard, &b.Balance, &b.LastUpdatedTimestamp, &total); err != nil {
+		if err := rows.Scan(&b.Address, &b.TokenStandard, NumericDest(&b.Balance), &b.LastUpdatedTimestamp, &total); err != nil {
 			return nil, 0, err
 		}
 		out = append(out, &b)
@@ -135,3 +191,110 @@ func (r *BalanceRepository) ListByToken(ctx context.Context, tokenStandard strin
 	}
 	return out, total, nil
 }
+
+// ListByAddressAtHeight is ListByAddress as of momentum height: each
+// token's balance after the last recorded change at or before height, or
+// before the first change after it. Pairs with no recorded change keep
+// their current balance. Tokens the address held nothing of at height
+// are left out, and LastUpdatedTimestamp is that of the change the
+// balance comes from (0 when none is at or before height).
+func (r *BalanceRepository) ListByAddressAtHeight(ctx context.Context, address string, height uint64) ([]*models.Balance, error) {
+	if address == "" {
+		return nil, fmt.Errorf("address is required")
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT address, token_standard, balance, last_updated_timestamp FROM (
+			SELECT b.address, b.token_standard,
+				COALESCE(past.balance, next.balance - next.delta, b.balance) AS balance,
+				CASE
+					WHEN past.balance IS NOT NULL THEN past.momentum_timestamp
+					WHEN next.balance IS NOT NULL THEN 0
+					ELSE b.last_updated_timestamp
+				END AS last_updated_timestamp
+			FROM balances b
+			LEFT JOIN LATERAL (
+				SELECT balance, momentum_timestamp FROM balance_history h
+				WHERE h.address = b.address AND h.token_standard = b.token_standard
+					AND h.momentum_height <= $2
+				ORDER BY h.momentum_height DESC LIMIT 1
+			) past ON true
+			LEFT JOIN LATERAL (
+				SELECT balance, delta FROM balance_history h
+				WHERE h.address = b.address AND h.token_standard = b.token_standard
+					AND h.momentum_height > $2
+				ORDER BY h.momentum_height LIMIT 1
+			) next ON true
+			WHERE b.address = $1
+		) at_height
+		WHERE balance <> 0
+		ORDER BY balance DESC`, address, height)
+	if err != nil {
+		return nil, fmt.Errorf("BalanceRepository.ListByAddressAtHeight: %w", err)
+	}
+	defer rows.Close()
+
+	var out []*models.Balance
+	for rows.Next() {
+		var b models.Balance
+		if err := rows.Scan(&b.Address, &b.TokenStandard, NumericDest(&b.Balance), &b.LastUpdatedTimestamp); err != nil {
+			return nil, fmt.Errorf("BalanceRepository.ListByAddressAtHeight: %w", err)
+		}
+		out = append(out, &b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("BalanceRepository.ListByAddressAtHeight: %w", err)
+	}
+	return out, nil
+}
+
+// BalanceHistoryFilter narrows History. An empty TokenStandard matches
+// every token; FromHeight and ToHeight bound momentum_height inclusively,
+// 0 leaving that end open.
+type BalanceHistoryFilter struct {
+	TokenStandard string
+	FromHeight    uint64
+	ToHeight      uint64
+}
+
+// History returns an address's balance changes newest first, one row
+// per (token, momentum) that changed the balance.
+func (r *BalanceRepository) History(ctx context.Context, address string, f BalanceHistoryFilter, opts ListOpts) ([]*models.BalanceChange, int64, error) {
+	if address == "" {
+		return nil, 0, fmt.Errorf("address is required")
+	}
+	const where = `WHERE address = $1 AND ($2 = '' OR token_standard = $2)
+		AND momentum_height >= $3 AND ($4 = 0 OR momentum_height <= $4)`
+	rows, err := r.pool.Query(ctx, `
+		SELECT address, token_standard, momentum_height, momentum_timestamp, balance, delta,
+			COUNT(*) OVER () AS total
+		FROM balance_history `+where+`
+		ORDER BY momentum_height DESC, token_standard
+		LIMIT $5 OFFSET $6`,
+		address, f.TokenStandard, f.FromHeight, f.ToHeight, opts.Limit, opts.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("BalanceRepository.History: %w", err)
+	}
+	defer rows.Close()
+	var (
+		out   []*models.BalanceChange
+		total int64
+	)
+	for rows.Next() {
+		c := &models.BalanceChange{}
+		if err := rows.Scan(&c.Address, &c.TokenStandard, &c.MomentumHeight, &c.MomentumTimestamp,
+			NumericDest(&c.Balance), NumericDest(&c.Delta), &total); err != nil {
+			return nil, 0, fmt.Errorf("BalanceRepository.History: %w", err)
+		}
+		out = append(out, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("BalanceRepository.History: %w", err)
+	}
+	if len(out) == 0 && opts.Offset > 0 {
+		if total, err = fallbackCount(ctx, r.pool, `SELECT COUNT(*) FROM balance_history `+where,
+			address, f.TokenStandard, f.FromHeight, f.ToHeight); err != nil {
+			return nil, 0, fmt.Errorf("BalanceRepository.History: %w", err)
+		}
+	}
+	return out, total, nil
+}
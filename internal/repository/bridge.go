@@ -19,38 +19,60 @@ func NewBridgeRepository(pool *pgxpool.Pool) *BridgeRepository {
 	return &BridgeRepository{pool: pool}
 }
 
+const upsertWrapRequestSQL = `
+	INSERT INTO wrap_token_requests (id, network_class, chain_id, to_address, token_standard,
+		token_address, amount, fee, signature, creation_momentum_height, confirmations_to_finality)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	ON CONFLICT (id) DO UPDATE SET
+		signature = EXCLUDED.signature,
+		confirmations_to_finality = EXCLUDED.confirmations_to_finality`
+
+func upsertWrapRequestArgs(w *models.WrapTokenRequest) []any {
+	return []any{w.ID, w.NetworkClass, w.ChainID, w.ToAddress, w.TokenStandard,
+		w.TokenAddress, numeric(w.Amount), numeric(w.Fee), w.Signature, w.CreationMomentumHeight, w.ConfirmationsToFinality}
+}
+
 // UpsertWrapRequest inserts or updates a wrap token request
 func (r *BridgeRepository) UpsertWrapRequest(ctx context.Context, w *models.WrapTokenRequest) error {
-	_, err := r.pool.Exec(ctx, `
-		INSERT INTO wrap_token_requests (id, network_class, chain_id, to_address, token_standard,
-			token_address, amount, fee, signature, creation_momentum_height, confirmations_to_finality)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
-		ON CONFLICT (id) DO UPDATE SET
-			signature = EXCLUDED.signature,
-			confirmations_to_finality = EXCLUDED.confirmations_to_finality`,
-		w.ID, w.NetworkClass, w.ChainID, w.ToAddress, w.TokenStandard,
-		w.TokenAddress, w.Amount, w.Fee, w.Signature, w.CreationMomentumHeight, w.ConfirmationsToFinality)
+	_, err := r.pool.Exec(ctx, upsertWrapRequestSQL, upsertWrapRequestArgs(w)...)
 	return err
 }
 
+// UpsertWrapRequestBatch queues UpsertWrapRequest on batch, for callers
+// that write webhook outbox rows in the same transaction.
+func (r *BridgeRepository) UpsertWrapRequestBatch(batch *pgx.Batch, w *models.WrapTokenRequest) {
+	batch.Queue(upsertWrapRequestSQL, upsertWrapRequestArgs(w)...)
+}
+
+const upsertUnwrapRequestSQL = `
+	INSERT INTO unwrap_token_requests (transaction_hash, log_index, network_class, chain_id,
+		to_address, token_standard, token_address, amount, signature,
+		registration_momentum_height, redeemed, revoked, redeemable_in)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	ON CONFLICT (transaction_hash, log_index) DO UPDATE SET
+		signature = EXCLUDED.signature,
+		redeemed = EXCLUDED.redeemed,
+		revoked = EXCLUDED.revoked,
+		redeemable_in = EXCLUDED.redeemable_in`
+
+func upsertUnwrapRequestArgs(u *models.UnwrapTokenRequest) []any {
+	return []any{u.TransactionHash, u.LogIndex, u.NetworkClass, u.ChainID,
+		u.ToAddress, u.TokenStandard, u.TokenAddress, numeric(u.Amount), u.Signature,
+		u.RegistrationMomentumHeight, u.Redeemed, u.Revoked, u.RedeemableIn}
+}
+
 // UpsertUnwrapRequest inserts or updates an unwrap token request
 func (r *BridgeRepository) UpsertUnwrapRequest(ctx context.Context, u *models.UnwrapTokenRequest) error {
-	_, err := r.pool.Exec(ctx, `
-		INSERT INTO unwrap_token_requests (transaction_hash, log_index, network_class, chain_id,
-			to_address, token_standard, token_address, amount, signature,
-			registration_momentum_height, redeemed, revoked, redeemable_in)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
-		ON CONFLICT (transaction_hash, log_index) DO UPDATE SET
-			signature = EXCLUDED.signature,
-			redeemed = EXCLUDED.redeemed,
-			revoked = EXCLUDED.revoked,
-			redeemable_in = EXCLUDED.redeemable_in`,
-		u.TransactionHash, u.LogIndex, u.NetworkClass, u.ChainID,
-		u.ToAddress, u.TokenStandard, u.TokenAddress, u.Amount, u.Signature,
-		u.RegistrationMomentumHeight, u.Redeemed, u.Revoked, u.RedeemableIn)
+	_, err := r.pool.Exec(ctx, upsertUnwrapRequestSQL, upsertUnwrapRequestArgs(u)...)
 	return err
 }
 
+// UpsertUnwrapRequestBatch queues UpsertUnwrapRequest on batch, for
+// callers that write webhook outbox rows in the same transaction.
+func (r *BridgeRepository) UpsertUnwrapRequestBatch(batch *pgx.Batch, u *models.UnwrapTokenRequest) {
+	batch.Queue(upsertUnwrapRequestSQL, upsertUnwrapRequestArgs(u)...)
+}
+
 // GetWrapRequestByID retrieves a wrap request by ID
 func (r *BridgeRepository) GetWrapRequestByID(ctx context.Context, id string) (*models.WrapTokenRequest, error) {
 	var w models.WrapTokenRequest
@@ -59,7 +81,7 @@ func (r *BridgeRepository) GetWrapRequestByID(ctx context.Context, id string) (*
 			token_address, amount, fee, signature, creation_momentum_height, confirmations_to_finality
 		FROM wrap_token_requests WHERE id = $1`, id).Scan(
 		&w.ID, &w.NetworkClass, &w.ChainID, &w.ToAddress, &w.TokenStandard,
-		&w.TokenAddress, &w.Amount, &w.Fee, &w.Signature, &w.CreationMomentumHeight, &w.ConfirmationsToFinality)
+		&w.TokenAddress, NumericDest(&w.Amount), NumericDest(&w.Fee), &w.Signature, &w.CreationMomentumHeight, &w.ConfirmationsToFinality)
 	if err != nil {
 		return nil, err
 	}
@@ -75,7 +97,7 @@ func (r *BridgeRepository) GetUnwrapRequestByTxHash(ctx context.Context, txHash
 			registration_momentum_height, redeemed, revoked, redeemable_in
 		FROM unwrap_token_requests WHERE transaction_hash = $1 AND log_index = $2`, txHash, logIndex).Scan(
 		&u.TransactionHash, &u.LogIndex, &u.NetworkClass, &u.ChainID,
-		&u.ToAddress, &u.TokenStandard, &u.TokenAddress, &u.Amount, &u.Signature,
+		&u.ToAddress, &u.TokenStandard, &u.TokenAddress, NumericDest(&u.Amount), &u.Signature,
 		&u.RegistrationMomentumHeight, &u.Redeemed, &u.Revoked, &u.RedeemableIn)
 	if err != nil {
 		return nil, err
@@ -135,7 +157,7 @@ func (r *BridgeRepository) ListWraps(ctx context.Context, opts ListOpts) ([]*mod
 	for rows.Next() {
 		var w models.WrapTokenRequest
 		if err := rows.Scan(&w.ID, &w.NetworkClass, &w.ChainID, &w.ToAddress, &w.TokenStandard,
-			&w.TokenAddress, &w.Amount, &w.Fee, &w.Signature, &w.CreationMomentumHeight, &w.ConfirmationsToFinality, &total); err != nil {
+			&w.TokenAddress, NumericDest(&w.Amount), NumericDest(&w.Fee), &w.Signature, &w.CreationMomentumHeight, &w.ConfirmationsToFinality, &total); err != nil {
 			return nil, 0, err
 		}
 		out = append(out, &w)
@@ -177,7 +199,7 @@ func (r *BridgeRepository) ListWrapsByAddress(ctx context.Context, address strin
 	for rows.Next() {
 		var w models.WrapTokenRequest
 		if err := rows.Scan(&w.ID, &w.NetworkClass, &w.ChainID, &w.ToAddress, &w.TokenStandard,
-			&w.TokenAddress, &w.Amount, &w.Fee, &w.Signature, &w.CreationMomentumHeight, &w.ConfirmationsToFinality, &total); err != nil {
+			&w.TokenAddress, NumericDest(&w.Amount), NumericDest(&w.Fee), &w.Signature, &w.CreationMomentumHeight, &w.ConfirmationsToFinality, &total); err != nil {
 			return nil, 0, err
 		}
 		out = append(out, &w)
@@ -218,7 +240,7 @@ func (r *BridgeRepository) ListUnwraps(ctx context.Context, opts ListOpts) ([]*m
 	for rows.Next() {
 		var u models.UnwrapTokenRequest
 		if err := rows.Scan(&u.TransactionHash, &u.LogIndex, &u.NetworkClass, &u.ChainID,
-			&u.ToAddress, &u.TokenStandard, &u.TokenAddress, &u.Amount, &u.Signature,
+			&u.ToAddress, &u.TokenStandard, &u.TokenAddress, NumericDest(&u.Amount), &u.Signature,
 			&u.RegistrationMomentumHeight, &u.Redeemed, &u.Revoked, &u.RedeemableIn, &total); err != nil {
 			return nil, 0, err
 		}
@@ -262,7 +284,7 @@ func (r *BridgeRepository) ListUnwrapsByAddress(ctx context.Context, address str
 	for rows.Next() {
 		var u models.UnwrapTokenRequest
 		if err := rows.Scan(&u.TransactionHash, &u.LogIndex, &u.NetworkClass, &u.ChainID,
-			&u.ToAddress, &u.TokenStandard, &u.TokenAddress, &u.Amount, &u.Signature,
+			&u.ToAddress, &u.TokenStandard, &u.TokenAddress, NumericDest(&u.Amount), &u.Signature,
 			&u.RegistrationMomentumHeight, &u.Redeemed, &u.Revoked, &u.RedeemableIn, &total); err != nil {
 			return nil, 0, err
 		}
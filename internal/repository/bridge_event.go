@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+// BridgeEventRepository manages bridge_events, every call into the
+// Bridge contract decoded from its receive block.
+type BridgeEventRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewBridgeEventRepository constructs a BridgeEventRepository backed by
+// pool.
+func NewBridgeEventRepository(pool *pgxpool.Pool) *BridgeEventRepository {
+	return &BridgeEventRepository{pool: pool}
+}
+
+const bridgeEventColumns = `account_block_hash, send_block_hash, method, address, token_standard,
+	amount, inputs, momentum_height, momentum_timestamp`
+
+// BridgeEventFilter narrows List. Empty fields match everything.
+type BridgeEventFilter struct {
+	Method  string
+	Address string
+}
+
+// InsertBatch enqueues a BridgeEvent on the per-momentum batch.
+// Idempotent via ON CONFLICT (account_block_hash) DO NOTHING.
+func (r *BridgeEventRepository) InsertBatch(batch *pgx.Batch, e *models.BridgeEvent) {
+	inputs := "{}"
+	if len(e.Inputs) > 0 {
+		if b, err := json.Marshal(e.Inputs); err == nil {
+			inputs = sanitizeJSONForPostgres(string(b))
+		}
+	}
+	batch.Queue(`
+		INSERT INTO bridge_events (`+bridgeEventColumns+`)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+		ON CONFLICT (account_block_hash) DO NOTHING`,
+		e.AccountBlockHash, e.SendBlockHash, e.Method, e.Address, e.TokenStandard,
+		numeric(e.Amount), inputs, e.MomentumHeight, e.MomentumTimestamp)
+}
+
+// List returns bridge events newest first.
+func (r *BridgeEventRepository) List(ctx context.Context, f BridgeEventFilter, opts ListOpts) ([]*models.BridgeEvent, int64, error) {
+	const where = `WHERE ($1 = '' OR method = $1) AND ($2 = '' OR address = $2)`
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+bridgeEventColumns+`, COUNT(*) OVER () AS total
+		FROM bridge_events `+where+`
+		ORDER BY momentum_height DESC, account_block_hash
+		LIMIT $3 OFFSET $4`, f.Method, f.Address, opts.Limit, opts.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("BridgeEventRepository.List: %w", err)
+	}
+	defer rows.Close()
+	var (
+		out   []*models.BridgeEvent
+		total int64
+	)
+	for rows.Next() {
+		e := &models.BridgeEvent{}
+		if err := rows.Scan(append(bridgeEventDest(e), &total)...); err != nil {
+			return nil, 0, fmt.Errorf("BridgeEventRepository.List: %w", err)
+		}
+		out = append(out, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("BridgeEventRepository.List: %w", err)
+	}
+	if len(out) == 0 && opts.Offset > 0 {
+		if total, err = fallbackCount(ctx, r.pool, `SELECT COUNT(*) FROM bridge_events `+where, f.Method, f.Address); err != nil {
+			return nil, 0, fmt.Errorf("BridgeEventRepository.List: %w", err)
+		}
+	}
+	return out, total, nil
+}
+
+// bridgeEventDest returns Scan targets for bridgeEventColumns.
+func bridgeEventDest(e *models.BridgeEvent) []interface{} {
+	return []interface{}{
+		&e.AccountBlockHash, &e.SendBlockHash, &e.Method, &e.Address, &e.TokenStandard,
+		NumericDest(&e.Amount), &e.Inputs, &e.MomentumHeight, &e.MomentumTimestamp,
+	}
+}
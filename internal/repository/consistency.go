@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+// ConsistencyRepository backs the verifier: it reads the database side
+// of each node comparison and keeps consistency_issues, the differences
+// found.
+type ConsistencyRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewConsistencyRepository constructs a ConsistencyRepository backed by pool.
+func NewConsistencyRepository(pool *pgxpool.Pool) *ConsistencyRepository {
+	return &ConsistencyRepository{pool: pool}
+}
+
+// addressSources maps the address populations the verifier draws from
+// to the query listing them. Active stakes and fusions are the ones the
+// node still lists; negative_balances are the addresses holding a
+// balance below zero, which the node never reports.
+var addressSources = map[string]string{
+	"accounts":          `SELECT address FROM accounts`,
+	"stakes":            `SELECT DISTINCT address FROM stakes WHERE is_active`,
+	"fusions":           `SELECT DISTINCT address FROM fusions WHERE is_active`,
+	"negative_balances": `SELECT DISTINCT address FROM balances WHERE balance < 0`,
+}
+
+// StoredMomentums returns, for each of heights that is indexed, the
+// stored momentum hash and the number of account blocks stored for it.
+// Heights that are not indexed are absent from both maps.
+func (r *ConsistencyRepository) StoredMomentums(ctx context.Context, heights []uint64) (hashes map[uint64]string, blocks map[uint64]int, err error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT m.height, m.hash,
+			(SELECT COUNT(*) FROM account_blocks ab WHERE ab.momentum_height = m.height)
+		FROM momentums m
+		WHERE m.height = ANY($1::bigint[])`, heights)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ConsistencyRepository.StoredMomentums: %w", err)
+	}
+	defer rows.Close()
+	hashes, blocks = map[uint64]string{}, map[uint64]int{}
+	for rows.Next() {
+		var (
+			h    uint64
+			hash string
+			n    int
+		)
+		if err := rows.Scan(&h, &hash, &n); err != nil {
+			return nil, nil, fmt.Errorf("ConsistencyRepository.StoredMomentums: %w", err)
+		}
+		hashes[h], blocks[h] = hash, n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("ConsistencyRepository.StoredMomentums: %w", err)
+	}
+	return hashes, blocks, nil
+}
+
+// AccountHeights returns the indexed chain height (accounts.block_count)
+// of each of addresses that has an accounts row.
+func (r *ConsistencyRepository) AccountHeights(ctx context.Context, addresses []string) (map[string]uint64, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT address, block_count FROM accounts
+		WHERE address = ANY($1::text[])`, addresses)
+	if err != nil {
+		return nil, fmt.Errorf("ConsistencyRepository.AccountHeights: %w", err)
+	}
+	defer rows.Close()
+	out := make(map[string]uint64, len(addresses))
+	for rows.Next() {
+		var (
+			a string
+			h int64
+		)
+		if err := rows.Scan(&a, &h); err != nil {
+			return nil, fmt.Errorf("ConsistencyRepository.AccountHeights: %w", err)
+		}
+		out[a] = uint64(h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ConsistencyRepository.AccountHeights: %w", err)
+	}
+	return out, nil
+}
+
+// Addresses pages through source ("accounts", "stakes", "fusions" or
+// "negative_balances") in address order: up to limit addresses after the
+// given one.
+func (r *ConsistencyRepository) Addresses(ctx context.Context, source, after string, limit int) ([]string, error) {
+	query, ok := addressSources[source]
+	if !ok {
+		return nil, fmt.Errorf("ConsistencyRepository.Addresses: unknown source %q", source)
+	}
+	return r.addresses(ctx, "ConsistencyRepository.Addresses", `
+		SELECT address FROM (`+query+`) a
+		WHERE address > $1
+		ORDER BY address
+		LIMIT $2`, after, limit)
+}
+
+// SampleAddresses returns up to n addresses drawn at random from source.
+func (r *ConsistencyRepository) SampleAddresses(ctx context.Context, source string, n int) ([]string, error) {
+	query, ok := addressSources[source]
+	if !ok {
+		return nil, fmt.Errorf("ConsistencyRepository.SampleAddresses: unknown source %q", source)
+	}
+	return r.addresses(ctx, "ConsistencyRepository.SampleAddresses", `
+		SELECT address FROM (`+query+`) a
+		ORDER BY random()
+		LIMIT $1`, n)
+}
+
+func (r *ConsistencyRepository) addresses(ctx context.Context, op, query string, args ...interface{}) ([]string, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var a string
+		if err := rows.Scan(&a); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		out = append(out, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return out, nil
+}
+
+// ContractCallHeights returns, ascending, the heights of the momentums
+// holding contract's receives of the send hashed id and of every call
+// naming id as its `id` input: the blocks that open a stake or fusion
+// entry and the ones that cancel it.
+func (r *ConsistencyRepository) ContractCallHeights(ctx context.Context, contract, id string) ([]uint64, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT DISTINCT r.momentum_height
+		FROM account_blocks r
+		JOIN account_blocks s ON s.hash = r.paired_account_block
+		WHERE r.address = $1 AND (s.hash = $2 OR s.input->>'id' = $2)
+		ORDER BY 1`, contract, id)
+	if err != nil {
+		return nil, fmt.Errorf("ConsistencyRepository.ContractCallHeights: %w", err)
+	}
+	defer rows.Close()
+	var out []uint64
+	for rows.Next() {
+		var h uint64
+		if err := rows.Scan(&h); err != nil {
+			return nil, fmt.Errorf("ConsistencyRepository.ContractCallHeights: %w", err)
+		}
+		out = append(out, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ConsistencyRepository.ContractCallHeights: %w", err)
+	}
+	return out, nil
+}
+
+// Report records one comparison of check, in one transaction: found
+// opens an issue per subject, or bumps the one already open, and the
+// open issues of the clean subjects are resolved at now.
+func (r *ConsistencyRepository) Report(ctx context.Context, check string, found []*models.ConsistencyIssue, clean []string, now int64) error {
+	batch := &pgx.Batch{}
+	for _, f := range found {
+		batch.Queue(`
+			INSERT INTO consistency_issues (check_name, subject, kind, db_value, node_value,
+				first_seen_at, last_seen_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $6)
+			ON CONFLICT (check_name, subject) WHERE resolved_at IS NULL DO UPDATE SET
+				kind         = EXCLUDED.kind,
+				db_value     = EXCLUDED.db_value,
+				node_value   = EXCLUDED.node_value,
+				occurrences  = consistency_issues.occurrences + 1,
+				last_seen_at = EXCLUDED.last_seen_at`,
+			check, f.Subject, f.Kind, f.DBValue, f.NodeValue, now)
+	}
+	if len(clean) > 0 {
+		batch.Queue(`
+			UPDATE consistency_issues SET resolved_at = $3
+			WHERE check_name = $1 AND subject = ANY($2) AND resolved_at IS NULL`,
+			check, clean, now)
+	}
+	if batch.Len() == 0 {
+		return nil
+	}
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		return tx.SendBatch(ctx, batch).Close()
+	})
+	if err != nil {
+		return fmt.Errorf("ConsistencyRepository.Report: %w", err)
+	}
+	return nil
+}
+
+// MarkRepairQueued stamps the open issue of check and subject with the
+// time its repair was queued.
+func (r *ConsistencyRepository) MarkRepairQueued(ctx context.Context, check, subject string, now int64) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE consistency_issues SET repair_queued_at = $3
+		WHERE check_name = $1 AND subject = $2 AND resolved_at IS NULL`,
+		check, subject, now)
+	if err != nil {
+		return fmt.Errorf("ConsistencyRepository.MarkRepairQueued: %w", err)
+	}
+	return nil
+}
+
+// OpenCounts returns the number of open issues per check. Checks with
+// none are absent.
+func (r *ConsistencyRepository) OpenCounts(ctx context.Context) (map[string]int64, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT check_name, COUNT(*) FROM consistency_issues
+		WHERE resolved_at IS NULL
+		GROUP BY check_name`)
+	if err != nil {
+		return nil, fmt.Errorf("ConsistencyRepository.OpenCounts: %w", err)
+	}
+	defer rows.Close()
+	out := map[string]int64{}
+	for rows.Next() {
+		var (
+			check string
+			n     int64
+		)
+		if err := rows.Scan(&check, &n); err != nil {
+			return nil, fmt.Errorf("ConsistencyRepository.OpenCounts: %w", err)
+		}
+		out[check] = n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ConsistencyRepository.OpenCounts: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,122 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+func TestIntegration_Consistency_Reads(t *testing.T) {
+	pool := newTestDB(t)
+	ctx := context.Background()
+	repos := NewRepositories(pool)
+	repo := repos.Consistency
+
+	batch := &pgx.Batch{}
+	for _, h := range []uint64{1, 2} {
+		repos.Momentum.InsertBatch(ctx, batch, &models.Momentum{
+			Height: h, Hash: "m" + string(rune('0'+h)), Timestamp: int64(h) * 100, Producer: "z1qprod",
+		})
+	}
+	block := func(hash string, height int64, blockType int16, addr, paired string, tx *models.TxData) {
+		repos.AccountBlock.InsertBatch(batch, &models.AccountBlock{
+			Hash: hash, MomentumHash: "m", MomentumTimestamp: height * 100, MomentumHeight: height,
+			BlockType: blockType, Height: height, Address: addr, ToAddress: models.StakeAddress,
+			Amount: big.NewInt(0), TokenStandard: models.ZnnTokenStandard, Data: "00", PairedAccountBlock: paired,
+		}, tx)
+	}
+	// Height 1 opens stake s1; height 2 receives it and its cancel.
+	block("s1", 1, models.BlockTypeUserSend, "z1qa", "", &models.TxData{Method: "Stake"})
+	block("c1", 1, models.BlockTypeUserSend, "z1qa", "", &models.TxData{Method: "Cancel", Inputs: map[string]string{"id": "s1"}})
+	block("r1", 2, models.BlockTypeContractReceive, models.StakeAddress, "s1", nil)
+	block("r2", 2, models.BlockTypeContractReceive, models.StakeAddress, "c1", nil)
+	repos.Stake.InsertBatch(batch, &models.Stake{ID: "s1", Address: "z1qa", IsActive: true})
+	repos.Stake.InsertBatch(batch, &models.Stake{ID: "s2", Address: "z1qb", IsActive: false})
+	sendBatch(t, ctx, pool, batch)
+
+	hashes, blocks, err := repo.StoredMomentums(ctx, []uint64{1, 2, 3})
+	if err != nil {
+		t.Fatalf("stored momentums: %v", err)
+	}
+	if len(hashes) != 2 || hashes[1] != "m1" || blocks[1] != 2 || blocks[2] != 2 {
+		t.Errorf("stored momentums = %v %v", hashes, blocks)
+	}
+
+	if heights, err := repo.ContractCallHeights(ctx, models.StakeAddress, "s1"); err != nil || !slices.Equal(heights, []uint64{2}) {
+		t.Errorf("contract call heights = %v (%v), want [2]", heights, err)
+	}
+
+	// Only active stakes count; paging resumes after the last address.
+	if addrs, _ := repo.Addresses(ctx, "stakes", "", 10); !slices.Equal(addrs, []string{"z1qa"}) {
+		t.Errorf("stake addresses = %v", addrs)
+	}
+	if addrs, _ := repo.Addresses(ctx, "stakes", "z1qa", 10); len(addrs) != 0 {
+		t.Errorf("stake addresses after z1qa = %v", addrs)
+	}
+	if addrs, _ := repo.SampleAddresses(ctx, "stakes", 5); !slices.Equal(addrs, []string{"z1qa"}) {
+		t.Errorf("sampled stake addresses = %v", addrs)
+	}
+	if _, err := repo.Addresses(ctx, "momentums", "", 1); err == nil {
+		t.Error("Addresses accepted an unknown source")
+	}
+}
+
+func TestIntegration_Consistency_Issues(t *testing.T) {
+	pool := newTestDB(t)
+	ctx := context.Background()
+	repo := NewConsistencyRepository(pool)
+
+	issue := func(subject, node string) *models.ConsistencyIssue {
+		return &models.ConsistencyIssue{Subject: subject, Kind: models.IssueDiffers, DBValue: "1", NodeValue: node}
+	}
+	if err := repo.Report(ctx, "balances", []*models.ConsistencyIssue{issue("z1qa", "2"), issue("z1qb", "2")}, nil, 100); err != nil {
+		t.Fatalf("report: %v", err)
+	}
+	// Seen again: one open row, bumped. z1qb matches again and resolves.
+	if err := repo.Report(ctx, "balances", []*models.ConsistencyIssue{issue("z1qa", "3")}, []string{"z1qb"}, 200); err != nil {
+		t.Fatalf("re-report: %v", err)
+	}
+	if err := repo.MarkRepairQueued(ctx, "balances", "z1qa", 250); err != nil {
+		t.Fatalf("mark: %v", err)
+	}
+
+	var (
+		occurrences    int
+		node           string
+		first, last    int64
+		repairQueuedAt *int64
+		resolvedAt     *int64
+	)
+	err := pool.QueryRow(ctx, `
+		SELECT occurrences, node_value, first_seen_at, last_seen_at, repair_queued_at, resolved_at
+		FROM consistency_issues WHERE check_name = 'balances' AND subject = 'z1qa'`).
+		Scan(&occurrences, &node, &first, &last, &repairQueuedAt, &resolvedAt)
+	if err != nil {
+		t.Fatalf("read z1qa: %v", err)
+	}
+	if occurrences != 2 || node != "3" || first != 100 || last != 200 ||
+		repairQueuedAt == nil || *repairQueuedAt != 250 || resolvedAt != nil {
+		t.Errorf("z1qa = %d %q %d-%d repair=%v resolved=%v", occurrences, node, first, last, repairQueuedAt, resolvedAt)
+	}
+
+	counts, err := repo.OpenCounts(ctx)
+	if err != nil || len(counts) != 1 || counts["balances"] != 1 {
+		t.Errorf("open counts = %v (%v), want balances=1", counts, err)
+	}
+
+	// A resolved subject that differs again opens a new issue.
+	if err := repo.Report(ctx, "balances", []*models.ConsistencyIssue{issue("z1qb", "4")}, nil, 300); err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	var rows int
+	if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM consistency_issues WHERE subject = 'z1qb'`).Scan(&rows); err != nil || rows != 2 {
+		t.Errorf("z1qb rows = %d (%v), want resolved + reopened", rows, err)
+	}
+}
@@ -47,13 +47,79 @@ func (r *DelegationRepository) CloseActiveBatch(batch *pgx.Batch, delegator stri
 		delegator, endedAt)
 }
 
-// OpenBatch starts a new active delegation interval. Should be invoked after
+// OpenBatch starts a new active delegation interval opened by the pillar
+// contract receive block accountBlockHash. Should be invoked after
 // CloseActiveBatch in the same batch when a delegator switches pillars.
-func (r *DelegationRepository) OpenBatch(batch *pgx.Batch, delegator, pillarOwner string, startedAt int64) {
+// An interval already opened by the same block is left alone.
+func (r *DelegationRepository) OpenBatch(batch *pgx.Batch, accountBlockHash, delegator, pillarOwner string, startedAt int64) {
 	batch.Queue(`
-		INSERT INTO delegations (delegator_address, pillar_owner_address, started_at)
-		VALUES ($1, $2, $3)`,
-		delegator, pillarOwner, startedAt)
+		INSERT INTO delegations (delegator_address, pillar_owner_address, started_at, account_block_hash)
+		VALUES ($1, $2, $3, NULLIF($4, ''))
+		ON CONFLICT (account_block_hash) DO NOTHING`,
+		delegator, pillarOwner, startedAt, accountBlockHash)
+}
+
+// delegationSupersededSQL holds when delegator $1 already has delegation
+// history from a pillar contract block after the one being applied ($3
+// hash, $4 contract chain height, $2 momentum timestamp), or from that
+// block itself. Rows from before migration 035 have no hash and are
+// compared by timestamp.
+const delegationSupersededSQL = `
+	EXISTS (
+		SELECT 1 FROM delegations d
+		LEFT JOIN account_blocks ab ON ab.hash = d.account_block_hash
+		WHERE d.delegator_address = $1
+		  AND (d.account_block_hash = $3
+		    OR ab.height > $4
+		    OR (d.account_block_hash IS NULL AND (d.started_at > $2 OR d.ended_at > $2))))`
+
+// delegationOpenSQL holds when delegator $1's open interval is already
+// the one a Delegate to $5 at $2 would open: a replay of the block that
+// opened a pre-035 row.
+const delegationOpenSQL = `
+	EXISTS (
+		SELECT 1 FROM delegations d
+		WHERE d.delegator_address = $1 AND d.ended_at IS NULL
+		  AND d.started_at = $2 AND d.pillar_owner_address = $5)`
+
+// DelegateBatch applies a Delegate handled by the pillar contract receive
+// block accountBlockHash at contract chain height blockHeight: it points
+// accounts.delegate at pillarOwner, closes the delegator's open interval
+// and opens a new one. Replaying the block, or an older one, changes
+// nothing: each statement is skipped once later history exists.
+func (r *DelegationRepository) DelegateBatch(batch *pgx.Batch, accountBlockHash string, blockHeight uint64, delegator, pillarOwner string, ts int64) {
+	args := []any{delegator, ts, accountBlockHash, int64(blockHeight), pillarOwner}
+	batch.Queue(`
+		UPDATE accounts SET delegate = $5, delegation_start_timestamp = $2
+		WHERE address = $1
+		  AND NOT `+delegationSupersededSQL+`
+		  AND NOT `+delegationOpenSQL, args...)
+	batch.Queue(`
+		UPDATE delegations SET ended_at = $2
+		WHERE delegator_address = $1 AND ended_at IS NULL
+		  AND NOT `+delegationSupersededSQL+`
+		  AND NOT `+delegationOpenSQL, args...)
+	batch.Queue(`
+		INSERT INTO delegations (delegator_address, pillar_owner_address, started_at, account_block_hash)
+		SELECT $1, $5, $2, $3
+		WHERE NOT `+delegationSupersededSQL+`
+		  AND NOT `+delegationOpenSQL+`
+		ON CONFLICT (account_block_hash) DO NOTHING`, args...)
+}
+
+// UndelegateBatch applies an Undelegate handled by the pillar contract
+// receive block accountBlockHash at contract chain height blockHeight:
+// it clears accounts.delegate and closes the open interval, unless the
+// delegator has delegated again since.
+func (r *DelegationRepository) UndelegateBatch(batch *pgx.Batch, accountBlockHash string, blockHeight uint64, delegator string, ts int64) {
+	args := []any{delegator, ts, accountBlockHash, int64(blockHeight)}
+	batch.Queue(`
+		UPDATE accounts SET delegate = '', delegation_start_timestamp = 0
+		WHERE address = $1 AND NOT `+delegationSupersededSQL, args...)
+	batch.Queue(`
+		UPDATE delegations SET ended_at = $2
+		WHERE delegator_address = $1 AND ended_at IS NULL
+		  AND NOT `+delegationSupersededSQL, args...)
 }
 
 // CountActiveByPillar returns the number of currently-active delegations to a
@@ -0,0 +1,36 @@
+package repository
+
+import "github.com/jackc/pgx/v5"
+
+// Additive counters — pillar produced-momentum counts, account flow
+// totals and tx_count, token transaction counts and burn totals,
+// cumulative rewards, balances — are applied once per momentum or
+// account block.
+// Each increment is gated on the effects_applied flag of the row it
+// comes from, and the momentum's transaction sets the flags last
+// (MarkEffectsAppliedBatch), so reprocessing a height leaves the
+// counters as they were.
+//
+// The gate must be queued after the row's own insert in the same
+// batch: a concurrent transaction writing the same row then waits on
+// the insert and, once it proceeds, sees the flag the first one set.
+
+// blockEffectsPending is the SQL predicate that holds until the
+// effects of the account block hashed by param have been applied.
+func blockEffectsPending(param string) string {
+	return `NOT EXISTS (SELECT 1 FROM account_blocks WHERE hash = ` + param + ` AND effects_applied)`
+}
+
+// momentumEffectsPending is the SQL predicate that holds until the
+// effects of the momentum at height param have been applied.
+func momentumEffectsPending(param string) string {
+	return `NOT EXISTS (SELECT 1 FROM momentums WHERE height = ` + param + ` AND effects_applied)`
+}
+
+// MarkEffectsAppliedBatch flags the momentum at height and its account
+// blocks as counted. Queue it after every gated counter of the
+// momentum.
+func (r *MomentumRepository) MarkEffectsAppliedBatch(batch *pgx.Batch, height uint64) {
+	batch.Queue(`UPDATE momentums SET effects_applied = true WHERE height = $1 AND NOT effects_applied`, height)
+	batch.Queue(`UPDATE account_blocks SET effects_applied = true WHERE momentum_height = $1 AND NOT effects_applied`, height)
+}
@@ -0,0 +1,106 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+// TestIntegration_Effects_ReprocessIsIdempotent commits the same momentum
+// twice, the way the indexer queues it, and checks that every additive
+// counter holds what one pass produced.
+func TestIntegration_Effects_ReprocessIsIdempotent(t *testing.T) {
+	pool := newTestDB(t)
+	ctx := context.Background()
+	repos := NewRepositories(pool)
+
+	const (
+		a, b   = "z1qA", "z1qB"
+		pillar = "z1qpillar"
+		zts    = "zts1custom"
+	)
+	if err := repos.Pillar.Upsert(ctx, &models.Pillar{
+		OwnerAddress: pillar, ProducerAddress: pillar, WithdrawAddress: pillar, Name: "P",
+	}); err != nil {
+		t.Fatalf("seed pillar: %v", err)
+	}
+
+	commit := func() {
+		t.Helper()
+		batch := &pgx.Batch{}
+		repos.Momentum.InsertBatch(ctx, batch, &models.Momentum{
+			Height: 1, Hash: "m1", Timestamp: 100, Producer: pillar, ProducerOwner: pillar,
+		})
+		repos.Pillar.IncrementMomentumCountBatch(batch, 1, pillar)
+
+		repos.AccountBlock.InsertBatch(batch, &models.AccountBlock{
+			Hash: "s1", MomentumHash: "m1", MomentumTimestamp: 100, MomentumHeight: 1,
+			BlockType: models.BlockTypeUserSend, Height: 1, Address: a, ToAddress: b,
+			Amount: big.NewInt(100), TokenStandard: models.ZnnTokenStandard,
+		}, nil)
+		repos.Account.UpsertBatch(batch, &models.Account{Address: a, BlockCount: 1})
+		repos.Account.AddSendBatch(batch, "s1", a, models.ZnnTokenStandard, 100, 100)
+		repos.Account.BumpTxCountBatch(batch, "s1", a, 100)
+		repos.Account.BumpTxCountBatch(batch, "s1", b, 100)
+		repos.Token.UpsertBatch(batch, &models.Token{TokenStandard: zts, Name: "C", Symbol: "C",
+			TotalSupply: big.NewInt(1000), MaxSupply: big.NewInt(1000)})
+		repos.Token.IncrementTransactionCountBatch(batch, "s1", zts)
+		repos.Token.AddBlockBurnBatch(batch, "s1", zts, big.NewInt(7))
+		repos.Reward.AddRewardBatch(batch, &models.RewardTransaction{
+			Hash: "s1", Address: a, RewardType: models.RewardTypeStake,
+			Amount: big.NewInt(5), TokenStandard: models.QsrTokenStandard,
+		})
+
+		repos.Momentum.MarkEffectsAppliedBatch(batch, 1)
+		sendBatch(t, ctx, pool, batch)
+	}
+
+	type counters struct {
+		produced, txA, txB, znnSent, tokenTx int64
+		burned, rewards                      string
+	}
+	read := func() counters {
+		t.Helper()
+		var c counters
+		err := pool.QueryRow(ctx, `
+			SELECT
+				(SELECT produced_momentum_count FROM pillars WHERE owner_address = $1),
+				(SELECT tx_count FROM accounts WHERE address = $2),
+				(SELECT tx_count FROM accounts WHERE address = $3),
+				(SELECT znn_sent FROM accounts WHERE address = $2),
+				(SELECT transaction_count FROM tokens WHERE token_standard = $4),
+				(SELECT total_burned::text FROM tokens WHERE token_standard = $4),
+				(SELECT amount::text FROM cumulative_rewards WHERE address = $2)`,
+			pillar, a, b, zts).Scan(&c.produced, &c.txA, &c.txB, &c.znnSent, &c.tokenTx, &c.burned, &c.rewards)
+		if err != nil {
+			t.Fatalf("read counters: %v", err)
+		}
+		return c
+	}
+
+	commit()
+	first := read()
+	want := counters{produced: 1, txA: 1, txB: 1, znnSent: 100, tokenTx: 1, burned: "7", rewards: "5"}
+	if first != want {
+		t.Fatalf("after one pass: %+v, want %+v", first, want)
+	}
+
+	commit()
+	if second := read(); second != first {
+		t.Errorf("after reprocessing: %+v, want %+v", second, first)
+	}
+
+	var pending int
+	_ = pool.QueryRow(ctx, `
+		SELECT (SELECT COUNT(*) FROM momentums WHERE NOT effects_applied)
+			+ (SELECT COUNT(*) FROM account_blocks WHERE NOT effects_applied)`).Scan(&pending)
+	if pending != 0 {
+		t.Errorf("%d rows left unflagged", pending)
+	}
+}
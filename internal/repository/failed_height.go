@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+// FailedHeightRepository manages indexer_failed_heights, the queue of
+// momentum heights waiting to be re-processed.
+type FailedHeightRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewFailedHeightRepository constructs a FailedHeightRepository backed by pool.
+func NewFailedHeightRepository(pool *pgxpool.Pool) *FailedHeightRepository {
+	return &FailedHeightRepository{pool: pool}
+}
+
+const failedHeightColumns = `height, last_error, attempts, first_failed_at, last_failed_at, next_retry_at`
+
+// scanFailedHeight reads one indexer_failed_heights row. The SELECT
+// lists failedHeightColumns followed by one column per extra
+// destination.
+func scanFailedHeight(rows pgx.Row, f *models.FailedHeight, extra ...interface{}) error {
+	dst := []interface{}{
+		&f.Height, &f.LastError, &f.Attempts, &f.FirstFailedAt, &f.LastFailedAt, &f.NextRetryAt,
+	}
+	return rows.Scan(append(dst, extra...)...)
+}
+
+// Record notes a failed attempt at height. The first failure schedules a
+// retry backoff seconds after now; each further one doubles the delay, up
+// to maxBackoff seconds.
+func (r *FailedHeightRepository) Record(ctx context.Context, height uint64, errMsg string, now, backoff, maxBackoff int64) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO indexer_failed_heights (`+failedHeightColumns+`)
+		VALUES ($1, $2, 1, $3, $3, $3 + LEAST($4::bigint, $5::bigint))
+		ON CONFLICT (height) DO UPDATE SET
+			last_error = EXCLUDED.last_error,
+			attempts = indexer_failed_heights.attempts + 1,
+			last_failed_at = EXCLUDED.last_failed_at,
+			next_retry_at = EXCLUDED.last_failed_at + LEAST($5::bigint,
+				$4::bigint * (2::bigint ^ LEAST(indexer_failed_heights.attempts, 30))::bigint)`,
+		height, errMsg, now, backoff, maxBackoff)
+	if err != nil {
+		return fmt.Errorf("FailedHeightRepository.Record: %w", err)
+	}
+	return nil
+}
+
+// Requeue queues height to be reprocessed at the retrier's next pass
+// even though it is complete, with reason as its error. A height already
+// queued keeps its error and attempts, and becomes due now.
+func (r *FailedHeightRepository) Requeue(ctx context.Context, height uint64, reason string, now int64) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO indexer_failed_heights (`+failedHeightColumns+`, reprocess)
+		VALUES ($1, $2, 0, $3, $3, $3, true)
+		ON CONFLICT (height) DO UPDATE SET
+			reprocess = true,
+			next_retry_at = LEAST(indexer_failed_heights.next_retry_at, EXCLUDED.next_retry_at)`,
+		height, reason, now)
+	if err != nil {
+		return fmt.Errorf("FailedHeightRepository.Requeue: %w", err)
+	}
+	return nil
+}
+
+// Due returns up to limit heights whose retry is due at now, lowest
+// height first so a run of failures is re-processed in chain order.
+func (r *FailedHeightRepository) Due(ctx context.Context, now int64, limit int) ([]*models.FailedHeight, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+failedHeightColumns+`, reprocess
+		FROM indexer_failed_heights
+		WHERE next_retry_at <= $1
+		ORDER BY height
+		LIMIT $2`, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("FailedHeightRepository.Due: %w", err)
+	}
+	defer rows.Close()
+	var out []*models.FailedHeight
+	for rows.Next() {
+		var f models.FailedHeight
+		if err := scanFailedHeight(rows, &f, &f.Reprocess); err != nil {
+			return nil, fmt.Errorf("FailedHeightRepository.Due: %w", err)
+		}
+		out = append(out, &f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("FailedHeightRepository.Due: %w", err)
+	}
+	return out, nil
+}
+
+// Resolve removes height from the queue.
+func (r *FailedHeightRepository) Resolve(ctx context.Context, height uint64) error {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM indexer_failed_heights WHERE height = $1`, height); err != nil {
+		return fmt.Errorf("FailedHeightRepository.Resolve: %w", err)
+	}
+	return nil
+}
+
+// Count returns the number of outstanding failed heights.
+func (r *FailedHeightRepository) Count(ctx context.Context) (int64, error) {
+	var n int64
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM indexer_failed_heights`).Scan(&n); err != nil {
+		return 0, fmt.Errorf("FailedHeightRepository.Count: %w", err)
+	}
+	return n, nil
+}
+
+// List returns outstanding failed heights ordered by height (sort =
+// "desc" for newest first, default ascending), with the total count.
+func (r *FailedHeightRepository) List(ctx context.Context, opts ListOpts) ([]*models.FailedHeight, int64, error) {
+	order := "ASC"
+	if opts.Sort == "desc" {
+		order = "DESC"
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+failedHeightColumns+`, COUNT(*) OVER () AS total
+		FROM indexer_failed_heights
+		ORDER BY height `+order+`
+		LIMIT $1 OFFSET $2`, opts.Limit, opts.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("FailedHeightRepository.List: %w", err)
+	}
+	defer rows.Close()
+	var (
+		out   []*models.FailedHeight
+		total int64
+	)
+	for rows.Next() {
+		var f models.FailedHeight
+		if err := scanFailedHeight(rows, &f, &total); err != nil {
+			return nil, 0, fmt.Errorf("FailedHeightRepository.List: %w", err)
+		}
+		out = append(out, &f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("FailedHeightRepository.List: %w", err)
+	}
+	if len(out) == 0 && opts.Offset > 0 {
+		total, err = fallbackCount(ctx, r.pool, `SELECT COUNT(*) FROM indexer_failed_heights`)
+		if err != nil {
+			return nil, 0, fmt.Errorf("FailedHeightRepository.List: %w", err)
+		}
+	}
+	return out, total, nil
+}
@@ -0,0 +1,82 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+)
+
+func TestIntegration_FailedHeight_Lifecycle(t *testing.T) {
+	pool := newTestDB(t)
+	ctx := context.Background()
+	repo := NewFailedHeightRepository(pool)
+
+	for _, h := range []uint64{30, 10} {
+		if err := repo.Record(ctx, h, "boom", 1000, 60, 300); err != nil {
+			t.Fatalf("record %d: %v", h, err)
+		}
+	}
+	// Repeated failures double the delay up to the cap.
+	for n, want := range []int64{1120, 1240, 1300} {
+		if err := repo.Record(ctx, 30, "again", 1000, 60, 300); err != nil {
+			t.Fatalf("re-record: %v", err)
+		}
+		rows, _, err := repo.List(ctx, ListOpts{Limit: 10, Sort: "desc"})
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if f := rows[0]; f.Height != 30 || f.Attempts != n+2 || f.NextRetryAt != want ||
+			f.FirstFailedAt != 1000 || f.LastError != "again" {
+			t.Errorf("after %d re-failures: %+v, want next_retry_at %d", n+1, f, want)
+		}
+	}
+
+	due, err := repo.Due(ctx, 1060, 10)
+	if err != nil {
+		t.Fatalf("due: %v", err)
+	}
+	if len(due) != 1 || due[0].Height != 10 || due[0].Attempts != 1 {
+		t.Fatalf("due at 1060 = %+v, want only height 10", due)
+	}
+	if due, _ = repo.Due(ctx, 5000, 10); len(due) != 2 || due[0].Height != 10 {
+		t.Errorf("due at 5000 = %+v, want 10 then 30", due)
+	}
+
+	if err := repo.Resolve(ctx, 10); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	n, err := repo.Count(ctx)
+	if err != nil || n != 1 {
+		t.Errorf("count = %d, %v; want 1", n, err)
+	}
+	rows, total, err := repo.List(ctx, ListOpts{Limit: 10, Offset: 5})
+	if err != nil || len(rows) != 0 || total != 1 {
+		t.Errorf("past-the-end page: %d rows, total %d, err %v", len(rows), total, err)
+	}
+}
+
+func TestIntegration_FailedHeight_Requeue(t *testing.T) {
+	pool := newTestDB(t)
+	ctx := context.Background()
+	repo := NewFailedHeightRepository(pool)
+
+	// A failed height keeps its attempts but is pulled forward and
+	// flagged for reprocessing; a fresh one is due at once.
+	if err := repo.Record(ctx, 7, "boom", 1000, 60, 300); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	for _, h := range []uint64{7, 9} {
+		if err := repo.Requeue(ctx, h, "verify", 1010); err != nil {
+			t.Fatalf("requeue %d: %v", h, err)
+		}
+	}
+	due, err := repo.Due(ctx, 1010, 10)
+	if err != nil {
+		t.Fatalf("due: %v", err)
+	}
+	if len(due) != 2 || due[0].Height != 7 || due[0].Attempts != 1 || !due[0].Reprocess ||
+		due[1].Height != 9 || due[1].Attempts != 0 || !due[1].Reprocess || due[1].LastError != "verify" {
+		t.Errorf("due after requeue = %+v, %+v", due[0], due[1])
+	}
+}
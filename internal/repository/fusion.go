@@ -26,7 +26,7 @@ func (r *FusionRepository) Insert(ctx context.Context, f *models.Fusion) error {
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		ON CONFLICT (id) DO NOTHING`,
 		f.ID, f.Address, f.Beneficiary, f.MomentumHash, f.MomentumTimestamp,
-		f.MomentumHeight, f.QsrAmount, f.ExpirationHeight, f.IsActive, f.CancelID)
+		f.MomentumHeight, numeric(f.QsrAmount), f.ExpirationHeight, f.IsActive, f.CancelID)
 	return err
 }
 
@@ -38,7 +38,7 @@ func (r *FusionRepository) InsertBatch(batch *pgx.Batch, f *models.Fusion) {
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		ON CONFLICT (id) DO NOTHING`,
 		f.ID, f.Address, f.Beneficiary, f.MomentumHash, f.MomentumTimestamp,
-		f.MomentumHeight, f.QsrAmount, f.ExpirationHeight, f.IsActive, f.CancelID)
+		f.MomentumHeight, numeric(f.QsrAmount), f.ExpirationHeight, f.IsActive, f.CancelID)
 }
 
 // SetInactive marks a fusion as inactive by cancel ID and address
@@ -84,7 +84,7 @@ func (r *FusionRepository) List(ctx context.Context, activeOnly bool, opts ListO
 	for rows.Next() {
 		var f models.Fusion
 		if err := rows.Scan(&f.ID, &f.Address, &f.Beneficiary, &f.MomentumHash, &f.MomentumTimestamp,
-			&f.MomentumHeight, &f.QsrAmount, &f.ExpirationHeight, &f.IsActive, &f.CancelID, &total); err != nil {
+			&f.MomentumHeight, NumericDest(&f.QsrAmount), &f.ExpirationHeight, &f.IsActive, &f.CancelID, &total); err != nil {
 			return nil, 0, err
 		}
 		out = append(out, &f)
@@ -131,7 +131,7 @@ func (r *FusionRepository) ListByAddress(ctx context.Context, address string, ac
 	for rows.Next() {
 		var f models.Fusion
 		if err := rows.Scan(&f.ID, &f.Address, &f.Beneficiary, &f.MomentumHash, &f.MomentumTimestamp,
-			&f.MomentumHeight, &f.QsrAmount, &f.ExpirationHeight, &f.IsActive, &f.CancelID, &total); err != nil {
+			&f.MomentumHeight, NumericDest(&f.QsrAmount), &f.ExpirationHeight, &f.IsActive, &f.CancelID, &total); err != nil {
 			return nil, 0, err
 		}
 		out = append(out, &f)
@@ -157,7 +157,7 @@ func (r *FusionRepository) GetByID(ctx context.Context, id string) (*models.Fusi
 			momentum_height, qsr_amount, expiration_height, is_active, cancel_id
 		FROM fusions WHERE id = $1`, id).Scan(
 		&f.ID, &f.Address, &f.Beneficiary, &f.MomentumHash, &f.MomentumTimestamp,
-		&f.MomentumHeight, &f.QsrAmount, &f.ExpirationHeight, &f.IsActive, &f.CancelID)
+		&f.MomentumHeight, NumericDest(&f.QsrAmount), &f.ExpirationHeight, &f.IsActive, &f.CancelID)
 	if err != nil {
 		return nil, err
 	}
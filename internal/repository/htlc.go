@@ -28,7 +28,7 @@ func (r *HtlcRepository) Insert(ctx context.Context, h *models.Htlc) error {
 		INSERT INTO htlcs (`+htlcCols+`)
 		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15)
 		ON CONFLICT (id) DO NOTHING`,
-		h.ID, h.TimeLockedAddress, h.HashLockedAddress, h.TokenStandard, h.Amount,
+		h.ID, h.TimeLockedAddress, h.HashLockedAddress, h.TokenStandard, numeric(h.Amount),
 		h.ExpirationTimestamp, h.HashType, h.KeyMaxSize, h.HashLock, h.Status, h.Preimage,
 		h.CreationMomentumHeight, h.CreationMomentumTimestamp,
 		h.SettleMomentumHeight, h.SettleMomentumTimestamp)
@@ -41,7 +41,7 @@ func (r *HtlcRepository) InsertBatch(batch *pgx.Batch, h *models.Htlc) {
 		INSERT INTO htlcs (`+htlcCols+`)
 		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15)
 		ON CONFLICT (id) DO NOTHING`,
-		h.ID, h.TimeLockedAddress, h.HashLockedAddress, h.TokenStandard, h.Amount,
+		h.ID, h.TimeLockedAddress, h.HashLockedAddress, h.TokenStandard, numeric(h.Amount),
 		h.ExpirationTimestamp, h.HashType, h.KeyMaxSize, h.HashLock, h.Status, h.Preimage,
 		h.CreationMomentumHeight, h.CreationMomentumTimestamp,
 		h.SettleMomentumHeight, h.SettleMomentumTimestamp)
@@ -72,7 +72,7 @@ func (r *HtlcRepository) SettleBatch(batch *pgx.Batch, id string, status int16,
 func (r *HtlcRepository) GetByID(ctx context.Context, id string) (*models.Htlc, error) {
 	var h models.Htlc
 	err := r.pool.QueryRow(ctx, `SELECT `+htlcCols+` FROM htlcs WHERE id = $1`, id).Scan(
-		&h.ID, &h.TimeLockedAddress, &h.HashLockedAddress, &h.TokenStandard, &h.Amount,
+		&h.ID, &h.TimeLockedAddress, &h.HashLockedAddress, &h.TokenStandard, NumericDest(&h.Amount),
 		&h.ExpirationTimestamp, &h.HashType, &h.KeyMaxSize, &h.HashLock, &h.Status, &h.Preimage,
 		&h.CreationMomentumHeight, &h.CreationMomentumTimestamp,
 		&h.SettleMomentumHeight, &h.SettleMomentumTimestamp)
@@ -98,7 +98,7 @@ func (r *HtlcRepository) List(ctx context.Context, opts ListOpts) ([]*models.Htl
 	)
 	for rows.Next() {
 		var h models.Htlc
-		if err = rows.Scan(&h.ID, &h.TimeLockedAddress, &h.HashLockedAddress, &h.TokenStandard, &h.Amount,
+		if err = rows.Scan(&h.ID, &h.TimeLockedAddress, &h.HashLockedAddress, &h.TokenStandard, NumericDest(&h.Amount),
 			&h.ExpirationTimestamp, &h.HashType, &h.KeyMaxSize, &h.HashLock, &h.Status, &h.Preimage,
 			&h.CreationMomentumHeight, &h.CreationMomentumTimestamp,
 			&h.SettleMomentumHeight, &h.SettleMomentumTimestamp, &total); err != nil {
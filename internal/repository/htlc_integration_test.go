@@ -4,6 +4,7 @@ package repository
 
 import (
 	"context"
+	"math/big"
 	"testing"
 
 	"github.com/jackc/pgx/v5"
@@ -21,7 +22,7 @@ func TestIntegration_Htlc_InsertSettleList(t *testing.T) {
 		TimeLockedAddress:         "z1qsender",
 		HashLockedAddress:         "z1qreceiver",
 		TokenStandard:             models.ZnnTokenStandard,
-		Amount:                    500,
+		Amount:                    big.NewInt(500),
 		ExpirationTimestamp:       1700001000,
 		HashType:                  0,
 		KeyMaxSize:                32,
@@ -65,7 +66,7 @@ func TestIntegration_Htlc_BatchAndPagination(t *testing.T) {
 		TimeLockedAddress:         "z1qsender",
 		HashLockedAddress:         "z1qreceiver",
 		TokenStandard:             models.ZnnTokenStandard,
-		Amount:                    100,
+		Amount:                    big.NewInt(100),
 		ExpirationTimestamp:       1700001000,
 		HashType:                  0,
 		KeyMaxSize:                32,
@@ -79,7 +80,7 @@ func TestIntegration_Htlc_BatchAndPagination(t *testing.T) {
 		TimeLockedAddress:         "z1qsender",
 		HashLockedAddress:         "z1qreceiver",
 		TokenStandard:             models.ZnnTokenStandard,
-		Amount:                    200,
+		Amount:                    big.NewInt(200),
 		ExpirationTimestamp:       1700002000,
 		HashType:                  0,
 		KeyMaxSize:                32,
@@ -99,7 +100,7 @@ func TestIntegration_Htlc_BatchAndPagination(t *testing.T) {
 	if err != nil {
 		t.Fatalf("get b10: %v", err)
 	}
-	if gotLow.Status != int16(models.HtlcStatusActive) || gotLow.Amount != 100 {
+	if gotLow.Status != int16(models.HtlcStatusActive) || gotLow.Amount.Cmp(big.NewInt(100)) != 0 {
 		t.Errorf("b10 not inserted as active: %+v", gotLow)
 	}
 	gotHigh, err := repo.GetByID(ctx, "b20")
@@ -5,6 +5,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"math/big"
 	"testing"
 
 	"github.com/0x3639/nom-indexer-go/internal/models"
@@ -78,7 +79,7 @@ func TestIntegration_AccountBlockRepo_List(t *testing.T) {
 	for i := 1; i <= 4; i++ {
 		ab := &models.AccountBlock{
 			Hash: fmt.Sprintf("b%d", i), MomentumHeight: int64(100 + i),
-			BlockType: 2, Height: int64(i), Address: "z1qsender", ToAddress: "z1qrecv", Amount: int64(i * 10),
+			BlockType: 2, Height: int64(i), Address: "z1qsender", ToAddress: "z1qrecv", Amount: big.NewInt(int64(i * 10)),
 		}
 		if err := repo.Insert(ctx, ab, nil); err != nil {
 			t.Fatalf("seed: %v", err)
@@ -159,14 +160,14 @@ func TestIntegration_BalanceRepo_List(t *testing.T) {
 	for i := 1; i <= 3; i++ {
 		if err := repo.Upsert(ctx, &models.Balance{
 			Address: fmt.Sprintf("z1qa%d", i), TokenStandard: models.ZnnTokenStandard,
-			Balance: int64(i * 100), LastUpdatedTimestamp: 1000,
+			Balance: big.NewInt(int64(i * 100)), LastUpdatedTimestamp: 1000,
 		}); err != nil {
 			t.Fatalf("seed: %v", err)
 		}
 	}
 	// Zero balance is excluded by ListByToken.
 	_ = repo.Upsert(ctx, &models.Balance{
-		Address: "z1qzero", TokenStandard: models.ZnnTokenStandard, Balance: 0,
+		Address: "z1qzero", TokenStandard: models.ZnnTokenStandard, Balance: big.NewInt(0),
 	})
 
 	byAddr, _ := repo.ListByAddress(ctx, "z1qa2")
@@ -182,7 +183,7 @@ func TestIntegration_BalanceRepo_List(t *testing.T) {
 		t.Errorf("ListByToken got %d/%d, want 3/3 (excluding zero)", len(byTok), total)
 	}
 	// DESC by balance.
-	if byTok[0].Balance != 300 {
+	if byTok[0].Balance.Cmp(big.NewInt(300)) != 0 {
 		t.Errorf("first row balance = %d, want 300", byTok[0].Balance)
 	}
 }
@@ -298,12 +299,12 @@ func TestIntegration_StakeFusionRepos_List(t *testing.T) {
 		_ = sr.Insert(ctx, &models.Stake{
 			ID: fmt.Sprintf("s%d", i), Address: "z1qstaker",
 			StartTimestamp: int64(1000 * i), ExpirationTimestamp: int64(2000 * i),
-			ZnnAmount: int64(i * 100), DurationInSec: 100, IsActive: true, CancelID: fmt.Sprintf("c%d", i),
+			ZnnAmount: big.NewInt(int64(i * 100)), DurationInSec: 100, IsActive: true, CancelID: fmt.Sprintf("c%d", i),
 		})
 		_ = fr.Insert(ctx, &models.Fusion{
 			ID: fmt.Sprintf("f%d", i), Address: "z1qfuser", Beneficiary: "z1qbene",
 			MomentumHash: "mh", MomentumTimestamp: int64(1000 * i), MomentumHeight: int64(i),
-			QsrAmount: int64(i * 50), ExpirationHeight: int64(100 * i), IsActive: true, CancelID: fmt.Sprintf("fc%d", i),
+			QsrAmount: big.NewInt(int64(i * 50)), ExpirationHeight: int64(100 * i), IsActive: true, CancelID: fmt.Sprintf("fc%d", i),
 		})
 	}
 
@@ -369,11 +370,11 @@ func TestIntegration_RewardRepo_List(t *testing.T) {
 	repo := NewRewardRepository(pool)
 
 	for i := 1; i <= 3; i++ {
-		_ = repo.UpdateCumulativeRewards(ctx, "z1qrcv", models.RewardTypePillar, int64(i*100), models.ZnnTokenStandard)
+		_ = repo.UpdateCumulativeRewards(ctx, "z1qrcv", models.RewardTypePillar, big.NewInt(int64(i*100)), models.ZnnTokenStandard)
 		_ = repo.InsertRewardTransaction(ctx, &models.RewardTransaction{
 			Hash: fmt.Sprintf("rt%d", i), Address: "z1qrcv", RewardType: models.RewardTypePillar,
 			MomentumTimestamp: int64(1000 * i), MomentumHeight: int64(i), AccountHeight: int64(i),
-			Amount: int64(i * 100), TokenStandard: models.ZnnTokenStandard, SourceAddress: models.PillarAddress,
+			Amount: big.NewInt(int64(i * 100)), TokenStandard: models.ZnnTokenStandard, SourceAddress: models.PillarAddress,
 		})
 	}
 
@@ -381,7 +382,7 @@ func TestIntegration_RewardRepo_List(t *testing.T) {
 	if err != nil {
 		t.Fatalf("CumulativeByAddress: %v", err)
 	}
-	if len(cum) != 1 || cum[0].Amount != 600 {
+	if len(cum) != 1 || cum[0].Amount.Cmp(big.NewInt(600)) != 0 {
 		t.Errorf("CumulativeByAddress = %+v, want one row amount=600 (100+200+300)", cum)
 	}
 
@@ -400,13 +401,13 @@ func TestIntegration_BridgeRepo_List(t *testing.T) {
 		_ = repo.UpsertWrapRequest(ctx, &models.WrapTokenRequest{
 			ID: fmt.Sprintf("w%d", i), NetworkClass: 2, ChainID: 1,
 			ToAddress: "0xeth", TokenStandard: models.ZnnTokenStandard,
-			TokenAddress: "0xt", Amount: int64(i * 100), Fee: 1,
+			TokenAddress: "0xt", Amount: big.NewInt(int64(i * 100)), Fee: big.NewInt(1),
 			CreationMomentumHeight: int64(i * 10),
 		})
 		_ = repo.UpsertUnwrapRequest(ctx, &models.UnwrapTokenRequest{
 			TransactionHash: fmt.Sprintf("0xtx%d", i), LogIndex: 0, NetworkClass: 2, ChainID: 1,
 			ToAddress: "z1qrcv", TokenStandard: models.ZnnTokenStandard,
-			TokenAddress: "0xt", Amount: int64(i * 50),
+			TokenAddress: "0xt", Amount: big.NewInt(int64(i * 50)),
 			RegistrationMomentumHeight: int64(i * 10),
 		})
 	}
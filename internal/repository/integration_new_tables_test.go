@@ -4,6 +4,9 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"math/big"
+	"reflect"
 	"testing"
 
 	"github.com/jackc/pgx/v5"
@@ -43,7 +46,7 @@ func TestIntegration_TokenEvents_MintBurnRoundTrip(t *testing.T) {
 		TokenStandard:     models.ZnnTokenStandard,
 		Issuer:            models.PillarAddress,
 		Receiver:          "z1qrecipient",
-		Amount:            5000,
+		Amount:            big.NewInt(5000),
 	}
 	if err := repo.InsertMint(ctx, mint); err != nil {
 		t.Fatalf("insert mint: %v", err)
@@ -58,7 +61,7 @@ func TestIntegration_TokenEvents_MintBurnRoundTrip(t *testing.T) {
 		MomentumTimestamp: 1700000001,
 		TokenStandard:     models.ZnnTokenStandard,
 		Burner:            "z1qburner",
-		Amount:            200,
+		Amount:            big.NewInt(200),
 	}
 	if err := repo.InsertBurn(ctx, burn); err != nil {
 		t.Fatalf("insert burn: %v", err)
@@ -82,7 +85,7 @@ func TestIntegration_TokenEvents_SumDailyMintsBurns(t *testing.T) {
 	mk := func(hash string, ts int64, std string, amount int64) *models.TokenMint {
 		return &models.TokenMint{
 			AccountBlockHash: hash, MomentumHeight: 1, MomentumTimestamp: ts,
-			TokenStandard: std, Issuer: "z1qx", Receiver: "z1qy", Amount: amount,
+			TokenStandard: std, Issuer: "z1qx", Receiver: "z1qy", Amount: big.NewInt(amount),
 		}
 	}
 	for _, m := range []*models.TokenMint{
@@ -98,7 +101,7 @@ func TestIntegration_TokenEvents_SumDailyMintsBurns(t *testing.T) {
 	}
 	if err := repo.InsertBurn(ctx, &models.TokenBurn{
 		AccountBlockHash: "0xd", MomentumHeight: 1, MomentumTimestamp: dayStart + 3,
-		TokenStandard: models.ZnnTokenStandard, Burner: "z1qb", Amount: 50,
+		TokenStandard: models.ZnnTokenStandard, Burner: "z1qb", Amount: big.NewInt(50),
 	}); err != nil {
 		t.Fatalf("insert burn: %v", err)
 	}
@@ -107,10 +110,10 @@ func TestIntegration_TokenEvents_SumDailyMintsBurns(t *testing.T) {
 	if err != nil {
 		t.Fatalf("sum: %v", err)
 	}
-	if mints != 350 {
+	if mints.Cmp(big.NewInt(350)) != 0 {
 		t.Errorf("expected mints=350, got %d", mints)
 	}
-	if burns != 50 {
+	if burns.Cmp(big.NewInt(50)) != 0 {
 		t.Errorf("expected burns=50, got %d", burns)
 	}
 }
@@ -183,7 +186,7 @@ func TestIntegration_DelegationHistory_CloseAndOpen(t *testing.T) {
 
 	// Open delegation to A at ts=100.
 	b := &pgx.Batch{}
-	repo.OpenBatch(b, "z1qdelegator", "z1qa", 100)
+	repo.OpenBatch(b, "d1", "z1qdelegator", "z1qa", 100)
 	sendBatch(t, ctx, pool, b)
 
 	got, err := repo.GetActivePillarFor(ctx, "z1qdelegator")
@@ -197,7 +200,7 @@ func TestIntegration_DelegationHistory_CloseAndOpen(t *testing.T) {
 	// Switch to B at ts=200: close A, open B.
 	b = &pgx.Batch{}
 	repo.CloseActiveBatch(b, "z1qdelegator", 200)
-	repo.OpenBatch(b, "z1qdelegator", "z1qb", 200)
+	repo.OpenBatch(b, "d2", "z1qdelegator", "z1qb", 200)
 	sendBatch(t, ctx, pool, b)
 
 	got, _ = repo.GetActivePillarFor(ctx, "z1qdelegator")
@@ -220,6 +223,95 @@ func TestIntegration_DelegationHistory_CloseAndOpen(t *testing.T) {
 	}
 }
 
+// TestIntegration_Delegation_ReplayIsIdempotent applies Delegate,
+// Undelegate, Delegate the way the indexer does, replays every block
+// (backfill --reprocess), and checks the account and history are
+// unchanged. A pre-035 row without a hash is not reopened either.
+func TestIntegration_Delegation_ReplayIsIdempotent(t *testing.T) {
+	pool := newTestDB(t)
+	ctx := context.Background()
+	repos := NewRepositories(pool)
+	const a, p1, p2 = "z1qdelegator", "z1qp1", "z1qp2"
+
+	if err := repos.Account.Upsert(ctx, &models.Account{Address: a}); err != nil {
+		t.Fatalf("upsert account: %v", err)
+	}
+	b := &pgx.Batch{}
+	for h, hash := range []string{"rd1", "ru2", "rd3"} {
+		repos.AccountBlock.InsertBatch(b, &models.AccountBlock{
+			Hash: hash, MomentumHash: "m", MomentumTimestamp: int64(100 * (h + 1)), MomentumHeight: int64(h + 1),
+			BlockType: models.BlockTypeContractReceive, Height: int64(h + 1), Address: models.PillarAddress,
+			Amount: big.NewInt(0), TokenStandard: models.ZnnTokenStandard,
+		}, nil)
+	}
+	apply := func(b *pgx.Batch) {
+		repos.Delegation.DelegateBatch(b, "rd1", 1, a, p1, 100)
+		repos.Delegation.UndelegateBatch(b, "ru2", 2, a, 200)
+		repos.Delegation.DelegateBatch(b, "rd3", 3, a, p2, 300)
+	}
+	apply(b)
+	sendBatch(t, ctx, pool, b)
+
+	type interval struct {
+		pillar  string
+		started int64
+		ended   *int64
+	}
+	state := func() (string, int64, []interval) {
+		acc, err := repos.Account.GetByAddress(ctx, a)
+		if err != nil {
+			t.Fatalf("get account: %v", err)
+		}
+		rows, err := pool.Query(ctx, `SELECT pillar_owner_address, started_at, ended_at
+			FROM delegations WHERE delegator_address = $1 ORDER BY started_at, id`, a)
+		if err != nil {
+			t.Fatalf("list delegations: %v", err)
+		}
+		defer rows.Close()
+		var out []interval
+		for rows.Next() {
+			var iv interval
+			if err := rows.Scan(&iv.pillar, &iv.started, &iv.ended); err != nil {
+				t.Fatalf("scan: %v", err)
+			}
+			out = append(out, iv)
+		}
+		return acc.Delegate, acc.DelegationStartTimestamp, out
+	}
+	delegate, since, history := state()
+	ended := int64(200)
+	want := []interval{{p1, 100, &ended}, {p2, 300, nil}}
+	if delegate != p2 || since != 300 || !reflect.DeepEqual(history, want) {
+		t.Fatalf("after indexing: %s@%d %+v, want %s@300 %+v", delegate, since, history, p2, want)
+	}
+
+	// Replay the older blocks on their own, then all three.
+	b = &pgx.Batch{}
+	repos.Delegation.DelegateBatch(b, "rd1", 1, a, p1, 100)
+	repos.Delegation.UndelegateBatch(b, "ru2", 2, a, 200)
+	sendBatch(t, ctx, pool, b)
+	b = &pgx.Batch{}
+	apply(b)
+	sendBatch(t, ctx, pool, b)
+
+	delegate, since, history = state()
+	if delegate != p2 || since != 300 || !reflect.DeepEqual(history, want) {
+		t.Errorf("after replay: %s@%d %+v, want %s@300 %+v", delegate, since, history, p2, want)
+	}
+
+	// A row written before account_block_hash existed.
+	const legacy = "z1qlegacy"
+	b = &pgx.Batch{}
+	repos.Delegation.OpenBatch(b, "", legacy, p1, 100)
+	repos.Delegation.DelegateBatch(b, "rdx", 9, legacy, p1, 100)
+	sendBatch(t, ctx, pool, b)
+	var n int
+	_ = pool.QueryRow(ctx, `SELECT COUNT(*) FROM delegations WHERE delegator_address = $1 AND ended_at IS NULL`, legacy).Scan(&n)
+	if n != 1 {
+		t.Errorf("legacy delegator open intervals = %d, want 1", n)
+	}
+}
+
 func TestIntegration_StatHistory_NetworkUpsertIsIdempotent(t *testing.T) {
 	pool := newTestDB(t)
 	ctx := context.Background()
@@ -255,11 +347,11 @@ func TestIntegration_Account_AddSendAddReceiveAndActivity(t *testing.T) {
 	repo := NewAccountRepository(pool)
 
 	b := &pgx.Batch{}
-	repo.AddSendBatch(b, "z1qsender", models.ZnnTokenStandard, 100, 1000)
-	repo.AddSendBatch(b, "z1qsender", models.ZnnTokenStandard, 200, 2000)
-	repo.AddReceiveBatch(b, "z1qrecv", models.QsrTokenStandard, 50, 1500)
+	repo.AddSendBatch(b, "h1", "z1qsender", models.ZnnTokenStandard, 100, 1000)
+	repo.AddSendBatch(b, "h2", "z1qsender", models.ZnnTokenStandard, 200, 2000)
+	repo.AddReceiveBatch(b, "h3", "z1qrecv", models.QsrTokenStandard, 50, 1500)
 	// Non-ZNN/QSR — only updates activity.
-	repo.AddReceiveBatch(b, "z1qsender", "zts1other", 10, 500)
+	repo.AddReceiveBatch(b, "h4", "z1qsender", "zts1other", 10, 500)
 	sendBatch(t, ctx, pool, b)
 
 	sender, err := repo.GetByAddress(ctx, "z1qsender")
@@ -368,11 +460,11 @@ func TestIntegration_Account_BumpTxCount(t *testing.T) {
 
 	b := &pgx.Batch{}
 	// Three "appearances" for z1qa across times 1000, 2000, 500.
-	repo.BumpTxCountBatch(b, "z1qa", 1000)
-	repo.BumpTxCountBatch(b, "z1qa", 2000)
-	repo.BumpTxCountBatch(b, "z1qa", 500)
+	repo.BumpTxCountBatch(b, "h1", "z1qa", 1000)
+	repo.BumpTxCountBatch(b, "h2", "z1qa", 2000)
+	repo.BumpTxCountBatch(b, "h3", "z1qa", 500)
 	// One appearance for z1qb at time 1500.
-	repo.BumpTxCountBatch(b, "z1qb", 1500)
+	repo.BumpTxCountBatch(b, "h4", "z1qb", 1500)
 	sendBatch(t, ctx, pool, b)
 
 	a, err := repo.GetByAddress(ctx, "z1qa")
@@ -444,3 +536,587 @@ func TestIntegration_Pillar_IsWithdrawAddress(t *testing.T) {
 		}
 	}
 }
+
+func TestIntegration_Spork_CreateActivateAndRollback(t *testing.T) {
+	pool := newTestDB(t)
+	ctx := context.Background()
+	repos := NewRepositories(pool)
+	repo := repos.Spork
+
+	b := &pgx.Batch{}
+	repo.InsertBatch(b, &models.Spork{ID: "s1", Name: "htlc", Description: "enable htlc",
+		CreatorAddress: "z1qspork", CreationMomentumHeight: 10, CreationMomentumTimestamp: 1000})
+	repo.InsertBatch(b, &models.Spork{ID: "s2", Name: "ptlc", CreationMomentumHeight: 20, CreationMomentumTimestamp: 2000})
+	repo.ActivateBatch(b, "s1", 36, 31, 3100)
+	// A second activation and an unknown id change nothing.
+	repo.ActivateBatch(b, "s1", 99, 90, 9000)
+	repo.ActivateBatch(b, "nope", 99, 90, 9000)
+	sendBatch(t, ctx, pool, b)
+
+	s, err := repo.GetByID(ctx, "s1")
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if !s.Activated || s.EnforcementHeight != 36 || s.ActivationMomentumHeight != 31 || s.Name != "htlc" {
+		t.Errorf("s1 = %+v, want activated at 31 enforcing from 36", s)
+	}
+	if _, err := repo.GetByID(ctx, "nope"); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("GetByID(unknown) err = %v, want pgx.ErrNoRows", err)
+	}
+
+	all, total, err := repo.List(ctx, false, ListOpts{Limit: 10})
+	if err != nil || total != 2 || len(all) != 2 || all[0].ID != "s2" {
+		t.Errorf("List(all) = %d rows, total %d, err %v; want s2 first of 2", len(all), total, err)
+	}
+	active, total, err := repo.List(ctx, true, ListOpts{Limit: 10})
+	if err != nil || total != 1 || len(active) != 1 || active[0].ID != "s1" {
+		t.Errorf("List(activated) = %d rows, total %d, err %v; want only s1", len(active), total, err)
+	}
+
+	// Rolling back to height 15 drops s2 and undoes s1's activation.
+	b = &pgx.Batch{}
+	repos.Reorg.RollbackAboveBatch(b, 15, 1500)
+	sendBatch(t, ctx, pool, b)
+
+	all, total, _ = repo.List(ctx, false, ListOpts{Limit: 10})
+	if total != 1 || len(all) != 1 || all[0].ID != "s1" {
+		t.Fatalf("after rollback List = %d rows, total %d; want only s1", len(all), total)
+	}
+	if all[0].Activated || all[0].EnforcementHeight != 0 || all[0].ActivationMomentumHeight != 0 {
+		t.Errorf("after rollback s1 = %+v, want not activated", all[0])
+	}
+}
+
+func TestIntegration_Liquidity_StakeCancelUnlockAndRollback(t *testing.T) {
+	pool := newTestDB(t)
+	ctx := context.Background()
+	repos := NewRepositories(pool)
+	repo := repos.Liquidity
+
+	const lp = "zts1lp"
+	b := &pgx.Batch{}
+	repo.InsertStakeBatch(b, &models.LiquidityStake{ID: "l1", StakeAddress: "z1qa", TokenStandard: lp,
+		Amount: big.NewInt(10), WeightedAmount: big.NewInt(10), DurationInSec: 100,
+		StartTimestamp: 1000, ExpirationTimestamp: 1100, IsActive: true, CreationMomentumHeight: 10})
+	repo.InsertStakeBatch(b, &models.LiquidityStake{ID: "l2", StakeAddress: "z1qb", TokenStandard: lp,
+		Amount: big.NewInt(20), WeightedAmount: big.NewInt(40), DurationInSec: 500,
+		StartTimestamp: 1000, ExpirationTimestamp: 1500, IsActive: true, CreationMomentumHeight: 10})
+	// Cancelling someone else's entry matches nothing.
+	repo.CancelStakeBatch(b, "l1", "z1qb", 1200, 20)
+	repo.CancelStakeBatch(b, "l1", "z1qa", 1200, 20)
+	repo.UnlockStakesBatch(b, lp, 1300, 30)
+	sendBatch(t, ctx, pool, b)
+
+	all, total, err := repo.ListStakes(ctx, "", false, ListOpts{Limit: 10})
+	if err != nil || total != 2 {
+		t.Fatalf("ListStakes = %d, %v; want 2", total, err)
+	}
+	byID := map[string]*models.LiquidityStake{}
+	for _, s := range all {
+		byID[s.ID] = s
+	}
+	if l1 := byID["l1"]; l1.IsActive || l1.RevokeTimestamp != 1200 || l1.CancelMomentumHeight != 20 {
+		t.Errorf("l1 = %+v, want cancelled at 1200", l1)
+	}
+	if l2 := byID["l2"]; l2.ExpirationTimestamp != 1300 || l2.UnlockMomentumHeight != 30 || l2.WeightedAmount.Int64() != 40 {
+		t.Errorf("l2 = %+v, want unlocked to expire at 1300", l2)
+	}
+	active, total, err := repo.ListStakes(ctx, "z1qb", true, ListOpts{Limit: 10})
+	if err != nil || total != 1 || active[0].ID != "l2" {
+		t.Errorf("ListStakes(z1qb, active) = %d rows, total %d, err %v; want l2", len(active), total, err)
+	}
+
+	// Rolling back to 15 undoes the cancel and the unlock.
+	b = &pgx.Batch{}
+	repos.Reorg.RollbackAboveBatch(b, 15, 1150)
+	sendBatch(t, ctx, pool, b)
+	active, total, _ = repo.ListStakes(ctx, "", true, ListOpts{Limit: 10})
+	if total != 2 {
+		t.Fatalf("after rollback %d active stakes, want 2", total)
+	}
+	for _, s := range active {
+		if s.ID == "l2" && (s.ExpirationTimestamp != 1500 || s.UnlockMomentumHeight != 0) {
+			t.Errorf("after rollback l2 = %+v, want original expiry 1500", s)
+		}
+	}
+}
+
+func TestIntegration_Liquidity_Config(t *testing.T) {
+	pool := newTestDB(t)
+	ctx := context.Background()
+	repo := NewLiquidityRepository(pool)
+
+	if _, err := repo.GetConfig(ctx); !errors.Is(err, pgx.ErrNoRows) {
+		t.Fatalf("GetConfig before sync err = %v, want pgx.ErrNoRows", err)
+	}
+	cfg := &models.LiquidityConfig{
+		Administrator: "z1qadmin", ZnnReward: big.NewInt(5), QsrReward: big.NewInt(6),
+		TokenTuples: []models.LiquidityTokenTuple{{TokenStandard: "zts1lp", ZnnPercentage: 7000, QsrPercentage: 3000, MinAmount: "100"}},
+		Guardians:   []string{"z1qg1", "z1qg2"}, AdministratorDelay: 10, SoftDelay: 5, LastUpdatedTimestamp: 1,
+	}
+	if err := repo.UpsertConfig(ctx, cfg); err != nil {
+		t.Fatalf("UpsertConfig: %v", err)
+	}
+	cfg.IsHalted = true
+	if err := repo.UpsertConfig(ctx, cfg); err != nil {
+		t.Fatalf("UpsertConfig again: %v", err)
+	}
+	got, err := repo.GetConfig(ctx)
+	if err != nil {
+		t.Fatalf("GetConfig: %v", err)
+	}
+	if !got.IsHalted || got.ZnnReward.Int64() != 5 || len(got.Guardians) != 2 ||
+		len(got.TokenTuples) != 1 || got.TokenTuples[0].ZnnPercentage != 7000 || got.TokenTuples[0].MinAmount != "100" {
+		t.Errorf("GetConfig = %+v", got)
+	}
+}
+
+func TestIntegration_Liquidity_AdminActionsListAndRollback(t *testing.T) {
+	pool := newTestDB(t)
+	ctx := context.Background()
+	repos := NewRepositories(pool)
+	repo := repos.Liquidity
+
+	action := func(hash, method string, inputs map[string]string, height int64) *models.LiquidityAdminAction {
+		return &models.LiquidityAdminAction{AccountBlockHash: hash, SendBlockHash: "s" + hash, Method: method,
+			Address: "z1qadmin", TokenStandard: models.ZnnTokenStandard, Amount: big.NewInt(0), Inputs: inputs,
+			MomentumHeight: height, MomentumTimestamp: height * 10}
+	}
+	b := &pgx.Batch{}
+	repo.InsertAdminActionBatch(b, action("r1", "SetAdditionalReward", map[string]string{"znnReward": "5", "qsrReward": "6"}, 10))
+	repo.InsertAdminActionBatch(b, action("r2", "SetIsHalted", map[string]string{"isHalted": "true"}, 20))
+	repo.InsertAdminActionBatch(b, action("r3", "SetIsHalted", map[string]string{"isHalted": "false"}, 30))
+	// Replayed by a reprocess: ignored.
+	repo.InsertAdminActionBatch(b, action("r3", "SetIsHalted", map[string]string{"isHalted": "false"}, 30))
+	sendBatch(t, ctx, pool, b)
+
+	all, total, err := repo.ListAdminActions(ctx, "", ListOpts{Limit: 10})
+	if err != nil || total != 3 || all[0].AccountBlockHash != "r3" || all[2].Inputs["znnReward"] != "5" {
+		t.Fatalf("ListAdminActions = %+v, total %d, err %v; want r3, r2, r1", all, total, err)
+	}
+	halts, total, err := repo.ListAdminActions(ctx, "SetIsHalted", ListOpts{Limit: 10})
+	if err != nil || total != 2 || halts[1].Inputs["isHalted"] != "true" || halts[1].MomentumTimestamp != 200 {
+		t.Errorf("ListAdminActions(SetIsHalted) = %+v, total %d, err %v; want r3, r2", halts, total, err)
+	}
+
+	b = &pgx.Batch{}
+	repos.Reorg.RollbackAboveBatch(b, 15, 150)
+	sendBatch(t, ctx, pool, b)
+	if _, total, _ = repo.ListAdminActions(ctx, "", ListOpts{Limit: 10}); total != 1 {
+		t.Errorf("after rollback %d actions, want 1", total)
+	}
+}
+
+func TestIntegration_BridgeEvent_ListAndRollback(t *testing.T) {
+	pool := newTestDB(t)
+	ctx := context.Background()
+	repos := NewRepositories(pool)
+	repo := repos.BridgeEvent
+
+	b := &pgx.Batch{}
+	repo.InsertBatch(b, &models.BridgeEvent{AccountBlockHash: "r1", SendBlockHash: "s1", Method: "WrapToken",
+		Address: "z1qa", TokenStandard: models.ZnnTokenStandard, Amount: big.NewInt(5),
+		Inputs: map[string]string{"chainId": "1"}, MomentumHeight: 10, MomentumTimestamp: 100})
+	repo.InsertBatch(b, &models.BridgeEvent{AccountBlockHash: "r2", SendBlockHash: "s2", Method: "Halt",
+		Address: "z1qadmin", MomentumHeight: 20, MomentumTimestamp: 200})
+	// A replayed block changes nothing.
+	repo.InsertBatch(b, &models.BridgeEvent{AccountBlockHash: "r1", Method: "Redeem", MomentumHeight: 10})
+	sendBatch(t, ctx, pool, b)
+
+	all, total, err := repo.List(ctx, BridgeEventFilter{}, ListOpts{Limit: 10})
+	if err != nil || total != 2 || all[0].AccountBlockHash != "r2" {
+		t.Fatalf("List = %d rows, total %d, err %v; want r2 first of 2", len(all), total, err)
+	}
+	if w := all[1]; w.Method != "WrapToken" || w.Amount.Int64() != 5 || w.Inputs["chainId"] != "1" {
+		t.Errorf("r1 = %+v", w)
+	}
+	wraps, total, err := repo.List(ctx, BridgeEventFilter{Method: "WrapToken", Address: "z1qa"}, ListOpts{Limit: 10})
+	if err != nil || total != 1 || wraps[0].AccountBlockHash != "r1" {
+		t.Errorf("List(WrapToken, z1qa) = %d rows, total %d, err %v; want r1", len(wraps), total, err)
+	}
+
+	b = &pgx.Batch{}
+	repos.Reorg.RollbackAboveBatch(b, 15, 150)
+	sendBatch(t, ctx, pool, b)
+	if _, total, _ = repo.List(ctx, BridgeEventFilter{}, ListOpts{Limit: 10}); total != 1 {
+		t.Errorf("after rollback %d events, want 1", total)
+	}
+}
+
+func TestIntegration_SentinelEvent_LifecycleAndRollback(t *testing.T) {
+	pool := newTestDB(t)
+	ctx := context.Background()
+	repos := NewRepositories(pool)
+	repo := repos.SentinelEvent
+
+	event := func(hash, method string, znn, qsr, height int64) *models.SentinelEvent {
+		return &models.SentinelEvent{AccountBlockHash: hash, SendBlockHash: "s" + hash, Owner: "z1qa", Method: method,
+			ZnnAmount: big.NewInt(znn), QsrAmount: big.NewInt(qsr), MomentumHeight: height, MomentumTimestamp: height * 10}
+	}
+	b := &pgx.Batch{}
+	repo.InsertBatch(b, event("r1", "DepositQsr", 0, 50000, 10))
+	repo.InsertBatch(b, event("r2", "Register", 5000, 50000, 11))
+	repos.Sentinel.RegisterBatch(b, "z1qa", 110)
+	repo.InsertBatch(b, event("r3", "CollectReward", 7, 3, 20))
+	repo.InsertBatch(b, event("r4", "Revoke", 5000, 50000, 30))
+	repos.Sentinel.SetInactiveBatch(b, "z1qa")
+	// A replayed block changes nothing.
+	repo.InsertBatch(b, event("r1", "WithdrawQsr", 0, 1, 10))
+	sendBatch(t, ctx, pool, b)
+
+	all, total, err := repo.List(ctx, SentinelEventFilter{Owner: "z1qa"}, ListOpts{Limit: 10})
+	if err != nil || total != 4 || all[0].AccountBlockHash != "r4" {
+		t.Fatalf("List = %d rows, total %d, err %v; want r4 first of 4", len(all), total, err)
+	}
+	for _, e := range all {
+		want := "r2"
+		if e.Method == "DepositQsr" {
+			want = ""
+		}
+		if e.RegistrationBlockHash != want {
+			t.Errorf("%s registration = %q, want %q", e.Method, e.RegistrationBlockHash, want)
+		}
+	}
+	if d := all[3]; d.Method != "DepositQsr" || d.QsrAmount.Int64() != 50000 {
+		t.Errorf("r1 = %+v", d)
+	}
+	rewards, total, err := repo.List(ctx, SentinelEventFilter{Method: "CollectReward"}, ListOpts{Limit: 10})
+	if err != nil || total != 1 || rewards[0].ZnnAmount.Int64() != 7 {
+		t.Errorf("List(CollectReward) = %d rows, total %d, err %v; want r3", len(rewards), total, err)
+	}
+	if s, err := repos.Sentinel.GetByOwner(ctx, "z1qa"); err != nil || s.Active || s.RegistrationTimestamp != 110 {
+		t.Errorf("sentinel = %+v, %v; want inactive, registered at 110", s, err)
+	}
+
+	b = &pgx.Batch{}
+	repos.Reorg.RollbackAboveBatch(b, 15, 150)
+	sendBatch(t, ctx, pool, b)
+	if _, total, _ = repo.List(ctx, SentinelEventFilter{}, ListOpts{Limit: 10}); total != 2 {
+		t.Errorf("after rollback %d events, want 2", total)
+	}
+}
+
+func TestIntegration_TokenEvent_HistoryAndRollback(t *testing.T) {
+	pool := newTestDB(t)
+	ctx := context.Background()
+	repos := NewRepositories(pool)
+	repo := repos.TokenEvent
+
+	const zts = "zts1test"
+	event := func(hash, address, owner string, accountHeight int64, mintable, burnable bool, height int64) *models.TokenEvent {
+		return &models.TokenEvent{AccountBlockHash: hash, SendBlockHash: "s" + hash, TokenStandard: zts,
+			Address: address, AccountHeight: accountHeight, OwnerAfter: owner,
+			IsMintableAfter: mintable, IsBurnableAfter: burnable, MomentumHeight: height, MomentumTimestamp: height * 10}
+	}
+	b := &pgx.Batch{}
+	repos.Token.UpsertBatch(b, &models.Token{TokenStandard: zts, Name: "Test", Symbol: "TEST", Owner: "z1qa",
+		TotalSupply: big.NewInt(100), MaxSupply: big.NewInt(1000), IsMintable: true})
+	repo.InsertIssueBatch(b, event("r1", "z1qa", "z1qa", 1, true, false, 10))
+	// Hand the token to z1qb and make it burnable.
+	repo.UpdateTokenBatch(b, event("r2", "z1qa", "z1qb", 2, true, true, 20))
+	// Rejected: z1qa no longer owns it.
+	repo.UpdateTokenBatch(b, event("r3", "z1qa", "z1qa", 3, true, true, 30))
+	// z1qb switches minting off.
+	repo.UpdateTokenBatch(b, event("r4", "z1qb", "z1qb", 4, false, true, 40))
+	// Rejected: minting cannot be switched back on.
+	repo.UpdateTokenBatch(b, event("r5", "z1qb", "z1qb", 5, true, true, 50))
+	sendBatch(t, ctx, pool, b)
+
+	events, total, err := repo.History(ctx, zts, ListOpts{Limit: 10})
+	if err != nil || total != 3 {
+		t.Fatalf("History = %d rows, total %d, err %v; want r4, r2, r1", len(events), total, err)
+	}
+	if e := events[0]; e.AccountBlockHash != "r4" || *e.OwnerBefore != "z1qb" || !*e.IsMintableBefore || e.IsMintableAfter {
+		t.Errorf("r4 = %+v", e)
+	}
+	if e := events[1]; e.AccountBlockHash != "r2" || *e.OwnerBefore != "z1qa" || e.OwnerAfter != "z1qb" || *e.IsBurnableBefore {
+		t.Errorf("r2 = %+v", e)
+	}
+	if e := events[2]; e.Method != "IssueToken" || e.OwnerBefore != nil || e.IsMintableBefore != nil {
+		t.Errorf("r1 = %+v", e)
+	}
+	tok, err := repos.Token.GetByStandard(ctx, zts)
+	if err != nil || tok.Owner != "z1qb" || tok.IsMintable || !tok.IsBurnable || tok.MaxSupply.Int64() != 100 {
+		t.Errorf("token = %+v, %v; want owned by z1qb, burnable, minting off at max supply 100", tok, err)
+	}
+
+	b = &pgx.Batch{}
+	repos.Reorg.RollbackAboveBatch(b, 15, 150)
+	sendBatch(t, ctx, pool, b)
+	if _, total, _ = repo.History(ctx, zts, ListOpts{Limit: 10}); total != 1 {
+		t.Errorf("after rollback %d events, want 1", total)
+	}
+	if tok, err = repos.Token.GetByStandard(ctx, zts); err != nil || tok.Owner != "z1qa" || !tok.IsMintable || tok.IsBurnable {
+		t.Errorf("token after rollback = %+v, %v; want z1qa's mintable, non-burnable token back", tok, err)
+	}
+}
+
+func TestIntegration_Accelerator_HistoryPayoutsAndRollback(t *testing.T) {
+	pool := newTestDB(t)
+	ctx := context.Background()
+	repos := NewRepositories(pool)
+	repo := repos.Accelerator
+
+	for _, owner := range []string{"z1qp1", "z1qp2"} {
+		if err := repos.Pillar.Upsert(ctx, &models.Pillar{OwnerAddress: owner, Name: owner}); err != nil {
+			t.Fatalf("seed pillar: %v", err)
+		}
+	}
+	change := func(hash, project, phase, method, address string, znn, qsr, height, ts int64) *models.ProjectStatusChange {
+		return &models.ProjectStatusChange{AccountBlockHash: hash, ProjectID: project, PhaseID: phase,
+			VotingID: project + phase, Method: method, Address: address,
+			ZnnAmount: big.NewInt(znn), QsrAmount: big.NewInt(qsr), MomentumHeight: height, MomentumTimestamp: ts}
+	}
+	b := &pgx.Batch{}
+	repo.CreateProjectBatch(b, change("c1", "p1", "", "CreateProject", "z1qa", 300, 3000, 10, 100))
+	repo.CreateProjectBatch(b, change("c2", "p2", "", "CreateProject", "z1qa", 10, 10, 10, 100))
+	// One of two pillars votes yes on p1: a pass.
+	repos.Vote.InsertBatch(b, &models.Vote{VoterAddress: "z1qp1", ProjectID: "p1", VotingID: "p1", MomentumHeight: 11})
+	repo.SettleBatch(b, "u1", "z1qu", 12, 200)
+	// p2 runs out of time.
+	repo.SettleBatch(b, "u2", "z1qu", 13, 100+acceleratorVotingPeriod+1)
+	// Rejected: z1qb does not own p1.
+	repo.AddPhaseBatch(b, change("a0", "p1", "ph0", "AddPhase", "z1qb", 300, 3000, 14, 300))
+	repo.AddPhaseBatch(b, change("a1", "p1", "ph1", "AddPhase", "z1qa", 100, 100, 14, 300))
+	repo.AddPhaseBatch(b, change("a2", "p1", "ph2", "UpdatePhase", "z1qa", 300, 3000, 15, 400))
+	// Rejected: ph2 is still in voting.
+	repo.AddPhaseBatch(b, change("a3", "p1", "ph3", "AddPhase", "z1qa", 1, 1, 16, 500))
+	for i, token := range []string{models.ZnnTokenStandard, models.QsrTokenStandard} {
+		repo.InsertPayoutBatch(b, &models.AcceleratorPayout{AccountBlockHash: []string{"d0", "d1"}[i], UpdateBlockHash: "u3",
+			PhaseID: "ph2", ToAddress: "z1qa", TokenStandard: token, Amount: big.NewInt(300 * int64(1+9*i)),
+			MomentumHeight: 17, MomentumTimestamp: 600})
+	}
+	repo.PhasePaidBatch(b, change("u3", "", "ph2", "Update", "z1qu", 300, 3000, 17, 600))
+	// The treasury is funded by a send to the contract; the contract's
+	// receive carries no amount of its own, as on the real chain.
+	repos.AccountBlock.InsertBatch(b, &models.AccountBlock{Hash: "f0", MomentumHeight: 4, MomentumTimestamp: 40,
+		BlockType: models.BlockTypeUserSend, Address: "z1qfunder", ToAddress: models.AcceleratorAddress,
+		Amount: big.NewInt(1000), TokenStandard: models.ZnnTokenStandard}, nil)
+	repos.AccountBlock.InsertBatch(b, &models.AccountBlock{Hash: "f1", MomentumHeight: 5, MomentumTimestamp: 50,
+		BlockType: models.BlockTypeContractReceive, Address: models.AcceleratorAddress, PairedAccountBlock: "f0",
+		Amount: big.NewInt(0), TokenStandard: models.ZnnTokenStandard}, nil)
+	repos.AccountBlock.InsertBatch(b, &models.AccountBlock{Hash: "d0", MomentumHeight: 17, MomentumTimestamp: 600,
+		BlockType: models.BlockTypeContractSend, Address: models.AcceleratorAddress, ToAddress: "z1qa",
+		Amount: big.NewInt(300), TokenStandard: models.ZnnTokenStandard}, nil)
+	sendBatch(t, ctx, pool, b)
+
+	history, total, err := repo.History(ctx, "p1", ListOpts{Limit: 10})
+	if err != nil || total != 6 {
+		t.Fatalf("History(p1) = %d rows, total %d, err %v; want 6", len(history), total, err)
+	}
+	want := []struct {
+		hash, phase string
+		status      models.ProjectStatus
+	}{
+		{"u3", "", models.ProjectStatusCompleted}, {"u3", "ph2", models.ProjectStatusPaid},
+		{"a2", "ph2", models.ProjectStatusVoting}, {"a1", "ph1", models.ProjectStatusVoting},
+		{"u1", "", models.ProjectStatusActive}, {"c1", "", models.ProjectStatusVoting},
+	}
+	for n, w := range want {
+		if c := history[n]; c.AccountBlockHash != w.hash || c.PhaseID != w.phase || c.NewStatus != int16(w.status) {
+			t.Errorf("history[%d] = %+v, want %s %q -> %d", n, c, w.hash, w.phase, w.status)
+		}
+	}
+	if p2, _, _ := repo.History(ctx, "p2", ListOpts{Limit: 10}); len(p2) != 2 || p2[0].NewStatus != int16(models.ProjectStatusClosed) {
+		t.Errorf("History(p2) = %+v, want closed after its voting period", p2)
+	}
+
+	payouts, total, err := repo.ListPayouts(ctx, AcceleratorPayoutFilter{ProjectID: "p1"}, ListOpts{Limit: 10})
+	if err != nil || total != 2 || payouts[0].PhaseID != "ph2" {
+		t.Errorf("ListPayouts(p1) = %+v, %d, %v; want the two ph2 sends", payouts, total, err)
+	}
+	points, _, err := repo.Treasury(ctx, ListOpts{Limit: 10})
+	if err != nil || len(points) != 2 || points[0].ZnnBalance.Int64() != 700 || points[0].ZnnDelta.Int64() != -300 ||
+		points[1].MomentumHeight != 5 || points[1].ZnnDelta.Int64() != 1000 {
+		t.Errorf("Treasury = %+v, %v; want 1000 ZNN received at 5 and 700 left after the payout", points, err)
+	}
+
+	b = &pgx.Batch{}
+	repos.Reorg.RollbackAboveBatch(b, 15, 150)
+	sendBatch(t, ctx, pool, b)
+	if _, total, _ = repo.History(ctx, "p1", ListOpts{Limit: 10}); total != 4 {
+		t.Errorf("after rollback %d changes, want 4", total)
+	}
+	if _, total, _ = repo.ListPayouts(ctx, AcceleratorPayoutFilter{}, ListOpts{Limit: 10}); total != 0 {
+		t.Errorf("after rollback %d payouts, want 0", total)
+	}
+}
+
+func TestIntegration_PillarUpdate_NameHistory(t *testing.T) {
+	pool := newTestDB(t)
+	ctx := context.Background()
+	repo := NewPillarUpdateRepository(pool)
+
+	update := func(hash, method, name string, height int64) *models.PillarUpdate {
+		return &models.PillarUpdate{AccountBlockHash: hash, Method: method, Name: name,
+			OwnerAddress: "z1q" + name, MomentumHeight: height, MomentumHash: "h"}
+	}
+	b := &pgx.Batch{}
+	repo.InsertBatch(b, update("r1", "Register", "p1", 10))
+	repo.InsertBatch(b, update("r2", "UpdatePillar", "p1", 11))
+	repo.InsertBatch(b, update("r3", "Revoke", "p1", 20))
+	repo.InsertBatch(b, update("r4", "RegisterLegacy", "p2", 5))
+	// Reprocessing the same receive block adds nothing.
+	repo.InsertBatch(b, update("r1", "Register", "p1", 10))
+	// Rows without a block hash are never deduplicated.
+	repo.InsertBatch(b, update("", "", "p3", 1))
+	sendBatch(t, ctx, pool, b)
+
+	rows, err := repo.NameHistory(ctx)
+	if err != nil {
+		t.Fatalf("NameHistory: %v", err)
+	}
+	var got []string
+	for _, pu := range rows {
+		got = append(got, pu.AccountBlockHash+" "+pu.Method)
+	}
+	want := []string{"r4 RegisterLegacy", "r1 Register", "r3 Revoke"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NameHistory = %v, want %v", got, want)
+	}
+	var total int
+	if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM pillar_updates`).Scan(&total); err != nil || total != 5 {
+		t.Errorf("pillar_updates has %d rows (%v), want 5", total, err)
+	}
+}
+
+func TestIntegration_Balance_DeltasAndReconcile(t *testing.T) {
+	pool := newTestDB(t)
+	ctx := context.Background()
+	repos := NewRepositories(pool)
+	const addr = "z1qholder"
+	znn := models.ZnnTokenStandard
+
+	balance := func() *big.Int {
+		t.Helper()
+		b, err := repos.Balance.GetByAddressAndToken(ctx, addr, znn)
+		if err != nil {
+			t.Fatalf("balance: %v", err)
+		}
+		return b.Balance
+	}
+
+	// Momentum 5 debits an address with no row yet; reprocessing it
+	// after its effects are marked changes nothing.
+	batch := &pgx.Batch{}
+	repos.Momentum.InsertBatch(ctx, batch, &models.Momentum{Height: 5, Hash: "m5", Timestamp: 500, Producer: "z1qprod"})
+	repos.Account.UpsertBatch(batch, &models.Account{Address: addr, BlockCount: 3})
+	repos.Balance.AddDeltaBatch(batch, 5, addr, znn, big.NewInt(-70), 500)
+	repos.Momentum.MarkEffectsAppliedBatch(batch, 5)
+	sendBatch(t, ctx, pool, batch)
+	again := &pgx.Batch{}
+	repos.Balance.AddDeltaBatch(again, 5, addr, znn, big.NewInt(-70), 500)
+	sendBatch(t, ctx, pool, again)
+	if got := balance(); got.Cmp(big.NewInt(-70)) != 0 {
+		t.Fatalf("balance after reprocess = %s, want -70", got)
+	}
+
+	negative, err := repos.Consistency.Addresses(ctx, "negative_balances", "", 10)
+	if err != nil || len(negative) != 1 || negative[0] != addr {
+		t.Fatalf("negative_balances = %v (%v), want [%s]", negative, err, addr)
+	}
+	heights, err := repos.Consistency.AccountHeights(ctx, []string{addr, "z1qnobody"})
+	if err != nil || len(heights) != 1 || heights[addr] != 3 {
+		t.Fatalf("account heights = %v (%v), want %s at 3", heights, err, addr)
+	}
+
+	// A node read at another height is not written; one at the indexed
+	// height replaces the balance.
+	stale := &pgx.Batch{}
+	repos.Balance.UpsertAtHeightBatch(stale, &models.Balance{Address: addr, TokenStandard: znn, Balance: big.NewInt(930), LastUpdatedTimestamp: 600}, 4)
+	sendBatch(t, ctx, pool, stale)
+	if got := balance(); got.Cmp(big.NewInt(-70)) != 0 {
+		t.Errorf("balance after a read at height 4 = %s, want -70 untouched", got)
+	}
+	current := &pgx.Batch{}
+	repos.Balance.UpsertAtHeightBatch(current, &models.Balance{Address: addr, TokenStandard: znn, Balance: big.NewInt(930), LastUpdatedTimestamp: 600}, 3)
+	sendBatch(t, ctx, pool, current)
+	if got := balance(); got.Cmp(big.NewInt(930)) != 0 {
+		t.Errorf("balance after a read at height 3 = %s, want 930", got)
+	}
+
+	// Reprocessing an older block does not lower the indexed height.
+	older := &pgx.Batch{}
+	repos.Account.UpsertBatch(older, &models.Account{Address: addr, BlockCount: 2})
+	sendBatch(t, ctx, pool, older)
+	if heights, _ := repos.Consistency.AccountHeights(ctx, []string{addr}); heights[addr] != 3 {
+		t.Errorf("block_count after reprocessing block 2 = %d, want 3", heights[addr])
+	}
+}
+
+func TestIntegration_BalanceHistory(t *testing.T) {
+	pool := newTestDB(t)
+	ctx := context.Background()
+	repos := NewRepositories(pool)
+	const addr = "z1qholder"
+	znn, qsr := models.ZnnTokenStandard, models.QsrTokenStandard
+
+	momentum := func(height uint64, hash string, ts int64, deltas map[string]int64) {
+		t.Helper()
+		batch := &pgx.Batch{}
+		repos.Momentum.InsertBatch(ctx, batch, &models.Momentum{Height: height, Hash: hash, Timestamp: ts, Producer: "z1qprod"})
+		for token, d := range deltas {
+			repos.Balance.AddDeltaBatch(batch, height, addr, token, big.NewInt(d), ts)
+		}
+		repos.Momentum.MarkEffectsAppliedBatch(batch, height)
+		sendBatch(t, ctx, pool, batch)
+	}
+	if err := repos.Account.Upsert(ctx, &models.Account{Address: addr, BlockCount: 2}); err != nil {
+		t.Fatalf("account: %v", err)
+	}
+	momentum(5, "m5", 500, map[string]int64{znn: 100})
+	momentum(7, "m7", 700, map[string]int64{znn: -30, qsr: 5})
+	momentum(7, "m7", 700, map[string]int64{znn: -30, qsr: 5}) // reprocessed: already counted
+
+	rows, total, err := repos.Balance.History(ctx, addr, BalanceHistoryFilter{}, ListOpts{Limit: 10})
+	if err != nil || total != 3 || len(rows) != 3 {
+		t.Fatalf("history = %d rows of %d (%v), want 3", len(rows), total, err)
+	}
+	if rows[1].TokenStandard != znn || rows[1].MomentumHeight != 7 ||
+		rows[1].Balance.Int64() != 70 || rows[1].Delta.Int64() != -30 || rows[1].MomentumTimestamp != 700 {
+		t.Errorf("ZNN change at 7 = %+v, want balance 70 delta -30", rows[1])
+	}
+	if rows, _, _ := repos.Balance.History(ctx, addr, BalanceHistoryFilter{TokenStandard: znn, ToHeight: 6}, ListOpts{Limit: 10}); len(rows) != 1 || rows[0].MomentumHeight != 5 {
+		t.Errorf("ZNN history to 6 = %+v, want the change at 5", rows)
+	}
+	if rows, _, _ := repos.Balance.History(ctx, addr, BalanceHistoryFilter{FromHeight: 6}, ListOpts{Limit: 10}); len(rows) != 2 {
+		t.Errorf("history from 6 = %d rows, want 2", len(rows))
+	}
+
+	at := func(height uint64) map[string]int64 {
+		t.Helper()
+		bals, err := repos.Balance.ListByAddressAtHeight(ctx, addr, height)
+		if err != nil {
+			t.Fatalf("balances at %d: %v", height, err)
+		}
+		out := map[string]int64{}
+		for _, b := range bals {
+			out[b.TokenStandard] = b.Balance.Int64()
+		}
+		return out
+	}
+	if got := at(4); len(got) != 0 {
+		t.Errorf("balances at 4 = %v, want none", got)
+	}
+	if got := at(6); len(got) != 1 || got[znn] != 100 {
+		t.Errorf("balances at 6 = %v, want 100 ZNN", got)
+	}
+	if got := at(7); got[znn] != 70 || got[qsr] != 5 {
+		t.Errorf("balances at 7 = %v, want 70 ZNN and 5 QSR", got)
+	}
+
+	// The node reports 1000 ZNN more than the blocks explain, as for a
+	// genesis holder: the whole series moves, the deltas do not.
+	fix := &pgx.Batch{}
+	repos.Balance.UpsertAtHeightBatch(fix, &models.Balance{Address: addr, TokenStandard: znn, Balance: big.NewInt(1070), LastUpdatedTimestamp: 800}, 2)
+	sendBatch(t, ctx, pool, fix)
+	if got := at(4); got[znn] != 1000 {
+		t.Errorf("ZNN at 4 after the correction = %d, want 1000", got[znn])
+	}
+	if got := at(6); got[znn] != 1100 {
+		t.Errorf("ZNN at 6 after the correction = %d, want 1100", got[znn])
+	}
+	if rows, _, _ := repos.Balance.History(ctx, addr, BalanceHistoryFilter{TokenStandard: znn}, ListOpts{Limit: 10}); len(rows) != 2 || rows[0].Delta.Int64() != -30 {
+		t.Errorf("ZNN history after the correction = %+v, want deltas unchanged", rows)
+	}
+}
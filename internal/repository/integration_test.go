@@ -12,6 +12,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"math/big"
 	"os"
 	"path/filepath"
 	"strings"
@@ -99,10 +100,16 @@ func newTestDB(t *testing.T) *pgxpool.Pool {
 		bridge_networks, bridge_network_tokens, bridge_admin, bridge_guardians,
 		bridge_orchestrator_info, bridge_security_info,
 		bridge_time_challenges,
-		delegations,
+		delegations, sporks, liquidity_stakes, liquidity_config, liquidity_admin_actions, bridge_events,
+		sentinel_events,
+		token_events,
+		project_status_changes, accelerator_payouts,
+		balance_history,
 		network_stat_histories, token_stat_histories, pillar_stat_histories,
 		bridge_stat_histories,
-		indexer_sync_status
+		indexer_sync_status,
+		webhook_outbox, webhook_delivery_attempts, webhook_subscriptions,
+		backfill_checkpoints, indexer_failed_heights, consistency_issues
 		RESTART IDENTITY`)
 	if err != nil {
 		t.Fatalf("truncate: %v", err)
@@ -177,7 +184,7 @@ func TestIntegration_AccountBlock_InsertAndUpdate(t *testing.T) {
 		Height:             1,
 		Address:            "z1qsender",
 		ToAddress:          "z1qrecipient",
-		Amount:             1000,
+		Amount:             big.NewInt(1000),
 		TokenStandard:      models.ZnnTokenStandard,
 		Data:               "",
 		PairedAccountBlock: "",
@@ -359,8 +366,8 @@ func TestIntegration_Bridge_FinalityHelpers(t *testing.T) {
 		ToAddress:               "0xeth",
 		TokenStandard:           models.ZnnTokenStandard,
 		TokenAddress:            "0xtoken",
-		Amount:                  100,
-		Fee:                     1,
+		Amount:                  big.NewInt(100),
+		Fee:                     big.NewInt(1),
 		Signature:               "sig",
 		CreationMomentumHeight:  500,
 		ConfirmationsToFinality: 3,
@@ -403,7 +410,7 @@ func TestIntegration_Reward_CumulativeRewardsAccumulates(t *testing.T) {
 	repo := NewRewardRepository(pool)
 
 	for _, amt := range []int64{100, 250, 50} {
-		if err := repo.UpdateCumulativeRewards(ctx, "z1qaddr", models.RewardTypePillar, amt, models.ZnnTokenStandard); err != nil {
+		if err := repo.UpdateCumulativeRewards(ctx, "z1qaddr", models.RewardTypePillar, big.NewInt(amt), models.ZnnTokenStandard); err != nil {
 			t.Fatalf("update: %v", err)
 		}
 	}
@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+// LiquidityRepository manages liquidity_stakes, the Liquidity contract's
+// LP-token stake entries, liquidity_admin_actions, the administrator's
+// and guardians' calls, and the liquidity_config singleton.
+type LiquidityRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewLiquidityRepository constructs a LiquidityRepository backed by pool.
+func NewLiquidityRepository(pool *pgxpool.Pool) *LiquidityRepository {
+	return &LiquidityRepository{pool: pool}
+}
+
+const liquidityStakeColumns = `id, stake_address, token_standard, amount, weighted_amount,
+	duration_in_sec, start_timestamp, expiration_timestamp, revoke_timestamp, is_active,
+	creation_momentum_height, cancel_momentum_height, unlock_momentum_height`
+
+func scanLiquidityStake(row pgx.Row, s *models.LiquidityStake, extra ...interface{}) error {
+	dst := []interface{}{
+		&s.ID, &s.StakeAddress, &s.TokenStandard, NumericDest(&s.Amount), NumericDest(&s.WeightedAmount),
+		&s.DurationInSec, &s.StartTimestamp, &s.ExpirationTimestamp, &s.RevokeTimestamp, &s.IsActive,
+		&s.CreationMomentumHeight, &s.CancelMomentumHeight, &s.UnlockMomentumHeight,
+	}
+	return row.Scan(append(dst, extra...)...)
+}
+
+// InsertStakeBatch enqueues a LiquidityStake on the per-momentum batch.
+// Idempotent via ON CONFLICT (id) DO NOTHING.
+func (r *LiquidityRepository) InsertStakeBatch(batch *pgx.Batch, s *models.LiquidityStake) {
+	batch.Queue(`
+		INSERT INTO liquidity_stakes (`+liquidityStakeColumns+`)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)
+		ON CONFLICT (id) DO NOTHING`,
+		s.ID, s.StakeAddress, s.TokenStandard, numeric(s.Amount), numeric(s.WeightedAmount),
+		s.DurationInSec, s.StartTimestamp, s.ExpirationTimestamp, s.RevokeTimestamp, s.IsActive,
+		s.CreationMomentumHeight, s.CancelMomentumHeight, s.UnlockMomentumHeight)
+}
+
+// CancelStakeBatch enqueues a CancelLiquidityStake on the per-momentum
+// batch. The contract keys entries by (id, owner), so a cancel sent by
+// anyone but the owner matches nothing.
+func (r *LiquidityRepository) CancelStakeBatch(batch *pgx.Batch, id, address string, revokeTs, height int64) {
+	batch.Queue(`
+		UPDATE liquidity_stakes SET is_active = false, revoke_timestamp = $3,
+			cancel_momentum_height = $4
+		WHERE id = $1 AND stake_address = $2 AND is_active`,
+		id, address, revokeTs, height)
+}
+
+// UnlockStakesBatch enqueues an UnlockLiquidityStakeEntries on the
+// per-momentum batch: every active entry of tokenStandard that expires
+// after ts becomes cancellable at ts.
+func (r *LiquidityRepository) UnlockStakesBatch(batch *pgx.Batch, tokenStandard string, ts, height int64) {
+	batch.Queue(`
+		UPDATE liquidity_stakes SET expiration_timestamp = $2, unlock_momentum_height = $3
+		WHERE token_standard = $1 AND is_active AND expiration_timestamp > $2`,
+		tokenStandard, ts, height)
+}
+
+// ListStakes returns liquidity stakes newest first. address filters to one
+// staker when non-empty; activeOnly to is_active = true.
+func (r *LiquidityRepository) ListStakes(ctx context.Context, address string, activeOnly bool, opts ListOpts) ([]*models.LiquidityStake, int64, error) {
+	where := "WHERE ($1 = '' OR stake_address = $1)"
+	if activeOnly {
+		where += " AND is_active"
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+liquidityStakeColumns+`, COUNT(*) OVER () AS total
+		FROM liquidity_stakes `+where+`
+		ORDER BY creation_momentum_height DESC, id
+		LIMIT $2 OFFSET $3`, address, opts.Limit, opts.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("LiquidityRepository.ListStakes: %w", err)
+	}
+	defer rows.Close()
+	var (
+		out   []*models.LiquidityStake
+		total int64
+	)
+	for rows.Next() {
+		var s models.LiquidityStake
+		if err := scanLiquidityStake(rows, &s, &total); err != nil {
+			return nil, 0, fmt.Errorf("LiquidityRepository.ListStakes: %w", err)
+		}
+		out = append(out, &s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("LiquidityRepository.ListStakes: %w", err)
+	}
+	if len(out) == 0 && opts.Offset > 0 {
+		if total, err = fallbackCount(ctx, r.pool, `SELECT COUNT(*) FROM liquidity_stakes `+where, address); err != nil {
+			return nil, 0, fmt.Errorf("LiquidityRepository.ListStakes: %w", err)
+		}
+	}
+	return out, total, nil
+}
+
+// UpsertConfig writes the liquidity_config singleton.
+func (r *LiquidityRepository) UpsertConfig(ctx context.Context, c *models.LiquidityConfig) error {
+	tuples := c.TokenTuples
+	if tuples == nil {
+		tuples = []models.LiquidityTokenTuple{}
+	}
+	tuplesJSON, err := json.Marshal(tuples)
+	if err != nil {
+		return fmt.Errorf("LiquidityRepository.UpsertConfig: %w", err)
+	}
+	guardians := c.Guardians
+	if guardians == nil {
+		guardians = []string{}
+	}
+	_, err = r.pool.Exec(ctx, `
+		INSERT INTO liquidity_config (row_id, administrator, is_halted, znn_reward, qsr_reward,
+			token_tuples, guardians, administrator_delay, soft_delay, last_updated_timestamp)
+		VALUES (1, $1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (row_id) DO UPDATE SET
+			administrator = EXCLUDED.administrator,
+			is_halted = EXCLUDED.is_halted,
+			znn_reward = EXCLUDED.znn_reward,
+			qsr_reward = EXCLUDED.qsr_reward,
+			token_tuples = EXCLUDED.token_tuples,
+			guardians = EXCLUDED.guardians,
+			administrator_delay = EXCLUDED.administrator_delay,
+			soft_delay = EXCLUDED.soft_delay,
+			last_updated_timestamp = EXCLUDED.last_updated_timestamp`,
+		c.Administrator, c.IsHalted, numeric(c.ZnnReward), numeric(c.QsrReward),
+		tuplesJSON, guardians, c.AdministratorDelay, c.SoftDelay, c.LastUpdatedTimestamp)
+	if err != nil {
+		return fmt.Errorf("LiquidityRepository.UpsertConfig: %w", err)
+	}
+	return nil
+}
+
+// GetConfig returns the liquidity_config singleton; pgx.ErrNoRows,
+// wrapped, until the first sync has written it.
+func (r *LiquidityRepository) GetConfig(ctx context.Context) (*models.LiquidityConfig, error) {
+	var (
+		c          models.LiquidityConfig
+		tuplesJSON []byte
+	)
+	err := r.pool.QueryRow(ctx, `
+		SELECT administrator, is_halted, znn_reward, qsr_reward, token_tuples, guardians,
+			administrator_delay, soft_delay, last_updated_timestamp
+		FROM liquidity_config WHERE row_id = 1`).Scan(
+		&c.Administrator, &c.IsHalted, NumericDest(&c.ZnnReward), NumericDest(&c.QsrReward),
+		&tuplesJSON, &c.Guardians, &c.AdministratorDelay, &c.SoftDelay, &c.LastUpdatedTimestamp)
+	if err != nil {
+		return nil, fmt.Errorf("LiquidityRepository.GetConfig: %w", err)
+	}
+	if err := json.Unmarshal(tuplesJSON, &c.TokenTuples); err != nil {
+		return nil, fmt.Errorf("LiquidityRepository.GetConfig: token_tuples: %w", err)
+	}
+	return &c, nil
+}
+
+const liquidityAdminActionColumns = `account_block_hash, send_block_hash, method, address, token_standard,
+	amount, inputs, momentum_height, momentum_timestamp`
+
+// InsertAdminActionBatch enqueues a LiquidityAdminAction on the
+// per-momentum batch. Idempotent via ON CONFLICT (account_block_hash) DO
+// NOTHING.
+func (r *LiquidityRepository) InsertAdminActionBatch(batch *pgx.Batch, a *models.LiquidityAdminAction) {
+	inputs := "{}"
+	if len(a.Inputs) > 0 {
+		if b, err := json.Marshal(a.Inputs); err == nil {
+			inputs = sanitizeJSONForPostgres(string(b))
+		}
+	}
+	batch.Queue(`
+		INSERT INTO liquidity_admin_actions (`+liquidityAdminActionColumns+`)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+		ON CONFLICT (account_block_hash) DO NOTHING`,
+		a.AccountBlockHash, a.SendBlockHash, a.Method, a.Address, a.TokenStandard,
+		numeric(a.Amount), inputs, a.MomentumHeight, a.MomentumTimestamp)
+}
+
+// ListAdminActions returns administrator and guardian calls newest first.
+// method filters to one ABI method when non-empty.
+func (r *LiquidityRepository) ListAdminActions(ctx context.Context, method string, opts ListOpts) ([]*models.LiquidityAdminAction, int64, error) {
+	const where = `WHERE ($1 = '' OR method = $1)`
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+liquidityAdminActionColumns+`, COUNT(*) OVER () AS total
+		FROM liquidity_admin_actions `+where+`
+		ORDER BY momentum_height DESC, account_block_hash
+		LIMIT $2 OFFSET $3`, method, opts.Limit, opts.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("LiquidityRepository.ListAdminActions: %w", err)
+	}
+	defer rows.Close()
+	var (
+		out   []*models.LiquidityAdminAction
+		total int64
+	)
+	for rows.Next() {
+		a := &models.LiquidityAdminAction{}
+		if err := rows.Scan(&a.AccountBlockHash, &a.SendBlockHash, &a.Method, &a.Address, &a.TokenStandard,
+			NumericDest(&a.Amount), &a.Inputs, &a.MomentumHeight, &a.MomentumTimestamp, &total); err != nil {
+			return nil, 0, fmt.Errorf("LiquidityRepository.ListAdminActions: %w", err)
+		}
+		out = append(out, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("LiquidityRepository.ListAdminActions: %w", err)
+	}
+	if len(out) == 0 && opts.Offset > 0 {
+		if total, err = fallbackCount(ctx, r.pool, `SELECT COUNT(*) FROM liquidity_admin_actions `+where, method); err != nil {
+			return nil, 0, fmt.Errorf("LiquidityRepository.ListAdminActions: %w", err)
+		}
+	}
+	return out, total, nil
+}
@@ -78,6 +78,78 @@ func (r *MomentumRepository) GetLatest(ctx context.Context) (*models.Momentum, e
 	return &m, nil
 }
 
+// HashesByHeightRange returns the stored momentum hash for every indexed
+// height in [from, to] inclusive, keyed by height. Heights that are not
+// indexed (gaps, or beyond the tip) are simply absent from the map. Used
+// by the indexer's parent-hash check and common-ancestor search, which
+// only need the hash column.
+func (r *MomentumRepository) HashesByHeightRange(ctx context.Context, from, to uint64) (map[uint64]string, error) {
+	out := make(map[uint64]string)
+	if from > to {
+		return out, nil
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT height, hash FROM momentums
+		WHERE height >= $1 AND height <= $2`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			height uint64
+			hash   string
+		)
+		if err := rows.Scan(&height, &hash); err != nil {
+			return nil, err
+		}
+		out[height] = hash
+	}
+	return out, rows.Err()
+}
+
+// GapsInRange returns, in ascending order, the heights in [from, to]
+// that need backfilling: heights with no momentum row, and momentums
+// with fewer stored account blocks than their tx_count.
+func (r *MomentumRepository) GapsInRange(ctx context.Context, from, to uint64) ([]uint64, error) {
+	if from > to {
+		return nil, nil
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT e.height
+		FROM generate_series($1::bigint, $2::bigint) AS e(height)
+		LEFT JOIN momentums m ON m.height = e.height
+		WHERE m.height IS NULL
+		UNION
+		SELECT m.height
+		FROM momentums m
+		LEFT JOIN (
+			SELECT momentum_height, COUNT(*) AS actual_txs
+			FROM account_blocks
+			WHERE momentum_height BETWEEN $1 AND $2
+			GROUP BY momentum_height
+		) ab ON ab.momentum_height = m.height
+		WHERE m.height BETWEEN $1 AND $2
+		  AND m.tx_count > 0 AND COALESCE(ab.actual_txs, 0) < m.tx_count
+		ORDER BY 1`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("MomentumRepository.GapsInRange: %w", err)
+	}
+	defer rows.Close()
+	var out []uint64
+	for rows.Next() {
+		var h uint64
+		if err := rows.Scan(&h); err != nil {
+			return nil, fmt.Errorf("MomentumRepository.GapsInRange: %w", err)
+		}
+		out = append(out, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("MomentumRepository.GapsInRange: %w", err)
+	}
+	return out, nil
+}
+
 // List returns momentums ordered by height (sort = "asc" or default desc),
 // along with the total count for pagination metadata.
 //
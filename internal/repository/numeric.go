@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Token amounts are NUMERIC(78,0) columns (migrations/017) carried as
+// *big.Int in internal/models. pgx has no binary codec for *big.Int in
+// either direction, so every amount crosses the driver through the two
+// adapters below: numeric() for query arguments and NumericDest() for
+// Scan targets.
+
+// numeric wraps an amount as a NUMERIC query argument. Every amount
+// column is NOT NULL, so a nil amount is written as 0.
+func numeric(v *big.Int) pgtype.Numeric {
+	if v == nil {
+		return pgtype.Numeric{Int: new(big.Int), Valid: true}
+	}
+	return pgtype.Numeric{Int: v, Valid: true}
+}
+
+// NumericDest returns a Scan target that stores a NUMERIC column into
+// *dst. SQL NULL sets *dst to nil. Exported for the few aggregate
+// queries that run outside this package (internal/indexer/cron.go).
+func NumericDest(dst **big.Int) pgtype.NumericScanner {
+	return bigIntScanner{dst: dst}
+}
+
+type bigIntScanner struct {
+	dst **big.Int
+}
+
+func (s bigIntScanner) ScanNumeric(n pgtype.Numeric) error {
+	if !n.Valid {
+		*s.dst = nil
+		return nil
+	}
+	v, err := numericToBigInt(n)
+	if err != nil {
+		return err
+	}
+	*s.dst = v
+	return nil
+}
+
+// numericToBigInt converts a decoded NUMERIC to an integer. Postgres
+// strips trailing zeros on the wire (1000 arrives as 1e3), so a positive
+// exponent is expected; a fractional value is an error rather than a
+// silent truncation.
+func numericToBigInt(n pgtype.Numeric) (*big.Int, error) {
+	if n.NaN || n.InfinityModifier != pgtype.Finite {
+		return nil, fmt.Errorf("cannot scan non-finite numeric into *big.Int")
+	}
+	v := new(big.Int)
+	if n.Int != nil {
+		v.Set(n.Int)
+	}
+	switch {
+	case n.Exp > 0:
+		scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n.Exp)), nil)
+		v.Mul(v, scale)
+	case n.Exp < 0:
+		scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-n.Exp)), nil)
+		q, rem := new(big.Int).QuoRem(v, scale, new(big.Int))
+		if rem.Sign() != 0 {
+			return nil, fmt.Errorf("cannot scan fractional numeric %s into *big.Int", n.Int.String())
+		}
+		v = q
+	}
+	return v, nil
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestNumericToBigInt(t *testing.T) {
+	// 10^30 overflows int64 by a wide margin — the case NUMERIC exists for.
+	huge, _ := new(big.Int).SetString("1000000000000000000000000000000", 10)
+
+	tests := []struct {
+		name    string
+		in      pgtype.Numeric
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "plain integer",
+			in:   pgtype.Numeric{Int: big.NewInt(12345), Valid: true},
+			want: "12345",
+		},
+		{
+			name: "trailing zeros carried in exponent",
+			in:   pgtype.Numeric{Int: big.NewInt(1), Exp: 30, Valid: true},
+			want: huge.String(),
+		},
+		{
+			name: "negative exponent with zero fraction",
+			in:   pgtype.Numeric{Int: big.NewInt(1500), Exp: -2, Valid: true},
+			want: "15",
+		},
+		{
+			name: "nil Int reads as zero",
+			in:   pgtype.Numeric{Valid: true},
+			want: "0",
+		},
+		{
+			name:    "fractional value rejected",
+			in:      pgtype.Numeric{Int: big.NewInt(1501), Exp: -2, Valid: true},
+			wantErr: true,
+		},
+		{
+			name:    "NaN rejected",
+			in:      pgtype.Numeric{NaN: true, Valid: true},
+			wantErr: true,
+		},
+		{
+			name:    "infinity rejected",
+			in:      pgtype.Numeric{InfinityModifier: pgtype.Infinity, Valid: true},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := numericToBigInt(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("numericToBigInt() = %s, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("numericToBigInt() error = %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("numericToBigInt() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNumericDestNull(t *testing.T) {
+	v := big.NewInt(7)
+	if err := NumericDest(&v).ScanNumeric(pgtype.Numeric{}); err != nil {
+		t.Fatalf("ScanNumeric(NULL) error = %v", err)
+	}
+	if v != nil {
+		t.Errorf("ScanNumeric(NULL) left %s, want nil", v)
+	}
+}
+
+func TestNumericNilWritesZero(t *testing.T) {
+	n := numeric(nil)
+	if !n.Valid || n.Int == nil || n.Int.Sign() != 0 {
+		t.Errorf("numeric(nil) = %+v, want valid 0", n)
+	}
+}
@@ -105,12 +105,13 @@ func (r *PillarRepository) IncrementMomentumCount(ctx context.Context, ownerAddr
 	return err
 }
 
-// IncrementMomentumCountBatch adds a produced-momentum counter update to a batch.
-func (r *PillarRepository) IncrementMomentumCountBatch(batch *pgx.Batch, ownerAddress string) {
+// IncrementMomentumCountBatch adds a produced-momentum counter update to
+// a batch, applied once per momentum height; see momentumEffectsPending.
+func (r *PillarRepository) IncrementMomentumCountBatch(batch *pgx.Batch, height uint64, ownerAddress string) {
 	batch.Queue(`
 		UPDATE pillars SET produced_momentum_count = produced_momentum_count + 1
-		WHERE owner_address = $1`,
-		ownerAddress)
+		WHERE owner_address = $1 AND `+momentumEffectsPending("$2"),
+		ownerAddress, height)
 }
 
 // UpdateVotingActivity updates the voting activity
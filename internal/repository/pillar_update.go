@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -17,27 +18,35 @@ func NewPillarUpdateRepository(pool *pgxpool.Pool) *PillarUpdateRepository {
 	return &PillarUpdateRepository{pool: pool}
 }
 
-// Insert inserts a pillar update
+// Insert inserts a pillar update. Idempotent per receive block: a row
+// whose AccountBlockHash is already recorded is skipped.
 func (r *PillarUpdateRepository) Insert(ctx context.Context, pu *models.PillarUpdate) error {
 	_, err := r.pool.Exec(ctx, `
-		INSERT INTO pillar_updates (name, owner_address, producer_address, withdraw_address,
+		INSERT INTO pillar_updates (account_block_hash, method, name, owner_address,
+			producer_address, withdraw_address,
 			momentum_timestamp, momentum_height, momentum_hash,
 			give_momentum_reward_percentage, give_delegate_reward_percentage)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
-		pu.Name, pu.OwnerAddress, pu.ProducerAddress, pu.WithdrawAddress,
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (account_block_hash) WHERE account_block_hash <> '' DO NOTHING`,
+		pu.AccountBlockHash, pu.Method, pu.Name, pu.OwnerAddress,
+		pu.ProducerAddress, pu.WithdrawAddress,
 		pu.MomentumTimestamp, pu.MomentumHeight, pu.MomentumHash,
 		pu.GiveMomentumRewardPercentage, pu.GiveDelegateRewardPercentage)
 	return err
 }
 
-// InsertBatch adds a pillar update insert to a batch
+// InsertBatch adds a pillar update insert to a batch, idempotent like
+// Insert.
 func (r *PillarUpdateRepository) InsertBatch(batch *pgx.Batch, pu *models.PillarUpdate) {
 	batch.Queue(`
-		INSERT INTO pillar_updates (name, owner_address, producer_address, withdraw_address,
+		INSERT INTO pillar_updates (account_block_hash, method, name, owner_address,
+			producer_address, withdraw_address,
 			momentum_timestamp, momentum_height, momentum_hash,
 			give_momentum_reward_percentage, give_delegate_reward_percentage)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
-		pu.Name, pu.OwnerAddress, pu.ProducerAddress, pu.WithdrawAddress,
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (account_block_hash) WHERE account_block_hash <> '' DO NOTHING`,
+		pu.AccountBlockHash, pu.Method, pu.Name, pu.OwnerAddress,
+		pu.ProducerAddress, pu.WithdrawAddress,
 		pu.MomentumTimestamp, pu.MomentumHeight, pu.MomentumHash,
 		pu.GiveMomentumRewardPercentage, pu.GiveDelegateRewardPercentage)
 }
@@ -69,3 +78,30 @@ func (r *PillarUpdateRepository) GetInfoAtHeightByProducer(ctx context.Context,
 	}
 	return ownerAddress, name, nil
 }
+
+// NameHistory returns the rows that bound who held a pillar name — the
+// Register, RegisterLegacy and Revoke rows — in chain order.
+func (r *PillarUpdateRepository) NameHistory(ctx context.Context) ([]*models.PillarUpdate, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, account_block_hash, method, name, owner_address, momentum_height
+		FROM pillar_updates
+		WHERE method IN ('Register', 'RegisterLegacy', 'Revoke')
+		ORDER BY momentum_height, id`)
+	if err != nil {
+		return nil, fmt.Errorf("PillarUpdateRepository.NameHistory: %w", err)
+	}
+	defer rows.Close()
+	var out []*models.PillarUpdate
+	for rows.Next() {
+		pu := &models.PillarUpdate{}
+		if err := rows.Scan(&pu.ID, &pu.AccountBlockHash, &pu.Method, &pu.Name,
+			&pu.OwnerAddress, &pu.MomentumHeight); err != nil {
+			return nil, fmt.Errorf("PillarUpdateRepository.NameHistory: %w", err)
+		}
+		out = append(out, pu)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("PillarUpdateRepository.NameHistory: %w", err)
+	}
+	return out, nil
+}
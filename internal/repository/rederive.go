@@ -0,0 +1,574 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+// RederiveRepository backs cmd/rederive: it reads stored account blocks
+// back out, reads the projection rows a height range owns, and clears
+// them so they can be rewritten. The rewrites themselves go through the
+// owning repositories' batch methods, so the tool and the indexer share
+// the INSERT statements.
+type RederiveRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRederiveRepository constructs a RederiveRepository backed by pool.
+func NewRederiveRepository(pool *pgxpool.Pool) *RederiveRepository {
+	return &RederiveRepository{pool: pool}
+}
+
+// blockPairCols selects a receive block (r) and its paired send (s) in
+// the column order of accountBlockCols, twice.
+const blockPairCols = `r.hash, r.momentum_hash, r.momentum_timestamp, r.momentum_height, r.block_type,
+	r.height, r.address, r.to_address, r.amount, r.token_standard, r.data, r.method, r.input,
+	r.paired_account_block, r.descendant_of,
+	s.hash, s.momentum_hash, s.momentum_timestamp, s.momentum_height, s.block_type,
+	s.height, s.address, s.to_address, s.amount, s.token_standard, s.data, s.method, s.input,
+	s.paired_account_block, s.descendant_of`
+
+// blockPairs runs a SELECT over receive blocks r joined to their paired
+// send s, filtered by where, in chain order.
+func (r *RederiveRepository) blockPairs(ctx context.Context, where string, args ...interface{}) ([]*models.BlockPair, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+blockPairCols+`
+		FROM account_blocks r
+		JOIN account_blocks s ON s.hash = r.paired_account_block
+		WHERE `+where+`
+		ORDER BY r.momentum_height, r.height`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.BlockPair
+	for rows.Next() {
+		p := &models.BlockPair{}
+		if err := rows.Scan(append(accountBlockDest(&p.Receive), accountBlockDest(&p.Send)...)...); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// ContractCalls returns the calls contract received in momentums from
+// through to: its contract-receive blocks, each with the user send that
+// made the call.
+func (r *RederiveRepository) ContractCalls(ctx context.Context, contract string, from, to uint64) ([]*models.BlockPair, error) {
+	out, err := r.blockPairs(ctx, `r.address = $1 AND r.block_type = $2
+		AND r.momentum_height BETWEEN $3 AND $4`,
+		contract, models.BlockTypeContractReceive, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("RederiveRepository.ContractCalls: %w", err)
+	}
+	return out, nil
+}
+
+// ContractCallsByID returns, at any height, the calls to contract whose
+// send was indexed as one of methods with an `id` input in ids — the
+// cancels and settlements that reference an entry by id.
+func (r *RederiveRepository) ContractCallsByID(ctx context.Context, contract string, methods, ids []string) ([]*models.BlockPair, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	out, err := r.blockPairs(ctx, `r.address = $1 AND r.block_type = $2
+		AND s.method = ANY($3) AND s.input->>'id' = ANY($4)`,
+		contract, models.BlockTypeContractReceive, methods, ids)
+	if err != nil {
+		return nil, fmt.Errorf("RederiveRepository.ContractCallsByID: %w", err)
+	}
+	return out, nil
+}
+
+// ContractCallsBySender returns, at any height, the calls to contract
+// sent by any of senders.
+func (r *RederiveRepository) ContractCallsBySender(ctx context.Context, contract string, senders []string) ([]*models.BlockPair, error) {
+	if len(senders) == 0 {
+		return nil, nil
+	}
+	out, err := r.blockPairs(ctx, `r.address = $1 AND r.block_type = $2 AND s.address = ANY($3)`,
+		contract, models.BlockTypeContractReceive, senders)
+	if err != nil {
+		return nil, fmt.Errorf("RederiveRepository.ContractCallsBySender: %w", err)
+	}
+	return out, nil
+}
+
+// RewardReceives returns the user-receive blocks in momentums from
+// through to that may collect a reward: those paired with a contract
+// send or with a send from the liquidity treasury.
+func (r *RederiveRepository) RewardReceives(ctx context.Context, from, to uint64) ([]*models.BlockPair, error) {
+	out, err := r.blockPairs(ctx, `r.block_type = $1 AND r.momentum_height BETWEEN $2 AND $3
+		AND (s.block_type = $4 OR s.address = $5)`,
+		models.BlockTypeUserReceive, from, to, models.BlockTypeContractSend, models.LiquidityTreasuryAddress)
+	if err != nil {
+		return nil, fmt.Errorf("RederiveRepository.RewardReceives: %w", err)
+	}
+	return out, nil
+}
+
+// TimestampRange returns the first and last momentum timestamps in
+// heights from through to, or zeros when none are indexed. Stakes carry
+// only a timestamp, so their rows are matched to a height range by it.
+func (r *RederiveRepository) TimestampRange(ctx context.Context, from, to uint64) (first, last int64, err error) {
+	err = r.pool.QueryRow(ctx, `
+		SELECT COALESCE(MIN(timestamp), 0), COALESCE(MAX(timestamp), 0)
+		FROM momentums WHERE height BETWEEN $1 AND $2`, from, to).Scan(&first, &last)
+	if err != nil {
+		return 0, 0, fmt.Errorf("RederiveRepository.TimestampRange: %w", err)
+	}
+	return first, last, nil
+}
+
+// Votes returns the votes cast in momentums from through to, plus any
+// vote held for one of the (voters[i], votingIDs[i]) pairs.
+func (r *RederiveRepository) Votes(ctx context.Context, from, to uint64, voters, votingIDs []string) ([]*models.Vote, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, momentum_hash, momentum_timestamp, momentum_height,
+			voter_address, project_id, phase_id, voting_id, vote
+		FROM votes
+		WHERE momentum_height BETWEEN $1 AND $2
+			OR (voter_address, voting_id) IN (SELECT * FROM unnest($3::text[], $4::text[]))`,
+		from, to, voters, votingIDs)
+	if err != nil {
+		return nil, fmt.Errorf("RederiveRepository.Votes: %w", err)
+	}
+	defer rows.Close()
+	var out []*models.Vote
+	for rows.Next() {
+		v := &models.Vote{}
+		if err := rows.Scan(&v.ID, &v.MomentumHash, &v.MomentumTimestamp, &v.MomentumHeight,
+			&v.VoterAddress, &v.ProjectID, &v.PhaseID, &v.VotingID, &v.Vote); err != nil {
+			return nil, fmt.Errorf("RederiveRepository.Votes: %w", err)
+		}
+		out = append(out, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("RederiveRepository.Votes: %w", err)
+	}
+	return out, nil
+}
+
+// RewardTransactions returns the reward transactions in momentums from
+// through to.
+func (r *RederiveRepository) RewardTransactions(ctx context.Context, from, to uint64) ([]*models.RewardTransaction, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT hash, address, reward_type, momentum_timestamp, momentum_height,
+			account_height, amount, token_standard, source_address
+		FROM reward_transactions
+		WHERE momentum_height BETWEEN $1 AND $2`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("RederiveRepository.RewardTransactions: %w", err)
+	}
+	defer rows.Close()
+	var out []*models.RewardTransaction
+	for rows.Next() {
+		rt := &models.RewardTransaction{}
+		if err := rows.Scan(&rt.Hash, &rt.Address, &rt.RewardType, &rt.MomentumTimestamp, &rt.MomentumHeight,
+			&rt.AccountHeight, NumericDest(&rt.Amount), &rt.TokenStandard, &rt.SourceAddress); err != nil {
+			return nil, fmt.Errorf("RederiveRepository.RewardTransactions: %w", err)
+		}
+		out = append(out, rt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("RederiveRepository.RewardTransactions: %w", err)
+	}
+	return out, nil
+}
+
+// Delegations returns every delegation interval of delegators.
+func (r *RederiveRepository) Delegations(ctx context.Context, delegators []string) ([]*models.Delegation, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, delegator_address, pillar_owner_address, started_at, ended_at
+		FROM delegations WHERE delegator_address = ANY($1)
+		ORDER BY delegator_address, started_at, id`, delegators)
+	if err != nil {
+		return nil, fmt.Errorf("RederiveRepository.Delegations: %w", err)
+	}
+	defer rows.Close()
+	var out []*models.Delegation
+	for rows.Next() {
+		d := &models.Delegation{}
+		if err := rows.Scan(&d.ID, &d.DelegatorAddress, &d.PillarOwnerAddress, &d.StartedAt, &d.EndedAt); err != nil {
+			return nil, fmt.Errorf("RederiveRepository.Delegations: %w", err)
+		}
+		out = append(out, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("RederiveRepository.Delegations: %w", err)
+	}
+	return out, nil
+}
+
+// Accounts returns the accounts rows of addresses; addresses without a
+// row are left out.
+func (r *RederiveRepository) Accounts(ctx context.Context, addresses []string) ([]*models.Account, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT address, block_count, public_key, delegate, delegation_start_timestamp,
+			genesis_znn_balance, genesis_qsr_balance,
+			znn_sent, znn_received, qsr_sent, qsr_received,
+			first_active_at, last_active_at,
+			first_seen, last_seen, tx_count
+		FROM accounts WHERE address = ANY($1)`, addresses)
+	if err != nil {
+		return nil, fmt.Errorf("RederiveRepository.Accounts: %w", err)
+	}
+	defer rows.Close()
+	var out []*models.Account
+	for rows.Next() {
+		a := &models.Account{}
+		if err := rows.Scan(&a.Address, &a.BlockCount, &a.PublicKey, &a.Delegate, &a.DelegationStartTimestamp,
+			&a.GenesisZnnBalance, &a.GenesisQsrBalance,
+			&a.ZnnSent, &a.ZnnReceived, &a.QsrSent, &a.QsrReceived,
+			&a.FirstActiveAt, &a.LastActiveAt,
+			&a.FirstSeen, &a.LastSeen, &a.TxCount); err != nil {
+			return nil, fmt.Errorf("RederiveRepository.Accounts: %w", err)
+		}
+		out = append(out, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("RederiveRepository.Accounts: %w", err)
+	}
+	return out, nil
+}
+
+// Stakes returns the stakes with one of ids or started between the
+// timestamps first and last.
+func (r *RederiveRepository) Stakes(ctx context.Context, ids []string, first, last int64) ([]*models.Stake, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, address, start_timestamp, expiration_timestamp, znn_amount,
+			duration_in_sec, is_active, cancel_id
+		FROM stakes
+		WHERE id = ANY($1) OR start_timestamp BETWEEN $2 AND $3`, ids, first, last)
+	if err != nil {
+		return nil, fmt.Errorf("RederiveRepository.Stakes: %w", err)
+	}
+	defer rows.Close()
+	var out []*models.Stake
+	for rows.Next() {
+		s := &models.Stake{}
+		if err := rows.Scan(&s.ID, &s.Address, &s.StartTimestamp, &s.ExpirationTimestamp, NumericDest(&s.ZnnAmount),
+			&s.DurationInSec, &s.IsActive, &s.CancelID); err != nil {
+			return nil, fmt.Errorf("RederiveRepository.Stakes: %w", err)
+		}
+		out = append(out, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("RederiveRepository.Stakes: %w", err)
+	}
+	return out, nil
+}
+
+// Fusions returns the fusions with one of ids or created in momentums
+// from through to.
+func (r *RederiveRepository) Fusions(ctx context.Context, ids []string, from, to uint64) ([]*models.Fusion, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, address, beneficiary, momentum_hash, momentum_timestamp,
+			momentum_height, qsr_amount, expiration_height, is_active, cancel_id
+		FROM fusions
+		WHERE id = ANY($1) OR momentum_height BETWEEN $2 AND $3`, ids, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("RederiveRepository.Fusions: %w", err)
+	}
+	defer rows.Close()
+	var out []*models.Fusion
+	for rows.Next() {
+		f := &models.Fusion{}
+		if err := rows.Scan(&f.ID, &f.Address, &f.Beneficiary, &f.MomentumHash, &f.MomentumTimestamp,
+			&f.MomentumHeight, NumericDest(&f.QsrAmount), &f.ExpirationHeight, &f.IsActive, &f.CancelID); err != nil {
+			return nil, fmt.Errorf("RederiveRepository.Fusions: %w", err)
+		}
+		out = append(out, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("RederiveRepository.Fusions: %w", err)
+	}
+	return out, nil
+}
+
+// Htlcs returns the HTLCs with one of ids or created in momentums from
+// through to.
+func (r *RederiveRepository) Htlcs(ctx context.Context, ids []string, from, to uint64) ([]*models.Htlc, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+htlcCols+`
+		FROM htlcs
+		WHERE id = ANY($1) OR creation_momentum_height BETWEEN $2 AND $3`, ids, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("RederiveRepository.Htlcs: %w", err)
+	}
+	defer rows.Close()
+	var out []*models.Htlc
+	for rows.Next() {
+		h := &models.Htlc{}
+		if err := rows.Scan(&h.ID, &h.TimeLockedAddress, &h.HashLockedAddress, &h.TokenStandard, NumericDest(&h.Amount),
+			&h.ExpirationTimestamp, &h.HashType, &h.KeyMaxSize, &h.HashLock, &h.Status, &h.Preimage,
+			&h.CreationMomentumHeight, &h.CreationMomentumTimestamp,
+			&h.SettleMomentumHeight, &h.SettleMomentumTimestamp); err != nil {
+			return nil, fmt.Errorf("RederiveRepository.Htlcs: %w", err)
+		}
+		out = append(out, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("RederiveRepository.Htlcs: %w", err)
+	}
+	return out, nil
+}
+
+// TokenMints returns the token mints in momentums from through to.
+func (r *RederiveRepository) TokenMints(ctx context.Context, from, to uint64) ([]*models.TokenMint, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, account_block_hash, momentum_height, momentum_timestamp,
+			token_standard, issuer, receiver, amount
+		FROM token_mints WHERE momentum_height BETWEEN $1 AND $2`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("RederiveRepository.TokenMints: %w", err)
+	}
+	defer rows.Close()
+	var out []*models.TokenMint
+	for rows.Next() {
+		m := &models.TokenMint{}
+		if err := rows.Scan(&m.ID, &m.AccountBlockHash, &m.MomentumHeight, &m.MomentumTimestamp,
+			&m.TokenStandard, &m.Issuer, &m.Receiver, NumericDest(&m.Amount)); err != nil {
+			return nil, fmt.Errorf("RederiveRepository.TokenMints: %w", err)
+		}
+		out = append(out, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("RederiveRepository.TokenMints: %w", err)
+	}
+	return out, nil
+}
+
+// TokenBurns returns the token burns in momentums from through to.
+func (r *RederiveRepository) TokenBurns(ctx context.Context, from, to uint64) ([]*models.TokenBurn, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, account_block_hash, momentum_height, momentum_timestamp,
+			token_standard, burner, amount
+		FROM token_burns WHERE momentum_height BETWEEN $1 AND $2`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("RederiveRepository.TokenBurns: %w", err)
+	}
+	defer rows.Close()
+	var out []*models.TokenBurn
+	for rows.Next() {
+		b := &models.TokenBurn{}
+		if err := rows.Scan(&b.ID, &b.AccountBlockHash, &b.MomentumHeight, &b.MomentumTimestamp,
+			&b.TokenStandard, &b.Burner, NumericDest(&b.Amount)); err != nil {
+			return nil, fmt.Errorf("RederiveRepository.TokenBurns: %w", err)
+		}
+		out = append(out, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("RederiveRepository.TokenBurns: %w", err)
+	}
+	return out, nil
+}
+
+// BridgeEvents returns the bridge events in momentums from through to.
+func (r *RederiveRepository) BridgeEvents(ctx context.Context, from, to uint64) ([]*models.BridgeEvent, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+bridgeEventColumns+`
+		FROM bridge_events WHERE momentum_height BETWEEN $1 AND $2`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("RederiveRepository.BridgeEvents: %w", err)
+	}
+	defer rows.Close()
+	var out []*models.BridgeEvent
+	for rows.Next() {
+		e := &models.BridgeEvent{}
+		if err := rows.Scan(bridgeEventDest(e)...); err != nil {
+			return nil, fmt.Errorf("RederiveRepository.BridgeEvents: %w", err)
+		}
+		out = append(out, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("RederiveRepository.BridgeEvents: %w", err)
+	}
+	return out, nil
+}
+
+// PillarUpdates returns the pillar updates in momentums from through to.
+func (r *RederiveRepository) PillarUpdates(ctx context.Context, from, to uint64) ([]*models.PillarUpdate, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, account_block_hash, method, name, owner_address, producer_address, withdraw_address,
+			momentum_timestamp, momentum_height, momentum_hash,
+			give_momentum_reward_percentage, give_delegate_reward_percentage
+		FROM pillar_updates WHERE momentum_height BETWEEN $1 AND $2
+		ORDER BY momentum_height, id`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("RederiveRepository.PillarUpdates: %w", err)
+	}
+	defer rows.Close()
+	var out []*models.PillarUpdate
+	for rows.Next() {
+		pu := &models.PillarUpdate{}
+		if err := rows.Scan(&pu.ID, &pu.AccountBlockHash, &pu.Method, &pu.Name,
+			&pu.OwnerAddress, &pu.ProducerAddress, &pu.WithdrawAddress,
+			&pu.MomentumTimestamp, &pu.MomentumHeight, &pu.MomentumHash,
+			&pu.GiveMomentumRewardPercentage, &pu.GiveDelegateRewardPercentage); err != nil {
+			return nil, fmt.Errorf("RederiveRepository.PillarUpdates: %w", err)
+		}
+		out = append(out, pu)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("RederiveRepository.PillarUpdates: %w", err)
+	}
+	return out, nil
+}
+
+// TouchedAddresses returns every address that appears, as sender or
+// recipient, in an account block of momentums from through to.
+func (r *RederiveRepository) TouchedAddresses(ctx context.Context, from, to uint64) ([]string, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT address FROM account_blocks WHERE momentum_height BETWEEN $1 AND $2
+		UNION
+		SELECT to_address FROM account_blocks
+		WHERE momentum_height BETWEEN $1 AND $2 AND to_address IS NOT NULL AND to_address <> ''`,
+		from, to)
+	if err != nil {
+		return nil, fmt.Errorf("RederiveRepository.TouchedAddresses: %w", err)
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var a string
+		if err := rows.Scan(&a); err != nil {
+			return nil, fmt.Errorf("RederiveRepository.TouchedAddresses: %w", err)
+		}
+		out = append(out, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("RederiveRepository.TouchedAddresses: %w", err)
+	}
+	return out, nil
+}
+
+// AccountFlows computes the flow and activity counters of addresses from
+// every stored account block, the way processAccountBlocks accumulates
+// them: ZNN/QSR sent and received and first/last activity over the
+// address's own chain, and tx_count and first/last seen over every block
+// it sends or is sent to. Only Address and those counters are set.
+func (r *RederiveRepository) AccountFlows(ctx context.Context, addresses []string) ([]*models.Account, error) {
+	rows, err := r.pool.Query(ctx, `
+		WITH addrs AS (SELECT DISTINCT unnest($1::text[]) AS address),
+		own AS (
+			SELECT b.address,
+				COALESCE(SUM(b.amount) FILTER (WHERE b.block_type IN ($2, $3) AND b.token_standard = $7), 0)::bigint AS znn_sent,
+				COALESCE(SUM(b.amount) FILTER (WHERE b.block_type IN ($4, $5, $6) AND b.token_standard = $7), 0)::bigint AS znn_received,
+				COALESCE(SUM(b.amount) FILTER (WHERE b.block_type IN ($2, $3) AND b.token_standard = $8), 0)::bigint AS qsr_sent,
+				COALESCE(SUM(b.amount) FILTER (WHERE b.block_type IN ($4, $5, $6) AND b.token_standard = $8), 0)::bigint AS qsr_received,
+				MIN(b.momentum_timestamp) AS first_active_at,
+				MAX(b.momentum_timestamp) AS last_active_at
+			FROM account_blocks b JOIN addrs ON addrs.address = b.address
+			GROUP BY b.address
+		),
+		seen AS (
+			SELECT addr, MIN(ts) AS first_seen, MAX(ts) AS last_seen, COUNT(*) AS tx_count FROM (
+				SELECT b.hash, b.address AS addr, b.momentum_timestamp AS ts
+				FROM account_blocks b JOIN addrs ON addrs.address = b.address
+				UNION
+				SELECT b.hash, b.to_address, b.momentum_timestamp
+				FROM account_blocks b JOIN addrs ON addrs.address = b.to_address
+			) appearances
+			GROUP BY addr
+		)
+		SELECT addrs.address,
+			COALESCE(own.znn_sent, 0), COALESCE(own.znn_received, 0),
+			COALESCE(own.qsr_sent, 0), COALESCE(own.qsr_received, 0),
+			own.first_active_at, own.last_active_at,
+			seen.first_seen, seen.last_seen, COALESCE(seen.tx_count, 0)
+		FROM addrs
+		LEFT JOIN own ON own.address = addrs.address
+		LEFT JOIN seen ON seen.addr = addrs.address`,
+		addresses,
+		models.BlockTypeUserSend, models.BlockTypeContractSend,
+		models.BlockTypeGenesisReceive, models.BlockTypeUserReceive, models.BlockTypeContractReceive,
+		models.ZnnTokenStandard, models.QsrTokenStandard)
+	if err != nil {
+		return nil, fmt.Errorf("RederiveRepository.AccountFlows: %w", err)
+	}
+	defer rows.Close()
+	var out []*models.Account
+	for rows.Next() {
+		a := &models.Account{}
+		if err := rows.Scan(&a.Address,
+			&a.ZnnSent, &a.ZnnReceived, &a.QsrSent, &a.QsrReceived,
+			&a.FirstActiveAt, &a.LastActiveAt,
+			&a.FirstSeen, &a.LastSeen, &a.TxCount); err != nil {
+			return nil, fmt.Errorf("RederiveRepository.AccountFlows: %w", err)
+		}
+		out = append(out, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("RederiveRepository.AccountFlows: %w", err)
+	}
+	return out, nil
+}
+
+// SetAccountFlowsBatch overwrites the counters AccountFlows computes,
+// inserting a stub account row when the address has none.
+func (r *RederiveRepository) SetAccountFlowsBatch(batch *pgx.Batch, a *models.Account) {
+	batch.Queue(`
+		INSERT INTO accounts (address, block_count, public_key,
+			znn_sent, znn_received, qsr_sent, qsr_received,
+			first_active_at, last_active_at, first_seen, last_seen, tx_count)
+		VALUES ($1, 0, '', $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (address) DO UPDATE SET
+			znn_sent        = EXCLUDED.znn_sent,
+			znn_received    = EXCLUDED.znn_received,
+			qsr_sent        = EXCLUDED.qsr_sent,
+			qsr_received    = EXCLUDED.qsr_received,
+			first_active_at = EXCLUDED.first_active_at,
+			last_active_at  = EXCLUDED.last_active_at,
+			first_seen      = EXCLUDED.first_seen,
+			last_seen       = EXCLUDED.last_seen,
+			tx_count        = EXCLUDED.tx_count`,
+		a.Address, a.ZnnSent, a.ZnnReceived, a.QsrSent, a.QsrReceived,
+		a.FirstActiveAt, a.LastActiveAt, a.FirstSeen, a.LastSeen, a.TxCount)
+}
+
+// rederiveKeyColumns whitelists the tables DeleteKeysBatch clears and the
+// column each is keyed by.
+var rederiveKeyColumns = map[string]string{
+	"reward_transactions": "hash",
+	"delegations":         "delegator_address",
+	"stakes":              "id",
+	"fusions":             "id",
+	"htlcs":               "id",
+	"token_mints":         "account_block_hash",
+	"token_burns":         "account_block_hash",
+	"bridge_events":       "account_block_hash",
+}
+
+// DeleteKeysBatch queues the deletion of the rows of table whose key
+// column (see rederiveKeyColumns) is one of keys.
+func (r *RederiveRepository) DeleteKeysBatch(batch *pgx.Batch, table string, keys []string) error {
+	col, ok := rederiveKeyColumns[table]
+	if !ok {
+		return fmt.Errorf("RederiveRepository.DeleteKeysBatch: unsupported table %q", table)
+	}
+	// String concat is safe here: table and col come from the whitelist.
+	batch.Queue(`DELETE FROM `+table+` WHERE `+col+` = ANY($1)`, keys)
+	return nil
+}
+
+// DeleteVotesBatch queues the deletion of the votes held for the
+// (voters[i], votingIDs[i]) pairs.
+func (r *RederiveRepository) DeleteVotesBatch(batch *pgx.Batch, voters, votingIDs []string) {
+	batch.Queue(`
+		DELETE FROM votes
+		WHERE (voter_address, voting_id) IN (SELECT * FROM unnest($1::text[], $2::text[]))`,
+		voters, votingIDs)
+}
+
+// DeletePillarUpdatesBatch queues the deletion of the pillar updates in
+// momentums from through to.
+func (r *RederiveRepository) DeletePillarUpdatesBatch(batch *pgx.Batch, from, to uint64) {
+	batch.Queue(`DELETE FROM pillar_updates WHERE momentum_height BETWEEN $1 AND $2`, from, to)
+}
@@ -0,0 +1,177 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+// TestIntegration_Rederive_Reads stores a contract call, a reward payout
+// and a plain transfer, and reads them back the way cmd/rederive does.
+func TestIntegration_Rederive_Reads(t *testing.T) {
+	pool := newTestDB(t)
+	ctx := context.Background()
+	repos := NewRepositories(pool)
+	repo := repos.Rederive
+
+	const a, b = "z1qA", "z1qB"
+	batch := &pgx.Batch{}
+	for h, ts := range map[uint64]int64{1: 100, 2: 200, 3: 300} {
+		repos.Momentum.InsertBatch(ctx, batch, &models.Momentum{
+			Height: h, Hash: "m" + string(rune('0'+h)), Timestamp: ts, Producer: "z1qprod",
+		})
+	}
+	block := func(hash string, height int64, blockType int16, addr, to string, amount int64, token, paired string, tx *models.TxData) {
+		repos.AccountBlock.InsertBatch(batch, &models.AccountBlock{
+			Hash: hash, MomentumHash: "m", MomentumTimestamp: height * 100, MomentumHeight: height,
+			BlockType: blockType, Height: height, Address: addr, ToAddress: to,
+			Amount: big.NewInt(amount), TokenStandard: token, Data: "00", PairedAccountBlock: paired,
+		}, tx)
+	}
+	// Height 1: A cancels stake x1. Height 2: the stake contract receives it.
+	block("s1", 1, models.BlockTypeUserSend, a, models.StakeAddress, 0, models.ZnnTokenStandard, "",
+		&models.TxData{Method: "Cancel", Inputs: map[string]string{"id": "x1"}})
+	block("r1", 2, models.BlockTypeContractReceive, models.StakeAddress, models.EmptyAddress, 0, models.EmptyTokenStandard, "s1", nil)
+	// Height 2: the pillar contract pays B; height 3: B collects it and
+	// sends 40 ZNN to A.
+	block("c1", 2, models.BlockTypeContractSend, models.PillarAddress, b, 7, models.QsrTokenStandard, "", nil)
+	block("u1", 3, models.BlockTypeUserReceive, b, models.EmptyAddress, 0, models.EmptyTokenStandard, "c1", nil)
+	block("t1", 3, models.BlockTypeUserSend, b, a, 40, models.ZnnTokenStandard, "", nil)
+	sendBatch(t, ctx, pool, batch)
+
+	calls, err := repo.ContractCalls(ctx, models.StakeAddress, 1, 3)
+	if err != nil {
+		t.Fatalf("contract calls: %v", err)
+	}
+	if len(calls) != 1 || calls[0].Receive.Hash != "r1" || calls[0].Send.Hash != "s1" || calls[0].Send.Address != a {
+		t.Fatalf("contract calls = %+v", calls)
+	}
+	if calls, _ := repo.ContractCalls(ctx, models.StakeAddress, 3, 3); len(calls) != 0 {
+		t.Errorf("calls outside the range = %+v", calls)
+	}
+	if got, _ := repo.ContractCallsByID(ctx, models.StakeAddress, []string{"Cancel"}, []string{"x1"}); len(got) != 1 {
+		t.Errorf("calls by id = %+v, want r1", got)
+	}
+	if got, _ := repo.ContractCallsByID(ctx, models.StakeAddress, []string{"Stake"}, []string{"x1"}); len(got) != 0 {
+		t.Errorf("calls by id, other method = %+v", got)
+	}
+	if got, _ := repo.ContractCallsBySender(ctx, models.StakeAddress, []string{a}); len(got) != 1 {
+		t.Errorf("calls by sender = %+v", got)
+	}
+
+	rewards, err := repo.RewardReceives(ctx, 1, 3)
+	if err != nil {
+		t.Fatalf("reward receives: %v", err)
+	}
+	if len(rewards) != 1 || rewards[0].Receive.Hash != "u1" || rewards[0].Send.Amount.Int64() != 7 {
+		t.Errorf("reward receives = %+v", rewards)
+	}
+
+	first, last, err := repo.TimestampRange(ctx, 2, 3)
+	if err != nil || first != 200 || last != 300 {
+		t.Errorf("timestamp range = %d-%d (%v), want 200-300", first, last, err)
+	}
+
+	touched, err := repo.TouchedAddresses(ctx, 3, 3)
+	if err != nil {
+		t.Fatalf("touched: %v", err)
+	}
+	want := []string{models.EmptyAddress, a, b}
+	slices.Sort(touched)
+	slices.Sort(want)
+	if !slices.Equal(touched, want) {
+		t.Errorf("touched = %v, want %v", touched, want)
+	}
+
+	flows, err := repo.AccountFlows(ctx, []string{a, b, "z1qnobody"})
+	if err != nil {
+		t.Fatalf("account flows: %v", err)
+	}
+	byAddr := map[string]*models.Account{}
+	for _, f := range flows {
+		byAddr[f.Address] = f
+	}
+	fa, fb := byAddr[a], byAddr[b]
+	if fa.ZnnSent != 0 || fa.TxCount != 2 || *fa.FirstSeen != 100 || *fa.LastSeen != 300 || *fa.FirstActiveAt != 100 {
+		t.Errorf("A flows = %+v", fa)
+	}
+	if fb.ZnnSent != 40 || fb.QsrReceived != 0 || fb.TxCount != 3 || *fb.FirstActiveAt != 300 || *fb.FirstSeen != 200 {
+		t.Errorf("B flows = %+v", fb)
+	}
+	if n := byAddr["z1qnobody"]; n.TxCount != 0 || n.FirstSeen != nil {
+		t.Errorf("unknown address flows = %+v", n)
+	}
+
+	// SetAccountFlowsBatch creates the row when missing and overwrites
+	// the counters when not.
+	wb := &pgx.Batch{}
+	repo.SetAccountFlowsBatch(wb, fb)
+	fb2 := *fb
+	fb2.TxCount = 9
+	repo.SetAccountFlowsBatch(wb, &fb2)
+	sendBatch(t, ctx, pool, wb)
+	accs, err := repo.Accounts(ctx, []string{b})
+	if err != nil || len(accs) != 1 || accs[0].TxCount != 9 || accs[0].ZnnSent != 40 {
+		t.Errorf("accounts after set = %+v (%v)", accs, err)
+	}
+}
+
+func TestIntegration_Rederive_Deletes(t *testing.T) {
+	pool := newTestDB(t)
+	ctx := context.Background()
+	repos := NewRepositories(pool)
+	repo := repos.Rederive
+
+	batch := &pgx.Batch{}
+	for _, v := range []*models.Vote{
+		{VoterAddress: "z1qv1", VotingID: "vid1", MomentumHeight: 5},
+		{VoterAddress: "z1qv1", VotingID: "vid2", MomentumHeight: 6},
+		{VoterAddress: "z1qv2", VotingID: "vid1", MomentumHeight: 50},
+	} {
+		repos.Vote.InsertBatch(batch, v)
+	}
+	for _, h := range []int64{5, 6, 50} {
+		repos.PillarUpdate.InsertBatch(batch, &models.PillarUpdate{Name: "p", OwnerAddress: "z1qo", MomentumHeight: h})
+	}
+	repos.Stake.InsertBatch(batch, &models.Stake{ID: "s1", Address: "z1qa", StartTimestamp: 10, IsActive: true})
+	repos.Stake.InsertBatch(batch, &models.Stake{ID: "s2", Address: "z1qa", StartTimestamp: 99, IsActive: true})
+	sendBatch(t, ctx, pool, batch)
+
+	votes, err := repo.Votes(ctx, 1, 10, []string{"z1qv2"}, []string{"vid1"})
+	if err != nil || len(votes) != 3 {
+		t.Fatalf("votes in range or by key = %d (%v), want 3", len(votes), err)
+	}
+	if stakes, _ := repo.Stakes(ctx, []string{"s2"}, 0, 50); len(stakes) != 2 {
+		t.Errorf("stakes by id or start = %d, want 2", len(stakes))
+	}
+
+	db := &pgx.Batch{}
+	repo.DeleteVotesBatch(db, []string{"z1qv1"}, []string{"vid2"})
+	repo.DeletePillarUpdatesBatch(db, 1, 10)
+	if err := repo.DeleteKeysBatch(db, "stakes", []string{"s1"}); err != nil {
+		t.Fatalf("delete stakes: %v", err)
+	}
+	sendBatch(t, ctx, pool, db)
+
+	if err := repo.DeleteKeysBatch(&pgx.Batch{}, "momentums", []string{"1"}); err == nil {
+		t.Error("DeleteKeysBatch accepted a table outside its whitelist")
+	}
+	votes, _ = repo.Votes(ctx, 1, 100, nil, nil)
+	if len(votes) != 2 {
+		t.Errorf("votes after delete = %+v, want vid1 x2", votes)
+	}
+	updates, _ := repo.PillarUpdates(ctx, 1, 100)
+	if len(updates) != 1 || updates[0].MomentumHeight != 50 {
+		t.Errorf("pillar updates after delete = %+v", updates)
+	}
+	if stakes, _ := repo.Stakes(ctx, nil, 0, 1000); len(stakes) != 1 || stakes[0].ID != "s2" {
+		t.Errorf("stakes after delete = %+v", stakes)
+	}
+}
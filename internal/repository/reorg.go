@@ -0,0 +1,323 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+// ReorgRepository rewinds ledger-derived state when the upstream node
+// switches to a different fork. Every statement is keyed off the common
+// ancestor height (and its momentum timestamp, for the tables that only
+// record time), so the rollback is a pure function of the data already
+// in Postgres and can be queued into a single transaction alongside the
+// reorg NOTIFY.
+//
+// RPC-snapshot tables (pillars, sentinels, projects, phases, bridge
+// requests, swap_assets) are not touched: the indexer's refresh loops
+// overwrite them from the node's current view, which is already on the
+// new fork. So are the token contract's balances, which the caller
+// re-fetches.
+type ReorgRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewReorgRepository(pool *pgxpool.Pool) *ReorgRepository {
+	return &ReorgRepository{pool: pool}
+}
+
+// AffectedAddresses returns every address that appears (as sender or
+// recipient) in an account block confirmed above height.
+func (r *ReorgRepository) AffectedAddresses(ctx context.Context, height int64) ([]string, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT address FROM account_blocks WHERE momentum_height > $1
+		UNION
+		SELECT to_address FROM account_blocks
+		WHERE momentum_height > $1 AND to_address IS NOT NULL AND to_address <> ''`,
+		height)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var a string
+		if err := rows.Scan(&a); err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// RollbackAboveBatch queues the statements that undo everything indexed
+// above height: additive counters are decremented by exactly what the
+// orphaned rows contributed, state flips (cancels, settles, delegation
+// closes) are reverted, and then the orphaned rows themselves are
+// deleted. timestamp is the ancestor momentum's timestamp, used for
+// stakes and delegations, which carry no height column.
+//
+// Statement order matters: every counter/state fix-up reads the orphaned
+// account_blocks / momentums rows, so those two tables are deleted last.
+//
+// Votes are upserted per (voter, voting_id), so a vote cast above height
+// has already overwritten any earlier vote by the same voter; rolling it
+// back removes the row rather than restoring the overwritten value.
+func (r *ReorgRepository) RollbackAboveBatch(batch *pgx.Batch, height, timestamp int64) {
+	// Pillar produced-momentum counters.
+	batch.Queue(`
+		UPDATE pillars p SET produced_momentum_count = GREATEST(p.produced_momentum_count - o.n, 0)
+		FROM (
+			SELECT producer_owner, COUNT(*) AS n FROM momentums
+			WHERE height > $1 AND producer_owner <> ''
+			GROUP BY producer_owner
+		) o
+		WHERE p.owner_address = o.producer_owner`,
+		height)
+
+	// Token transaction counters and burn totals.
+	batch.Queue(`
+		UPDATE tokens t SET transaction_count = GREATEST(t.transaction_count - o.n, 0)
+		FROM (
+			SELECT token_standard, COUNT(*) AS n FROM account_blocks
+			WHERE momentum_height > $1
+			GROUP BY token_standard
+		) o
+		WHERE t.token_standard = o.token_standard`,
+		height)
+	batch.Queue(`
+		UPDATE tokens t SET total_burned = t.total_burned - o.amount
+		FROM (
+			SELECT token_standard, SUM(amount) AS amount FROM token_burns
+			WHERE momentum_height > $1
+			GROUP BY token_standard
+		) o
+		WHERE t.token_standard = o.token_standard`,
+		height)
+
+	// Token owner and flags go back to where the first UpdateToken above
+	// the ancestor found them.
+	batch.Queue(`
+		UPDATE tokens t SET owner = e.owner_before,
+			is_mintable = e.is_mintable_before, is_burnable = e.is_burnable_before
+		FROM (
+			SELECT DISTINCT ON (token_standard) token_standard,
+				owner_before, is_mintable_before, is_burnable_before
+			FROM token_events
+			WHERE momentum_height > $1 AND method = 'UpdateToken'
+			ORDER BY token_standard, account_height
+		) e
+		WHERE t.token_standard = e.token_standard`,
+		height)
+
+	// Cumulative reward totals.
+	batch.Queue(`
+		UPDATE cumulative_rewards c SET amount = c.amount - o.amount
+		FROM (
+			SELECT address, reward_type, token_standard, SUM(amount) AS amount
+			FROM reward_transactions
+			WHERE momentum_height > $1
+			GROUP BY address, reward_type, token_standard
+		) o
+		WHERE c.address = o.address AND c.reward_type = o.reward_type
+			AND c.token_standard = o.token_standard`,
+		height)
+
+	// Balances, less what the orphaned blocks moved: a send's own amount,
+	// a receive's paired send's. The token contract is left to the
+	// caller, as its balances do not follow its blocks.
+	batch.Queue(`
+		UPDATE balances b SET balance = b.balance - d.delta
+		FROM (
+			SELECT address, token_standard, SUM(delta) AS delta FROM (
+				SELECT address, token_standard, -amount AS delta FROM account_blocks
+				WHERE momentum_height > $1 AND block_type IN ($2, $3)
+				UNION ALL
+				SELECT rcv.address, s.token_standard, s.amount FROM account_blocks rcv
+				JOIN account_blocks s ON s.hash = rcv.paired_account_block
+				WHERE rcv.momentum_height > $1 AND rcv.block_type IN ($4, $5)
+			) moved
+			WHERE address <> $6
+			GROUP BY address, token_standard
+		) d
+		WHERE b.address = d.address AND b.token_standard = d.token_standard`,
+		height,
+		models.BlockTypeUserSend, models.BlockTypeContractSend,
+		models.BlockTypeUserReceive, models.BlockTypeContractReceive,
+		models.TokenAddress)
+
+	// Account flow totals and tx_count. tx_count counts each block once
+	// per distinct address it touches (see BumpTxCountBatch), which is
+	// what the (hash, address) UNION reproduces.
+	batch.Queue(`
+		UPDATE accounts a SET
+			znn_sent     = a.znn_sent     - COALESCE(f.znn_sent, 0),
+			znn_received = a.znn_received - COALESCE(f.znn_received, 0),
+			qsr_sent     = a.qsr_sent     - COALESCE(f.qsr_sent, 0),
+			qsr_received = a.qsr_received - COALESCE(f.qsr_received, 0)
+		FROM (
+			SELECT address,
+				SUM(amount) FILTER (WHERE block_type IN ($2, $3) AND token_standard = $7) AS znn_sent,
+				SUM(amount) FILTER (WHERE block_type IN ($4, $5, $6) AND token_standard = $7) AS znn_received,
+				SUM(amount) FILTER (WHERE block_type IN ($2, $3) AND token_standard = $8) AS qsr_sent,
+				SUM(amount) FILTER (WHERE block_type IN ($4, $5, $6) AND token_standard = $8) AS qsr_received
+			FROM account_blocks
+			WHERE momentum_height > $1
+			GROUP BY address
+		) f
+		WHERE a.address = f.address`,
+		height,
+		models.BlockTypeUserSend, models.BlockTypeContractSend,
+		models.BlockTypeGenesisReceive, models.BlockTypeUserReceive, models.BlockTypeContractReceive,
+		models.ZnnTokenStandard, models.QsrTokenStandard)
+	batch.Queue(`
+		UPDATE accounts a SET tx_count = GREATEST(a.tx_count - o.n, 0)
+		FROM (
+			SELECT addr, COUNT(*) AS n FROM (
+				SELECT hash, address AS addr FROM account_blocks WHERE momentum_height > $1
+				UNION
+				SELECT hash, to_address AS addr FROM account_blocks
+				WHERE momentum_height > $1 AND to_address IS NOT NULL AND to_address <> ''
+			) appearances
+			GROUP BY addr
+		) o
+		WHERE a.address = o.addr`,
+		height)
+
+	// Activity bounds and chain height are MIN/MAX aggregates, so they
+	// are recomputed from the surviving blocks rather than decremented.
+	batch.Queue(`
+		UPDATE accounts a SET
+			block_count     = COALESCE((SELECT MAX(b.height) FROM account_blocks b
+				WHERE b.address = a.address AND b.momentum_height <= $1), 0),
+			first_active_at = (SELECT MIN(b.momentum_timestamp) FROM account_blocks b
+				WHERE b.address = a.address AND b.momentum_height <= $1),
+			last_active_at  = (SELECT MAX(b.momentum_timestamp) FROM account_blocks b
+				WHERE b.address = a.address AND b.momentum_height <= $1),
+			first_seen      = (SELECT MIN(s.ts) FROM (
+				SELECT momentum_timestamp AS ts FROM account_blocks
+				WHERE address = a.address AND momentum_height <= $1
+				UNION ALL
+				SELECT momentum_timestamp FROM account_blocks
+				WHERE to_address = a.address AND momentum_height <= $1) s),
+			last_seen       = (SELECT MAX(s.ts) FROM (
+				SELECT momentum_timestamp AS ts FROM account_blocks
+				WHERE address = a.address AND momentum_height <= $1
+				UNION ALL
+				SELECT momentum_timestamp FROM account_blocks
+				WHERE to_address = a.address AND momentum_height <= $1) s)
+		WHERE a.address IN (
+			SELECT address FROM account_blocks WHERE momentum_height > $1
+			UNION
+			SELECT to_address FROM account_blocks
+			WHERE momentum_height > $1 AND to_address IS NOT NULL AND to_address <> '')`,
+		height)
+
+	// Delegations: restore each affected delegator's account to the
+	// interval that was open at the ancestor, then drop intervals opened
+	// after it and re-open intervals closed after it.
+	batch.Queue(`
+		UPDATE accounts a SET
+			delegate = COALESCE(d.pillar_owner_address, ''),
+			delegation_start_timestamp = COALESCE(d.started_at, 0)
+		FROM (
+			SELECT DISTINCT delegator_address FROM delegations
+			WHERE started_at > $1 OR ended_at > $1
+		) x
+		LEFT JOIN LATERAL (
+			SELECT pillar_owner_address, started_at FROM delegations
+			WHERE delegator_address = x.delegator_address
+				AND started_at <= $1 AND (ended_at IS NULL OR ended_at > $1)
+			ORDER BY started_at DESC
+			LIMIT 1
+		) d ON true
+		WHERE a.address = x.delegator_address`,
+		timestamp)
+	batch.Queue(`DELETE FROM delegations WHERE started_at > $1`, timestamp)
+	batch.Queue(`UPDATE delegations SET ended_at = NULL WHERE ended_at > $1`, timestamp)
+
+	// Stake and fusion cancels are applied on the contract's receive
+	// block, keyed by the id in the paired send's decoded input.
+	batch.Queue(`
+		UPDATE stakes SET is_active = true
+		WHERE cancel_id IN (
+			SELECT s.input->>'id' FROM account_blocks rcv
+			JOIN account_blocks s ON s.hash = rcv.paired_account_block
+			WHERE rcv.momentum_height > $1 AND rcv.address = $2 AND s.method = 'Cancel')`,
+		height, models.StakeAddress)
+	batch.Queue(`DELETE FROM stakes WHERE start_timestamp > $1`, timestamp)
+	batch.Queue(`
+		UPDATE fusions SET is_active = true
+		WHERE cancel_id IN (
+			SELECT s.input->>'id' FROM account_blocks rcv
+			JOIN account_blocks s ON s.hash = rcv.paired_account_block
+			WHERE rcv.momentum_height > $1 AND rcv.address = $2 AND s.method = 'CancelFuse')`,
+		height, models.PlasmaAddress)
+	batch.Queue(`DELETE FROM fusions WHERE momentum_height > $1`, height)
+
+	// HTLCs settled above the ancestor go back to active; HTLCs created
+	// above it disappear.
+	batch.Queue(`
+		UPDATE htlcs SET status = $2, preimage = '',
+			settle_momentum_height = 0, settle_momentum_timestamp = 0
+		WHERE settle_momentum_height > $1`,
+		height, int16(models.HtlcStatusActive))
+	batch.Queue(`DELETE FROM htlcs WHERE creation_momentum_height > $1`, height)
+
+	// Sporks activated above the ancestor go back to inactive; sporks
+	// created above it disappear.
+	batch.Queue(`
+		UPDATE sporks SET activated = false, enforcement_height = 0,
+			activation_momentum_height = 0, activation_momentum_timestamp = 0
+		WHERE activation_momentum_height > $1`,
+		height)
+	batch.Queue(`DELETE FROM sporks WHERE creation_momentum_height > $1`, height)
+
+	// Liquidity stakes: cancels above the ancestor are undone, and unlocks
+	// restore the original expiry (an entry an earlier unlock already
+	// shortened is never touched by a later one).
+	batch.Queue(`
+		UPDATE liquidity_stakes SET is_active = true, revoke_timestamp = 0, cancel_momentum_height = 0
+		WHERE cancel_momentum_height > $1`,
+		height)
+	batch.Queue(`
+		UPDATE liquidity_stakes SET expiration_timestamp = start_timestamp + duration_in_sec,
+			unlock_momentum_height = 0
+		WHERE unlock_momentum_height > $1`,
+		height)
+	batch.Queue(`DELETE FROM liquidity_stakes WHERE creation_momentum_height > $1`, height)
+
+	// Append-only event tables.
+	batch.Queue(`DELETE FROM token_mints WHERE momentum_height > $1`, height)
+	batch.Queue(`DELETE FROM token_burns WHERE momentum_height > $1`, height)
+	batch.Queue(`DELETE FROM token_events WHERE momentum_height > $1`, height)
+	batch.Queue(`DELETE FROM bridge_events WHERE momentum_height > $1`, height)
+	batch.Queue(`DELETE FROM liquidity_admin_actions WHERE momentum_height > $1`, height)
+	batch.Queue(`DELETE FROM sentinel_events WHERE momentum_height > $1`, height)
+	batch.Queue(`DELETE FROM project_status_changes WHERE momentum_height > $1`, height)
+	batch.Queue(`DELETE FROM accelerator_payouts WHERE momentum_height > $1`, height)
+	batch.Queue(`DELETE FROM balance_history WHERE momentum_height > $1`, height)
+	batch.Queue(`DELETE FROM reward_transactions WHERE momentum_height > $1`, height)
+	batch.Queue(`DELETE FROM votes WHERE momentum_height > $1`, height)
+	batch.Queue(`DELETE FROM pillar_updates WHERE momentum_height > $1`, height)
+	batch.Queue(`DELETE FROM swap_retrievals WHERE momentum_height > $1`, height)
+
+	// Surviving blocks that were linked to an orphaned receive/descendant
+	// lose that link; the re-indexed fork sets it again if it still holds.
+	batch.Queue(`
+		UPDATE account_blocks SET paired_account_block = ''
+		WHERE momentum_height <= $1 AND paired_account_block IN (
+			SELECT hash FROM account_blocks WHERE momentum_height > $1)`,
+		height)
+	batch.Queue(`
+		UPDATE account_blocks SET descendant_of = ''
+		WHERE momentum_height <= $1 AND descendant_of IN (
+			SELECT hash FROM account_blocks WHERE momentum_height > $1)`,
+		height)
+
+	batch.Queue(`DELETE FROM account_blocks WHERE momentum_height > $1`, height)
+	batch.Queue(`DELETE FROM momentums WHERE height > $1`, height)
+}
@@ -0,0 +1,159 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+// TestIntegration_Reorg_RollbackAbove indexes three momentums the way the
+// indexer would, rolls back to height 2, and checks that counters, state
+// flips, and rows all match what indexing heights 1-2 alone produces.
+func TestIntegration_Reorg_RollbackAbove(t *testing.T) {
+	pool := newTestDB(t)
+	ctx := context.Background()
+	repos := NewRepositories(pool)
+
+	const (
+		a, b   = "z1qA", "z1qB"
+		p1, p2 = "z1qpillar1", "z1qpillar2"
+	)
+
+	// Momentum 1: A delegates to p1; HTLC h1 created.
+	// Momentum 2: A sends 100 ZNN to B.
+	// Momentum 3: B receives it, A sends 40 more, A switches to p2,
+	// h1 is unlocked and HTLC h2 is created.
+	batch := &pgx.Batch{}
+	for h, ts := range map[uint64]int64{1: 100, 2: 200, 3: 300} {
+		repos.Momentum.InsertBatch(ctx, batch, &models.Momentum{
+			Height: h, Hash: "m" + string(rune('0'+h)), Timestamp: ts, Producer: "z1qprod",
+		})
+	}
+	repos.Account.UpdateDelegateBatch(batch, a, p1, 100)
+	repos.Delegation.OpenBatch(batch, "r1", a, p1, 100)
+	repos.Htlc.InsertBatch(batch, &models.Htlc{ID: "h1", CreationMomentumHeight: 1, CreationMomentumTimestamp: 100})
+
+	repos.AccountBlock.InsertBatch(batch, &models.AccountBlock{
+		Hash: "b2", MomentumHash: "m2", MomentumTimestamp: 200, MomentumHeight: 2,
+		BlockType: models.BlockTypeUserSend, Height: 1, Address: a, ToAddress: b,
+		Amount: big.NewInt(100), TokenStandard: models.ZnnTokenStandard,
+	}, nil)
+	repos.Account.UpsertBatch(batch, &models.Account{Address: a, BlockCount: 1})
+	repos.Account.AddSendBatch(batch, "b2", a, models.ZnnTokenStandard, 100, 200)
+	repos.Account.BumpTxCountBatch(batch, "b2", a, 200)
+	repos.Account.BumpTxCountBatch(batch, "b2", b, 200)
+
+	repos.AccountBlock.InsertBatch(batch, &models.AccountBlock{
+		Hash: "r3", MomentumHash: "m3", MomentumTimestamp: 300, MomentumHeight: 3,
+		BlockType: models.BlockTypeUserReceive, Height: 1, Address: b,
+		Amount: big.NewInt(100), TokenStandard: models.ZnnTokenStandard, PairedAccountBlock: "b2",
+	}, nil)
+	repos.AccountBlock.UpdatePairedBlockBatch(batch, "b2", "r3")
+	repos.Account.UpsertBatch(batch, &models.Account{Address: b, BlockCount: 1})
+	repos.Account.AddReceiveBatch(batch, "r3", b, models.ZnnTokenStandard, 100, 300)
+	repos.Account.BumpTxCountBatch(batch, "r3", b, 300)
+
+	repos.AccountBlock.InsertBatch(batch, &models.AccountBlock{
+		Hash: "b3", MomentumHash: "m3", MomentumTimestamp: 300, MomentumHeight: 3,
+		BlockType: models.BlockTypeUserSend, Height: 2, Address: a, ToAddress: b,
+		Amount: big.NewInt(40), TokenStandard: models.ZnnTokenStandard,
+	}, nil)
+	repos.Account.UpsertBatch(batch, &models.Account{Address: a, BlockCount: 2})
+	repos.Account.AddSendBatch(batch, "b3", a, models.ZnnTokenStandard, 40, 300)
+	repos.Account.BumpTxCountBatch(batch, "b3", a, 300)
+	repos.Account.BumpTxCountBatch(batch, "b3", b, 300)
+
+	repos.Account.UpdateDelegateBatch(batch, a, p2, 300)
+	repos.Delegation.CloseActiveBatch(batch, a, 300)
+	repos.Delegation.OpenBatch(batch, "r3d", a, p2, 300)
+	repos.Htlc.SettleBatch(batch, "h1", int16(models.HtlcStatusUnlocked), "c0ffee", 3, 300)
+	repos.Htlc.InsertBatch(batch, &models.Htlc{ID: "h2", CreationMomentumHeight: 3, CreationMomentumTimestamp: 300})
+
+	// A held 1000 ZNN before momentum 2; the balance deltas follow the
+	// blocks above.
+	repos.Balance.UpsertBatch(batch, &models.Balance{Address: a, TokenStandard: models.ZnnTokenStandard, Balance: big.NewInt(1000)})
+	repos.Balance.AddDeltaBatch(batch, 2, a, models.ZnnTokenStandard, big.NewInt(-100), 200)
+	repos.Balance.AddDeltaBatch(batch, 3, a, models.ZnnTokenStandard, big.NewInt(-40), 300)
+	repos.Balance.AddDeltaBatch(batch, 3, b, models.ZnnTokenStandard, big.NewInt(100), 300)
+	sendBatch(t, ctx, pool, batch)
+
+	addrs, err := repos.Reorg.AffectedAddresses(ctx, 2)
+	if err != nil {
+		t.Fatalf("affected addresses: %v", err)
+	}
+	if len(addrs) != 2 {
+		t.Errorf("affected addresses = %v, want [A B]", addrs)
+	}
+
+	rb := &pgx.Batch{}
+	repos.Reorg.RollbackAboveBatch(rb, 2, 200)
+	sendBatch(t, ctx, pool, rb)
+
+	if h, _ := repos.Momentum.GetLatestHeight(ctx); h != 2 {
+		t.Errorf("latest height = %d, want 2", h)
+	}
+	var blocks int
+	_ = pool.QueryRow(ctx, `SELECT COUNT(*) FROM account_blocks`).Scan(&blocks)
+	if blocks != 1 {
+		t.Errorf("account_blocks = %d, want 1", blocks)
+	}
+	sent, err := repos.AccountBlock.GetByHash(ctx, "b2")
+	if err != nil {
+		t.Fatalf("get b2: %v", err)
+	}
+	if sent.PairedAccountBlock != "" {
+		t.Errorf("b2 paired_account_block = %q, want cleared", sent.PairedAccountBlock)
+	}
+
+	accA, _ := repos.Account.GetByAddress(ctx, a)
+	if accA.ZnnSent != 100 || accA.TxCount != 1 || accA.BlockCount != 1 {
+		t.Errorf("A counters = sent %d tx %d blocks %d, want 100/1/1", accA.ZnnSent, accA.TxCount, accA.BlockCount)
+	}
+	if accA.LastSeen == nil || *accA.LastSeen != 200 {
+		t.Errorf("A last_seen = %v, want 200", accA.LastSeen)
+	}
+	if accA.Delegate != p1 || accA.DelegationStartTimestamp != 100 {
+		t.Errorf("A delegate = %q@%d, want %q@100", accA.Delegate, accA.DelegationStartTimestamp, p1)
+	}
+	accB, _ := repos.Account.GetByAddress(ctx, b)
+	if accB.ZnnReceived != 0 || accB.TxCount != 1 || accB.FirstActiveAt != nil {
+		t.Errorf("B = received %d tx %d firstActive %v, want 0/1/nil", accB.ZnnReceived, accB.TxCount, accB.FirstActiveAt)
+	}
+
+	for addr, want := range map[string]int64{a: 900, b: 0} {
+		bal, err := repos.Balance.GetByAddressAndToken(ctx, addr, models.ZnnTokenStandard)
+		if err != nil {
+			t.Fatalf("balance of %s: %v", addr, err)
+		}
+		if bal.Balance.Cmp(big.NewInt(want)) != 0 {
+			t.Errorf("balance of %s = %s, want %d", addr, bal.Balance, want)
+		}
+	}
+	var history int
+	_ = pool.QueryRow(ctx, `SELECT COUNT(*) FROM balance_history WHERE momentum_height > 2`).Scan(&history)
+	if history != 0 {
+		t.Errorf("balance_history rows above the ancestor = %d, want 0", history)
+	}
+
+	pillar, err := repos.Delegation.GetActivePillarFor(ctx, a)
+	if err != nil || pillar != p1 {
+		t.Errorf("active delegation = %q (%v), want %q", pillar, err, p1)
+	}
+
+	h1, err := repos.Htlc.GetByID(ctx, "h1")
+	if err != nil {
+		t.Fatalf("get h1: %v", err)
+	}
+	if h1.Status != int16(models.HtlcStatusActive) || h1.Preimage != "" || h1.SettleMomentumHeight != 0 {
+		t.Errorf("h1 not reverted to active: %+v", h1)
+	}
+	if _, err := repos.Htlc.GetByID(ctx, "h2"); err == nil {
+		t.Error("h2 created above the ancestor should be deleted")
+	}
+}
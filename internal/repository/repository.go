@@ -6,54 +6,86 @@ import (
 
 // Repositories holds all repository instances
 type Repositories struct {
-	Momentum     *MomentumRepository
-	Account      *AccountRepository
-	AccountBlock *AccountBlockRepository
-	Balance      *BalanceRepository
-	Token        *TokenRepository
-	TokenEvent   *TokenEventRepository
-	Pillar       *PillarRepository
-	PillarUpdate *PillarUpdateRepository
-	Sentinel     *SentinelRepository
-	Stake        *StakeRepository
-	Htlc         *HtlcRepository
-	Swap         *SwapRepository
-	Fusion       *FusionRepository
-	Project      *ProjectRepository
-	ProjectPhase *ProjectPhaseRepository
-	Vote         *VoteRepository
-	Reward       *RewardRepository
-	Bridge       *BridgeRepository
-	BridgeConfig *BridgeConfigRepository
-	Delegation   *DelegationRepository
-	StatHistory  *StatHistoryRepository
-	SyncStatus   *SyncStatusRepository
+	Momentum      *MomentumRepository
+	Account       *AccountRepository
+	AccountBlock  *AccountBlockRepository
+	Balance       *BalanceRepository
+	Token         *TokenRepository
+	TokenEvent    *TokenEventRepository
+	Pillar        *PillarRepository
+	PillarUpdate  *PillarUpdateRepository
+	Sentinel      *SentinelRepository
+	SentinelEvent *SentinelEventRepository
+	Stake         *StakeRepository
+	Htlc          *HtlcRepository
+	Spork         *SporkRepository
+	Liquidity     *LiquidityRepository
+	Swap          *SwapRepository
+	Fusion        *FusionRepository
+	Project       *ProjectRepository
+	ProjectPhase  *ProjectPhaseRepository
+	Vote          *VoteRepository
+	Accelerator   *AcceleratorRepository
+	Reward        *RewardRepository
+	Bridge        *BridgeRepository
+	BridgeConfig  *BridgeConfigRepository
+	BridgeEvent   *BridgeEventRepository
+	Delegation    *DelegationRepository
+	StatHistory   *StatHistoryRepository
+	SyncStatus    *SyncStatusRepository
+	Reorg         *ReorgRepository
+	// WebhookOutbox is the durable webhook delivery queue.
+	WebhookOutbox *WebhookOutboxRepository
+	// WebhookSubscription holds webhook endpoints registered via the API.
+	WebhookSubscription *WebhookSubscriptionRepository
+	// BackfillCheckpoint records the progress of cmd/backfill runs.
+	BackfillCheckpoint *BackfillCheckpointRepository
+	// FailedHeight queues momentum heights the indexer failed to process.
+	FailedHeight *FailedHeightRepository
+	// Rederive backs cmd/rederive's rebuilds of projections from
+	// account_blocks.
+	Rederive *RederiveRepository
+	// Consistency backs the verifier's node comparisons and
+	// consistency_issues.
+	Consistency *ConsistencyRepository
 }
 
 // NewRepositories creates all repository instances
 func NewRepositories(pool *pgxpool.Pool) *Repositories {
 	return &Repositories{
-		Momentum:     NewMomentumRepository(pool),
-		Account:      NewAccountRepository(pool),
-		AccountBlock: NewAccountBlockRepository(pool),
-		Balance:      NewBalanceRepository(pool),
-		Token:        NewTokenRepository(pool),
-		TokenEvent:   NewTokenEventRepository(pool),
-		Pillar:       NewPillarRepository(pool),
-		PillarUpdate: NewPillarUpdateRepository(pool),
-		Sentinel:     NewSentinelRepository(pool),
-		Stake:        NewStakeRepository(pool),
-		Htlc:         NewHtlcRepository(pool),
-		Swap:         NewSwapRepository(pool),
-		Fusion:       NewFusionRepository(pool),
-		Project:      NewProjectRepository(pool),
-		ProjectPhase: NewProjectPhaseRepository(pool),
-		Vote:         NewVoteRepository(pool),
-		Reward:       NewRewardRepository(pool),
-		Bridge:       NewBridgeRepository(pool),
-		BridgeConfig: NewBridgeConfigRepository(pool),
-		Delegation:   NewDelegationRepository(pool),
-		StatHistory:  NewStatHistoryRepository(pool),
-		SyncStatus:   NewSyncStatusRepository(pool),
+		Momentum:            NewMomentumRepository(pool),
+		Account:             NewAccountRepository(pool),
+		AccountBlock:        NewAccountBlockRepository(pool),
+		Balance:             NewBalanceRepository(pool),
+		Token:               NewTokenRepository(pool),
+		TokenEvent:          NewTokenEventRepository(pool),
+		Pillar:              NewPillarRepository(pool),
+		PillarUpdate:        NewPillarUpdateRepository(pool),
+		Sentinel:            NewSentinelRepository(pool),
+		SentinelEvent:       NewSentinelEventRepository(pool),
+		Stake:               NewStakeRepository(pool),
+		Htlc:                NewHtlcRepository(pool),
+		Spork:               NewSporkRepository(pool),
+		Liquidity:           NewLiquidityRepository(pool),
+		Swap:                NewSwapRepository(pool),
+		Fusion:              NewFusionRepository(pool),
+		Project:             NewProjectRepository(pool),
+		ProjectPhase:        NewProjectPhaseRepository(pool),
+		Vote:                NewVoteRepository(pool),
+		Accelerator:         NewAcceleratorRepository(pool),
+		Reward:              NewRewardRepository(pool),
+		Bridge:              NewBridgeRepository(pool),
+		BridgeConfig:        NewBridgeConfigRepository(pool),
+		BridgeEvent:         NewBridgeEventRepository(pool),
+		Delegation:          NewDelegationRepository(pool),
+		StatHistory:         NewStatHistoryRepository(pool),
+		SyncStatus:          NewSyncStatusRepository(pool),
+		Reorg:               NewReorgRepository(pool),
+		WebhookOutbox:       NewWebhookOutboxRepository(pool),
+		WebhookSubscription: NewWebhookSubscriptionRepository(pool),
+		BackfillCheckpoint:  NewBackfillCheckpointRepository(pool),
+		FailedHeight:        NewFailedHeightRepository(pool),
+		Rederive:            NewRederiveRepository(pool),
+		Consistency:         NewConsistencyRepository(pool),
 	}
 }
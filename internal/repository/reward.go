@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"math/big"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -19,24 +20,38 @@ func NewRewardRepository(pool *pgxpool.Pool) *RewardRepository {
 }
 
 // UpdateCumulativeRewards updates or inserts cumulative rewards
-func (r *RewardRepository) UpdateCumulativeRewards(ctx context.Context, address string, rewardType models.RewardType, amount int64, tokenStandard string) error {
+func (r *RewardRepository) UpdateCumulativeRewards(ctx context.Context, address string, rewardType models.RewardType, amount *big.Int, tokenStandard string) error {
 	_, err := r.pool.Exec(ctx, `
 		INSERT INTO cumulative_rewards (address, reward_type, amount, token_standard)
 		VALUES ($1, $2, $3, $4)
 		ON CONFLICT (address, reward_type, token_standard) DO UPDATE SET
 			amount = cumulative_rewards.amount + $3`,
-		address, int(rewardType), amount, tokenStandard)
+		address, int(rewardType), numeric(amount), tokenStandard)
 	return err
 }
 
 // UpdateCumulativeRewardsBatch adds a cumulative rewards update to a batch
-func (r *RewardRepository) UpdateCumulativeRewardsBatch(batch *pgx.Batch, address string, rewardType models.RewardType, amount int64, tokenStandard string) {
+func (r *RewardRepository) UpdateCumulativeRewardsBatch(batch *pgx.Batch, address string, rewardType models.RewardType, amount *big.Int, tokenStandard string) {
 	batch.Queue(`
 		INSERT INTO cumulative_rewards (address, reward_type, amount, token_standard)
 		VALUES ($1, $2, $3, $4)
 		ON CONFLICT (address, reward_type, token_standard) DO UPDATE SET
 			amount = cumulative_rewards.amount + $3`,
-		address, int(rewardType), amount, tokenStandard)
+		address, int(rewardType), numeric(amount), tokenStandard)
+}
+
+// AddRewardBatch adds rt's amount to its cumulative_rewards row, once
+// per reward receive block (rt.Hash); see blockEffectsPending. The
+// insert branch only runs for a total that does not exist yet, which
+// the block cannot have been counted into.
+func (r *RewardRepository) AddRewardBatch(batch *pgx.Batch, rt *models.RewardTransaction) {
+	batch.Queue(`
+		INSERT INTO cumulative_rewards (address, reward_type, amount, token_standard)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (address, reward_type, token_standard) DO UPDATE SET
+			amount = cumulative_rewards.amount + $3
+		WHERE `+blockEffectsPending("$5"),
+		rt.Address, int(rt.RewardType), numeric(rt.Amount), rt.TokenStandard, rt.Hash)
 }
 
 // InsertRewardTransaction inserts a reward transaction
@@ -47,7 +62,7 @@ func (r *RewardRepository) InsertRewardTransaction(ctx context.Context, rt *mode
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		ON CONFLICT (hash) DO NOTHING`,
 		rt.Hash, rt.Address, int(rt.RewardType), rt.MomentumTimestamp,
-		rt.MomentumHeight, rt.AccountHeight, rt.Amount, rt.TokenStandard, rt.SourceAddress)
+		rt.MomentumHeight, rt.AccountHeight, numeric(rt.Amount), rt.TokenStandard, rt.SourceAddress)
 	return err
 }
 
@@ -59,7 +74,7 @@ func (r *RewardRepository) InsertRewardTransactionBatch(batch *pgx.Batch, rt *mo
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		ON CONFLICT (hash) DO NOTHING`,
 		rt.Hash, rt.Address, int(rt.RewardType), rt.MomentumTimestamp,
-		rt.MomentumHeight, rt.AccountHeight, rt.Amount, rt.TokenStandard, rt.SourceAddress)
+		rt.MomentumHeight, rt.AccountHeight, numeric(rt.Amount), rt.TokenStandard, rt.SourceAddress)
 }
 
 // CumulativeByAddress returns the rolled-up cumulative_rewards rows for
@@ -80,7 +95,7 @@ func (r *RewardRepository) CumulativeByAddress(ctx context.Context, address stri
 	for rows.Next() {
 		var c models.CumulativeReward
 		var rt int
-		if err := rows.Scan(&c.ID, &c.Address, &rt, &c.Amount, &c.TokenStandard); err != nil {
+		if err := rows.Scan(&c.ID, &c.Address, &rt, NumericDest(&c.Amount), &c.TokenStandard); err != nil {
 			return nil, err
 		}
 		c.RewardType = models.RewardType(rt)
@@ -115,7 +130,7 @@ func (r *RewardRepository) HistoryByAddress(ctx context.Context, address string,
 		var rt models.RewardTransaction
 		var rtype int
 		if err := rows.Scan(&rt.Hash, &rt.Address, &rtype, &rt.MomentumTimestamp,
-			&rt.MomentumHeight, &rt.AccountHeight, &rt.Amount, &rt.TokenStandard, &rt.SourceAddress, &total); err != nil {
+			&rt.MomentumHeight, &rt.AccountHeight, NumericDest(&rt.Amount), &rt.TokenStandard, &rt.SourceAddress, &total); err != nil {
 			return nil, 0, err
 		}
 		rt.RewardType = models.RewardType(rtype)
@@ -152,7 +167,7 @@ func (r *RewardRepository) GetByAddress(ctx context.Context, address string) ([]
 		var rt models.RewardTransaction
 		var rewardType int
 		err := rows.Scan(&rt.Hash, &rt.Address, &rewardType, &rt.MomentumTimestamp,
-			&rt.MomentumHeight, &rt.AccountHeight, &rt.Amount, &rt.TokenStandard, &rt.SourceAddress)
+			&rt.MomentumHeight, &rt.AccountHeight, NumericDest(&rt.Amount), &rt.TokenStandard, &rt.SourceAddress)
 		if err != nil {
 			return nil, err
 		}
@@ -17,18 +17,35 @@ func NewSentinelRepository(pool *pgxpool.Pool) *SentinelRepository {
 	return &SentinelRepository{pool: pool}
 }
 
-// Upsert inserts or updates a sentinel
+// Upsert inserts or updates a sentinel from the node's view, which
+// overrides what the ledger-side RegisterBatch and SetInactiveBatch wrote.
 func (r *SentinelRepository) Upsert(ctx context.Context, s *models.Sentinel) error {
 	_, err := r.pool.Exec(ctx, `
 		INSERT INTO sentinels (owner, registration_timestamp, is_revocable, revoke_cooldown, active)
 		VALUES ($1, $2, $3, $4, $5)
 		ON CONFLICT (owner) DO UPDATE SET
+			registration_timestamp = EXCLUDED.registration_timestamp,
 			is_revocable = EXCLUDED.is_revocable,
-			revoke_cooldown = EXCLUDED.revoke_cooldown`,
+			revoke_cooldown = EXCLUDED.revoke_cooldown,
+			active = EXCLUDED.active`,
 		s.Owner, s.RegistrationTimestamp, s.IsRevocable, s.RevokeCooldown, s.Active)
 	return err
 }
 
+// RegisterBatch adds a ledger Register to a batch: the owner's sentinel
+// becomes active as of ts, not yet revocable. The next Upsert replaces
+// ts with the node's registration timestamp and fills the cooldown.
+func (r *SentinelRepository) RegisterBatch(batch *pgx.Batch, owner string, ts int64) {
+	batch.Queue(`
+		INSERT INTO sentinels (owner, registration_timestamp, is_revocable, revoke_cooldown, active)
+		VALUES ($1, $2, false, '', true)
+		ON CONFLICT (owner) DO UPDATE SET
+			registration_timestamp = EXCLUDED.registration_timestamp,
+			is_revocable = false,
+			active = true`,
+		owner, ts)
+}
+
 // SetInactive marks a sentinel as inactive
 func (r *SentinelRepository) SetInactive(ctx context.Context, owner string) error {
 	_, err := r.pool.Exec(ctx, `
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+// SentinelEventRepository manages sentinel_events, the Sentinel
+// contract's accepted calls decoded from its receive blocks.
+type SentinelEventRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewSentinelEventRepository constructs a SentinelEventRepository backed
+// by pool.
+func NewSentinelEventRepository(pool *pgxpool.Pool) *SentinelEventRepository {
+	return &SentinelEventRepository{pool: pool}
+}
+
+const sentinelEventColumns = `account_block_hash, send_block_hash, owner, method,
+	znn_amount, qsr_amount, momentum_height, momentum_timestamp`
+
+// SentinelEventFilter narrows List. Empty fields match everything.
+type SentinelEventFilter struct {
+	Owner  string
+	Method string
+}
+
+// InsertBatch enqueues a SentinelEvent on the per-momentum batch.
+// Idempotent via ON CONFLICT (account_block_hash) DO NOTHING.
+func (r *SentinelEventRepository) InsertBatch(batch *pgx.Batch, e *models.SentinelEvent) {
+	batch.Queue(`
+		INSERT INTO sentinel_events (`+sentinelEventColumns+`)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+		ON CONFLICT (account_block_hash) DO NOTHING`,
+		e.AccountBlockHash, e.SendBlockHash, e.Owner, e.Method,
+		numeric(e.ZnnAmount), numeric(e.QsrAmount), e.MomentumHeight, e.MomentumTimestamp)
+}
+
+// List returns sentinel events newest first, each with the hash of the
+// owner's latest Register at or below its height: the registration it
+// belongs to. That is "" for events before the owner's first
+// registration, such as the QSR deposit that funds it.
+func (r *SentinelEventRepository) List(ctx context.Context, f SentinelEventFilter, opts ListOpts) ([]*models.SentinelEvent, int64, error) {
+	const where = `WHERE ($1 = '' OR e.owner = $1) AND ($2 = '' OR e.method = $2)`
+	rows, err := r.pool.Query(ctx, `
+		SELECT e.account_block_hash, e.send_block_hash, e.owner, e.method,
+			e.znn_amount, e.qsr_amount, e.momentum_height, e.momentum_timestamp,
+			COALESCE((
+				SELECT reg.account_block_hash FROM sentinel_events reg
+				WHERE reg.owner = e.owner AND reg.method = 'Register'
+					AND reg.momentum_height <= e.momentum_height
+				ORDER BY reg.momentum_height DESC LIMIT 1), '') AS registration_block_hash,
+			COUNT(*) OVER () AS total
+		FROM sentinel_events e `+where+`
+		ORDER BY e.momentum_height DESC, e.account_block_hash
+		LIMIT $3 OFFSET $4`, f.Owner, f.Method, opts.Limit, opts.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("SentinelEventRepository.List: %w", err)
+	}
+	defer rows.Close()
+	var (
+		out   []*models.SentinelEvent
+		total int64
+	)
+	for rows.Next() {
+		e := &models.SentinelEvent{}
+		if err := rows.Scan(&e.AccountBlockHash, &e.SendBlockHash, &e.Owner, &e.Method,
+			NumericDest(&e.ZnnAmount), NumericDest(&e.QsrAmount), &e.MomentumHeight, &e.MomentumTimestamp,
+			&e.RegistrationBlockHash, &total); err != nil {
+			return nil, 0, fmt.Errorf("SentinelEventRepository.List: %w", err)
+		}
+		out = append(out, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("SentinelEventRepository.List: %w", err)
+	}
+	if len(out) == 0 && opts.Offset > 0 {
+		if total, err = fallbackCount(ctx, r.pool, `SELECT COUNT(*) FROM sentinel_events e `+where, f.Owner, f.Method); err != nil {
+			return nil, 0, fmt.Errorf("SentinelEventRepository.List: %w", err)
+		}
+	}
+	return out, total, nil
+}
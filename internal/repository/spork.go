@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+// SporkRepository manages sporks, the Spork contract's feature switches.
+type SporkRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewSporkRepository constructs a SporkRepository backed by pool.
+func NewSporkRepository(pool *pgxpool.Pool) *SporkRepository {
+	return &SporkRepository{pool: pool}
+}
+
+const sporkColumns = `id, name, description, creator_address, activated, enforcement_height,
+	creation_momentum_height, creation_momentum_timestamp,
+	activation_momentum_height, activation_momentum_timestamp`
+
+func scanSpork(row pgx.Row, s *models.Spork, extra ...interface{}) error {
+	dst := []interface{}{
+		&s.ID, &s.Name, &s.Description, &s.CreatorAddress, &s.Activated, &s.EnforcementHeight,
+		&s.CreationMomentumHeight, &s.CreationMomentumTimestamp,
+		&s.ActivationMomentumHeight, &s.ActivationMomentumTimestamp,
+	}
+	return row.Scan(append(dst, extra...)...)
+}
+
+// InsertBatch enqueues a CreateSpork on the per-momentum batch.
+// Idempotent via ON CONFLICT (id) DO NOTHING.
+func (r *SporkRepository) InsertBatch(batch *pgx.Batch, s *models.Spork) {
+	batch.Queue(`
+		INSERT INTO sporks (`+sporkColumns+`)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+		ON CONFLICT (id) DO NOTHING`,
+		s.ID, s.Name, s.Description, s.CreatorAddress, s.Activated, s.EnforcementHeight,
+		s.CreationMomentumHeight, s.CreationMomentumTimestamp,
+		s.ActivationMomentumHeight, s.ActivationMomentumTimestamp)
+}
+
+// ActivateBatch enqueues an ActivateSpork on the per-momentum batch. The
+// contract refuses to activate a spork twice, so an already active one
+// keeps its first enforcement height; an unknown id is a no-op.
+func (r *SporkRepository) ActivateBatch(batch *pgx.Batch, id string, enforcementHeight, height, ts int64) {
+	batch.Queue(`
+		UPDATE sporks SET activated = true, enforcement_height = $2,
+			activation_momentum_height = $3, activation_momentum_timestamp = $4
+		WHERE id = $1 AND NOT activated`,
+		id, enforcementHeight, height, ts)
+}
+
+// GetByID returns one spork. A missing id is pgx.ErrNoRows, wrapped.
+func (r *SporkRepository) GetByID(ctx context.Context, id string) (*models.Spork, error) {
+	var s models.Spork
+	if err := scanSpork(r.pool.QueryRow(ctx, `SELECT `+sporkColumns+` FROM sporks WHERE id = $1`, id), &s); err != nil {
+		return nil, fmt.Errorf("SporkRepository.GetByID: %w", err)
+	}
+	return &s, nil
+}
+
+// List returns sporks newest first, only activated ones when
+// activatedOnly is set.
+func (r *SporkRepository) List(ctx context.Context, activatedOnly bool, opts ListOpts) ([]*models.Spork, int64, error) {
+	where := ""
+	if activatedOnly {
+		where = "WHERE activated"
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+sporkColumns+`, COUNT(*) OVER () AS total
+		FROM sporks `+where+`
+		ORDER BY creation_momentum_height DESC, id
+		LIMIT $1 OFFSET $2`, opts.Limit, opts.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("SporkRepository.List: %w", err)
+	}
+	defer rows.Close()
+	var (
+		out   []*models.Spork
+		total int64
+	)
+	for rows.Next() {
+		var s models.Spork
+		if err := scanSpork(rows, &s, &total); err != nil {
+			return nil, 0, fmt.Errorf("SporkRepository.List: %w", err)
+		}
+		out = append(out, &s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("SporkRepository.List: %w", err)
+	}
+	if len(out) == 0 && opts.Offset > 0 {
+		if total, err = fallbackCount(ctx, r.pool, `SELECT COUNT(*) FROM sporks `+where); err != nil {
+			return nil, 0, fmt.Errorf("SporkRepository.List: %w", err)
+		}
+	}
+	return out, total, nil
+}
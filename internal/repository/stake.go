@@ -25,7 +25,7 @@ func (r *StakeRepository) Insert(ctx context.Context, s *models.Stake) error {
 			duration_in_sec, is_active, cancel_id)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		ON CONFLICT (id) DO NOTHING`,
-		s.ID, s.Address, s.StartTimestamp, s.ExpirationTimestamp, s.ZnnAmount,
+		s.ID, s.Address, s.StartTimestamp, s.ExpirationTimestamp, numeric(s.ZnnAmount),
 		s.DurationInSec, s.IsActive, s.CancelID)
 	return err
 }
@@ -37,7 +37,7 @@ func (r *StakeRepository) InsertBatch(batch *pgx.Batch, s *models.Stake) {
 			duration_in_sec, is_active, cancel_id)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		ON CONFLICT (id) DO NOTHING`,
-		s.ID, s.Address, s.StartTimestamp, s.ExpirationTimestamp, s.ZnnAmount,
+		s.ID, s.Address, s.StartTimestamp, s.ExpirationTimestamp, numeric(s.ZnnAmount),
 		s.DurationInSec, s.IsActive, s.CancelID)
 }
 
@@ -82,7 +82,7 @@ func (r *StakeRepository) List(ctx context.Context, activeOnly bool, opts ListOp
 	)
 	for rows.Next() {
 		var s models.Stake
-		if err := rows.Scan(&s.ID, &s.Address, &s.StartTimestamp, &s.ExpirationTimestamp, &s.ZnnAmount,
+		if err := rows.Scan(&s.ID, &s.Address, &s.StartTimestamp, &s.ExpirationTimestamp, NumericDest(&s.ZnnAmount),
 			&s.DurationInSec, &s.IsActive, &s.CancelID, &total); err != nil {
 			return nil, 0, err
 		}
@@ -127,7 +127,7 @@ func (r *StakeRepository) ListByAddress(ctx context.Context, address string, act
 	)
 	for rows.Next() {
 		var s models.Stake
-		if err := rows.Scan(&s.ID, &s.Address, &s.StartTimestamp, &s.ExpirationTimestamp, &s.ZnnAmount,
+		if err := rows.Scan(&s.ID, &s.Address, &s.StartTimestamp, &s.ExpirationTimestamp, NumericDest(&s.ZnnAmount),
 			&s.DurationInSec, &s.IsActive, &s.CancelID, &total); err != nil {
 			return nil, 0, err
 		}
@@ -153,7 +153,7 @@ func (r *StakeRepository) GetByID(ctx context.Context, id string) (*models.Stake
 		SELECT id, address, start_timestamp, expiration_timestamp, znn_amount,
 			duration_in_sec, is_active, cancel_id
 		FROM stakes WHERE id = $1`, id).Scan(
-		&s.ID, &s.Address, &s.StartTimestamp, &s.ExpirationTimestamp, &s.ZnnAmount,
+		&s.ID, &s.Address, &s.StartTimestamp, &s.ExpirationTimestamp, NumericDest(&s.ZnnAmount),
 		&s.DurationInSec, &s.IsActive, &s.CancelID)
 	if err != nil {
 		return nil, err
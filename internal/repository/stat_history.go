@@ -2,7 +2,11 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math/big"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/0x3639/nom-indexer-go/internal/models"
@@ -10,7 +14,8 @@ import (
 
 // StatHistoryRepository owns the four *_stat_histories tables. The cron loop
 // computes a day's row and upserts on (date, key) so re-running mid-day is
-// safe and idempotent.
+// safe and idempotent. It also reads the history the stat backfill
+// rebuilds past days from.
 type StatHistoryRepository struct {
 	pool *pgxpool.Pool
 }
@@ -19,78 +24,533 @@ func NewStatHistoryRepository(pool *pgxpool.Pool) *StatHistoryRepository {
 	return &StatHistoryRepository{pool: pool}
 }
 
-func (r *StatHistoryRepository) UpsertNetworkStat(ctx context.Context, s *models.NetworkStatHistory) error {
-	_, err := r.pool.Exec(ctx, `
-		INSERT INTO network_stat_histories (date, total_tx, daily_tx, total_addresses,
-			daily_addresses, active_addresses, total_tokens, daily_tokens,
-			total_stakes, daily_stakes, total_fusions, daily_fusions,
-			total_pillars, total_sentinels)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
-		ON CONFLICT (date) DO UPDATE SET
-			total_tx = EXCLUDED.total_tx,
-			daily_tx = EXCLUDED.daily_tx,
-			total_addresses = EXCLUDED.total_addresses,
-			daily_addresses = EXCLUDED.daily_addresses,
-			active_addresses = EXCLUDED.active_addresses,
-			total_tokens = EXCLUDED.total_tokens,
-			daily_tokens = EXCLUDED.daily_tokens,
-			total_stakes = EXCLUDED.total_stakes,
-			daily_stakes = EXCLUDED.daily_stakes,
-			total_fusions = EXCLUDED.total_fusions,
-			daily_fusions = EXCLUDED.daily_fusions,
-			total_pillars = EXCLUDED.total_pillars,
-			total_sentinels = EXCLUDED.total_sentinels`,
-		s.Date, s.TotalTx, s.DailyTx, s.TotalAddresses,
+const upsertNetworkStatSQL = `
+	INSERT INTO network_stat_histories (date, total_tx, daily_tx, total_addresses,
+		daily_addresses, active_addresses, total_tokens, daily_tokens,
+		total_stakes, daily_stakes, total_fusions, daily_fusions,
+		total_pillars, total_sentinels)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	ON CONFLICT (date) DO UPDATE SET
+		total_tx = EXCLUDED.total_tx,
+		daily_tx = EXCLUDED.daily_tx,
+		total_addresses = EXCLUDED.total_addresses,
+		daily_addresses = EXCLUDED.daily_addresses,
+		active_addresses = EXCLUDED.active_addresses,
+		total_tokens = EXCLUDED.total_tokens,
+		daily_tokens = EXCLUDED.daily_tokens,
+		total_stakes = EXCLUDED.total_stakes,
+		daily_stakes = EXCLUDED.daily_stakes,
+		total_fusions = EXCLUDED.total_fusions,
+		daily_fusions = EXCLUDED.daily_fusions,
+		total_pillars = EXCLUDED.total_pillars,
+		total_sentinels = EXCLUDED.total_sentinels`
+
+func networkStatArgs(s *models.NetworkStatHistory) []any {
+	return []any{s.Date, s.TotalTx, s.DailyTx, s.TotalAddresses,
 		s.DailyAddresses, s.ActiveAddresses, s.TotalTokens, s.DailyTokens,
 		s.TotalStakes, s.DailyStakes, s.TotalFusions, s.DailyFusions,
-		s.TotalPillars, s.TotalSentinels)
+		s.TotalPillars, s.TotalSentinels}
+}
+
+func (r *StatHistoryRepository) UpsertNetworkStat(ctx context.Context, s *models.NetworkStatHistory) error {
+	_, err := r.pool.Exec(ctx, upsertNetworkStatSQL, networkStatArgs(s)...)
 	return err
 }
 
+const upsertTokenStatSQL = `
+	INSERT INTO token_stat_histories (date, token_standard, daily_minted, daily_burned,
+		total_supply, total_holders, total_transactions)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	ON CONFLICT (date, token_standard) DO UPDATE SET
+		daily_minted = EXCLUDED.daily_minted,
+		daily_burned = EXCLUDED.daily_burned,
+		total_supply = EXCLUDED.total_supply,
+		total_holders = EXCLUDED.total_holders,
+		total_transactions = EXCLUDED.total_transactions`
+
+func tokenStatArgs(s *models.TokenStatHistory) []any {
+	return []any{s.Date, s.TokenStandard, numeric(s.DailyMinted), numeric(s.DailyBurned),
+		numeric(s.TotalSupply), s.TotalHolders, s.TotalTransactions}
+}
+
 func (r *StatHistoryRepository) UpsertTokenStat(ctx context.Context, s *models.TokenStatHistory) error {
-	_, err := r.pool.Exec(ctx, `
-		INSERT INTO token_stat_histories (date, token_standard, daily_minted, daily_burned,
-			total_supply, total_holders, total_transactions)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		ON CONFLICT (date, token_standard) DO UPDATE SET
-			daily_minted = EXCLUDED.daily_minted,
-			daily_burned = EXCLUDED.daily_burned,
-			total_supply = EXCLUDED.total_supply,
-			total_holders = EXCLUDED.total_holders,
-			total_transactions = EXCLUDED.total_transactions`,
-		s.Date, s.TokenStandard, s.DailyMinted, s.DailyBurned,
-		s.TotalSupply, s.TotalHolders, s.TotalTransactions)
+	_, err := r.pool.Exec(ctx, upsertTokenStatSQL, tokenStatArgs(s)...)
 	return err
 }
 
+const upsertPillarStatSQL = `
+	INSERT INTO pillar_stat_histories (date, pillar_owner_address, rank, weight,
+		momentum_rewards, delegate_rewards, total_delegators)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	ON CONFLICT (date, pillar_owner_address) DO UPDATE SET
+		rank = EXCLUDED.rank,
+		weight = EXCLUDED.weight,
+		momentum_rewards = EXCLUDED.momentum_rewards,
+		delegate_rewards = EXCLUDED.delegate_rewards,
+		total_delegators = EXCLUDED.total_delegators`
+
+func pillarStatArgs(s *models.PillarStatHistory) []any {
+	return []any{s.Date, s.PillarOwnerAddress, s.Rank, s.Weight,
+		s.MomentumRewards, s.DelegateRewards, s.TotalDelegators}
+}
+
 func (r *StatHistoryRepository) UpsertPillarStat(ctx context.Context, s *models.PillarStatHistory) error {
-	_, err := r.pool.Exec(ctx, `
-		INSERT INTO pillar_stat_histories (date, pillar_owner_address, rank, weight,
-			momentum_rewards, delegate_rewards, total_delegators)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		ON CONFLICT (date, pillar_owner_address) DO UPDATE SET
-			rank = EXCLUDED.rank,
-			weight = EXCLUDED.weight,
-			momentum_rewards = EXCLUDED.momentum_rewards,
-			delegate_rewards = EXCLUDED.delegate_rewards,
-			total_delegators = EXCLUDED.total_delegators`,
-		s.Date, s.PillarOwnerAddress, s.Rank, s.Weight,
-		s.MomentumRewards, s.DelegateRewards, s.TotalDelegators)
+	_, err := r.pool.Exec(ctx, upsertPillarStatSQL, pillarStatArgs(s)...)
 	return err
 }
 
+const upsertBridgeStatSQL = `
+	INSERT INTO bridge_stat_histories (date, network_class, chain_id, token_standard,
+		wrap_tx_count, wrapped_amount, unwrap_tx_count, unwrapped_amount, total_volume)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	ON CONFLICT (date, network_class, chain_id, token_standard) DO UPDATE SET
+		wrap_tx_count = EXCLUDED.wrap_tx_count,
+		wrapped_amount = EXCLUDED.wrapped_amount,
+		unwrap_tx_count = EXCLUDED.unwrap_tx_count,
+		unwrapped_amount = EXCLUDED.unwrapped_amount,
+		total_volume = EXCLUDED.total_volume`
+
+func bridgeStatArgs(s *models.BridgeStatHistory) []any {
+	return []any{s.Date, s.NetworkClass, s.ChainID, s.TokenStandard,
+		s.WrapTxCount, numeric(s.WrappedAmount), s.UnwrapTxCount, numeric(s.UnwrappedAmount), numeric(s.TotalVolume)}
+}
+
 func (r *StatHistoryRepository) UpsertBridgeStat(ctx context.Context, s *models.BridgeStatHistory) error {
-	_, err := r.pool.Exec(ctx, `
-		INSERT INTO bridge_stat_histories (date, network_class, chain_id, token_standard,
-			wrap_tx_count, wrapped_amount, unwrap_tx_count, unwrapped_amount, total_volume)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-		ON CONFLICT (date, network_class, chain_id, token_standard) DO UPDATE SET
-			wrap_tx_count = EXCLUDED.wrap_tx_count,
-			wrapped_amount = EXCLUDED.wrapped_amount,
-			unwrap_tx_count = EXCLUDED.unwrap_tx_count,
-			unwrapped_amount = EXCLUDED.unwrapped_amount,
-			total_volume = EXCLUDED.total_volume`,
-		s.Date, s.NetworkClass, s.ChainID, s.TokenStandard,
-		s.WrapTxCount, s.WrappedAmount, s.UnwrapTxCount, s.UnwrappedAmount, s.TotalVolume)
+	_, err := r.pool.Exec(ctx, upsertBridgeStatSQL, bridgeStatArgs(s)...)
 	return err
 }
+
+// StatDay is every stat-history row of one date.
+type StatDay struct {
+	Date    string
+	Network *models.NetworkStatHistory
+	Tokens  []*models.TokenStatHistory
+	Pillars []*models.PillarStatHistory
+	Bridges []*models.BridgeStatHistory
+}
+
+// ReplaceDay replaces the rows of d.Date in all four tables with d's, in
+// one transaction, so keys d no longer has (a pillar that was not yet
+// active, say) do not linger from an earlier run.
+func (r *StatHistoryRepository) ReplaceDay(ctx context.Context, d *StatDay) error {
+	batch := &pgx.Batch{}
+	for _, table := range []string{"network_stat_histories", "token_stat_histories",
+		"pillar_stat_histories", "bridge_stat_histories"} {
+		batch.Queue(`DELETE FROM `+table+` WHERE date = $1`, d.Date)
+	}
+	if d.Network != nil {
+		batch.Queue(upsertNetworkStatSQL, networkStatArgs(d.Network)...)
+	}
+	for _, s := range d.Tokens {
+		batch.Queue(upsertTokenStatSQL, tokenStatArgs(s)...)
+	}
+	for _, s := range d.Pillars {
+		batch.Queue(upsertPillarStatSQL, pillarStatArgs(s)...)
+	}
+	for _, s := range d.Bridges {
+		batch.Queue(upsertBridgeStatSQL, bridgeStatArgs(s)...)
+	}
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		return tx.SendBatch(ctx, batch).Close()
+	})
+	if err != nil {
+		return fmt.Errorf("StatHistoryRepository.ReplaceDay: %w", err)
+	}
+	return nil
+}
+
+// LatestDate returns the most recent date with a network_stat_histories
+// row, or "" when there is none.
+func (r *StatHistoryRepository) LatestDate(ctx context.Context) (string, error) {
+	var date *string
+	if err := r.pool.QueryRow(ctx, `
+		SELECT to_char(MAX(date), 'YYYY-MM-DD') FROM network_stat_histories`).Scan(&date); err != nil {
+		return "", fmt.Errorf("StatHistoryRepository.LatestDate: %w", err)
+	}
+	if date == nil {
+		return "", nil
+	}
+	return *date, nil
+}
+
+// NetworkStatAsOf computes the network row of date as it stood at endTs,
+// the end of the day starting at startTs: totals count what existed
+// before endTs, daily values what happened in between. total_tx,
+// daily_tx, total_tokens and daily_tokens are left zero; the stat
+// backfill carries them from its ledger.
+func (r *StatHistoryRepository) NetworkStatAsOf(ctx context.Context, date string, startTs, endTs int64) (*models.NetworkStatHistory, error) {
+	s := &models.NetworkStatHistory{Date: date}
+	err := r.pool.QueryRow(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM accounts WHERE first_seen < $2)::bigint,
+			(SELECT COUNT(*) FROM accounts
+				WHERE first_active_at >= $1 AND first_active_at < $2)::bigint,
+			(SELECT COUNT(DISTINCT address) FROM account_blocks
+				WHERE momentum_height BETWEEN
+					(SELECT MIN(height) FROM momentums WHERE timestamp >= $1 AND timestamp < $2)
+					AND (SELECT MAX(height) FROM momentums WHERE timestamp >= $1 AND timestamp < $2))::bigint,
+			(SELECT COUNT(*) FROM stakes WHERE start_timestamp < $2)::bigint,
+			(SELECT COUNT(*) FROM stakes
+				WHERE start_timestamp >= $1 AND start_timestamp < $2)::bigint,
+			(SELECT COUNT(*) FROM fusions WHERE momentum_timestamp < $2)::bigint,
+			(SELECT COUNT(*) FROM fusions
+				WHERE momentum_timestamp >= $1 AND momentum_timestamp < $2)::bigint,
+			(SELECT COUNT(*) FROM pillars
+				WHERE spawn_timestamp < $2 AND (NOT is_revoked OR revoke_timestamp >= $2))::bigint,
+			(SELECT COUNT(*) FROM sentinels s
+				WHERE s.registration_timestamp < $2 AND (s.active OR EXISTS (
+					SELECT 1 FROM account_blocks ab
+					WHERE ab.address = s.owner AND ab.to_address = $3
+						AND ab.method = 'Revoke' AND ab.momentum_timestamp >= $2)))::bigint`,
+		startTs, endTs, models.SentinelAddress).Scan(
+		&s.TotalAddresses, &s.DailyAddresses, &s.ActiveAddresses,
+		&s.TotalStakes, &s.DailyStakes, &s.TotalFusions, &s.DailyFusions,
+		&s.TotalPillars, &s.TotalSentinels)
+	if err != nil {
+		return nil, fmt.Errorf("StatHistoryRepository.NetworkStatAsOf: %w", err)
+	}
+	return s, nil
+}
+
+// BridgeStatsForDay aggregates the wrap and unwrap requests of the
+// momentums in [startTs, endTs) into one row per (network_class,
+// chain_id, token_standard) with activity.
+func (r *StatHistoryRepository) BridgeStatsForDay(ctx context.Context, date string, startTs, endTs int64) ([]*models.BridgeStatHistory, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT network_class, chain_id, token_standard,
+			COUNT(*)::bigint AS wrap_tx,
+			COALESCE(SUM(amount), 0) AS wrapped_amount
+		FROM wrap_token_requests
+		WHERE creation_momentum_height IN (
+			SELECT height FROM momentums WHERE timestamp >= $1 AND timestamp < $2
+		)
+		GROUP BY network_class, chain_id, token_standard`,
+		startTs, endTs)
+	if err != nil {
+		return nil, fmt.Errorf("StatHistoryRepository.BridgeStatsForDay: wraps: %w", err)
+	}
+	var out []*models.BridgeStatHistory
+	stats := map[string]*models.BridgeStatHistory{}
+	for rows.Next() {
+		s := &models.BridgeStatHistory{Date: date}
+		if err := rows.Scan(&s.NetworkClass, &s.ChainID, &s.TokenStandard,
+			&s.WrapTxCount, NumericDest(&s.WrappedAmount)); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("StatHistoryRepository.BridgeStatsForDay: wraps: %w", err)
+		}
+		s.UnwrappedAmount = new(big.Int)
+		s.TotalVolume = new(big.Int).Set(s.WrappedAmount)
+		stats[fmt.Sprintf("%d:%d:%s", s.NetworkClass, s.ChainID, s.TokenStandard)] = s
+		out = append(out, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("StatHistoryRepository.BridgeStatsForDay: wraps: %w", err)
+	}
+
+	rows, err = r.pool.Query(ctx, `
+		SELECT network_class, chain_id, token_standard,
+			COUNT(*)::bigint AS unwrap_tx,
+			COALESCE(SUM(amount), 0) AS unwrapped_amount
+		FROM unwrap_token_requests
+		WHERE registration_momentum_height IN (
+			SELECT height FROM momentums WHERE timestamp >= $1 AND timestamp < $2
+		)
+		GROUP BY network_class, chain_id, token_standard`,
+		startTs, endTs)
+	if err != nil {
+		return nil, fmt.Errorf("StatHistoryRepository.BridgeStatsForDay: unwraps: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			nc, ci int
+			ts     string
+			tx     int64
+			amount *big.Int
+		)
+		if err := rows.Scan(&nc, &ci, &ts, &tx, NumericDest(&amount)); err != nil {
+			return nil, fmt.Errorf("StatHistoryRepository.BridgeStatsForDay: unwraps: %w", err)
+		}
+		key := fmt.Sprintf("%d:%d:%s", nc, ci, ts)
+		s := stats[key]
+		if s == nil {
+			s = &models.BridgeStatHistory{
+				Date: date, NetworkClass: nc, ChainID: ci, TokenStandard: ts,
+				WrappedAmount: new(big.Int), TotalVolume: new(big.Int),
+			}
+			stats[key] = s
+			out = append(out, s)
+		}
+		s.UnwrapTxCount = tx
+		s.UnwrappedAmount = amount
+		s.TotalVolume.Add(s.TotalVolume, amount)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("StatHistoryRepository.BridgeStatsForDay: unwraps: %w", err)
+	}
+	return out, nil
+}
+
+// StatLedger is the state the stat backfill rewinds from, read in one
+// snapshot: everything below is as of momentum Height.
+type StatLedger struct {
+	Height           uint64
+	HeadTimestamp    int64
+	GenesisTimestamp int64
+	// TotalTx is the summed tx_count of every momentum.
+	TotalTx int64
+	// Balances holds every non-zero balance.
+	Balances []*models.Balance
+	Tokens   []*StatToken
+}
+
+// StatToken is one token's running totals in a StatLedger.
+type StatToken struct {
+	TokenStandard string
+	TotalSupply   *big.Int
+	// Transactions counts the account blocks carrying the token.
+	Transactions int64
+	// FirstSeen is the momentum timestamp of the first of them, 0 when
+	// there is none.
+	FirstSeen int64
+}
+
+// LedgerAt reads a StatLedger at the highest indexed momentum, in one
+// repeatable-read transaction so the balances and counts agree with the
+// height. Height is 0 when no momentum is indexed.
+func (r *StatHistoryRepository) LedgerAt(ctx context.Context) (*StatLedger, error) {
+	l := &StatLedger{}
+	err := pgx.BeginTxFunc(ctx, r.pool, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, `
+			SELECT height, timestamp,
+				(SELECT timestamp FROM momentums ORDER BY height LIMIT 1),
+				(SELECT COALESCE(SUM(tx_count), 0)::bigint FROM momentums)
+			FROM momentums ORDER BY height DESC LIMIT 1`).Scan(
+			&l.Height, &l.HeadTimestamp, &l.GenesisTimestamp, &l.TotalTx)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		rows, err := tx.Query(ctx, `
+			SELECT address, token_standard, balance FROM balances WHERE balance <> 0`)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			b := &models.Balance{}
+			if err := rows.Scan(&b.Address, &b.TokenStandard, NumericDest(&b.Balance)); err != nil {
+				rows.Close()
+				return err
+			}
+			l.Balances = append(l.Balances, b)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		rows, err = tx.Query(ctx, `
+			SELECT t.token_standard, t.total_supply,
+				COALESCE(ab.n, 0)::bigint, COALESCE(ab.first_seen, 0)
+			FROM tokens t
+			LEFT JOIN (
+				SELECT token_standard, COUNT(*) AS n, MIN(momentum_timestamp) AS first_seen
+				FROM account_blocks GROUP BY token_standard
+			) ab ON ab.token_standard = t.token_standard
+			ORDER BY t.token_standard`)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			t := &StatToken{}
+			if err := rows.Scan(&t.TokenStandard, NumericDest(&t.TotalSupply), &t.Transactions, &t.FirstSeen); err != nil {
+				return err
+			}
+			l.Tokens = append(l.Tokens, t)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("StatHistoryRepository.LedgerAt: %w", err)
+	}
+	return l, nil
+}
+
+// HeightsBetween returns the first and last momentum height with a
+// timestamp in [startTs, endTs); ok is false when there is none.
+func (r *StatHistoryRepository) HeightsBetween(ctx context.Context, startTs, endTs int64) (from, to uint64, ok bool, err error) {
+	var lo, hi *uint64
+	if err := r.pool.QueryRow(ctx, `
+		SELECT MIN(height), MAX(height) FROM momentums
+		WHERE timestamp >= $1 AND timestamp < $2`, startTs, endTs).Scan(&lo, &hi); err != nil {
+		return 0, 0, false, fmt.Errorf("StatHistoryRepository.HeightsBetween: %w", err)
+	}
+	if lo == nil || hi == nil {
+		return 0, 0, false, nil
+	}
+	return *lo, *hi, true, nil
+}
+
+// BalanceChange is the net amount an address gained (negative: lost) in
+// one token over a height range.
+type BalanceChange struct {
+	Address       string
+	TokenStandard string
+	Amount        *big.Int
+}
+
+// BalanceChanges sums the account blocks of heights from through to into
+// one BalanceChange per address and token: receives add their amount,
+// sends subtract it.
+func (r *StatHistoryRepository) BalanceChanges(ctx context.Context, from, to uint64) ([]*BalanceChange, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT address, token_standard,
+			SUM(CASE WHEN block_type IN ($3, $4) THEN -amount ELSE amount END)
+		FROM account_blocks
+		WHERE momentum_height BETWEEN $1 AND $2 AND amount > 0
+		GROUP BY address, token_standard`,
+		from, to, models.BlockTypeUserSend, models.BlockTypeContractSend)
+	if err != nil {
+		return nil, fmt.Errorf("StatHistoryRepository.BalanceChanges: %w", err)
+	}
+	defer rows.Close()
+	var out []*BalanceChange
+	for rows.Next() {
+		c := &BalanceChange{}
+		if err := rows.Scan(&c.Address, &c.TokenStandard, NumericDest(&c.Amount)); err != nil {
+			return nil, fmt.Errorf("StatHistoryRepository.BalanceChanges: %w", err)
+		}
+		out = append(out, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("StatHistoryRepository.BalanceChanges: %w", err)
+	}
+	return out, nil
+}
+
+// BlockCounts returns, for heights from through to, the momentums'
+// summed tx_count and the number of account blocks per token standard.
+func (r *StatHistoryRepository) BlockCounts(ctx context.Context, from, to uint64) (txCount int64, byToken map[string]int64, err error) {
+	if err := r.pool.QueryRow(ctx, `
+		SELECT COALESCE(SUM(tx_count), 0)::bigint FROM momentums
+		WHERE height BETWEEN $1 AND $2`, from, to).Scan(&txCount); err != nil {
+		return 0, nil, fmt.Errorf("StatHistoryRepository.BlockCounts: %w", err)
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT token_standard, COUNT(*) FROM account_blocks
+		WHERE momentum_height BETWEEN $1 AND $2
+		GROUP BY token_standard`, from, to)
+	if err != nil {
+		return 0, nil, fmt.Errorf("StatHistoryRepository.BlockCounts: %w", err)
+	}
+	defer rows.Close()
+	byToken = map[string]int64{}
+	for rows.Next() {
+		var (
+			zts string
+			n   int64
+		)
+		if err := rows.Scan(&zts, &n); err != nil {
+			return 0, nil, fmt.Errorf("StatHistoryRepository.BlockCounts: %w", err)
+		}
+		byToken[zts] = n
+	}
+	if err := rows.Err(); err != nil {
+		return 0, nil, fmt.Errorf("StatHistoryRepository.BlockCounts: %w", err)
+	}
+	return txCount, byToken, nil
+}
+
+// MintsBurns sums token_mints and token_burns per token standard over
+// heights from through to.
+func (r *StatHistoryRepository) MintsBurns(ctx context.Context, from, to uint64) (mints, burns map[string]*big.Int, err error) {
+	mints, burns = map[string]*big.Int{}, map[string]*big.Int{}
+	for _, q := range []struct {
+		table string
+		into  map[string]*big.Int
+	}{{"token_mints", mints}, {"token_burns", burns}} {
+		rows, err := r.pool.Query(ctx, `
+			SELECT token_standard, SUM(amount) FROM `+q.table+`
+			WHERE momentum_height BETWEEN $1 AND $2
+			GROUP BY token_standard`, from, to)
+		if err != nil {
+			return nil, nil, fmt.Errorf("StatHistoryRepository.MintsBurns: %w", err)
+		}
+		for rows.Next() {
+			var (
+				zts string
+				sum *big.Int
+			)
+			if err := rows.Scan(&zts, NumericDest(&sum)); err != nil {
+				rows.Close()
+				return nil, nil, fmt.Errorf("StatHistoryRepository.MintsBurns: %w", err)
+			}
+			q.into[zts] = sum
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, nil, fmt.Errorf("StatHistoryRepository.MintsBurns: %w", err)
+		}
+	}
+	return mints, burns, nil
+}
+
+// PillarSpan is when a pillar was active: from SpawnedAt (0 when it
+// predates spawn tracking) until RevokedAt (0 while it is active).
+type PillarSpan struct {
+	OwnerAddress string
+	SpawnedAt    int64
+	RevokedAt    int64
+}
+
+// PillarSpans returns the span of every pillar, revoked ones included.
+func (r *StatHistoryRepository) PillarSpans(ctx context.Context) ([]*PillarSpan, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT owner_address, spawn_timestamp,
+			CASE WHEN is_revoked THEN revoke_timestamp ELSE 0 END
+		FROM pillars ORDER BY owner_address`)
+	if err != nil {
+		return nil, fmt.Errorf("StatHistoryRepository.PillarSpans: %w", err)
+	}
+	defer rows.Close()
+	var out []*PillarSpan
+	for rows.Next() {
+		p := &PillarSpan{}
+		if err := rows.Scan(&p.OwnerAddress, &p.SpawnedAt, &p.RevokedAt); err != nil {
+			return nil, fmt.Errorf("StatHistoryRepository.PillarSpans: %w", err)
+		}
+		out = append(out, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("StatHistoryRepository.PillarSpans: %w", err)
+	}
+	return out, nil
+}
+
+// Delegations returns every delegation interval, open or closed.
+func (r *StatHistoryRepository) Delegations(ctx context.Context) ([]*models.Delegation, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, delegator_address, pillar_owner_address, started_at, ended_at
+		FROM delegations ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("StatHistoryRepository.Delegations: %w", err)
+	}
+	defer rows.Close()
+	var out []*models.Delegation
+	for rows.Next() {
+		d := &models.Delegation{}
+		if err := rows.Scan(&d.ID, &d.DelegatorAddress, &d.PillarOwnerAddress, &d.StartedAt, &d.EndedAt); err != nil {
+			return nil, fmt.Errorf("StatHistoryRepository.Delegations: %w", err)
+		}
+		out = append(out, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("StatHistoryRepository.Delegations: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,133 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+func TestIntegration_StatHistory_ReplaceDayDropsStaleRows(t *testing.T) {
+	pool := newTestDB(t)
+	ctx := context.Background()
+	repo := NewStatHistoryRepository(pool)
+
+	const date = "2023-01-02"
+	if err := repo.UpsertPillarStat(ctx, &models.PillarStatHistory{Date: date, PillarOwnerAddress: "z1qold"}); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	if err := repo.UpsertPillarStat(ctx, &models.PillarStatHistory{Date: "2023-01-03", PillarOwnerAddress: "z1qold"}); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	err := repo.ReplaceDay(ctx, &StatDay{
+		Date:    date,
+		Network: &models.NetworkStatHistory{Date: date, TotalTx: 3},
+		Tokens: []*models.TokenStatHistory{{Date: date, TokenStandard: models.ZnnTokenStandard,
+			DailyMinted: big.NewInt(0), DailyBurned: big.NewInt(0), TotalSupply: big.NewInt(10)}},
+		Pillars: []*models.PillarStatHistory{{Date: date, PillarOwnerAddress: "z1qnew", Weight: 5}},
+	})
+	if err != nil {
+		t.Fatalf("ReplaceDay: %v", err)
+	}
+
+	var owners []string
+	rows, err := pool.Query(ctx, `SELECT pillar_owner_address FROM pillar_stat_histories WHERE date = $1`, date)
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	for rows.Next() {
+		var o string
+		_ = rows.Scan(&o)
+		owners = append(owners, o)
+	}
+	rows.Close()
+	if len(owners) != 1 || owners[0] != "z1qnew" {
+		t.Errorf("pillar rows of %s = %v, want only z1qnew", date, owners)
+	}
+	var others int
+	_ = pool.QueryRow(ctx, `SELECT COUNT(*) FROM pillar_stat_histories WHERE date <> $1`, date).Scan(&others)
+	if others != 1 {
+		t.Errorf("rows of other dates = %d, want 1 (left alone)", others)
+	}
+
+	latest, err := repo.LatestDate(ctx)
+	if err != nil || latest != date {
+		t.Errorf("LatestDate = %q, %v; want %q", latest, err, date)
+	}
+}
+
+func TestIntegration_StatHistory_LedgerAndChanges(t *testing.T) {
+	pool := newTestDB(t)
+	ctx := context.Background()
+	repos := NewRepositories(pool)
+	repo := repos.StatHistory
+
+	if l, err := repo.LedgerAt(ctx); err != nil || l.Height != 0 {
+		t.Fatalf("LedgerAt on empty db = %+v, %v; want height 0", l, err)
+	}
+	if date, err := repo.LatestDate(ctx); err != nil || date != "" {
+		t.Fatalf("LatestDate on empty db = %q, %v", date, err)
+	}
+
+	batch := &pgx.Batch{}
+	for h := uint64(1); h <= 3; h++ {
+		repos.Momentum.InsertBatch(ctx, batch, &models.Momentum{
+			Height: h, Hash: fmt.Sprintf("m%d", h), Timestamp: int64(h) * 100, TxCount: 1,
+		})
+	}
+	repos.AccountBlock.InsertBatch(batch, &models.AccountBlock{
+		Hash: "s1", MomentumHash: "m2", MomentumTimestamp: 200, MomentumHeight: 2,
+		BlockType: models.BlockTypeUserSend, Height: 1, Address: "z1qa", ToAddress: "z1qb",
+		Amount: big.NewInt(40), TokenStandard: models.ZnnTokenStandard,
+	}, nil)
+	repos.AccountBlock.InsertBatch(batch, &models.AccountBlock{
+		Hash: "r1", MomentumHash: "m3", MomentumTimestamp: 300, MomentumHeight: 3,
+		BlockType: models.BlockTypeUserReceive, Height: 1, Address: "z1qb",
+		Amount: big.NewInt(40), TokenStandard: models.ZnnTokenStandard,
+	}, nil)
+	repos.Token.UpsertBatch(batch, &models.Token{TokenStandard: models.ZnnTokenStandard, Name: "ZNN", Symbol: "ZNN",
+		TotalSupply: big.NewInt(1000), MaxSupply: big.NewInt(1000)})
+	sendBatch(t, ctx, pool, batch)
+
+	l, err := repo.LedgerAt(ctx)
+	if err != nil {
+		t.Fatalf("LedgerAt: %v", err)
+	}
+	if l.Height != 3 || l.HeadTimestamp != 300 || l.GenesisTimestamp != 100 || l.TotalTx != 3 {
+		t.Errorf("ledger head = %+v", l)
+	}
+	if len(l.Tokens) != 1 || l.Tokens[0].Transactions != 2 || l.Tokens[0].FirstSeen != 200 {
+		t.Errorf("ledger tokens = %+v", l.Tokens)
+	}
+
+	from, to, ok, err := repo.HeightsBetween(ctx, 150, 301)
+	if err != nil || !ok || from != 2 || to != 3 {
+		t.Errorf("HeightsBetween = %d, %d, %v, %v; want 2, 3", from, to, ok, err)
+	}
+	if _, _, ok, _ := repo.HeightsBetween(ctx, 400, 500); ok {
+		t.Error("HeightsBetween past the head: want ok false")
+	}
+
+	changes, err := repo.BalanceChanges(ctx, 2, 3)
+	if err != nil {
+		t.Fatalf("BalanceChanges: %v", err)
+	}
+	got := map[string]int64{}
+	for _, c := range changes {
+		got[c.Address] = c.Amount.Int64()
+	}
+	if len(got) != 2 || got["z1qa"] != -40 || got["z1qb"] != 40 {
+		t.Errorf("BalanceChanges = %v, want z1qa -40, z1qb +40", got)
+	}
+
+	txs, byToken, err := repo.BlockCounts(ctx, 3, 3)
+	if err != nil || txs != 1 || byToken[models.ZnnTokenStandard] != 1 {
+		t.Errorf("BlockCounts(3, 3) = %d, %v, %v", txs, byToken, err)
+	}
+}
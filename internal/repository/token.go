@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"math/big"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -29,7 +30,7 @@ func (r *TokenRepository) Upsert(ctx context.Context, t *models.Token) error {
 			total_supply = EXCLUDED.total_supply,
 			max_supply = EXCLUDED.max_supply`,
 		t.TokenStandard, t.Name, t.Symbol, t.Domain, t.Decimals, t.Owner,
-		t.TotalSupply, t.MaxSupply, t.IsBurnable, t.IsMintable, t.IsUtility)
+		numeric(t.TotalSupply), numeric(t.MaxSupply), t.IsBurnable, t.IsMintable, t.IsUtility)
 	return err
 }
 
@@ -44,24 +45,33 @@ func (r *TokenRepository) UpsertBatch(batch *pgx.Batch, t *models.Token) {
 			total_supply = EXCLUDED.total_supply,
 			max_supply = EXCLUDED.max_supply`,
 		t.TokenStandard, t.Name, t.Symbol, t.Domain, t.Decimals, t.Owner,
-		t.TotalSupply, t.MaxSupply, t.IsBurnable, t.IsMintable, t.IsUtility)
+		numeric(t.TotalSupply), numeric(t.MaxSupply), t.IsBurnable, t.IsMintable, t.IsUtility)
 }
 
 // UpdateBurnAmount increments the total burned amount
-func (r *TokenRepository) UpdateBurnAmount(ctx context.Context, tokenStandard string, burnAmount int64) error {
+func (r *TokenRepository) UpdateBurnAmount(ctx context.Context, tokenStandard string, burnAmount *big.Int) error {
 	_, err := r.pool.Exec(ctx, `
 		UPDATE tokens SET total_burned = total_burned + $2
 		WHERE token_standard = $1`,
-		tokenStandard, burnAmount)
+		tokenStandard, numeric(burnAmount))
 	return err
 }
 
 // UpdateBurnAmountBatch adds a burn amount update to a batch
-func (r *TokenRepository) UpdateBurnAmountBatch(batch *pgx.Batch, tokenStandard string, burnAmount int64) {
+func (r *TokenRepository) UpdateBurnAmountBatch(batch *pgx.Batch, tokenStandard string, burnAmount *big.Int) {
 	batch.Queue(`
 		UPDATE tokens SET total_burned = total_burned + $2
 		WHERE token_standard = $1`,
-		tokenStandard, burnAmount)
+		tokenStandard, numeric(burnAmount))
+}
+
+// AddBlockBurnBatch adds the burn recorded by the account block hashed
+// blockHash to the token's total, once; see blockEffectsPending.
+func (r *TokenRepository) AddBlockBurnBatch(batch *pgx.Batch, blockHash, tokenStandard string, burnAmount *big.Int) {
+	batch.Queue(`
+		UPDATE tokens SET total_burned = total_burned + $2
+		WHERE token_standard = $1 AND `+blockEffectsPending("$3"),
+		tokenStandard, numeric(burnAmount), blockHash)
 }
 
 // UpdateLastUpdateTimestamp updates the last update timestamp
@@ -90,12 +100,13 @@ func (r *TokenRepository) IncrementTransactionCount(ctx context.Context, tokenSt
 	return err
 }
 
-// IncrementTransactionCountBatch adds a transaction count increment to a batch
-func (r *TokenRepository) IncrementTransactionCountBatch(batch *pgx.Batch, tokenStandard string) {
+// IncrementTransactionCountBatch adds a transaction count increment to a
+// batch, applied once per blockHash; see blockEffectsPending.
+func (r *TokenRepository) IncrementTransactionCountBatch(batch *pgx.Batch, blockHash, tokenStandard string) {
 	batch.Queue(`
 		UPDATE tokens SET transaction_count = transaction_count + 1
-		WHERE token_standard = $1`,
-		tokenStandard)
+		WHERE token_standard = $1 AND `+blockEffectsPending("$2"),
+		tokenStandard, blockHash)
 }
 
 // UpdateHolderCount updates the holder count
@@ -120,8 +131,8 @@ func (r *TokenRepository) GetByStandard(ctx context.Context, tokenStandard strin
 			total_burned, last_update_timestamp, holder_count, transaction_count
 		FROM tokens WHERE token_standard = $1`, tokenStandard).Scan(
 		&t.TokenStandard, &t.Name, &t.Symbol, &t.Domain, &t.Decimals, &t.Owner,
-		&t.TotalSupply, &t.MaxSupply, &t.IsBurnable, &t.IsMintable, &t.IsUtility,
-		&t.TotalBurned, &t.LastUpdateTimestamp, &t.HolderCount, &t.TransactionCount)
+		NumericDest(&t.TotalSupply), NumericDest(&t.MaxSupply), &t.IsBurnable, &t.IsMintable, &t.IsUtility,
+		NumericDest(&t.TotalBurned), &t.LastUpdateTimestamp, &t.HolderCount, &t.TransactionCount)
 	if err != nil {
 		return nil, err
 	}
@@ -151,8 +162,8 @@ func (r *TokenRepository) List(ctx context.Context, opts ListOpts) ([]*models.To
 	for rows.Next() {
 		var t models.Token
 		if err := rows.Scan(&t.TokenStandard, &t.Name, &t.Symbol, &t.Domain, &t.Decimals, &t.Owner,
-			&t.TotalSupply, &t.MaxSupply, &t.IsBurnable, &t.IsMintable, &t.IsUtility,
-			&t.TotalBurned, &t.LastUpdateTimestamp, &t.HolderCount, &t.TransactionCount, &total); err != nil {
+			NumericDest(&t.TotalSupply), NumericDest(&t.MaxSupply), &t.IsBurnable, &t.IsMintable, &t.IsUtility,
+			NumericDest(&t.TotalBurned), &t.LastUpdateTimestamp, &t.HolderCount, &t.TransactionCount, &total); err != nil {
 			return nil, 0, err
 		}
 		out = append(out, &t)
@@ -186,8 +197,8 @@ func (r *TokenRepository) GetAll(ctx context.Context) ([]*models.Token, error) {
 	for rows.Next() {
 		var t models.Token
 		err := rows.Scan(&t.TokenStandard, &t.Name, &t.Symbol, &t.Domain, &t.Decimals, &t.Owner,
-			&t.TotalSupply, &t.MaxSupply, &t.IsBurnable, &t.IsMintable, &t.IsUtility,
-			&t.TotalBurned, &t.LastUpdateTimestamp, &t.HolderCount, &t.TransactionCount)
+			NumericDest(&t.TotalSupply), NumericDest(&t.MaxSupply), &t.IsBurnable, &t.IsMintable, &t.IsUtility,
+			NumericDest(&t.TotalBurned), &t.LastUpdateTimestamp, &t.HolderCount, &t.TransactionCount)
 		if err != nil {
 			return nil, err
 		}
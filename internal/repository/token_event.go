@@ -2,6 +2,9 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -9,7 +12,8 @@ import (
 	"github.com/0x3639/nom-indexer-go/internal/models"
 )
 
-// TokenEventRepository tracks individual mint and burn events per token.
+// TokenEventRepository tracks individual mint and burn events per token,
+// and the token_events control history: issuance, owner and flag changes.
 type TokenEventRepository struct {
 	pool *pgxpool.Pool
 }
@@ -25,7 +29,7 @@ func (r *TokenEventRepository) InsertMint(ctx context.Context, m *models.TokenMi
 		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		ON CONFLICT (account_block_hash) DO NOTHING`,
 		m.AccountBlockHash, m.MomentumHeight, m.MomentumTimestamp,
-		m.TokenStandard, m.Issuer, m.Receiver, m.Amount)
+		m.TokenStandard, m.Issuer, m.Receiver, numeric(m.Amount))
 	return err
 }
 
@@ -36,7 +40,7 @@ func (r *TokenEventRepository) InsertMintBatch(batch *pgx.Batch, m *models.Token
 		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		ON CONFLICT (account_block_hash) DO NOTHING`,
 		m.AccountBlockHash, m.MomentumHeight, m.MomentumTimestamp,
-		m.TokenStandard, m.Issuer, m.Receiver, m.Amount)
+		m.TokenStandard, m.Issuer, m.Receiver, numeric(m.Amount))
 }
 
 func (r *TokenEventRepository) InsertBurn(ctx context.Context, b *models.TokenBurn) error {
@@ -46,7 +50,7 @@ func (r *TokenEventRepository) InsertBurn(ctx context.Context, b *models.TokenBu
 		VALUES ($1, $2, $3, $4, $5, $6)
 		ON CONFLICT (account_block_hash) DO NOTHING`,
 		b.AccountBlockHash, b.MomentumHeight, b.MomentumTimestamp,
-		b.TokenStandard, b.Burner, b.Amount)
+		b.TokenStandard, b.Burner, numeric(b.Amount))
 	return err
 }
 
@@ -57,13 +61,13 @@ func (r *TokenEventRepository) InsertBurnBatch(batch *pgx.Batch, b *models.Token
 		VALUES ($1, $2, $3, $4, $5, $6)
 		ON CONFLICT (account_block_hash) DO NOTHING`,
 		b.AccountBlockHash, b.MomentumHeight, b.MomentumTimestamp,
-		b.TokenStandard, b.Burner, b.Amount)
+		b.TokenStandard, b.Burner, numeric(b.Amount))
 }
 
 // SumDailyMintsBurns returns total mints+burns for one token across a UTC
 // date. The date is treated as midnight-to-midnight in UTC regardless of the
 // Postgres session timezone.
-func (r *TokenEventRepository) SumDailyMintsBurns(ctx context.Context, tokenStandard, date string) (mints, burns int64, err error) {
+func (r *TokenEventRepository) SumDailyMintsBurns(ctx context.Context, tokenStandard, date string) (mints, burns *big.Int, err error) {
 	err = r.pool.QueryRow(ctx, `
 		WITH bounds AS (
 			SELECT EXTRACT(EPOCH FROM ($1::date AT TIME ZONE 'UTC'))::bigint AS lo,
@@ -78,6 +82,117 @@ func (r *TokenEventRepository) SumDailyMintsBurns(ctx context.Context, tokenStan
 				WHERE token_standard = $2
 				AND momentum_timestamp >= bounds.lo
 				AND momentum_timestamp < bounds.hi), 0)`,
-		date, tokenStandard).Scan(&mints, &burns)
+		date, tokenStandard).Scan(NumericDest(&mints), NumericDest(&burns))
 	return
 }
+
+const tokenEventColumns = `account_block_hash, send_block_hash, token_standard, method, address,
+	account_height, owner_before, owner_after, is_mintable_before, is_mintable_after,
+	is_burnable_before, is_burnable_after, inputs, momentum_height, momentum_timestamp`
+
+func tokenEventInputs(in map[string]string) string {
+	if len(in) == 0 {
+		return "{}"
+	}
+	b, err := json.Marshal(in)
+	if err != nil {
+		return "{}"
+	}
+	return sanitizeJSONForPostgres(string(b))
+}
+
+// InsertIssueBatch enqueues an accepted IssueToken on the per-momentum
+// batch. Idempotent via ON CONFLICT (account_block_hash) DO NOTHING.
+func (r *TokenEventRepository) InsertIssueBatch(batch *pgx.Batch, e *models.TokenEvent) {
+	batch.Queue(`
+		INSERT INTO token_events (`+tokenEventColumns+`)
+		VALUES ($1, $2, $3, 'IssueToken', $4, $5, NULL, $6, NULL, $7, NULL, $8, $9, $10, $11)
+		ON CONFLICT (account_block_hash) DO NOTHING`,
+		e.AccountBlockHash, e.SendBlockHash, e.TokenStandard, e.Address,
+		e.AccountHeight, e.OwnerAfter, e.IsMintableAfter, e.IsBurnableAfter,
+		tokenEventInputs(e.Inputs), e.MomentumHeight, e.MomentumTimestamp)
+}
+
+// UpdateTokenBatch enqueues an UpdateToken on the per-momentum batch.
+// e carries the requested owner and flags in its *After fields; the
+// *Before fields are read from the token's previous event, or from the
+// tokens row when it has none. Like the contract, the call is dropped
+// unless e.Address is the current owner and it leaves a non-mintable
+// token non-mintable. An accepted call also moves the tokens row to the
+// new values when it is the token's latest event, capping max_supply at
+// total_supply when minting is switched off.
+func (r *TokenEventRepository) UpdateTokenBatch(batch *pgx.Batch, e *models.TokenEvent) {
+	batch.Queue(`
+		WITH prev AS (
+			SELECT owner_after AS owner, is_mintable_after AS mintable, is_burnable_after AS burnable
+			FROM token_events
+			WHERE token_standard = $3 AND account_height < $5
+			ORDER BY account_height DESC
+			LIMIT 1
+		), cur AS (
+			SELECT owner, mintable, burnable FROM prev
+			UNION ALL
+			SELECT owner, is_mintable, is_burnable FROM tokens
+			WHERE token_standard = $3 AND NOT EXISTS (SELECT 1 FROM prev)
+		), ins AS (
+			INSERT INTO token_events (`+tokenEventColumns+`)
+			SELECT $1, $2, $3, 'UpdateToken', $4, $5, cur.owner, $6, cur.mintable, $7,
+				cur.burnable, $8, $9, $10, $11
+			FROM cur
+			WHERE cur.owner = $4 AND (cur.mintable OR NOT $7)
+			ON CONFLICT (account_block_hash) DO NOTHING
+			RETURNING token_standard, account_height, owner_after,
+				is_mintable_before, is_mintable_after, is_burnable_after
+		)
+		UPDATE tokens t SET
+			owner = ins.owner_after,
+			is_mintable = ins.is_mintable_after,
+			is_burnable = ins.is_burnable_after,
+			max_supply = CASE WHEN ins.is_mintable_before AND NOT ins.is_mintable_after
+				THEN t.total_supply ELSE t.max_supply END
+		FROM ins
+		WHERE t.token_standard = ins.token_standard
+			AND NOT EXISTS (SELECT 1 FROM token_events l
+				WHERE l.token_standard = ins.token_standard AND l.account_height > ins.account_height)`,
+		e.AccountBlockHash, e.SendBlockHash, e.TokenStandard, e.Address,
+		e.AccountHeight, e.OwnerAfter, e.IsMintableAfter, e.IsBurnableAfter,
+		tokenEventInputs(e.Inputs), e.MomentumHeight, e.MomentumTimestamp)
+}
+
+// History returns one token's control events newest first.
+func (r *TokenEventRepository) History(ctx context.Context, tokenStandard string, opts ListOpts) ([]*models.TokenEvent, int64, error) {
+	const where = `WHERE token_standard = $1`
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+tokenEventColumns+`, COUNT(*) OVER () AS total
+		FROM token_events `+where+`
+		ORDER BY account_height DESC
+		LIMIT $2 OFFSET $3`, tokenStandard, opts.Limit, opts.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("TokenEventRepository.History: %w", err)
+	}
+	defer rows.Close()
+	var (
+		out   []*models.TokenEvent
+		total int64
+	)
+	for rows.Next() {
+		e := &models.TokenEvent{}
+		if err := rows.Scan(
+			&e.AccountBlockHash, &e.SendBlockHash, &e.TokenStandard, &e.Method, &e.Address,
+			&e.AccountHeight, &e.OwnerBefore, &e.OwnerAfter, &e.IsMintableBefore, &e.IsMintableAfter,
+			&e.IsBurnableBefore, &e.IsBurnableAfter, &e.Inputs, &e.MomentumHeight, &e.MomentumTimestamp,
+			&total); err != nil {
+			return nil, 0, fmt.Errorf("TokenEventRepository.History: %w", err)
+		}
+		out = append(out, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("TokenEventRepository.History: %w", err)
+	}
+	if len(out) == 0 && opts.Offset > 0 {
+		if total, err = fallbackCount(ctx, r.pool, `SELECT COUNT(*) FROM token_events `+where, tokenStandard); err != nil {
+			return nil, 0, fmt.Errorf("TokenEventRepository.History: %w", err)
+		}
+	}
+	return out, total, nil
+}
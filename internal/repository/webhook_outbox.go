@@ -0,0 +1,256 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+const webhookOutboxColumns = `
+    id, delivery_id::text, subscription_id, endpoint_url, event_type, payload, momentum_height, status,
+    attempts, next_attempt_at, last_error, created_at, delivered_at, dead_at`
+
+// WebhookOutboxRepository is the durable queue behind webhook delivery.
+// The indexer inserts rows with InsertBatch inside each momentum's
+// transaction; the webhooks.Dispatcher claims and completes them.
+type WebhookOutboxRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewWebhookOutboxRepository constructs a WebhookOutboxRepository backed by pool.
+func NewWebhookOutboxRepository(pool *pgxpool.Pool) *WebhookOutboxRepository {
+	return &WebhookOutboxRepository{pool: pool}
+}
+
+// InsertBatch queues one pending outbox row. e.NextAttemptAt should be
+// the creation time so the worker picks it up immediately. e.DeliveryID
+// is derived from the event (see webhooks.Dispatcher.Outbox), so a row
+// already queued for the same event and endpoint - by an earlier pass
+// over the same height - is left alone rather than queued twice.
+func (r *WebhookOutboxRepository) InsertBatch(batch *pgx.Batch, e *models.WebhookOutboxEntry) {
+	batch.Queue(insertWebhookOutboxSQL+` ON CONFLICT (delivery_id) DO NOTHING`,
+		webhookOutboxInsertArgs(e)...)
+}
+
+// Insert queues a single row outside any momentum transaction and returns
+// its id. Used by the API's "send test event" action. An empty
+// e.DeliveryID is assigned a random one by the database.
+func (r *WebhookOutboxRepository) Insert(ctx context.Context, e *models.WebhookOutboxEntry) (int64, error) {
+	var id int64
+	if err := r.pool.QueryRow(ctx, insertWebhookOutboxSQL+` RETURNING id`,
+		webhookOutboxInsertArgs(e)...).Scan(&id); err != nil {
+		return 0, fmt.Errorf("WebhookOutboxRepository.Insert: %w", err)
+	}
+	return id, nil
+}
+
+const insertWebhookOutboxSQL = `
+	INSERT INTO webhook_outbox (subscription_id, endpoint_url, event_type, payload,
+		momentum_height, next_attempt_at, created_at, delivery_id)
+	VALUES ($1, $2, $3, $4::jsonb, $5, $6, $7, COALESCE(NULLIF($8, '')::uuid, gen_random_uuid()))`
+
+func webhookOutboxInsertArgs(e *models.WebhookOutboxEntry) []any {
+	return []any{e.SubscriptionID, e.EndpointURL, e.EventType, string(e.Payload),
+		e.MomentumHeight, e.NextAttemptAt, e.CreatedAt, e.DeliveryID}
+}
+
+// DiscardPendingAboveBatch queues the removal of undelivered rows for
+// momentums above height. Used by the reorg rollback: no subscriber has
+// seen those events, and the data they describe is about to be deleted
+// in the same transaction. Delivered and dead rows are kept as history.
+func (r *WebhookOutboxRepository) DiscardPendingAboveBatch(batch *pgx.Batch, height int64) {
+	batch.Queue(`
+		DELETE FROM webhook_outbox
+		WHERE momentum_height > $1 AND status = 'pending'`, height)
+}
+
+// ClaimDue leases up to limit pending rows whose next_attempt_at has
+// passed, oldest first, by pushing their next_attempt_at to leaseUntil.
+// A worker that crashes mid-delivery therefore has its rows retried once
+// the lease lapses instead of losing them.
+func (r *WebhookOutboxRepository) ClaimDue(ctx context.Context, now, leaseUntil int64, limit int) ([]*models.WebhookOutboxEntry, error) {
+	rows, err := r.pool.Query(ctx, `
+		UPDATE webhook_outbox SET next_attempt_at = $2
+		WHERE id IN (
+			SELECT id FROM webhook_outbox
+			WHERE status = 'pending' AND next_attempt_at <= $1
+			ORDER BY id
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+webhookOutboxColumns,
+		now, leaseUntil, limit)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookOutboxRepository.ClaimDue: %w", err)
+	}
+	entries, err := scanWebhookOutbox(rows)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookOutboxRepository.ClaimDue: %w", err)
+	}
+	// UPDATE … RETURNING does not preserve the subquery's order.
+	slices.SortFunc(entries, func(a, b *models.WebhookOutboxEntry) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return entries, nil
+}
+
+// Complete records attempt a and moves its outbox row to status in one
+// transaction. For status pending, nextAttemptAt schedules the retry;
+// delivered and dead stamp delivered_at / dead_at with the attempt time.
+func (r *WebhookOutboxRepository) Complete(ctx context.Context, a *models.WebhookDeliveryAttempt, status string, nextAttemptAt int64) error {
+	batch := &pgx.Batch{}
+	batch.Queue(`
+		INSERT INTO webhook_delivery_attempts (outbox_id, attempt, attempted_at,
+			status_code, error, duration_ms)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		a.OutboxID, a.Attempt, a.AttemptedAt, a.StatusCode, a.Error, a.DurationMs)
+	batch.Queue(`
+		UPDATE webhook_outbox SET
+			status          = $2,
+			attempts        = $3,
+			next_attempt_at = $4,
+			last_error      = $5,
+			delivered_at    = CASE WHEN $2 = 'delivered' THEN $6::bigint END,
+			dead_at         = CASE WHEN $2 = 'dead' THEN $6::bigint END
+		WHERE id = $1`,
+		a.OutboxID, status, a.Attempt, nextAttemptAt, a.Error, a.AttemptedAt)
+
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		return tx.SendBatch(ctx, batch).Close()
+	})
+	if err != nil {
+		return fmt.Errorf("WebhookOutboxRepository.Complete: %w", err)
+	}
+	return nil
+}
+
+// Defer pushes the next attempt of the pending rows ids to until without
+// recording an attempt. The dispatcher sets rows aside this way while
+// their endpoint's circuit is open or its queue is full.
+func (r *WebhookOutboxRepository) Defer(ctx context.Context, ids []int64, until int64) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE webhook_outbox SET next_attempt_at = $2
+		WHERE id = ANY($1) AND status = 'pending'`, ids, until)
+	if err != nil {
+		return fmt.Errorf("WebhookOutboxRepository.Defer: %w", err)
+	}
+	return nil
+}
+
+// PruneDelivered deletes delivered rows (and, by cascade, their attempts)
+// delivered before the given time. Pending and dead rows are never pruned.
+func (r *WebhookOutboxRepository) PruneDelivered(ctx context.Context, before int64) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `
+		DELETE FROM webhook_outbox
+		WHERE status = 'delivered' AND delivered_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("WebhookOutboxRepository.PruneDelivered: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ListDead returns up to limit dead-lettered rows, oldest first. An empty
+// endpointURL lists every endpoint.
+func (r *WebhookOutboxRepository) ListDead(ctx context.Context, endpointURL string, limit int) ([]*models.WebhookOutboxEntry, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+webhookOutboxColumns+`
+		FROM webhook_outbox
+		WHERE status = 'dead' AND ($1 = '' OR endpoint_url = $1)
+		ORDER BY id
+		LIMIT $2`,
+		endpointURL, limit)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookOutboxRepository.ListDead: %w", err)
+	}
+	entries, err := scanWebhookOutbox(rows)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookOutboxRepository.ListDead: %w", err)
+	}
+	return entries, nil
+}
+
+// ReplayDead moves dead rows back to pending with a fresh retry budget,
+// due at now. With ids empty every dead row for endpointURL (or for all
+// endpoints when endpointURL is empty) is replayed. Attempt history is
+// kept. Returns the number of rows replayed.
+func (r *WebhookOutboxRepository) ReplayDead(ctx context.Context, endpointURL string, ids []int64, now int64) (int64, error) {
+	if ids == nil {
+		ids = []int64{} // a NULL array would make the filter match nothing
+	}
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE webhook_outbox SET
+			status = 'pending', attempts = 0, next_attempt_at = $3, dead_at = NULL
+		WHERE status = 'dead'
+		  AND ($1 = '' OR endpoint_url = $1)
+		  AND (cardinality($2::bigint[]) = 0 OR id = ANY($2))`,
+		endpointURL, ids, now)
+	if err != nil {
+		return 0, fmt.Errorf("WebhookOutboxRepository.ReplayDead: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ListDeliveries returns the delivery log of one subscription: every
+// recorded attempt against its outbox rows, newest first, with the row's
+// event type and current status. Callers check ownership first.
+func (r *WebhookOutboxRepository) ListDeliveries(ctx context.Context, subscriptionID int64, opts ListOpts) ([]*models.WebhookDelivery, int64, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT a.outbox_id, a.attempt, a.attempted_at, a.status_code, a.error, a.duration_ms,
+			o.delivery_id::text, o.event_type, o.momentum_height, o.status,
+			COUNT(*) OVER () AS total
+		FROM webhook_delivery_attempts a
+		JOIN webhook_outbox o ON o.id = a.outbox_id
+		WHERE o.subscription_id = $1
+		ORDER BY a.id DESC
+		LIMIT $2 OFFSET $3`, subscriptionID, opts.Limit, opts.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("WebhookOutboxRepository.ListDeliveries: %w", err)
+	}
+	defer rows.Close()
+	var (
+		out   []*models.WebhookDelivery
+		total int64
+	)
+	for rows.Next() {
+		var d models.WebhookDelivery
+		if err := rows.Scan(&d.OutboxID, &d.Attempt, &d.AttemptedAt, &d.StatusCode, &d.Error,
+			&d.DurationMs, &d.DeliveryID, &d.EventType, &d.MomentumHeight, &d.Status, &total); err != nil {
+			return nil, 0, fmt.Errorf("WebhookOutboxRepository.ListDeliveries: %w", err)
+		}
+		out = append(out, &d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("WebhookOutboxRepository.ListDeliveries: %w", err)
+	}
+	if len(out) == 0 && opts.Offset > 0 {
+		total, err = fallbackCount(ctx, r.pool, `
+			SELECT COUNT(*) FROM webhook_delivery_attempts a
+			JOIN webhook_outbox o ON o.id = a.outbox_id
+			WHERE o.subscription_id = $1`, subscriptionID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("WebhookOutboxRepository.ListDeliveries: %w", err)
+		}
+	}
+	return out, total, nil
+}
+
+func scanWebhookOutbox(rows pgx.Rows) ([]*models.WebhookOutboxEntry, error) {
+	defer rows.Close()
+	var out []*models.WebhookOutboxEntry
+	for rows.Next() {
+		var e models.WebhookOutboxEntry
+		if err := rows.Scan(&e.ID, &e.DeliveryID, &e.SubscriptionID, &e.EndpointURL, &e.EventType, &e.Payload,
+			&e.MomentumHeight, &e.Status, &e.Attempts, &e.NextAttemptAt,
+			&e.LastError, &e.CreatedAt, &e.DeliveredAt, &e.DeadAt); err != nil {
+			return nil, err
+		}
+		out = append(out, &e)
+	}
+	return out, rows.Err()
+}
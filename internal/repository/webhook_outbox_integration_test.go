@@ -0,0 +1,219 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+// TestIntegration_WebhookOutbox_InsertSkipsKnownDeliveryID queues the
+// same event twice, as reprocessing a height does, and checks only one
+// row is kept. Rows without a delivery id get distinct random ones.
+func TestIntegration_WebhookOutbox_InsertSkipsKnownDeliveryID(t *testing.T) {
+	pool := newTestDB(t)
+	ctx := context.Background()
+	repo := NewWebhookOutboxRepository(pool)
+
+	const id = "0b5c8a1e-3f6d-5a2b-8c4e-7d9f1a2b3c4d"
+	for range 2 {
+		batch := &pgx.Batch{}
+		repo.InsertBatch(batch, &models.WebhookOutboxEntry{
+			DeliveryID: id, EndpointURL: "http://a", EventType: "momentum.inserted",
+			Payload: []byte(`{"height":1}`), MomentumHeight: 1, NextAttemptAt: 100, CreatedAt: 100,
+		})
+		sendBatch(t, ctx, pool, batch)
+	}
+	var rows int
+	if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM webhook_outbox WHERE delivery_id = $1`, id).Scan(&rows); err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if rows != 1 {
+		t.Errorf("rows with the delivery id = %d, want 1", rows)
+	}
+
+	for range 2 {
+		if _, err := repo.Insert(ctx, &models.WebhookOutboxEntry{
+			EndpointURL: "http://a", EventType: "webhook.test", Payload: []byte(`{}`), NextAttemptAt: 100, CreatedAt: 100,
+		}); err != nil {
+			t.Fatalf("insert test event: %v", err)
+		}
+	}
+	if err := pool.QueryRow(ctx, `SELECT COUNT(DISTINCT delivery_id) FROM webhook_outbox WHERE event_type = 'webhook.test'`).Scan(&rows); err != nil {
+		t.Fatalf("count test events: %v", err)
+	}
+	if rows != 2 {
+		t.Errorf("distinct test event ids = %d, want 2", rows)
+	}
+}
+
+func TestIntegration_WebhookOutbox_Lifecycle(t *testing.T) {
+	pool := newTestDB(t)
+	ctx := context.Background()
+	repo := NewWebhookOutboxRepository(pool)
+
+	batch := &pgx.Batch{}
+	for h, url := range []string{"http://a", "http://b", "http://a"} {
+		repo.InsertBatch(batch, &models.WebhookOutboxEntry{
+			EndpointURL: url, EventType: "momentum.inserted",
+			Payload:        []byte(`{"height":1}`),
+			MomentumHeight: int64(h + 1), NextAttemptAt: 100, CreatedAt: 100,
+		})
+	}
+	sendBatch(t, ctx, pool, batch)
+
+	// Nothing is due before next_attempt_at.
+	if got, err := repo.ClaimDue(ctx, 99, 200, 10); err != nil || len(got) != 0 {
+		t.Fatalf("early claim = %d rows, err %v; want none", len(got), err)
+	}
+	claimed, err := repo.ClaimDue(ctx, 100, 200, 2)
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if len(claimed) != 2 || claimed[0].ID != 1 || claimed[1].ID != 2 {
+		t.Fatalf("claimed = %+v, want ids 1 and 2", claimed)
+	}
+	if claimed[0].NextAttemptAt != 200 || string(claimed[0].Payload) != `{"height": 1}` {
+		t.Errorf("claimed row = %+v", claimed[0])
+	}
+	if len(claimed[0].DeliveryID) != 36 || claimed[0].DeliveryID == claimed[1].DeliveryID {
+		t.Errorf("delivery ids = %q, %q; want distinct UUIDs", claimed[0].DeliveryID, claimed[1].DeliveryID)
+	}
+	// Leased rows are not handed out again; the third is.
+	claimed, _ = repo.ClaimDue(ctx, 100, 200, 10)
+	if len(claimed) != 1 || claimed[0].ID != 3 {
+		t.Fatalf("second claim = %+v, want only id 3", claimed)
+	}
+
+	ok := 200
+	if err := repo.Complete(ctx, &models.WebhookDeliveryAttempt{
+		OutboxID: 1, Attempt: 1, AttemptedAt: 110, StatusCode: &ok, DurationMs: 5,
+	}, models.WebhookStatusDelivered, 110); err != nil {
+		t.Fatalf("complete delivered: %v", err)
+	}
+	msg := "unexpected status 500"
+	fail := 500
+	if err := repo.Complete(ctx, &models.WebhookDeliveryAttempt{
+		OutboxID: 2, Attempt: 1, AttemptedAt: 110, StatusCode: &fail, Error: &msg,
+	}, models.WebhookStatusDead, 110); err != nil {
+		t.Fatalf("complete dead: %v", err)
+	}
+
+	var attempts int
+	if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM webhook_delivery_attempts`).Scan(&attempts); err != nil {
+		t.Fatalf("count attempts: %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("attempts = %d, want 2", attempts)
+	}
+
+	dead, err := repo.ListDead(ctx, "", 10)
+	if err != nil {
+		t.Fatalf("list dead: %v", err)
+	}
+	if len(dead) != 1 || dead[0].ID != 2 || dead[0].DeadAt == nil || *dead[0].DeadAt != 110 ||
+		dead[0].LastError == nil || *dead[0].LastError != msg {
+		t.Fatalf("dead = %+v", dead)
+	}
+	if dead, _ := repo.ListDead(ctx, "http://a", 10); len(dead) != 0 {
+		t.Errorf("dead for http://a = %+v, want none", dead)
+	}
+
+	n, err := repo.ReplayDead(ctx, "http://b", nil, 120)
+	if err != nil || n != 1 {
+		t.Fatalf("replay = %d, err %v; want 1", n, err)
+	}
+	claimed, _ = repo.ClaimDue(ctx, 120, 300, 10)
+	if len(claimed) != 1 || claimed[0].ID != 2 || claimed[0].Attempts != 0 || claimed[0].DeadAt != nil {
+		t.Fatalf("replayed row = %+v, want id 2 pending with a fresh budget", claimed)
+	}
+
+	if n, err := repo.PruneDelivered(ctx, 110); err != nil || n != 0 {
+		t.Fatalf("prune at delivery time = %d, err %v; want 0", n, err)
+	}
+	if n, err := repo.PruneDelivered(ctx, 111); err != nil || n != 1 {
+		t.Fatalf("prune = %d, err %v; want 1", n, err)
+	}
+	// The cascade removes the delivered row's attempt.
+	if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM webhook_delivery_attempts`).Scan(&attempts); err != nil {
+		t.Fatalf("count attempts: %v", err)
+	}
+	if attempts != 1 {
+		t.Errorf("attempts after prune = %d, want 1", attempts)
+	}
+}
+
+func TestIntegration_WebhookOutbox_DiscardPendingAbove(t *testing.T) {
+	pool := newTestDB(t)
+	ctx := context.Background()
+	repo := NewWebhookOutboxRepository(pool)
+
+	batch := &pgx.Batch{}
+	for h := int64(1); h <= 3; h++ {
+		repo.InsertBatch(batch, &models.WebhookOutboxEntry{
+			EndpointURL: "http://a", EventType: "momentum.inserted", Payload: []byte(`{}`),
+			MomentumHeight: h, NextAttemptAt: 100, CreatedAt: 100,
+		})
+	}
+	sendBatch(t, ctx, pool, batch)
+
+	// Row 3 was already delivered; the reorg keeps it as history.
+	if err := repo.Complete(ctx, &models.WebhookDeliveryAttempt{OutboxID: 3, Attempt: 1, AttemptedAt: 100},
+		models.WebhookStatusDelivered, 100); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+
+	batch = &pgx.Batch{}
+	repo.DiscardPendingAboveBatch(batch, 1)
+	sendBatch(t, ctx, pool, batch)
+
+	rows, err := pool.Query(ctx, `SELECT id FROM webhook_outbox ORDER BY id`)
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 3 {
+		t.Errorf("remaining ids = %v, want [1 3]", ids)
+	}
+}
+
+func TestIntegration_WebhookOutbox_Defer(t *testing.T) {
+	pool := newTestDB(t)
+	ctx := context.Background()
+	repo := NewWebhookOutboxRepository(pool)
+
+	batch := &pgx.Batch{}
+	for h := int64(1); h <= 2; h++ {
+		repo.InsertBatch(batch, &models.WebhookOutboxEntry{
+			EndpointURL: "http://a", EventType: "momentum.inserted", Payload: []byte(`{}`),
+			MomentumHeight: h, NextAttemptAt: 100, CreatedAt: 100,
+		})
+	}
+	sendBatch(t, ctx, pool, batch)
+	if err := repo.Complete(ctx, &models.WebhookDeliveryAttempt{OutboxID: 2, Attempt: 1, AttemptedAt: 100},
+		models.WebhookStatusDelivered, 100); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+
+	if err := repo.Defer(ctx, []int64{1, 2}, 500); err != nil {
+		t.Fatalf("defer: %v", err)
+	}
+	if claimed, _ := repo.ClaimDue(ctx, 499, 600, 10); len(claimed) != 0 {
+		t.Fatalf("claimed %+v before the deferral ends", claimed)
+	}
+	claimed, err := repo.ClaimDue(ctx, 500, 600, 10)
+	if err != nil || len(claimed) != 1 || claimed[0].ID != 1 || claimed[0].Attempts != 0 {
+		t.Fatalf("claim after deferral = %+v, err %v; want id 1 with no attempts spent", claimed, err)
+	}
+	var next int64
+	if err := pool.QueryRow(ctx, `SELECT next_attempt_at FROM webhook_outbox WHERE id = 2`).Scan(&next); err != nil || next != 100 {
+		t.Errorf("delivered row next_attempt_at = %d, err %v; want untouched", next, err)
+	}
+}
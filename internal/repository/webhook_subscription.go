@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+const webhookSubscriptionColumns = `
+    id, owner, url, events, filter, description, secret, previous_secret,
+    previous_secret_expires_at, status, created_at, updated_at, secret_rotated_at`
+
+// WebhookSubscriptionRepository stores runtime-managed webhook
+// subscriptions. The API reads and writes them on behalf of their owner;
+// the indexer's webhooks.Dispatcher reloads the full set with ListAll.
+//
+// Owner-scoped methods return pgx.ErrNoRows both for a missing id and for
+// an id owned by someone else, so one subject cannot probe another's
+// subscriptions.
+type WebhookSubscriptionRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewWebhookSubscriptionRepository constructs a WebhookSubscriptionRepository backed by pool.
+func NewWebhookSubscriptionRepository(pool *pgxpool.Pool) *WebhookSubscriptionRepository {
+	return &WebhookSubscriptionRepository{pool: pool}
+}
+
+// Create inserts s and fills in its ID.
+func (r *WebhookSubscriptionRepository) Create(ctx context.Context, s *models.WebhookSubscription) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO webhook_subscriptions (owner, url, events, filter, description, secret, status,
+			created_at, updated_at, secret_rotated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id`,
+		s.Owner, s.URL, nonNilEvents(s.Events), s.Filter, s.Description, s.Secret, s.Status,
+		s.CreatedAt, s.UpdatedAt, s.SecretRotatedAt).Scan(&s.ID)
+	if err != nil {
+		return fmt.Errorf("WebhookSubscriptionRepository.Create: %w", err)
+	}
+	return nil
+}
+
+// Get returns owner's subscription id.
+func (r *WebhookSubscriptionRepository) Get(ctx context.Context, owner string, id int64) (*models.WebhookSubscription, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+webhookSubscriptionColumns+`
+		FROM webhook_subscriptions
+		WHERE id = $1 AND owner = $2`, id, owner)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookSubscriptionRepository.Get: %w", err)
+	}
+	subs, err := scanWebhookSubscriptions(rows)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookSubscriptionRepository.Get: %w", err)
+	}
+	if len(subs) == 0 {
+		return nil, pgx.ErrNoRows
+	}
+	return subs[0], nil
+}
+
+// ListByOwner returns owner's subscriptions, oldest first, with the
+// total count for pagination.
+func (r *WebhookSubscriptionRepository) ListByOwner(ctx context.Context, owner string, opts ListOpts) ([]*models.WebhookSubscription, int64, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+webhookSubscriptionColumns+`, COUNT(*) OVER () AS total
+		FROM webhook_subscriptions
+		WHERE owner = $1
+		ORDER BY id
+		LIMIT $2 OFFSET $3`, owner, opts.Limit, opts.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("WebhookSubscriptionRepository.ListByOwner: %w", err)
+	}
+	defer rows.Close()
+	var (
+		out   []*models.WebhookSubscription
+		total int64
+	)
+	for rows.Next() {
+		var s models.WebhookSubscription
+		if err := rows.Scan(&s.ID, &s.Owner, &s.URL, &s.Events, &s.Filter, &s.Description, &s.Secret,
+			&s.PreviousSecret, &s.PreviousSecretExpiresAt, &s.Status, &s.CreatedAt, &s.UpdatedAt, &s.SecretRotatedAt, &total); err != nil {
+			return nil, 0, fmt.Errorf("WebhookSubscriptionRepository.ListByOwner: %w", err)
+		}
+		out = append(out, &s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("WebhookSubscriptionRepository.ListByOwner: %w", err)
+	}
+	if len(out) == 0 && opts.Offset > 0 {
+		total, err = fallbackCount(ctx, r.pool,
+			`SELECT COUNT(*) FROM webhook_subscriptions WHERE owner = $1`, owner)
+		if err != nil {
+			return nil, 0, fmt.Errorf("WebhookSubscriptionRepository.ListByOwner: %w", err)
+		}
+	}
+	return out, total, nil
+}
+
+// ListAll returns every subscription, active and paused. Used by the
+// dispatcher to refresh its routing table.
+func (r *WebhookSubscriptionRepository) ListAll(ctx context.Context) ([]*models.WebhookSubscription, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+webhookSubscriptionColumns+`
+		FROM webhook_subscriptions
+		ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookSubscriptionRepository.ListAll: %w", err)
+	}
+	subs, err := scanWebhookSubscriptions(rows)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookSubscriptionRepository.ListAll: %w", err)
+	}
+	return subs, nil
+}
+
+// Update writes the mutable fields of s (url, events, filter,
+// description, status, updated_at). The secret is changed only by RotateSecret.
+func (r *WebhookSubscriptionRepository) Update(ctx context.Context, s *models.WebhookSubscription) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE webhook_subscriptions SET
+			url = $3, events = $4, filter = $5, description = $6, status = $7, updated_at = $8
+		WHERE id = $1 AND owner = $2`,
+		s.ID, s.Owner, s.URL, nonNilEvents(s.Events), s.Filter, s.Description, s.Status, s.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("WebhookSubscriptionRepository.Update: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// RotateSecret replaces the signing secret of owner's subscription id.
+// The old secret becomes the previous secret, which keeps signing
+// deliveries alongside the new one until previousExpiresAt.
+func (r *WebhookSubscriptionRepository) RotateSecret(ctx context.Context, owner string, id int64, secret string, now, previousExpiresAt int64) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE webhook_subscriptions SET
+			previous_secret = secret, previous_secret_expires_at = $5,
+			secret = $3, secret_rotated_at = $4, updated_at = $4
+		WHERE id = $1 AND owner = $2`, id, owner, secret, now, previousExpiresAt)
+	if err != nil {
+		return fmt.Errorf("WebhookSubscriptionRepository.RotateSecret: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// Delete removes owner's subscription id. Its outbox rows and delivery
+// log go with it (ON DELETE CASCADE).
+func (r *WebhookSubscriptionRepository) Delete(ctx context.Context, owner string, id int64) error {
+	tag, err := r.pool.Exec(ctx, `
+		DELETE FROM webhook_subscriptions WHERE id = $1 AND owner = $2`, id, owner)
+	if err != nil {
+		return fmt.Errorf("WebhookSubscriptionRepository.Delete: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// nonNilEvents maps a nil filter to an empty array; the column is NOT NULL.
+func nonNilEvents(events []string) []string {
+	if events == nil {
+		return []string{}
+	}
+	return events
+}
+
+func scanWebhookSubscriptions(rows pgx.Rows) ([]*models.WebhookSubscription, error) {
+	defer rows.Close()
+	var out []*models.WebhookSubscription
+	for rows.Next() {
+		var s models.WebhookSubscription
+		if err := rows.Scan(&s.ID, &s.Owner, &s.URL, &s.Events, &s.Filter, &s.Description, &s.Secret,
+			&s.PreviousSecret, &s.PreviousSecretExpiresAt, &s.Status, &s.CreatedAt, &s.UpdatedAt, &s.SecretRotatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, &s)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,133 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+func TestIntegration_WebhookSubscription_CRUD(t *testing.T) {
+	pool := newTestDB(t)
+	ctx := context.Background()
+	repo := NewWebhookSubscriptionRepository(pool)
+
+	s := &models.WebhookSubscription{
+		Owner: "alice", URL: "http://a", Events: []string{"reorg"}, Secret: "s1",
+		Filter: models.WebhookFilter{TokenStandards: []string{models.ZnnTokenStandard}, MinAmount: "100"},
+		Status: models.WebhookSubscriptionActive, CreatedAt: 100, UpdatedAt: 100, SecretRotatedAt: 100,
+	}
+	if err := repo.Create(ctx, s); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if s.ID != 1 {
+		t.Fatalf("id = %d, want 1", s.ID)
+	}
+	if err := repo.Create(ctx, &models.WebhookSubscription{
+		Owner: "bob", URL: "http://b", Secret: "s2", Status: models.WebhookSubscriptionActive,
+	}); err != nil {
+		t.Fatalf("create bob: %v", err)
+	}
+
+	got, err := repo.Get(ctx, "alice", 1)
+	if err != nil || got.URL != "http://a" || len(got.Events) != 1 || got.Secret != "s1" {
+		t.Fatalf("get = %+v, err %v", got, err)
+	}
+	if len(got.Filter.TokenStandards) != 1 || got.Filter.MinAmount != "100" {
+		t.Errorf("filter = %+v, want it round-tripped", got.Filter)
+	}
+	if _, err := repo.Get(ctx, "bob", 1); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("get as bob err = %v, want ErrNoRows", err)
+	}
+
+	list, total, err := repo.ListByOwner(ctx, "alice", ListOpts{Limit: 10})
+	if err != nil || total != 1 || len(list) != 1 {
+		t.Fatalf("list = %d rows, total %d, err %v", len(list), total, err)
+	}
+	if _, total, _ := repo.ListByOwner(ctx, "alice", ListOpts{Limit: 10, Offset: 5}); total != 1 {
+		t.Errorf("total past the end = %d, want 1", total)
+	}
+
+	got.Status = models.WebhookSubscriptionPaused
+	got.Events = nil
+	got.Filter = models.WebhookFilter{BlockTypes: []int{models.BlockTypeUserSend}}
+	got.UpdatedAt = 200
+	if err := repo.Update(ctx, got); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	got.Owner = "bob"
+	if err := repo.Update(ctx, got); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("update as bob err = %v, want ErrNoRows", err)
+	}
+	if err := repo.RotateSecret(ctx, "alice", 1, "s3", 300, 400); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	got, _ = repo.Get(ctx, "alice", 1)
+	if got.Status != models.WebhookSubscriptionPaused || len(got.Events) != 0 ||
+		got.Filter.MinAmount != "" || len(got.Filter.BlockTypes) != 1 ||
+		got.Secret != "s3" || got.PreviousSecret != "s1" || got.PreviousSecretExpiresAt != 400 ||
+		got.SecretRotatedAt != 300 || got.UpdatedAt != 300 {
+		t.Errorf("after update+rotate = %+v", got)
+	}
+
+	all, err := repo.ListAll(ctx)
+	if err != nil || len(all) != 2 {
+		t.Fatalf("list all = %d, err %v", len(all), err)
+	}
+}
+
+func TestIntegration_WebhookSubscription_DeleteCascadesToOutbox(t *testing.T) {
+	pool := newTestDB(t)
+	ctx := context.Background()
+	subs := NewWebhookSubscriptionRepository(pool)
+	outbox := NewWebhookOutboxRepository(pool)
+
+	s := &models.WebhookSubscription{Owner: "alice", URL: "http://a", Secret: "s", Status: models.WebhookSubscriptionActive}
+	if err := subs.Create(ctx, s); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	id, err := outbox.Insert(ctx, &models.WebhookOutboxEntry{
+		SubscriptionID: &s.ID, EndpointURL: s.URL, EventType: "webhook.test",
+		Payload: []byte(`{}`), NextAttemptAt: 100, CreatedAt: 100,
+	})
+	if err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	code := 500
+	msg := "unexpected status 500"
+	if err := outbox.Complete(ctx, &models.WebhookDeliveryAttempt{
+		OutboxID: id, Attempt: 1, AttemptedAt: 110, StatusCode: &code, Error: &msg, DurationMs: 3,
+	}, models.WebhookStatusPending, 120); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+
+	deliveries, total, err := outbox.ListDeliveries(ctx, s.ID, ListOpts{Limit: 10})
+	if err != nil || total != 1 || len(deliveries) != 1 {
+		t.Fatalf("deliveries = %d, total %d, err %v", len(deliveries), total, err)
+	}
+	d := deliveries[0]
+	if d.OutboxID != id || d.DeliveryID == "" || d.EventType != "webhook.test" || d.Status != models.WebhookStatusPending ||
+		d.StatusCode == nil || *d.StatusCode != 500 {
+		t.Errorf("delivery = %+v", d)
+	}
+
+	if err := subs.Delete(ctx, "alice", s.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	var rows int
+	if err := pool.QueryRow(ctx, `
+		SELECT (SELECT COUNT(*) FROM webhook_outbox) + (SELECT COUNT(*) FROM webhook_delivery_attempts)`).Scan(&rows); err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if rows != 0 {
+		t.Errorf("%d outbox/attempt rows survived the delete, want 0", rows)
+	}
+	if err := subs.Delete(ctx, "alice", s.ID); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("second delete err = %v, want ErrNoRows", err)
+	}
+}
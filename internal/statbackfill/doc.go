@@ -0,0 +1,17 @@
+// Package statbackfill rebuilds the daily *_stat_histories rows of past
+// days, as each day stood at its end, from the tables the indexer keeps.
+//
+// The cron loop only ever snapshots the current day from current totals,
+// so history starts at deployment. Counts with a timestamp on every row
+// (stakes, fusions, accounts, momentum transactions, pillar and sentinel
+// lifetimes, bridge requests) are filtered by date directly. Balances,
+// token supplies and per-token transaction counts have no history; the
+// runner reads their current values once and rewinds them day by day,
+// newest first, by what each day's account blocks, mints and burns
+// changed. Holder counts and pillar weights are then counted from the
+// rewound balances.
+//
+// cmd/backfill-stats is the command-line front end; the indexer's cron
+// loop runs the same rebuild for the days since its last snapshot. See
+// docs/operations/stat-backfill.md.
+package statbackfill
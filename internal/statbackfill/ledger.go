@@ -0,0 +1,193 @@
+package statbackfill
+
+import (
+	"cmp"
+	"math/big"
+	"slices"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+	"github.com/0x3639/nom-indexer-go/internal/repository"
+)
+
+type balanceKey struct {
+	address, token string
+}
+
+// ledger is the running state a Runner rewinds: balances, token totals
+// and the transaction total as of the end of the last day rewound to.
+type ledger struct {
+	balances map[balanceKey]*big.Int
+	// holders counts, per token, the addresses with a positive balance.
+	holders   map[string]int64
+	supply    map[string]*big.Int
+	txs       map[string]int64
+	firstSeen map[string]int64
+	tokens    []string
+	totalTx   int64
+}
+
+func newLedger(snap *repository.StatLedger) *ledger {
+	l := &ledger{
+		balances:  map[balanceKey]*big.Int{},
+		holders:   map[string]int64{},
+		supply:    map[string]*big.Int{},
+		txs:       map[string]int64{},
+		firstSeen: map[string]int64{},
+		totalTx:   snap.TotalTx,
+	}
+	for _, b := range snap.Balances {
+		l.add(b.Address, b.TokenStandard, b.Balance)
+	}
+	for _, t := range snap.Tokens {
+		l.tokens = append(l.tokens, t.TokenStandard)
+		l.supply[t.TokenStandard] = new(big.Int).Set(t.TotalSupply)
+		l.txs[t.TokenStandard] = t.Transactions
+		l.firstSeen[t.TokenStandard] = t.FirstSeen
+	}
+	return l
+}
+
+// add moves the balance of address in token by delta. The token
+// contract is left out: it mints what it sends and burns what it
+// receives, so its balance does not follow its blocks, and it holds
+// nothing between calls.
+func (l *ledger) add(address, token string, delta *big.Int) {
+	if address == models.TokenAddress || delta == nil || delta.Sign() == 0 {
+		return
+	}
+	k := balanceKey{address, token}
+	bal, ok := l.balances[k]
+	if !ok {
+		bal = new(big.Int)
+	}
+	wasHolder := bal.Sign() > 0
+	bal = new(big.Int).Add(bal, delta)
+	if bal.Sign() == 0 {
+		delete(l.balances, k)
+	} else {
+		l.balances[k] = bal
+	}
+	switch isHolder := bal.Sign() > 0; {
+	case isHolder && !wasHolder:
+		l.holders[token]++
+	case !isHolder && wasHolder:
+		l.holders[token]--
+	}
+}
+
+// balance returns address's balance in token; never nil.
+func (l *ledger) balance(address, token string) *big.Int {
+	if b, ok := l.balances[balanceKey{address, token}]; ok {
+		return b
+	}
+	return new(big.Int)
+}
+
+// rewind undoes what sp changed, taking the ledger from the end of sp's
+// range back to its start.
+func (l *ledger) rewind(sp *span) {
+	for _, c := range sp.balances {
+		l.add(c.Address, c.TokenStandard, new(big.Int).Neg(c.Amount))
+	}
+	l.totalTx -= sp.txCount
+	for token, n := range sp.byToken {
+		if _, ok := l.txs[token]; ok {
+			l.txs[token] -= n
+		}
+	}
+	for token, amount := range sp.mints {
+		if s, ok := l.supply[token]; ok {
+			l.supply[token] = new(big.Int).Sub(s, amount)
+		}
+	}
+	for token, amount := range sp.burns {
+		if s, ok := l.supply[token]; ok {
+			l.supply[token] = new(big.Int).Add(s, amount)
+		}
+	}
+}
+
+// issued reports whether token existed by endTs: it has an account
+// block before then. Tokens with no block at all are never counted.
+func (l *ledger) issued(token string, endTs int64) bool {
+	first := l.firstSeen[token]
+	return first > 0 && first < endTs
+}
+
+// tokenCounts returns how many tokens existed by endTs, and how many of
+// them appeared in [startTs, endTs).
+func (l *ledger) tokenCounts(startTs, endTs int64) (total, daily int64) {
+	for _, token := range l.tokens {
+		if !l.issued(token, endTs) {
+			continue
+		}
+		total++
+		if l.firstSeen[token] >= startTs {
+			daily++
+		}
+	}
+	return total, daily
+}
+
+// tokenStats returns the token rows of date, whose day ends at endTs,
+// with sp the day's changes.
+func (l *ledger) tokenStats(date string, endTs int64, sp *span) []*models.TokenStatHistory {
+	var out []*models.TokenStatHistory
+	for _, token := range l.tokens {
+		if !l.issued(token, endTs) {
+			continue
+		}
+		out = append(out, &models.TokenStatHistory{
+			Date:              date,
+			TokenStandard:     token,
+			DailyMinted:       orZero(sp.mints[token]),
+			DailyBurned:       orZero(sp.burns[token]),
+			TotalSupply:       new(big.Int).Set(l.supply[token]),
+			TotalHolders:      l.holders[token],
+			TotalTransactions: l.txs[token],
+		})
+	}
+	return out
+}
+
+// pillarStats returns the pillar rows of date, whose day ends at endTs:
+// one per pillar active then, weighted by the ZNN balance of the
+// addresses delegating to it and ranked by weight from 0, like the
+// node's pillar list.
+func (l *ledger) pillarStats(date string, endTs int64, spans []*repository.PillarSpan, delegations []*models.Delegation) []*models.PillarStatHistory {
+	rows := map[string]*models.PillarStatHistory{}
+	var out []*models.PillarStatHistory
+	for _, p := range spans {
+		if p.SpawnedAt >= endTs || (p.RevokedAt != 0 && p.RevokedAt < endTs) {
+			continue
+		}
+		row := &models.PillarStatHistory{Date: date, PillarOwnerAddress: p.OwnerAddress}
+		rows[p.OwnerAddress] = row
+		out = append(out, row)
+	}
+	for _, d := range delegations {
+		row := rows[d.PillarOwnerAddress]
+		if row == nil || d.StartedAt >= endTs || (d.EndedAt != nil && *d.EndedAt < endTs) {
+			continue
+		}
+		row.TotalDelegators++
+		row.Weight += l.balance(d.DelegatorAddress, models.ZnnTokenStandard).Int64()
+	}
+	slices.SortStableFunc(out, func(a, b *models.PillarStatHistory) int {
+		if c := cmp.Compare(b.Weight, a.Weight); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.PillarOwnerAddress, b.PillarOwnerAddress)
+	})
+	for rank, row := range out {
+		row.Rank = rank
+	}
+	return out
+}
+
+func orZero(v *big.Int) *big.Int {
+	if v == nil {
+		return new(big.Int)
+	}
+	return v
+}
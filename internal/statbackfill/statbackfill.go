@@ -0,0 +1,220 @@
+package statbackfill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+	"github.com/0x3639/nom-indexer-go/internal/repository"
+)
+
+// DateLayout is the layout of the dates Options and Report carry, and of
+// the stat tables' date column.
+const DateLayout = "2006-01-02"
+
+// logEvery is how many days pass between progress log lines.
+const logEvery = 30
+
+// Options bounds a run. The zero value rebuilds every day from the
+// first indexed momentum through the last fully indexed day.
+type Options struct {
+	// From and To are the first and last day rebuilt, inclusive; only
+	// their UTC date counts. Zero From means the day of the first
+	// indexed momentum; zero To means the last day before both today
+	// and the day of the highest indexed momentum.
+	From, To time.Time
+}
+
+// Validate reports whether o is usable.
+func (o Options) Validate() error {
+	if !o.From.IsZero() && !o.To.IsZero() && day(o.From).After(day(o.To)) {
+		return fmt.Errorf("from %s is after to %s", day(o.From).Format(DateLayout), day(o.To).Format(DateLayout))
+	}
+	return nil
+}
+
+// Report is the outcome of a run.
+type Report struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Height is the indexed momentum the ledger was rewound from.
+	Height uint64 `json:"height"`
+	// Days counts the days rewritten, newest first; an error leaves the
+	// newer ones written.
+	Days int `json:"days"`
+}
+
+// store is the database side of a run.
+// *repository.StatHistoryRepository implements it.
+type store interface {
+	LedgerAt(ctx context.Context) (*repository.StatLedger, error)
+	HeightsBetween(ctx context.Context, startTs, endTs int64) (from, to uint64, ok bool, err error)
+	BalanceChanges(ctx context.Context, from, to uint64) ([]*repository.BalanceChange, error)
+	BlockCounts(ctx context.Context, from, to uint64) (int64, map[string]int64, error)
+	MintsBurns(ctx context.Context, from, to uint64) (mints, burns map[string]*big.Int, err error)
+	NetworkStatAsOf(ctx context.Context, date string, startTs, endTs int64) (*models.NetworkStatHistory, error)
+	BridgeStatsForDay(ctx context.Context, date string, startTs, endTs int64) ([]*models.BridgeStatHistory, error)
+	PillarSpans(ctx context.Context) ([]*repository.PillarSpan, error)
+	Delegations(ctx context.Context) ([]*models.Delegation, error)
+	ReplaceDay(ctx context.Context, d *repository.StatDay) error
+}
+
+// Runner rebuilds the stat-history rows of a range of days.
+type Runner struct {
+	opts   Options
+	logger *zap.Logger
+	store  store
+	now    func() time.Time
+}
+
+// NewRunner builds a Runner over pool. opts must have passed Validate.
+func NewRunner(pool *pgxpool.Pool, logger *zap.Logger, opts Options) *Runner {
+	return &Runner{
+		opts:   opts,
+		logger: logger,
+		store:  repository.NewStatHistoryRepository(pool),
+		now:    time.Now,
+	}
+}
+
+// span is what the momentums of a height range changed.
+type span struct {
+	balances []*repository.BalanceChange
+	txCount  int64
+	byToken  map[string]int64
+	mints    map[string]*big.Int
+	burns    map[string]*big.Int
+}
+
+// Run rewrites every day of the range, newest first, each in one
+// transaction.
+func (r *Runner) Run(ctx context.Context) (*Report, error) {
+	snap, err := r.store.LedgerAt(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if snap.Height == 0 {
+		return nil, errors.New("no momentum is indexed")
+	}
+
+	last := day(time.Unix(snap.HeadTimestamp, 0)).AddDate(0, 0, -1)
+	if yesterday := day(r.now()).AddDate(0, 0, -1); last.After(yesterday) {
+		last = yesterday
+	}
+	to := last
+	if !r.opts.To.IsZero() {
+		to = day(r.opts.To)
+		if to.After(last) {
+			return nil, fmt.Errorf("%s is not fully indexed; the last complete day is %s",
+				to.Format(DateLayout), last.Format(DateLayout))
+		}
+	}
+	from := day(time.Unix(snap.GenesisTimestamp, 0))
+	if !r.opts.From.IsZero() {
+		from = day(r.opts.From)
+	}
+	report := &Report{From: from.Format(DateLayout), To: to.Format(DateLayout), Height: snap.Height}
+	if from.After(to) {
+		return report, nil
+	}
+
+	spans, err := r.store.PillarSpans(ctx)
+	if err != nil {
+		return report, err
+	}
+	delegations, err := r.store.Delegations(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	// Rewind from the head to the end of the last day rebuilt.
+	l := newLedger(snap)
+	sp, err := r.readSpan(ctx, snap.Height, to.AddDate(0, 0, 1).Unix(), math.MaxInt64)
+	if err != nil {
+		return report, fmt.Errorf("rewind to %s: %w", to.Format(DateLayout), err)
+	}
+	l.rewind(sp)
+
+	for d := to; !d.Before(from); d = d.AddDate(0, 0, -1) {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		date := d.Format(DateLayout)
+		if err := r.runDay(ctx, l, snap.Height, d, spans, delegations); err != nil {
+			return report, fmt.Errorf("%s: %w", date, err)
+		}
+		report.Days++
+		if report.Days%logEvery == 0 {
+			r.logger.Info("stat backfill: progress", zap.String("date", date), zap.Int("days", report.Days))
+		}
+	}
+	return report, nil
+}
+
+// runDay writes the rows of day d from l, which holds the state at the
+// end of d, then rewinds l to the end of the day before.
+func (r *Runner) runDay(ctx context.Context, l *ledger, head uint64, d time.Time, spans []*repository.PillarSpan, delegations []*models.Delegation) error {
+	date := d.Format(DateLayout)
+	startTs, endTs := d.Unix(), d.AddDate(0, 0, 1).Unix()
+	sp, err := r.readSpan(ctx, head, startTs, endTs)
+	if err != nil {
+		return err
+	}
+
+	network, err := r.store.NetworkStatAsOf(ctx, date, startTs, endTs)
+	if err != nil {
+		return err
+	}
+	network.TotalTx, network.DailyTx = l.totalTx, sp.txCount
+	network.TotalTokens, network.DailyTokens = l.tokenCounts(startTs, endTs)
+	bridges, err := r.store.BridgeStatsForDay(ctx, date, startTs, endTs)
+	if err != nil {
+		return err
+	}
+	err = r.store.ReplaceDay(ctx, &repository.StatDay{
+		Date:    date,
+		Network: network,
+		Tokens:  l.tokenStats(date, endTs, sp),
+		Pillars: l.pillarStats(date, endTs, spans, delegations),
+		Bridges: bridges,
+	})
+	if err != nil {
+		return err
+	}
+	l.rewind(sp)
+	return nil
+}
+
+// readSpan reads what the momentums in [startTs, endTs), up to head,
+// changed. No momentum in range reads as no change.
+func (r *Runner) readSpan(ctx context.Context, head uint64, startTs, endTs int64) (*span, error) {
+	sp := &span{}
+	from, to, ok, err := r.store.HeightsBetween(ctx, startTs, endTs)
+	if err != nil || !ok || from > head {
+		return sp, err
+	}
+	to = min(to, head)
+	if sp.balances, err = r.store.BalanceChanges(ctx, from, to); err != nil {
+		return nil, err
+	}
+	if sp.txCount, sp.byToken, err = r.store.BlockCounts(ctx, from, to); err != nil {
+		return nil, err
+	}
+	if sp.mints, sp.burns, err = r.store.MintsBurns(ctx, from, to); err != nil {
+		return nil, err
+	}
+	return sp, nil
+}
+
+// day truncates t to the start of its UTC date.
+func day(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,348 @@
+package statbackfill
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+	"github.com/0x3639/nom-indexer-go/internal/repository"
+)
+
+// fakeMomentum is what one momentum of the fake chain changed.
+type fakeMomentum struct {
+	timestamp int64
+	changes   []*repository.BalanceChange
+	byToken   map[string]int64
+	txCount   int64
+	mints     map[string]*big.Int
+	burns     map[string]*big.Int
+}
+
+// fakeStore serves a Runner from an in-memory chain whose momentum at
+// height h is chain[h-1], and records the days written.
+type fakeStore struct {
+	ledger      *repository.StatLedger
+	chain       []fakeMomentum
+	spans       []*repository.PillarSpan
+	delegations []*models.Delegation
+	written     map[string]*repository.StatDay
+	order       []string
+}
+
+func (f *fakeStore) LedgerAt(context.Context) (*repository.StatLedger, error) {
+	return f.ledger, nil
+}
+
+func (f *fakeStore) HeightsBetween(_ context.Context, startTs, endTs int64) (from, to uint64, ok bool, err error) {
+	for i, m := range f.chain {
+		if m.timestamp < startTs || m.timestamp >= endTs {
+			continue
+		}
+		h := uint64(i + 1)
+		if !ok {
+			from = h
+		}
+		to, ok = h, true
+	}
+	return from, to, ok, nil
+}
+
+func (f *fakeStore) each(from, to uint64, fn func(m fakeMomentum)) {
+	for h := from; h <= to; h++ {
+		fn(f.chain[h-1])
+	}
+}
+
+func (f *fakeStore) BalanceChanges(_ context.Context, from, to uint64) ([]*repository.BalanceChange, error) {
+	var out []*repository.BalanceChange
+	f.each(from, to, func(m fakeMomentum) { out = append(out, m.changes...) })
+	return out, nil
+}
+
+func (f *fakeStore) BlockCounts(_ context.Context, from, to uint64) (int64, map[string]int64, error) {
+	var n int64
+	byToken := map[string]int64{}
+	f.each(from, to, func(m fakeMomentum) {
+		n += m.txCount
+		for token, c := range m.byToken {
+			byToken[token] += c
+		}
+	})
+	return n, byToken, nil
+}
+
+func (f *fakeStore) MintsBurns(_ context.Context, from, to uint64) (map[string]*big.Int, map[string]*big.Int, error) {
+	mints, burns := map[string]*big.Int{}, map[string]*big.Int{}
+	sum := func(into, from map[string]*big.Int) {
+		for token, v := range from {
+			into[token] = new(big.Int).Add(orZero(into[token]), v)
+		}
+	}
+	f.each(from, to, func(m fakeMomentum) {
+		sum(mints, m.mints)
+		sum(burns, m.burns)
+	})
+	return mints, burns, nil
+}
+
+func (f *fakeStore) NetworkStatAsOf(_ context.Context, date string, _, _ int64) (*models.NetworkStatHistory, error) {
+	return &models.NetworkStatHistory{Date: date, TotalPillars: 7}, nil
+}
+
+func (f *fakeStore) BridgeStatsForDay(context.Context, string, int64, int64) ([]*models.BridgeStatHistory, error) {
+	return nil, nil
+}
+
+func (f *fakeStore) PillarSpans(context.Context) ([]*repository.PillarSpan, error) {
+	return f.spans, nil
+}
+
+func (f *fakeStore) Delegations(context.Context) ([]*models.Delegation, error) {
+	return f.delegations, nil
+}
+
+func (f *fakeStore) ReplaceDay(_ context.Context, d *repository.StatDay) error {
+	if f.written == nil {
+		f.written = map[string]*repository.StatDay{}
+	}
+	f.written[d.Date] = d
+	f.order = append(f.order, d.Date)
+	return nil
+}
+
+const (
+	addrA, addrB, addrC = "z1qa", "z1qb", "z1qc"
+	pillar1, pillar2    = "z1qp1", "z1qp2"
+	pillar3             = "z1qp3"
+	customToken         = "zts1custom"
+)
+
+var (
+	day1 = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	day2 = day1.AddDate(0, 0, 1)
+	day3 = day1.AddDate(0, 0, 2)
+)
+
+func noon(d time.Time) int64 { return d.Add(12 * time.Hour).Unix() }
+
+// newFakeStore builds a three-day chain, one momentum a day, indexed up
+// to noon of day 3:
+//
+//	day 1: A receives 100 ZNN
+//	day 2: A receives 100 ZNN, B 50 ZNN; 500 zts1custom minted to C
+//	day 3: A receives 100 ZNN; 10 ZNN burned
+func newFakeStore() *fakeStore {
+	ended := noon(day2)
+	return &fakeStore{
+		ledger: &repository.StatLedger{
+			Height:           3,
+			HeadTimestamp:    noon(day3),
+			GenesisTimestamp: noon(day1),
+			TotalTx:          4,
+			Balances: []*models.Balance{
+				{Address: addrA, TokenStandard: models.ZnnTokenStandard, Balance: big.NewInt(300)},
+				{Address: addrB, TokenStandard: models.ZnnTokenStandard, Balance: big.NewInt(50)},
+				{Address: addrC, TokenStandard: customToken, Balance: big.NewInt(500)},
+				{Address: models.TokenAddress, TokenStandard: customToken, Balance: big.NewInt(1)},
+			},
+			Tokens: []*repository.StatToken{
+				{TokenStandard: models.ZnnTokenStandard, TotalSupply: big.NewInt(1000), Transactions: 3, FirstSeen: noon(day1)},
+				{TokenStandard: customToken, TotalSupply: big.NewInt(500), Transactions: 1, FirstSeen: noon(day2)},
+			},
+		},
+		chain: []fakeMomentum{
+			{
+				timestamp: noon(day1),
+				changes:   []*repository.BalanceChange{{Address: addrA, TokenStandard: models.ZnnTokenStandard, Amount: big.NewInt(100)}},
+				txCount:   1,
+				byToken:   map[string]int64{models.ZnnTokenStandard: 1},
+			},
+			{
+				timestamp: noon(day2),
+				changes: []*repository.BalanceChange{
+					{Address: addrA, TokenStandard: models.ZnnTokenStandard, Amount: big.NewInt(100)},
+					{Address: addrB, TokenStandard: models.ZnnTokenStandard, Amount: big.NewInt(50)},
+					{Address: addrC, TokenStandard: customToken, Amount: big.NewInt(500)},
+				},
+				txCount: 2,
+				byToken: map[string]int64{models.ZnnTokenStandard: 1, customToken: 1},
+				mints:   map[string]*big.Int{customToken: big.NewInt(500)},
+			},
+			{
+				timestamp: noon(day3),
+				changes:   []*repository.BalanceChange{{Address: addrA, TokenStandard: models.ZnnTokenStandard, Amount: big.NewInt(100)}},
+				txCount:   1,
+				byToken:   map[string]int64{models.ZnnTokenStandard: 1},
+				burns:     map[string]*big.Int{models.ZnnTokenStandard: big.NewInt(10)},
+			},
+		},
+		spans: []*repository.PillarSpan{
+			{OwnerAddress: pillar1, SpawnedAt: day1.Unix()},
+			{OwnerAddress: pillar2, SpawnedAt: noon(day2)},
+			{OwnerAddress: pillar3, SpawnedAt: day1.Unix(), RevokedAt: noon(day2)},
+		},
+		delegations: []*models.Delegation{
+			{DelegatorAddress: addrB, PillarOwnerAddress: pillar1, StartedAt: noon(day1)},
+			{DelegatorAddress: addrA, PillarOwnerAddress: pillar1, StartedAt: noon(day1), EndedAt: &ended},
+			{DelegatorAddress: addrA, PillarOwnerAddress: pillar2, StartedAt: noon(day2)},
+		},
+	}
+}
+
+func newTestRunner(f *fakeStore, opts Options) *Runner {
+	return &Runner{
+		opts:   opts,
+		logger: zap.NewNop(),
+		store:  f,
+		now:    func() time.Time { return day3.AddDate(0, 0, 5) },
+	}
+}
+
+func TestRun_RewindsEachDay(t *testing.T) {
+	f := newFakeStore()
+	report, err := newTestRunner(f, Options{}).Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	want := &Report{From: "2023-01-01", To: "2023-01-02", Height: 3, Days: 2}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("report = %+v, want %+v", report, want)
+	}
+	if !reflect.DeepEqual(f.order, []string{"2023-01-02", "2023-01-01"}) {
+		t.Errorf("days written in order %v, want newest first", f.order)
+	}
+
+	d2 := f.written["2023-01-02"]
+	if n := d2.Network; n.TotalTx != 3 || n.DailyTx != 2 || n.TotalTokens != 2 || n.DailyTokens != 1 || n.TotalPillars != 7 {
+		t.Errorf("day 2 network = %+v", n)
+	}
+	assertTokens(t, "day 2", d2.Tokens, []*models.TokenStatHistory{
+		{Date: "2023-01-02", TokenStandard: models.ZnnTokenStandard, DailyMinted: big.NewInt(0), DailyBurned: big.NewInt(0),
+			TotalSupply: big.NewInt(1010), TotalHolders: 2, TotalTransactions: 2},
+		{Date: "2023-01-02", TokenStandard: customToken, DailyMinted: big.NewInt(500), DailyBurned: big.NewInt(0),
+			TotalSupply: big.NewInt(500), TotalHolders: 1, TotalTransactions: 1},
+	})
+	assertPillars(t, "day 2", d2.Pillars, []*models.PillarStatHistory{
+		{Date: "2023-01-02", PillarOwnerAddress: pillar2, Rank: 0, Weight: 200, TotalDelegators: 1},
+		{Date: "2023-01-02", PillarOwnerAddress: pillar1, Rank: 1, Weight: 50, TotalDelegators: 1},
+	})
+
+	d1 := f.written["2023-01-01"]
+	if n := d1.Network; n.TotalTx != 1 || n.DailyTx != 1 || n.TotalTokens != 1 || n.DailyTokens != 1 {
+		t.Errorf("day 1 network = %+v", n)
+	}
+	assertTokens(t, "day 1", d1.Tokens, []*models.TokenStatHistory{
+		{Date: "2023-01-01", TokenStandard: models.ZnnTokenStandard, DailyMinted: big.NewInt(0), DailyBurned: big.NewInt(0),
+			TotalSupply: big.NewInt(1010), TotalHolders: 1, TotalTransactions: 1},
+	})
+	assertPillars(t, "day 1", d1.Pillars, []*models.PillarStatHistory{
+		{Date: "2023-01-01", PillarOwnerAddress: pillar1, Rank: 0, Weight: 100, TotalDelegators: 2},
+		{Date: "2023-01-01", PillarOwnerAddress: pillar3, Rank: 1, Weight: 0, TotalDelegators: 0},
+	})
+}
+
+func TestRun_Range(t *testing.T) {
+	f := newFakeStore()
+	report, err := newTestRunner(f, Options{From: day2, To: day2}).Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if report.Days != 1 || !reflect.DeepEqual(f.order, []string{"2023-01-02"}) {
+		t.Errorf("report = %+v, written %v; want only 2023-01-02", report, f.order)
+	}
+	if got := f.written["2023-01-02"].Network.TotalTx; got != 3 {
+		t.Errorf("total_tx = %d, want 3", got)
+	}
+}
+
+func TestRun_FromAfterLastDayWritesNothing(t *testing.T) {
+	f := newFakeStore()
+	report, err := newTestRunner(f, Options{From: day3}).Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if report.Days != 0 || len(f.order) != 0 {
+		t.Errorf("report = %+v, written %v; want nothing", report, f.order)
+	}
+}
+
+func TestRun_RejectsIncompleteDay(t *testing.T) {
+	f := newFakeStore()
+	_, err := newTestRunner(f, Options{To: day3}).Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "not fully indexed") {
+		t.Fatalf("err = %v, want a not fully indexed error", err)
+	}
+	if len(f.order) != 0 {
+		t.Errorf("written %v, want nothing", f.order)
+	}
+}
+
+func TestRun_EmptyDatabase(t *testing.T) {
+	f := &fakeStore{ledger: &repository.StatLedger{}}
+	if _, err := newTestRunner(f, Options{}).Run(context.Background()); err == nil {
+		t.Fatal("expected an error with no momentum indexed")
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	if err := (Options{}).Validate(); err != nil {
+		t.Errorf("zero options: %v", err)
+	}
+	if err := (Options{From: day2, To: day2.Add(time.Hour)}).Validate(); err != nil {
+		t.Errorf("same day: %v", err)
+	}
+	if err := (Options{From: day3, To: day1}).Validate(); err == nil {
+		t.Error("from after to: expected an error")
+	}
+}
+
+func TestLedgerAdd_TracksHolders(t *testing.T) {
+	l := newLedger(&repository.StatLedger{})
+	l.add(addrA, customToken, big.NewInt(5))
+	l.add(addrB, customToken, big.NewInt(5))
+	l.add(models.TokenAddress, customToken, big.NewInt(5))
+	if got := l.holders[customToken]; got != 2 {
+		t.Fatalf("holders = %d, want 2 (the token contract is not counted)", got)
+	}
+	l.add(addrA, customToken, big.NewInt(-5))
+	if got := l.holders[customToken]; got != 1 {
+		t.Errorf("holders after emptying A = %d, want 1", got)
+	}
+	if got := l.balance(addrA, customToken); got.Sign() != 0 {
+		t.Errorf("balance of A = %s, want 0", got)
+	}
+}
+
+func assertTokens(t *testing.T, label string, got, want []*models.TokenStatHistory) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: %d token rows, want %d", label, len(got), len(want))
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.Date != w.Date || g.TokenStandard != w.TokenStandard ||
+			g.DailyMinted.Cmp(w.DailyMinted) != 0 || g.DailyBurned.Cmp(w.DailyBurned) != 0 ||
+			g.TotalSupply.Cmp(w.TotalSupply) != 0 || g.TotalHolders != w.TotalHolders ||
+			g.TotalTransactions != w.TotalTransactions {
+			t.Errorf("%s: token row %d = %+v, want %+v", label, i, g, w)
+		}
+	}
+}
+
+func assertPillars(t *testing.T, label string, got, want []*models.PillarStatHistory) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: %d pillar rows, want %d", label, len(got), len(want))
+	}
+	for i := range want {
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("%s: pillar row %d = %+v, want %+v", label, i, got[i], want[i])
+		}
+	}
+}
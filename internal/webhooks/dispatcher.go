@@ -1,132 +1,421 @@
-// Package webhooks delivers fire-and-forget event notifications to configured
-// HTTP endpoints. Delivery is best-effort: failures are retried a bounded
-// number of times and then dropped (with a log line). It never blocks the
-// indexer's sync loop.
+// Package webhooks delivers event notifications to configured HTTP
+// endpoints from a durable outbox. The indexer writes outbox rows in the
+// same transaction as the momentum that produced them (see Outbox); the
+// Dispatcher claims due rows and hands each to its endpoint's worker,
+// which retries failures with exponential backoff and jitter, records
+// every attempt, and dead-letters rows that exhaust their retries. Every
+// endpoint has its own queue, worker and circuit breaker, so a slow or
+// dead endpoint does not hold up the others. Delivery is at-least-once
+// and never blocks the indexer's sync loop.
+//
+// Deliveries are signed with the endpoint's secret over a timestamp and
+// the body (see pkg/webhookverify), and carry a delivery id that stays
+// the same across retries so subscribers can drop duplicates.
+//
+// Endpoints come from two places: the static list in config.yaml, and
+// subscriptions registered at runtime through the API, which the
+// Dispatcher reloads from the database every Config.ReloadInterval.
 package webhooks
 
 import (
 	"bytes"
 	"context"
-	"crypto/hmac"
-	"crypto/sha256"
+	crand "crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
 	"net/http"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+	"github.com/0x3639/nom-indexer-go/pkg/webhookverify"
 )
 
-// Event is one notification.
+// Event is one notification. Payload is one of the payload types in
+// events.go (or any value that marshals to the same JSON).
 type Event struct {
-	Type    string         `json:"type"`
-	Payload map[string]any `json:"payload"`
+	Type    string `json:"type"`
+	Payload any    `json:"payload"`
 }
 
 // Endpoint is a subscriber.
 type Endpoint struct {
 	URL    string
 	Secret string
-	Events []string // empty = all
+	// PreviousSecret, when set, signs deliveries alongside Secret while
+	// a rotation is under way: until PreviousSecretUntil (unix seconds),
+	// or indefinitely when that is 0.
+	PreviousSecret      string
+	PreviousSecretUntil int64
+	Events              []string // empty = all
+	// Filter narrows Events by content (address, token, method, block
+	// type, amount); see filter.go.
+	Filter models.WebhookFilter
+	// SubscriptionID is the webhook_subscriptions row this endpoint was
+	// loaded from; 0 for endpoints from config.yaml.
+	SubscriptionID int64
+}
+
+// Store is the durable outbox the Dispatcher drains.
+// repository.WebhookOutboxRepository implements it.
+type Store interface {
+	ClaimDue(ctx context.Context, now, leaseUntil int64, limit int) ([]*models.WebhookOutboxEntry, error)
+	Complete(ctx context.Context, a *models.WebhookDeliveryAttempt, status string, nextAttemptAt int64) error
+	Defer(ctx context.Context, ids []int64, until int64) error
+	PruneDelivered(ctx context.Context, before int64) (int64, error)
+}
+
+// Subscriptions lists runtime-managed subscriptions, active and paused.
+// repository.WebhookSubscriptionRepository implements it.
+type Subscriptions interface {
+	ListAll(ctx context.Context) ([]*models.WebhookSubscription, error)
+}
+
+// Config tunes delivery. Zero fields fall back to the defaults noted on
+// each field.
+type Config struct {
+	// Timeout is the per-request HTTP timeout (default 5s).
+	Timeout time.Duration
+	// MaxRetries is how many times a failed delivery is retried before
+	// the row is dead-lettered. Zero means one attempt only.
+	MaxRetries int
+	// BackoffBase is the delay before the first retry; each further retry
+	// doubles it (default 2s).
+	BackoffBase time.Duration
+	// BackoffMax caps the retry delay (default 1h).
+	BackoffMax time.Duration
+	// Retention is how long delivered rows are kept before pruning
+	// (default 7 days). Dead rows are kept until replayed.
+	Retention time.Duration
+	// PollInterval is how often the worker looks for due rows when it
+	// has not been woken by Notify (default 1s).
+	PollInterval time.Duration
+	// ReloadInterval is how often subscriptions are reloaded from the
+	// database (default 5s).
+	ReloadInterval time.Duration
+	// CircuitThreshold is how many consecutive failed deliveries open an
+	// endpoint's circuit (default 5).
+	CircuitThreshold int
+	// CircuitCooldown is how long an open circuit sets the endpoint's
+	// rows aside before one delivery probes it again (default 1m).
+	CircuitCooldown time.Duration
+	// Observer receives per-endpoint metrics; nil records none.
+	Observer Observer
+}
+
+func (c Config) withDefaults() Config {
+	if c.Timeout <= 0 {
+		c.Timeout = 5 * time.Second
+	}
+	if c.BackoffBase <= 0 {
+		c.BackoffBase = 2 * time.Second
+	}
+	if c.BackoffMax <= 0 {
+		c.BackoffMax = time.Hour
+	}
+	if c.Retention <= 0 {
+		c.Retention = 7 * 24 * time.Hour
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = time.Second
+	}
+	if c.ReloadInterval <= 0 {
+		c.ReloadInterval = 5 * time.Second
+	}
+	if c.CircuitThreshold <= 0 {
+		c.CircuitThreshold = 5
+	}
+	if c.CircuitCooldown <= 0 {
+		c.CircuitCooldown = time.Minute
+	}
+	if c.Observer == nil {
+		c.Observer = nopObserver{}
+	}
+	return c
 }
 
-// Dispatcher fans events out to endpoints via a buffered queue + worker.
+// claimBatch is how many due rows are leased per round trip.
+const claimBatch = 32
+
+// pruneEvery is how often delivered rows past Retention are deleted.
+const pruneEvery = time.Hour
+
+// routes is an immutable snapshot of every endpoint the Dispatcher knows.
+// It is swapped atomically on reload, so Outbox (called from the
+// indexer's commit path) never waits on the database.
+type routes struct {
+	// active is every endpoint new events are routed to, in order:
+	// config endpoints, then active subscriptions.
+	active []route
+	// byURL holds config endpoints; byID holds every subscription,
+	// paused ones included.
+	byURL  map[string]Endpoint
+	byID   map[int64]Endpoint
+	paused map[int64]bool
+}
+
+// route is an endpoint with its compiled content filter.
+type route struct {
+	Endpoint
+	filter *matcher
+}
+
+// Dispatcher routes events to endpoints (Outbox) and runs the claim loop
+// and per-endpoint workers that drain the Store.
 type Dispatcher struct {
-	endpoints  []Endpoint
-	timeout    time.Duration
-	maxRetries int
-	logger     *zap.Logger
-	queue      chan Event
-	client     *http.Client
-	done       chan struct{}
-	quit       chan struct{}
-	closed     atomic.Bool
-	startOnce  sync.Once
-	stopOnce   sync.Once
-}
-
-// New builds a Dispatcher. logger may be nil (a no-op logger is used).
-func New(endpoints []Endpoint, timeout time.Duration, maxRetries int, logger *zap.Logger) *Dispatcher {
+	static []Endpoint
+	subs   Subscriptions
+	routes atomic.Pointer[routes]
+	store  Store
+	cfg    Config
+	logger *zap.Logger
+	client *http.Client
+	// subClient delivers to runtime subscriptions; it refuses
+	// non-public addresses (see ErrPrivateTarget).
+	subClient *http.Client
+
+	now   func() time.Time
+	float func() float64
+
+	// workers is keyed by endpoint label. mu also orders queue sends
+	// against closing a retired worker's queue.
+	mu      sync.Mutex
+	workers map[string]*endpointWorker
+	wg      sync.WaitGroup
+
+	wake      chan struct{}
+	done      chan struct{}
+	quit      chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+// New builds a Dispatcher for the static endpoints plus, when subs is
+// non-nil, every subscription it lists. logger may be nil (a no-op logger
+// is used).
+func New(endpoints []Endpoint, store Store, subs Subscriptions, cfg Config, logger *zap.Logger) *Dispatcher {
 	if logger == nil {
 		logger = zap.NewNop()
 	}
-	return &Dispatcher{
-		endpoints:  endpoints,
-		timeout:    timeout,
-		maxRetries: maxRetries,
-		logger:     logger,
-		queue:      make(chan Event, 1024),
-		client:     &http.Client{Timeout: timeout},
-		done:       make(chan struct{}),
-		quit:       make(chan struct{}),
+	cfg = cfg.withDefaults()
+	d := &Dispatcher{
+		static:    endpoints,
+		subs:      subs,
+		store:     store,
+		cfg:       cfg,
+		logger:    logger,
+		client:    &http.Client{Timeout: cfg.Timeout, CheckRedirect: noRedirects},
+		subClient: subscriptionClient(cfg.Timeout),
+		now:       time.Now,
+		float:     rand.Float64,
+		workers:   make(map[string]*endpointWorker),
+		wake:      make(chan struct{}, 1),
+		done:      make(chan struct{}),
+		quit:      make(chan struct{}),
 	}
+	d.routes.Store(buildRoutes(endpoints, nil, logger))
+	return d
 }
 
-// Start launches the delivery worker. Idempotent: calling it more than once
-// spawns at most one worker.
+// Start loads subscriptions and launches the claim loop. Endpoint
+// workers are started as rows for them are claimed. The first load
+// happens before Start returns, so events committed right after startup
+// already reach runtime subscriptions. Idempotent: calling it more than
+// once spawns at most one claim loop.
 func (d *Dispatcher) Start() {
-	d.startOnce.Do(func() { go d.run() })
-}
-
-// Stop signals the worker to shut down and blocks until it has returned.
-//
-// Shutdown is best-effort: an in-flight delivery (including its bounded retry
-// loop) is allowed to finish, but any events still buffered in the queue that
-// have not yet been picked up are dropped — consistent with the drop-on-full
-// contract. The queue itself is never closed; shutdown is signaled via the
-// quit channel plus an atomic flag so a racing Emit can never send on a closed
-// channel.
-//
-// Stop is idempotent and safe to call concurrently: subsequent calls are
-// no-ops that simply wait for the (already-closed) done channel.
-func (d *Dispatcher) Stop() {
-	d.stopOnce.Do(func() {
-		d.closed.Store(true)
-		close(d.quit)
+	d.startOnce.Do(func() {
+		d.reload()
+		go d.run()
 	})
-	<-d.done
 }
 
-// Emit enqueues an event. Non-blocking: if the queue is full the event is
-// dropped with a warning (back-pressure must never stall the indexer). After
-// Stop has been called Emit is a harmless no-op.
-func (d *Dispatcher) Emit(e Event) {
-	if d.closed.Load() {
+// reload rebuilds the routing snapshot from the static endpoints and the
+// current subscriptions, and retires the workers of deleted ones. On
+// error the previous snapshot stays in place.
+func (d *Dispatcher) reload() {
+	if d.subs == nil {
 		return
 	}
-	select {
-	case d.queue <- e:
-	default:
-		d.logger.Warn("webhook queue full; dropping event", zap.String("type", e.Type))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	subs, err := d.subs.ListAll(ctx)
+	if err != nil {
+		d.logger.Warn("webhook subscription reload failed", zap.Error(err))
+		return
+	}
+	r := buildRoutes(d.static, subs, d.logger)
+	d.routes.Store(r)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for label, w := range d.workers {
+		if !r.knows(w.subscriptionID, w.url) {
+			// The worker finishes what is already queued (those rows are
+			// skipped: their subscription is gone) and exits.
+			close(w.queue)
+			delete(d.workers, label)
+			d.cfg.Observer.ForgetWebhookEndpoint(label)
+		}
 	}
 }
 
-func (d *Dispatcher) run() {
-	defer close(d.done)
-	for {
-		select {
-		case <-d.quit:
+// buildRoutes compiles each endpoint's filter. An endpoint whose filter
+// does not compile (validated on the way in, so only possible for rows
+// edited by hand) receives no new events until it is fixed; rows already
+// queued for it are still delivered.
+func buildRoutes(static []Endpoint, subs []*models.WebhookSubscription, logger *zap.Logger) *routes {
+	r := &routes{
+		active: make([]route, 0, len(static)+len(subs)),
+		byURL:  make(map[string]Endpoint, len(static)),
+		byID:   make(map[int64]Endpoint, len(subs)),
+		paused: make(map[int64]bool),
+	}
+	add := func(ep Endpoint) {
+		m, err := compileFilter(ep.Filter)
+		if err != nil {
+			logger.Warn("webhook endpoint has an invalid filter; not routing events to it",
+				zap.String("url", ep.URL), zap.Int64("subscription_id", ep.SubscriptionID), zap.Error(err))
 			return
-		case e := <-d.queue:
-			d.dispatch(e)
 		}
+		r.active = append(r.active, route{Endpoint: ep, filter: m})
+	}
+	for _, ep := range static {
+		r.byURL[ep.URL] = ep
+		add(ep)
+	}
+	for _, s := range subs {
+		ep := Endpoint{URL: s.URL, Secret: s.Secret, Events: s.Events, Filter: s.Filter, SubscriptionID: s.ID}
+		if s.PreviousSecret != "" && s.PreviousSecretExpiresAt > 0 {
+			ep.PreviousSecret, ep.PreviousSecretUntil = s.PreviousSecret, s.PreviousSecretExpiresAt
+		}
+		r.byID[s.ID] = ep
+		if s.Status == models.WebhookSubscriptionPaused {
+			r.paused[s.ID] = true
+			continue
+		}
+		add(ep)
 	}
+	return r
 }
 
-func (d *Dispatcher) dispatch(e Event) {
-	body, err := json.Marshal(e)
-	if err != nil {
-		d.logger.Warn("webhook marshal failed", zap.Error(err))
-		return
+// knows reports whether the endpoint still exists: a subscription by id
+// (paused or not), a config endpoint by URL.
+func (r *routes) knows(subscriptionID int64, url string) bool {
+	if subscriptionID != 0 {
+		_, ok := r.byID[subscriptionID]
+		return ok
+	}
+	_, ok := r.byURL[url]
+	return ok
+}
+
+// resolve finds the endpoint an outbox row belongs to. ok is false when
+// it no longer exists; paused is true for a paused subscription.
+func (r *routes) resolve(e *models.WebhookOutboxEntry) (ep Endpoint, ok, paused bool) {
+	if e.SubscriptionID == nil {
+		ep, ok = r.byURL[e.EndpointURL]
+		return ep, ok, false
+	}
+	ep, ok = r.byID[*e.SubscriptionID]
+	return ep, ok, r.paused[*e.SubscriptionID]
+}
+
+// Stop signals the claim loop and every endpoint worker to shut down and
+// blocks until they have returned. In-flight deliveries are allowed to
+// finish. Rows not yet delivered, queued ones included, stay in the
+// outbox and are picked up by the next process once their lease lapses.
+//
+// Stop is idempotent and safe to call concurrently. Calling Stop on a
+// Dispatcher that was never started returns immediately.
+func (d *Dispatcher) Stop() {
+	started := true
+	d.startOnce.Do(func() { started = false })
+	d.stopOnce.Do(func() { close(d.quit) })
+	if started {
+		<-d.done
 	}
-	for _, ep := range d.endpoints {
-		if !d.wants(ep, e.Type) {
+}
+
+// Notify wakes the claim loop so newly committed rows are delivered without
+// waiting for the next poll. Non-blocking; safe after Stop.
+func (d *Dispatcher) Notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Outbox returns one pending outbox row per endpoint subscribed to e
+// whose content filter it passes, for the caller to insert in the same
+// transaction as the data e describes. Returns nil when no endpoint
+// wants the event. Reorg events skip content filters: every subscriber
+// needs them to unwind what it was sent.
+func (d *Dispatcher) Outbox(e Event, momentumHeight uint64, now time.Time) ([]*models.WebhookOutboxEntry, error) {
+	var out []*models.WebhookOutboxEntry
+	var payload []byte
+	var attrs *attributes
+	for _, ep := range d.routes.Load().active {
+		if !d.wants(ep.Endpoint, e.Type) {
 			continue
 		}
-		d.deliver(ep, e.Type, body)
+		if ep.filter != nil && e.Type != EventReorg {
+			if attrs == nil {
+				a := eventAttributes(e)
+				attrs = &a
+			}
+			if !ep.filter.match(*attrs) {
+				continue
+			}
+		}
+		if payload == nil {
+			p, err := json.Marshal(e.Payload)
+			if err != nil {
+				return nil, fmt.Errorf("marshal %s payload: %w", e.Type, err)
+			}
+			payload = p
+		}
+		var subID *int64
+		if ep.SubscriptionID != 0 {
+			subID = &ep.SubscriptionID
+		}
+		out = append(out, &models.WebhookOutboxEntry{
+			DeliveryID:     deliveryID(ep.Endpoint, e.Type, momentumHeight, payload),
+			SubscriptionID: subID,
+			EndpointURL:    ep.URL,
+			EventType:      e.Type,
+			Payload:        payload,
+			MomentumHeight: int64(momentumHeight),
+			NextAttemptAt:  now.Unix(),
+			CreatedAt:      now.Unix(),
+		})
 	}
+	return out, nil
+}
+
+// deliveryNamespace is the UUIDv5 namespace for outbox delivery ids.
+var deliveryNamespace = uuid.MustParse("6f0c3b5e-2d4a-5c1e-9b7f-8a3d2e1c4b60")
+
+// deliveryID derives an entry's delivery id from the endpoint, the event
+// and the momentum it belongs to. The payload carries the momentum or
+// account block hash, so re-processing a height (backfill --reprocess,
+// a retried commit) yields the same id and the outbox insert skips the
+// duplicate instead of delivering the event again.
+func deliveryID(ep Endpoint, eventType string, momentumHeight uint64, payload []byte) string {
+	key := "url:" + ep.URL
+	if ep.SubscriptionID != 0 {
+		key = "sub:" + strconv.FormatInt(ep.SubscriptionID, 10)
+	}
+	data := fmt.Appendf(nil, "%s\x00%s\x00%d\x00", key, eventType, momentumHeight)
+	return uuid.NewSHA1(deliveryNamespace, append(data, payload...)).String()
 }
 
 func (d *Dispatcher) wants(ep Endpoint, eventType string) bool {
@@ -141,39 +430,343 @@ func (d *Dispatcher) wants(ep Endpoint, eventType string) bool {
 	return false
 }
 
-func (d *Dispatcher) deliver(ep Endpoint, eventType string, body []byte) {
-	for attempt := 0; attempt <= d.maxRetries; attempt++ {
-		if attempt > 0 {
-			time.Sleep(time.Duration(attempt) * 200 * time.Millisecond)
+func (d *Dispatcher) run() {
+	defer close(d.done)
+	// Workers watch quit themselves; wait for their in-flight deliveries.
+	defer d.wg.Wait()
+	poll := time.NewTicker(d.cfg.PollInterval)
+	defer poll.Stop()
+	lastPrune := time.Time{}
+	lastReload := d.now()
+	for {
+		if d.now().Sub(lastPrune) >= pruneEvery {
+			d.prune()
+			lastPrune = d.now()
 		}
-		ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
-		req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.URL, bytes.NewReader(body))
-		if err != nil {
-			cancel()
-			d.logger.Warn("webhook request build failed", zap.Error(err))
+		if d.now().Sub(lastReload) >= d.cfg.ReloadInterval {
+			d.reload()
+			lastReload = d.now()
+		}
+		d.drain()
+		select {
+		case <-d.quit:
 			return
+		case <-d.wake:
+		case <-poll.C:
 		}
-		req.Header.Set("Content-Type", "application/json")
-		if ep.Secret != "" {
-			req.Header.Set("X-Webhook-Signature", ComputeSignature(ep.Secret, body))
+	}
+}
+
+// drain claims due rows and hands them to their endpoints' workers until
+// none are left, a whole batch could not be handed on, or Stop is called.
+func (d *Dispatcher) drain() {
+	for {
+		select {
+		case <-d.quit:
+			return
+		default:
 		}
-		resp, err := d.client.Do(req)
+		now := d.now()
+		// A row can wait behind a full queue of timeouts before its
+		// worker reaches it; the lease covers that so it is not
+		// re-claimed in the meantime.
+		lease := now.Add((queueSize+1)*d.cfg.Timeout + time.Minute)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		entries, err := d.store.ClaimDue(ctx, now.Unix(), lease.Unix(), claimBatch)
 		cancel()
-		if err == nil && resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			_ = resp.Body.Close()
+		if err != nil {
+			d.logger.Warn("webhook outbox claim failed", zap.Error(err))
 			return
 		}
-		if resp != nil {
-			_ = resp.Body.Close()
+		if len(entries) == 0 {
+			return
+		}
+		progressed := false
+		deferred := make(map[int64][]int64)
+		for _, e := range entries {
+			until, ok := d.dispatch(e, now)
+			progressed = progressed || ok
+			if !until.IsZero() {
+				deferred[until.Unix()] = append(deferred[until.Unix()], e.ID)
+			}
+		}
+		for until, ids := range deferred {
+			d.setAside(ids, until)
 		}
+		if !progressed {
+			// Everything claimed was held or set aside; claiming again
+			// now would only spin.
+			return
+		}
+	}
+}
+
+// dispatch hands a claimed row to its endpoint's worker. progressed is
+// false when the row was not queued or settled; a non-zero deferUntil
+// asks the caller to set it aside until then.
+func (d *Dispatcher) dispatch(e *models.WebhookOutboxEntry, now time.Time) (deferUntil time.Time, progressed bool) {
+	ep, ok, paused := d.routes.Load().resolve(e)
+	if !ok && e.SubscriptionID != nil {
+		// Possibly created since the last reload (e.g. a test event sent
+		// right after registering); look again before giving up.
+		d.reload()
+		ep, ok, paused = d.routes.Load().resolve(e)
+	}
+	switch {
+	case e.SubscriptionID != nil && (paused || !ok):
+		// Held while paused: the claim lease lapses and the row comes
+		// back on a later pass. A deleted subscription's rows are removed
+		// by the cascade.
+		return time.Time{}, false
+	case !ok:
+		// Removed from config since the row was written. Dead-letter it
+		// straight away; re-adding the endpoint and replaying recovers it.
+		d.complete(e, d.newAttempt(e), errors.New("endpoint no longer configured"), false)
+		return time.Time{}, true
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	w := d.workerLocked(ep)
+	if open, until := w.breaker.blocked(now); open {
+		return until, false
+	}
+	select {
+	case w.queue <- e:
+		d.cfg.Observer.SetWebhookQueueDepth(w.label, len(w.queue))
+		return time.Time{}, true
+	default:
+		// The endpoint is behind by a full queue. Set the row aside
+		// briefly instead of holding it for the whole lease.
+		return now.Add(d.cfg.PollInterval), false
+	}
+}
+
+// workerLocked returns ep's worker, starting it on first use. d.mu must
+// be held.
+func (d *Dispatcher) workerLocked(ep Endpoint) *endpointWorker {
+	label := endpointLabel(ep.SubscriptionID, ep.URL)
+	if w, ok := d.workers[label]; ok {
+		return w
+	}
+	w := &endpointWorker{
+		label:          label,
+		subscriptionID: ep.SubscriptionID,
+		url:            ep.URL,
+		queue:          make(chan *models.WebhookOutboxEntry, queueSize),
+		breaker:        breaker{threshold: d.cfg.CircuitThreshold, cooldown: d.cfg.CircuitCooldown},
+	}
+	d.workers[label] = w
+	d.wg.Add(1)
+	go d.work(w)
+	return w
+}
+
+// work delivers w's queued rows until Stop is called or w is retired.
+func (d *Dispatcher) work(w *endpointWorker) {
+	defer d.wg.Done()
+	for {
+		select {
+		case <-d.quit:
+			return
+		case e, ok := <-w.queue:
+			if !ok {
+				return
+			}
+			d.cfg.Observer.SetWebhookQueueDepth(w.label, len(w.queue))
+			if open, until := w.breaker.blocked(d.now()); open {
+				// The circuit opened while this row was queued.
+				d.setAside([]int64{e.ID}, until.Unix())
+				continue
+			}
+			d.deliver(w, e)
+		}
+	}
+}
+
+// setAside pushes rows' next attempt to until without spending one.
+func (d *Dispatcher) setAside(ids []int64, until int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := d.store.Defer(ctx, ids, until); err != nil {
+		// The claim lease lapses and the rows come back anyway.
+		d.logger.Warn("webhook outbox defer failed", zap.Int("rows", len(ids)), zap.Error(err))
+	}
+}
+
+func (d *Dispatcher) newAttempt(e *models.WebhookOutboxEntry) *models.WebhookDeliveryAttempt {
+	return &models.WebhookDeliveryAttempt{
+		OutboxID:    e.ID,
+		Attempt:     e.Attempts + 1,
+		AttemptedAt: d.now().Unix(),
+	}
+}
+
+// deliver makes one attempt at e on w's endpoint and records its outcome.
+func (d *Dispatcher) deliver(w *endpointWorker, e *models.WebhookOutboxEntry) {
+	// Resolve again: the endpoint may have been paused, edited or
+	// re-keyed while the row was queued.
+	ep, ok, paused := d.routes.Load().resolve(e)
+	if e.SubscriptionID != nil && (paused || !ok) {
+		return
+	}
+	attempt := d.newAttempt(e)
+	if !ok {
+		d.complete(e, attempt, errors.New("endpoint no longer configured"), false)
+		return
+	}
+
+	start := d.now()
+	code, failure := d.post(ep, e)
+	elapsed := d.now().Sub(start)
+	attempt.DurationMs = int(elapsed.Milliseconds())
+	if code != 0 {
+		attempt.StatusCode = &code
+	}
+	d.cfg.Observer.ObserveWebhookDelivery(w.label, failure == nil, elapsed)
+
+	switch opened, closed := w.breaker.record(failure == nil, d.now()); {
+	case opened:
+		d.cfg.Observer.SetWebhookCircuitOpen(w.label, true)
+		d.logger.Warn("webhook endpoint circuit opened",
+			zap.String("endpoint", w.label),
+			zap.Int("consecutive_failures", d.cfg.CircuitThreshold),
+			zap.Duration("cooldown", d.cfg.CircuitCooldown),
+			zap.Error(failure))
+	case closed:
+		d.cfg.Observer.SetWebhookCircuitOpen(w.label, false)
+		d.logger.Info("webhook endpoint circuit closed", zap.String("endpoint", w.label))
+	}
+
+	d.complete(e, attempt, failure, true)
+}
+
+// complete records attempt and settles e: delivered on success, dead once
+// retries run out (or at once when !retryable), else pending with a
+// backoff.
+func (d *Dispatcher) complete(e *models.WebhookOutboxEntry, attempt *models.WebhookDeliveryAttempt, failure error, retryable bool) {
+	status, next := models.WebhookStatusDelivered, attempt.AttemptedAt
+	switch {
+	case failure == nil:
+	case !retryable || attempt.Attempt > d.cfg.MaxRetries:
+		status = models.WebhookStatusDead
+	default:
+		status = models.WebhookStatusPending
+		next = d.now().Add(d.backoff(attempt.Attempt)).Unix()
+	}
+	if failure != nil {
+		msg := failure.Error()
+		attempt.Error = &msg
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := d.store.Complete(ctx, attempt, status, next); err != nil {
+		// The lease lapses and the row is retried; at-least-once.
+		d.logger.Warn("webhook outbox update failed", zap.Int64("id", e.ID), zap.Error(err))
+		return
+	}
+	switch {
+	case status == models.WebhookStatusDead:
+		d.logger.Warn("webhook delivery dead-lettered",
+			zap.Int64("id", e.ID),
+			zap.String("endpoint", entryLabel(e)),
+			zap.String("type", e.EventType),
+			zap.Int("attempts", attempt.Attempt),
+			zap.Error(failure))
+	case status == models.WebhookStatusPending && next <= d.now().Unix():
+		// The retry is already due; don't leave it for the next poll.
+		d.Notify()
 	}
-	d.logger.Warn("webhook delivery failed after retries",
-		zap.String("url", ep.URL), zap.String("type", eventType))
 }
 
-// ComputeSignature returns the hex HMAC-SHA256 of body keyed by secret.
-func ComputeSignature(secret string, body []byte) string {
-	mac := hmac.New(sha256.New, []byte(secret))
-	mac.Write(body)
-	return hex.EncodeToString(mac.Sum(nil))
+// post sends e to ep and returns the HTTP status code (0 when no response
+// was received) and a non-nil error unless the response was 2xx. Each
+// attempt is signed afresh with the current time.
+func (d *Dispatcher) post(ep Endpoint, e *models.WebhookOutboxEntry) (int, error) {
+	body, err := json.Marshal(webhookverify.Envelope{
+		ID:      e.DeliveryID,
+		Type:    e.EventType,
+		Version: SchemaVersion(e.EventType),
+		Payload: e.Payload,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("marshal body: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), d.cfg.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhookverify.IDHeader, e.DeliveryID)
+	now := d.now()
+	if secrets := ep.signingSecrets(now); len(secrets) > 0 {
+		req.Header.Set(webhookverify.SignatureHeader, webhookverify.Header(now.Unix(), body, secrets...))
+	}
+	client := d.client
+	if ep.SubscriptionID != 0 {
+		client = d.subClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay before retry number attempt (1-based):
+// BackoffBase doubled per attempt, capped at BackoffMax, then jittered
+// into [d/2, d) so endpoints recovering from an outage are not hit by
+// every queued row at once.
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	delay := d.cfg.BackoffBase
+	for n := 1; n < attempt && delay < d.cfg.BackoffMax; n++ {
+		delay *= 2
+	}
+	delay = min(delay, d.cfg.BackoffMax)
+	half := delay / 2
+	return half + time.Duration(d.float()*float64(half))
+}
+
+// prune deletes delivered rows older than Retention.
+func (d *Dispatcher) prune() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	n, err := d.store.PruneDelivered(ctx, d.now().Add(-d.cfg.Retention).Unix())
+	if err != nil {
+		d.logger.Warn("webhook outbox prune failed", zap.Error(err))
+		return
+	}
+	if n > 0 {
+		d.logger.Info("pruned delivered webhook outbox rows", zap.Int64("rows", n))
+	}
+}
+
+// signingSecrets returns the secrets that sign a delivery at now: the
+// current one, then the previous one while its grace period lasts.
+func (ep Endpoint) signingSecrets(now time.Time) []string {
+	var out []string
+	if ep.Secret != "" {
+		out = append(out, ep.Secret)
+	}
+	if ep.PreviousSecret != "" && (ep.PreviousSecretUntil == 0 || now.Unix() < ep.PreviousSecretUntil) {
+		out = append(out, ep.PreviousSecret)
+	}
+	return out
+}
+
+// NewSecret returns a random 32-byte signing secret, hex-encoded.
+func NewSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := crand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
 }
@@ -1,107 +1,529 @@
 package webhooks
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+	"github.com/0x3639/nom-indexer-go/pkg/webhookverify"
 )
 
+// memStore is an in-memory Store with the same claim/lease semantics as
+// repository.WebhookOutboxRepository.
+type memStore struct {
+	mu       sync.Mutex
+	entries  []*models.WebhookOutboxEntry
+	attempts []*models.WebhookDeliveryAttempt
+}
+
+func (s *memStore) add(entries ...*models.WebhookOutboxEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range entries {
+		e.ID = int64(len(s.entries) + 1)
+		e.DeliveryID = fmt.Sprintf("delivery-%d", e.ID)
+		e.Status = models.WebhookStatusPending
+		s.entries = append(s.entries, e)
+	}
+}
+
+func (s *memStore) ClaimDue(_ context.Context, now, leaseUntil int64, limit int) ([]*models.WebhookOutboxEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []*models.WebhookOutboxEntry
+	for _, e := range s.entries {
+		if len(out) == limit {
+			break
+		}
+		if e.Status == models.WebhookStatusPending && e.NextAttemptAt <= now {
+			e.NextAttemptAt = leaseUntil
+			c := *e
+			out = append(out, &c)
+		}
+	}
+	return out, nil
+}
+
+func (s *memStore) Complete(_ context.Context, a *models.WebhookDeliveryAttempt, status string, next int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts = append(s.attempts, a)
+	e := s.entries[a.OutboxID-1]
+	e.Status = status
+	e.Attempts = a.Attempt
+	e.NextAttemptAt = next
+	e.LastError = a.Error
+	return nil
+}
+
+func (s *memStore) Defer(_ context.Context, ids []int64, until int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		if e := s.entries[id-1]; e.Status == models.WebhookStatusPending {
+			e.NextAttemptAt = until
+		}
+	}
+	return nil
+}
+
+func (s *memStore) PruneDelivered(context.Context, int64) (int64, error) { return 0, nil }
+
+func (s *memStore) status(id int64) (string, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.entries[id-1]
+	return e.Status, e.Attempts
+}
+
+// waitFor polls cond for up to 2s.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("condition not met within 2s")
+}
+
 func TestDispatcher_DeliversAndSigns(t *testing.T) {
 	var (
 		mu     sync.Mutex
 		bodies [][]byte
 		sigs   []string
+		ids    []string
 	)
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		buf, _ := io.ReadAll(r.Body)
 		mu.Lock()
 		bodies = append(bodies, buf)
 		sigs = append(sigs, r.Header.Get("X-Webhook-Signature"))
+		ids = append(ids, r.Header.Get("X-Webhook-ID"))
 		mu.Unlock()
 		w.WriteHeader(http.StatusOK)
 	}))
 	defer srv.Close()
 
-	d := New([]Endpoint{{URL: srv.URL, Secret: "s3cr3t"}}, 2*time.Second, 1, nil)
+	store := &memStore{}
+	d := New([]Endpoint{{URL: srv.URL, Secret: "s3cr3t"}}, store, nil, Config{Timeout: 2 * time.Second}, nil)
+	entries, err := d.Outbox(Event{Type: "momentum.inserted", Payload: map[string]any{"height": 42}}, 42, time.Now())
+	if err != nil {
+		t.Fatalf("Outbox: %v", err)
+	}
+	store.add(entries...)
+
 	d.Start()
 	defer d.Stop()
+	d.Notify()
 
-	d.Emit(Event{Type: "momentum.inserted", Payload: map[string]any{"height": "42"}})
-
-	deadline := time.Now().Add(2 * time.Second)
-	for time.Now().Before(deadline) {
-		mu.Lock()
-		n := len(bodies)
-		mu.Unlock()
-		if n > 0 {
-			break
-		}
-		time.Sleep(10 * time.Millisecond)
-	}
+	waitFor(t, func() bool {
+		status, _ := store.status(1)
+		return status == models.WebhookStatusDelivered
+	})
 
 	mu.Lock()
 	defer mu.Unlock()
 	if len(bodies) != 1 {
 		t.Fatalf("expected 1 delivery, got %d", len(bodies))
 	}
-	var got Event
+	var got struct {
+		ID      string         `json:"id"`
+		Type    string         `json:"type"`
+		Version int            `json:"version"`
+		Payload map[string]any `json:"payload"`
+	}
 	if err := json.Unmarshal(bodies[0], &got); err != nil {
 		t.Fatalf("payload not JSON: %v", err)
 	}
-	if got.Type != "momentum.inserted" {
-		t.Errorf("type = %q", got.Type)
+	if got.ID != "delivery-1" || got.Type != "momentum.inserted" || got.Version != 1 || got.Payload["height"] != float64(42) {
+		t.Errorf("body = %s", bodies[0])
+	}
+	if ids[0] != "delivery-1" {
+		t.Errorf("X-Webhook-ID = %q, want the envelope id", ids[0])
+	}
+	v := webhookverify.Verifier{Secrets: []string{"s3cr3t"}}
+	if err := v.Verify(sigs[0], bodies[0]); err != nil {
+		t.Errorf("signature %q does not verify: %v", sigs[0], err)
+	}
+	if a := store.attempts[0]; a.StatusCode == nil || *a.StatusCode != http.StatusOK || a.Error != nil {
+		t.Errorf("attempt = %+v, want a recorded 200", a)
+	}
+}
+
+func TestDispatcher_OutboxRoutesByEventFilter(t *testing.T) {
+	d := New([]Endpoint{
+		{URL: "http://blocks", Events: []string{"account_block.inserted"}},
+		{URL: "http://all"},
+	}, &memStore{}, nil, Config{}, nil)
+
+	entries, err := d.Outbox(Event{Type: "momentum.inserted", Payload: map[string]any{}}, 7, time.Unix(100, 0))
+	if err != nil {
+		t.Fatalf("Outbox: %v", err)
+	}
+	if len(entries) != 1 || entries[0].EndpointURL != "http://all" {
+		t.Fatalf("entries = %+v, want only the unfiltered endpoint", entries)
 	}
-	if want := ComputeSignature("s3cr3t", bodies[0]); sigs[0] != want {
-		t.Errorf("signature = %q, want %q", sigs[0], want)
+	e := entries[0]
+	if e.MomentumHeight != 7 || e.NextAttemptAt != 100 || e.CreatedAt != 100 {
+		t.Errorf("entry = %+v", e)
+	}
+
+	entries, _ = d.Outbox(Event{Type: "account_block.inserted", Payload: map[string]any{}}, 7, time.Unix(100, 0))
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries for a block event, want 2", len(entries))
 	}
 }
 
-func TestDispatcher_EventFilter(t *testing.T) {
-	d := New([]Endpoint{{URL: "http://x", Events: []string{"account_block.inserted"}}}, time.Second, 1, nil)
-	if d.wants(d.endpoints[0], "momentum.inserted") {
-		t.Error("should not want unsubscribed event")
+func TestDispatcher_OutboxDeliveryIDsAreDeterministic(t *testing.T) {
+	d := New([]Endpoint{{URL: "http://a"}, {URL: "http://b"}}, &memStore{}, nil, Config{}, nil)
+	ev := Event{Type: "account_block.inserted", Payload: map[string]any{"hash": "h1"}}
+
+	first, err := d.Outbox(ev, 7, time.Unix(100, 0))
+	if err != nil {
+		t.Fatalf("Outbox: %v", err)
+	}
+	again, _ := d.Outbox(ev, 7, time.Unix(500, 0))
+	if len(first) != 2 || len(again) != 2 {
+		t.Fatalf("got %d and %d entries, want 2 each", len(first), len(again))
+	}
+	for i := range first {
+		if len(first[i].DeliveryID) != 36 || first[i].DeliveryID != again[i].DeliveryID {
+			t.Errorf("entry %d: ids %q and %q, want the same UUID on both passes", i, first[i].DeliveryID, again[i].DeliveryID)
+		}
+	}
+	if first[0].DeliveryID == first[1].DeliveryID {
+		t.Error("two endpoints share a delivery id")
+	}
+
+	other, _ := d.Outbox(Event{Type: ev.Type, Payload: map[string]any{"hash": "h2"}}, 7, time.Unix(100, 0))
+	if other[0].DeliveryID == first[0].DeliveryID {
+		t.Error("a different block got the same delivery id")
 	}
-	if !d.wants(d.endpoints[0], "account_block.inserted") {
-		t.Error("should want subscribed event")
+	higher, _ := d.Outbox(ev, 8, time.Unix(100, 0))
+	if higher[0].DeliveryID == first[0].DeliveryID {
+		t.Error("the same payload at another height got the same delivery id")
 	}
 }
 
-func TestDispatcher_EmitAfterStopIsSafe(t *testing.T) {
-	d := New([]Endpoint{{URL: "http://127.0.0.1:0"}}, time.Second, 0, nil)
+func TestDispatcher_RetriesThenDeadLetters(t *testing.T) {
+	var calls sync.WaitGroup
+	calls.Add(3)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer calls.Done()
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	store := &memStore{}
+	// A 1ms base keeps every retry inside the same unix second, so the
+	// worker picks it straight back up.
+	d := New([]Endpoint{{URL: srv.URL}}, store,
+		nil, Config{Timeout: time.Second, MaxRetries: 2, BackoffBase: time.Millisecond}, nil)
+	entries, _ := d.Outbox(Event{Type: "reorg", Payload: map[string]any{}}, 1, time.Now())
+	store.add(entries...)
+
+	d.Start()
+	defer d.Stop()
+
+	waitFor(t, func() bool {
+		status, _ := store.status(1)
+		return status == models.WebhookStatusDead
+	})
+	calls.Wait()
+	if _, attempts := store.status(1); attempts != 3 {
+		t.Fatalf("attempts = %d, want 3 (1 + max_retries)", attempts)
+	}
+	if n := len(store.attempts); n != 3 {
+		t.Fatalf("recorded %d attempts, want 3", n)
+	}
+	last := store.attempts[2]
+	if last.StatusCode == nil || *last.StatusCode != http.StatusBadGateway || last.Error == nil {
+		t.Errorf("last attempt = %+v, want a recorded 502", last)
+	}
+}
+
+func TestDispatcher_RemovedEndpointDeadLettersImmediately(t *testing.T) {
+	store := &memStore{}
+	store.add(&models.WebhookOutboxEntry{EndpointURL: "http://gone", EventType: "reorg", Payload: []byte(`{}`)})
+	d := New(nil, store, nil, Config{MaxRetries: 5}, nil)
+	d.Start()
+	defer d.Stop()
+
+	waitFor(t, func() bool {
+		status, _ := store.status(1)
+		return status == models.WebhookStatusDead
+	})
+}
+
+func TestDispatcher_BackoffDoublesWithJitterAndCap(t *testing.T) {
+	d := New(nil, &memStore{}, nil, Config{BackoffBase: time.Second, BackoffMax: 10 * time.Second}, nil)
+
+	d.float = func() float64 { return 0 }
+	for attempt, want := range map[int]time.Duration{
+		1: 500 * time.Millisecond,
+		2: time.Second,
+		3: 2 * time.Second,
+		4: 4 * time.Second,
+		5: 5 * time.Second, // capped at 10s, halved
+		9: 5 * time.Second,
+	} {
+		if got := d.backoff(attempt); got != want {
+			t.Errorf("backoff(%d) with zero jitter = %v, want %v", attempt, got, want)
+		}
+	}
+
+	d.float = func() float64 { return 0.999 }
+	if got := d.backoff(2); got < 1900*time.Millisecond || got >= 2*time.Second {
+		t.Errorf("backoff(2) with max jitter = %v, want just under 2s", got)
+	}
+}
+
+func TestDispatcher_StopIsSafe(t *testing.T) {
+	// Never started: Stop must not block.
+	d := New(nil, &memStore{}, nil, Config{}, nil)
+	d.Stop()
+	d.Notify()
+
+	d = New(nil, &memStore{}, nil, Config{}, nil)
 	d.Start()
 	d.Stop()
-	// Must not panic.
-	d.Emit(Event{Type: "momentum.inserted", Payload: map[string]any{"h": "1"}})
-	// Double stop must not panic.
+	// Notify and a second Stop after shutdown must not panic or block.
+	d.Notify()
 	d.Stop()
 }
 
-func TestDispatcher_ConcurrentEmitAndStop(t *testing.T) {
-	d := New([]Endpoint{{URL: "http://127.0.0.1:0"}}, time.Second, 0, nil)
+func TestDispatcher_ConcurrentNotifyAndStop(t *testing.T) {
+	d := New(nil, &memStore{}, nil, Config{}, nil)
 	d.Start()
 
-	const emitters = 8
+	const notifiers = 8
 	var wg sync.WaitGroup
-	wg.Add(emitters)
-	for i := 0; i < emitters; i++ {
+	wg.Add(notifiers)
+	for i := 0; i < notifiers; i++ {
 		go func() {
 			defer wg.Done()
 			for j := 0; j < 200; j++ {
-				// Some of these land before Stop, some after; all must be
-				// panic-free (the queue is never closed).
-				d.Emit(Event{Type: "momentum.inserted", Payload: map[string]any{"j": j}})
+				d.Notify()
 			}
 		}()
 	}
 
-	// Race Stop against the emitters.
 	go d.Stop()
 
 	wg.Wait()
-	// Ensure shutdown completed; second Stop is a no-op wait.
 	d.Stop()
 }
+
+// memSubs is a Subscriptions fake whose contents can change between
+// reloads.
+type memSubs struct {
+	mu   sync.Mutex
+	subs []*models.WebhookSubscription
+}
+
+func (m *memSubs) set(subs ...*models.WebhookSubscription) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subs = subs
+}
+
+func (m *memSubs) ListAll(context.Context) ([]*models.WebhookSubscription, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*models.WebhookSubscription(nil), m.subs...), nil
+}
+
+func TestDispatcher_OutboxRoutesToSubscriptions(t *testing.T) {
+	subs := &memSubs{}
+	subs.set(
+		&models.WebhookSubscription{ID: 7, URL: "http://sub", Events: []string{"reorg"}, Status: models.WebhookSubscriptionActive},
+		&models.WebhookSubscription{ID: 8, URL: "http://paused", Status: models.WebhookSubscriptionPaused},
+	)
+	d := New([]Endpoint{{URL: "http://static"}}, &memStore{}, subs, Config{}, nil)
+	d.reload()
+
+	entries, err := d.Outbox(Event{Type: "reorg", Payload: map[string]any{}}, 1, time.Unix(100, 0))
+	if err != nil {
+		t.Fatalf("Outbox: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want static + active subscription", len(entries))
+	}
+	if entries[0].SubscriptionID != nil || entries[0].EndpointURL != "http://static" {
+		t.Errorf("entries[0] = %+v, want the config endpoint", entries[0])
+	}
+	if entries[1].SubscriptionID == nil || *entries[1].SubscriptionID != 7 {
+		t.Errorf("entries[1] = %+v, want subscription 7", entries[1])
+	}
+
+	// A new subscription is picked up on the next reload.
+	subs.set(&models.WebhookSubscription{ID: 9, URL: "http://new", Status: models.WebhookSubscriptionActive})
+	d.reload()
+	entries, _ = d.Outbox(Event{Type: "momentum.inserted", Payload: map[string]any{}}, 2, time.Unix(100, 0))
+	if len(entries) != 2 || *entries[1].SubscriptionID != 9 {
+		t.Errorf("after reload got %+v, want static + subscription 9", entries)
+	}
+}
+
+func TestDispatcher_PausedSubscriptionHoldsRows(t *testing.T) {
+	var hits int
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits++
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	subs := &memSubs{}
+	subs.set(&models.WebhookSubscription{ID: 3, URL: srv.URL, Status: models.WebhookSubscriptionPaused})
+	id := int64(3)
+	store := &memStore{}
+	store.add(&models.WebhookOutboxEntry{SubscriptionID: &id, EndpointURL: srv.URL, EventType: "reorg", Payload: []byte(`{}`)})
+
+	d := New(nil, store, subs, Config{PollInterval: 20 * time.Millisecond, ReloadInterval: 20 * time.Millisecond}, nil)
+	allowLoopback(d)
+	d.Start()
+	defer d.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+	if status, attempts := store.status(1); status != models.WebhookStatusPending || attempts != 0 {
+		t.Fatalf("paused row = %s after %d attempts, want held pending", status, attempts)
+	}
+
+	// Resuming delivers the held row once its lease lapses.
+	subs.set(&models.WebhookSubscription{ID: 3, URL: srv.URL, Status: models.WebhookSubscriptionActive})
+	d.reload()
+	store.mu.Lock()
+	store.entries[0].NextAttemptAt = 0
+	store.mu.Unlock()
+	d.Notify()
+	waitFor(t, func() bool {
+		status, _ := store.status(1)
+		return status == models.WebhookStatusDelivered
+	})
+	mu.Lock()
+	defer mu.Unlock()
+	if hits != 1 {
+		t.Errorf("endpoint hit %d times, want 1", hits)
+	}
+}
+
+func TestDispatcher_UnknownSubscriptionReloadsBeforeDelivering(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	subs := &memSubs{}
+	// Long reload interval: only the on-demand reload can find it.
+	d := New(nil, &memStore{}, subs, Config{ReloadInterval: time.Hour}, nil)
+	allowLoopback(d)
+	store := d.store.(*memStore)
+	d.Start()
+	defer d.Stop()
+
+	subs.set(&models.WebhookSubscription{ID: 5, URL: srv.URL, Secret: "x", Status: models.WebhookSubscriptionActive})
+	id := int64(5)
+	store.add(&models.WebhookOutboxEntry{SubscriptionID: &id, EndpointURL: srv.URL, EventType: EventTest, Payload: []byte(`{}`)})
+	d.Notify()
+	waitFor(t, func() bool {
+		status, _ := store.status(1)
+		return status == models.WebhookStatusDelivered
+	})
+}
+
+func TestEndpoint_SigningSecrets(t *testing.T) {
+	now := time.Unix(1000, 0)
+	r := buildRoutes([]Endpoint{{URL: "http://cfg", Secret: "new", PreviousSecret: "old"}}, []*models.WebhookSubscription{
+		{ID: 1, URL: "http://a", Secret: "new", PreviousSecret: "old", PreviousSecretExpiresAt: 1001},
+		{ID: 2, URL: "http://b", Secret: "new", PreviousSecret: "old", PreviousSecretExpiresAt: 1000},
+		{ID: 3, URL: "http://c", Secret: "new"},
+		{ID: 4, URL: "http://d"},
+	}, zap.NewNop())
+
+	for _, tc := range []struct {
+		name string
+		ep   Endpoint
+		want []string
+	}{
+		{"config previous secret has no expiry", r.byURL["http://cfg"], []string{"new", "old"}},
+		{"within the grace period", r.byID[1], []string{"new", "old"}},
+		{"grace period over", r.byID[2], []string{"new"}},
+		{"never rotated", r.byID[3], []string{"new"}},
+		{"unsigned", r.byID[4], nil},
+	} {
+		if got := tc.ep.signingSecrets(now); !slices.Equal(got, tc.want) {
+			t.Errorf("%s: signingSecrets = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestDispatcher_RetryKeepsDeliveryIDAndResigns(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		ids  []string
+		sigs []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		ids = append(ids, r.Header.Get("X-Webhook-ID"))
+		sigs = append(sigs, r.Header.Get("X-Webhook-Signature"))
+		if len(ids) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	store := &memStore{}
+	d := New([]Endpoint{{URL: srv.URL, Secret: "new", PreviousSecret: "old"}}, store, nil, Config{
+		Timeout: time.Second, MaxRetries: 3, BackoffBase: time.Millisecond,
+	}, nil)
+	// Each attempt is signed at the time it is made.
+	var clock atomic.Int64
+	clock.Store(1000)
+	d.now = func() time.Time { return time.Unix(clock.Add(1), 0) }
+	entries, _ := d.Outbox(Event{Type: EventReorg, Payload: Reorg{}}, 1, time.Unix(0, 0))
+	store.add(entries...)
+	d.Start()
+	defer d.Stop()
+
+	waitFor(t, func() bool {
+		status, _ := store.status(1)
+		return status == models.WebhookStatusDelivered
+	})
+	mu.Lock()
+	defer mu.Unlock()
+	if len(ids) != 2 || ids[0] != "delivery-1" || ids[1] != ids[0] {
+		t.Fatalf("delivery ids = %v, want the same id on both attempts", ids)
+	}
+	t0, v0, _ := webhookverify.Parse(sigs[0])
+	t1, v1, _ := webhookverify.Parse(sigs[1])
+	if t1 <= t0 || len(v0) != 2 || len(v1) != 2 || v0[0] == v1[0] {
+		t.Errorf("signatures %q then %q, want two v1 values each, re-signed on retry", sigs[0], sigs[1])
+	}
+}
+
+// allowLoopback lets d deliver to subscriptions on an httptest server,
+// which listens on a loopback address subscriptionClient refuses.
+func allowLoopback(d *Dispatcher) {
+	d.subClient = d.client
+}
@@ -0,0 +1,107 @@
+package webhooks
+
+import (
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+// queueSize bounds how many claimed rows wait for one endpoint's worker.
+// Rows beyond it are set aside in the outbox rather than held in memory.
+const queueSize = claimBatch
+
+// Observer receives per-endpoint delivery metrics. endpoint is the
+// endpoint's label (see endpointLabel). metrics.Metrics implements it.
+type Observer interface {
+	SetWebhookQueueDepth(endpoint string, n int)
+	ObserveWebhookDelivery(endpoint string, ok bool, d time.Duration)
+	SetWebhookCircuitOpen(endpoint string, open bool)
+	// ForgetWebhookEndpoint drops the series of an endpoint that no
+	// longer exists.
+	ForgetWebhookEndpoint(endpoint string)
+}
+
+type nopObserver struct{}
+
+func (nopObserver) SetWebhookQueueDepth(string, int)                   {}
+func (nopObserver) ObserveWebhookDelivery(string, bool, time.Duration) {}
+func (nopObserver) SetWebhookCircuitOpen(string, bool)                 {}
+func (nopObserver) ForgetWebhookEndpoint(string)                       {}
+
+// endpointLabel names an endpoint in metrics and logs: a runtime
+// subscription by its id (its URL is the subscriber's business), a
+// config endpoint by its URL without credentials or query string.
+func endpointLabel(subscriptionID int64, rawURL string) string {
+	if subscriptionID != 0 {
+		return "subscription/" + strconv.FormatInt(subscriptionID, 10)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "invalid-url"
+	}
+	u.User, u.RawQuery, u.Fragment = nil, "", ""
+	return u.String()
+}
+
+// entryLabel is endpointLabel for the endpoint an outbox row belongs to.
+func entryLabel(e *models.WebhookOutboxEntry) string {
+	if e.SubscriptionID != nil {
+		return endpointLabel(*e.SubscriptionID, e.EndpointURL)
+	}
+	return endpointLabel(0, e.EndpointURL)
+}
+
+// endpointWorker delivers one endpoint's rows, one at a time and in
+// claim order, so a slow or failing endpoint only ever delays itself.
+type endpointWorker struct {
+	label          string
+	subscriptionID int64
+	url            string
+	queue          chan *models.WebhookOutboxEntry
+	breaker        breaker
+}
+
+// breaker is an endpoint's circuit breaker. After threshold consecutive
+// failures it opens for cooldown, during which the endpoint's rows are
+// set aside without spending an attempt. The first delivery after the
+// cooldown is the probe: success closes the circuit, failure reopens it.
+// The worker delivers serially, so there is never more than one probe.
+type breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+}
+
+// blocked reports whether the circuit is open at now, and until when.
+func (b *breaker) blocked(now time.Time) (bool, time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures >= b.threshold && now.Before(b.openUntil) {
+		return true, b.openUntil
+	}
+	return false, time.Time{}
+}
+
+// record notes a delivery outcome and reports whether it opened or
+// closed the circuit.
+func (b *breaker) record(ok bool, now time.Time) (opened, closed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if ok {
+		closed = b.failures >= b.threshold
+		b.failures = 0
+		return false, closed
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		// Re-arming after a failed probe is not a new opening.
+		opened = b.failures == b.threshold
+		b.openUntil = now.Add(b.cooldown)
+	}
+	return opened, false
+}
@@ -0,0 +1,237 @@
+package webhooks
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+func TestBreaker_OpensProbesAndCloses(t *testing.T) {
+	b := breaker{threshold: 3, cooldown: time.Minute}
+	t0 := time.Unix(1000, 0)
+
+	for n := 1; n < 3; n++ {
+		if opened, _ := b.record(false, t0); opened {
+			t.Fatalf("opened after %d failures, threshold 3", n)
+		}
+	}
+	if open, _ := b.blocked(t0); open {
+		t.Fatal("blocked below the threshold")
+	}
+	if opened, _ := b.record(false, t0); !opened {
+		t.Fatal("third failure did not open the circuit")
+	}
+	if open, until := b.blocked(t0.Add(30 * time.Second)); !open || !until.Equal(t0.Add(time.Minute)) {
+		t.Fatalf("blocked mid-cooldown = %v until %v", open, until)
+	}
+
+	// After the cooldown one probe goes through; its failure re-arms the
+	// cooldown without counting as a new opening.
+	t1 := t0.Add(time.Minute)
+	if open, _ := b.blocked(t1); open {
+		t.Fatal("still blocked after the cooldown")
+	}
+	if opened, _ := b.record(false, t1); opened {
+		t.Error("failed probe reported as a new opening")
+	}
+	if open, _ := b.blocked(t1.Add(time.Second)); !open {
+		t.Fatal("failed probe did not reopen the circuit")
+	}
+
+	// A successful probe closes it.
+	t2 := t1.Add(time.Minute)
+	if _, closed := b.record(true, t2); !closed {
+		t.Error("successful probe did not report closing")
+	}
+	if open, _ := b.blocked(t2); open || b.failures != 0 {
+		t.Errorf("after success: blocked=%v failures=%d", open, b.failures)
+	}
+	if _, closed := b.record(true, t2); closed {
+		t.Error("success on a closed circuit reported closing")
+	}
+}
+
+func TestEndpointLabel(t *testing.T) {
+	for _, tc := range []struct {
+		id   int64
+		url  string
+		want string
+	}{
+		{12, "https://hooks.example/x?token=secret", "subscription/12"},
+		{0, "https://user:pw@ops.example/hook?key=abc#frag", "https://ops.example/hook"},
+		{0, "http://localhost:9000/in", "http://localhost:9000/in"},
+	} {
+		if got := endpointLabel(tc.id, tc.url); got != tc.want {
+			t.Errorf("endpointLabel(%d, %q) = %q, want %q", tc.id, tc.url, got, tc.want)
+		}
+	}
+}
+
+// recordingObserver is an Observer that keeps what it is told.
+type recordingObserver struct {
+	mu        sync.Mutex
+	successes map[string]int
+	failures  map[string]int
+	open      map[string]bool
+	forgotten []string
+}
+
+func newRecordingObserver() *recordingObserver {
+	return &recordingObserver{successes: map[string]int{}, failures: map[string]int{}, open: map[string]bool{}}
+}
+
+func (o *recordingObserver) SetWebhookQueueDepth(string, int) {}
+func (o *recordingObserver) ObserveWebhookDelivery(endpoint string, ok bool, _ time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if ok {
+		o.successes[endpoint]++
+	} else {
+		o.failures[endpoint]++
+	}
+}
+func (o *recordingObserver) SetWebhookCircuitOpen(endpoint string, open bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.open[endpoint] = open
+}
+func (o *recordingObserver) ForgetWebhookEndpoint(endpoint string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.forgotten = append(o.forgotten, endpoint)
+}
+
+func TestDispatcher_SlowEndpointDoesNotDelayOthers(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer slow.Close()
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer fast.Close()
+
+	store := &memStore{}
+	d := New([]Endpoint{{URL: slow.URL}, {URL: fast.URL}}, store, nil, Config{Timeout: 10 * time.Second}, nil)
+	for h := uint64(1); h <= 3; h++ {
+		entries, _ := d.Outbox(Event{Type: EventMomentumInserted, Payload: MomentumInserted{Height: h}}, h, time.Now())
+		store.add(entries...)
+	}
+	d.Start()
+	defer d.Stop()
+	defer close(release) // before Stop, which waits for the stuck request
+
+	// Rows 2, 4 and 6 are the fast endpoint's. They all go out while the
+	// slow endpoint is still stuck on its first request.
+	waitFor(t, func() bool {
+		for _, id := range []int64{2, 4, 6} {
+			if status, _ := store.status(id); status != models.WebhookStatusDelivered {
+				return false
+			}
+		}
+		return true
+	})
+	if status, attempts := store.status(1); status != models.WebhookStatusPending || attempts != 0 {
+		t.Errorf("slow endpoint's first row = %s after %d attempts, want still in flight", status, attempts)
+	}
+}
+
+func TestDispatcher_CircuitOpensAndSetsRowsAside(t *testing.T) {
+	var mu sync.Mutex
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits++
+		mu.Unlock()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	obs := newRecordingObserver()
+	store := &memStore{}
+	d := New([]Endpoint{{URL: srv.URL}}, store, nil, Config{
+		Timeout:          time.Second,
+		MaxRetries:       10,
+		BackoffBase:      time.Millisecond,
+		CircuitThreshold: 2,
+		CircuitCooldown:  time.Hour,
+		Observer:         obs,
+	}, nil)
+	for h := uint64(1); h <= 4; h++ {
+		entries, _ := d.Outbox(Event{Type: EventMomentumInserted, Payload: MomentumInserted{Height: h}}, h, time.Now())
+		store.add(entries...)
+	}
+	d.Start()
+	defer d.Stop()
+
+	// Two failures open the circuit; every row is then set aside for the
+	// cooldown without spending further attempts.
+	label := endpointLabel(0, srv.URL)
+	cutoff := time.Now().Add(50 * time.Minute).Unix()
+	waitFor(t, func() bool {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		for _, e := range store.entries {
+			if e.NextAttemptAt < cutoff {
+				return false
+			}
+		}
+		return true
+	})
+	mu.Lock()
+	defer mu.Unlock()
+	if hits != 2 {
+		t.Errorf("endpoint hit %d times, want 2 (the threshold)", hits)
+	}
+	for id := int64(1); id <= 4; id++ {
+		if status, _ := store.status(id); status != models.WebhookStatusPending {
+			t.Errorf("row %d = %s, want pending", id, status)
+		}
+	}
+	obs.mu.Lock()
+	defer obs.mu.Unlock()
+	if !obs.open[label] || obs.failures[label] != 2 {
+		t.Errorf("observer: open=%v failures=%d, want open after 2", obs.open[label], obs.failures[label])
+	}
+}
+
+func TestDispatcher_DeletedSubscriptionRetiresWorker(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	obs := newRecordingObserver()
+	subs := &memSubs{}
+	subs.set(&models.WebhookSubscription{ID: 4, URL: srv.URL, Status: models.WebhookSubscriptionActive})
+	store := &memStore{}
+	d := New(nil, store, subs, Config{ReloadInterval: time.Hour, Observer: obs}, nil)
+	allowLoopback(d)
+	d.Start()
+	defer d.Stop()
+
+	entries, _ := d.Outbox(Event{Type: EventReorg, Payload: Reorg{}}, 1, time.Now())
+	store.add(entries...)
+	d.Notify()
+	waitFor(t, func() bool {
+		status, _ := store.status(1)
+		return status == models.WebhookStatusDelivered
+	})
+
+	subs.set()
+	d.reload()
+	d.mu.Lock()
+	n := len(d.workers)
+	d.mu.Unlock()
+	obs.mu.Lock()
+	defer obs.mu.Unlock()
+	if n != 0 || len(obs.forgotten) != 1 || obs.forgotten[0] != "subscription/4" {
+		t.Errorf("after delete: %d workers, forgotten %v", n, obs.forgotten)
+	}
+}
@@ -0,0 +1,282 @@
+package webhooks
+
+import "slices"
+
+// Event types the indexer emits. Subscription filters are validated
+// against EventTypes.
+const (
+	EventMomentumInserted     = "momentum.inserted"
+	EventAccountBlockInserted = "account_block.inserted"
+	EventReorg                = "reorg"
+
+	EventDelegationChanged = "delegation.changed"
+	EventPillarRegistered  = "pillar.registered"
+	EventPillarRevoked     = "pillar.revoked"
+	EventStakeCreated      = "stake.created"
+	EventStakeCancelled    = "stake.cancelled"
+	EventFusionCreated     = "fusion.created"
+	EventHtlcCreated       = "htlc.created"
+	EventHtlcUnlocked      = "htlc.unlocked"
+	EventHtlcReclaimed     = "htlc.reclaimed"
+	EventTokenMinted       = "token.minted"
+	EventTokenBurned       = "token.burned"
+	EventProjectCreated    = "project.created"
+	EventVoteCast          = "vote.cast"
+
+	EventBridgeWrapStatusChanged   = "bridge.wrap.status_changed"
+	EventBridgeUnwrapStatusChanged = "bridge.unwrap.status_changed"
+
+	// EventTest is queued by the API's "send test event" action. It is
+	// never emitted by the indexer and bypasses event filters.
+	EventTest = "webhook.test"
+)
+
+// EventTypes lists every event type a subscription can filter on.
+var EventTypes = []string{
+	EventMomentumInserted, EventAccountBlockInserted, EventReorg,
+	EventDelegationChanged,
+	EventPillarRegistered, EventPillarRevoked,
+	EventStakeCreated, EventStakeCancelled,
+	EventFusionCreated,
+	EventHtlcCreated, EventHtlcUnlocked, EventHtlcReclaimed,
+	EventTokenMinted, EventTokenBurned,
+	EventProjectCreated, EventVoteCast,
+	EventBridgeWrapStatusChanged, EventBridgeUnwrapStatusChanged,
+}
+
+// IsEventType reports whether t is one of EventTypes.
+func IsEventType(t string) bool {
+	return slices.Contains(EventTypes, t)
+}
+
+// schemaVersions is the current payload schema version of each event
+// type, sent as the envelope's "version". A version is bumped only for
+// incompatible changes (a field removed, renamed or retyped); adding a
+// field does not bump it.
+var schemaVersions = map[string]int{
+	EventMomentumInserted:          1,
+	EventAccountBlockInserted:      1,
+	EventReorg:                     1,
+	EventDelegationChanged:         1,
+	EventPillarRegistered:          1,
+	EventPillarRevoked:             1,
+	EventStakeCreated:              1,
+	EventStakeCancelled:            1,
+	EventFusionCreated:             1,
+	EventHtlcCreated:               1,
+	EventHtlcUnlocked:              1,
+	EventHtlcReclaimed:             1,
+	EventTokenMinted:               1,
+	EventTokenBurned:               1,
+	EventProjectCreated:            1,
+	EventVoteCast:                  1,
+	EventBridgeWrapStatusChanged:   1,
+	EventBridgeUnwrapStatusChanged: 1,
+	EventTest:                      1,
+}
+
+// SchemaVersion returns the payload schema version of event type t, or 1
+// for a type this build does not know (e.g. a row queued by a newer
+// indexer).
+func SchemaVersion(t string) int {
+	if v, ok := schemaVersions[t]; ok {
+		return v
+	}
+	return 1
+}
+
+// The types below are the payloads of each event type, in the shape
+// subscribers receive them. Keys are camelCase; token amounts are decimal
+// strings in base units, since they routinely exceed 2^53.
+
+// MomentumInserted is the momentum.inserted payload.
+type MomentumInserted struct {
+	Height    uint64 `json:"height"`
+	Hash      string `json:"hash"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// AccountBlockInserted is the account_block.inserted payload. Method is
+// the decoded embedded-contract method, empty for anything else.
+type AccountBlockInserted struct {
+	MomentumHeight uint64 `json:"momentumHeight"`
+	Hash           string `json:"hash"`
+	Address        string `json:"address"`
+	ToAddress      string `json:"toAddress"`
+	BlockType      int    `json:"blockType"`
+	TokenStandard  string `json:"tokenStandard"`
+	Amount         string `json:"amount"`
+	Method         string `json:"method"`
+}
+
+// Reorg is the reorg payload.
+type Reorg struct {
+	CommonAncestorHeight uint64 `json:"commonAncestorHeight"`
+	CommonAncestorHash   string `json:"commonAncestorHash"`
+	OrphanedTipHeight    uint64 `json:"orphanedTipHeight"`
+	OrphanedTipHash      string `json:"orphanedTipHash"`
+}
+
+// Source locates an embedded-contract event on chain: the contract's
+// receive block and the momentum that confirmed it, plus the contract
+// method called. It is embedded in every payload derived from an
+// embedded-contract call.
+type Source struct {
+	Method            string `json:"method"`
+	AccountBlockHash  string `json:"accountBlockHash"`
+	MomentumHeight    uint64 `json:"momentumHeight"`
+	MomentumTimestamp int64  `json:"momentumTimestamp"`
+}
+
+// DelegationChanged is the delegation.changed payload. PillarName and
+// PillarOwner are empty when Delegated is false (an undelegation).
+type DelegationChanged struct {
+	Source
+	Delegator   string `json:"delegator"`
+	Delegated   bool   `json:"delegated"`
+	PillarName  string `json:"pillarName"`
+	PillarOwner string `json:"pillarOwner"`
+}
+
+// PillarRegistered is the pillar.registered payload.
+type PillarRegistered struct {
+	Source
+	Name            string `json:"name"`
+	Owner           string `json:"owner"`
+	ProducerAddress string `json:"producerAddress"`
+	RewardAddress   string `json:"rewardAddress"`
+}
+
+// PillarRevoked is the pillar.revoked payload.
+type PillarRevoked struct {
+	Source
+	Name  string `json:"name"`
+	Owner string `json:"owner"`
+}
+
+// StakeCreated is the stake.created payload.
+type StakeCreated struct {
+	Source
+	ID                  string `json:"id"`
+	Address             string `json:"address"`
+	ZnnAmount           string `json:"znnAmount"`
+	DurationInSec       int    `json:"durationInSec"`
+	ExpirationTimestamp int64  `json:"expirationTimestamp"`
+}
+
+// StakeCancelled is the stake.cancelled payload.
+type StakeCancelled struct {
+	Source
+	ID      string `json:"id"`
+	Address string `json:"address"`
+}
+
+// FusionCreated is the fusion.created payload.
+type FusionCreated struct {
+	Source
+	ID               string `json:"id"`
+	Address          string `json:"address"`
+	Beneficiary      string `json:"beneficiary"`
+	QsrAmount        string `json:"qsrAmount"`
+	ExpirationHeight int64  `json:"expirationHeight"`
+}
+
+// HtlcCreated is the htlc.created payload.
+type HtlcCreated struct {
+	Source
+	ID                  string `json:"id"`
+	TimeLockedAddress   string `json:"timeLockedAddress"`
+	HashLockedAddress   string `json:"hashLockedAddress"`
+	TokenStandard       string `json:"tokenStandard"`
+	Amount              string `json:"amount"`
+	ExpirationTimestamp int64  `json:"expirationTimestamp"`
+	HashType            int    `json:"hashType"`
+	KeyMaxSize          int    `json:"keyMaxSize"`
+	HashLock            string `json:"hashLock"`
+}
+
+// HtlcUnlocked is the htlc.unlocked payload. Preimage is hex.
+type HtlcUnlocked struct {
+	Source
+	ID       string `json:"id"`
+	Address  string `json:"address"`
+	Preimage string `json:"preimage"`
+}
+
+// HtlcReclaimed is the htlc.reclaimed payload.
+type HtlcReclaimed struct {
+	Source
+	ID      string `json:"id"`
+	Address string `json:"address"`
+}
+
+// TokenMinted is the token.minted payload.
+type TokenMinted struct {
+	Source
+	TokenStandard string `json:"tokenStandard"`
+	Issuer        string `json:"issuer"`
+	Receiver      string `json:"receiver"`
+	Amount        string `json:"amount"`
+}
+
+// TokenBurned is the token.burned payload.
+type TokenBurned struct {
+	Source
+	TokenStandard string `json:"tokenStandard"`
+	Burner        string `json:"burner"`
+	Amount        string `json:"amount"`
+}
+
+// ProjectCreated is the project.created payload.
+type ProjectCreated struct {
+	Source
+	ID             string `json:"id"`
+	Owner          string `json:"owner"`
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	URL            string `json:"url"`
+	ZnnFundsNeeded string `json:"znnFundsNeeded"`
+	QsrFundsNeeded string `json:"qsrFundsNeeded"`
+}
+
+// VoteCast is the vote.cast payload. ProjectID and PhaseID are empty
+// when the voting id could not be resolved; PhaseID is empty for a vote
+// on the project itself.
+type VoteCast struct {
+	Source
+	VotingID  string `json:"votingId"`
+	Voter     string `json:"voter"`
+	ProjectID string `json:"projectId"`
+	PhaseID   string `json:"phaseId"`
+	Vote      int    `json:"vote"`
+}
+
+// BridgeWrapStatusChanged is the bridge.wrap.status_changed payload.
+// PreviousStatus is empty the first time the indexer sees the request.
+type BridgeWrapStatusChanged struct {
+	ID                     string `json:"id"`
+	PreviousStatus         string `json:"previousStatus"`
+	Status                 string `json:"status"`
+	NetworkClass           int    `json:"networkClass"`
+	ChainID                int    `json:"chainId"`
+	ToAddress              string `json:"toAddress"`
+	TokenStandard          string `json:"tokenStandard"`
+	Amount                 string `json:"amount"`
+	Fee                    string `json:"fee"`
+	CreationMomentumHeight int64  `json:"creationMomentumHeight"`
+}
+
+// BridgeUnwrapStatusChanged is the bridge.unwrap.status_changed payload.
+// PreviousStatus is empty the first time the indexer sees the request.
+type BridgeUnwrapStatusChanged struct {
+	TransactionHash            string `json:"transactionHash"`
+	LogIndex                   int64  `json:"logIndex"`
+	PreviousStatus             string `json:"previousStatus"`
+	Status                     string `json:"status"`
+	NetworkClass               int    `json:"networkClass"`
+	ChainID                    int    `json:"chainId"`
+	ToAddress                  string `json:"toAddress"`
+	TokenStandard              string `json:"tokenStandard"`
+	Amount                     string `json:"amount"`
+	RegistrationMomentumHeight int64  `json:"registrationMomentumHeight"`
+}
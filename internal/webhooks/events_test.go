@@ -0,0 +1,34 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSchemaVersion_CoversEveryEventType(t *testing.T) {
+	for _, typ := range append(EventTypes, EventTest) {
+		if _, ok := schemaVersions[typ]; !ok {
+			t.Errorf("%s has no schema version", typ)
+		}
+	}
+	if got := SchemaVersion("from.the.future"); got != 1 {
+		t.Errorf("SchemaVersion(unknown) = %d, want 1", got)
+	}
+}
+
+func TestPayloads_FlattenSource(t *testing.T) {
+	b, err := json.Marshal(TokenBurned{
+		Source:        Source{Method: "Burn", AccountBlockHash: "ab", MomentumHeight: 7, MomentumTimestamp: 100},
+		TokenStandard: "zts1",
+		Burner:        "z1q",
+		Amount:        "123456789012345678901",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"method":"Burn","accountBlockHash":"ab","momentumHeight":7,"momentumTimestamp":100,` +
+		`"tokenStandard":"zts1","burner":"z1q","amount":"123456789012345678901"}`
+	if string(b) != want {
+		t.Errorf("got  %s\nwant %s", b, want)
+	}
+}
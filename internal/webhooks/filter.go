@@ -0,0 +1,280 @@
+package webhooks
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/zenon-network/go-zenon/common/types"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+// Filter limits, enforced by ValidateFilter so a single endpoint cannot
+// make routing expensive.
+const (
+	MaxFilterAddresses      = 1000
+	MaxFilterTokenStandards = 100
+	MaxFilterMethods        = 100
+)
+
+// attributes are the parts of an event a content filter can match on.
+// Zero fields mean the event does not carry that attribute.
+type attributes struct {
+	addresses     []string
+	tokenStandard string
+	method        string
+	blockType     int
+	amount        *big.Int
+}
+
+// filterable is implemented by payload types that expose attributes.
+// Payloads that do not implement it carry none and only reach endpoints
+// without a content filter.
+type filterable interface {
+	attributes() attributes
+}
+
+// eventAttributes extracts e's attributes.
+func eventAttributes(e Event) attributes {
+	if f, ok := e.Payload.(filterable); ok {
+		return f.attributes()
+	}
+	return attributes{}
+}
+
+// matcher is a compiled models.WebhookFilter. A nil *matcher matches
+// every event.
+type matcher struct {
+	addresses  map[string]bool
+	tokens     map[string]bool
+	methods    map[string]bool
+	blockTypes map[int]bool
+	minAmount  *big.Int
+}
+
+// ValidateFilter reports whether f is well formed: addresses and token
+// standards parse, block types are known, min_amount is a non-negative
+// integer, and no list exceeds its limit.
+func ValidateFilter(f models.WebhookFilter) error {
+	_, err := compileFilter(f)
+	return err
+}
+
+// compileFilter validates f and builds its matcher; nil when f is empty.
+func compileFilter(f models.WebhookFilter) (*matcher, error) {
+	if f.IsZero() {
+		return nil, nil
+	}
+	switch {
+	case len(f.Addresses) > MaxFilterAddresses:
+		return nil, fmt.Errorf("addresses: at most %d allowed", MaxFilterAddresses)
+	case len(f.TokenStandards) > MaxFilterTokenStandards:
+		return nil, fmt.Errorf("token_standards: at most %d allowed", MaxFilterTokenStandards)
+	case len(f.Methods) > MaxFilterMethods:
+		return nil, fmt.Errorf("methods: at most %d allowed", MaxFilterMethods)
+	}
+
+	m := &matcher{}
+	if len(f.Addresses) > 0 {
+		m.addresses = make(map[string]bool, len(f.Addresses))
+		for _, a := range f.Addresses {
+			addr, err := types.ParseAddress(a)
+			if err != nil {
+				return nil, fmt.Errorf("addresses: %q is not a valid address", a)
+			}
+			m.addresses[addr.String()] = true
+		}
+	}
+	if len(f.TokenStandards) > 0 {
+		m.tokens = make(map[string]bool, len(f.TokenStandards))
+		for _, t := range f.TokenStandards {
+			zts, err := types.ParseZTS(t)
+			if err != nil {
+				return nil, fmt.Errorf("token_standards: %q is not a valid token standard", t)
+			}
+			m.tokens[zts.String()] = true
+		}
+	}
+	if len(f.Methods) > 0 {
+		m.methods = make(map[string]bool, len(f.Methods))
+		for _, name := range f.Methods {
+			if name == "" {
+				return nil, errors.New("methods: empty method name")
+			}
+			m.methods[name] = true
+		}
+	}
+	if len(f.BlockTypes) > 0 {
+		m.blockTypes = make(map[int]bool, len(f.BlockTypes))
+		for _, bt := range f.BlockTypes {
+			if bt < models.BlockTypeGenesisReceive || bt > models.BlockTypeContractReceive {
+				return nil, fmt.Errorf("block_types: %d is not a block type (1-5)", bt)
+			}
+			m.blockTypes[bt] = true
+		}
+	}
+	if f.MinAmount != "" {
+		v, ok := new(big.Int).SetString(f.MinAmount, 10)
+		if !ok || v.Sign() < 0 {
+			return nil, fmt.Errorf("min_amount: %q is not a non-negative integer", f.MinAmount)
+		}
+		m.minAmount = v
+	}
+	return m, nil
+}
+
+// match reports whether an event with attributes a passes the filter.
+// Every configured dimension must match; within one, any value does. An
+// event that lacks a filtered attribute does not match.
+func (m *matcher) match(a attributes) bool {
+	if m == nil {
+		return true
+	}
+	if m.addresses != nil {
+		found := false
+		for _, addr := range a.addresses {
+			if m.addresses[addr] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if m.tokens != nil && !m.tokens[a.tokenStandard] {
+		return false
+	}
+	if m.methods != nil && !m.methods[a.method] {
+		return false
+	}
+	if m.blockTypes != nil && !m.blockTypes[a.blockType] {
+		return false
+	}
+	if m.minAmount != nil && (a.amount == nil || a.amount.Cmp(m.minAmount) < 0) {
+		return false
+	}
+	return true
+}
+
+// parseAmount reads a payload amount; nil when s is empty or malformed.
+func parseAmount(s string) *big.Int {
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil
+	}
+	return v
+}
+
+// nonEmpty drops empty strings, e.g. an unset counterparty.
+func nonEmpty(in ...string) []string {
+	out := in[:0]
+	for _, s := range in {
+		if s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func (p AccountBlockInserted) attributes() attributes {
+	return attributes{
+		addresses:     nonEmpty(p.Address, p.ToAddress),
+		tokenStandard: p.TokenStandard,
+		method:        p.Method,
+		blockType:     p.BlockType,
+		amount:        parseAmount(p.Amount),
+	}
+}
+
+func (p DelegationChanged) attributes() attributes {
+	return attributes{addresses: nonEmpty(p.Delegator, p.PillarOwner), method: p.Method}
+}
+
+func (p PillarRegistered) attributes() attributes {
+	return attributes{addresses: nonEmpty(p.Owner, p.ProducerAddress, p.RewardAddress), method: p.Method}
+}
+
+func (p PillarRevoked) attributes() attributes {
+	return attributes{addresses: nonEmpty(p.Owner), method: p.Method}
+}
+
+func (p StakeCreated) attributes() attributes {
+	return attributes{
+		addresses:     nonEmpty(p.Address),
+		tokenStandard: models.ZnnTokenStandard,
+		method:        p.Method,
+		amount:        parseAmount(p.ZnnAmount),
+	}
+}
+
+func (p StakeCancelled) attributes() attributes {
+	return attributes{addresses: nonEmpty(p.Address), method: p.Method}
+}
+
+func (p FusionCreated) attributes() attributes {
+	return attributes{
+		addresses:     nonEmpty(p.Address, p.Beneficiary),
+		tokenStandard: models.QsrTokenStandard,
+		method:        p.Method,
+		amount:        parseAmount(p.QsrAmount),
+	}
+}
+
+func (p HtlcCreated) attributes() attributes {
+	return attributes{
+		addresses:     nonEmpty(p.TimeLockedAddress, p.HashLockedAddress),
+		tokenStandard: p.TokenStandard,
+		method:        p.Method,
+		amount:        parseAmount(p.Amount),
+	}
+}
+
+func (p HtlcUnlocked) attributes() attributes {
+	return attributes{addresses: nonEmpty(p.Address), method: p.Method}
+}
+
+func (p HtlcReclaimed) attributes() attributes {
+	return attributes{addresses: nonEmpty(p.Address), method: p.Method}
+}
+
+func (p TokenMinted) attributes() attributes {
+	return attributes{
+		addresses:     nonEmpty(p.Issuer, p.Receiver),
+		tokenStandard: p.TokenStandard,
+		method:        p.Method,
+		amount:        parseAmount(p.Amount),
+	}
+}
+
+func (p TokenBurned) attributes() attributes {
+	return attributes{
+		addresses:     nonEmpty(p.Burner),
+		tokenStandard: p.TokenStandard,
+		method:        p.Method,
+		amount:        parseAmount(p.Amount),
+	}
+}
+
+func (p ProjectCreated) attributes() attributes {
+	return attributes{addresses: nonEmpty(p.Owner), method: p.Method}
+}
+
+func (p VoteCast) attributes() attributes {
+	return attributes{addresses: nonEmpty(p.Voter), method: p.Method}
+}
+
+// The wrap request's ToAddress is on the remote chain, so it is not a
+// filterable address.
+func (p BridgeWrapStatusChanged) attributes() attributes {
+	return attributes{tokenStandard: p.TokenStandard, amount: parseAmount(p.Amount)}
+}
+
+func (p BridgeUnwrapStatusChanged) attributes() attributes {
+	return attributes{
+		addresses:     nonEmpty(p.ToAddress),
+		tokenStandard: p.TokenStandard,
+		amount:        parseAmount(p.Amount),
+	}
+}
@@ -0,0 +1,145 @@
+package webhooks
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/0x3639/nom-indexer-go/internal/models"
+)
+
+func TestValidateFilter(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		f     models.WebhookFilter
+		valid bool
+	}{
+		{"empty", models.WebhookFilter{}, true},
+		{"full", models.WebhookFilter{
+			Addresses:      []string{models.PillarAddress},
+			TokenStandards: []string{models.ZnnTokenStandard},
+			Methods:        []string{"Delegate"},
+			BlockTypes:     []int{models.BlockTypeUserSend},
+			MinAmount:      "0",
+		}, true},
+		{"bad address", models.WebhookFilter{Addresses: []string{"z1nope"}}, false},
+		{"bad token", models.WebhookFilter{TokenStandards: []string{"ZNN"}}, false},
+		{"empty method", models.WebhookFilter{Methods: []string{""}}, false},
+		{"block type 0", models.WebhookFilter{BlockTypes: []int{0}}, false},
+		{"block type 6", models.WebhookFilter{BlockTypes: []int{6}}, false},
+		{"negative amount", models.WebhookFilter{MinAmount: "-5"}, false},
+		{"decimal amount", models.WebhookFilter{MinAmount: "1.5"}, false},
+		{"too many methods", models.WebhookFilter{Methods: make([]string, MaxFilterMethods+1)}, false},
+	} {
+		if err := ValidateFilter(tc.f); (err == nil) != tc.valid {
+			t.Errorf("%s: ValidateFilter = %v, want valid=%v", tc.name, err, tc.valid)
+		}
+	}
+}
+
+func TestMatcher(t *testing.T) {
+	send := AccountBlockInserted{
+		Address:       models.PillarAddress,
+		ToAddress:     models.StakeAddress,
+		BlockType:     models.BlockTypeUserSend,
+		TokenStandard: models.ZnnTokenStandard,
+		Amount:        "500000000",
+		Method:        "Stake",
+	}
+	for _, tc := range []struct {
+		name string
+		f    models.WebhookFilter
+		want bool
+	}{
+		{"no filter", models.WebhookFilter{}, true},
+		{"recipient address", models.WebhookFilter{Addresses: []string{models.StakeAddress}}, true},
+		{"any of several addresses", models.WebhookFilter{Addresses: []string{models.TokenAddress, models.PillarAddress}}, true},
+		{"other address", models.WebhookFilter{Addresses: []string{models.TokenAddress}}, false},
+		{"token", models.WebhookFilter{TokenStandards: []string{models.ZnnTokenStandard}}, true},
+		{"other token", models.WebhookFilter{TokenStandards: []string{models.QsrTokenStandard}}, false},
+		{"method", models.WebhookFilter{Methods: []string{"Stake"}}, true},
+		{"other method", models.WebhookFilter{Methods: []string{"Cancel"}}, false},
+		{"block type", models.WebhookFilter{BlockTypes: []int{models.BlockTypeUserSend, models.BlockTypeContractSend}}, true},
+		{"other block type", models.WebhookFilter{BlockTypes: []int{models.BlockTypeUserReceive}}, false},
+		{"amount equal to minimum", models.WebhookFilter{MinAmount: "500000000"}, true},
+		{"amount below minimum", models.WebhookFilter{MinAmount: "500000001"}, false},
+		{"all dimensions", models.WebhookFilter{
+			Addresses:      []string{models.PillarAddress},
+			TokenStandards: []string{models.ZnnTokenStandard},
+			Methods:        []string{"Stake"},
+			MinAmount:      "1",
+		}, true},
+		{"one dimension fails", models.WebhookFilter{
+			Addresses: []string{models.PillarAddress},
+			Methods:   []string{"Cancel"},
+		}, false},
+	} {
+		m, err := compileFilter(tc.f)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if got := m.match(send.attributes()); got != tc.want {
+			t.Errorf("%s: match = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestMatcher_MissingAttributeDoesNotMatch(t *testing.T) {
+	m, err := compileFilter(models.WebhookFilter{MinAmount: "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.match(StakeCancelled{Address: models.PillarAddress}.attributes()) {
+		t.Error("an event without an amount matched a min_amount filter")
+	}
+	if m.match(eventAttributes(Event{Type: EventMomentumInserted, Payload: MomentumInserted{Height: 1}})) {
+		t.Error("momentum.inserted matched a min_amount filter")
+	}
+	if !m.match(StakeCreated{ZnnAmount: "1"}.attributes()) {
+		t.Error("stake.created with znnAmount 1 did not match min_amount 1")
+	}
+}
+
+func TestDispatcher_OutboxAppliesContentFilters(t *testing.T) {
+	subs := &memSubs{}
+	subs.set(
+		&models.WebhookSubscription{ID: 1, URL: "http://whale", Status: models.WebhookSubscriptionActive,
+			Filter: models.WebhookFilter{TokenStandards: []string{models.ZnnTokenStandard}, MinAmount: "1000"}},
+		&models.WebhookSubscription{ID: 2, URL: "http://pillar", Status: models.WebhookSubscriptionActive,
+			Filter: models.WebhookFilter{Addresses: []string{models.PillarAddress}}},
+		// Bad rows (written around the API) are left out of routing.
+		&models.WebhookSubscription{ID: 3, URL: "http://broken", Status: models.WebhookSubscriptionActive,
+			Filter: models.WebhookFilter{BlockTypes: []int{42}}},
+	)
+	d := New([]Endpoint{{URL: "http://all"}}, &memStore{}, subs, Config{}, nil)
+	d.reload()
+
+	targets := func(e Event) []string {
+		t.Helper()
+		entries, err := d.Outbox(e, 1, time.Unix(100, 0))
+		if err != nil {
+			t.Fatalf("Outbox: %v", err)
+		}
+		var urls []string
+		for _, en := range entries {
+			urls = append(urls, en.EndpointURL)
+		}
+		return urls
+	}
+	large := Event{Type: EventAccountBlockInserted, Payload: AccountBlockInserted{
+		Address: models.PillarAddress, TokenStandard: models.ZnnTokenStandard, Amount: "5000",
+	}}
+	if got := targets(large); !slices.Equal(got, []string{"http://all", "http://whale", "http://pillar"}) {
+		t.Errorf("large ZNN send from the pillar address went to %v", got)
+	}
+	small := Event{Type: EventAccountBlockInserted, Payload: AccountBlockInserted{
+		Address: models.TokenAddress, TokenStandard: models.ZnnTokenStandard, Amount: "10",
+	}}
+	if got := targets(small); !slices.Equal(got, []string{"http://all"}) {
+		t.Errorf("small unrelated send went to %v", got)
+	}
+	// Reorgs carry no content but reach every subscriber.
+	if got := targets(Event{Type: EventReorg, Payload: Reorg{}}); !slices.Equal(got, []string{"http://all", "http://whale", "http://pillar"}) {
+		t.Errorf("reorg went to %v", got)
+	}
+}
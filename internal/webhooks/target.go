@@ -0,0 +1,113 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateTarget is returned for a runtime subscription URL whose host
+// is, or resolves to, an address on the indexer's own networks.
+//
+// Subscriptions are registered over the API by integrators, and the
+// delivery log shows them each attempt's status and latency, so a
+// subscription that could reach loopback, private, link-local (cloud
+// metadata at 169.254.169.254) or unspecified addresses would let them
+// probe internal services. The URL is checked when the subscription is
+// saved (CheckTarget) and every delivery dial is checked again
+// (subscriptionClient), so a DNS change after the save does not get past
+// it. Config endpoints are the operator's own and are not restricted.
+var ErrPrivateTarget = errors.New("address is not publicly routable")
+
+// blockedPrefixes are the non-public ranges netip has no predicate for:
+// "this network" and carrier-grade NAT (which also hosts some cloud
+// metadata services).
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// publicAddr reports whether a subscription may deliver to ip.
+func publicAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return false
+	}
+	for _, p := range blockedPrefixes {
+		if p.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckTarget returns an error wrapping ErrPrivateTarget when rawURL's
+// host is, or resolves to, an address a subscription may not reach. A
+// host that does not resolve is let through: nothing can be delivered
+// to it yet, and the dial-time check applies once it does resolve.
+func CheckTarget(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	host := u.Hostname()
+	if ip, err := netip.ParseAddr(host); err == nil {
+		if !publicAddr(ip) {
+			return fmt.Errorf("%s: %w", host, ErrPrivateTarget)
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil
+	}
+	for _, ip := range addrs {
+		if !publicAddr(ip) {
+			return fmt.Errorf("%s resolves to %s: %w", host, ip, ErrPrivateTarget)
+		}
+	}
+	return nil
+}
+
+// checkDial is a net.Dialer Control hook refusing connections to
+// non-public addresses. It runs after name resolution, on the address
+// actually dialed.
+func checkDial(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil || !publicAddr(ip) {
+		return fmt.Errorf("dial %s: %w", address, ErrPrivateTarget)
+	}
+	return nil
+}
+
+// noRedirects makes a client return a redirect response as is. A
+// webhook receiver has no reason to redirect, and following one would
+// let a subscription reach a host its URL was not checked against; the
+// 3xx counts as a failed delivery.
+func noRedirects(*http.Request, []*http.Request) error {
+	return http.ErrUseLastResponse
+}
+
+// subscriptionClient is the HTTP client for runtime subscriptions: every
+// dial is checked with checkDial, and no proxy is used, since the proxy
+// would make the dial and the check would see only its address.
+func subscriptionClient(timeout time.Duration) *http.Client {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = nil
+	t.DialContext = (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   checkDial,
+	}).DialContext
+	return &http.Client{Timeout: timeout, Transport: t, CheckRedirect: noRedirects}
+}
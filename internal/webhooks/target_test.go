@@ -0,0 +1,79 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestPublicAddr(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"1.1.1.1":         true,
+		"2606:4700::1111": true,
+		"127.0.0.1":       false,
+		"::1":             false,
+		"10.1.2.3":        false,
+		"172.16.0.1":      false,
+		"192.168.1.1":     false,
+		"169.254.169.254": false,
+		"fe80::1":         false,
+		"fd00:ec2::254":   false,
+		"0.0.0.0":         false,
+		"::":              false,
+		"100.100.100.200": false,
+		"::ffff:10.0.0.1": false,
+		"224.0.0.1":       false,
+	} {
+		if got := publicAddr(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("publicAddr(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestCheckTarget(t *testing.T) {
+	for _, u := range []string{"http://127.0.0.1/hook", "http://169.254.169.254/latest", "http://[::1]:8080/"} {
+		if err := CheckTarget(context.Background(), u); !errors.Is(err, ErrPrivateTarget) {
+			t.Errorf("CheckTarget(%s) = %v, want ErrPrivateTarget", u, err)
+		}
+	}
+	if err := CheckTarget(context.Background(), "https://1.1.1.1/hook"); err != nil {
+		t.Errorf("public address rejected: %v", err)
+	}
+}
+
+func TestSubscriptionClient_RefusesLoopback(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	_, err := subscriptionClient(time.Second).Post(srv.URL, "application/json", nil)
+	if !errors.Is(err, ErrPrivateTarget) {
+		t.Fatalf("delivery to %s: err = %v, want ErrPrivateTarget", srv.URL, err)
+	}
+}
+
+func TestDispatcher_DoesNotFollowRedirects(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		t.Error("redirect was followed")
+	}))
+	defer target.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer srv.Close()
+
+	d := New(nil, &memStore{}, nil, Config{}, nil)
+	resp, err := d.client.Post(srv.URL, "application/json", nil)
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusTemporaryRedirect {
+		t.Errorf("status = %d, want the 307 itself", resp.StatusCode)
+	}
+}
@@ -0,0 +1,50 @@
+-- Narrowing back to BIGINT fails with "bigint out of range" if any row holds
+-- a value above math.MaxInt64; clamp or delete those rows first.
+ALTER TABLE bridge_stat_histories
+    ALTER COLUMN total_volume     TYPE BIGINT,
+    ALTER COLUMN unwrapped_amount TYPE BIGINT,
+    ALTER COLUMN wrapped_amount   TYPE BIGINT;
+
+ALTER TABLE token_stat_histories
+    ALTER COLUMN total_supply TYPE BIGINT,
+    ALTER COLUMN daily_burned TYPE BIGINT,
+    ALTER COLUMN daily_minted TYPE BIGINT;
+
+ALTER TABLE unwrap_token_requests
+    ALTER COLUMN amount TYPE BIGINT;
+
+ALTER TABLE wrap_token_requests
+    ALTER COLUMN fee    TYPE BIGINT,
+    ALTER COLUMN amount TYPE BIGINT;
+
+ALTER TABLE htlcs
+    ALTER COLUMN amount TYPE BIGINT;
+
+ALTER TABLE fusions
+    ALTER COLUMN qsr_amount TYPE BIGINT;
+
+ALTER TABLE stakes
+    ALTER COLUMN znn_amount TYPE BIGINT;
+
+ALTER TABLE reward_transactions
+    ALTER COLUMN amount TYPE BIGINT;
+
+ALTER TABLE cumulative_rewards
+    ALTER COLUMN amount TYPE BIGINT;
+
+ALTER TABLE token_burns
+    ALTER COLUMN amount TYPE BIGINT;
+
+ALTER TABLE token_mints
+    ALTER COLUMN amount TYPE BIGINT;
+
+ALTER TABLE tokens
+    ALTER COLUMN total_burned TYPE BIGINT,
+    ALTER COLUMN max_supply   TYPE BIGINT,
+    ALTER COLUMN total_supply TYPE BIGINT;
+
+ALTER TABLE balances
+    ALTER COLUMN balance TYPE BIGINT;
+
+ALTER TABLE account_blocks
+    ALTER COLUMN amount TYPE BIGINT;
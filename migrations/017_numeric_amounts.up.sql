@@ -0,0 +1,56 @@
+-- migrations/017_numeric_amounts.up.sql
+-- Widen token amount columns from BIGINT to NUMERIC(78,0) so values above
+-- math.MaxInt64 are stored exactly instead of being capped. 78 digits holds
+-- any uint256. Columns that only ever carry ZNN/QSR (account flows, genesis
+-- balances, pillar weights, AZ funds, swap amounts) stay BIGINT.
+--
+-- Each ALTER rewrites its table; on a large account_blocks table expect this
+-- migration to take a while and to hold an ACCESS EXCLUSIVE lock throughout.
+ALTER TABLE account_blocks
+    ALTER COLUMN amount TYPE NUMERIC(78,0);
+
+ALTER TABLE balances
+    ALTER COLUMN balance TYPE NUMERIC(78,0);
+
+ALTER TABLE tokens
+    ALTER COLUMN total_supply TYPE NUMERIC(78,0),
+    ALTER COLUMN max_supply   TYPE NUMERIC(78,0),
+    ALTER COLUMN total_burned TYPE NUMERIC(78,0);
+
+ALTER TABLE token_mints
+    ALTER COLUMN amount TYPE NUMERIC(78,0);
+
+ALTER TABLE token_burns
+    ALTER COLUMN amount TYPE NUMERIC(78,0);
+
+ALTER TABLE cumulative_rewards
+    ALTER COLUMN amount TYPE NUMERIC(78,0);
+
+ALTER TABLE reward_transactions
+    ALTER COLUMN amount TYPE NUMERIC(78,0);
+
+ALTER TABLE stakes
+    ALTER COLUMN znn_amount TYPE NUMERIC(78,0);
+
+ALTER TABLE fusions
+    ALTER COLUMN qsr_amount TYPE NUMERIC(78,0);
+
+ALTER TABLE htlcs
+    ALTER COLUMN amount TYPE NUMERIC(78,0);
+
+ALTER TABLE wrap_token_requests
+    ALTER COLUMN amount TYPE NUMERIC(78,0),
+    ALTER COLUMN fee    TYPE NUMERIC(78,0);
+
+ALTER TABLE unwrap_token_requests
+    ALTER COLUMN amount TYPE NUMERIC(78,0);
+
+ALTER TABLE token_stat_histories
+    ALTER COLUMN daily_minted TYPE NUMERIC(78,0),
+    ALTER COLUMN daily_burned TYPE NUMERIC(78,0),
+    ALTER COLUMN total_supply TYPE NUMERIC(78,0);
+
+ALTER TABLE bridge_stat_histories
+    ALTER COLUMN wrapped_amount   TYPE NUMERIC(78,0),
+    ALTER COLUMN unwrapped_amount TYPE NUMERIC(78,0),
+    ALTER COLUMN total_volume     TYPE NUMERIC(78,0);
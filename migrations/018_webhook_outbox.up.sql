@@ -0,0 +1,50 @@
+-- Durable webhook delivery. The indexer writes one outbox row per
+-- (event, subscribed endpoint) inside the same transaction as the
+-- momentum (or reorg rollback) that produced the event, so an event
+-- exists exactly when the data it describes is committed. The delivery
+-- worker in internal/webhooks drains due rows, records every HTTP
+-- attempt, and reschedules failures with exponential backoff until
+-- webhooks.max_retries is exhausted, at which point the row is parked
+-- as 'dead' for an operator to replay (cmd/webhook-replay).
+--
+-- Timestamps are unix seconds, like indexer_sync_status.
+CREATE TABLE IF NOT EXISTS webhook_outbox (
+    id              BIGSERIAL PRIMARY KEY,
+    endpoint_url    TEXT     NOT NULL,
+    event_type      TEXT     NOT NULL,
+    payload         JSONB    NOT NULL,
+    momentum_height BIGINT   NOT NULL,
+    status          TEXT     NOT NULL DEFAULT 'pending'
+                    CHECK (status IN ('pending', 'delivered', 'dead')),
+    attempts        INTEGER  NOT NULL DEFAULT 0,
+    next_attempt_at BIGINT   NOT NULL,
+    last_error      TEXT,
+    created_at      BIGINT   NOT NULL,
+    delivered_at    BIGINT,
+    dead_at         BIGINT
+);
+
+-- The worker's claim query: due pending rows in insertion order.
+CREATE INDEX IF NOT EXISTS idx_webhook_outbox_due
+    ON webhook_outbox (next_attempt_at, id) WHERE status = 'pending';
+-- Dead-letter listing and replay, per endpoint.
+CREATE INDEX IF NOT EXISTS idx_webhook_outbox_dead
+    ON webhook_outbox (endpoint_url, id) WHERE status = 'dead';
+-- Reorg rollback discards undelivered rows above the common ancestor.
+CREATE INDEX IF NOT EXISTS idx_webhook_outbox_height
+    ON webhook_outbox (momentum_height) WHERE status = 'pending';
+
+-- One row per HTTP attempt. status_code is NULL when the request never
+-- got a response (DNS, connect, timeout).
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempts (
+    id           BIGSERIAL PRIMARY KEY,
+    outbox_id    BIGINT   NOT NULL REFERENCES webhook_outbox(id) ON DELETE CASCADE,
+    attempt      INTEGER  NOT NULL,
+    attempted_at BIGINT   NOT NULL,
+    status_code  INTEGER,
+    error        TEXT,
+    duration_ms  INTEGER  NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_attempts_outbox
+    ON webhook_delivery_attempts (outbox_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_webhook_outbox_subscription;
+ALTER TABLE webhook_outbox DROP COLUMN IF EXISTS subscription_id;
+DROP TABLE IF EXISTS webhook_subscriptions;
@@ -0,0 +1,35 @@
+-- Runtime-managed webhook subscriptions, created and edited through the
+-- authenticated /api/v1/webhooks endpoints. They sit alongside the static
+-- webhooks.endpoints list from config.yaml; the indexer's dispatcher
+-- reloads this table periodically, so changes apply without a restart.
+--
+-- owner is the JWT subject that created the row; every API call is
+-- scoped to it. secret signs deliveries and is only ever returned by
+-- create and rotate-secret. events is the allowlist of event types
+-- (empty = all).
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id                BIGSERIAL PRIMARY KEY,
+    owner             TEXT     NOT NULL,
+    url               TEXT     NOT NULL,
+    events            TEXT[]   NOT NULL DEFAULT '{}',
+    description       TEXT     NOT NULL DEFAULT '',
+    secret            TEXT     NOT NULL,
+    status            TEXT     NOT NULL DEFAULT 'active'
+                      CHECK (status IN ('active', 'paused')),
+    created_at        BIGINT   NOT NULL,
+    updated_at        BIGINT   NOT NULL,
+    secret_rotated_at BIGINT   NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_owner
+    ON webhook_subscriptions (owner, id);
+
+-- Outbox rows queued for a subscription point at it; rows for static
+-- config endpoints leave it NULL and are matched by endpoint_url.
+-- Deleting a subscription drops its queue and delivery log with it.
+ALTER TABLE webhook_outbox
+    ADD COLUMN IF NOT EXISTS subscription_id BIGINT
+        REFERENCES webhook_subscriptions(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_webhook_outbox_subscription
+    ON webhook_outbox (subscription_id, id) WHERE subscription_id IS NOT NULL;
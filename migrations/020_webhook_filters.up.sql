@@ -0,0 +1,8 @@
+-- Content filters for runtime webhook subscriptions: addresses, token
+-- standards, embedded-contract methods, block types and a minimum
+-- amount, all optional. Stored as a JSON object (see
+-- models.WebhookFilter); '{}' means no content filtering. The dispatcher
+-- evaluates them when routing events, so a filtered-out event never
+-- gets an outbox row for the subscription.
+ALTER TABLE webhook_subscriptions
+    ADD COLUMN IF NOT EXISTS filter JSONB NOT NULL DEFAULT '{}';
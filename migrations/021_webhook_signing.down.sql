@@ -0,0 +1,5 @@
+ALTER TABLE webhook_subscriptions
+    DROP COLUMN IF EXISTS previous_secret_expires_at,
+    DROP COLUMN IF EXISTS previous_secret;
+
+ALTER TABLE webhook_outbox DROP COLUMN IF EXISTS delivery_id;
@@ -0,0 +1,14 @@
+-- Replay-safe webhook signing. Every outbox row gets a delivery id, sent
+-- in the envelope and the X-Webhook-ID header and kept across retries,
+-- so subscribers can deduplicate at-least-once deliveries. Existing rows
+-- are backfilled by the column default.
+--
+-- Rotating a subscription's secret keeps the old one in previous_secret
+-- until previous_secret_expires_at (unix seconds); until then every
+-- delivery is signed with both. 0 means no previous secret is in use.
+ALTER TABLE webhook_outbox
+    ADD COLUMN IF NOT EXISTS delivery_id UUID NOT NULL DEFAULT gen_random_uuid();
+
+ALTER TABLE webhook_subscriptions
+    ADD COLUMN IF NOT EXISTS previous_secret            TEXT   NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS previous_secret_expires_at BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,20 @@
+-- Progress of cmd/backfill runs, so an interrupted run resumes where it
+-- stopped instead of rescanning from height 1. One row per named run;
+-- the default name is derived from the run's flags, so re-running the
+-- same command picks its row up again.
+--
+-- next_height is the first height not yet known to be done; heights
+-- below it in [from_height, to_height] are finished. contracts is empty
+-- unless the run re-ran only some embedded-contract handlers. Timestamps
+-- are unix seconds.
+CREATE TABLE IF NOT EXISTS backfill_checkpoints (
+    name         TEXT PRIMARY KEY,
+    mode         TEXT     NOT NULL CHECK (mode IN ('gaps', 'reprocess')),
+    contracts    TEXT[]   NOT NULL DEFAULT '{}',
+    from_height  BIGINT   NOT NULL,
+    to_height    BIGINT   NOT NULL,
+    next_height  BIGINT   NOT NULL,
+    started_at   BIGINT   NOT NULL,
+    updated_at   BIGINT   NOT NULL,
+    completed_at BIGINT
+);
@@ -0,0 +1,19 @@
+-- Heights the indexer could not process. Backfill records a height here
+-- instead of leaving a hole it cannot see again; the indexer's retrier
+-- re-processes due rows with exponential backoff and deletes each one
+-- once its height is complete. A height that keeps failing stays
+-- listed (GET /api/v1/failed-heights) with its latest error.
+--
+-- Timestamps are unix seconds, like indexer_sync_status.
+CREATE TABLE IF NOT EXISTS indexer_failed_heights (
+    height          BIGINT   PRIMARY KEY,
+    last_error      TEXT     NOT NULL,
+    attempts        INTEGER  NOT NULL DEFAULT 1,
+    first_failed_at BIGINT   NOT NULL,
+    last_failed_at  BIGINT   NOT NULL,
+    next_retry_at   BIGINT   NOT NULL
+);
+
+-- The retrier's claim query: due rows, lowest height first.
+CREATE INDEX IF NOT EXISTS idx_indexer_failed_heights_due
+    ON indexer_failed_heights (next_retry_at, height);
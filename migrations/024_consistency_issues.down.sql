@@ -0,0 +1,2 @@
+ALTER TABLE indexer_failed_heights DROP COLUMN IF EXISTS reprocess;
+DROP TABLE IF EXISTS consistency_issues;
@@ -0,0 +1,31 @@
+-- Differences between the indexed data and the node, found by
+-- cmd/verify or the indexer's verify job. One row per check and subject
+-- (a height, an address, a token standard or a pillar owner) while the
+-- difference stands; resolved_at is set once a later run finds the
+-- subject matching again, and a difference found after that opens a
+-- new row. db_value and node_value are the two sides as the check
+-- renders them.
+--
+-- Timestamps are unix seconds, like indexer_failed_heights.
+CREATE TABLE IF NOT EXISTS consistency_issues (
+    id               BIGSERIAL PRIMARY KEY,
+    check_name       TEXT     NOT NULL,
+    subject          TEXT     NOT NULL,
+    kind             TEXT     NOT NULL CHECK (kind IN ('differs', 'missing_in_db', 'missing_on_node')),
+    db_value         TEXT     NOT NULL DEFAULT '',
+    node_value       TEXT     NOT NULL DEFAULT '',
+    occurrences      INTEGER  NOT NULL DEFAULT 1,
+    first_seen_at    BIGINT   NOT NULL,
+    last_seen_at     BIGINT   NOT NULL,
+    repair_queued_at BIGINT,
+    resolved_at      BIGINT
+);
+
+-- At most one open issue per subject; the verifier upserts against it.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_consistency_issues_open
+    ON consistency_issues (check_name, subject) WHERE resolved_at IS NULL;
+
+-- Heights the verifier re-queues are complete, so the retrier must
+-- reprocess them instead of dropping them as already filled.
+ALTER TABLE indexer_failed_heights
+    ADD COLUMN IF NOT EXISTS reprocess BOOLEAN NOT NULL DEFAULT false;
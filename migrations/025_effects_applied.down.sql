@@ -0,0 +1,2 @@
+ALTER TABLE account_blocks DROP COLUMN IF EXISTS effects_applied;
+ALTER TABLE momentums DROP COLUMN IF EXISTS effects_applied;
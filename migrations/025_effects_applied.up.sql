@@ -0,0 +1,16 @@
+-- Marks the momentums and account blocks whose additive counters have
+-- been applied: pillars.produced_momentum_count for a momentum; account
+-- flow totals and tx_count, tokens.transaction_count and total_burned,
+-- and cumulative_rewards for an account block. Each counter update is
+-- gated on its row's flag, and the momentum's transaction sets the flags
+-- last, so reprocessing a height (a subscription retry, a backfill or a
+-- verify repair) does not count it again.
+--
+-- Rows already indexed were counted when they were written: they get
+-- true, and only rows inserted from now on start out false. With a
+-- constant default, ADD COLUMN does not rewrite the table.
+ALTER TABLE momentums ADD COLUMN IF NOT EXISTS effects_applied BOOLEAN NOT NULL DEFAULT true;
+ALTER TABLE momentums ALTER COLUMN effects_applied SET DEFAULT false;
+
+ALTER TABLE account_blocks ADD COLUMN IF NOT EXISTS effects_applied BOOLEAN NOT NULL DEFAULT true;
+ALTER TABLE account_blocks ALTER COLUMN effects_applied SET DEFAULT false;
@@ -0,0 +1,19 @@
+-- Sporks: protocol feature switches, indexed from the Spork contract's
+-- CreateSpork and ActivateSpork calls. A spork is created inactive by
+-- the spork address (or, within a fixed height window, the community
+-- spork address) and activated once; the node enforces it from
+-- enforcement_height, the activation's frontier momentum plus 6.
+CREATE TABLE IF NOT EXISTS sporks (
+    id                             TEXT PRIMARY KEY,          -- CreateSpork send-block hash (64-hex)
+    name                           TEXT    NOT NULL DEFAULT '',
+    description                    TEXT    NOT NULL DEFAULT '',
+    creator_address                TEXT    NOT NULL DEFAULT '', -- sender of CreateSpork
+    activated                      BOOLEAN NOT NULL DEFAULT false,
+    enforcement_height             BIGINT  NOT NULL DEFAULT 0, -- 0 until activated
+    creation_momentum_height       BIGINT  NOT NULL DEFAULT 0,
+    creation_momentum_timestamp    BIGINT  NOT NULL DEFAULT 0,
+    activation_momentum_height     BIGINT  NOT NULL DEFAULT 0,
+    activation_momentum_timestamp  BIGINT  NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_sporks_creation_height ON sporks (creation_momentum_height);
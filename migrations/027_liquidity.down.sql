@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS liquidity_config;
+DROP TABLE IF EXISTS liquidity_stakes;
@@ -0,0 +1,38 @@
+-- Liquidity program state. liquidity_stakes is indexed from the Liquidity
+-- contract's LiquidityStake / CancelLiquidityStake /
+-- UnlockLiquidityStakeEntries receive blocks; liquidity_config is a
+-- singleton refreshed from LiquidityApi on the cached-data cadence,
+-- because the administrator's configuration calls only take effect once
+-- their time challenge has passed.
+CREATE TABLE IF NOT EXISTS liquidity_stakes (
+    id                       TEXT PRIMARY KEY,                -- LiquidityStake send-block hash (64-hex)
+    stake_address            TEXT          NOT NULL DEFAULT '',
+    token_standard           TEXT          NOT NULL DEFAULT '', -- staked LP token
+    amount                   NUMERIC(78,0) NOT NULL DEFAULT 0,
+    weighted_amount          NUMERIC(78,0) NOT NULL DEFAULT 0,  -- amount x months locked
+    duration_in_sec          BIGINT        NOT NULL DEFAULT 0,
+    start_timestamp          BIGINT        NOT NULL DEFAULT 0,  -- Unix seconds
+    expiration_timestamp     BIGINT        NOT NULL DEFAULT 0,  -- Unix seconds; pulled in by an unlock
+    revoke_timestamp         BIGINT        NOT NULL DEFAULT 0,  -- Unix seconds; 0 while active
+    is_active                BOOLEAN       NOT NULL DEFAULT true,
+    creation_momentum_height BIGINT        NOT NULL DEFAULT 0,
+    cancel_momentum_height   BIGINT        NOT NULL DEFAULT 0,  -- height of CancelLiquidityStake
+    unlock_momentum_height   BIGINT        NOT NULL DEFAULT 0   -- height of UnlockLiquidityStakeEntries
+);
+
+CREATE INDEX IF NOT EXISTS idx_liquidity_stakes_address ON liquidity_stakes (stake_address);
+CREATE INDEX IF NOT EXISTS idx_liquidity_stakes_token ON liquidity_stakes (token_standard);
+CREATE INDEX IF NOT EXISTS idx_liquidity_stakes_active ON liquidity_stakes (is_active);
+
+CREATE TABLE IF NOT EXISTS liquidity_config (
+    row_id                 SMALLINT PRIMARY KEY CHECK (row_id = 1),
+    administrator          TEXT          NOT NULL DEFAULT '',
+    is_halted              BOOLEAN       NOT NULL DEFAULT false,
+    znn_reward             NUMERIC(78,0) NOT NULL DEFAULT 0,    -- additional ZNN per epoch
+    qsr_reward             NUMERIC(78,0) NOT NULL DEFAULT 0,    -- additional QSR per epoch
+    token_tuples           JSONB         NOT NULL DEFAULT '[]', -- [{token_standard, znn_percentage, qsr_percentage, min_amount}]
+    guardians              TEXT[]        NOT NULL DEFAULT '{}',
+    administrator_delay    BIGINT        NOT NULL DEFAULT 0,    -- momentums
+    soft_delay             BIGINT        NOT NULL DEFAULT 0,    -- momentums
+    last_updated_timestamp BIGINT        NOT NULL DEFAULT 0
+);
@@ -0,0 +1,19 @@
+-- Every call into the Bridge contract, decoded from the ledger: one row
+-- per contract-receive block on the bridge address. wrap_token_requests
+-- and unwrap_token_requests are polled from BridgeApi; this table is the
+-- on-chain record of who called what, and in which momentum.
+CREATE TABLE IF NOT EXISTS bridge_events (
+    account_block_hash TEXT PRIMARY KEY,                  -- contract receive block
+    send_block_hash    TEXT          NOT NULL DEFAULT '', -- the user send that made the call
+    method             TEXT          NOT NULL DEFAULT '', -- ABI method, e.g. WrapToken, Halt
+    address            TEXT          NOT NULL DEFAULT '', -- caller
+    token_standard     TEXT          NOT NULL DEFAULT '', -- token sent with the call
+    amount             NUMERIC(78,0) NOT NULL DEFAULT 0,
+    inputs             JSONB         NOT NULL DEFAULT '{}', -- decoded ABI inputs
+    momentum_height    BIGINT        NOT NULL DEFAULT 0,
+    momentum_timestamp BIGINT        NOT NULL DEFAULT 0   -- Unix seconds
+);
+
+CREATE INDEX IF NOT EXISTS idx_bridge_events_momentum_height ON bridge_events (momentum_height);
+CREATE INDEX IF NOT EXISTS idx_bridge_events_method ON bridge_events (method);
+CREATE INDEX IF NOT EXISTS idx_bridge_events_address ON bridge_events (address);
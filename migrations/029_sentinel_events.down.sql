@@ -0,0 +1 @@
+DROP TABLE IF EXISTS sentinel_events;
@@ -0,0 +1,18 @@
+-- Sentinel lifecycle, decoded from the ledger: one row per accepted
+-- DepositQsr, WithdrawQsr, Register, Revoke or CollectReward call on the
+-- Sentinel contract. sentinels stays the node's current view, refreshed
+-- from SentinelApi; this table is the history behind it.
+CREATE TABLE IF NOT EXISTS sentinel_events (
+    account_block_hash TEXT PRIMARY KEY,                  -- contract receive block
+    send_block_hash    TEXT          NOT NULL DEFAULT '', -- the owner's send that made the call
+    owner              TEXT          NOT NULL DEFAULT '',
+    method             TEXT          NOT NULL DEFAULT '', -- DepositQsr, WithdrawQsr, Register, Revoke, CollectReward
+    znn_amount         NUMERIC(78,0) NOT NULL DEFAULT 0,  -- ZNN locked, returned or collected
+    qsr_amount         NUMERIC(78,0) NOT NULL DEFAULT 0,  -- QSR deposited, locked, returned or collected
+    momentum_height    BIGINT        NOT NULL DEFAULT 0,
+    momentum_timestamp BIGINT        NOT NULL DEFAULT 0   -- Unix seconds
+);
+
+CREATE INDEX IF NOT EXISTS idx_sentinel_events_owner ON sentinel_events (owner, momentum_height);
+CREATE INDEX IF NOT EXISTS idx_sentinel_events_momentum_height ON sentinel_events (momentum_height);
+CREATE INDEX IF NOT EXISTS idx_sentinel_events_method ON sentinel_events (method);
@@ -0,0 +1,25 @@
+-- Token control history, decoded from the ledger: one row per accepted
+-- IssueToken or UpdateToken call on the token contract, with the owner
+-- and mintable/burnable flags before and after. tokens only holds the
+-- current values; token_mints and token_burns track supply.
+CREATE TABLE IF NOT EXISTS token_events (
+    account_block_hash TEXT PRIMARY KEY,                  -- token contract receive block
+    send_block_hash    TEXT    NOT NULL DEFAULT '',       -- the caller's send
+    token_standard     TEXT    NOT NULL DEFAULT '',
+    method             TEXT    NOT NULL DEFAULT '',       -- IssueToken, UpdateToken
+    address            TEXT    NOT NULL DEFAULT '',       -- caller
+    account_height     BIGINT  NOT NULL DEFAULT 0,        -- token contract chain height; orders events
+    owner_before       TEXT,                              -- NULL for IssueToken
+    owner_after        TEXT    NOT NULL DEFAULT '',
+    is_mintable_before BOOLEAN,
+    is_mintable_after  BOOLEAN NOT NULL DEFAULT false,
+    is_burnable_before BOOLEAN,
+    is_burnable_after  BOOLEAN NOT NULL DEFAULT false,
+    inputs             JSONB   NOT NULL DEFAULT '{}',     -- decoded ABI inputs; issuance parameters for IssueToken
+    momentum_height    BIGINT  NOT NULL DEFAULT 0,
+    momentum_timestamp BIGINT  NOT NULL DEFAULT 0         -- Unix seconds
+);
+
+CREATE INDEX IF NOT EXISTS idx_token_events_token ON token_events (token_standard, account_height);
+CREATE INDEX IF NOT EXISTS idx_token_events_momentum_height ON token_events (momentum_height);
+CREATE INDEX IF NOT EXISTS idx_token_events_owner_after ON token_events (owner_after);
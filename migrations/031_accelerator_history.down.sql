@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS accelerator_payouts;
+DROP TABLE IF EXISTS project_status_changes;
@@ -0,0 +1,44 @@
+-- Accelerator-Z history, decoded from the ledger. projects and
+-- project_phases only hold the node's current view; these tables record
+-- when each status changed and what the contract paid out.
+
+-- One row per status change of a project or phase. Statuses use the
+-- contract's enum: 0 voting, 1 active, 2 paid, 3 closed, 4 completed.
+CREATE TABLE IF NOT EXISTS project_status_changes (
+    account_block_hash TEXT     NOT NULL,               -- accelerator receive block that made the change
+    project_id         TEXT     NOT NULL,
+    phase_id           TEXT     NOT NULL DEFAULT '',    -- '' for the project's own status
+    voting_id          TEXT     NOT NULL DEFAULT '',
+    method             TEXT     NOT NULL DEFAULT '',    -- CreateProject, AddPhase, UpdatePhase, Update
+    address            TEXT     NOT NULL DEFAULT '',    -- caller
+    old_status         SMALLINT,                        -- NULL when the project or phase is created
+    new_status         SMALLINT NOT NULL,
+    znn_amount         NUMERIC(78,0) NOT NULL DEFAULT 0, -- funds asked for on creation, paid out on 'paid'
+    qsr_amount         NUMERIC(78,0) NOT NULL DEFAULT 0,
+    momentum_height    BIGINT   NOT NULL DEFAULT 0,
+    momentum_timestamp BIGINT   NOT NULL DEFAULT 0,     -- Unix seconds
+    PRIMARY KEY (account_block_hash, project_id, phase_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_status_changes_project ON project_status_changes (project_id, momentum_height);
+CREATE INDEX IF NOT EXISTS idx_project_status_changes_phase ON project_status_changes (phase_id) WHERE phase_id <> '';
+CREATE INDEX IF NOT EXISTS idx_project_status_changes_momentum_height ON project_status_changes (momentum_height);
+
+-- One row per phase payout send from the accelerator contract: a ZNN and
+-- a QSR send to the project owner for each paid phase.
+CREATE TABLE IF NOT EXISTS accelerator_payouts (
+    account_block_hash TEXT PRIMARY KEY,                -- the accelerator's contract send
+    update_block_hash  TEXT NOT NULL DEFAULT '',        -- the Update receive that paid it
+    project_id         TEXT NOT NULL DEFAULT '',
+    phase_id           TEXT NOT NULL DEFAULT '',
+    to_address         TEXT NOT NULL DEFAULT '',
+    token_standard     TEXT NOT NULL DEFAULT '',
+    amount             NUMERIC(78,0) NOT NULL DEFAULT 0,
+    momentum_height    BIGINT NOT NULL DEFAULT 0,
+    momentum_timestamp BIGINT NOT NULL DEFAULT 0        -- Unix seconds
+);
+
+CREATE INDEX IF NOT EXISTS idx_accelerator_payouts_project ON accelerator_payouts (project_id, momentum_height);
+CREATE INDEX IF NOT EXISTS idx_accelerator_payouts_phase ON accelerator_payouts (phase_id);
+CREATE INDEX IF NOT EXISTS idx_accelerator_payouts_to_address ON accelerator_payouts (to_address);
+CREATE INDEX IF NOT EXISTS idx_accelerator_payouts_momentum_height ON accelerator_payouts (momentum_height);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_pillar_updates_name_history;
+DROP INDEX IF EXISTS idx_pillar_updates_account_block_hash;
+
+DELETE FROM pillar_updates WHERE method = 'Revoke';
+
+ALTER TABLE pillar_updates
+    DROP COLUMN IF EXISTS account_block_hash,
+    DROP COLUMN IF EXISTS method;
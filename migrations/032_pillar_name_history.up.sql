@@ -0,0 +1,34 @@
+-- pillar_updates becomes the pillar name history: each row records the
+-- pillar contract call that wrote it, and accepted Revoke calls get rows
+-- of their own, so the owner of a name can be resolved at any height.
+ALTER TABLE pillar_updates
+    ADD COLUMN IF NOT EXISTS method TEXT NOT NULL DEFAULT '',             -- Register, RegisterLegacy, UpdatePillar, Revoke
+    ADD COLUMN IF NOT EXISTS account_block_hash TEXT NOT NULL DEFAULT ''; -- pillar contract receive block
+
+-- Fill both for existing rows from the call that wrote them. Duplicates
+-- left by reprocessing keep an empty hash so the unique index holds.
+WITH matched AS (
+    SELECT DISTINCT ON (pu.id) pu.id, s.method, r.hash
+    FROM pillar_updates pu
+    JOIN account_blocks r
+      ON r.address = 'z1qxemdeddedxpyllarxxxxxxxxxxxxxxxsy3fmg'
+     AND r.momentum_height = pu.momentum_height
+    JOIN account_blocks s ON s.hash = r.paired_account_block
+    WHERE s.method IN ('Register', 'RegisterLegacy', 'UpdatePillar')
+      AND s.input->>'name' = pu.name
+      AND s.address = pu.owner_address
+    ORDER BY pu.id, r.height
+), ranked AS (
+    SELECT id, method, hash, ROW_NUMBER() OVER (PARTITION BY hash ORDER BY id) AS n
+    FROM matched
+)
+UPDATE pillar_updates pu
+SET method = ranked.method,
+    account_block_hash = CASE WHEN ranked.n = 1 THEN ranked.hash ELSE '' END
+FROM ranked
+WHERE ranked.id = pu.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pillar_updates_account_block_hash
+    ON pillar_updates (account_block_hash) WHERE account_block_hash <> '';
+CREATE INDEX IF NOT EXISTS idx_pillar_updates_name_history
+    ON pillar_updates (name, momentum_height) WHERE method IN ('Register', 'RegisterLegacy', 'Revoke');
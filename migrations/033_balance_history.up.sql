@@ -0,0 +1,44 @@
+-- One row per momentum that changed an (address, token) balance: the
+-- balance after the momentum and the signed change in it. Written in the
+-- momentum's transaction from the same deltas as balances; the token
+-- contract, whose balances are read from the node, has no history.
+CREATE TABLE IF NOT EXISTS balance_history (
+    address            TEXT          NOT NULL,
+    token_standard     TEXT          NOT NULL,
+    momentum_height    BIGINT        NOT NULL,
+    momentum_timestamp BIGINT        NOT NULL DEFAULT 0, -- Unix seconds
+    balance            NUMERIC(78,0) NOT NULL,           -- after the momentum
+    delta              NUMERIC(78,0) NOT NULL,           -- signed change in the momentum
+    PRIMARY KEY (address, token_standard, momentum_height)
+);
+
+CREATE INDEX IF NOT EXISTS idx_balance_history_momentum_height ON balance_history (momentum_height);
+
+-- Fill from the indexed blocks: a send moves its own amount, a receive
+-- its paired send's. Each series is anchored on the current balance, so
+-- the balance after a momentum is today's less every later change.
+INSERT INTO balance_history (address, token_standard, momentum_height, momentum_timestamp, balance, delta)
+SELECT d.address, d.token_standard, d.momentum_height, d.momentum_timestamp,
+    COALESCE(b.balance, 0) - COALESCE(SUM(d.delta) OVER (
+        PARTITION BY d.address, d.token_standard ORDER BY d.momentum_height DESC
+        ROWS BETWEEN UNBOUNDED PRECEDING AND 1 PRECEDING), 0),
+    d.delta
+FROM (
+    SELECT address, token_standard, momentum_height,
+        COALESCE(MAX(momentum_timestamp), 0) AS momentum_timestamp, SUM(delta) AS delta
+    FROM (
+        SELECT address, token_standard, momentum_height, momentum_timestamp, -amount AS delta
+        FROM account_blocks
+        WHERE block_type IN (2, 4)
+        UNION ALL
+        SELECT rcv.address, s.token_standard, rcv.momentum_height, rcv.momentum_timestamp, s.amount
+        FROM account_blocks rcv
+        JOIN account_blocks s ON s.hash = rcv.paired_account_block
+        WHERE rcv.block_type IN (3, 5)
+    ) moved
+    WHERE address <> 'z1qxemdeddedxt0kenxxxxxxxxxxxxxxxxh9amk0'
+    GROUP BY address, token_standard, momentum_height
+    HAVING SUM(delta) <> 0
+) d
+LEFT JOIN balances b ON b.address = d.address AND b.token_standard = d.token_standard
+ON CONFLICT DO NOTHING;
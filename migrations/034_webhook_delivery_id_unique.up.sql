@@ -0,0 +1,8 @@
+-- Outbox delivery ids are now derived from the endpoint, event type,
+-- momentum height and payload (UUIDv5) instead of drawn at random, so
+-- re-processing a height produces the same ids. The unique index lets
+-- the insert skip an event that is already queued or delivered instead
+-- of sending it again. The column default stays for the API's test
+-- events, which are not tied to a momentum.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_outbox_delivery_id
+    ON webhook_outbox (delivery_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_delegations_account_block_hash;
+ALTER TABLE delegations DROP COLUMN IF EXISTS account_block_hash;
@@ -0,0 +1,8 @@
+-- Key each delegation interval on the pillar contract receive block that
+-- opened it, so replaying a Delegate (backfill --reprocess) cannot open
+-- the same interval twice. Rows written before this migration keep a
+-- NULL hash; replays of their blocks are recognised by timestamp instead.
+ALTER TABLE delegations ADD COLUMN IF NOT EXISTS account_block_hash TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_delegations_account_block_hash
+    ON delegations (account_block_hash);
@@ -0,0 +1,20 @@
+-- The Liquidity administrator's and guardians' calls, decoded from the
+-- ledger: one row per contract-receive block on the liquidity address
+-- whose method is an administrative one (SetTokenTuple,
+-- SetAdditionalReward, SetIsHalted, ChangeAdministrator,
+-- NominateGuardians, ...). liquidity_config holds only the state the
+-- node reports now; this table is the history of how it got there.
+CREATE TABLE IF NOT EXISTS liquidity_admin_actions (
+    account_block_hash TEXT PRIMARY KEY,                  -- contract receive block
+    send_block_hash    TEXT          NOT NULL DEFAULT '', -- the send that made the call
+    method             TEXT          NOT NULL DEFAULT '', -- ABI method, e.g. SetIsHalted
+    address            TEXT          NOT NULL DEFAULT '', -- caller
+    token_standard     TEXT          NOT NULL DEFAULT '', -- token sent with the call
+    amount             NUMERIC(78,0) NOT NULL DEFAULT 0,
+    inputs             JSONB         NOT NULL DEFAULT '{}', -- decoded ABI inputs
+    momentum_height    BIGINT        NOT NULL DEFAULT 0,
+    momentum_timestamp BIGINT        NOT NULL DEFAULT 0   -- Unix seconds
+);
+
+CREATE INDEX IF NOT EXISTS idx_liquidity_admin_actions_momentum_height ON liquidity_admin_actions (momentum_height);
+CREATE INDEX IF NOT EXISTS idx_liquidity_admin_actions_method ON liquidity_admin_actions (method);
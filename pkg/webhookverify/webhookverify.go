@@ -0,0 +1,181 @@
+// Package webhookverify verifies the signatures nom-indexer puts on its
+// webhook deliveries. It has no dependencies outside the standard
+// library, so consumers can import it without pulling in the indexer.
+//
+// Every delivery to an endpoint with a secret carries
+//
+//	X-Webhook-Signature: t=<unix seconds>,v1=<hex>[,v1=<hex>]
+//
+// where each v1 value is the hex HMAC-SHA256 of "<t>.<raw body>" under
+// one of the endpoint's secrets. There are two v1 values while a rotated
+// secret is still in its grace period. A receiver accepts the request
+// when any v1 matches one of its secrets and t is within its tolerance
+// of the current time, which stops a captured request from being
+// replayed later. The envelope's "id" (also sent as X-Webhook-ID) is the
+// same on every retry of a delivery; use it to drop duplicates.
+//
+// Typical use in an HTTP handler:
+//
+//	v := webhookverify.Verifier{Secrets: []string{secret}}
+//	body, err := v.VerifyRequest(r)
+//	if err != nil {
+//		http.Error(w, "bad signature", http.StatusUnauthorized)
+//		return
+//	}
+package webhookverify
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers set on every delivery.
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	IDHeader        = "X-Webhook-ID"
+)
+
+// DefaultTolerance is how far a delivery's timestamp may be from the
+// receiver's clock when Verifier.Tolerance is zero.
+const DefaultTolerance = 5 * time.Minute
+
+// MaxBodySize is the largest body VerifyRequest reads.
+const MaxBodySize = 1 << 20
+
+// Errors returned by Verify. They are distinct so a receiver can log why
+// it rejected a request; all of them mean "reject".
+var (
+	ErrMalformedHeader = errors.New("webhookverify: missing or malformed signature header")
+	ErrTimestamp       = errors.New("webhookverify: timestamp outside tolerance")
+	ErrNoMatch         = errors.New("webhookverify: no signature matches")
+)
+
+// Envelope is the JSON body of every delivery. Payload's shape depends
+// on Type and Version.
+type Envelope struct {
+	ID      string          `json:"id"`
+	Type    string          `json:"type"`
+	Version int             `json:"version"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// Sign returns the v1 signature of body sent at timestamp: the hex
+// HMAC-SHA256 of "<timestamp>.<body>" keyed by secret.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Header builds a signature header value for body sent at timestamp,
+// with one v1 signature per non-empty secret, in order.
+func Header(timestamp int64, body []byte, secrets ...string) string {
+	var b strings.Builder
+	b.WriteString("t=")
+	b.WriteString(strconv.FormatInt(timestamp, 10))
+	for _, s := range secrets {
+		if s == "" {
+			continue
+		}
+		b.WriteString(",v1=")
+		b.WriteString(Sign(s, timestamp, body))
+	}
+	return b.String()
+}
+
+// Parse splits a signature header into its timestamp and v1 signatures.
+// Unknown keys (future schemes) are ignored.
+func Parse(header string) (timestamp int64, signatures []string, err error) {
+	seenT := false
+	for _, part := range strings.Split(header, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return 0, nil, ErrMalformedHeader
+		}
+		switch k {
+		case "t":
+			t, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || seenT {
+				return 0, nil, ErrMalformedHeader
+			}
+			timestamp, seenT = t, true
+		case "v1":
+			signatures = append(signatures, v)
+		}
+	}
+	if !seenT || len(signatures) == 0 {
+		return 0, nil, ErrMalformedHeader
+	}
+	return timestamp, signatures, nil
+}
+
+// Verifier checks deliveries against a set of secrets. The zero
+// Tolerance means DefaultTolerance; Now defaults to time.Now.
+type Verifier struct {
+	// Secrets are the endpoint secrets to accept. List two while you
+	// rotate a secret on your side.
+	Secrets   []string
+	Tolerance time.Duration
+	Now       func() time.Time
+}
+
+// Verify checks header (the X-Webhook-Signature value) against the raw
+// body as received. Pass the exact bytes: re-serialising parsed JSON
+// changes the signature.
+func (v Verifier) Verify(header string, body []byte) error {
+	ts, sigs, err := Parse(header)
+	if err != nil {
+		return err
+	}
+	tolerance := v.Tolerance
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+	now := time.Now
+	if v.Now != nil {
+		now = v.Now
+	}
+	if skew := now().Sub(time.Unix(ts, 0)); skew > tolerance || skew < -tolerance {
+		return fmt.Errorf("%w: sent %s", ErrTimestamp, time.Unix(ts, 0).UTC().Format(time.RFC3339))
+	}
+	for _, secret := range v.Secrets {
+		if secret == "" {
+			continue
+		}
+		want := []byte(Sign(secret, ts, body))
+		for _, sig := range sigs {
+			if hmac.Equal(want, []byte(sig)) {
+				return nil
+			}
+		}
+	}
+	return ErrNoMatch
+}
+
+// VerifyRequest reads r's body (at most MaxBodySize bytes) and verifies
+// it against r's signature header. It returns the body for the caller
+// to decode, e.g. into an Envelope.
+func (v Verifier) VerifyRequest(r *http.Request) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, MaxBodySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("webhookverify: read body: %w", err)
+	}
+	if len(body) > MaxBodySize {
+		return nil, fmt.Errorf("webhookverify: body exceeds %d bytes", MaxBodySize)
+	}
+	if err := v.Verify(r.Header.Get(SignatureHeader), body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
@@ -0,0 +1,113 @@
+package webhookverify
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSign_KnownVector(t *testing.T) {
+	// printf '1700000000.{"id":"x"}' | openssl dgst -sha256 -hmac s3cr3t
+	got := Sign("s3cr3t", 1700000000, []byte(`{"id":"x"}`))
+	if want := "2a8e217061b97ef3c7309b8ae4d803970484a757ecfc3a1219d18876526b3eef"; got != want {
+		t.Fatalf("Sign = %q, want %q", got, want)
+	}
+	if got == Sign("s3cr3t", 1700000001, []byte(`{"id":"x"}`)) {
+		t.Error("signature does not cover the timestamp")
+	}
+	if got == Sign("other", 1700000000, []byte(`{"id":"x"}`)) {
+		t.Error("signature does not depend on the secret")
+	}
+}
+
+func TestHeader(t *testing.T) {
+	body := []byte(`{}`)
+	got := Header(42, body, "new", "", "old")
+	want := "t=42,v1=" + Sign("new", 42, body) + ",v1=" + Sign("old", 42, body)
+	if got != want {
+		t.Errorf("Header = %q, want %q", got, want)
+	}
+}
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		header string
+		ts     int64
+		n      int
+		ok     bool
+	}{
+		{"t=10,v1=aa", 10, 1, true},
+		{"t=10, v1=aa, v1=bb", 10, 2, true},
+		{"v1=aa,t=10,v2=zz", 10, 1, true},
+		{"", 0, 0, false},
+		{"t=10", 0, 0, false},
+		{"v1=aa", 0, 0, false},
+		{"t=x,v1=aa", 0, 0, false},
+		{"t=1,t=2,v1=aa", 0, 0, false},
+		{"deadbeef", 0, 0, false},
+	} {
+		ts, sigs, err := Parse(tc.header)
+		if ok := err == nil; ok != tc.ok {
+			t.Errorf("Parse(%q) err = %v, want ok=%v", tc.header, err, tc.ok)
+			continue
+		}
+		if tc.ok && (ts != tc.ts || len(sigs) != tc.n) {
+			t.Errorf("Parse(%q) = %d, %v", tc.header, ts, sigs)
+		}
+	}
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"id":"d1","type":"reorg","version":1,"payload":{}}`)
+	v := Verifier{Secrets: []string{"current"}, Now: func() time.Time { return now }}
+
+	for _, tc := range []struct {
+		name   string
+		header string
+		want   error
+	}{
+		{"valid", Header(now.Unix(), body, "current"), nil},
+		{"valid among two", Header(now.Unix(), body, "next", "current"), nil},
+		{"within tolerance", Header(now.Add(-4*time.Minute).Unix(), body, "current"), nil},
+		{"too old", Header(now.Add(-6*time.Minute).Unix(), body, "current"), ErrTimestamp},
+		{"too new", Header(now.Add(6*time.Minute).Unix(), body, "current"), ErrTimestamp},
+		{"wrong secret", Header(now.Unix(), body, "other"), ErrNoMatch},
+		{"malformed", "abc", ErrMalformedHeader},
+		{"missing", "", ErrMalformedHeader},
+	} {
+		if err := v.Verify(tc.header, body); !errors.Is(err, tc.want) {
+			t.Errorf("%s: Verify = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+
+	// A replayed body with a fresh timestamp but the old signature fails.
+	old := Header(now.Add(-time.Hour).Unix(), body, "current")
+	_, sigs, _ := Parse(old)
+	forged := "t=1700000000,v1=" + sigs[0]
+	if err := v.Verify(forged, body); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("re-stamped signature: Verify = %v, want ErrNoMatch", err)
+	}
+	// A tampered body fails.
+	if err := v.Verify(Header(now.Unix(), body, "current"), append(body, ' ')); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("tampered body: Verify = %v, want ErrNoMatch", err)
+	}
+}
+
+func TestVerifier_VerifyRequest(t *testing.T) {
+	body := []byte(`{"id":"d1"}`)
+	r := httptest.NewRequest("POST", "/hook", bytes.NewReader(body))
+	r.Header.Set(SignatureHeader, Header(time.Now().Unix(), body, "k"))
+
+	got, err := Verifier{Secrets: []string{"k"}}.VerifyRequest(r)
+	if err != nil || !bytes.Equal(got, body) {
+		t.Fatalf("VerifyRequest = %q, %v", got, err)
+	}
+
+	r = httptest.NewRequest("POST", "/hook", bytes.NewReader(body))
+	if _, err := (Verifier{Secrets: []string{"k"}}).VerifyRequest(r); !errors.Is(err, ErrMalformedHeader) {
+		t.Errorf("unsigned request: err = %v", err)
+	}
+}